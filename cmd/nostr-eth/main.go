@@ -0,0 +1,472 @@
+// Command nostr-eth exposes the nostr-eth event logic to other services
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/comunifi/nostr-eth/pkg/bundler"
+	"github.com/comunifi/nostr-eth/pkg/config"
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/comunifi/nostr-eth/pkg/tenant"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		if err := serve(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	case "bundler":
+		if err := bundlerRPC(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	case "query":
+		if err := query(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: nostr-eth serve [-addr :50051] [-relays wss://a,wss://b] [-publish-relays wss://c,...] [-outbox] [-http :8080] [-auth] [-auth-pubkeys npub1...] [-tenants tenants.json] [-config config.json | -config-pubkey <hex>] [-admin-pubkeys <hex>,... [-admin-state admin.json]] [-audit] [-heartbeat 1m]")
+	fmt.Fprintln(os.Stderr, "       nostr-eth bundler -chain-id 100 -entry-points 0x... [-addr :4337] [-relays wss://a,wss://b] [-bundlers <hex>,...] [-estimators <hex>,...]")
+	fmt.Fprintln(os.Stderr, `       nostr-eth query [-in dump.jsonl] [-limit 100] 'kind=111013 AND chain="100" AND amount>1e18'`)
+	fmt.Fprintln(os.Stderr, "the signing key is read from NOSTR_ETH_PRIVATE_KEY")
+}
+
+// serve runs the gRPC server until it receives SIGINT or SIGTERM
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":50051", "address to listen on")
+	relays := flags.String("relays", "", "comma separated relays to publish to")
+	publishRelays := flags.String("publish-relays", "", "comma separated relays publish requests may name besides -relays")
+	outbox := flags.Bool("outbox", false, "also publish to the NIP-65 read relays of the tagged users")
+	httpAddr := flags.String("http", "", "address to serve the REST API on, disabled when empty")
+	origins := flags.String("http-origins", "", "comma separated origins of the browsers allowed to open the stream")
+	requireAuth := flags.Bool("auth", false, "require NIP-98 auth to publish over gRPC and HTTP, any pubkey may publish the events it signed")
+	authPubkeys := flags.String("auth-pubkeys", "", "comma separated pubkeys allowed to publish and to have unsigned events signed by the server, implies -auth")
+	tenants := flags.String("tenants", "", "file of the tenants, enables the Tenants API managed by -auth-pubkeys")
+	configFile := flags.String("config", "", "JSON config file of the relays, reloaded while running")
+	configPubkey := flags.String("config-pubkey", "", "pubkey whose NIP-78 config events on -relays are reloaded while running")
+	adminPubkeys := flags.String("admin-pubkeys", "", "comma separated hex pubkeys of the operators whose admin commands are applied")
+	adminState := flags.String("admin-state", "", "file of the applied admin commands, kept in memory when empty")
+	audit := flags.Bool("audit", false, "publish signed audit records of the applied configs to the relays")
+	heartbeat := flags.Duration("heartbeat", 0, "interval of the heartbeats published to the relays, disabled when 0")
+	flags.Parse(args)
+
+	if *tenants != "" && (*httpAddr == "" || *authPubkeys == "") {
+		return fmt.Errorf("-tenants requires -http and -auth-pubkeys")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool := nostr.NewSimplePool(ctx)
+
+	var opts []service.PublisherOption
+	if *outbox {
+		// Relay lists are looked up on the relays the events are published to
+		reader := event.NewPoolMultiReader(pool, splitList(*relays))
+		opts = append(opts, service.WithRelayListResolver(event.NewRelayListResolver(reader, 0)))
+	}
+
+	publisher := service.NewPoolPublisher(pool, opts...)
+	server := service.NewServer(os.Getenv("NOSTR_ETH_PRIVATE_KEY"), splitList(*relays), publisher)
+	server.AllowRelays(splitList(*publishRelays))
+
+	// New configs replace the default relays of the server and restart the loops reading relays
+	feed := &relayFeed{}
+	apply := func(c *config.Config) {
+		c.Apply(config.Targets{Server: server})
+		feed.publish(c.Relays)
+	}
+
+	var auditor *sink.Auditor
+	if *audit {
+		auditor = sink.NewAuditor(publishSink{server}, os.Getenv("NOSTR_ETH_PRIVATE_KEY"), func(err error) {
+			log.Printf("failed to publish audit record: %v", err)
+		})
+	}
+
+	current := &config.Config{Relays: splitList(*relays)}
+	var admin *config.Admin
+	var reloader *config.Reloader
+	if *configFile != "" || *configPubkey != "" {
+		var source config.Source = config.NewFileSource(*configFile)
+		if *configFile == "" {
+			source = config.NewEventSource(event.NewPoolMultiReader(pool, splitList(*relays)), *configPubkey, "")
+		}
+
+		// Admin commands apply on top of the reloaded config
+		reloader = config.NewReloader(source, func(c *config.Config) {
+			if admin != nil {
+				c = admin.SetConfig(c)
+			}
+			apply(c)
+			log.Printf("config reloaded, relays: %v", c.Relays)
+		}, 0, func(err error) {
+			log.Printf("failed to reload config: %v", err)
+		})
+		reloader.SetAuditor(auditor)
+
+		if _, err := reloader.Reload(ctx); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		current = reloader.Config()
+	}
+
+	if *adminPubkeys != "" {
+		bridge, err := nostr.GetPublicKey(os.Getenv("NOSTR_ETH_PRIVATE_KEY"))
+		if err != nil {
+			return fmt.Errorf("admin commands require NOSTR_ETH_PRIVATE_KEY: %w", err)
+		}
+
+		adminOpts := []config.AdminOption{config.WithAdminAuditor(auditor)}
+		if *adminState != "" {
+			adminOpts = append(adminOpts, config.WithAdminStateStore(state.NewFileStore(*adminState)))
+		}
+		admin = config.NewAdmin(bridge, splitList(*adminPubkeys), current, func(c *config.Config) {
+			apply(c)
+			log.Printf("admin command applied, relays: %v, contracts: %v, muted: %v", c.Relays, c.Contracts, c.Muted)
+		}, adminOpts...)
+		if err := admin.Restore(ctx); err != nil {
+			return err
+		}
+
+		go follow(ctx, server.Relays(), feed.subscribe(), func(ctx context.Context, relays []string) {
+			admin.Run(ctx, pool.SubscribeMany(ctx, relays, admin.Filter()), func(err error) {
+				log.Printf("rejected admin command: %v", err)
+			})
+		})
+	}
+
+	if reloader != nil {
+		if err := reloader.Start(ctx); err != nil {
+			return err
+		}
+		defer reloader.Stop()
+	}
+
+	if *heartbeat > 0 {
+		instance, err := os.Hostname()
+		if err != nil {
+			instance = event.DefaultHeartbeatInstance
+		}
+
+		heartbeater := watcher.NewHeartbeater(publishSink{server}, os.Getenv("NOSTR_ETH_PRIVATE_KEY"), instance, *heartbeat, func(err error) {
+			log.Printf("failed to publish heartbeat: %v", err)
+		})
+		heartbeater.SetRelayStatus(func() map[string]bool {
+			status := map[string]bool{}
+			for _, url := range server.Relays() {
+				relay, ok := pool.Relays.Load(nostr.NormalizeURL(url))
+				status[url] = ok && relay.IsConnected()
+			}
+			return status
+		})
+		if err := heartbeater.Start(ctx); err != nil {
+			return err
+		}
+		defer heartbeater.Stop()
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	var auth *service.Authenticator
+	if *requireAuth || *authPubkeys != "" {
+		auth = service.NewAuthenticator(service.WithAuthPubkeys(splitList(*authPubkeys)...))
+	}
+
+	if *httpAddr != "" {
+		s := store.NewMemoryStore()
+		go follow(ctx, server.Relays(), feed.subscribe(), func(ctx context.Context, relays []string) {
+			ingest(ctx, pool, relays, s)
+		})
+
+		httpOpts := []service.HTTPOption{service.WithStreamOrigins(splitList(*origins)...)}
+		if auth != nil {
+			httpOpts = append(httpOpts, service.WithAuth(auth))
+		}
+
+		var handler http.Handler = service.NewHTTPHandler(server, s, httpOpts...)
+		if *tenants != "" {
+			registry := tenant.NewRegistry(publisher,
+				tenant.WithStateStore(state.NewFileStore(*tenants)),
+				tenant.WithHTTPOptions(service.WithStreamOrigins(splitList(*origins)...)),
+				tenant.WithRunner(func(ctx context.Context, t *tenant.Tenant) {
+					ingest(ctx, pool, t.Relays(), t.Store())
+				}),
+			)
+			if err := registry.Start(ctx); err != nil {
+				return err
+			}
+			defer registry.Stop()
+
+			tenantHandler := tenant.NewHandler(registry, auth)
+			mux := http.NewServeMux()
+			mux.Handle("/", handler)
+			mux.Handle("/tenants", tenantHandler)
+			mux.Handle("/tenants/", tenantHandler)
+			handler = mux
+		}
+
+		httpServer := &http.Server{Addr: *httpAddr, Handler: handler}
+		go func() {
+			<-ctx.Done()
+			httpServer.Shutdown(context.Background())
+		}()
+		go func() {
+			log.Printf("nostr-eth REST API listening on %s", *httpAddr)
+			if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+				log.Print(err)
+			}
+		}()
+	}
+
+	var grpcOpts []grpc.ServerOption
+	if auth != nil {
+		grpcOpts = append(grpcOpts, grpc.UnaryInterceptor(auth.UnaryInterceptor()))
+	}
+
+	grpcServer := grpc.NewServer(grpcOpts...)
+	pb.RegisterNostrEthServer(grpcServer, server)
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	log.Printf("nostr-eth gRPC server listening on %s", listener.Addr())
+
+	return grpcServer.Serve(listener)
+}
+
+// bundlerRPC runs the ERC-4337 bundler JSON-RPC endpoint until it receives SIGINT or SIGTERM
+func bundlerRPC(args []string) error {
+	flags := flag.NewFlagSet("bundler", flag.ExitOnError)
+	addr := flags.String("addr", ":4337", "address to listen on")
+	relays := flags.String("relays", "", "comma separated relays of the bundler network")
+	chainID := flags.String("chain-id", "", "chain id of the user operations")
+	entryPoints := flags.String("entry-points", "", "comma separated supported entry points")
+	bundlers := flags.String("bundlers", "", "comma separated hex pubkeys of the bundlers whose user op updates are trusted")
+	estimators := flags.String("estimators", "", "comma separated hex pubkeys of the estimators whose gas estimates are trusted")
+	flags.Parse(args)
+
+	chain, ok := new(big.Int).SetString(*chainID, 10)
+	if !ok {
+		return fmt.Errorf("invalid chain id: %q", *chainID)
+	}
+
+	var supported []common.Address
+	for _, entryPoint := range splitList(*entryPoints) {
+		if !common.IsHexAddress(entryPoint) {
+			return fmt.Errorf("invalid entry point: %s", entryPoint)
+		}
+		supported = append(supported, common.HexToAddress(entryPoint))
+	}
+	if len(supported) == 0 {
+		return fmt.Errorf("at least one entry point is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool := bundler.NewPoolRelays(nostr.NewSimplePool(ctx), splitList(*relays))
+	bridge := bundler.NewBridge(chain, supported, os.Getenv("NOSTR_ETH_PRIVATE_KEY"), pool,
+		bundler.WithBundlers(splitList(*bundlers)...), bundler.WithEstimators(splitList(*estimators)...))
+
+	server := &http.Server{Addr: *addr, Handler: bridge}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	log.Printf("nostr-eth bundler RPC listening on %s", *addr)
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// ingest stores the events of the package kinds published on the relays until the context is
+// done
+func ingest(ctx context.Context, pool *nostr.SimplePool, relays []string, s *store.MemoryStore) {
+	if len(relays) == 0 {
+		return
+	}
+
+	var kinds []int
+	for _, spec := range event.Kinds() {
+		kinds = append(kinds, event.MappedKind(spec.Kind))
+	}
+
+	for relayEvent := range pool.SubscribeMany(ctx, relays, nostr.Filter{Kinds: kinds}) {
+		if err := s.Publish(ctx, *relayEvent.Event); err != nil && ctx.Err() == nil {
+			log.Printf("failed to store %s: %v", relayEvent.Event.ID, err)
+		}
+	}
+}
+
+// publishSink publishes the events sent to it to the default relays of a server
+type publishSink struct {
+	server *service.Server
+}
+
+// Send publishes an event, signed by the server since it comes from the bridge itself, it fails
+// when no relay accepted it
+func (s publishSink) Send(ctx context.Context, evt *nostr.Event) error {
+	resp, err := s.server.PublishEvent(service.AllowSigning(ctx), &pb.PublishEventRequest{Event: pb.FromEvent(evt)})
+	if err != nil {
+		return err
+	}
+	if len(service.NewPublishResult(evt.ID, resp.GetResults()).Accepted()) == 0 {
+		return fmt.Errorf("no relay accepted %s", evt.ID)
+	}
+	return nil
+}
+
+// relayFeed hands the relays of every new config to the loops following them
+type relayFeed struct {
+	mu          sync.Mutex
+	subscribers []chan []string
+}
+
+// subscribe returns a channel of the relays of the next configs
+func (f *relayFeed) subscribe() <-chan []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	changes := make(chan []string, 1)
+	f.subscribers = append(f.subscribers, changes)
+	return changes
+}
+
+// publish hands relays to the subscribers, replacing the relays they did not read yet
+func (f *relayFeed) publish(relays []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, changes := range f.subscribers {
+		select {
+		case <-changes:
+		default:
+		}
+		changes <- relays
+	}
+}
+
+// follow runs a function on relays until the context is done, restarting it on the relays of
+// every change
+func follow(ctx context.Context, relays []string, changes <-chan []string, run func(ctx context.Context, relays []string)) {
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			run(runCtx, relays)
+		}()
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-done
+			return
+		case relays = <-changes:
+			cancel()
+			<-done
+		}
+	}
+}
+
+// query prints the events of an NDJSON dump matching a filter expression, newest first
+func query(args []string) error {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	in := flags.String("in", "", "NDJSON event dump to query, stdin when empty")
+	limit := flags.Int("limit", 0, "maximum number of events to print, all when 0")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one filter expression, got %d arguments", flags.NArg())
+	}
+
+	q, err := store.ParseQuery(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	r := io.Reader(os.Stdin)
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	ctx := context.Background()
+
+	s := store.NewMemoryStore()
+	if _, err := watcher.ImportEvents(ctx, s, r); err != nil {
+		return err
+	}
+
+	events, err := q.Run(ctx, s, *limit)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, evt := range events {
+		if err := encoder.Encode(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value, ignoring empty elements
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
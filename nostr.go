@@ -2,13 +2,18 @@ package nostreth
 
 // Re-export all functions from the log package
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"math/big"
+	"net/http"
+	"time"
 
 	"github.com/comunifi/nostr-eth/pkg/event"
 	"github.com/comunifi/nostr-eth/pkg/neth"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
 )
 
 // Re-export log package types
@@ -17,6 +22,20 @@ type TxTransferEvent = event.TxTransferEvent
 type Log = neth.Log
 type UserOpEvent = event.UserOpEvent
 type UserOp = neth.UserOp
+type TransferDirection = neth.TransferDirection
+
+// Re-export constructor option types
+type LogOption = event.LogOption
+
+// Re-export topic registry types
+type TopicDecoder = event.TopicDecoder
+type TopicEntry = event.TopicEntry
+type TopicRegistry = event.TopicRegistry
+
+// Re-export pagination types
+type Cursor = event.Cursor
+type Page = event.Page
+type Paginator = event.Paginator
 
 // Re-export group package types
 type GroupMetadata = event.GroupMetadata
@@ -24,6 +43,7 @@ type GroupMessage = event.GroupMessage
 type GroupJoin = event.GroupJoin
 type GroupLeave = event.GroupLeave
 type GroupModeration = event.GroupModeration
+type GroupState = event.GroupState
 
 // Group Metadata Event Types (39000s)
 type GroupMetadataEvent = event.GroupMetadataEvent
@@ -39,13 +59,26 @@ type GroupUpdatedEvent = event.GroupUpdatedEvent
 
 // Re-export log package constants
 const (
-	KindTxLog       = event.KindTxLog
-	KindTxTransfer  = event.KindTxTransfer
-	EventUserOpKind = event.EventUserOpKind
-
-	TopicERC20Transfer = neth.TopicERC20Transfer
+	KindTxLog            = event.KindTxLog
+	KindTxTransfer       = event.KindTxTransfer
+	KindTxTransferLegacy = event.KindTxTransferLegacy
+	EventUserOpKind      = event.EventUserOpKind
+
+	TopicERC20Transfer  = neth.TopicERC20Transfer
+	TopicERC20Approval  = neth.TopicERC20Approval
+	TopicUniswapV2Swap  = neth.TopicUniswapV2Swap
+	TopicUniswapV3Swap  = neth.TopicUniswapV3Swap
+	TopicWETHDeposit    = neth.TopicWETHDeposit
+	TopicWETHWithdrawal = neth.TopicWETHWithdrawal
+
+	TransferDirectionIn   = neth.TransferDirectionIn
+	TransferDirectionOut  = neth.TransferDirectionOut
+	TransferDirectionSelf = neth.TransferDirectionSelf
+	TransferDirectionNone = neth.TransferDirectionNone
 
 	EventTypeTxLogCreated      = event.EventTypeTxLogCreated
+	EventTypeTxLogUpdated      = event.EventTypeTxLogUpdated
+	EventTypeTxLogOrphaned     = event.EventTypeTxLogOrphaned
 	EventTypeTxTransferCreated = event.EventTypeTxTransferCreated
 
 	EventTypeUserOpRequested = event.EventTypeUserOpRequested
@@ -85,22 +118,78 @@ const (
 )
 
 // Re-export log package functions
-func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
-	return event.CreateTxTransferEvent(log)
+func CreateTxTransferEvent(log neth.Log, opts ...event.LogOption) (*nostr.Event, error) {
+	return event.CreateTxTransferEvent(log, opts...)
+}
+
+func WithSortableAmount() event.LogOption {
+	return event.WithSortableAmount()
+}
+
+func WithTopicRegistry(registry *event.TopicRegistry) event.LogOption {
+	return event.WithTopicRegistry(registry)
+}
+
+func NewTopicRegistry() *event.TopicRegistry {
+	return event.NewTopicRegistry()
+}
+
+func RegisterTopic(topic string, entry event.TopicEntry) error {
+	return event.RegisterTopic(topic, entry)
+}
+
+func RegisterDefaultTopics(registry *event.TopicRegistry) error {
+	return event.RegisterDefaultTopics(registry)
+}
+
+func EncodeSortableAmount(amount *big.Int) (string, error) {
+	return event.EncodeSortableAmount(amount)
+}
+
+func DecodeSortableAmount(encoded string) (*big.Int, error) {
+	return event.DecodeSortableAmount(encoded)
+}
+
+func GetSortableAmountFromEvent(evt *nostr.Event) (*big.Int, error) {
+	return event.GetSortableAmountFromEvent(evt)
 }
 
 func ParseTxTransferEvent(evt *nostr.Event) (*event.TxTransferEvent, error) {
 	return event.ParseTxTransferEvent(evt)
 }
 
-func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
-	return event.CreateTxLogEvent(log)
+func CreateTxLogEvent(log neth.Log, opts ...event.LogOption) (*nostr.Event, error) {
+	return event.CreateTxLogEvent(log, opts...)
 }
 
 func ParseTxLogEvent(evt *nostr.Event) (*event.TxLogEvent, error) {
 	return event.ParseTxLogEvent(evt)
 }
 
+func LatestLogsForAddress(events []*nostr.Event, address string) ([]neth.Log, error) {
+	return event.LatestLogsForAddress(events, address)
+}
+
+func IsAddressInEvent(evt *nostr.Event, address string) bool {
+	return event.IsAddressInEvent(evt, address)
+}
+
+func FormatUnits(amount *big.Int, decimals int64) string {
+	return neth.FormatUnits(amount, decimals)
+}
+
+func ParseUnits(value string, decimals int64) (*big.Int, error) {
+	return neth.ParseUnits(value, decimals)
+}
+
+func FormatEther(wei *big.Int) string {
+	return neth.FormatEther(wei)
+}
+
+func ParseEther(value string) (*big.Int, error) {
+	return neth.ParseEther(value)
+}
+
 func GetEventData(log neth.Log) (map[string]interface{}, error) {
 	return log.GetEventData()
 }
@@ -257,6 +346,10 @@ func ParseGroupUpdatedEvent(evt *nostr.Event) (*event.GroupUpdatedEvent, error)
 	return event.ParseGroupUpdatedEvent(evt)
 }
 
+func ComputeGroupState(events []*nostr.Event) (*event.GroupState, error) {
+	return event.ComputeGroupState(events)
+}
+
 func GetGroupIDFromEvent(evt *nostr.Event) (string, error) {
 	return event.GetGroupIDFromEvent(evt)
 }
@@ -277,6 +370,14 @@ func FormatGroupIdentifier(host, groupID string) string {
 	return event.FormatGroupIdentifier(host, groupID)
 }
 
+func DecodeGroupIdentifier(groupIdentifier string) (*event.GroupAddress, error) {
+	return event.DecodeGroupIdentifier(groupIdentifier)
+}
+
+func EncodeGroupIdentifier(address event.GroupAddress, format event.GroupIdentifierFormat) (string, error) {
+	return event.EncodeGroupIdentifier(address, format)
+}
+
 func ValidateGroupID(groupID string) error {
 	return event.ValidateGroupID(groupID)
 }
@@ -286,12 +387,12 @@ func GetEventTypeFromGroupEvent(evt *nostr.Event) string {
 }
 
 // Re-export message package functions
-func CreateMessageEvent(content string, group *string) (*nostr.Event, error) {
-	return event.CreateMessageEvent(content, group)
+func CreateMessageEvent(content string, group *string, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateMessageEvent(content, group, opts...)
 }
 
-func UpdateMessageEvent(content string, group *string, originalEvent *nostr.Event) (*nostr.Event, error) {
-	return event.UpdateMessageEvent(content, group, originalEvent)
+func UpdateMessageEvent(content string, group *string, originalEvent *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.UpdateMessageEvent(content, group, originalEvent, opts...)
 }
 
 func GetGroupFromEvent(evt *nostr.Event) (string, error) {
@@ -322,8 +423,8 @@ func FilterEventsByTxHash(events []*nostr.Event, txHash string) []*nostr.Event {
 	return event.FilterEventsByTxHash(events, txHash)
 }
 
-func CreateReplyEvent(content string, group *string, replyTo *nostr.Event) (*nostr.Event, error) {
-	return event.CreateReplyEvent(content, group, replyTo)
+func CreateReplyEvent(content string, group *string, replyTo *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateReplyEvent(content, group, replyTo, opts...)
 }
 
 func GetReplyChainFromEvent(evt *nostr.Event) (string, string, error) {
@@ -334,8 +435,8 @@ func GetParticipantsFromEvent(evt *nostr.Event) []string {
 	return event.GetParticipantsFromEvent(evt)
 }
 
-func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.Event, relayURL string) (*nostr.Event, error) {
-	return event.CreateQuoteRepostEvent(content, group, repostedEvent, relayURL)
+func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.Event, relayURL string, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateQuoteRepostEvent(content, group, repostedEvent, relayURL, opts...)
 }
 
 func IsReplyEvent(evt *nostr.Event) bool {
@@ -349,3 +450,1416 @@ func IsMentionEvent(evt *nostr.Event) bool {
 func IsRootEvent(evt *nostr.Event) bool {
 	return event.IsRootEvent(evt)
 }
+
+// Re-export canonicalization functions
+func CanonicalContentHash(evt *nostr.Event) string {
+	return event.CanonicalContentHash(evt)
+}
+
+func EqualSemantics(a, b *nostr.Event) bool {
+	return event.EqualSemantics(a, b)
+}
+
+// Re-export pagination functions
+func SortEventsByCreatedAt(events []*nostr.Event, newestFirst bool) {
+	event.SortEventsByCreatedAt(events, newestFirst)
+}
+
+func NewPaginator(events []*nostr.Event, pageSize int) *event.Paginator {
+	return event.NewPaginator(events, pageSize)
+}
+
+// Re-export approval package types
+type TxApprovalEvent = event.TxApprovalEvent
+type Allowance = event.Allowance
+type AllowanceStateEvent = event.AllowanceStateEvent
+type AllowanceTracker = event.AllowanceTracker
+
+// Re-export approval package constants
+const (
+	KindTxApproval     = event.KindTxApproval
+	KindAllowanceState = event.KindAllowanceState
+
+	EventTypeTxApprovalCreated = event.EventTypeTxApprovalCreated
+)
+
+// Re-export approval package functions
+func CreateTxApprovalEvent(log neth.Log, opts ...event.LogOption) (*nostr.Event, error) {
+	return event.CreateTxApprovalEvent(log, opts...)
+}
+
+func ParseTxApprovalEvent(evt *nostr.Event) (*event.TxApprovalEvent, error) {
+	return event.ParseTxApprovalEvent(evt)
+}
+
+func GetAllowanceFromLog(log neth.Log) (*event.Allowance, error) {
+	return event.GetAllowanceFromLog(log)
+}
+
+func NewAllowanceTracker() *event.AllowanceTracker {
+	return event.NewAllowanceTracker()
+}
+
+func CreateAllowanceStateEvent(chainID, owner string, allowances []event.Allowance) (*nostr.Event, error) {
+	return event.CreateAllowanceStateEvent(chainID, owner, allowances)
+}
+
+func ParseAllowanceStateEvent(evt *nostr.Event) (*event.AllowanceStateEvent, error) {
+	return event.ParseAllowanceStateEvent(evt)
+}
+
+// Re-export bridge package types
+type BridgeTransferEvent = event.BridgeTransferEvent
+type BridgeCorrelator = event.BridgeCorrelator
+
+// Re-export bridge package constants
+const (
+	KindBridgeTransfer = event.KindBridgeTransfer
+
+	EventTypeBridgeTransferInitiated = event.EventTypeBridgeTransferInitiated
+	EventTypeBridgeTransferFinalized = event.EventTypeBridgeTransferFinalized
+)
+
+// Re-export bridge package functions
+func CreateBridgeTransferEvent(messageHash string, deposit neth.Log, finalization *neth.Log) (*nostr.Event, error) {
+	return event.CreateBridgeTransferEvent(messageHash, deposit, finalization)
+}
+
+func ParseBridgeTransferEvent(evt *nostr.Event) (*event.BridgeTransferEvent, error) {
+	return event.ParseBridgeTransferEvent(evt)
+}
+
+func GetBridgeMessageHash(log neth.Log) (string, bool) {
+	return event.GetBridgeMessageHash(log)
+}
+
+func NewBridgeCorrelator(depositTopics, finalizationTopics []string, messageHash func(log neth.Log) (string, bool)) *event.BridgeCorrelator {
+	return event.NewBridgeCorrelator(depositTopics, finalizationTopics, messageHash)
+}
+
+// Re-export tx package types
+type TxEvent = event.TxEvent
+
+// Re-export tx package constants
+const (
+	KindTx = event.KindTx
+
+	EventTypeTxCreated = event.EventTypeTxCreated
+)
+
+// Re-export tx package functions
+func CreateTxEvent(txHash string, logs []neth.Log) (*nostr.Event, error) {
+	return event.CreateTxEvent(txHash, logs)
+}
+
+func ParseTxEvent(evt *nostr.Event) (*event.TxEvent, error) {
+	return event.ParseTxEvent(evt)
+}
+
+// Re-export pending tx package types
+type PendingTx = neth.PendingTx
+type PendingTxEvent = event.PendingTxEvent
+
+// Re-export pending tx package constants
+const (
+	KindPendingTx = event.KindPendingTx
+
+	EventTypePendingTxSeen = event.EventTypePendingTxSeen
+
+	DefaultPendingTxExpiration = event.DefaultPendingTxExpiration
+)
+
+// Re-export pending tx package functions
+func CreatePendingTxEvent(tx neth.PendingTx, expiration time.Duration) (*nostr.Event, error) {
+	return event.CreatePendingTxEvent(tx, expiration)
+}
+
+func ParsePendingTxEvent(evt *nostr.Event) (*event.PendingTxEvent, error) {
+	return event.ParsePendingTxEvent(evt)
+}
+
+func IsPendingTxExpired(evt *nostr.Event, now time.Time) bool {
+	return event.IsPendingTxExpired(evt, now)
+}
+
+func IsPendingTxSuperseded(pending *nostr.Event, mined *nostr.Event) bool {
+	return event.IsPendingTxSuperseded(pending, mined)
+}
+
+func PrunePendingTxEvents(events []*nostr.Event, now time.Time) []*nostr.Event {
+	return event.PrunePendingTxEvents(events, now)
+}
+
+// Re-export native transfer package types
+type NativeTransfer = neth.NativeTransfer
+type NativeTransferEvent = event.NativeTransferEvent
+
+// Re-export native transfer package constants
+const (
+	KindNativeTransfer  = event.KindNativeTransfer
+	TopicNativeTransfer = neth.TopicNativeTransfer
+
+	EventTypeNativeTransferCreated = event.EventTypeNativeTransferCreated
+)
+
+// Re-export native transfer package functions
+func CreateNativeTransferEvent(transfer neth.NativeTransfer, opts ...event.LogOption) (*nostr.Event, error) {
+	return event.CreateNativeTransferEvent(transfer, opts...)
+}
+
+func ParseNativeTransferEvent(evt *nostr.Event) (*event.NativeTransferEvent, error) {
+	return event.ParseNativeTransferEvent(evt)
+}
+
+// Re-export co-signing package types
+type UserOpSignatureRequestEvent = event.UserOpSignatureRequestEvent
+type UserOpPartialSignatureEvent = event.UserOpPartialSignatureEvent
+type SignatureAggregator = event.SignatureAggregator
+
+// Re-export co-signing package constants
+const (
+	KindUserOpSignatureRequest = event.KindUserOpSignatureRequest
+	KindUserOpPartialSignature = event.KindUserOpPartialSignature
+
+	EventTypeUserOpSignatureRequested = event.EventTypeUserOpSignatureRequested
+	EventTypeUserOpPartiallySigned    = event.EventTypeUserOpPartiallySigned
+)
+
+// Re-export co-signing package functions
+func CreateUserOpSignatureRequestEvent(chainID *big.Int, entryPoint *common.Address, userOp neth.UserOp, signers []common.Address, threshold int) (*nostr.Event, error) {
+	return event.CreateUserOpSignatureRequestEvent(chainID, entryPoint, userOp, signers, threshold)
+}
+
+func ParseUserOpSignatureRequestEvent(evt *nostr.Event) (*event.UserOpSignatureRequestEvent, error) {
+	return event.ParseUserOpSignatureRequestEvent(evt)
+}
+
+func CreateUserOpPartialSignatureEvent(request *nostr.Event, signer common.Address, signature []byte, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateUserOpPartialSignatureEvent(request, signer, signature, opts...)
+}
+
+func ParseUserOpPartialSignatureEvent(evt *nostr.Event) (*event.UserOpPartialSignatureEvent, error) {
+	return event.ParseUserOpPartialSignatureEvent(evt)
+}
+
+func NewSignatureAggregator(request *nostr.Event) (*event.SignatureAggregator, error) {
+	return event.NewSignatureAggregator(request)
+}
+
+// Re-export session key package types
+type SessionKey = neth.SessionKey
+type SessionPermission = neth.SessionPermission
+type SessionKeyEvent = event.SessionKeyEvent
+
+// Re-export session key package constants
+const (
+	KindSessionKey = event.KindSessionKey
+
+	EventTypeSessionKeyGranted = event.EventTypeSessionKeyGranted
+	EventTypeSessionKeyRevoked = event.EventTypeSessionKeyRevoked
+)
+
+// Re-export session key package functions
+func CreateSessionKeyEvent(chainID *big.Int, session neth.SessionKey) (*nostr.Event, error) {
+	return event.CreateSessionKeyEvent(chainID, session)
+}
+
+func ParseSessionKeyEvent(evt *nostr.Event) (*event.SessionKeyEvent, error) {
+	return event.ParseSessionKeyEvent(evt)
+}
+
+func ActiveSessionKeys(events []*nostr.Event, account string, now time.Time, authors ...string) ([]neth.SessionKey, error) {
+	return event.ActiveSessionKeys(events, account, now, authors...)
+}
+
+// Re-export account deployment functions
+func ComputeCreate2Address(deployer common.Address, salt [32]byte, initCodeHash []byte) common.Address {
+	return neth.ComputeCreate2Address(deployer, salt, initCodeHash)
+}
+
+func IsDeployment(userOp neth.UserOp) bool {
+	return userOp.IsDeployment()
+}
+
+// Re-export gas estimation package types
+type GasEstimate = neth.GasEstimate
+type GasEstimateRequestEvent = event.GasEstimateRequestEvent
+type GasEstimateResponseEvent = event.GasEstimateResponseEvent
+
+// Re-export gas estimation package constants
+const (
+	KindGasEstimateRequest  = event.KindGasEstimateRequest
+	KindGasEstimateResponse = event.KindGasEstimateResponse
+
+	EventTypeGasEstimateRequested = event.EventTypeGasEstimateRequested
+	EventTypeGasEstimated         = event.EventTypeGasEstimated
+)
+
+// Re-export gas estimation package functions
+func CreateGasEstimateRequestEvent(chainID *big.Int, entryPoint *common.Address, userOp neth.UserOp, estimator string) (*nostr.Event, error) {
+	return event.CreateGasEstimateRequestEvent(chainID, entryPoint, userOp, estimator)
+}
+
+func ParseGasEstimateRequestEvent(evt *nostr.Event) (*event.GasEstimateRequestEvent, error) {
+	return event.ParseGasEstimateRequestEvent(evt)
+}
+
+func CreateGasEstimateResponseEvent(request *nostr.Event, estimate neth.GasEstimate, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateGasEstimateResponseEvent(request, estimate, opts...)
+}
+
+func ParseGasEstimateResponseEvent(evt *nostr.Event) (*event.GasEstimateResponseEvent, error) {
+	return event.ParseGasEstimateResponseEvent(evt)
+}
+
+// Re-export user op failure types
+type FailureReason = event.FailureReason
+
+// Re-export user op failure functions
+func NewFailureReason(message string, revertData []byte) event.FailureReason {
+	return event.NewFailureReason(message, revertData)
+}
+
+func FailUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, retryCount int, reason event.FailureReason, ev *nostr.Event) (*nostr.Event, error) {
+	return event.FailUserOpEvent(chainID, userOp, txHash, retryCount, reason, ev)
+}
+
+func CanTransitionUserOp(from *event.UserOpEvent, to event.EventTypeUserOp) bool {
+	return event.CanTransitionUserOp(from, to)
+}
+
+// Re-export retry package types
+type RetryPolicy = event.RetryPolicy
+type RetryScheduler = event.RetryScheduler
+
+// Re-export retry package variables
+var DefaultRetryPolicy = event.DefaultRetryPolicy
+
+// Re-export retry package functions
+func NewRetryScheduler(policy event.RetryPolicy) *event.RetryScheduler {
+	return event.NewRetryScheduler(policy)
+}
+
+// Re-export content schema variables
+var ErrNoContentSchema = event.ErrNoContentSchema
+
+// Re-export content schema functions
+func ContentSchema(kind int) ([]byte, error) {
+	return event.ContentSchema(kind)
+}
+
+func ContentSchemaNames() []string {
+	return event.ContentSchemaNames()
+}
+
+func ValidateContentAgainstSchema(evt *nostr.Event) error {
+	return event.ValidateContentAgainstSchema(evt)
+}
+
+// Re-export token list types
+type TokenInfo = neth.TokenInfo
+type TokenList = neth.TokenList
+type TokenRegistry = event.TokenRegistry
+
+// Re-export token list variables
+var DefaultTokenRegistry = event.DefaultTokenRegistry
+
+// Re-export token list functions
+func ParseTokenList(data []byte) (*neth.TokenList, error) {
+	return neth.ParseTokenList(data)
+}
+
+func FetchTokenList(ctx context.Context, client *http.Client, url string) (*neth.TokenList, error) {
+	return neth.FetchTokenList(ctx, client, url)
+}
+
+func NewTokenRegistry() *event.TokenRegistry {
+	return event.NewTokenRegistry()
+}
+
+func WithTokenRegistry(registry *event.TokenRegistry) event.LogOption {
+	return event.WithTokenRegistry(registry)
+}
+
+func WithTransferKind(kind int) event.LogOption {
+	return event.WithTransferKind(kind)
+}
+
+func IsTxTransferEvent(evt *nostr.Event) bool {
+	return event.IsTxTransferEvent(evt)
+}
+
+func MigrateTxTransferEvent(evt *nostr.Event) (*nostr.Event, error) {
+	return event.MigrateTxTransferEvent(evt)
+}
+
+func IsKnownToken(chainID, address string) bool {
+	return event.IsKnownToken(chainID, address)
+}
+
+func IsSpamToken(chainID, address string) bool {
+	return event.IsSpamToken(chainID, address)
+}
+
+// Re-export attestation package types
+type TxLogAttestationEvent = event.TxLogAttestationEvent
+type AttestationQuorum = event.AttestationQuorum
+type AttestationAggregator = event.AttestationAggregator
+
+// Re-export attestation package constants
+const (
+	KindTxLogAttestation = event.KindTxLogAttestation
+)
+
+// Re-export attestation package functions
+func ObservationHash(log neth.Log) string {
+	return event.ObservationHash(log)
+}
+
+func CreateTxLogAttestationEvent(log neth.Log) (*nostr.Event, error) {
+	return event.CreateTxLogAttestationEvent(log)
+}
+
+func ParseTxLogAttestationEvent(evt *nostr.Event) (*event.TxLogAttestationEvent, error) {
+	return event.ParseTxLogAttestationEvent(evt)
+}
+
+func NewAttestationAggregator(observers []string, threshold int) (*event.AttestationAggregator, error) {
+	return event.NewAttestationAggregator(observers, threshold)
+}
+
+// Re-export receipt proof types
+type ReceiptProof = neth.ReceiptProof
+type ReceiptLog = neth.ReceiptLog
+
+// Re-export receipt proof functions
+func BuildReceiptProof(header []byte, transactions, receipts [][]byte, txIndex, logIndex uint64) (*neth.ReceiptProof, error) {
+	return neth.BuildReceiptProof(header, transactions, receipts, txIndex, logIndex)
+}
+
+func DeriveRoot(values [][]byte) common.Hash {
+	return neth.DeriveRoot(values)
+}
+
+func WithReceiptProof(proof *neth.ReceiptProof) event.LogOption {
+	return event.WithReceiptProof(proof)
+}
+
+func VerifyReceiptProof(evt *nostr.Event, blockHash common.Hash) error {
+	return event.VerifyReceiptProof(evt, blockHash)
+}
+
+// Re-export checkpoint package types
+type Checkpoint = neth.Checkpoint
+type CheckpointEvent = event.CheckpointEvent
+type TxLogStatus = event.TxLogStatus
+
+// Re-export checkpoint package constants
+const (
+	KindChainCheckpoint = event.KindChainCheckpoint
+
+	FinalitySafe      = neth.FinalitySafe
+	FinalityFinalized = neth.FinalityFinalized
+
+	TxLogStatusIncluded  = event.TxLogStatusIncluded
+	TxLogStatusConfirmed = event.TxLogStatusConfirmed
+	TxLogStatusSafe      = event.TxLogStatusSafe
+	TxLogStatusFinalized = event.TxLogStatusFinalized
+	TxLogStatusOrphaned  = event.TxLogStatusOrphaned
+)
+
+// Re-export checkpoint package functions
+func WithBlockNumber(blockNumber uint64) event.LogOption {
+	return event.WithBlockNumber(blockNumber)
+}
+
+func CreateCheckpointEvent(checkpoint neth.Checkpoint) (*nostr.Event, error) {
+	return event.CreateCheckpointEvent(checkpoint)
+}
+
+func ParseCheckpointEvent(evt *nostr.Event) (*event.CheckpointEvent, error) {
+	return event.ParseCheckpointEvent(evt)
+}
+
+func UpdateTxLogStatus(evt *nostr.Event, status event.TxLogStatus, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.UpdateTxLogStatus(evt, status, opts...)
+}
+
+func OrphanTxLogEvent(evt *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.OrphanTxLogEvent(evt, opts...)
+}
+
+func UpgradeTxLogStatus(evt *nostr.Event, checkpoint *nostr.Event) (*nostr.Event, error) {
+	return event.UpgradeTxLogStatus(evt, checkpoint)
+}
+
+func UpgradeTxLogStatuses(events []*nostr.Event, checkpoint *nostr.Event) ([]*nostr.Event, error) {
+	return event.UpgradeTxLogStatuses(events, checkpoint)
+}
+
+// Re-export reconciliation package types
+type ReconciliationReport = event.ReconciliationReport
+type AddressReconciliation = event.AddressReconciliation
+type ReconciliationReportEvent = event.ReconciliationReportEvent
+
+// Re-export reconciliation package constants
+const (
+	KindReconciliationReport = event.KindReconciliationReport
+)
+
+// Re-export reconciliation package functions
+func CreateReconciliationReportEvent(report event.ReconciliationReport) (*nostr.Event, error) {
+	return event.CreateReconciliationReportEvent(report)
+}
+
+func ParseReconciliationReportEvent(evt *nostr.Event) (*event.ReconciliationReportEvent, error) {
+	return event.ParseReconciliationReportEvent(evt)
+}
+
+// Re-export parser limits
+const (
+	MaxContentSize  = event.MaxContentSize
+	MaxContentDepth = event.MaxContentDepth
+	MaxTags         = event.MaxTags
+	MaxTagSize      = event.MaxTagSize
+)
+
+var ErrMalformedEvent = event.ErrMalformedEvent
+
+func CheckEvent(evt *nostr.Event) error {
+	return event.CheckEvent(evt)
+}
+
+// Re-export kind registry types
+type KindSpec = event.KindSpec
+type KindCategory = event.KindCategory
+
+// Re-export kind registry constants
+const (
+	KindCategoryChain              = event.KindCategoryChain
+	KindCategoryAccountAbstraction = event.KindCategoryAccountAbstraction
+	KindCategoryGroup              = event.KindCategoryGroup
+	KindCategoryMessage            = event.KindCategoryMessage
+	KindCategoryOperations         = event.KindCategoryOperations
+)
+
+var ErrUnknownKind = event.ErrUnknownKind
+
+// Re-export kind registry functions
+func KindInfo(kind int) (event.KindSpec, bool) {
+	return event.KindInfo(kind)
+}
+
+func IsKnownKind(kind int) bool {
+	return event.IsKnownKind(kind)
+}
+
+func Kinds() []event.KindSpec {
+	return event.Kinds()
+}
+
+func ParseEvent(evt *nostr.Event) (any, error) {
+	return event.ParseEvent(evt)
+}
+
+// Re-export tag namespace constants
+const TagNamespaceSeparator = event.TagNamespaceSeparator
+
+// Re-export tag namespace functions
+func SetTagNamespace(namespace string, compat bool) {
+	event.SetTagNamespace(namespace, compat)
+}
+
+func TagNamespace() string {
+	return event.TagNamespace()
+}
+
+func NamespacedTag(value string) string {
+	return event.NamespacedTag(value)
+}
+
+func TypeTagValue(value string) string {
+	return event.TypeTagValue(value)
+}
+
+func HasTypeTag(evt *nostr.Event, value string) bool {
+	return event.HasTypeTag(evt, value)
+}
+
+func TypeTagValues(values ...string) []string {
+	return event.TypeTagValues(values...)
+}
+
+// Re-export group identifier types
+type GroupAddress = event.GroupAddress
+type GroupIdentifierFormat = event.GroupIdentifierFormat
+
+// Re-export group identifier constants
+const (
+	GroupIdentifierHost  = event.GroupIdentifierHost
+	GroupIdentifierNaddr = event.GroupIdentifierNaddr
+)
+
+// Re-export NIP-29 group types
+type GroupKindMode = event.GroupKindMode
+type GroupAdmin = event.GroupAdmin
+type GroupRole = event.GroupRole
+type GroupAdminsListEvent = event.GroupAdminsListEvent
+type GroupMembersEvent = event.GroupMembersEvent
+type GroupRolesEvent = event.GroupRolesEvent
+
+// Re-export NIP-29 group constants
+const (
+	KindGroupAdminsList = event.KindGroupAdminsList
+	KindGroupMembers    = event.KindGroupMembers
+	KindGroupRoles      = event.KindGroupRoles
+
+	GroupKindsLegacy = event.GroupKindsLegacy
+	GroupKindsNIP29  = event.GroupKindsNIP29
+)
+
+// Re-export NIP-29 group errors
+var ErrLegacyGroupKind = event.ErrLegacyGroupKind
+
+// Re-export NIP-29 group functions
+func SetGroupKindMode(mode event.GroupKindMode) {
+	event.SetGroupKindMode(mode)
+}
+
+func CurrentGroupKindMode() event.GroupKindMode {
+	return event.CurrentGroupKindMode()
+}
+
+func IsNIP29GroupEvent(evt *nostr.Event) bool {
+	return event.IsNIP29GroupEvent(evt)
+}
+
+func CreateGroupAdminsListEvent(groupID string, admins []event.GroupAdmin) (*nostr.Event, error) {
+	return event.CreateGroupAdminsListEvent(groupID, admins)
+}
+
+func CreateGroupMembersEvent(groupID string, members []string) (*nostr.Event, error) {
+	return event.CreateGroupMembersEvent(groupID, members)
+}
+
+func CreateGroupRolesEvent(groupID string, roles []event.GroupRole) (*nostr.Event, error) {
+	return event.CreateGroupRolesEvent(groupID, roles)
+}
+
+func ParseGroupAdminsListEvent(evt *nostr.Event) (*event.GroupAdminsListEvent, error) {
+	return event.ParseGroupAdminsListEvent(evt)
+}
+
+func ParseGroupMembersEvent(evt *nostr.Event) (*event.GroupMembersEvent, error) {
+	return event.ParseGroupMembersEvent(evt)
+}
+
+func ParseGroupRolesEvent(evt *nostr.Event) (*event.GroupRolesEvent, error) {
+	return event.ParseGroupRolesEvent(evt)
+}
+
+// Re-export relay hint types
+type RelayHintProvider = event.RelayHintProvider
+type RelayHints = event.RelayHints
+type ReferenceOption = event.ReferenceOption
+
+// Re-export relay hint functions
+func SetRelayHintProvider(provider event.RelayHintProvider) {
+	event.SetRelayHintProvider(provider)
+}
+
+func WithRelayHint(relayURL string) event.ReferenceOption {
+	return event.WithRelayHint(relayURL)
+}
+
+func WithRelayHintProvider(provider event.RelayHintProvider) event.ReferenceOption {
+	return event.WithRelayHintProvider(provider)
+}
+
+// Re-export nostr URI types
+type NostrReference = event.NostrReference
+type EventQuerier = event.EventQuerier
+
+// Re-export nostr URI functions
+func ExtractNostrURIs(content string) []event.NostrReference {
+	return event.ExtractNostrURIs(content)
+}
+
+func HydrateNostrURIs(ctx context.Context, querier event.EventQuerier, content string) (map[string]*nostr.Event, error) {
+	return event.HydrateNostrURIs(ctx, querier, content)
+}
+
+// Re-export mention functions
+func WithMentions(pubkeys ...string) event.ReferenceOption {
+	return event.WithMentions(pubkeys...)
+}
+
+func GetMentionedPubkeys(evt *nostr.Event) []string {
+	return event.GetMentionedPubkeys(evt)
+}
+
+// Re-export tip types
+type Reaction = event.Reaction
+type TipIntent = event.TipIntent
+type TipStatus = event.TipStatus
+type TipEvent = event.TipEvent
+type TipTransferEvent = event.TipTransferEvent
+
+// Re-export tip constants
+const (
+	KindReaction       = event.KindReaction
+	KindTipTransfer    = event.KindTipTransfer
+	DefaultTipReaction = event.DefaultTipReaction
+
+	TipStatusPending   = event.TipStatusPending
+	TipStatusConfirmed = event.TipStatusConfirmed
+)
+
+// Re-export tip functions
+func CreateTipEvent(message *nostr.Event, reaction event.Reaction, intent event.TipIntent, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateTipEvent(message, reaction, intent, opts...)
+}
+
+func IsTipEvent(evt *nostr.Event) bool {
+	return event.IsTipEvent(evt)
+}
+
+func ParseTipEvent(evt *nostr.Event) (*event.TipEvent, error) {
+	return event.ParseTipEvent(evt)
+}
+
+func CreateTipTransferEvent(tip *nostr.Event, transfer *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateTipTransferEvent(tip, transfer, opts...)
+}
+
+func ParseTipTransferEvent(evt *nostr.Event) (*event.TipTransferEvent, error) {
+	return event.ParseTipTransferEvent(evt)
+}
+
+// Re-export token gate types
+type GateRule = neth.GateRule
+type GateRuleType = neth.GateRuleType
+type TokenReader = neth.TokenReader
+type RPCTokenReader = neth.RPCTokenReader
+type GateMatch = event.GateMatch
+type TokenGateEvent = event.TokenGateEvent
+
+// Re-export token gate constants
+const (
+	KindGroupTokenGate = event.KindGroupTokenGate
+
+	GateRuleTokenBalance = neth.GateRuleTokenBalance
+	GateRuleNFTOwnership = neth.GateRuleNFTOwnership
+
+	GateMatchAny = event.GateMatchAny
+	GateMatchAll = event.GateMatchAll
+)
+
+// Re-export token gate errors
+var ErrNotEligible = event.ErrNotEligible
+
+// Re-export token gate functions
+func NewRPCTokenReader(client *http.Client, url string) *neth.RPCTokenReader {
+	return neth.NewRPCTokenReader(client, url)
+}
+
+func CreateTokenGateEvent(groupID string, match event.GateMatch, rules ...neth.GateRule) (*nostr.Event, error) {
+	return event.CreateTokenGateEvent(groupID, match, rules...)
+}
+
+func ParseTokenGateEvent(evt *nostr.Event) (*event.TokenGateEvent, error) {
+	return event.ParseTokenGateEvent(evt)
+}
+
+func VerifyGateEligibility(ctx context.Context, gate *event.TokenGateEvent, readers map[string]neth.TokenReader, address common.Address) error {
+	return event.VerifyGateEligibility(ctx, gate, readers, address)
+}
+
+// Re-export governance types
+type BalanceProof = neth.BalanceProof
+type VotingStrategy = event.VotingStrategy
+type ProposalEvent = event.ProposalEvent
+type VoteEvent = event.VoteEvent
+type RejectedVote = event.RejectedVote
+type TallyResult = event.TallyResult
+type TallyOption = event.TallyOption
+type Call = neth.Call
+type ProposalExecutionEvent = event.ProposalExecutionEvent
+
+// Re-export governance constants
+const (
+	KindProposal = event.KindProposal
+	KindVote     = event.KindVote
+
+	KindProposalExecution = event.KindProposalExecution
+
+	VotingStrategySingle       = event.VotingStrategySingle
+	VotingStrategyTokenBalance = event.VotingStrategyTokenBalance
+)
+
+// Re-export governance functions
+func BalanceStorageKey(holder common.Address, slot uint64) common.Hash {
+	return neth.BalanceStorageKey(holder, slot)
+}
+
+func CreateProposalEvent(proposal event.ProposalEvent) (*nostr.Event, error) {
+	return event.CreateProposalEvent(proposal)
+}
+
+func ParseProposalEvent(evt *nostr.Event) (*event.ProposalEvent, error) {
+	return event.ParseProposalEvent(evt)
+}
+
+func VoteAddressMessage(proposalID, pubkey string) []byte {
+	return event.VoteAddressMessage(proposalID, pubkey)
+}
+
+func CreateVoteEvent(proposal *nostr.Event, vote event.VoteEvent, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateVoteEvent(proposal, vote, opts...)
+}
+
+func ParseVoteEvent(evt *nostr.Event) (*event.VoteEvent, error) {
+	return event.ParseVoteEvent(evt)
+}
+
+func Tally(proposal *nostr.Event, votes []*nostr.Event, opts ...event.TallyOption) (*event.TallyResult, error) {
+	return event.Tally(proposal, votes, opts...)
+}
+
+func WithSnapshotBlockHash(hash common.Hash) event.TallyOption {
+	return event.WithSnapshotBlockHash(hash)
+}
+
+func DecodeExecuteCalls(callData []byte) ([]neth.Call, error) {
+	return neth.DecodeExecuteCalls(callData)
+}
+
+func CreateProposalExecutionEvent(proposal *nostr.Event, result *event.TallyResult, execution event.ProposalExecutionEvent, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateProposalExecutionEvent(proposal, result, execution, opts...)
+}
+
+func ParseProposalExecutionEvent(evt *nostr.Event) (*event.ProposalExecutionEvent, error) {
+	return event.ParseProposalExecutionEvent(evt)
+}
+
+func VerifyProposalExecution(proposal, execution *nostr.Event, executed []neth.Call) error {
+	return event.VerifyProposalExecution(proposal, execution, executed)
+}
+
+func VerifyUserOpExecution(proposal, execution, userOp *nostr.Event) error {
+	return event.VerifyUserOpExecution(proposal, execution, userOp)
+}
+
+// Re-export escrow types
+type EscrowStatus = event.EscrowStatus
+type EscrowAction = event.EscrowAction
+type EscrowParty = event.EscrowParty
+type EscrowProposalEvent = event.EscrowProposalEvent
+type EscrowFundingEvent = event.EscrowFundingEvent
+type EscrowSignalEvent = event.EscrowSignalEvent
+
+// Re-export escrow constants
+const (
+	KindEscrowProposal = event.KindEscrowProposal
+	KindEscrowFunding  = event.KindEscrowFunding
+	KindEscrowSignal   = event.KindEscrowSignal
+
+	EscrowStatusProposed = event.EscrowStatusProposed
+	EscrowStatusFunded   = event.EscrowStatusFunded
+	EscrowStatusDisputed = event.EscrowStatusDisputed
+	EscrowStatusReleased = event.EscrowStatusReleased
+	EscrowStatusRefunded = event.EscrowStatusRefunded
+
+	EscrowActionRelease = event.EscrowActionRelease
+	EscrowActionRefund  = event.EscrowActionRefund
+	EscrowActionDispute = event.EscrowActionDispute
+)
+
+// Re-export escrow functions
+func CreateEscrowProposalEvent(escrow event.EscrowProposalEvent, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateEscrowProposalEvent(escrow, opts...)
+}
+
+func ParseEscrowProposalEvent(evt *nostr.Event) (*event.EscrowProposalEvent, error) {
+	return event.ParseEscrowProposalEvent(evt)
+}
+
+func CreateEscrowFundingEvent(escrow *nostr.Event, txLog *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateEscrowFundingEvent(escrow, txLog, opts...)
+}
+
+func ParseEscrowFundingEvent(evt *nostr.Event) (*event.EscrowFundingEvent, error) {
+	return event.ParseEscrowFundingEvent(evt)
+}
+
+func CreateEscrowSignalEvent(escrow *nostr.Event, action event.EscrowAction, reason, txHash string, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateEscrowSignalEvent(escrow, action, reason, txHash, opts...)
+}
+
+func ParseEscrowSignalEvent(evt *nostr.Event) (*event.EscrowSignalEvent, error) {
+	return event.ParseEscrowSignalEvent(evt)
+}
+
+func ComputeEscrowStatus(escrow *nostr.Event, events []*nostr.Event) (event.EscrowStatus, error) {
+	return event.ComputeEscrowStatus(escrow, events)
+}
+
+// Re-export split types
+type Split = neth.Split
+
+// Re-export split constants
+const SplitDenominator = neth.SplitDenominator
+
+// Re-export split functions
+func WithSplits(splits ...neth.Split) event.LogOption {
+	return event.WithSplits(splits...)
+}
+
+func ValidateSplits(splits []neth.Split) error {
+	return neth.ValidateSplits(splits)
+}
+
+func SplitAmounts(total *big.Int, splits []neth.Split) ([]*big.Int, error) {
+	return neth.SplitAmounts(total, splits)
+}
+
+func GetSplitsFromEvent(evt *nostr.Event) ([]neth.Split, error) {
+	return event.GetSplitsFromEvent(evt)
+}
+
+func VerifyTransferSplits(transfer *nostr.Event, batch []*nostr.Event) error {
+	return event.VerifyTransferSplits(transfer, batch)
+}
+
+// Re-export fiat rate types
+type FiatRate = neth.FiatRate
+type RateProvider = neth.RateProvider
+type StaticRateProvider = neth.StaticRateProvider
+type ChainlinkFeed = neth.ChainlinkFeed
+type ChainlinkRateProvider = neth.ChainlinkRateProvider
+type FiatValue = event.FiatValue
+
+// Re-export fiat rate errors
+var ErrNoRate = neth.ErrNoRate
+
+// Re-export fiat rate functions
+func NewStaticRateProvider() *neth.StaticRateProvider {
+	return neth.NewStaticRateProvider()
+}
+
+func NewChainlinkRateProvider(client *http.Client, url string) *neth.ChainlinkRateProvider {
+	return neth.NewChainlinkRateProvider(client, url)
+}
+
+func WithRateProvider(provider neth.RateProvider) event.LogOption {
+	return event.WithRateProvider(provider)
+}
+
+func GetFiatValueFromEvent(evt *nostr.Event) (*event.FiatValue, error) {
+	return event.GetFiatValueFromEvent(evt)
+}
+
+// Re-export address book types
+type Payee = event.Payee
+type AddressBook = event.AddressBook
+
+// Re-export address book constants
+const (
+	KindAddressBook    = event.KindAddressBook
+	DefaultAddressBook = event.DefaultAddressBook
+)
+
+// Re-export address book functions
+func CreateAddressBookEvent(book event.AddressBook, privateKey string) (*nostr.Event, error) {
+	return event.CreateAddressBookEvent(book, privateKey)
+}
+
+func ParseAddressBookEvent(evt *nostr.Event, privateKey string) (*event.AddressBook, error) {
+	return event.ParseAddressBookEvent(evt, privateKey)
+}
+
+// Re-export receipt types
+type ReceiptConfig = event.ReceiptConfig
+type ReceiptAttribute = event.ReceiptAttribute
+type ReceiptMetadata = event.ReceiptMetadata
+type ReceiptNFT = event.ReceiptNFT
+
+// Re-export receipt constants
+const (
+	KindFileMetadata   = event.KindFileMetadata
+	DefaultReceiptName = event.DefaultReceiptName
+)
+
+// Re-export receipt functions
+func ReceiptTokenID(chainID, logHash string) *big.Int {
+	return event.ReceiptTokenID(chainID, logHash)
+}
+
+func ReceiptTokenURI(baseURI string, tokenID *big.Int) string {
+	return event.ReceiptTokenURI(baseURI, tokenID)
+}
+
+func CreateReceiptNFT(transfer *nostr.Event, config event.ReceiptConfig) (*event.ReceiptNFT, error) {
+	return event.CreateReceiptNFT(transfer, config)
+}
+
+func CreateReceiptFileEvent(transfer *nostr.Event, receipt *event.ReceiptNFT, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateReceiptFileEvent(transfer, receipt, opts...)
+}
+
+// Re-export multi-relay read types
+type MultiReader = event.MultiReader
+type MultiReadResult = event.MultiReadResult
+
+// Re-export multi-relay read functions
+func NewMultiReader(relays map[string]event.EventQuerier) *event.MultiReader {
+	return event.NewMultiReader(relays)
+}
+
+func NewPoolMultiReader(pool *nostr.SimplePool, urls []string) *event.MultiReader {
+	return event.NewPoolMultiReader(pool, urls)
+}
+
+// Re-export relay list types
+type RelayList = event.RelayList
+type RelayListResolver = event.RelayListResolver
+
+// Re-export relay list constants
+const (
+	KindRelayList          = event.KindRelayList
+	DefaultRelayListTTL    = event.DefaultRelayListTTL
+	MaxOutboxRelaysPerUser = event.MaxOutboxRelaysPerUser
+)
+
+// Re-export relay list functions
+func ParseRelayListEvent(evt *nostr.Event) (*event.RelayList, error) {
+	return event.ParseRelayListEvent(evt)
+}
+
+func NewRelayListResolver(querier event.EventQuerier, ttl time.Duration) *event.RelayListResolver {
+	return event.NewRelayListResolver(querier, ttl)
+}
+
+// Re-export transfer CSV types
+type TransferCSVOptions = event.TransferCSVOptions
+
+// Re-export transfer CSV functions
+func ExportTransfersCSV(w io.Writer, events []*nostr.Event, opts event.TransferCSVOptions) (int, error) {
+	return event.ExportTransfersCSV(w, events, opts)
+}
+
+// Re-export balance snapshot types
+type BalanceSnapshot = event.BalanceSnapshot
+type BalanceSnapshotEvent = event.BalanceSnapshotEvent
+type EventTypeSnapshot = event.EventTypeSnapshot
+
+// Re-export balance snapshot constants
+const (
+	KindBalanceSnapshot      = event.KindBalanceSnapshot
+	EventTypeBalanceSnapshot = event.EventTypeBalanceSnapshot
+)
+
+// Re-export balance snapshot functions
+func CreateBalanceSnapshotEvent(snapshot event.BalanceSnapshot) (*nostr.Event, error) {
+	return event.CreateBalanceSnapshotEvent(snapshot)
+}
+
+func ParseBalanceSnapshotEvent(evt *nostr.Event) (*event.BalanceSnapshotEvent, error) {
+	return event.ParseBalanceSnapshotEvent(evt)
+}
+
+func BalanceSnapshotID(chainID, token, address string) string {
+	return event.BalanceSnapshotID(chainID, token, address)
+}
+
+func LatestBalanceSnapshot(ctx context.Context, querier event.EventQuerier, chainID, token, address string) (*event.BalanceSnapshotEvent, error) {
+	return event.LatestBalanceSnapshot(ctx, querier, chainID, token, address)
+}
+
+// Re-export alert types
+type AlertEvent = event.AlertEvent
+type AlertAcknowledgementEvent = event.AlertAcknowledgementEvent
+type AlertSeverity = event.AlertSeverity
+
+// Re-export alert constants
+const (
+	KindAlert                = event.KindAlert
+	KindAlertAcknowledgement = event.KindAlertAcknowledgement
+	AlertSeverityInfo        = event.AlertSeverityInfo
+	AlertSeverityWarning     = event.AlertSeverityWarning
+	AlertSeverityCritical    = event.AlertSeverityCritical
+)
+
+// Re-export alert functions
+func CreateAlertEvent(rule string, severity event.AlertSeverity, message string, subject *nostr.Event, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateAlertEvent(rule, severity, message, subject, opts...)
+}
+
+func ParseAlertEvent(evt *nostr.Event) (*event.AlertEvent, error) {
+	return event.ParseAlertEvent(evt)
+}
+
+func CreateAlertAcknowledgementEvent(alert *nostr.Event, note string, opts ...event.ReferenceOption) (*nostr.Event, error) {
+	return event.CreateAlertAcknowledgementEvent(alert, note, opts...)
+}
+
+func ParseAlertAcknowledgementEvent(evt *nostr.Event) (*event.AlertAcknowledgementEvent, error) {
+	return event.ParseAlertAcknowledgementEvent(evt)
+}
+
+func UnacknowledgedAlerts(events []*nostr.Event) []*nostr.Event {
+	return event.UnacknowledgedAlerts(events)
+}
+
+func QueryUnacknowledgedAlerts(ctx context.Context, querier event.EventQuerier, filter nostr.Filter) ([]*nostr.Event, error) {
+	return event.QueryUnacknowledgedAlerts(ctx, querier, filter)
+}
+
+// Re-export formatter types
+type Formatter = event.Formatter
+type FormatTarget = event.FormatTarget
+type FormatData = event.FormatData
+
+// Re-export formatter constants
+const (
+	FormatAlt       = event.FormatAlt
+	FormatMessage   = event.FormatMessage
+	FormatPushTitle = event.FormatPushTitle
+	FormatPushBody  = event.FormatPushBody
+)
+
+// Re-export formatter variables
+var DefaultFormatter = event.DefaultFormatter
+
+// Re-export formatter functions
+func NewFormatter(tokens *event.TokenRegistry) *event.Formatter {
+	return event.NewFormatter(tokens)
+}
+
+// Re-export localization types
+type Catalog = event.Catalog
+
+// Re-export localization constants
+const (
+	DefaultLocale             = event.DefaultLocale
+	MessageTxLogAlt           = event.MessageTxLogAlt
+	MessageTxLogAltEvent      = event.MessageTxLogAltEvent
+	MessageTxLogAltValue      = event.MessageTxLogAltValue
+	MessageTxLogStatusAlt     = event.MessageTxLogStatusAlt
+	MessageTxLogStatusAltAt   = event.MessageTxLogStatusAltAt
+	MessageAltData            = event.MessageAltData
+	MessageUserOpAlt          = event.MessageUserOpAlt
+	MessageUserOpUpdateAlt    = event.MessageUserOpUpdateAlt
+	MessageUserOpAltFailure   = event.MessageUserOpAltFailure
+	MessageUserOpAltPaymaster = event.MessageUserOpAltPaymaster
+	MessageTransferReceived   = event.MessageTransferReceived
+)
+
+// Re-export localization variables
+var DefaultCatalog = event.DefaultCatalog
+
+// Re-export localization functions
+func NewCatalog() *event.Catalog {
+	return event.NewCatalog()
+}
+
+func SetLocale(locale string) {
+	event.SetLocale(locale)
+}
+
+func Locale() string {
+	return event.Locale()
+}
+
+// Re-export sanitization types
+type SanitizePolicy = event.SanitizePolicy
+
+// Re-export sanitization variables
+var DefaultSanitizePolicy = event.DefaultSanitizePolicy
+
+// Re-export sanitization functions
+func SetSanitizePolicy(policy event.SanitizePolicy) {
+	event.SetSanitizePolicy(policy)
+}
+
+func CurrentSanitizePolicy() event.SanitizePolicy {
+	return event.CurrentSanitizePolicy()
+}
+
+func WithSanitizePolicy(policy event.SanitizePolicy) event.LogOption {
+	return event.WithSanitizePolicy(policy)
+}
+
+func SanitizeText(text string, max int, multiline bool) string {
+	return event.SanitizeText(text, max, multiline)
+}
+
+// Re-export flattening types
+type FlattenPolicy = event.FlattenPolicy
+
+// Re-export flattening constants
+const NestedKeySeparator = event.NestedKeySeparator
+
+// Re-export flattening functions
+func SetFlattenPolicy(policy event.FlattenPolicy) {
+	event.SetFlattenPolicy(policy)
+}
+
+func CurrentFlattenPolicy() event.FlattenPolicy {
+	return event.CurrentFlattenPolicy()
+}
+
+func WithFlattenPolicy(policy event.FlattenPolicy) event.LogOption {
+	return event.WithFlattenPolicy(policy)
+}
+
+func IsReservedTagName(name string) bool {
+	return event.IsReservedTagName(name)
+}
+
+// Re-export curated topic constants
+const (
+	TopicERC721Transfer             = neth.TopicERC721Transfer
+	TopicERC721Approval             = neth.TopicERC721Approval
+	TopicApprovalForAll             = neth.TopicApprovalForAll
+	TopicERC1155Single              = neth.TopicERC1155Single
+	TopicERC1155Batch               = neth.TopicERC1155Batch
+	TopicERC1155URI                 = neth.TopicERC1155URI
+	TopicUserOperationEvent         = neth.TopicUserOperationEvent
+	TopicAccountDeployed            = neth.TopicAccountDeployed
+	TopicUserOperationRevertReason  = neth.TopicUserOperationRevertReason
+	TopicPostOpRevertReason         = neth.TopicPostOpRevertReason
+	TopicBeforeExecution            = neth.TopicBeforeExecution
+	TopicEntryPointDeposited        = neth.TopicEntryPointDeposited
+	TopicEntryPointWithdrawn        = neth.TopicEntryPointWithdrawn
+	TopicSafeSetup                  = neth.TopicSafeSetup
+	TopicSafeExecutionSuccess       = neth.TopicSafeExecutionSuccess
+	TopicSafeExecutionFailure       = neth.TopicSafeExecutionFailure
+	TopicSafeAddedOwner             = neth.TopicSafeAddedOwner
+	TopicSafeRemovedOwner           = neth.TopicSafeRemovedOwner
+	TopicSafeChangedThreshold       = neth.TopicSafeChangedThreshold
+	TopicSafeReceived               = neth.TopicSafeReceived
+	TopicSafeEnabledModule          = neth.TopicSafeEnabledModule
+	TopicSafeDisabledModule         = neth.TopicSafeDisabledModule
+	TopicSafeModuleExecutionSuccess = neth.TopicSafeModuleExecutionSuccess
+	TopicSafeModuleExecutionFailure = neth.TopicSafeModuleExecutionFailure
+)
+
+// Re-export curated topic functions
+func TopicName(topic string) (string, bool) {
+	return neth.TopicName(topic)
+}
+
+func NameToTopic(name string) (string, bool) {
+	return neth.NameToTopic(name)
+}
+
+func EventTopic(signature string) string {
+	return neth.EventTopic(signature)
+}
+
+func TopicTagValues(topics ...string) ([]string, error) {
+	return event.TopicTagValues(topics...)
+}
+
+// Re-export permit types
+type Permit = event.Permit
+type PermitSource = event.PermitSource
+type TxPermitEvent = event.TxPermitEvent
+type PermitCall = neth.PermitCall
+
+// Re-export permit constants
+const (
+	KindTxPermit             = event.KindTxPermit
+	EventTypeTxPermitCreated = event.EventTypeTxPermitCreated
+	PermitSourceEIP2612      = event.PermitSourceEIP2612
+	PermitSourcePermit2      = event.PermitSourcePermit2
+	Permit2Address           = neth.Permit2Address
+
+	TopicPermit2Approval          = neth.TopicPermit2Approval
+	TopicPermit2Permit            = neth.TopicPermit2Permit
+	TopicPermit2Lockdown          = neth.TopicPermit2Lockdown
+	TopicPermit2NonceInvalidation = neth.TopicPermit2NonceInvalidation
+)
+
+// Re-export permit functions
+func GetPermitFromLog(log neth.Log) (*event.Permit, error) {
+	return event.GetPermitFromLog(log)
+}
+
+func GetPermitFromCall(chainID, token, txHash string, callData []byte, at time.Time) (*event.Permit, error) {
+	return event.GetPermitFromCall(chainID, token, txHash, callData, at)
+}
+
+func CreateTxPermitEvent(permit event.Permit, opts ...event.LogOption) (*nostr.Event, error) {
+	return event.CreateTxPermitEvent(permit, opts...)
+}
+
+func ParseTxPermitEvent(evt *nostr.Event) (*event.TxPermitEvent, error) {
+	return event.ParseTxPermitEvent(evt)
+}
+
+func DecodePermitCall(callData []byte) (*neth.PermitCall, error) {
+	return neth.DecodePermitCall(callData)
+}
+
+// Re-export stablecoin types and variables
+type Stablecoin = neth.Stablecoin
+type StableAmount = event.StableAmount
+
+var KnownStablecoins = neth.KnownStablecoins
+
+// Re-export stablecoin functions
+func GetStableAmountFromEvent(evt *nostr.Event) (*event.StableAmount, error) {
+	return event.GetStableAmountFromEvent(evt)
+}
+
+// Re-export dust types and functions
+type DustThresholds = event.DustThresholds
+
+func NewDustThresholds() *event.DustThresholds {
+	return event.NewDustThresholds()
+}
+
+func WithDustThresholds(thresholds *event.DustThresholds) event.LogOption {
+	return event.WithDustThresholds(thresholds)
+}
+
+// Re-export kind map types and functions
+type KindMap = event.KindMap
+
+func SetKindMap(m event.KindMap) error {
+	return event.SetKindMap(m)
+}
+
+func CurrentKindMap() event.KindMap {
+	return event.CurrentKindMap()
+}
+
+func MappedKind(kind int) int {
+	return event.MappedKind(kind)
+}
+
+func MappedKinds(kinds ...int) []int {
+	return event.MappedKinds(kinds...)
+}
+
+func DefaultKind(kind int) int {
+	return event.DefaultKind(kind)
+}
+
+// Re-export relay limit types and variables
+type RelayLimits = event.RelayLimits
+
+var ErrRelayLimit = event.ErrRelayLimit
+
+// Re-export relay limit functions
+func RelayLimitsFromNIP11(info nip11.RelayInformationDocument) event.RelayLimits {
+	return event.RelayLimitsFromNIP11(info)
+}
+
+func EstimateSize(evt *nostr.Event) int {
+	return event.EstimateSize(evt)
+}
+
+func ValidateForRelay(evt *nostr.Event, limits event.RelayLimits) error {
+	return event.ValidateForRelay(evt, limits)
+}
+
+// Re-export admin command types
+type AdminCommandEvent = event.AdminCommandEvent
+type AdminAction = event.AdminAction
+
+// Re-export admin command constants
+const (
+	KindAdminCommand          = event.KindAdminCommand
+	AdminActionAddContract    = event.AdminActionAddContract
+	AdminActionRemoveContract = event.AdminActionRemoveContract
+	AdminActionMuteAddress    = event.AdminActionMuteAddress
+	AdminActionUnmuteAddress  = event.AdminActionUnmuteAddress
+	AdminActionAddRelay       = event.AdminActionAddRelay
+	AdminActionRemoveRelay    = event.AdminActionRemoveRelay
+	AdminActionRotateRelay    = event.AdminActionRotateRelay
+)
+
+// Re-export admin command functions
+func CreateAdminCommandEvent(bridgePubkey string, action event.AdminAction, value, replacement string) (*nostr.Event, error) {
+	return event.CreateAdminCommandEvent(bridgePubkey, action, value, replacement)
+}
+
+func ParseAdminCommandEvent(evt *nostr.Event) (*event.AdminCommandEvent, error) {
+	return event.ParseAdminCommandEvent(evt)
+}
+
+// Re-export audit record types
+type AuditRecord = event.AuditRecord
+type AuditOperation = event.AuditOperation
+
+// Re-export audit record constants
+const (
+	KindAuditRecord      = event.KindAuditRecord
+	AuditBackfillStarted = event.AuditBackfillStarted
+	AuditReorgHandled    = event.AuditReorgHandled
+	AuditConfigApplied   = event.AuditConfigApplied
+	AuditRelayRemoved    = event.AuditRelayRemoved
+)
+
+// Re-export audit record functions
+func CreateAuditRecordEvent(record event.AuditRecord) (*nostr.Event, error) {
+	return event.CreateAuditRecordEvent(record)
+}
+
+func ParseAuditRecordEvent(evt *nostr.Event) (*event.AuditRecord, error) {
+	return event.ParseAuditRecordEvent(evt)
+}
+
+func AuditTrail(events []*nostr.Event) []event.AuditRecord {
+	return event.AuditTrail(events)
+}
+
+// Re-export heartbeat types
+type Heartbeat = event.Heartbeat
+
+// Re-export heartbeat constants
+const (
+	KindHeartbeat            = event.KindHeartbeat
+	DefaultHeartbeatInstance = event.DefaultHeartbeatInstance
+)
+
+// Re-export heartbeat functions
+func CreateHeartbeatEvent(heartbeat event.Heartbeat) (*nostr.Event, error) {
+	return event.CreateHeartbeatEvent(heartbeat)
+}
+
+func ParseHeartbeatEvent(evt *nostr.Event) (*event.Heartbeat, error) {
+	return event.ParseHeartbeatEvent(evt)
+}
+
+func IsBridgeHealthy(events []*nostr.Event, maxAge time.Duration) bool {
+	return event.IsBridgeHealthy(events, maxAge)
+}
+
+// Re-export leader claim types
+type LeaderClaim = event.LeaderClaim
+
+// Re-export leader claim constants
+const (
+	KindLeaderClaim = event.KindLeaderClaim
+)
+
+// Re-export leader claim functions
+func CreateLeaderClaimEvent(claim event.LeaderClaim) (*nostr.Event, error) {
+	return event.CreateLeaderClaimEvent(claim)
+}
+
+func ParseLeaderClaimEvent(evt *nostr.Event) (*event.LeaderClaim, error) {
+	return event.ParseLeaderClaimEvent(evt)
+}
+
+func LeaderClaimID(group, instance string) string {
+	return event.LeaderClaimID(group, instance)
+}
+
+func CurrentLeader(events []*nostr.Event, group string) *event.LeaderClaim {
+	return event.CurrentLeader(events, group)
+}
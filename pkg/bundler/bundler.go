@@ -0,0 +1,380 @@
+package bundler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// DefaultEstimateTimeout is how long eth_estimateUserOperationGas waits for an estimator
+	DefaultEstimateTimeout = 10 * time.Second
+
+	// DefaultPollInterval is how often the relays are queried while waiting for an estimate
+	DefaultPollInterval = 500 * time.Millisecond
+)
+
+// Relays publishes events to and queries events from the relays of the bundler network
+type Relays interface {
+	Publish(ctx context.Context, evt nostr.Event) error
+	Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+}
+
+// Bridge translates ERC-4337 bundler RPC calls into Nostr user op events
+type Bridge struct {
+	chainID     *big.Int
+	entryPoints []common.Address
+	privateKey  string
+	relays      Relays
+	bundlers    []string // pubkeys trusted for the state of user ops
+	estimators  []string // pubkeys trusted for gas estimates
+
+	estimateTimeout time.Duration
+	pollInterval    time.Duration
+}
+
+// Option configures a Bridge
+type Option func(*Bridge)
+
+// WithEstimateTimeout sets how long gas estimates are waited for
+func WithEstimateTimeout(timeout time.Duration) Option {
+	return func(b *Bridge) {
+		b.estimateTimeout = timeout
+	}
+}
+
+// WithPollInterval sets how often the relays are queried while waiting for an estimate
+func WithPollInterval(interval time.Duration) Option {
+	return func(b *Bridge) {
+		b.pollInterval = interval
+	}
+}
+
+// WithBundlers sets the pubkeys of the bundlers whose user op updates are trusted, besides the
+// events of the bridge itself
+func WithBundlers(pubkeys ...string) Option {
+	return func(b *Bridge) {
+		b.bundlers = pubkeys
+	}
+}
+
+// WithEstimators sets the pubkeys of the estimators whose gas estimates are trusted, gas cannot
+// be estimated without them
+func WithEstimators(pubkeys ...string) Option {
+	return func(b *Bridge) {
+		b.estimators = pubkeys
+	}
+}
+
+// NewBridge creates a new bridge for a chain, the events it publishes are signed with the
+// private key
+func NewBridge(chainID *big.Int, entryPoints []common.Address, privateKey string, relays Relays, opts ...Option) *Bridge {
+	b := &Bridge{
+		chainID:         chainID,
+		entryPoints:     entryPoints,
+		privateKey:      privateKey,
+		relays:          relays,
+		estimateTimeout: DefaultEstimateTimeout,
+		pollInterval:    DefaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// GasEstimate is the result of eth_estimateUserOperationGas
+type GasEstimate struct {
+	PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+}
+
+// UserOperationByHash is the result of eth_getUserOperationByHash
+type UserOperationByHash struct {
+	UserOperation   *neth.UserOp    `json:"userOperation"`
+	EntryPoint      *common.Address `json:"entryPoint"`
+	TransactionHash *string         `json:"transactionHash"`
+	BlockHash       *string         `json:"blockHash"`
+	BlockNumber     *hexutil.Big    `json:"blockNumber"`
+	Status          string          `json:"status"`
+}
+
+// Call executes a bundler RPC method with JSON encoded positional params
+func (b *Bridge) Call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "eth_chainId":
+		return (*hexutil.Big)(b.chainID), nil
+	case "eth_supportedEntryPoints":
+		return b.entryPoints, nil
+	case "eth_sendUserOperation":
+		var userOp neth.UserOp
+		var entryPoint common.Address
+		if err := parseParams(params, &userOp, &entryPoint); err != nil {
+			return nil, err
+		}
+		return b.SendUserOperation(ctx, userOp, entryPoint)
+	case "eth_estimateUserOperationGas":
+		var userOp neth.UserOp
+		var entryPoint common.Address
+		if err := parseParams(params, &userOp, &entryPoint); err != nil {
+			return nil, err
+		}
+		return b.EstimateUserOperationGas(ctx, userOp, entryPoint)
+	case "eth_getUserOperationByHash":
+		var hash string
+		if err := parseParams(params, &hash); err != nil {
+			return nil, err
+		}
+		return b.GetUserOperationByHash(ctx, hash)
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %s not found", method)}
+	}
+}
+
+// SendUserOperation publishes a user op request event and returns the ERC-4337 user op hash
+func (b *Bridge) SendUserOperation(ctx context.Context, userOp neth.UserOp, entryPoint common.Address) (string, error) {
+	if err := b.checkEntryPoint(entryPoint); err != nil {
+		return "", err
+	}
+	if err := checkUserOp(userOp); err != nil {
+		return "", err
+	}
+
+	var paymaster *common.Address
+	if len(userOp.PaymasterAndData) >= common.AddressLength {
+		address := common.BytesToAddress(userOp.PaymasterAndData[:common.AddressLength])
+		paymaster = &address
+	}
+
+	evt, err := event.CreateUserOpEvent(b.chainID, paymaster, &entryPoint, nil, nil, 0, userOp, event.EventTypeUserOpRequested)
+	if err != nil {
+		return "", &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	if err := b.publish(ctx, evt); err != nil {
+		return "", err
+	}
+
+	return userOp.GetUserOpHash(entryPoint, b.chainID).Hex(), nil
+}
+
+// EstimateUserOperationGas publishes a gas estimate request and waits for one of the estimators
+// to answer
+func (b *Bridge) EstimateUserOperationGas(ctx context.Context, userOp neth.UserOp, entryPoint common.Address) (*GasEstimate, error) {
+	if err := b.checkEntryPoint(entryPoint); err != nil {
+		return nil, err
+	}
+	if err := checkUserOp(userOp); err != nil {
+		return nil, err
+	}
+	if len(b.estimators) == 0 {
+		return nil, &Error{Code: CodeInternalError, Message: "no gas estimators are configured"}
+	}
+
+	request, err := event.CreateGasEstimateRequestEvent(b.chainID, &entryPoint, userOp, "")
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	if err := b.publish(ctx, request); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.estimateTimeout)
+	defer cancel()
+
+	filter := nostr.Filter{
+		Kinds:   event.MappedKinds(event.KindGasEstimateResponse),
+		Authors: b.estimators,
+		Tags:    nostr.TagMap{"e": []string{request.ID}},
+	}
+
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := b.relays.Query(ctx, filter)
+		if err == nil {
+			for _, evt := range events {
+				if ok, _ := evt.CheckSignature(); !ok {
+					continue
+				}
+				response, err := event.ParseGasEstimateResponseEvent(evt)
+				if err != nil || !response.Estimate.IsValid(time.Now()) {
+					continue
+				}
+
+				return &GasEstimate{
+					PreVerificationGas:   response.Estimate.PreVerificationGas,
+					VerificationGasLimit: response.Estimate.VerificationGasLimit,
+					CallGasLimit:         response.Estimate.CallGasLimit,
+				}, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &Error{Code: CodeInternalError, Message: "timed out waiting for a gas estimate"}
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetUserOperationByHash returns the latest state of a user op by its ERC-4337 hash, as published
+// by the bridge or one of the bundlers, nil when it is not known
+func (b *Bridge) GetUserOperationByHash(ctx context.Context, hash string) (*UserOperationByHash, error) {
+	authors := b.bundlers
+	if b.privateKey != "" {
+		pubkey, err := nostr.GetPublicKey(b.privateKey)
+		if err != nil {
+			return nil, err
+		}
+		authors = append([]string{pubkey}, authors...)
+	}
+	if len(authors) == 0 {
+		return nil, nil
+	}
+
+	events, err := b.relays.Query(ctx, nostr.Filter{
+		Kinds:   event.MappedKinds(event.EventUserOpKind),
+		Authors: authors,
+		Tags:    nostr.TagMap{"r": []string{hash}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *nostr.Event
+	var userOpEvent *event.UserOpEvent
+	for _, evt := range events {
+		if layer := evt.Tags.Find("layer"); layer == nil || layer[1] != b.chainID.String() {
+			continue
+		}
+		if latest != nil && evt.CreatedAt <= latest.CreatedAt {
+			continue
+		}
+		if ok, _ := evt.CheckSignature(); !ok {
+			continue
+		}
+
+		// The tag is only trusted when it is the hash of the user op in the event
+		parsed, err := event.ParseUserOpEvent(evt)
+		if err != nil || parsed.EntryPoint == nil || checkUserOp(parsed.UserOpData) != nil {
+			continue
+		}
+		if !strings.EqualFold(parsed.UserOpData.GetUserOpHash(*parsed.EntryPoint, b.chainID).Hex(), hash) {
+			continue
+		}
+
+		latest, userOpEvent = evt, parsed
+	}
+
+	if latest == nil {
+		return nil, nil
+	}
+
+	return &UserOperationByHash{
+		UserOperation:   &userOpEvent.UserOpData,
+		EntryPoint:      userOpEvent.EntryPoint,
+		TransactionHash: userOpEvent.TxHash,
+		Status:          string(userOpEvent.EventType),
+	}, nil
+}
+
+// checkEntryPoint checks that the entry point is supported by the bridge
+func (b *Bridge) checkEntryPoint(entryPoint common.Address) error {
+	for _, supported := range b.entryPoints {
+		if supported == entryPoint {
+			return nil
+		}
+	}
+	return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unsupported entry point %s", entryPoint.Hex())}
+}
+
+// checkUserOp checks that the numeric fields of a user op are set, they are left nil when missing
+// or malformed in the request, e.g. "0x00"
+func checkUserOp(userOp neth.UserOp) error {
+	fields := []struct {
+		name  string
+		value *big.Int
+	}{
+		{"nonce", userOp.Nonce},
+		{"callGasLimit", userOp.CallGasLimit},
+		{"verificationGasLimit", userOp.VerificationGasLimit},
+		{"preVerificationGas", userOp.PreVerificationGas},
+		{"maxFeePerGas", userOp.MaxFeePerGas},
+		{"maxPriorityFeePerGas", userOp.MaxPriorityFeePerGas},
+	}
+
+	for _, field := range fields {
+		if field.value == nil {
+			return &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("missing or invalid %s", field.name)}
+		}
+	}
+	return nil
+}
+
+// publish signs an event and publishes it to the relays
+func (b *Bridge) publish(ctx context.Context, evt *nostr.Event) error {
+	if b.privateKey == "" {
+		return &Error{Code: CodeInternalError, Message: "the bridge has no signing key"}
+	}
+
+	if err := evt.Sign(b.privateKey); err != nil {
+		return err
+	}
+
+	if err := b.relays.Publish(ctx, *evt); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// PoolRelays uses a go-nostr relay pool to reach a fixed set of relays
+type PoolRelays struct {
+	pool *nostr.SimplePool
+	urls []string
+}
+
+// NewPoolRelays creates new relays using the given pool and relay urls
+func NewPoolRelays(pool *nostr.SimplePool, urls []string) *PoolRelays {
+	return &PoolRelays{pool: pool, urls: urls}
+}
+
+// Publish publishes an event to all relays, it fails only when no relay accepts it
+func (r *PoolRelays) Publish(ctx context.Context, evt nostr.Event) error {
+	var errs []string
+	for result := range r.pool.PublishMany(ctx, r.urls, evt) {
+		if result.Error == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", result.RelayURL, result.Error))
+	}
+
+	if len(errs) == 0 {
+		return fmt.Errorf("no relays to publish to")
+	}
+	return fmt.Errorf("all relays rejected the event: %s", strings.Join(errs, ", "))
+}
+
+// Query fetches the events matching a filter from all relays
+func (r *PoolRelays) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var events []*nostr.Event
+	for relayEvent := range r.pool.FetchMany(ctx, r.urls, filter) {
+		events = append(events, relayEvent.Event)
+	}
+	return events, nil
+}
@@ -0,0 +1,218 @@
+package bundler
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// memoryRelays stores events in memory and answers gas estimate requests like an estimator
+// signing with its key
+type memoryRelays struct {
+	mu        sync.Mutex
+	events    []*nostr.Event
+	estimator string
+}
+
+func (r *memoryRelays) Publish(ctx context.Context, evt nostr.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, &evt)
+
+	if evt.Kind == event.KindGasEstimateRequest {
+		response, err := event.CreateGasEstimateResponseEvent(&evt, neth.GasEstimate{
+			CallGasLimit:         (*hexutil.Big)(big.NewInt(50000)),
+			VerificationGasLimit: (*hexutil.Big)(big.NewInt(100000)),
+			PreVerificationGas:   (*hexutil.Big)(big.NewInt(21000)),
+		})
+		if err != nil {
+			return err
+		}
+		if err := response.Sign(r.estimator); err != nil {
+			return err
+		}
+		r.events = append(r.events, response)
+	}
+
+	return nil
+}
+
+func (r *memoryRelays) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*nostr.Event
+	for _, evt := range r.events {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func call(t *testing.T, server *httptest.Server, body string) Response {
+	t.Helper()
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to call bridge: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestBridge(t *testing.T) {
+	entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	relays := &memoryRelays{estimator: nostr.GeneratePrivateKey()}
+	estimator, _ := nostr.GetPublicKey(relays.estimator)
+	bridge := NewBridge(big.NewInt(100), []common.Address{entryPoint}, nostr.GeneratePrivateKey(), relays, WithPollInterval(time.Millisecond), WithEstimators(estimator))
+
+	server := httptest.NewServer(bridge)
+	defer server.Close()
+
+	userOp := `{"sender":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6","nonce":"0x1","initCode":"0x","callData":"0x","callGasLimit":"0x0","verificationGasLimit":"0x0","preVerificationGas":"0x0","maxFeePerGas":"0x0","maxPriorityFeePerGas":"0x0","paymasterAndData":"0x","signature":"0x"}`
+
+	response := call(t, server, `{"jsonrpc":"2.0","id":1,"method":"eth_estimateUserOperationGas","params":[`+userOp+`,"`+entryPoint.Hex()+`"]}`)
+	if response.Error != nil {
+		t.Fatalf("Failed to estimate gas: %v", response.Error)
+	}
+	estimate := response.Result.(map[string]any)
+	if estimate["callGasLimit"] != "0xc350" {
+		t.Errorf("Expected callGasLimit 0xc350, got %v", estimate["callGasLimit"])
+	}
+
+	response = call(t, server, `{"jsonrpc":"2.0","id":2,"method":"eth_sendUserOperation","params":[`+userOp+`,"`+entryPoint.Hex()+`"]}`)
+	if response.Error != nil {
+		t.Fatalf("Failed to send user op: %v", response.Error)
+	}
+	hash := response.Result.(string)
+
+	var sent neth.UserOp
+	json.Unmarshal([]byte(userOp), &sent)
+	if expected := sent.GetUserOpHash(entryPoint, big.NewInt(100)).Hex(); hash != expected {
+		t.Errorf("Expected the ERC-4337 user op hash %s, got %s", expected, hash)
+	}
+
+	response = call(t, server, `{"jsonrpc":"2.0","id":3,"method":"eth_getUserOperationByHash","params":["`+hash+`"]}`)
+	if response.Error != nil {
+		t.Fatalf("Failed to get user op: %v", response.Error)
+	}
+	byHash := response.Result.(map[string]any)
+	if byHash["status"] != string(event.EventTypeUserOpRequested) {
+		t.Errorf("Expected status %s, got %v", event.EventTypeUserOpRequested, byHash["status"])
+	}
+	if !strings.EqualFold(byHash["entryPoint"].(string), entryPoint.Hex()) {
+		t.Errorf("Expected entry point %s, got %v", entryPoint.Hex(), byHash["entryPoint"])
+	}
+
+	response = call(t, server, `{"jsonrpc":"2.0","id":4,"method":"eth_sendUserOperation","params":[`+userOp+`,"0x0000000000000000000000000000000000000001"]}`)
+	if response.Error == nil || response.Error.Code != CodeInvalidParams {
+		t.Errorf("Expected unsupported entry point to fail with %d, got %v", CodeInvalidParams, response.Error)
+	}
+
+	response = call(t, server, `{"jsonrpc":"2.0","id":5,"method":"eth_unknown"}`)
+	if response.Error == nil || response.Error.Code != CodeMethodNotFound {
+		t.Errorf("Expected unknown method to fail with %d, got %v", CodeMethodNotFound, response.Error)
+	}
+}
+
+func TestBridgeInvalidUserOp(t *testing.T) {
+	entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	relays := &memoryRelays{estimator: nostr.GeneratePrivateKey()}
+	estimator, _ := nostr.GetPublicKey(relays.estimator)
+	bridge := NewBridge(big.NewInt(100), []common.Address{entryPoint}, nostr.GeneratePrivateKey(), relays, WithEstimators(estimator))
+
+	server := httptest.NewServer(bridge)
+	defer server.Close()
+
+	testCases := []struct {
+		name   string
+		userOp string
+	}{
+		{"missing nonce", `{"sender":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6","callGasLimit":"0x0","verificationGasLimit":"0x0","preVerificationGas":"0x0","maxFeePerGas":"0x0","maxPriorityFeePerGas":"0x0"}`},
+		{"malformed nonce", `{"sender":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6","nonce":"0x00","callGasLimit":"0x0","verificationGasLimit":"0x0","preVerificationGas":"0x0","maxFeePerGas":"0x0","maxPriorityFeePerGas":"0x0"}`},
+		{"missing gas", `{"sender":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6","nonce":"0x1"}`},
+	}
+
+	for _, tc := range testCases {
+		for _, method := range []string{"eth_sendUserOperation", "eth_estimateUserOperationGas"} {
+			response := call(t, server, `{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":[`+tc.userOp+`,"`+entryPoint.Hex()+`"]}`)
+			if response.Error == nil || response.Error.Code != CodeInvalidParams {
+				t.Errorf("%s: expected %s to fail with %d, got %v", tc.name, method, CodeInvalidParams, response.Error)
+			}
+		}
+	}
+
+	if len(relays.events) != 0 {
+		t.Errorf("Expected nothing to be published, got %d events", len(relays.events))
+	}
+}
+
+func TestBridgeUntrustedAuthors(t *testing.T) {
+	entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	userOp := neth.UserOp{
+		Sender:               common.HexToAddress("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(0),
+		VerificationGasLimit: big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+	}
+
+	// Estimates of anyone else than the estimators are ignored
+	relays := &memoryRelays{estimator: nostr.GeneratePrivateKey()}
+	bridge := NewBridge(big.NewInt(100), []common.Address{entryPoint}, nostr.GeneratePrivateKey(), relays,
+		WithPollInterval(time.Millisecond), WithEstimateTimeout(20*time.Millisecond), WithEstimators(nostr.GeneratePrivateKey()))
+
+	if _, err := bridge.EstimateUserOperationGas(context.Background(), userOp, entryPoint); err == nil {
+		t.Errorf("Expected the estimate of an untrusted estimator to be ignored")
+	}
+
+	if _, err := NewBridge(big.NewInt(100), []common.Address{entryPoint}, nostr.GeneratePrivateKey(), relays).EstimateUserOperationGas(context.Background(), userOp, entryPoint); err == nil {
+		t.Errorf("Expected gas estimates to require estimators")
+	}
+
+	// User ops published by anyone else than the bridge and the bundlers are ignored
+	forged, err := event.CreateUserOpEvent(big.NewInt(100), nil, &entryPoint, nil, nil, 0, userOp, event.EventTypeUserOpConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+	forged.Sign(nostr.GeneratePrivateKey())
+	relays.Publish(context.Background(), *forged)
+
+	hash := userOp.GetUserOpHash(entryPoint, big.NewInt(100)).Hex()
+	byHash, err := bridge.GetUserOperationByHash(context.Background(), hash)
+	if err != nil || byHash != nil {
+		t.Errorf("Expected the user op of an untrusted author to be ignored, got %+v, %v", byHash, err)
+	}
+
+	bundler := nostr.GeneratePrivateKey()
+	bundlerPubkey, _ := nostr.GetPublicKey(bundler)
+	trusted, _ := event.CreateUserOpEvent(big.NewInt(100), nil, &entryPoint, nil, nil, 0, userOp, event.EventTypeUserOpConfirmed)
+	trusted.Sign(bundler)
+	relays.Publish(context.Background(), *trusted)
+
+	byHash, err = NewBridge(big.NewInt(100), []common.Address{entryPoint}, nostr.GeneratePrivateKey(), relays, WithBundlers(bundlerPubkey)).GetUserOperationByHash(context.Background(), hash)
+	if err != nil || byHash == nil || byHash.Status != string(event.EventTypeUserOpConfirmed) {
+		t.Errorf("Expected the user op of a bundler, got %+v, %v", byHash, err)
+	}
+}
@@ -0,0 +1,152 @@
+// Package bundler exposes the ERC-4337 bundler JSON-RPC API on top of Nostr user op events,
+// so existing account abstraction SDKs can talk to a Nostr-based bundler network
+package bundler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// maxRequestSize is the maximum size of a JSON-RPC request body
+const maxRequestSize = 1 << 20
+
+// Request is a JSON-RPC 2.0 request
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC 2.0 response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ServeHTTP handles single and batched JSON-RPC requests
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+
+	var result any
+	if len(body) > 0 && body[0] == '[' {
+		var requests []json.RawMessage
+		if err := json.Unmarshal(body, &requests); err != nil || len(requests) == 0 {
+			result = errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "invalid batch"})
+		} else {
+			responses := make([]*Response, 0, len(requests))
+			for _, raw := range requests {
+				if resp := b.handle(r.Context(), raw); resp != nil {
+					responses = append(responses, resp)
+				}
+			}
+			result = responses
+		}
+	} else {
+		resp := b.handle(r.Context(), body)
+		if resp == nil {
+			// Notifications are not answered
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		result = resp
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handle executes a single request, nil is returned for notifications
+func (b *Bridge) handle(ctx context.Context, raw json.RawMessage) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "parse error"})
+	}
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+
+	result, err := b.Call(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+// errorResponse creates a response carrying an error
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+// parseParams decodes positional params into the given values, missing trailing params are
+// left untouched
+func parseParams(params json.RawMessage, values ...any) error {
+	var positional []json.RawMessage
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &positional); err != nil {
+			return &Error{Code: CodeInvalidParams, Message: "params must be an array"}
+		}
+	}
+
+	if len(positional) > len(values) {
+		return &Error{Code: CodeInvalidParams, Message: "too many params"}
+	}
+
+	for i, param := range positional {
+		if err := json.Unmarshal(param, values[i]); err != nil {
+			return &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+	}
+
+	return nil
+}
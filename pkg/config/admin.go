@@ -0,0 +1,362 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultAdminMaxAge is how far the time of an admin command may be from the time it is received
+const DefaultAdminMaxAge = 24 * time.Hour
+
+// MaxAuditEntries is the number of applied admin commands kept in the audit log
+const MaxAuditEntries = 100
+
+// AdminCheckpointKey is the key the applied admin commands are saved under in a state store
+const AdminCheckpointKey = "admin"
+
+// Admin command errors, commands failing them are not applied
+var (
+	ErrAdminInvalid   = errors.New("invalid admin command")
+	ErrAdminForbidden = errors.New("admin command not signed by an operator")
+	ErrAdminExpired   = errors.New("admin command expired")
+	ErrAdminReplayed  = errors.New("admin command already applied")
+)
+
+// AuditEntry is an applied admin command
+type AuditEntry struct {
+	EventID     string            `json:"event_id"`
+	Operator    string            `json:"operator"`
+	Action      event.AdminAction `json:"action"`
+	Value       string            `json:"value"`
+	Replacement string            `json:"replacement,omitempty"`
+	CreatedAt   int64             `json:"created_at"` // Time of the command
+	AppliedAt   int64             `json:"applied_at"`
+}
+
+// adminState is what an Admin saves to its state store
+type adminState struct {
+	Seen  map[string]int64 `json:"seen"` // Times of the applied commands, by event ID
+	Audit []AuditEntry     `json:"audit"`
+}
+
+// Admin applies the admin commands (event.KindAdminCommand) its operators send to a bridge on
+// top of its config. Commands are only accepted within the max age of their time and once, their
+// IDs are kept until they expire so that a captured command cannot be replayed. The commands of
+// the audit log are applied again on top of every new base config.
+type Admin struct {
+	mu sync.Mutex
+
+	bridge    string // Pubkey of the bridge, commands must tag it
+	operators map[string]bool
+	onChange  func(*Config)
+	maxAge    time.Duration
+	store     state.Store
+	auditor   *sink.Auditor
+	now       func() time.Time
+
+	base   *Config // Config the commands apply to
+	config *Config // Base config with the commands applied
+	seen   map[string]int64
+	audit  []AuditEntry
+}
+
+// AdminOption configures an Admin
+type AdminOption func(*Admin)
+
+// WithAdminMaxAge sets how far the time of a command may be from the time it is received
+func WithAdminMaxAge(maxAge time.Duration) AdminOption {
+	return func(a *Admin) {
+		a.maxAge = maxAge
+	}
+}
+
+// WithAdminStateStore persists the applied commands, so that they are not replayed after a
+// restart
+func WithAdminStateStore(store state.Store) AdminOption {
+	return func(a *Admin) {
+		a.store = store
+	}
+}
+
+// WithAdminAuditor records the applied commands and the relays they remove
+func WithAdminAuditor(auditor *sink.Auditor) AdminOption {
+	return func(a *Admin) {
+		a.auditor = auditor
+	}
+}
+
+// NewAdmin creates a new admin of the bridge with a pubkey, applying the commands of the
+// operators to a config and handing every new config to a callback
+func NewAdmin(bridgePubkey string, operators []string, config *Config, onChange func(*Config), opts ...AdminOption) *Admin {
+	a := &Admin{
+		bridge:    bridgePubkey,
+		operators: make(map[string]bool),
+		onChange:  onChange,
+		maxAge:    DefaultAdminMaxAge,
+		now:       time.Now,
+		base:      config.clone(),
+		config:    config.clone(),
+		seen:      make(map[string]int64),
+	}
+	for _, operator := range operators {
+		a.operators[operator] = true
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Restore loads the applied commands from the state store and applies them again, handing the
+// resulting config to the callback when there are any
+func (a *Admin) Restore(ctx context.Context) error {
+	if a.store == nil {
+		return nil
+	}
+
+	checkpoint, err := a.store.Load(ctx, AdminCheckpointKey)
+	if errors.Is(err, state.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved adminState
+	if err := json.Unmarshal(checkpoint.Data, &saved); err != nil {
+		return fmt.Errorf("invalid admin checkpoint: %w", err)
+	}
+
+	a.mu.Lock()
+	if saved.Seen != nil {
+		a.seen = saved.Seen
+	}
+	a.audit = saved.Audit
+	a.config = a.replay()
+	c := a.config.clone()
+	a.mu.Unlock()
+
+	if len(saved.Audit) > 0 && a.onChange != nil {
+		a.onChange(c)
+	}
+	return nil
+}
+
+// SetConfig replaces the base config commands apply to, e.g. when it is reloaded from its source,
+// and returns it with the commands of the audit log applied again
+func (a *Admin) SetConfig(c *Config) *Config {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.base = c.clone()
+	a.config = a.replay()
+	return a.config.clone()
+}
+
+// replay applies the commands of the audit log to the base config in order, skipping the ones
+// that no longer apply, e.g. the rotation of a relay the base config removed. a is locked.
+func (a *Admin) replay() *Config {
+	c := a.base.clone()
+	for _, entry := range a.audit {
+		next := c.clone()
+		command := event.AdminCommandEvent{Action: entry.Action, Value: entry.Value, Replacement: entry.Replacement}
+		if err := next.applyCommand(command); err == nil {
+			c = next
+		}
+	}
+	return c
+}
+
+// Audit returns the applied commands, oldest first
+func (a *Admin) Audit() []AuditEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return slices.Clone(a.audit)
+}
+
+// Filter returns the filter of the commands that can still be applied
+func (a *Admin) Filter() nostr.Filter {
+	operators := make([]string, 0, len(a.operators))
+	for operator := range a.operators {
+		operators = append(operators, operator)
+	}
+	slices.Sort(operators)
+
+	since := nostr.Timestamp(a.now().Add(-a.maxAge).Unix())
+	return nostr.Filter{
+		Kinds:   event.MappedKinds(event.KindAdminCommand),
+		Authors: operators,
+		Tags:    nostr.TagMap{"p": []string{a.bridge}},
+		Since:   &since,
+	}
+}
+
+// Handle checks and applies a command, returning its audit entry
+func (a *Admin) Handle(ctx context.Context, evt *nostr.Event) (*AuditEntry, error) {
+	if event.DefaultKind(evt.Kind) != event.KindAdminCommand {
+		return nil, fmt.Errorf("%w: kind %d", ErrAdminInvalid, evt.Kind)
+	}
+	// The ID is checked since it keys the replay protection, and the signature only covers it
+	if !evt.CheckID() {
+		return nil, fmt.Errorf("%w: invalid id", ErrAdminInvalid)
+	}
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return nil, fmt.Errorf("%w: invalid signature", ErrAdminInvalid)
+	}
+	if !a.operators[evt.PubKey] {
+		return nil, fmt.Errorf("%w: %s", ErrAdminForbidden, evt.PubKey)
+	}
+	if tag := evt.Tags.GetFirst([]string{"p", a.bridge}); tag == nil {
+		return nil, fmt.Errorf("%w: not for this bridge", ErrAdminInvalid)
+	}
+
+	command, err := event.ParseAdminCommandEvent(evt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAdminInvalid, err)
+	}
+
+	now := a.now()
+	if age := now.Sub(evt.CreatedAt.Time()); age > a.maxAge || age < -a.maxAge {
+		return nil, fmt.Errorf("%w: %s", ErrAdminExpired, evt.ID)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, createdAt := range a.seen {
+		if now.Sub(time.Unix(createdAt, 0)) > a.maxAge {
+			delete(a.seen, id)
+		}
+	}
+	if _, ok := a.seen[evt.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrAdminReplayed, evt.ID)
+	}
+
+	c := a.config.clone()
+	if err := c.applyCommand(*command); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAdminInvalid, err)
+	}
+
+	entry := AuditEntry{
+		EventID:     evt.ID,
+		Operator:    evt.PubKey,
+		Action:      command.Action,
+		Value:       command.Value,
+		Replacement: command.Replacement,
+		CreatedAt:   int64(evt.CreatedAt),
+		AppliedAt:   now.Unix(),
+	}
+
+	a.seen[evt.ID] = int64(evt.CreatedAt)
+	a.audit = append(a.audit, entry)
+	if len(a.audit) > MaxAuditEntries {
+		a.audit = a.audit[len(a.audit)-MaxAuditEntries:]
+	}
+	previous := a.config
+	a.config = c
+
+	// The command is saved before it takes effect, so that it is never applied twice
+	if err := a.save(ctx); err != nil {
+		return nil, err
+	}
+
+	if a.onChange != nil {
+		a.onChange(c.clone())
+	}
+
+	details := map[string]string{"event_id": evt.ID, "operator": evt.PubKey, "action": string(command.Action), "value": command.Value}
+	if command.Replacement != "" {
+		details["replacement"] = command.Replacement
+	}
+	recordChange(ctx, a.auditor, previous, c, fmt.Sprintf("Admin command %s %s was applied", command.Action, command.Value), details)
+
+	return &entry, nil
+}
+
+// Run handles the commands received from relays until the channel is closed or the context is
+// done, commands that cannot be applied are reported to onError when it is not nil
+func (a *Admin) Run(ctx context.Context, events <-chan nostr.RelayEvent, onError func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case relayEvent, ok := <-events:
+			if !ok {
+				return
+			}
+			// The same command arrives from every relay
+			if _, err := a.Handle(ctx, relayEvent.Event); err != nil && !errors.Is(err, ErrAdminReplayed) && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// save saves the applied commands to the state store, if any, a is locked
+func (a *Admin) save(ctx context.Context) error {
+	if a.store == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(adminState{Seen: a.seen, Audit: a.audit})
+	if err != nil {
+		return err
+	}
+	return a.store.Save(ctx, state.Checkpoint{Key: AdminCheckpointKey, Data: data, UpdatedAt: a.now()})
+}
+
+// applyCommand applies an admin command to the config
+func (c *Config) applyCommand(command event.AdminCommandEvent) error {
+	switch command.Action {
+	case event.AdminActionAddContract:
+		c.Contracts = addValue(c.Contracts, command.Value)
+	case event.AdminActionRemoveContract:
+		c.Contracts = removeValue(c.Contracts, command.Value)
+	case event.AdminActionMuteAddress:
+		c.Muted = addValue(c.Muted, command.Value)
+	case event.AdminActionUnmuteAddress:
+		c.Muted = removeValue(c.Muted, command.Value)
+	case event.AdminActionAddRelay:
+		c.Relays = addValue(c.Relays, command.Value)
+	case event.AdminActionRemoveRelay:
+		c.Relays = removeValue(c.Relays, command.Value)
+	case event.AdminActionRotateRelay:
+		i := slices.IndexFunc(c.Relays, func(relay string) bool { return strings.EqualFold(relay, command.Value) })
+		if i < 0 {
+			return fmt.Errorf("relay %s is not configured", command.Value)
+		}
+		if slices.ContainsFunc(c.Relays, func(relay string) bool { return strings.EqualFold(relay, command.Replacement) }) {
+			c.Relays = slices.Delete(c.Relays, i, i+1)
+		} else {
+			c.Relays[i] = command.Replacement
+		}
+	default:
+		return fmt.Errorf("unknown admin action: %s", command.Action)
+	}
+	return c.Validate()
+}
+
+// addValue adds a value to a list unless it is already in it, ignoring case
+func addValue(values []string, value string) []string {
+	if slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) }) {
+		return values
+	}
+	return append(values, value)
+}
+
+// removeValue removes a value from a list, ignoring case
+func removeValue(values []string, value string) []string {
+	return slices.DeleteFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// recordingSink keeps the events sent to it
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*nostr.Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, evt *nostr.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, evt)
+	return nil
+}
+
+func TestAdmin(t *testing.T) {
+	ctx := context.Background()
+	bridge, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	operatorKey := nostr.GeneratePrivateKey()
+	operator, _ := nostr.GetPublicKey(operatorKey)
+	checkpoints := state.NewMemoryStore()
+
+	command := func(key string, action event.AdminAction, value, replacement string) *nostr.Event {
+		t.Helper()
+		evt, err := event.CreateAdminCommandEvent(bridge, action, value, replacement)
+		if err != nil {
+			t.Fatalf("Failed to create admin command: %v", err)
+		}
+		if err := evt.Sign(key); err != nil {
+			t.Fatalf("Failed to sign admin command: %v", err)
+		}
+		return evt
+	}
+
+	var current *Config
+	audit := &recordingSink{}
+	base := &Config{Relays: []string{"wss://a.example.com"}}
+	admin := NewAdmin(bridge, []string{operator}, base, func(c *Config) { current = c },
+		WithAdminStateStore(checkpoints),
+		WithAdminAuditor(sink.NewAuditor(audit, nostr.GeneratePrivateKey(), nil)),
+	)
+
+	add := command(operatorKey, event.AdminActionAddContract, testToken, "")
+	for _, evt := range []*nostr.Event{
+		add,
+		command(operatorKey, event.AdminActionMuteAddress, "0x0000000000000000000000000000000000000001", ""),
+		command(operatorKey, event.AdminActionRotateRelay, "wss://a.example.com", "wss://b.example.com"),
+	} {
+		if _, err := admin.Handle(ctx, evt); err != nil {
+			t.Fatalf("Failed to apply admin command: %v", err)
+		}
+	}
+
+	if len(current.Contracts) != 1 || len(current.Muted) != 1 || current.Relays[0] != "wss://b.example.com" {
+		t.Errorf("Expected the commands to be applied, got %+v", current)
+	}
+	if len(base.Contracts) != 0 || base.Relays[0] != "wss://a.example.com" {
+		t.Errorf("Expected the base config to be left alone, got %+v", base)
+	}
+	if audit := admin.Audit(); len(audit) != 3 || audit[0].EventID != add.ID || audit[0].Operator != operator {
+		t.Errorf("Expected the commands in the audit log, got %+v", audit)
+	}
+
+	var operations []string
+	for _, evt := range audit.events {
+		record, err := event.ParseAuditRecordEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse audit record: %v", err)
+		}
+		operations = append(operations, string(record.Operation))
+	}
+	if got := strings.Join(operations, ","); got != "config_applied,config_applied,config_applied,relay_removed" {
+		t.Errorf("Expected 3 applied configs and a removed relay, got %s", got)
+	}
+
+	if _, err := admin.Handle(ctx, add); !errors.Is(err, ErrAdminReplayed) {
+		t.Errorf("Expected the command to be replayed, got %v", err)
+	}
+	edited := *add
+	edited.ID = strings.Repeat("0", 64)
+	if _, err := admin.Handle(ctx, &edited); !errors.Is(err, ErrAdminInvalid) {
+		t.Errorf("Expected a command with an edited id to be invalid, got %v", err)
+	}
+	if _, err := admin.Handle(ctx, command(nostr.GeneratePrivateKey(), event.AdminActionAddRelay, "wss://c.example.com", "")); !errors.Is(err, ErrAdminForbidden) {
+		t.Errorf("Expected a command of another pubkey to be forbidden, got %v", err)
+	}
+	if _, err := admin.Handle(ctx, command(operatorKey, event.AdminActionRotateRelay, "wss://a.example.com", "wss://c.example.com")); !errors.Is(err, ErrAdminInvalid) {
+		t.Errorf("Expected the rotation of a removed relay to fail, got %v", err)
+	}
+
+	old := command(operatorKey, event.AdminActionAddRelay, "wss://c.example.com", "")
+	old.CreatedAt = nostr.Timestamp(time.Now().Add(-2 * DefaultAdminMaxAge).Unix())
+	old.Sign(operatorKey)
+	if _, err := admin.Handle(ctx, old); !errors.Is(err, ErrAdminExpired) {
+		t.Errorf("Expected an old command to be expired, got %v", err)
+	}
+
+	forged := command(operatorKey, event.AdminActionAddRelay, "wss://c.example.com", "")
+	forged.Content = `{"action":"remove_relay","value":"wss://b.example.com"}`
+	if _, err := admin.Handle(ctx, forged); !errors.Is(err, ErrAdminInvalid) {
+		t.Errorf("Expected a forged command to be invalid, got %v", err)
+	}
+
+	// A reloaded config gets the applied commands again
+	reloaded := admin.SetConfig(&Config{Relays: []string{"wss://a.example.com", "wss://d.example.com"}})
+	if len(reloaded.Contracts) != 1 || len(reloaded.Muted) != 1 || strings.Join(reloaded.Relays, ",") != "wss://b.example.com,wss://d.example.com" {
+		t.Errorf("Expected the commands to be applied to the reloaded config, got %+v", reloaded)
+	}
+
+	// Applied commands are not replayed after a restart, and apply again to the config
+	var restored *Config
+	restarted := NewAdmin(bridge, []string{operator}, base, func(c *Config) { restored = c }, WithAdminStateStore(checkpoints))
+	if err := restarted.Restore(ctx); err != nil {
+		t.Fatalf("Failed to restore admin: %v", err)
+	}
+	if restored == nil || len(restored.Contracts) != 1 || restored.Relays[0] != "wss://b.example.com" {
+		t.Errorf("Expected the restored commands to be applied, got %+v", restored)
+	}
+	if _, err := restarted.Handle(ctx, add); !errors.Is(err, ErrAdminReplayed) {
+		t.Errorf("Expected the command to be replayed after a restart, got %v", err)
+	}
+	if len(restarted.Audit()) != 3 {
+		t.Errorf("Expected the audit log to be restored, got %d entries", len(restarted.Audit()))
+	}
+
+	filter := admin.Filter()
+	if len(filter.Authors) != 1 || filter.Authors[0] != operator || filter.Tags["p"][0] != bridge || filter.Since == nil {
+		t.Errorf("Expected a filter of the commands of the operator to the bridge, got %+v", filter)
+	}
+}
@@ -0,0 +1,204 @@
+// Package config loads the runtime configuration of a bridge, from a JSON file or a Nostr event,
+// and reloads it while the bridge runs so that contracts, relays and thresholds change without a
+// restart.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Config is the part of the configuration of a bridge that can change at runtime. It is the
+// whole state of its targets: fields left out reset them.
+type Config struct {
+	Relays        []string      `json:"relays"`              // Relays events are published to and read from
+	Contracts     []string      `json:"contracts,omitempty"` // Watched contract addresses, every contract when empty
+	Topics        []string      `json:"topics,omitempty"`    // Watched event topics, every topic when empty
+	Muted         []string      `json:"muted,omitempty"`     // Addresses whose logs and transfers are skipped
+	Confirmations Confirmations `json:"confirmations"`
+	Dust          Dust          `json:"dust"`
+}
+
+// Confirmations is the number of blocks a log waits for before it is confirmed
+type Confirmations struct {
+	Default uint64            `json:"default"`
+	Chains  map[string]uint64 `json:"chains,omitempty"` // Overrides by chain ID
+}
+
+// Dust is the minimum value of the transfers of the tokens, in base units as decimal strings
+type Dust struct {
+	Default string           `json:"default,omitempty"` // Tokens without their own threshold
+	Tokens  []TokenThreshold `json:"tokens,omitempty"`
+}
+
+// TokenThreshold is the minimum value of the transfers of a token
+type TokenThreshold struct {
+	ChainID string `json:"chain_id"`
+	Token   string `json:"token"`
+	Minimum string `json:"minimum"`
+}
+
+// Parse decodes and validates a JSON config
+func Parse(data []byte) (*Config, error) {
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the relays, addresses, topics and thresholds of a config
+func (c *Config) Validate() error {
+	for _, relay := range c.Relays {
+		if !nostr.IsValidRelayURL(relay) {
+			return fmt.Errorf("invalid relay %q", relay)
+		}
+	}
+	for _, contract := range c.Contracts {
+		if !common.IsHexAddress(contract) {
+			return fmt.Errorf("invalid contract %q", contract)
+		}
+	}
+	for _, address := range c.Muted {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid muted address %q", address)
+		}
+	}
+	for _, topic := range c.Topics {
+		if len(topic) != 66 || !strings.HasPrefix(topic, "0x") || !isHex(topic[2:]) {
+			return fmt.Errorf("invalid topic %q", topic)
+		}
+	}
+
+	if c.Dust.Default != "" {
+		if _, err := parseMinimum(c.Dust.Default); err != nil {
+			return err
+		}
+	}
+	for _, threshold := range c.Dust.Tokens {
+		if threshold.ChainID == "" || !common.IsHexAddress(threshold.Token) {
+			return fmt.Errorf("invalid dust threshold of token %q on chain %q", threshold.Token, threshold.ChainID)
+		}
+		if _, err := parseMinimum(threshold.Minimum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfirmationPolicy returns the confirmation policy of the watchers
+func (c *Config) ConfirmationPolicy() watcher.ConfirmationPolicy {
+	policy := watcher.ConfirmationPolicy{Default: c.Confirmations.Default, PerChain: make(map[string]uint64)}
+	for chainID, depth := range c.Confirmations.Chains {
+		policy.PerChain[chainID] = depth
+	}
+	return policy
+}
+
+// DustThresholds returns the dust thresholds of the tokens, the config must be valid
+func (c *Config) DustThresholds() *event.DustThresholds {
+	thresholds := event.NewDustThresholds()
+	if minimum, err := parseMinimum(c.Dust.Default); err == nil {
+		thresholds.SetDefault(minimum)
+	}
+	for _, threshold := range c.Dust.Tokens {
+		if minimum, err := parseMinimum(threshold.Minimum); err == nil {
+			thresholds.Set(threshold.ChainID, threshold.Token, minimum)
+		}
+	}
+	return thresholds
+}
+
+// Targets are the parts of a running bridge a config applies to, nil targets are skipped
+type Targets struct {
+	Server        *service.Server              // Default relays of the published events
+	Subscriptions *watcher.SubscriptionManager // Watched contracts and topics, and muted addresses
+	Watchers      []*watcher.Watcher           // Confirmation policy
+	Dust          *event.DustThresholds        // Thresholds the watchers were created with
+}
+
+// Apply updates the targets to the config, the changes apply from the next request or poll
+func (c *Config) Apply(targets Targets) {
+	if targets.Server != nil {
+		targets.Server.SetRelays(c.Relays)
+	}
+	if targets.Subscriptions != nil {
+		targets.Subscriptions.Set(c.Contracts, c.Topics)
+		targets.Subscriptions.SetMuted(c.Muted...)
+	}
+	for _, w := range targets.Watchers {
+		w.SetConfirmations(c.ConfirmationPolicy())
+	}
+	if targets.Dust != nil {
+		targets.Dust.Replace(c.DustThresholds())
+	}
+}
+
+// recordChange records a new config and the relays it removed
+func recordChange(ctx context.Context, auditor *sink.Auditor, previous, c *Config, message string, details map[string]string) {
+	auditor.Record(ctx, event.AuditRecord{Operation: event.AuditConfigApplied, Message: message, Details: details})
+
+	if previous == nil {
+		return
+	}
+	for _, relay := range previous.Relays {
+		if slices.ContainsFunc(c.Relays, func(r string) bool { return strings.EqualFold(r, relay) }) {
+			continue
+		}
+		auditor.Record(ctx, event.AuditRecord{
+			Operation: event.AuditRelayRemoved,
+			Message:   fmt.Sprintf("Relay %s was removed", relay),
+			Details:   map[string]string{"relay": relay},
+		})
+	}
+}
+
+// clone returns a deep copy of the config
+func (c *Config) clone() *Config {
+	if c == nil {
+		return &Config{}
+	}
+
+	clone := *c
+	clone.Relays = slices.Clone(c.Relays)
+	clone.Contracts = slices.Clone(c.Contracts)
+	clone.Topics = slices.Clone(c.Topics)
+	clone.Muted = slices.Clone(c.Muted)
+	clone.Confirmations.Chains = maps.Clone(c.Confirmations.Chains)
+	clone.Dust.Tokens = slices.Clone(c.Dust.Tokens)
+	return &clone
+}
+
+// parseMinimum parses a threshold in base units
+func parseMinimum(value string) (*big.Int, error) {
+	minimum, ok := new(big.Int).SetString(value, 10)
+	if !ok || minimum.Sign() < 0 {
+		return nil, fmt.Errorf("invalid dust threshold %q", value)
+	}
+	return minimum, nil
+}
+
+// isHex checks if a string only has hex digits
+func isHex(s string) bool {
+	for _, char := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", char) {
+			return false
+		}
+	}
+	return true
+}
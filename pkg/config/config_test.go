@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	testToken = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	testTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`{
+		"relays": ["wss://relay.example.com"],
+		"contracts": ["` + testToken + `"],
+		"topics": ["` + testTopic + `"],
+		"confirmations": {"default": 12, "chains": {"100": 20}},
+		"dust": {"default": "10", "tokens": [{"chain_id": "100", "token": "` + testToken + `", "minimum": "1000"}]}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	policy := c.ConfirmationPolicy()
+	if policy.Depth("100") != 20 || policy.Depth("1") != 12 {
+		t.Errorf("Expected 20 confirmations on chain 100 and 12 elsewhere, got %+v", policy)
+	}
+
+	dust := c.DustThresholds()
+	if got := dust.Threshold("100", testToken); got == nil || got.Int64() != 1000 {
+		t.Errorf("Expected a threshold of 1000, got %v", got)
+	}
+	if got := dust.Threshold("1", testToken); got == nil || got.Int64() != 10 {
+		t.Errorf("Expected the default threshold of 10, got %v", got)
+	}
+
+	for _, data := range []string{
+		`{`,
+		`{"relays": ["https://relay.example.com"]}`,
+		`{"contracts": ["0x123"]}`,
+		`{"topics": ["0x123"]}`,
+		`{"dust": {"default": "-1"}}`,
+		`{"dust": {"tokens": [{"chain_id": "100", "token": "` + testToken + `", "minimum": "a lot"}]}}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected %s to be invalid", data)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	server := service.NewServer("", []string{"wss://old.example.com"}, nil)
+	subscriptions := watcher.NewSubscriptionManager()
+	subscriptions.AddAddresses("0x0000000000000000000000000000000000000001")
+	dust := event.NewDustThresholds()
+	dust.Set("1", "0x0000000000000000000000000000000000000001", big.NewInt(5))
+
+	c := &Config{
+		Relays:    []string{"wss://new.example.com"},
+		Contracts: []string{testToken},
+		Dust:      Dust{Tokens: []TokenThreshold{{ChainID: "100", Token: testToken, Minimum: "1000"}}},
+	}
+	c.Apply(Targets{Server: server, Subscriptions: subscriptions, Dust: dust})
+
+	if relays := server.Relays(); len(relays) != 1 || relays[0] != "wss://new.example.com" {
+		t.Errorf("Expected the new relay, got %v", relays)
+	}
+	if addresses := subscriptions.Addresses(); len(addresses) != 1 || addresses[0] != "0xe91d153e0b41518a2ce8dd3d7944fa863463a97d" {
+		t.Errorf("Expected the new contract only, got %v", addresses)
+	}
+	if dust.Threshold("1", "0x0000000000000000000000000000000000000001") != nil {
+		t.Error("Expected the removed threshold to be gone")
+	}
+	if got := dust.Threshold("100", testToken); got == nil || got.Int64() != 1000 {
+		t.Errorf("Expected a threshold of 1000, got %v", got)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	changes := make(chan *Config, 10)
+	errs := make(chan error, 10)
+	r := NewReloader(NewFileSource(path), func(c *Config) { changes <- c }, 10*time.Millisecond, func(err error) { errs <- err })
+
+	write(`{"relays": ["wss://a.example.com"]}`)
+	if changed, err := r.Reload(ctx); err != nil || !changed {
+		t.Fatalf("Expected the config to load, got %v %v", changed, err)
+	}
+	<-changes
+	if changed, err := r.Reload(ctx); err != nil || changed {
+		t.Errorf("Expected an unchanged config, got %v %v", changed, err)
+	}
+
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Failed to start reloader: %v", err)
+	}
+	defer r.Stop()
+	if err := r.Start(ctx); !errors.Is(err, ErrReloaderStarted) {
+		t.Errorf("Expected the reloader to be started, got %v", err)
+	}
+
+	write(`{"relays": ["not a relay"]}`)
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the invalid config to be reported")
+	}
+	if relays := r.Config().Relays; relays[0] != "wss://a.example.com" {
+		t.Errorf("Expected the previous config to stay, got %v", relays)
+	}
+
+	write(`{"relays": ["wss://b.example.com"]}`)
+	select {
+	case c := <-changes:
+		if c.Relays[0] != "wss://b.example.com" {
+			t.Errorf("Expected the new relay, got %v", c.Relays)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the new config to be applied")
+	}
+}
+
+type querier []*nostr.Event
+
+func (q querier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return q, nil
+}
+
+func TestEventSource(t *testing.T) {
+	ctx := context.Background()
+	key := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(key)
+
+	configEvent := func(content string, createdAt nostr.Timestamp, key string) *nostr.Event {
+		evt := &nostr.Event{
+			Kind:      nostr.KindApplicationSpecificData,
+			CreatedAt: createdAt,
+			Tags:      nostr.Tags{{"d", DefaultEventIdentifier}},
+			Content:   content,
+		}
+		if err := evt.Sign(key); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+		return evt
+	}
+
+	forged := configEvent(`{"relays": ["wss://forged.example.com"]}`, 300, nostr.GeneratePrivateKey())
+	forged.PubKey = pubkey
+
+	source := NewEventSource(querier{
+		configEvent(`{"relays": ["wss://old.example.com"]}`, 100, key),
+		configEvent(`{"relays": ["wss://new.example.com"]}`, 200, key),
+		forged,
+	}, pubkey, "")
+
+	data, err := source.Load(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	var c Config
+	json.Unmarshal(data, &c)
+	if c.Relays[0] != "wss://new.example.com" {
+		t.Errorf("Expected the latest signed config, got %s", data)
+	}
+
+	if _, err := NewEventSource(querier{}, pubkey, "").Load(ctx); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Expected no config, got %v", err)
+	}
+}
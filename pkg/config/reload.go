@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultReloadInterval is how often the source of a config is checked for changes
+const DefaultReloadInterval = 10 * time.Second
+
+// DefaultEventIdentifier is the d tag of the NIP-78 event a config is published in
+const DefaultEventIdentifier = "nostr-eth-config"
+
+// ErrNoConfig is returned when the source has no config
+var ErrNoConfig = errors.New("no config found")
+
+// ErrReloaderStarted is returned when a started reloader is started again
+var ErrReloaderStarted = errors.New("reloader already started")
+
+// Source returns the raw JSON of a config
+type Source interface {
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileSource reads a config from a file
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a new source reading the file at path
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Load reads the file
+func (s *FileSource) Load(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(s.path)
+}
+
+// EventSource reads a config from the content of the latest NIP-78 application data event of a
+// pubkey, so that a bridge can be reconfigured by publishing an event
+type EventSource struct {
+	querier    event.EventQuerier
+	pubkey     string
+	identifier string
+}
+
+// NewEventSource creates a new source reading the events of a pubkey with a d tag, or
+// DefaultEventIdentifier when it is empty
+func NewEventSource(querier event.EventQuerier, pubkey, identifier string) *EventSource {
+	if identifier == "" {
+		identifier = DefaultEventIdentifier
+	}
+	return &EventSource{querier: querier, pubkey: pubkey, identifier: identifier}
+}
+
+// Load returns the content of the latest valid config event
+func (s *EventSource) Load(ctx context.Context) ([]byte, error) {
+	events, err := s.querier.Query(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindApplicationSpecificData},
+		Authors: []string{s.pubkey},
+		Tags:    nostr.TagMap{"d": []string{s.identifier}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *nostr.Event
+	for _, evt := range events {
+		if evt.PubKey != s.pubkey || evt.Kind != nostr.KindApplicationSpecificData {
+			continue
+		}
+		if d := evt.Tags.GetD(); d != s.identifier {
+			continue
+		}
+		if ok, err := evt.CheckSignature(); err != nil || !ok {
+			continue
+		}
+		if latest == nil || evt.CreatedAt > latest.CreatedAt {
+			latest = evt
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNoConfig
+	}
+	return []byte(latest.Content), nil
+}
+
+// Reloader checks a source for changes and hands every new valid config to a callback. Invalid
+// configs are reported and the previous config stays in effect.
+type Reloader struct {
+	mu sync.Mutex
+
+	source   Source
+	onChange func(*Config)
+	interval time.Duration
+	onError  func(error)
+
+	last    []byte
+	current *Config
+	auditor *sink.Auditor
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReloader creates a new reloader of a source checked every interval, or
+// DefaultReloadInterval when it is 0. Errors are reported to onError when it is not nil.
+func NewReloader(source Source, onChange func(*Config), interval time.Duration, onError func(error)) *Reloader {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Reloader{source: source, onChange: onChange, interval: interval, onError: onError}
+}
+
+// SetAuditor records the configs the reloader applies and the relays they remove
+func (r *Reloader) SetAuditor(auditor *sink.Auditor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.auditor = auditor
+}
+
+// Config returns the current config, nil until one was loaded
+func (r *Reloader) Config() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
+}
+
+// Reload loads the source and applies its config when it changed, returning whether it did
+func (r *Reloader) Reload(ctx context.Context) (bool, error) {
+	data, err := r.source.Load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	if r.last != nil && bytes.Equal(data, r.last) {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.last = data
+
+	c, err := Parse(data)
+	if err != nil {
+		r.mu.Unlock()
+		return false, err
+	}
+	previous, auditor := r.current, r.auditor
+	r.current = c
+	r.mu.Unlock()
+
+	if r.onChange != nil {
+		r.onChange(c)
+	}
+	recordChange(ctx, auditor, previous, c, "The config was reloaded", map[string]string{"relays": strings.Join(c.Relays, ",")})
+	return true, nil
+}
+
+// Start checks the source every interval until Stop is called or the context is cancelled
+func (r *Reloader) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return ErrReloaderStarted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		r.run(ctx)
+	}(r.done)
+
+	return nil
+}
+
+// Stop stops checking the source and waits for the running reload to finish
+func (r *Reloader) Stop() error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+// run reloads the config every interval until the context is done
+func (r *Reloader) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := r.Reload(ctx); err != nil && ctx.Err() == nil {
+			r.onError(err)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
+)
+
+const (
+	KindAddressBook = 31104 // Addressable, NIP-51 style set of payees
+
+	// DefaultAddressBook is the d tag of address books created without a name
+	DefaultAddressBook = "address-book"
+)
+
+// Payee is a labeled entry of an address book
+type Payee struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // Ethereum address or ENS name
+	ChainID string `json:"chain_id,omitempty"`
+	Token   string `json:"token,omitempty"` // Default token paid to the payee
+	Private bool   `json:"-"`               // Encrypted in the content instead of listed in the tags
+}
+
+// AddressBook is a list of payees synced through relays, private payees are only readable by
+// the owner of the list
+type AddressBook struct {
+	Name   string  `json:"name"` // d tag, to keep several address books
+	Payees []Payee `json:"payees"`
+}
+
+// payeeKey identifies a payee by its address and chain
+func payeeKey(address, chainID string) string {
+	return strings.ToLower(address) + ":" + chainID
+}
+
+// Get returns the payee with an address on a chain
+func (b *AddressBook) Get(address, chainID string) (Payee, bool) {
+	key := payeeKey(address, chainID)
+	for _, payee := range b.Payees {
+		if payeeKey(payee.Address, payee.ChainID) == key {
+			return payee, true
+		}
+	}
+	return Payee{}, false
+}
+
+// Set adds a payee, or replaces the payee with the same address and chain
+func (b *AddressBook) Set(payee Payee) error {
+	if err := validatePayee(payee); err != nil {
+		return err
+	}
+
+	key := payeeKey(payee.Address, payee.ChainID)
+	for i, existing := range b.Payees {
+		if payeeKey(existing.Address, existing.ChainID) == key {
+			b.Payees[i] = payee
+			return nil
+		}
+	}
+
+	b.Payees = append(b.Payees, payee)
+	return nil
+}
+
+// Remove removes the payee with an address on a chain, it returns false when there is none
+func (b *AddressBook) Remove(address, chainID string) bool {
+	key := payeeKey(address, chainID)
+	for i, payee := range b.Payees {
+		if payeeKey(payee.Address, payee.ChainID) == key {
+			b.Payees = append(b.Payees[:i], b.Payees[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// validatePayee checks that a payee has a name and an address or ENS name
+func validatePayee(payee Payee) error {
+	if payee.Name == "" {
+		return fmt.Errorf("payee name cannot be empty")
+	}
+	if !isEthereumAddress(payee.Address) && !isENSName(payee.Address) {
+		return fmt.Errorf("payee address must be an address or an ENS name: %s", payee.Address)
+	}
+	if payee.Token != "" && !isEthereumAddress(payee.Token) {
+		return fmt.Errorf("payee token must be an address: %s", payee.Token)
+	}
+	return nil
+}
+
+// isENSName checks if a string looks like an ENS name, dot separated labels without spaces
+func isENSName(value string) bool {
+	if strings.HasPrefix(value, "0x") || strings.ContainsAny(value, " \t\n") {
+		return false
+	}
+
+	labels := strings.Split(value, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// payeeTag returns the tag of a payee, ["payee", address, name, chain ID, token]
+func payeeTag(payee Payee) nostr.Tag {
+	return nostr.Tag{"payee", payee.Address, payee.Name, payee.ChainID, payee.Token}
+}
+
+// payeeFromTag returns the payee of a payee tag
+func payeeFromTag(tag nostr.Tag) (Payee, bool) {
+	if len(tag) < 3 || tag[0] != "payee" {
+		return Payee{}, false
+	}
+
+	payee := Payee{Address: tag[1], Name: tag[2]}
+	if len(tag) > 3 {
+		payee.ChainID = tag[3]
+	}
+	if len(tag) > 4 {
+		payee.Token = tag[4]
+	}
+	return payee, true
+}
+
+// CreateAddressBookEvent creates the event of an address book (kind 31104). Public payees are
+// listed in the tags, private ones are encrypted to the owner with NIP-44 in the content, as
+// NIP-51 lists do. The private key of the owner is only needed for private payees.
+func CreateAddressBookEvent(book AddressBook, privateKey string) (*nostr.Event, error) {
+	name := book.Name
+	if name == "" {
+		name = DefaultAddressBook
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindAddressBook),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   "",
+	}
+
+	// Addressable identifier, one per address book
+	evt.Tags = append(evt.Tags, []string{"d", name})
+
+	// Type tag
+	evt.Tags = append(evt.Tags, typeTag("address_book"))
+
+	private := make(nostr.Tags, 0)
+	for _, payee := range book.Payees {
+		if err := validatePayee(payee); err != nil {
+			return nil, err
+		}
+
+		if payee.Private {
+			private = append(private, payeeTag(payee))
+		} else {
+			evt.Tags = append(evt.Tags, payeeTag(payee))
+		}
+	}
+
+	if len(private) > 0 {
+		content, err := encryptToSelf(private, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		evt.Content = content
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is the address book %s, with %d payees", name, len(book.Payees))
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseAddressBookEvent parses an address book, private payees are decrypted when the private key
+// of the owner is given and left out otherwise
+func ParseAddressBookEvent(evt *nostr.Event, privateKey string) (*AddressBook, error) {
+	if DefaultKind(evt.Kind) != KindAddressBook {
+		return nil, fmt.Errorf("event is not an address book event (kind %d)", evt.Kind)
+	}
+
+	book := &AddressBook{Name: evt.Tags.GetD()}
+	for _, tag := range evt.Tags {
+		if payee, ok := payeeFromTag(tag); ok {
+			book.Payees = append(book.Payees, payee)
+		}
+	}
+
+	if evt.Content == "" || privateKey == "" {
+		return book, nil
+	}
+
+	private, err := decryptFromSelf(evt.Content, evt.PubKey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range private {
+		if payee, ok := payeeFromTag(tag); ok {
+			payee.Private = true
+			book.Payees = append(book.Payees, payee)
+		}
+	}
+
+	return book, nil
+}
+
+// parsePublicAddressBook parses the public payees of an address book, for the kind registry
+func parsePublicAddressBook(evt *nostr.Event) (any, error) {
+	return ParseAddressBookEvent(evt, "")
+}
+
+// encryptToSelf encrypts tags to the owner of a private key with NIP-44
+func encryptToSelf(tags nostr.Tags, privateKey string) (string, error) {
+	if privateKey == "" {
+		return "", fmt.Errorf("private payees need the private key of the owner")
+	}
+
+	pubkey, err := nostr.GetPublicKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := nip44.GenerateConversationKey(pubkey, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := json.Marshal(tags)
+	if err != nil {
+		return "", err
+	}
+
+	return nip44.Encrypt(string(plaintext), key)
+}
+
+// decryptFromSelf decrypts tags encrypted by the owner of a private key with NIP-44, the public
+// key of the owner defaults to the one of the private key
+func decryptFromSelf(content, pubkey, privateKey string) (nostr.Tags, error) {
+	if pubkey == "" {
+		var err error
+		if pubkey, err = nostr.GetPublicKey(privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := nip44.GenerateConversationKey(pubkey, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := nip44.Decrypt(content, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private payees: %w", err)
+	}
+
+	var tags nostr.Tags
+	if err := json.Unmarshal([]byte(plaintext), &tags); err != nil {
+		return nil, fmt.Errorf("invalid private payees: %w", err)
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,78 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAddressBook(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+
+	book := AddressBook{}
+	for _, payee := range []Payee{
+		{Name: "Landlord", Address: "0x2222222222222222222222222222222222222222", ChainID: "100", Token: "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"},
+		{Name: "Vitalik", Address: "vitalik.eth", ChainID: "1"},
+		{Name: "Therapist", Address: "0x3333333333333333333333333333333333333333", ChainID: "100", Private: true},
+	} {
+		if err := book.Set(payee); err != nil {
+			t.Fatalf("Failed to add %s: %v", payee.Name, err)
+		}
+	}
+
+	if err := book.Set(Payee{Name: "Nobody", Address: "nobody"}); err == nil {
+		t.Error("Expected an error for an invalid address")
+	}
+
+	// Updating replaces the entry with the same address and chain
+	if err := book.Set(Payee{Name: "Old landlord", Address: "0x2222222222222222222222222222222222222222", ChainID: "100"}); err != nil {
+		t.Fatalf("Failed to update payee: %v", err)
+	}
+	if payee, ok := book.Get("0x2222222222222222222222222222222222222222", "100"); !ok || payee.Name != "Old landlord" {
+		t.Errorf("Expected the updated payee, got %+v", payee)
+	}
+	if len(book.Payees) != 3 {
+		t.Errorf("Expected 3 payees, got %d", len(book.Payees))
+	}
+
+	if _, err := CreateAddressBookEvent(book, ""); err == nil {
+		t.Error("Expected an error for private payees without private key")
+	}
+
+	evt, err := CreateAddressBookEvent(book, sk)
+	if err != nil {
+		t.Fatalf("Failed to create address book: %v", err)
+	}
+	if err := evt.Sign(sk); err != nil {
+		t.Fatalf("Failed to sign address book: %v", err)
+	}
+
+	if evt.Tags.GetD() != DefaultAddressBook {
+		t.Errorf("Expected d tag %s, got %s", DefaultAddressBook, evt.Tags.GetD())
+	}
+	if evt.Tags.FindWithValue("payee", "0x3333333333333333333333333333333333333333") != nil {
+		t.Error("Expected the private payee not to be listed in the tags")
+	}
+
+	// Without the private key only the public payees are readable
+	public, err := ParseAddressBookEvent(evt, "")
+	if err != nil {
+		t.Fatalf("Failed to parse address book: %v", err)
+	}
+	if len(public.Payees) != 2 {
+		t.Errorf("Expected 2 public payees, got %d", len(public.Payees))
+	}
+
+	parsed, err := ParseAddressBookEvent(evt, sk)
+	if err != nil {
+		t.Fatalf("Failed to parse address book: %v", err)
+	}
+	payee, ok := parsed.Get("0x3333333333333333333333333333333333333333", "100")
+	if !ok || payee.Name != "Therapist" || !payee.Private {
+		t.Errorf("Expected the private payee back, got %+v", payee)
+	}
+
+	if !parsed.Remove("vitalik.eth", "1") || parsed.Remove("vitalik.eth", "1") {
+		t.Error("Expected the payee to be removed once")
+	}
+}
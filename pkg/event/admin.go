@@ -0,0 +1,115 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindAdminCommand = 111024 // Configuration change of a bridge, signed by one of its operators
+)
+
+// AdminAction is the configuration change an admin command asks for
+type AdminAction string
+
+const (
+	AdminActionAddContract    AdminAction = "add_contract"    // Start watching a contract
+	AdminActionRemoveContract AdminAction = "remove_contract" // Stop watching a contract
+	AdminActionMuteAddress    AdminAction = "mute_address"    // Skip the logs of an address
+	AdminActionUnmuteAddress  AdminAction = "unmute_address"  // Stop skipping the logs of an address
+	AdminActionAddRelay       AdminAction = "add_relay"       // Publish to a relay
+	AdminActionRemoveRelay    AdminAction = "remove_relay"    // Stop publishing to a relay
+	AdminActionRotateRelay    AdminAction = "rotate_relay"    // Replace a relay with another one
+)
+
+// AdminCommandEvent represents a configuration change sent to a bridge
+type AdminCommandEvent struct {
+	Action      AdminAction `json:"action"`
+	Value       string      `json:"value"`                 // Contract, address or relay the action is about
+	Replacement string      `json:"replacement,omitempty"` // New relay of a rotation
+}
+
+// Validate checks that the value of the command fits its action
+func (c AdminCommandEvent) Validate() error {
+	switch c.Action {
+	case AdminActionAddContract, AdminActionRemoveContract, AdminActionMuteAddress, AdminActionUnmuteAddress:
+		if !common.IsHexAddress(c.Value) {
+			return fmt.Errorf("invalid address for %s: %q", c.Action, c.Value)
+		}
+	case AdminActionAddRelay, AdminActionRemoveRelay:
+		if !nostr.IsValidRelayURL(c.Value) {
+			return fmt.Errorf("invalid relay for %s: %q", c.Action, c.Value)
+		}
+	case AdminActionRotateRelay:
+		if !nostr.IsValidRelayURL(c.Value) || !nostr.IsValidRelayURL(c.Replacement) {
+			return fmt.Errorf("invalid relays for %s: %q and %q", c.Action, c.Value, c.Replacement)
+		}
+	default:
+		return fmt.Errorf("unknown admin action: %s", c.Action)
+	}
+	return nil
+}
+
+// CreateAdminCommandEvent creates an admin command (kind 111024) for the bridge signing with a
+// pubkey, the replacement is only used to rotate relays. Bridges only apply the commands of
+// their operators, and each command once.
+func CreateAdminCommandEvent(bridgePubkey string, action AdminAction, value, replacement string) (*nostr.Event, error) {
+	if !nostr.IsValid32ByteHex(bridgePubkey) {
+		return nil, fmt.Errorf("invalid bridge pubkey: %q", bridgePubkey)
+	}
+
+	command := AdminCommandEvent{Action: action, Value: value, Replacement: replacement}
+	if err := command.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal admin command: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindAdminCommand),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Bridge the command is for
+	evt.Tags = append(evt.Tags, []string{"p", bridgePubkey})
+
+	// Type and action tags
+	evt.Tags = append(evt.Tags, typeTag("admin_command"))
+	evt.Tags = append(evt.Tags, []string{"action", string(action)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an admin command to %s %s", action, value)
+	if replacement != "" {
+		alt += " with " + replacement
+	}
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseAdminCommandEvent parses an admin command, the command is validated
+func ParseAdminCommandEvent(evt *nostr.Event) (*AdminCommandEvent, error) {
+	if DefaultKind(evt.Kind) != KindAdminCommand {
+		return nil, fmt.Errorf("event is not an admin command event (kind %d)", evt.Kind)
+	}
+
+	var command AdminCommandEvent
+	if err := unmarshalContent(evt, &command); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal admin command event: %w", err)
+	}
+	if err := command.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &command, nil
+}
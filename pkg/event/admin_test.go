@@ -0,0 +1,48 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAdminCommand(t *testing.T) {
+	bridge, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	evt, err := CreateAdminCommandEvent(bridge, AdminActionRotateRelay, "wss://old.example.com", "wss://new.example.com")
+	if err != nil {
+		t.Fatalf("Failed to create admin command: %v", err)
+	}
+	if evt.Kind != KindAdminCommand {
+		t.Errorf("Expected kind %d, got %d", KindAdminCommand, evt.Kind)
+	}
+	if tag := evt.Tags.GetFirst([]string{"p", bridge}); tag == nil {
+		t.Error("Expected the bridge to be tagged")
+	}
+	if !HasTypeTag(evt, "admin_command") {
+		t.Error("Expected the admin_command type tag")
+	}
+
+	parsed, err := ParseEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse admin command: %v", err)
+	}
+	command := parsed.(*AdminCommandEvent)
+	if command.Action != AdminActionRotateRelay || command.Replacement != "wss://new.example.com" {
+		t.Errorf("Expected a rotation to wss://new.example.com, got %+v", command)
+	}
+
+	for _, c := range []AdminCommandEvent{
+		{Action: AdminActionAddContract, Value: "wss://relay.example.com"},
+		{Action: AdminActionAddRelay, Value: "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"},
+		{Action: AdminActionRotateRelay, Value: "wss://old.example.com"},
+		{Action: "shutdown", Value: "now"},
+	} {
+		if _, err := CreateAdminCommandEvent(bridge, c.Action, c.Value, c.Replacement); err == nil {
+			t.Errorf("Expected %+v to be invalid", c)
+		}
+	}
+	if _, err := CreateAdminCommandEvent("bridge", AdminActionAddRelay, "wss://relay.example.com", ""); err == nil {
+		t.Error("Expected an invalid bridge pubkey to fail")
+	}
+}
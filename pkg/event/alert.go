@@ -0,0 +1,225 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindAlert                = 111021 // Anomaly raised by a monitoring rule on an event
+	KindAlertAcknowledgement = 111022 // Acknowledges an alert
+)
+
+// AlertSeverity tells how urgent an alert is
+type AlertSeverity string
+
+const (
+	AlertSeverityInfo     AlertSeverity = "info"
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// AlertEvent represents an anomaly a monitoring rule found on an event, e.g. a large transfer
+type AlertEvent struct {
+	Rule     string        `json:"rule"`
+	Severity AlertSeverity `json:"severity"`
+	Message  string        `json:"message"`
+	Subject  string        `json:"subject,omitempty"`  // ID of the event the rule was evaluated on
+	ChainID  string        `json:"chain_id,omitempty"` // Chain of the subject, when it is a chain event
+}
+
+// AlertAcknowledgementEvent represents the acknowledgement of an alert, e.g. by a treasurer who
+// checked the transfer
+type AlertAcknowledgementEvent struct {
+	AlertID string `json:"alert_id"`
+	Note    string `json:"note,omitempty"`
+}
+
+// CreateAlertEvent creates an alert (kind 111021) raised by a rule on a subject event, the
+// subject can be nil for alerts that are not about an event
+func CreateAlertEvent(rule string, severity AlertSeverity, message string, subject *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if rule == "" {
+		return nil, fmt.Errorf("alert rule cannot be empty")
+	}
+	switch severity {
+	case AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical:
+	default:
+		return nil, fmt.Errorf("unknown alert severity: %s", severity)
+	}
+
+	alert := AlertEvent{
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+	}
+	if subject != nil {
+		if subject.ID == "" {
+			return nil, fmt.Errorf("alert subject must have an ID")
+		}
+		alert.Subject = subject.ID
+		if tag := subject.Tags.GetFirst([]string{"layer", ""}); tag != nil && len(*tag) >= 2 {
+			alert.ChainID = (*tag)[1]
+		}
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(alert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindAlert),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Reference to the subject
+	if subject != nil {
+		evt.Tags = append(evt.Tags, []string{"e", subject.ID, references.eventRelay(subject), "subject"})
+	}
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("alert")) // Type
+	evt.Tags = append(evt.Tags, typeTag(rule))    // Rule
+	evt.Tags = append(evt.Tags, []string{"severity", string(severity)})
+
+	// Chain-specific tag
+	if alert.ChainID != "" {
+		evt.Tags = append(evt.Tags, []string{"layer", alert.ChainID}) // Chain ID
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a %s alert of rule %s: %s", severity, rule, message)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseAlertEvent parses an alert
+func ParseAlertEvent(evt *nostr.Event) (*AlertEvent, error) {
+	if DefaultKind(evt.Kind) != KindAlert {
+		return nil, fmt.Errorf("event is not an alert event (kind %d)", evt.Kind)
+	}
+
+	var alert AlertEvent
+	if err := unmarshalContent(evt, &alert); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alert event: %w", err)
+	}
+
+	return &alert, nil
+}
+
+// CreateAlertAcknowledgementEvent acknowledges an alert (kind 111022), with an optional note
+func CreateAlertAcknowledgementEvent(alert *nostr.Event, note string, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if alert == nil || alert.ID == "" {
+		return nil, fmt.Errorf("alert must have an ID")
+	}
+	if DefaultKind(alert.Kind) != KindAlert {
+		return nil, fmt.Errorf("event is not an alert event (kind %d)", alert.Kind)
+	}
+
+	content, err := json.Marshal(AlertAcknowledgementEvent{AlertID: alert.ID, Note: note})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert acknowledgement: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindAlertAcknowledgement),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Reference to the alert and its author
+	evt.Tags = append(evt.Tags, []string{"e", alert.ID, references.eventRelay(alert), "root"})
+	if alert.PubKey != "" {
+		evt.Tags = append(evt.Tags, []string{"p", alert.PubKey})
+	}
+
+	// Type tag
+	evt.Tags = append(evt.Tags, typeTag("alert_acknowledgement"))
+
+	// Alt tag
+	evt.Tags = append(evt.Tags, []string{"alt", fmt.Sprintf("This acknowledges alert %s", alert.ID)})
+
+	return evt, nil
+}
+
+// ParseAlertAcknowledgementEvent parses the acknowledgement of an alert
+func ParseAlertAcknowledgementEvent(evt *nostr.Event) (*AlertAcknowledgementEvent, error) {
+	if DefaultKind(evt.Kind) != KindAlertAcknowledgement {
+		return nil, fmt.Errorf("event is not an alert acknowledgement event (kind %d)", evt.Kind)
+	}
+
+	var ack AlertAcknowledgementEvent
+	if err := unmarshalContent(evt, &ack); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alert acknowledgement event: %w", err)
+	}
+
+	return &ack, nil
+}
+
+// UnacknowledgedAlerts returns the alerts of a list of events that no acknowledgement of the list
+// refers to, newest first. Acknowledgements count whoever signed them, callers restricting who
+// may acknowledge filter them by author first.
+func UnacknowledgedAlerts(events []*nostr.Event) []*nostr.Event {
+	acknowledged := make(map[string]bool)
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) != KindAlertAcknowledgement {
+			continue
+		}
+		if ack, err := ParseAlertAcknowledgementEvent(evt); err == nil {
+			acknowledged[ack.AlertID] = true
+		}
+	}
+
+	var alerts []*nostr.Event
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) == KindAlert && !acknowledged[evt.ID] {
+			alerts = append(alerts, evt)
+		}
+	}
+	SortEventsByCreatedAt(alerts, true)
+
+	return alerts
+}
+
+// QueryUnacknowledgedAlerts queries the alerts matching a filter, e.g. by author or since a
+// time, and their acknowledgements, and returns the alerts that are not acknowledged, newest
+// first. The kinds of the filter are replaced.
+func QueryUnacknowledgedAlerts(ctx context.Context, querier EventQuerier, filter nostr.Filter) ([]*nostr.Event, error) {
+	filter.Kinds = MappedKinds(KindAlert)
+	alerts, err := querier.Query(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		ids = append(ids, alert.ID)
+	}
+
+	acks, err := querier.Query(ctx, nostr.Filter{
+		Kinds: MappedKinds(KindAlertAcknowledgement),
+		Tags:  nostr.TagMap{"e": ids},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert acknowledgements: %w", err)
+	}
+
+	return UnacknowledgedAlerts(append(alerts, acks...)), nil
+}
@@ -0,0 +1,61 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAlertAcknowledgement(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+
+	subject, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	subject.Sign(privateKey)
+
+	if _, err := CreateAlertEvent("large_transfer", "urgent", "", subject); err == nil {
+		t.Error("Expected an unknown severity to fail")
+	}
+
+	var events memoryQuerier
+	for i, severity := range []AlertSeverity{AlertSeverityWarning, AlertSeverityCritical} {
+		alert, err := CreateAlertEvent("large_transfer", severity, "Transfer of 1 EURe", subject)
+		if err != nil {
+			t.Fatalf("Failed to create alert: %v", err)
+		}
+		alert.CreatedAt += nostr.Timestamp(i)
+		alert.Sign(privateKey)
+		events = append(events, alert)
+	}
+
+	parsed, err := ParseAlertEvent(events[1])
+	if err != nil {
+		t.Fatalf("Failed to parse alert: %v", err)
+	}
+	if parsed.Subject != subject.ID || parsed.ChainID != "100" || parsed.Severity != AlertSeverityCritical {
+		t.Errorf("Expected a critical alert about %s on chain 100, got %+v", subject.ID, parsed)
+	}
+
+	// Acknowledge the first alert
+	ack, err := CreateAlertAcknowledgementEvent(events[0], "Expected payroll")
+	if err != nil {
+		t.Fatalf("Failed to create acknowledgement: %v", err)
+	}
+	ack.Sign(privateKey)
+	events = append(events, ack)
+
+	if _, err := CreateAlertAcknowledgementEvent(subject, ""); err == nil {
+		t.Error("Expected acknowledging a non-alert to fail")
+	}
+
+	unacknowledged, err := QueryUnacknowledgedAlerts(context.Background(), events, nostr.Filter{Authors: []string{ack.PubKey}})
+	if err != nil {
+		t.Fatalf("Failed to query alerts: %v", err)
+	}
+	if len(unacknowledged) != 1 || unacknowledged[0].ID != events[1].ID {
+		t.Errorf("Expected only the critical alert to be unacknowledged, got %d alerts", len(unacknowledged))
+	}
+}
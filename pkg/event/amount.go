@@ -0,0 +1,60 @@
+package event
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// SortableAmountWidth is the number of decimal digits needed to represent any uint256 value
+	SortableAmountWidth = 78
+)
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// EncodeSortableAmount encodes an amount as a fixed-width, zero-padded decimal string so
+// that lexicographic ordering matches numeric ordering
+func EncodeSortableAmount(amount *big.Int) (string, error) {
+	if amount == nil {
+		amount = new(big.Int)
+	}
+
+	if amount.Sign() < 0 {
+		return "", fmt.Errorf("amount cannot be negative: %s", amount.String())
+	}
+
+	if amount.Cmp(maxUint256) > 0 {
+		return "", fmt.Errorf("amount exceeds uint256: %s", amount.String())
+	}
+
+	digits := amount.String()
+
+	return strings.Repeat("0", SortableAmountWidth-len(digits)) + digits, nil
+}
+
+// DecodeSortableAmount decodes an amount encoded with EncodeSortableAmount
+func DecodeSortableAmount(encoded string) (*big.Int, error) {
+	if len(encoded) != SortableAmountWidth {
+		return nil, fmt.Errorf("sortable amount must be %d digits, got %d", SortableAmountWidth, len(encoded))
+	}
+
+	amount, ok := new(big.Int).SetString(encoded, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid sortable amount: %s", encoded)
+	}
+
+	return amount, nil
+}
+
+// GetSortableAmountFromEvent extracts and decodes the amount_sortable tag from a Nostr event
+func GetSortableAmountFromEvent(evt *nostr.Event) (*big.Int, error) {
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "amount_sortable" {
+			return DecodeSortableAmount(tag[1])
+		}
+	}
+	return nil, fmt.Errorf("amount_sortable tag not found in event")
+}
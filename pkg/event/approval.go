@@ -0,0 +1,430 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for approvals and allowances
+const (
+	KindTxApproval     = 111002
+	KindAllowanceState = 31100 // Addressable, one event per chain and owner
+
+	EventTypeTxApprovalCreated EventTypeTxApproval = "tx_approval_created"
+)
+
+type EventTypeTxApproval string
+
+// TxApprovalEvent represents a Nostr event for ERC20 approval logs
+type TxApprovalEvent struct {
+	LogData   neth.Log            `json:"log_data"`
+	EventType EventTypeTxApproval `json:"event_type"`
+	Tags      []string            `json:"tags,omitempty"`
+}
+
+// Allowance represents the current allowance of a spender over the tokens of an owner
+type Allowance struct {
+	ChainID   string    `json:"chain_id"`
+	Token     string    `json:"token"`
+	Owner     string    `json:"owner"`
+	Spender   string    `json:"spender"`
+	Value     string    `json:"value"`
+	TxHash    string    `json:"tx_hash,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Via is PermitSourcePermit2 for the allowances held by Permit2, which the spender uses
+	// through Permit2 on top of the ERC20 allowance of Permit2. Empty for ERC20 allowances.
+	Via PermitSource `json:"via,omitempty"`
+}
+
+// AllowanceStateEvent represents the content of an allowance state event
+type AllowanceStateEvent struct {
+	ChainID    string      `json:"chain_id"`
+	Owner      string      `json:"owner"`
+	Allowances []Allowance `json:"allowances"`
+}
+
+// GetAllowanceFromLog decodes the owner, spender and value of an ERC20 approval log
+func GetAllowanceFromLog(log neth.Log) (*Allowance, error) {
+	if !strings.EqualFold(log.Topic, neth.TopicERC20Approval) {
+		return nil, fmt.Errorf("topic is not an ERC20 approval")
+	}
+
+	values, err := defaultTopicEntries[neth.TopicERC20Approval].Decoder(log)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, ok := values[neth.DataKeyOwner]
+	if !ok {
+		return nil, fmt.Errorf("owner not found in approval data")
+	}
+
+	spender, ok := values[neth.DataKeySpender]
+	if !ok {
+		return nil, fmt.Errorf("spender not found in approval data")
+	}
+
+	value, ok := values[neth.DataKeyValue]
+	if !ok {
+		return nil, fmt.Errorf("value not found in approval data")
+	}
+
+	if _, ok := new(big.Int).SetString(value, 10); !ok {
+		return nil, fmt.Errorf("value is not a valid integer")
+	}
+
+	return &Allowance{
+		ChainID:   log.ChainID,
+		Token:     log.To,
+		Owner:     owner,
+		Spender:   spender,
+		Value:     value,
+		TxHash:    log.TxHash,
+		UpdatedAt: log.CreatedAt,
+	}, nil
+}
+
+// CreateTxApprovalEvent creates a new Nostr event for an ERC20 approval
+func CreateTxApprovalEvent(log neth.Log, opts ...LogOption) (*nostr.Event, error) {
+	options := newLogOptions(opts)
+
+	allowance, err := GetAllowanceFromLog(log)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the event data
+	eventData := TxApprovalEvent{
+		LogData:   log,
+		EventType: EventTypeTxApprovalCreated,
+		Tags:      []string{"tx_approval", "evm", log.ChainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(log.CreatedAt.Unix()),
+		Kind:      MappedKind(KindTxApproval), // Custom kind for approvals
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", log.Hash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tx_approval"))     // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", log.ChainID}) // Chain ID
+
+	// Reference tags for transaction hash
+	evt.Tags = append(evt.Tags, []string{"r", log.TxHash}) // Transaction hash as reference
+
+	evt.Tags = append(evt.Tags, []string{"P", allowance.Owner})   // Owner address
+	evt.Tags = append(evt.Tags, []string{"p", allowance.Spender}) // Spender address
+
+	evt.Tags = append(evt.Tags, []string{"amount", allowance.Value}) // Allowance
+
+	if options.sortableAmount {
+		value, _ := new(big.Int).SetString(allowance.Value, 10)
+
+		sortable, err := EncodeSortableAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		evt.Tags = append(evt.Tags, []string{"amount_sortable", sortable})
+	}
+
+	// Topic tag
+	evt.Tags = append(evt.Tags, typeTag(log.Topic))
+
+	// Contract address tag
+	evt.Tags = append(evt.Tags, typeTag(log.To))
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an evm token approval on chain %s\n Owner: %s\n Spender: %s\n Amount: %s", log.ChainID, allowance.Owner, allowance.Spender, allowance.Value)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTxApprovalEvent parses a Nostr event back into a TxApprovalEvent
+func ParseTxApprovalEvent(evt *nostr.Event) (*TxApprovalEvent, error) {
+	var txApprovalEvent TxApprovalEvent
+	err := unmarshalContent(evt, &txApprovalEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &txApprovalEvent, nil
+}
+
+// allowanceKey identifies an allowance by chain, token, owner and spender
+type allowanceKey struct {
+	chainID string
+	token   string
+	owner   string
+	spender string
+	via     PermitSource
+}
+
+func newAllowanceKey(a Allowance) allowanceKey {
+	return allowanceKey{
+		chainID: a.ChainID,
+		token:   strings.ToLower(a.Token),
+		owner:   strings.ToLower(a.Owner),
+		spender: strings.ToLower(a.Spender),
+		via:     a.Via,
+	}
+}
+
+// AllowanceTracker folds approvals into the current allowance per owner, spender and token.
+// Allowances consumed by transferFrom are not tracked since ERC20 tokens do not have to emit
+// an approval when spending an allowance.
+type AllowanceTracker struct {
+	mu         sync.RWMutex
+	allowances map[allowanceKey]Allowance
+}
+
+// NewAllowanceTracker creates a new empty allowance tracker
+func NewAllowanceTracker() *AllowanceTracker {
+	return &AllowanceTracker{
+		allowances: make(map[allowanceKey]Allowance),
+	}
+}
+
+// Apply applies an approval log, older approvals than the one already tracked are ignored
+func (t *AllowanceTracker) Apply(log neth.Log) error {
+	allowance, err := GetAllowanceFromLog(log)
+	if err != nil {
+		return err
+	}
+
+	t.set(*allowance)
+
+	return nil
+}
+
+// ApplyPermit applies a signature based allowance. EIP-2612 permits set the ERC20 allowance,
+// Permit2 permits the allowance held by Permit2.
+func (t *AllowanceTracker) ApplyPermit(permit Permit) {
+	allowance := Allowance{
+		ChainID:   permit.ChainID,
+		Token:     permit.Token,
+		Owner:     permit.Owner,
+		Spender:   permit.Spender,
+		Value:     permit.Value,
+		TxHash:    permit.TxHash,
+		UpdatedAt: permit.UpdatedAt,
+	}
+	if permit.Source == PermitSourcePermit2 {
+		allowance.Via = PermitSourcePermit2
+	}
+
+	t.set(allowance)
+}
+
+// set tracks an allowance, unless a newer one is already tracked
+func (t *AllowanceTracker) set(allowance Allowance) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := newAllowanceKey(allowance)
+	if current, ok := t.allowances[key]; ok && current.UpdatedAt.After(allowance.UpdatedAt) {
+		return
+	}
+
+	t.allowances[key] = allowance
+}
+
+// ApplyEvent applies an approval, a permit or a tx log event carrying an ERC20 approval or a
+// Permit2 allowance
+func (t *AllowanceTracker) ApplyEvent(evt *nostr.Event) error {
+	switch DefaultKind(evt.Kind) {
+	case KindTxApproval:
+		txApprovalEvent, err := ParseTxApprovalEvent(evt)
+		if err != nil {
+			return err
+		}
+		return t.Apply(txApprovalEvent.LogData)
+	case KindTxPermit:
+		permitEvent, err := ParseTxPermitEvent(evt)
+		if err != nil {
+			return err
+		}
+		t.ApplyPermit(permitEvent.Permit)
+		return nil
+	case KindTxLog:
+		txLogEvent, err := ParseTxLogEvent(evt)
+		if err != nil {
+			return err
+		}
+		if permit, err := GetPermitFromLog(txLogEvent.LogData); err == nil {
+			t.ApplyPermit(*permit)
+			return nil
+		}
+		return t.Apply(txLogEvent.LogData)
+	default:
+		return fmt.Errorf("event is not an approval event (kind %d)", evt.Kind)
+	}
+}
+
+// Allowance returns the current ERC20 allowance of a spender over the tokens of an owner
+func (t *AllowanceTracker) Allowance(chainID, token, owner, spender string) *big.Int {
+	return t.allowance(Allowance{ChainID: chainID, Token: token, Owner: owner, Spender: spender})
+}
+
+// Permit2Allowance returns the current allowance held by Permit2 of a spender over the tokens of
+// an owner, it is only spendable within the ERC20 allowance of Permit2
+func (t *AllowanceTracker) Permit2Allowance(chainID, token, owner, spender string) *big.Int {
+	return t.allowance(Allowance{ChainID: chainID, Token: token, Owner: owner, Spender: spender, Via: PermitSourcePermit2})
+}
+
+// allowance returns the current value of an allowance
+func (t *AllowanceTracker) allowance(key Allowance) *big.Int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	allowance, ok := t.allowances[newAllowanceKey(key)]
+	if !ok {
+		return new(big.Int)
+	}
+
+	value, _ := new(big.Int).SetString(allowance.Value, 10)
+	return value
+}
+
+// AllowancesForOwner returns the non-zero allowances granted by an owner on a chain
+func (t *AllowanceTracker) AllowancesForOwner(chainID, owner string) []Allowance {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var allowances []Allowance
+	for key, allowance := range t.allowances {
+		if key.chainID != chainID || key.owner != strings.ToLower(owner) || allowance.Value == "0" {
+			continue
+		}
+		allowances = append(allowances, allowance)
+	}
+
+	sortAllowances(allowances)
+
+	return allowances
+}
+
+// StateEvents generates an allowance state event for every owner tracked
+func (t *AllowanceTracker) StateEvents() ([]*nostr.Event, error) {
+	t.mu.RLock()
+	owners := make(map[allowanceKey]string)
+	for key, allowance := range t.allowances {
+		owners[allowanceKey{chainID: key.chainID, owner: key.owner}] = allowance.Owner
+	}
+	t.mu.RUnlock()
+
+	var events []*nostr.Event
+	for key, owner := range owners {
+		evt, err := CreateAllowanceStateEvent(key.chainID, owner, t.AllowancesForOwner(key.chainID, owner))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, evt)
+	}
+
+	return events, nil
+}
+
+// CreateAllowanceStateEvent creates an addressable event holding the current allowances of an owner
+func CreateAllowanceStateEvent(chainID, owner string, allowances []Allowance) (*nostr.Event, error) {
+	if allowances == nil {
+		allowances = []Allowance{}
+	}
+
+	eventData := AllowanceStateEvent{
+		ChainID:    chainID,
+		Owner:      owner,
+		Allowances: allowances,
+	}
+
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal allowance state: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindAllowanceState),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Addressable identifier, one state per chain and owner
+	evt.Tags = append(evt.Tags, []string{"d", fmt.Sprintf("%s:%s", chainID, strings.ToLower(owner))})
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("allowance_state")) // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", chainID}) // Chain ID
+
+	// Owner and spender address tags
+	evt.Tags = append(evt.Tags, []string{"P", owner})
+	for _, allowance := range allowances {
+		evt.Tags = appendUniqueTags(evt.Tags, []string{"p", allowance.Spender})
+		evt.Tags = appendUniqueTags(evt.Tags, typeTag(allowance.Token))
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("These are the %d active token allowances of %s on chain %s", len(allowances), owner, chainID)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseAllowanceStateEvent parses an allowance state event
+func ParseAllowanceStateEvent(evt *nostr.Event) (*AllowanceStateEvent, error) {
+	if DefaultKind(evt.Kind) != KindAllowanceState {
+		return nil, fmt.Errorf("event is not an allowance state event (kind %d)", evt.Kind)
+	}
+
+	var eventData AllowanceStateEvent
+	err := unmarshalContent(evt, &eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowance state: %w", err)
+	}
+
+	return &eventData, nil
+}
+
+// sortAllowances sorts allowances by token then spender
+func sortAllowances(allowances []Allowance) {
+	sort.Slice(allowances, func(i, j int) bool {
+		a, b := allowances[i], allowances[j]
+		if !strings.EqualFold(a.Token, b.Token) {
+			return strings.ToLower(a.Token) < strings.ToLower(b.Token)
+		}
+		if !strings.EqualFold(a.Spender, b.Spender) {
+			return strings.ToLower(a.Spender) < strings.ToLower(b.Spender)
+		}
+		return a.Via < b.Via
+	})
+}
@@ -0,0 +1,127 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func approvalLog(hash, spender, value string, at time.Time) neth.Log {
+	data := json.RawMessage(`{"owner":"` + permitOwner + `","spender":"` + spender + `","value":"` + value + `"}`)
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Approval,
+		CreatedAt: at,
+		To:        permitToken,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+func TestTxApprovalEvent(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	evt, err := CreateTxApprovalEvent(approvalLog("0x01", permitSpender, "1000", at))
+	if err != nil {
+		t.Fatalf("Failed to create approval event: %v", err)
+	}
+	if evt.Kind != KindTxApproval || evt.Tags.GetD() != "0x01" {
+		t.Errorf("Expected an approval event of log 0x01, got kind %d and d %q", evt.Kind, evt.Tags.GetD())
+	}
+	if tag := evt.Tags.GetFirst([]string{"P", permitOwner}); tag == nil {
+		t.Error("Expected the owner to be tagged")
+	}
+	if tag := evt.Tags.GetFirst([]string{"p", permitSpender}); tag == nil {
+		t.Error("Expected the spender to be tagged")
+	}
+	if tag := evt.Tags.GetFirst([]string{"amount", "1000"}); tag == nil {
+		t.Error("Expected the allowance to be tagged")
+	}
+
+	parsed, err := ParseTxApprovalEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse approval event: %v", err)
+	}
+	if parsed.LogData.Hash != "0x01" || parsed.EventType != EventTypeTxApprovalCreated {
+		t.Errorf("Expected the approval back, got %+v", parsed)
+	}
+
+	transfer := approvalLog("0x02", permitSpender, "1000", at)
+	transfer.Topic = neth.TopicERC20Transfer
+	if _, err := CreateTxApprovalEvent(transfer); err == nil {
+		t.Error("Expected a transfer log to be rejected")
+	}
+	if _, err := CreateTxApprovalEvent(approvalLog("0x03", permitSpender, "abc", at)); err == nil {
+		t.Error("Expected an invalid value to be rejected")
+	}
+}
+
+func TestAllowanceTracker(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	other := "0x3333333333333333333333333333333333333333"
+	tracker := NewAllowanceTracker()
+
+	approval, _ := CreateTxApprovalEvent(approvalLog("0x01", permitSpender, "1000", at))
+	for _, log := range []neth.Log{
+		approvalLog("0x02", permitSpender, "500", at.Add(time.Minute)),
+		approvalLog("0x03", other, "7", at),
+	} {
+		if err := tracker.Apply(log); err != nil {
+			t.Fatalf("Failed to apply approval: %v", err)
+		}
+	}
+
+	// Older approvals do not replace newer ones
+	if err := tracker.ApplyEvent(approval); err != nil {
+		t.Fatalf("Failed to apply approval event: %v", err)
+	}
+	if allowance := tracker.Allowance("100", permitToken, permitOwner, permitSpender); allowance.String() != "500" {
+		t.Errorf("Expected the latest allowance of 500, got %s", allowance)
+	}
+
+	// Addresses are compared case-insensitively
+	if allowance := tracker.Allowance("100", strings.ToLower(permitToken), permitOwner, permitSpender); allowance.String() != "500" {
+		t.Errorf("Expected the allowance of the lowercase token, got %s", allowance)
+	}
+	if allowance := tracker.Allowance("1", permitToken, permitOwner, permitSpender); allowance.Sign() != 0 {
+		t.Errorf("Expected no allowance on another chain, got %s", allowance)
+	}
+
+	// Revoked allowances are not active
+	if err := tracker.Apply(approvalLog("0x04", other, "0", at.Add(time.Minute))); err != nil {
+		t.Fatalf("Failed to apply approval: %v", err)
+	}
+	allowances := tracker.AllowancesForOwner("100", permitOwner)
+	if len(allowances) != 1 || allowances[0].Spender != permitSpender {
+		t.Fatalf("Expected the allowance of the spender only, got %+v", allowances)
+	}
+
+	events, err := tracker.StateEvents()
+	if err != nil {
+		t.Fatalf("Failed to create state events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected one state event, got %d", len(events))
+	}
+	if d := events[0].Tags.GetD(); d != "100:"+permitOwner {
+		t.Errorf("Expected the state of the owner on chain 100, got %s", d)
+	}
+
+	state, err := ParseAllowanceStateEvent(events[0])
+	if err != nil {
+		t.Fatalf("Failed to parse state event: %v", err)
+	}
+	if len(state.Allowances) != 1 || state.Allowances[0].Value != "500" || state.Allowances[0].TxHash != "0xabc" {
+		t.Errorf("Expected the active allowance in the state, got %+v", state.Allowances)
+	}
+
+	if err := tracker.ApplyEvent(events[0]); err == nil {
+		t.Error("Expected a state event to be rejected")
+	}
+}
@@ -0,0 +1,240 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for tx log attestations
+const (
+	KindTxLogAttestation = 111011
+
+	EventTypeTxLogAttested EventTypeTxLogAttestation = "tx_log_attested"
+)
+
+type EventTypeTxLogAttestation string
+
+// TxLogAttestationEvent represents an observer confirming that it saw a log on chain
+type TxLogAttestationEvent struct {
+	LogHash     string                    `json:"log_hash"`
+	TxHash      string                    `json:"tx_hash"`
+	ChainID     string                    `json:"chain_id"`
+	Observation string                    `json:"observation"`
+	EventType   EventTypeTxLogAttestation `json:"event_type"`
+	Tags        []string                  `json:"tags,omitempty"`
+}
+
+// ObservationHash hashes the on-chain fields of a log. The timestamps set by the observer are
+// left out so that independent observers of the same log produce the same hash.
+func ObservationHash(log neth.Log) string {
+	buf := new(bytes.Buffer)
+
+	buf.Write(common.FromHex(log.Hash))
+	buf.Write(common.FromHex(log.TxHash))
+	buf.WriteString(log.ChainID)
+	buf.Write(common.FromHex(log.Topic))
+	buf.Write(common.FromHex(log.Sender))
+	buf.Write(common.FromHex(log.To))
+	if log.Value != nil {
+		buf.Write(common.LeftPadBytes(log.Value.Bytes(), 32))
+	}
+	buf.Write(sortedJSON(log.Data))
+
+	return crypto.Keccak256Hash(buf.Bytes()).Hex()
+}
+
+// sortedJSON returns the log data with sorted object keys, data that is not JSON is returned as is
+func sortedJSON(data *json.RawMessage) []byte {
+	if data == nil {
+		return nil
+	}
+	return canonicalContent(string(*data))
+}
+
+// CreateTxLogAttestationEvent creates a new Nostr event attesting that the observer saw the log,
+// the event references the tx log event through its d tag
+func CreateTxLogAttestationEvent(log neth.Log) (*nostr.Event, error) {
+	observation := ObservationHash(log)
+
+	// Create the event data
+	eventData := TxLogAttestationEvent{
+		LogHash:     log.Hash,
+		TxHash:      log.TxHash,
+		ChainID:     log.ChainID,
+		Observation: observation,
+		EventType:   EventTypeTxLogAttested,
+		Tags:        []string{"tx_log_attestation", "evm", log.ChainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindTxLogAttestation), // Custom kind for tx log attestations
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", log.Hash}) // Identifier of the attested log
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tx_log_attestation")) // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})    // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", log.ChainID}) // Chain ID
+
+	// Reference tags for transaction hash and attested kind
+	evt.Tags = append(evt.Tags, []string{"r", log.TxHash})
+	evt.Tags = append(evt.Tags, []string{"k", fmt.Sprint(MappedKind(KindTxLog))})
+
+	// Observation tag, observers agree when their observation hashes match
+	evt.Tags = append(evt.Tags, []string{"observation", observation})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an attestation of log %s in transaction %s on chain %s", log.Hash, log.TxHash, log.ChainID)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTxLogAttestationEvent parses a Nostr event back into a TxLogAttestationEvent
+func ParseTxLogAttestationEvent(evt *nostr.Event) (*TxLogAttestationEvent, error) {
+	var attestationEvent TxLogAttestationEvent
+	err := unmarshalContent(evt, &attestationEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &attestationEvent, nil
+}
+
+// AttestationQuorum is the state of the attestations of a log
+type AttestationQuorum struct {
+	LogHash     string
+	Observation string   // Observation with the most attestations
+	Observers   []string // Public keys attesting the observation, sorted
+	Conflicting int      // Number of observers attesting a different observation
+	Reached     bool
+}
+
+// AttestationAggregator collects the attestations of a fixed set of observers and reports
+// when N of them agree on a log
+type AttestationAggregator struct {
+	mu sync.Mutex
+
+	observers    map[string]bool
+	threshold    int
+	attestations map[string]map[string]attestation
+}
+
+type attestation struct {
+	observation string
+	createdAt   nostr.Timestamp
+}
+
+// NewAttestationAggregator creates a new aggregator requiring threshold of the observers,
+// identified by their public keys, to agree
+func NewAttestationAggregator(observers []string, threshold int) (*AttestationAggregator, error) {
+	if threshold <= 0 || threshold > len(observers) {
+		return nil, fmt.Errorf("invalid threshold %d for %d observers", threshold, len(observers))
+	}
+
+	a := &AttestationAggregator{
+		observers:    make(map[string]bool),
+		threshold:    threshold,
+		attestations: make(map[string]map[string]attestation),
+	}
+
+	for _, observer := range observers {
+		a.observers[observer] = true
+	}
+
+	return a, nil
+}
+
+// Add adds a signed attestation event, an observer attesting the same log again replaces its
+// previous attestation when the new one is more recent
+func (a *AttestationAggregator) Add(evt *nostr.Event) error {
+	if DefaultKind(evt.Kind) != KindTxLogAttestation {
+		return fmt.Errorf("event kind %d is not an attestation", evt.Kind)
+	}
+
+	if !a.observers[evt.PubKey] {
+		return fmt.Errorf("%s is not an observer", evt.PubKey)
+	}
+
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return fmt.Errorf("invalid attestation signature from %s", evt.PubKey)
+	}
+
+	attestationEvent, err := ParseTxLogAttestationEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byObserver, ok := a.attestations[attestationEvent.LogHash]
+	if !ok {
+		byObserver = make(map[string]attestation)
+		a.attestations[attestationEvent.LogHash] = byObserver
+	}
+
+	if previous, ok := byObserver[evt.PubKey]; ok && previous.createdAt > evt.CreatedAt {
+		return nil
+	}
+
+	byObserver[evt.PubKey] = attestation{
+		observation: attestationEvent.Observation,
+		createdAt:   evt.CreatedAt,
+	}
+
+	return nil
+}
+
+// Quorum returns the state of the attestations of a log
+func (a *AttestationAggregator) Quorum(logHash string) AttestationQuorum {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	quorum := AttestationQuorum{LogHash: logHash}
+
+	byObservation := make(map[string][]string)
+	for observer, att := range a.attestations[logHash] {
+		byObservation[att.observation] = append(byObservation[att.observation], observer)
+	}
+
+	total := 0
+	for observation, observers := range byObservation {
+		total += len(observers)
+
+		// Ties are broken on the observation hash to keep the result deterministic
+		if len(observers) > len(quorum.Observers) || (len(observers) == len(quorum.Observers) && observation < quorum.Observation) {
+			quorum.Observation = observation
+			quorum.Observers = observers
+		}
+	}
+
+	sort.Strings(quorum.Observers)
+	quorum.Conflicting = total - len(quorum.Observers)
+	quorum.Reached = len(quorum.Observers) >= a.threshold
+
+	return quorum
+}
@@ -0,0 +1,87 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAttestationAggregator(t *testing.T) {
+	data := json.RawMessage(`{"to":"0x2222222222222222222222222222222222222222","from":"0x1111111111111111111111111111111111111111","value":"10"}`)
+	log := neth.Log{
+		Hash:      "0x1234",
+		TxHash:    "0xabcd",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+
+	keys := []string{nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey(), nostr.GeneratePrivateKey()}
+	observers := make([]string, len(keys))
+	for i, key := range keys {
+		observers[i], _ = nostr.GetPublicKey(key)
+	}
+
+	aggregator, err := NewAttestationAggregator(observers, 2)
+	if err != nil {
+		t.Fatalf("Failed to create aggregator: %v", err)
+	}
+
+	attest := func(key string, log neth.Log) *nostr.Event {
+		evt, err := CreateTxLogAttestationEvent(log)
+		if err != nil {
+			t.Fatalf("Failed to create attestation: %v", err)
+		}
+		if err := evt.Sign(key); err != nil {
+			t.Fatalf("Failed to sign attestation: %v", err)
+		}
+		return evt
+	}
+
+	// The same log seen at a different time produces the same observation
+	if err := aggregator.Add(attest(keys[0], log)); err != nil {
+		t.Fatalf("Failed to add attestation: %v", err)
+	}
+	if aggregator.Quorum(log.Hash).Reached {
+		t.Error("Expected quorum not to be reached with one attestation")
+	}
+
+	seenLater := log
+	seenLater.CreatedAt = log.CreatedAt.Add(time.Minute)
+	if err := aggregator.Add(attest(keys[1], seenLater)); err != nil {
+		t.Fatalf("Failed to add attestation: %v", err)
+	}
+
+	tampered := log
+	tampered.Value = big.NewInt(1)
+	if err := aggregator.Add(attest(keys[2], tampered)); err != nil {
+		t.Fatalf("Failed to add attestation: %v", err)
+	}
+
+	quorum := aggregator.Quorum(log.Hash)
+	if !quorum.Reached {
+		t.Fatal("Expected quorum to be reached")
+	}
+	if quorum.Observation != ObservationHash(log) {
+		t.Errorf("Expected observation %s, got %s", ObservationHash(log), quorum.Observation)
+	}
+	if len(quorum.Observers) != 2 || quorum.Conflicting != 1 {
+		t.Errorf("Expected 2 agreeing and 1 conflicting observer, got %d and %d", len(quorum.Observers), quorum.Conflicting)
+	}
+
+	if err := aggregator.Add(attest(nostr.GeneratePrivateKey(), log)); err == nil {
+		t.Error("Expected attestation from an unknown observer to be rejected")
+	}
+
+	forged := attest(keys[2], log)
+	forged.Content = `{"log_hash":"0x1234","observation":"0x00"}`
+	if err := aggregator.Add(forged); err == nil {
+		t.Error("Expected attestation with an invalid signature to be rejected")
+	}
+}
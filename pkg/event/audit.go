@@ -0,0 +1,125 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindAuditRecord = 31106 // Addressable, one event per audited operation
+)
+
+// AuditOperation is a significant operation of a bridge
+type AuditOperation string
+
+const (
+	AuditBackfillStarted AuditOperation = "backfill_started" // Missed logs are being published
+	AuditReorgHandled    AuditOperation = "reorg_handled"    // Logs of orphaned blocks were rolled back
+	AuditConfigApplied   AuditOperation = "config_applied"   // A new config took effect
+	AuditRelayRemoved    AuditOperation = "relay_removed"    // A relay is no longer published to
+)
+
+// AuditRecord describes an operation of a bridge, so that operators and communities can check
+// what it did
+type AuditRecord struct {
+	ID        string            `json:"id"` // d tag, records with the same ID replace each other
+	Operation AuditOperation    `json:"operation"`
+	Message   string            `json:"message"`
+	ChainID   string            `json:"chain_id,omitempty"`
+	Block     uint64            `json:"block,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	At        time.Time         `json:"at"`
+}
+
+// CreateAuditRecordEvent creates an audit record (kind 31106). Records without an ID get one
+// from their operation and time.
+func CreateAuditRecordEvent(record AuditRecord) (*nostr.Event, error) {
+	if record.Operation == "" {
+		return nil, fmt.Errorf("audit operation cannot be empty")
+	}
+	if record.At.IsZero() {
+		record.At = timeNow()
+	}
+	if record.ID == "" {
+		record.ID = string(record.Operation) + ":" + strconv.FormatInt(record.At.UnixNano(), 10)
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(record.At.Unix()),
+		Kind:      MappedKind(KindAuditRecord),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Identifier of the record
+	evt.Tags = append(evt.Tags, []string{"d", record.ID})
+
+	// Type and operation tags
+	evt.Tags = append(evt.Tags, typeTag("audit"))
+	evt.Tags = append(evt.Tags, []string{"operation", string(record.Operation)})
+
+	// Chain-specific tag
+	if record.ChainID != "" {
+		evt.Tags = append(evt.Tags, []string{"layer", record.ChainID}) // Chain ID
+	}
+
+	// Alt tag
+	evt.Tags = append(evt.Tags, []string{"alt", fmt.Sprintf("This is an audit record of a bridge: %s", record.Message)})
+
+	return evt, nil
+}
+
+// ParseAuditRecordEvent parses an audit record
+func ParseAuditRecordEvent(evt *nostr.Event) (*AuditRecord, error) {
+	if DefaultKind(evt.Kind) != KindAuditRecord {
+		return nil, fmt.Errorf("event is not an audit record event (kind %d)", evt.Kind)
+	}
+
+	var record AuditRecord
+	if err := unmarshalContent(evt, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit record event: %w", err)
+	}
+
+	return &record, nil
+}
+
+// AuditTrail returns the audit records of a list of events, newest first. Only the latest event
+// of each author and ID is kept, as relays do for addressable events.
+func AuditTrail(events []*nostr.Event) []AuditRecord {
+	latest := make(map[string]*nostr.Event)
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) != KindAuditRecord {
+			continue
+		}
+		key := evt.PubKey + ":" + evt.Tags.GetD()
+		if existing, ok := latest[key]; !ok || evt.CreatedAt > existing.CreatedAt {
+			latest[key] = evt
+		}
+	}
+
+	records := make([]AuditRecord, 0, len(latest))
+	for _, evt := range latest {
+		if record, err := ParseAuditRecordEvent(evt); err == nil {
+			records = append(records, *record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].At.Equal(records[j].At) {
+			return records[i].At.After(records[j].At)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAuditTrail(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+	at := time.Unix(1700000000, 0)
+
+	record := func(r AuditRecord) *nostr.Event {
+		t.Helper()
+		evt, err := CreateAuditRecordEvent(r)
+		if err != nil {
+			t.Fatalf("Failed to create audit record: %v", err)
+		}
+		evt.Sign(privateKey)
+		return evt
+	}
+
+	if _, err := CreateAuditRecordEvent(AuditRecord{Message: "nothing"}); err == nil {
+		t.Error("Expected a record without an operation to fail")
+	}
+
+	reorg := record(AuditRecord{Operation: AuditReorgHandled, Message: "Reorg", ChainID: "100", Block: 2, At: at})
+	if reorg.Kind != KindAuditRecord || reorg.Tags.GetD() == "" {
+		t.Errorf("Expected an addressable audit record with a d tag, got kind %d", reorg.Kind)
+	}
+	if tag := reorg.Tags.GetFirst([]string{"operation", string(AuditReorgHandled)}); tag == nil {
+		t.Error("Expected the operation tag")
+	}
+
+	parsed, err := ParseEvent(reorg)
+	if err != nil {
+		t.Fatalf("Failed to parse audit record: %v", err)
+	}
+	if r := parsed.(*AuditRecord); r.ChainID != "100" || r.Block != 2 {
+		t.Errorf("Expected the reorg of block 2 on chain 100, got %+v", r)
+	}
+
+	// A record replaced by a later one of the same ID
+	started := record(AuditRecord{ID: "backfill", Operation: AuditBackfillStarted, Message: "Started", At: at.Add(time.Second)})
+	updated := record(AuditRecord{ID: "backfill", Operation: AuditBackfillStarted, Message: "Half way", At: at.Add(2 * time.Second)})
+
+	records := AuditTrail([]*nostr.Event{started, reorg, updated})
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Message != "Half way" || records[1].Operation != AuditReorgHandled {
+		t.Errorf("Expected the latest backfill record then the reorg, got %+v", records)
+	}
+}
@@ -0,0 +1,150 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindBalanceSnapshot = 31105 // Addressable, one event per chain, token and address
+
+	EventTypeBalanceSnapshot EventTypeSnapshot = "balance_snapshot"
+)
+
+type EventTypeSnapshot string
+
+// BalanceSnapshot is the balance of an address in a token at a block
+type BalanceSnapshot struct {
+	ChainID     string    `json:"chain_id"`
+	Token       string    `json:"token"`
+	Address     string    `json:"address"`
+	BlockNumber uint64    `json:"block_number"`
+	BlockHash   string    `json:"block_hash,omitempty"`
+	Balance     string    `json:"balance"` // Base units
+	TakenAt     time.Time `json:"taken_at"`
+}
+
+// BalanceSnapshotEvent represents a Nostr event publishing a balance snapshot
+type BalanceSnapshotEvent struct {
+	Snapshot  BalanceSnapshot   `json:"snapshot"`
+	EventType EventTypeSnapshot `json:"event_type"`
+}
+
+// BalanceSnapshotID returns the d tag of the balance snapshots of an address in a token
+func BalanceSnapshotID(chainID, token, address string) string {
+	return strings.ToLower(fmt.Sprintf("%s:%s:%s", chainID, token, address))
+}
+
+// CreateBalanceSnapshotEvent creates a new Nostr event publishing a balance snapshot, later
+// snapshots of the same address and token replace earlier ones
+func CreateBalanceSnapshotEvent(snapshot BalanceSnapshot) (*nostr.Event, error) {
+	if snapshot.ChainID == "" || snapshot.Token == "" || snapshot.Address == "" {
+		return nil, fmt.Errorf("balance snapshot needs a chain, a token and an address")
+	}
+	if _, ok := new(big.Int).SetString(snapshot.Balance, 10); !ok {
+		return nil, fmt.Errorf("invalid balance: %s", snapshot.Balance)
+	}
+	if snapshot.TakenAt.IsZero() {
+		snapshot.TakenAt = timeNow()
+	}
+
+	// Create the event data
+	eventData := BalanceSnapshotEvent{
+		Snapshot:  snapshot,
+		EventType: EventTypeBalanceSnapshot,
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal balance snapshot: %w", err)
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(snapshot.TakenAt.Unix()),
+		Kind:      MappedKind(KindBalanceSnapshot),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Addressable identifier, one per chain, token and address
+	evt.Tags = append(evt.Tags, []string{"d", BalanceSnapshotID(snapshot.ChainID, snapshot.Token, snapshot.Address)})
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("balance_snapshot"))         // Type
+	evt.Tags = append(evt.Tags, typeTag(snapshot.Token))             // Token
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})          // Blockchain
+	evt.Tags = append(evt.Tags, []string{"layer", snapshot.ChainID}) // Chain ID
+
+	// Address tag
+	evt.Tags = append(evt.Tags, []string{"p", snapshot.Address})
+
+	// Block tags
+	evt.Tags = append(evt.Tags, []string{"block", strconv.FormatUint(snapshot.BlockNumber, 10)})
+	if snapshot.BlockHash != "" {
+		evt.Tags = append(evt.Tags, []string{"block_hash", snapshot.BlockHash})
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is the balance of %s in %s at block %d on chain %s", snapshot.Address, snapshot.Token, snapshot.BlockNumber, snapshot.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseBalanceSnapshotEvent parses a Nostr event back into a BalanceSnapshotEvent
+func ParseBalanceSnapshotEvent(evt *nostr.Event) (*BalanceSnapshotEvent, error) {
+	if DefaultKind(evt.Kind) != KindBalanceSnapshot {
+		return nil, fmt.Errorf("event is not a balance snapshot event (kind %d)", evt.Kind)
+	}
+
+	var snapshotEvent BalanceSnapshotEvent
+	if err := unmarshalContent(evt, &snapshotEvent); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal balance snapshot event: %w", err)
+	}
+
+	return &snapshotEvent, nil
+}
+
+// LatestBalanceSnapshot returns the latest balance snapshot of an address in a token, nil when
+// there is none. Snapshots published by several authors are compared by block, then by time.
+func LatestBalanceSnapshot(ctx context.Context, querier EventQuerier, chainID, token, address string) (*BalanceSnapshotEvent, error) {
+	events, err := querier.Query(ctx, nostr.Filter{
+		Kinds: MappedKinds(KindBalanceSnapshot),
+		Tags:  nostr.TagMap{"d": []string{BalanceSnapshotID(chainID, token, address)}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balance snapshots: %w", err)
+	}
+
+	var latest *BalanceSnapshotEvent
+	for _, evt := range events {
+		snapshotEvent, err := ParseBalanceSnapshotEvent(evt)
+		if err != nil {
+			continue
+		}
+
+		if latest == nil || newerSnapshot(snapshotEvent.Snapshot, latest.Snapshot) {
+			latest = snapshotEvent
+		}
+	}
+
+	return latest, nil
+}
+
+// newerSnapshot checks if a snapshot was taken after another
+func newerSnapshot(snapshot, current BalanceSnapshot) bool {
+	if snapshot.BlockNumber != current.BlockNumber {
+		return snapshot.BlockNumber > current.BlockNumber
+	}
+	return snapshot.TakenAt.After(current.TakenAt)
+}
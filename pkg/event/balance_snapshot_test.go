@@ -0,0 +1,79 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestBalanceSnapshotEvent(t *testing.T) {
+	snapshot := BalanceSnapshot{
+		ChainID:     "100",
+		Token:       "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d",
+		Address:     "0x1111111111111111111111111111111111111111",
+		BlockNumber: 42,
+		BlockHash:   "0xabc",
+		Balance:     "1000000000000000000",
+		TakenAt:     time.Unix(1700000000, 0),
+	}
+
+	evt, err := CreateBalanceSnapshotEvent(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to create balance snapshot: %v", err)
+	}
+	if evt.Kind != KindBalanceSnapshot || !nostr.IsAddressableKind(evt.Kind) {
+		t.Fatalf("Expected addressable kind %d, got %d", KindBalanceSnapshot, evt.Kind)
+	}
+	if d := evt.Tags.GetD(); d != "100:0xe91d153e0b41518a2ce8dd3d7944fa863463a97d:0x1111111111111111111111111111111111111111" {
+		t.Errorf("Expected a lowercase d tag, got %s", d)
+	}
+
+	parsed, err := ParseBalanceSnapshotEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse balance snapshot: %v", err)
+	}
+	if parsed.Snapshot.Balance != snapshot.Balance || parsed.Snapshot.BlockNumber != 42 {
+		t.Errorf("Expected %s at block 42, got %s at block %d", snapshot.Balance, parsed.Snapshot.Balance, parsed.Snapshot.BlockNumber)
+	}
+
+	if _, err := CreateBalanceSnapshotEvent(BalanceSnapshot{ChainID: "100", Token: snapshot.Token, Address: snapshot.Address, Balance: "1.5"}); err == nil {
+		t.Error("Expected an invalid balance to fail")
+	}
+}
+
+func TestLatestBalanceSnapshot(t *testing.T) {
+	token := "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	address := "0x1111111111111111111111111111111111111111"
+
+	var events memoryQuerier
+	for i, block := range []uint64{10, 30, 20} {
+		evt, err := CreateBalanceSnapshotEvent(BalanceSnapshot{
+			ChainID:     "100",
+			Token:       token,
+			Address:     address,
+			BlockNumber: block,
+			Balance:     "100",
+			TakenAt:     time.Unix(1700000000+int64(i), 0),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create balance snapshot: %v", err)
+		}
+		evt.Sign(nostr.GeneratePrivateKey())
+		events = append(events, evt)
+	}
+
+	latest, err := LatestBalanceSnapshot(context.Background(), events, "100", token, address)
+	if err != nil {
+		t.Fatalf("Failed to get the latest snapshot: %v", err)
+	}
+	if latest == nil || latest.Snapshot.BlockNumber != 30 {
+		t.Fatalf("Expected the snapshot of block 30, got %+v", latest)
+	}
+
+	latest, err = LatestBalanceSnapshot(context.Background(), events, "100", token, "0x2222222222222222222222222222222222222222")
+	if err != nil || latest != nil {
+		t.Errorf("Expected no snapshot, got %+v (%v)", latest, err)
+	}
+}
@@ -0,0 +1,246 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for bridge transfers
+const (
+	KindBridgeTransfer = 111003
+
+	EventTypeBridgeTransferInitiated EventTypeBridgeTransfer = "bridge_transfer_initiated"
+	EventTypeBridgeTransferFinalized EventTypeBridgeTransfer = "bridge_transfer_finalized"
+)
+
+type EventTypeBridgeTransfer string
+
+// bridgeMessageHashKeys are the data keys commonly used by bridges for the cross-chain message hash
+var bridgeMessageHashKeys = []string{"messageHash", "message_hash", "msgHash", "withdrawalHash", "depositHash"}
+
+// BridgeTransferEvent represents a Nostr event linking the two sides of a bridge transfer
+type BridgeTransferEvent struct {
+	MessageHash  string                  `json:"message_hash"`
+	Deposit      neth.Log                `json:"deposit"`
+	Finalization *neth.Log               `json:"finalization,omitempty"`
+	EventType    EventTypeBridgeTransfer `json:"event_type"`
+	Tags         []string                `json:"tags,omitempty"`
+}
+
+// IsFinalized checks if the finalization side of the transfer has been observed
+func (b *BridgeTransferEvent) IsFinalized() bool {
+	return b.Finalization != nil
+}
+
+// GetBridgeMessageHash extracts the cross-chain message hash from the data of a log
+func GetBridgeMessageHash(log neth.Log) (string, bool) {
+	data, err := log.GetEventData()
+	if err != nil || data == nil {
+		return "", false
+	}
+
+	for _, key := range bridgeMessageHashKeys {
+		if hash, ok := data[key].(string); ok && hash != "" {
+			return strings.ToLower(hash), true
+		}
+	}
+
+	return "", false
+}
+
+// CreateBridgeTransferEvent creates a new Nostr event for a bridge transfer, the finalization
+// log is nil while the transfer is in flight
+func CreateBridgeTransferEvent(messageHash string, deposit neth.Log, finalization *neth.Log) (*nostr.Event, error) {
+	eventType := EventTypeBridgeTransferInitiated
+	if finalization != nil {
+		eventType = EventTypeBridgeTransferFinalized
+	}
+
+	tags := []string{"bridge_transfer", "evm", deposit.ChainID}
+	if finalization != nil {
+		tags = append(tags, finalization.ChainID)
+	}
+
+	// Create the event data
+	eventData := BridgeTransferEvent{
+		MessageHash:  messageHash,
+		Deposit:      deposit,
+		Finalization: finalization,
+		EventType:    eventType,
+		Tags:         tags,
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := deposit.CreatedAt
+	if finalization != nil {
+		createdAt = finalization.CreatedAt
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(createdAt.Unix()),
+		Kind:      MappedKind(KindBridgeTransfer), // Custom kind for bridge transfers
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", messageHash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("bridge_transfer")) // Type
+	evt.Tags = append(evt.Tags, typeTag(string(eventType))) // Event type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tags, source first
+	evt.Tags = append(evt.Tags, []string{"layer", deposit.ChainID, "source"})
+	if finalization != nil {
+		evt.Tags = append(evt.Tags, []string{"layer", finalization.ChainID, "destination"})
+	}
+
+	// Reference tags for transaction hashes
+	evt.Tags = append(evt.Tags, []string{"r", deposit.TxHash, "source"})
+	if finalization != nil {
+		evt.Tags = append(evt.Tags, []string{"r", finalization.TxHash, "destination"})
+	}
+
+	// Address tags
+	transfer, err := deposit.GetTransferData()
+	if err == nil && transfer != nil {
+		if transfer.From != "" {
+			evt.Tags = append(evt.Tags, []string{"P", transfer.From}) // Sender address
+		}
+		if transfer.To != "" {
+			evt.Tags = append(evt.Tags, []string{"p", transfer.To}) // Recipient address
+		}
+		if transfer.Value != "" {
+			evt.Tags = append(evt.Tags, []string{"amount", transfer.Value}) // Amount
+		}
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a bridge transfer from chain %s", deposit.ChainID)
+	if finalization != nil {
+		alt += fmt.Sprintf(" to chain %s, finalized in transaction %s", finalization.ChainID, finalization.TxHash)
+	} else {
+		alt += ", waiting for finalization"
+	}
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseBridgeTransferEvent parses a Nostr event back into a BridgeTransferEvent
+func ParseBridgeTransferEvent(evt *nostr.Event) (*BridgeTransferEvent, error) {
+	var bridgeTransferEvent BridgeTransferEvent
+	err := unmarshalContent(evt, &bridgeTransferEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &bridgeTransferEvent, nil
+}
+
+// BridgeCorrelator matches deposit logs on one chain with the finalization logs on another
+type BridgeCorrelator struct {
+	mu sync.Mutex
+
+	depositTopics      map[string]bool
+	finalizationTopics map[string]bool
+	messageHash        func(log neth.Log) (string, bool)
+
+	deposits      map[string]neth.Log
+	finalizations map[string]neth.Log
+}
+
+// NewBridgeCorrelator creates a new correlator for the given deposit and finalization topics,
+// the message hash is read from the log data when messageHash is nil
+func NewBridgeCorrelator(depositTopics, finalizationTopics []string, messageHash func(log neth.Log) (string, bool)) *BridgeCorrelator {
+	if messageHash == nil {
+		messageHash = GetBridgeMessageHash
+	}
+
+	c := &BridgeCorrelator{
+		depositTopics:      make(map[string]bool),
+		finalizationTopics: make(map[string]bool),
+		messageHash:        messageHash,
+		deposits:           make(map[string]neth.Log),
+		finalizations:      make(map[string]neth.Log),
+	}
+
+	for _, topic := range depositTopics {
+		c.depositTopics[strings.ToLower(topic)] = true
+	}
+	for _, topic := range finalizationTopics {
+		c.finalizationTopics[strings.ToLower(topic)] = true
+	}
+
+	return c
+}
+
+// Add adds a log to the correlator and returns the bridge transfer event it produces, if any.
+// A deposit produces an initiated event, a finalization produces a finalized event once its
+// deposit is known. Logs that are not part of a bridge transfer return nil.
+func (c *BridgeCorrelator) Add(log neth.Log) (*nostr.Event, error) {
+	topic := strings.ToLower(log.Topic)
+
+	isDeposit := c.depositTopics[topic]
+	isFinalization := c.finalizationTopics[topic]
+	if !isDeposit && !isFinalization {
+		return nil, nil
+	}
+
+	hash, ok := c.messageHash(log)
+	if !ok {
+		return nil, fmt.Errorf("message hash not found in log %s", log.Hash)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if isDeposit {
+		c.deposits[hash] = log
+
+		if finalization, ok := c.finalizations[hash]; ok {
+			delete(c.deposits, hash)
+			delete(c.finalizations, hash)
+			return CreateBridgeTransferEvent(hash, log, &finalization)
+		}
+
+		return CreateBridgeTransferEvent(hash, log, nil)
+	}
+
+	deposit, ok := c.deposits[hash]
+	if !ok {
+		// The finalization was observed first, wait for the deposit
+		c.finalizations[hash] = log
+		return nil, nil
+	}
+
+	delete(c.deposits, hash)
+
+	return CreateBridgeTransferEvent(hash, deposit, &log)
+}
+
+// Pending returns the deposits that have not been finalized yet
+func (c *BridgeCorrelator) Pending() []neth.Log {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending := make([]neth.Log, 0, len(c.deposits))
+	for _, deposit := range c.deposits {
+		pending = append(pending, deposit)
+	}
+	return pending
+}
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+const (
+	bridgeDepositTopic      = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	bridgeFinalizationTopic = "0x2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func bridgeLog(hash, chainID, topic, data string, at time.Time) neth.Log {
+	raw := json.RawMessage(data)
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xtx" + hash,
+		ChainID:   chainID,
+		Topic:     topic,
+		CreatedAt: at,
+		Value:     big.NewInt(0),
+		Data:      &raw,
+	}
+}
+
+func TestBridgeCorrelator(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	depositData := `{"messageHash":"0xABC","from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"1000"}`
+
+	deposit := bridgeLog("0x01", "1", bridgeDepositTopic, depositData, at)
+	finalization := bridgeLog("0x02", "100", bridgeFinalizationTopic, `{"message_hash":"0xabc"}`, at.Add(time.Hour))
+
+	t.Run("deposit before finalization", func(t *testing.T) {
+		c := NewBridgeCorrelator([]string{bridgeDepositTopic}, []string{bridgeFinalizationTopic}, nil)
+
+		evt, err := c.Add(deposit)
+		if err != nil || evt == nil {
+			t.Fatalf("Expected an initiated event, got %v", err)
+		}
+		initiated, err := ParseBridgeTransferEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse bridge transfer: %v", err)
+		}
+		if initiated.IsFinalized() || initiated.EventType != EventTypeBridgeTransferInitiated || initiated.MessageHash != "0xabc" {
+			t.Errorf("Expected an initiated transfer of 0xabc, got %+v", initiated)
+		}
+		if len(c.Pending()) != 1 {
+			t.Errorf("Expected the deposit to be pending, got %d", len(c.Pending()))
+		}
+
+		evt, err = c.Add(finalization)
+		if err != nil || evt == nil {
+			t.Fatalf("Expected a finalized event, got %v", err)
+		}
+		finalized, err := ParseBridgeTransferEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse bridge transfer: %v", err)
+		}
+		if !finalized.IsFinalized() || finalized.Finalization.Hash != "0x02" || finalized.Deposit.Hash != "0x01" {
+			t.Errorf("Expected the finalized transfer, got %+v", finalized)
+		}
+		if evt.Tags.GetD() != "0xabc" || evt.CreatedAt.Time().Unix() != at.Add(time.Hour).Unix() {
+			t.Errorf("Expected the finalized event of 0xabc at the finalization, got %s at %d", evt.Tags.GetD(), evt.CreatedAt)
+		}
+		if tag := evt.Tags.GetFirst([]string{"layer", "100", "destination"}); tag == nil {
+			t.Error("Expected the destination chain to be tagged")
+		}
+		if tag := evt.Tags.GetFirst([]string{"amount", "1000"}); tag == nil {
+			t.Error("Expected the amount of the deposit to be tagged")
+		}
+		if len(c.Pending()) != 0 {
+			t.Errorf("Expected no pending deposits, got %d", len(c.Pending()))
+		}
+	})
+
+	t.Run("finalization before deposit", func(t *testing.T) {
+		c := NewBridgeCorrelator([]string{bridgeDepositTopic}, []string{bridgeFinalizationTopic}, nil)
+
+		evt, err := c.Add(finalization)
+		if err != nil || evt != nil {
+			t.Fatalf("Expected the finalization to wait for its deposit, got %v and %v", evt, err)
+		}
+
+		evt, err = c.Add(deposit)
+		if err != nil || evt == nil {
+			t.Fatalf("Expected a finalized event, got %v", err)
+		}
+		finalized, err := ParseBridgeTransferEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse bridge transfer: %v", err)
+		}
+		if !finalized.IsFinalized() || finalized.EventType != EventTypeBridgeTransferFinalized {
+			t.Errorf("Expected the finalized transfer, got %+v", finalized)
+		}
+		if len(c.Pending()) != 0 {
+			t.Errorf("Expected no pending deposits, got %d", len(c.Pending()))
+		}
+	})
+
+	t.Run("missing message hash", func(t *testing.T) {
+		c := NewBridgeCorrelator([]string{bridgeDepositTopic}, []string{bridgeFinalizationTopic}, nil)
+
+		if _, err := c.Add(bridgeLog("0x03", "1", bridgeDepositTopic, `{"value":"1"}`, at)); err == nil {
+			t.Error("Expected a deposit without message hash to fail")
+		}
+		if len(c.Pending()) != 0 {
+			t.Errorf("Expected no pending deposits, got %d", len(c.Pending()))
+		}
+
+		evt, err := c.Add(bridgeLog("0x04", "1", neth.TopicERC20Transfer, `{"value":"1"}`, at))
+		if err != nil || evt != nil {
+			t.Errorf("Expected other logs to be ignored, got %v and %v", evt, err)
+		}
+	})
+}
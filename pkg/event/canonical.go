@@ -0,0 +1,97 @@
+package event
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// nonSemanticTags are tags that only carry human-readable or display information
+var nonSemanticTags = map[string]bool{
+	"alt": true,
+}
+
+// canonicalContent normalizes JSON content by removing whitespace and sorting object keys,
+// content that is not JSON is returned as is
+func canonicalContent(content string) []byte {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return []byte(content)
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		// Plain text that happens to be a valid JSON scalar is kept verbatim
+		return []byte(content)
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return []byte(content)
+	}
+
+	return b
+}
+
+// canonicalTags returns the semantic tags of an event in a deterministic order
+func canonicalTags(tags nostr.Tags) []nostr.Tag {
+	sorted := make([]nostr.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if len(tag) == 0 || nonSemanticTags[tag[0]] {
+			continue
+		}
+		sorted = append(sorted, tag)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	return sorted
+}
+
+// canonicalForm serializes the semantic parts of an event: kind, content and tags
+func canonicalForm(evt *nostr.Event) []byte {
+	b, _ := json.Marshal([]interface{}{
+		evt.Kind,
+		string(canonicalContent(evt.Content)),
+		canonicalTags(evt.Tags),
+	})
+	return b
+}
+
+// CanonicalContentHash returns a hex encoded sha256 hash of the semantic parts of an event.
+// The hash ignores the id, signature, author and creation time, JSON whitespace and key
+// order in the content, the order of tags and purely descriptive tags such as alt.
+func CanonicalContentHash(evt *nostr.Event) string {
+	hash := sha256.Sum256(canonicalForm(evt))
+	return hex.EncodeToString(hash[:])
+}
+
+// EqualSemantics checks if two events from the same author carry the same information,
+// ignoring the differences CanonicalContentHash ignores
+func EqualSemantics(a, b *nostr.Event) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.PubKey != b.PubKey {
+		return false
+	}
+
+	return bytes.Equal(canonicalForm(a), canonicalForm(b))
+}
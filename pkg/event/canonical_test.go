@@ -0,0 +1,48 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestEqualSemantics(t *testing.T) {
+	a := &nostr.Event{
+		ID:        "a",
+		PubKey:    "pubkey",
+		CreatedAt: 100,
+		Kind:      KindTxLog,
+		Tags:      nostr.Tags{{"d", "0x1"}, {"t", "tx_log"}, {"alt", "first"}},
+		Content:   `{"log_data":{"hash":"0x1","value":1},"event_type":"tx_log_created"}`,
+	}
+	b := &nostr.Event{
+		ID:        "b",
+		PubKey:    "pubkey",
+		CreatedAt: 200,
+		Kind:      KindTxLog,
+		Tags:      nostr.Tags{{"t", "tx_log"}, {"d", "0x1"}, {"alt", "second"}},
+		Content:   "{\n  \"event_type\": \"tx_log_created\",\n  \"log_data\": {\"value\": 1, \"hash\": \"0x1\"}\n}",
+	}
+
+	if !EqualSemantics(a, b) {
+		t.Error("Expected events to be semantically equal")
+	}
+	if CanonicalContentHash(a) != CanonicalContentHash(b) {
+		t.Error("Expected canonical hashes to match")
+	}
+
+	c := *b
+	c.Tags = nostr.Tags{{"t", "tx_log"}, {"d", "0x2"}}
+	if EqualSemantics(a, &c) {
+		t.Error("Expected events with different d tags to differ")
+	}
+
+	d := *b
+	d.PubKey = "other"
+	if EqualSemantics(a, &d) {
+		t.Error("Expected events from different authors to differ")
+	}
+	if CanonicalContentHash(a) != CanonicalContentHash(&d) {
+		t.Error("Expected canonical hash to ignore the author")
+	}
+}
@@ -0,0 +1,140 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for chain checkpoints
+const (
+	KindChainCheckpoint = 31102 // Addressable, one event per chain and finality
+
+	EventTypeCheckpointObserved EventTypeCheckpoint = "checkpoint_observed"
+)
+
+type EventTypeCheckpoint string
+
+// CheckpointEvent represents a Nostr event announcing a safe or finalized block of a chain
+type CheckpointEvent struct {
+	Checkpoint neth.Checkpoint     `json:"checkpoint"`
+	EventType  EventTypeCheckpoint `json:"event_type"`
+	Tags       []string            `json:"tags,omitempty"`
+}
+
+// CreateCheckpointEvent creates a new Nostr event announcing a checkpoint, later checkpoints of
+// the same chain and finality replace earlier ones
+func CreateCheckpointEvent(checkpoint neth.Checkpoint) (*nostr.Event, error) {
+	if checkpoint.Finality != neth.FinalitySafe && checkpoint.Finality != neth.FinalityFinalized {
+		return nil, fmt.Errorf("invalid finality: %s", checkpoint.Finality)
+	}
+
+	// Create the event data
+	eventData := CheckpointEvent{
+		Checkpoint: checkpoint,
+		EventType:  EventTypeCheckpointObserved,
+		Tags:       []string{"checkpoint", "evm", checkpoint.ChainID, string(checkpoint.Finality)},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(checkpoint.ObservedAt.Unix()),
+		Kind:      MappedKind(KindChainCheckpoint), // Custom kind for chain checkpoints
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Addressable identifier, one per chain and finality
+	evt.Tags = append(evt.Tags, []string{"d", fmt.Sprintf("%s:%s", checkpoint.ChainID, checkpoint.Finality)})
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("checkpoint"))                // Type
+	evt.Tags = append(evt.Tags, typeTag(string(checkpoint.Finality))) // Finality
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})           // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", checkpoint.ChainID}) // Chain ID
+
+	// Block tags
+	evt.Tags = append(evt.Tags, []string{"block", strconv.FormatUint(checkpoint.BlockNumber, 10)})
+	evt.Tags = append(evt.Tags, []string{"block_hash", checkpoint.BlockHash})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a %s checkpoint at block %d on chain %s", checkpoint.Finality, checkpoint.BlockNumber, checkpoint.ChainID)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseCheckpointEvent parses a Nostr event back into a CheckpointEvent
+func ParseCheckpointEvent(evt *nostr.Event) (*CheckpointEvent, error) {
+	var checkpointEvent CheckpointEvent
+	err := unmarshalContent(evt, &checkpointEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpointEvent, nil
+}
+
+// checkpointStatus maps the finality of a checkpoint to the tx log status it grants
+var checkpointStatus = map[neth.Finality]TxLogStatus{
+	neth.FinalitySafe:      TxLogStatusSafe,
+	neth.FinalityFinalized: TxLogStatusFinalized,
+}
+
+// UpgradeTxLogStatus creates an update event raising the status of a tx log event to the
+// finality of a checkpoint covering its block. Nil is returned when the checkpoint does not
+// cover the log or the log is already at least as final.
+func UpgradeTxLogStatus(evt *nostr.Event, checkpoint *nostr.Event) (*nostr.Event, error) {
+	checkpointEvent, err := ParseCheckpointEvent(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	status, ok := checkpointStatus[checkpointEvent.Checkpoint.Finality]
+	if !ok {
+		return nil, fmt.Errorf("invalid finality: %s", checkpointEvent.Checkpoint.Finality)
+	}
+
+	txLogEvent, err := ParseTxLogEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	if txLogEvent.BlockNumber == 0 || !checkpointEvent.Checkpoint.Covers(txLogEvent.LogData.ChainID, txLogEvent.BlockNumber) {
+		return nil, nil
+	}
+
+	if !txLogEvent.Status.Before(status) {
+		return nil, nil
+	}
+
+	return UpdateTxLogStatus(evt, status)
+}
+
+// UpgradeTxLogStatuses upgrades the status of every tx log event covered by a checkpoint and
+// returns the update events
+func UpgradeTxLogStatuses(events []*nostr.Event, checkpoint *nostr.Event) ([]*nostr.Event, error) {
+	var updates []*nostr.Event
+	for _, evt := range events {
+		update, err := UpgradeTxLogStatus(evt, checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if update != nil {
+			updates = append(updates, update)
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,85 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestUpgradeTxLogStatuses(t *testing.T) {
+	newLog := func(hash string, blockNumber uint64) *nostr.Event {
+		evt, err := CreateTxLogEvent(neth.Log{
+			Hash:      hash,
+			TxHash:    "0xabc",
+			ChainID:   "100",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: time.Unix(1700000000, 0),
+			Value:     big.NewInt(1),
+		}, WithBlockNumber(blockNumber))
+		if err != nil {
+			t.Fatalf("Failed to create tx log event: %v", err)
+		}
+		evt.ID = evt.GetID()
+		return evt
+	}
+
+	events := []*nostr.Event{newLog("0x1", 90), newLog("0x2", 100), newLog("0x3", 110)}
+
+	if tag := events[0].Tags.Find("status"); tag == nil || tag[1] != string(TxLogStatusIncluded) {
+		t.Fatalf("Expected status tag %s, got %v", TxLogStatusIncluded, tag)
+	}
+
+	safe, err := CreateCheckpointEvent(neth.Checkpoint{ChainID: "100", BlockNumber: 105, BlockHash: "0xbeef", Finality: neth.FinalitySafe, ObservedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint event: %v", err)
+	}
+
+	updates, err := UpgradeTxLogStatuses(events, safe)
+	if err != nil {
+		t.Fatalf("Failed to upgrade statuses: %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %d", len(updates))
+	}
+
+	update, err := ParseTxLogEvent(updates[0])
+	if err != nil {
+		t.Fatalf("Failed to parse update: %v", err)
+	}
+	if update.Status != TxLogStatusSafe || update.EventType != EventTypeTxLogUpdated {
+		t.Errorf("Expected safe update, got %s %s", update.Status, update.EventType)
+	}
+	if tag := updates[0].Tags.Find("e"); tag == nil || tag[1] != events[0].ID {
+		t.Errorf("Expected update to reference %s, got %v", events[0].ID, tag)
+	}
+	if tag := updates[0].Tags.GetD(); tag != "0x1" {
+		t.Errorf("Expected update to keep d tag 0x1, got %s", tag)
+	}
+
+	// A safe log is not downgraded by a later safe checkpoint, but is upgraded when finalized
+	updates[0].ID = updates[0].GetID()
+	if again, err := UpgradeTxLogStatus(updates[0], safe); err != nil || again != nil {
+		t.Errorf("Expected no update for an already safe log, got %v, %v", again, err)
+	}
+
+	finalized, err := CreateCheckpointEvent(neth.Checkpoint{ChainID: "100", BlockNumber: 95, BlockHash: "0xcafe", Finality: neth.FinalityFinalized, ObservedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint event: %v", err)
+	}
+
+	final, err := UpgradeTxLogStatus(updates[0], finalized)
+	if err != nil || final == nil {
+		t.Fatalf("Expected finalized update, got %v, %v", final, err)
+	}
+	if tags := final.Tags.GetAll([]string{"status"}); len(tags) != 1 || tags[0][1] != string(TxLogStatusFinalized) {
+		t.Errorf("Expected a single finalized status tag, got %v", tags)
+	}
+
+	otherChain, _ := CreateCheckpointEvent(neth.Checkpoint{ChainID: "1", BlockNumber: 1000, Finality: neth.FinalityFinalized, ObservedAt: time.Now()})
+	if update, err := UpgradeTxLogStatus(events[0], otherChain); err != nil || update != nil {
+		t.Errorf("Expected no update from another chain, got %v, %v", update, err)
+	}
+}
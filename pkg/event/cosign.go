@@ -0,0 +1,378 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for user op co-signing
+const (
+	KindUserOpSignatureRequest = 111007
+	KindUserOpPartialSignature = 111008
+
+	EventTypeUserOpSignatureRequested EventTypeUserOpCoSign = "user_op_signature_requested"
+	EventTypeUserOpPartiallySigned    EventTypeUserOpCoSign = "user_op_partially_signed"
+)
+
+type EventTypeUserOpCoSign string
+
+// UserOpSignatureRequestEvent represents a Nostr event asking co-signers to sign a user operation
+type UserOpSignatureRequestEvent struct {
+	UserOpData neth.UserOp           `json:"user_op_data"`
+	UserOpHash string                `json:"user_op_hash"`
+	ChainID    string                `json:"chain_id"`
+	EntryPoint *common.Address       `json:"entry_point,omitempty"`
+	Signers    []common.Address      `json:"signers"`
+	Threshold  int                   `json:"threshold"`
+	EventType  EventTypeUserOpCoSign `json:"event_type"`
+	Tags       []string              `json:"tags,omitempty"`
+}
+
+// UserOpPartialSignatureEvent represents a Nostr event carrying the signature of one co-signer
+type UserOpPartialSignatureEvent struct {
+	UserOpHash string                `json:"user_op_hash"`
+	Signer     common.Address        `json:"signer"`
+	Signature  hexutil.Bytes         `json:"signature"`
+	EventType  EventTypeUserOpCoSign `json:"event_type"`
+	Tags       []string              `json:"tags,omitempty"`
+}
+
+// userOpRequestHash returns the ERC-4337 hash the co-signers of a user operation sign, which
+// requires the entry point and all numeric fields of the user operation
+func userOpRequestHash(chainID *big.Int, entryPoint *common.Address, userOp neth.UserOp) (string, error) {
+	if entryPoint == nil {
+		return "", fmt.Errorf("entry point is required")
+	}
+	if chainID == nil || chainID.Sign() < 0 || chainID.BitLen() > 256 {
+		return "", fmt.Errorf("invalid chain id")
+	}
+
+	fields := []*big.Int{
+		userOp.Nonce,
+		userOp.CallGasLimit,
+		userOp.VerificationGasLimit,
+		userOp.PreVerificationGas,
+		userOp.MaxFeePerGas,
+		userOp.MaxPriorityFeePerGas,
+	}
+	for _, field := range fields {
+		if field == nil || field.Sign() < 0 || field.BitLen() > 256 {
+			return "", fmt.Errorf("user operation is missing numeric fields")
+		}
+	}
+
+	return userOp.GetUserOpHash(*entryPoint, chainID).Hex(), nil
+}
+
+// CreateUserOpSignatureRequestEvent creates a new Nostr event broadcasting a user operation that
+// needs threshold signatures out of the given signers. The co-signers sign the ERC-4337 hash of
+// the user operation for the entry point, which is required.
+func CreateUserOpSignatureRequestEvent(chainID *big.Int, entryPoint *common.Address, userOp neth.UserOp, signers []common.Address, threshold int) (*nostr.Event, error) {
+	if threshold <= 0 || threshold > len(signers) {
+		return nil, fmt.Errorf("invalid threshold %d for %d signers", threshold, len(signers))
+	}
+
+	userOpHash, err := userOpRequestHash(chainID, entryPoint, userOp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the event data
+	eventData := UserOpSignatureRequestEvent{
+		UserOpData: userOp,
+		UserOpHash: userOpHash,
+		ChainID:    chainID.String(),
+		EntryPoint: entryPoint,
+		Signers:    signers,
+		Threshold:  threshold,
+		EventType:  EventTypeUserOpSignatureRequested,
+		Tags:       []string{"user_op", "signature_request", "evm", chainID.String(), "account_abstraction"},
+	}
+
+	// Marshal the event data using the custom marshaling of the user op
+	content, err := json.Marshal(&eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindUserOpSignatureRequest), // Custom kind for signature requests
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", userOpHash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("user_op"))                                 // Type
+	evt.Tags = append(evt.Tags, typeTag("signature_request"))                       // Category
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})                         // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction"))                     // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(EventTypeUserOpSignatureRequested))) // Event type
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", chainID.String()}) // Chain ID
+
+	// Entry point tag
+	evt.Tags = append(evt.Tags, []string{"entry_point", entryPoint.Hex()})
+
+	// Account and co-signer address tags
+	evt.Tags = append(evt.Tags, []string{"P", userOp.Sender.String()}) // Smart account
+	for _, signer := range signers {
+		evt.Tags = append(evt.Tags, []string{"p", signer.Hex()}) // Co-signer
+	}
+
+	// Threshold tag
+	evt.Tags = append(evt.Tags, []string{"threshold", fmt.Sprintf("%d", threshold)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a request for %d of %d signatures on a user operation on chain %s", threshold, len(signers), chainID.String())
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseUserOpSignatureRequestEvent parses a Nostr event back into a UserOpSignatureRequestEvent
+func ParseUserOpSignatureRequestEvent(evt *nostr.Event) (*UserOpSignatureRequestEvent, error) {
+	var requestEvent UserOpSignatureRequestEvent
+	err := unmarshalContent(evt, &requestEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &requestEvent, nil
+}
+
+// verifyUserOpRequestHash checks that the hash of a signature request is the ERC-4337 hash of
+// its user operation, so that nobody signs or collects signatures for another hash
+func verifyUserOpRequestHash(requestEvent *UserOpSignatureRequestEvent) error {
+	chainID, ok := new(big.Int).SetString(requestEvent.ChainID, 10)
+	if !ok {
+		return fmt.Errorf("invalid chain id %q", requestEvent.ChainID)
+	}
+
+	userOpHash, err := userOpRequestHash(chainID, requestEvent.EntryPoint, requestEvent.UserOpData)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(userOpHash, requestEvent.UserOpHash) {
+		return fmt.Errorf("user operation hash %s does not match the user operation", requestEvent.UserOpHash)
+	}
+	return nil
+}
+
+// CreateUserOpPartialSignatureEvent creates a new Nostr event with the signature of a co-signer
+// in response to a signature request event
+func CreateUserOpPartialSignatureEvent(request *nostr.Event, signer common.Address, signature []byte, opts ...ReferenceOption) (*nostr.Event, error) {
+	requestEvent, err := ParseUserOpSignatureRequestEvent(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyUserOpRequestHash(requestEvent); err != nil {
+		return nil, err
+	}
+
+	if !containsAddress(requestEvent.Signers, signer) {
+		return nil, fmt.Errorf("%s is not a signer of user operation %s", signer.Hex(), requestEvent.UserOpHash)
+	}
+
+	// Create the event data
+	eventData := UserOpPartialSignatureEvent{
+		UserOpHash: requestEvent.UserOpHash,
+		Signer:     signer,
+		Signature:  signature,
+		EventType:  EventTypeUserOpPartiallySigned,
+		Tags:       []string{"user_op", "partial_signature", "evm", requestEvent.ChainID, "account_abstraction"},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindUserOpPartialSignature), // Custom kind for partial signatures
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", fmt.Sprintf("%s:%s", requestEvent.UserOpHash, strings.ToLower(signer.Hex()))}) // Identifier, one per signer
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("user_op"))                              // Type
+	evt.Tags = append(evt.Tags, typeTag("partial_signature"))                    // Category
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})                      // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction"))                  // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(EventTypeUserOpPartiallySigned))) // Event type
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", requestEvent.ChainID}) // Chain ID
+
+	// Reference to the signature request
+	evt.Tags = append(evt.Tags, hintedTag("e", request.ID, newReferenceOptions(opts).eventRelay(request)))
+	evt.Tags = append(evt.Tags, []string{"user_op_hash", requestEvent.UserOpHash})
+
+	// Address tags
+	evt.Tags = append(evt.Tags, []string{"P", signer.Hex()})                            // Co-signer
+	evt.Tags = append(evt.Tags, []string{"p", requestEvent.UserOpData.Sender.String()}) // Smart account
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a signature from %s for user operation %s", signer.Hex(), requestEvent.UserOpHash)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseUserOpPartialSignatureEvent parses a Nostr event back into a UserOpPartialSignatureEvent
+func ParseUserOpPartialSignatureEvent(evt *nostr.Event) (*UserOpPartialSignatureEvent, error) {
+	var partialSignatureEvent UserOpPartialSignatureEvent
+	err := unmarshalContent(evt, &partialSignatureEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &partialSignatureEvent, nil
+}
+
+// SignatureAggregator collects the partial signatures for a signature request
+type SignatureAggregator struct {
+	mu sync.Mutex
+
+	request    *UserOpSignatureRequestEvent
+	signatures map[common.Address][]byte
+}
+
+// NewSignatureAggregator creates a new aggregator for a signature request event. Requests whose
+// hash is not the ERC-4337 hash of their user operation are rejected.
+func NewSignatureAggregator(request *nostr.Event) (*SignatureAggregator, error) {
+	requestEvent, err := ParseUserOpSignatureRequestEvent(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyUserOpRequestHash(requestEvent); err != nil {
+		return nil, err
+	}
+
+	return &SignatureAggregator{
+		request:    requestEvent,
+		signatures: make(map[common.Address][]byte),
+	}, nil
+}
+
+// Add adds a partial signature event. Signatures for other user operations, from addresses that
+// are not signers of the request or that do not recover to their signer from the user operation
+// hash are rejected. The first valid signature of a signer is kept.
+func (a *SignatureAggregator) Add(evt *nostr.Event) error {
+	if DefaultKind(evt.Kind) != KindUserOpPartialSignature {
+		return fmt.Errorf("event kind %d is not a partial signature", evt.Kind)
+	}
+
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return fmt.Errorf("invalid partial signature event from %s", evt.PubKey)
+	}
+
+	partial, err := ParseUserOpPartialSignatureEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	if partial.UserOpHash != a.request.UserOpHash {
+		return fmt.Errorf("signature is for user operation %s, not %s", partial.UserOpHash, a.request.UserOpHash)
+	}
+
+	if !containsAddress(a.request.Signers, partial.Signer) {
+		return fmt.Errorf("%s is not a signer of user operation %s", partial.Signer.Hex(), a.request.UserOpHash)
+	}
+
+	if len(partial.Signature) == 0 {
+		return fmt.Errorf("empty signature from %s", partial.Signer.Hex())
+	}
+
+	if !neth.VerifyHashSignature(common.HexToHash(a.request.UserOpHash), partial.Signature, partial.Signer) {
+		return fmt.Errorf("signature of user operation %s is not from %s", a.request.UserOpHash, partial.Signer.Hex())
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.signatures[partial.Signer]; ok {
+		return nil
+	}
+	a.signatures[partial.Signer] = partial.Signature
+
+	return nil
+}
+
+// Count returns the number of distinct signers that have signed
+func (a *SignatureAggregator) Count() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.signatures)
+}
+
+// Ready checks if the threshold of the request has been reached
+func (a *SignatureAggregator) Ready() bool {
+	return a.Count() >= a.request.Threshold
+}
+
+// Assemble returns a copy of the user operation with the final signature, the partial
+// signatures are concatenated in ascending order of signer address
+func (a *SignatureAggregator) Assemble() (neth.UserOp, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.signatures) < a.request.Threshold {
+		return neth.UserOp{}, fmt.Errorf("%d of %d signatures collected", len(a.signatures), a.request.Threshold)
+	}
+
+	signers := make([]common.Address, 0, len(a.signatures))
+	for signer := range a.signatures {
+		signers = append(signers, signer)
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i].Bytes(), signers[j].Bytes()) < 0
+	})
+
+	var signature []byte
+	for _, signer := range signers[:a.request.Threshold] {
+		signature = append(signature, a.signatures[signer]...)
+	}
+
+	userOp := a.request.UserOpData.Copy()
+	userOp.Signature = signature
+
+	return userOp, nil
+}
+
+// containsAddress checks if an address is part of a list
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
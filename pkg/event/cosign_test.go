@@ -0,0 +1,142 @@
+package event
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSignatureAggregator(t *testing.T) {
+	chainID := big.NewInt(1)
+	userOp := neth.UserOp{
+		Sender:               common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(0),
+		VerificationGasLimit: big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+	}
+
+	keyA, _ := crypto.GenerateKey()
+	keyB, _ := crypto.GenerateKey()
+	outsiderKey, _ := crypto.GenerateKey()
+	signerA := crypto.PubkeyToAddress(keyA.PublicKey)
+	signerB := crypto.PubkeyToAddress(keyB.PublicKey)
+	outsider := crypto.PubkeyToAddress(outsiderKey.PublicKey)
+
+	entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+	if _, err := CreateUserOpSignatureRequestEvent(chainID, nil, userOp, []common.Address{signerB, signerA}, 2); err == nil {
+		t.Error("Expected an error for a request without entry point")
+	}
+
+	request, err := CreateUserOpSignatureRequestEvent(chainID, &entryPoint, userOp, []common.Address{signerB, signerA}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create signature request: %v", err)
+	}
+	requestEvent, _ := ParseUserOpSignatureRequestEvent(request)
+	userOpHash := common.HexToHash(requestEvent.UserOpHash)
+	if expected := userOp.GetUserOpHash(entryPoint, chainID); userOpHash != expected {
+		t.Fatalf("Expected user op hash %s, got %s", expected.Hex(), userOpHash.Hex())
+	}
+
+	sign := func(key *ecdsa.PrivateKey) []byte {
+		signature, err := crypto.Sign(neth.MessageHash(userOpHash.Bytes()), key)
+		if err != nil {
+			t.Fatalf("Failed to sign user op: %v", err)
+		}
+		return signature
+	}
+	partial := func(signer common.Address, signature []byte) *nostr.Event {
+		t.Helper()
+		evt, err := CreateUserOpPartialSignatureEvent(request, signer, signature)
+		if err != nil {
+			t.Fatalf("Failed to create partial signature: %v", err)
+		}
+		evt.Sign(nostr.GeneratePrivateKey())
+		return evt
+	}
+
+	if _, err := CreateUserOpPartialSignatureEvent(request, outsider, sign(outsiderKey)); err == nil {
+		t.Errorf("Expected an error for a signature from a non-signer")
+	}
+
+	// A request whose hash is not the hash of its user operation is rejected
+	tamperedData := *requestEvent
+	tamperedData.UserOpHash = crypto.Keccak256Hash([]byte("other")).Hex()
+	content, err := json.Marshal(&tamperedData)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	tampered := *request
+	tampered.Content = string(content)
+	if _, err := NewSignatureAggregator(&tampered); err == nil {
+		t.Error("Expected a request with another hash to be rejected")
+	}
+	if _, err := CreateUserOpPartialSignatureEvent(&tampered, signerA, sign(keyA)); err == nil {
+		t.Error("Expected no partial signature for a request with another hash")
+	}
+
+	aggregator, err := NewSignatureAggregator(request)
+	if err != nil {
+		t.Fatalf("Failed to create aggregator: %v", err)
+	}
+
+	// Signatures that do not recover to their signer are rejected
+	if err := aggregator.Add(partial(signerA, sign(outsiderKey))); err == nil {
+		t.Error("Expected a signature of another key to be rejected")
+	}
+	if err := aggregator.Add(partial(signerA, []byte{0xaa})); err == nil {
+		t.Error("Expected an invalid signature to be rejected")
+	}
+	unsigned := partial(signerA, sign(keyA))
+	unsigned.Content = unsigned.Content + " "
+	if err := aggregator.Add(unsigned); err == nil {
+		t.Error("Expected an event with an invalid signature to be rejected")
+	}
+
+	signatureA, signatureB := sign(keyA), sign(keyB)
+	for _, evt := range []*nostr.Event{partial(signerB, signatureB), partial(signerA, signatureA)} {
+		if aggregator.Ready() {
+			t.Fatalf("Expected aggregator not to be ready before all signatures")
+		}
+		if err := aggregator.Add(evt); err != nil {
+			t.Fatalf("Failed to add partial signature: %v", err)
+		}
+	}
+
+	// A verified signature is not replaced
+	other := sign(keyA)
+	other[crypto.RecoveryIDOffset] += 27
+	if err := aggregator.Add(partial(signerA, other)); err != nil {
+		t.Fatalf("Failed to add partial signature: %v", err)
+	}
+
+	if !aggregator.Ready() || aggregator.Count() != 2 {
+		t.Fatalf("Expected aggregator to be ready with 2 signatures, got %d", aggregator.Count())
+	}
+
+	assembled, err := aggregator.Assemble()
+	if err != nil {
+		t.Fatalf("Failed to assemble user op: %v", err)
+	}
+
+	expected := append(append([]byte{}, signatureB...), signatureA...)
+	if bytes.Compare(signerA.Bytes(), signerB.Bytes()) < 0 {
+		expected = append(append([]byte{}, signatureA...), signatureB...)
+	}
+	if !bytes.Equal(assembled.Signature, expected) {
+		t.Errorf("Expected signature %x, got %x", expected, assembled.Signature)
+	}
+	if assembled.Sender != userOp.Sender {
+		t.Errorf("Expected sender %s, got %s", userOp.Sender.Hex(), assembled.Sender.Hex())
+	}
+}
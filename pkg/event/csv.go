@@ -0,0 +1,172 @@
+package event
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// transferCSVHeader is the header of the transfer CSV exports
+var transferCSVHeader = []string{
+	"timestamp", "chain_id", "tx_hash", "log_hash", "from", "to", "direction",
+	"token", "symbol", "amount", "fiat_currency", "fiat_value",
+}
+
+// TransferCSVOptions selects the transfers of a CSV export, zero values select everything
+type TransferCSVOptions struct {
+	Address string    // Only the transfers from or to this address, with their direction
+	Since   time.Time // Only the transfers at or after this time
+	Until   time.Time // Only the transfers before this time
+
+	// Tokens gives the symbol and decimals of the tokens, DefaultTokenRegistry when nil. Amounts
+	// of unknown tokens are written in base units.
+	Tokens *TokenRegistry
+}
+
+// transferRow is a transfer of a CSV export
+type transferRow struct {
+	log  neth.Log
+	data *neth.LogTransferData
+	fiat *FiatValue
+}
+
+// ExportTransfersCSV writes the ERC20 transfers of transfer and tx log events as CSV for
+// accounting, one row per transfer from the oldest. Transfers seen in several events are written
+// once, with the fiat value of the transfer event, and orphaned logs are left out. Other events
+// are skipped. It returns the number of written transfers.
+func ExportTransfersCSV(w io.Writer, events []*nostr.Event, opts TransferCSVOptions) (int, error) {
+	tokens := opts.Tokens
+	if tokens == nil {
+		tokens = DefaultTokenRegistry
+	}
+
+	// Oldest first, so that the latest status of a log wins
+	sorted := append([]*nostr.Event(nil), events...)
+	SortEventsByCreatedAt(sorted, false)
+
+	rows := make(map[string]*transferRow)
+	for _, evt := range sorted {
+		log, err := logFromEvent(evt)
+		if err != nil || !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+			continue
+		}
+
+		key := log.ChainID + ":" + strings.ToLower(log.Hash)
+
+		if DefaultKind(evt.Kind) == KindTxLog {
+			if txLog, err := ParseTxLogEvent(evt); err == nil && txLog.Status == TxLogStatusOrphaned {
+				delete(rows, key)
+				continue
+			}
+		}
+
+		data, err := log.GetTransferData()
+		if err != nil || data == nil {
+			continue
+		}
+		if !matchesTransferCSV(*log, data, opts) {
+			continue
+		}
+
+		row, ok := rows[key]
+		if !ok {
+			row = &transferRow{log: *log, data: data}
+			rows[key] = row
+		}
+		if fiat, _ := GetFiatValueFromEvent(evt); fiat != nil {
+			row.fiat = fiat
+		}
+	}
+
+	ordered := make([]*transferRow, 0, len(rows))
+	for _, row := range rows {
+		ordered = append(ordered, row)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if !ordered[i].log.CreatedAt.Equal(ordered[j].log.CreatedAt) {
+			return ordered[i].log.CreatedAt.Before(ordered[j].log.CreatedAt)
+		}
+		return ordered[i].log.Hash < ordered[j].log.Hash
+	})
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(transferCSVHeader); err != nil {
+		return 0, err
+	}
+
+	for _, row := range ordered {
+		if err := writer.Write(transferCSVRecord(row, opts.Address, tokens)); err != nil {
+			return 0, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return 0, err
+	}
+	return len(ordered), nil
+}
+
+// matchesTransferCSV checks if a transfer is selected by the options
+func matchesTransferCSV(log neth.Log, data *neth.LogTransferData, opts TransferCSVOptions) bool {
+	if opts.Address != "" && !strings.EqualFold(data.From, opts.Address) && !strings.EqualFold(data.To, opts.Address) {
+		return false
+	}
+	if !opts.Since.IsZero() && log.CreatedAt.Before(opts.Since) {
+		return false
+	}
+	if !opts.Until.IsZero() && !log.CreatedAt.Before(opts.Until) {
+		return false
+	}
+	return true
+}
+
+// transferCSVRecord returns the CSV record of a transfer
+func transferCSVRecord(row *transferRow, address string, tokens *TokenRegistry) []string {
+	direction := ""
+	if address != "" {
+		switch {
+		case strings.EqualFold(row.data.From, address) && strings.EqualFold(row.data.To, address):
+			direction = "self"
+		case strings.EqualFold(row.data.To, address):
+			direction = "in"
+		default:
+			direction = "out"
+		}
+	}
+
+	amount := row.data.Value
+	symbol := ""
+	if token, ok := tokens.Token(row.log.ChainID, row.log.To); ok {
+		symbol = token.Symbol
+		if value, ok := new(big.Int).SetString(row.data.Value, 10); ok {
+			amount = neth.FormatUnits(value, token.Decimals)
+		}
+	}
+
+	fiatCurrency, fiatValue := "", ""
+	if row.fiat != nil {
+		fiatCurrency, fiatValue = row.fiat.Currency, row.fiat.Value
+	}
+
+	return []string{
+		row.log.CreatedAt.UTC().Format(time.RFC3339),
+		row.log.ChainID,
+		row.log.TxHash,
+		row.log.Hash,
+		row.data.From,
+		row.data.To,
+		direction,
+		row.log.To,
+		symbol,
+		amount,
+		fiatCurrency,
+		fiatValue,
+	}
+}
@@ -0,0 +1,81 @@
+package event
+
+import (
+	"encoding/csv"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestExportTransfersCSV(t *testing.T) {
+	provider := neth.NewStaticRateProvider()
+	provider.SetRate("100", goldenLog().To, neth.FiatRate{Currency: "EUR", Rate: big.NewInt(1), Decimals: 0, TokenDecimals: 18})
+
+	tokens := NewTokenRegistry()
+	list, err := neth.ParseTokenList([]byte(`{"name":"Test","tokens":[{"chainId":100,"address":"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d","name":"EURe","symbol":"EURe","decimals":18}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse token list: %v", err)
+	}
+	tokens.AddTokenList(list)
+
+	// The same transfer as a tx log and a transfer event
+	txLog, _ := CreateTxLogEvent(goldenLog())
+	transfer, _ := CreateTxTransferEvent(goldenLog(), WithRateProvider(provider))
+
+	later := goldenLog()
+	later.Hash = "0x02"
+	later.CreatedAt = later.CreatedAt.Add(48 * time.Hour)
+	laterTransfer, _ := CreateTxTransferEvent(later)
+
+	orphan := goldenLog()
+	orphan.Hash = "0x03"
+	orphanLog, _ := CreateTxLogEvent(orphan, WithBlockNumber(10))
+	orphaned, err := UpdateTxLogStatus(orphanLog, TxLogStatusOrphaned)
+	if err != nil {
+		t.Fatalf("Failed to orphan log: %v", err)
+	}
+	orphaned.CreatedAt++
+
+	var b strings.Builder
+	n, err := ExportTransfersCSV(&b, []*nostr.Event{laterTransfer, txLog, transfer, orphanLog, orphaned}, TransferCSVOptions{
+		Address: "0x2222222222222222222222222222222222222222",
+		Tokens:  tokens,
+	})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("Expected 2 transfers, got %d", n)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "timestamp" {
+		t.Fatalf("Expected a header and 2 rows, got %v", records)
+	}
+
+	first := records[1]
+	if first[3] != goldenLog().Hash || first[6] != "in" || first[8] != "EURe" || first[9] != "1" || first[10] != "EUR" || first[11] != "1" {
+		t.Errorf("Expected 1 EURe received worth 1 EUR, got %v", first)
+	}
+	if records[2][3] != "0x02" {
+		t.Errorf("Expected the later transfer last, got %v", records[2])
+	}
+
+	// Date range and address selection
+	b.Reset()
+	n, err = ExportTransfersCSV(&b, []*nostr.Event{laterTransfer, transfer}, TransferCSVOptions{Since: later.CreatedAt})
+	if err != nil || n != 1 {
+		t.Errorf("Expected 1 transfer since the later one, got %d, %v", n, err)
+	}
+	n, _ = ExportTransfersCSV(&b, []*nostr.Event{laterTransfer, transfer}, TransferCSVOptions{Address: "0x3333333333333333333333333333333333333333"})
+	if n != 0 {
+		t.Errorf("Expected no transfer of another address, got %d", n)
+	}
+}
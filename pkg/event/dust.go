@@ -0,0 +1,100 @@
+package event
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+// DustThresholds holds the minimum value of the transfers of each token, transfers strictly below
+// it are dust, e.g. spam airdrops
+type DustThresholds struct {
+	mu       sync.RWMutex
+	minimums map[string]*big.Int
+	fallback *big.Int
+}
+
+// NewDustThresholds creates a new empty set of thresholds, no transfer is dust until one is set
+func NewDustThresholds() *DustThresholds {
+	return &DustThresholds{minimums: make(map[string]*big.Int)}
+}
+
+// Set sets the minimum value of the transfers of a token in base units, nil removes it
+func (d *DustThresholds) Set(chainID, token string, minimum *big.Int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if minimum == nil {
+		delete(d.minimums, tokenKey(chainID, token))
+		return
+	}
+	d.minimums[tokenKey(chainID, token)] = new(big.Int).Set(minimum)
+}
+
+// SetDefault sets the minimum value of the transfers of tokens without their own threshold, nil
+// removes it
+func (d *DustThresholds) SetDefault(minimum *big.Int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if minimum == nil {
+		d.fallback = nil
+		return
+	}
+	d.fallback = new(big.Int).Set(minimum)
+}
+
+// Replace replaces the thresholds with those of another set, at once
+func (d *DustThresholds) Replace(other *DustThresholds) {
+	other.mu.RLock()
+	minimums := make(map[string]*big.Int, len(other.minimums))
+	for key, minimum := range other.minimums {
+		minimums[key] = new(big.Int).Set(minimum)
+	}
+	var fallback *big.Int
+	if other.fallback != nil {
+		fallback = new(big.Int).Set(other.fallback)
+	}
+	other.mu.RUnlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.minimums = minimums
+	d.fallback = fallback
+}
+
+// Threshold returns the minimum value of the transfers of a token, nil when there is none
+func (d *DustThresholds) Threshold(chainID, token string) *big.Int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if minimum, ok := d.minimums[tokenKey(chainID, token)]; ok {
+		return new(big.Int).Set(minimum)
+	}
+	if d.fallback != nil {
+		return new(big.Int).Set(d.fallback)
+	}
+	return nil
+}
+
+// IsDust checks if a log is an ERC20 transfer of less than the threshold of its token
+func (d *DustThresholds) IsDust(log neth.Log) bool {
+	if !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+		return false
+	}
+
+	transfer, err := log.GetTransferData()
+	if err != nil || transfer == nil {
+		return false
+	}
+	value, ok := new(big.Int).SetString(transfer.Value, 10)
+	if !ok {
+		return false
+	}
+
+	minimum := d.Threshold(log.ChainID, log.To)
+	return minimum != nil && value.Cmp(minimum) < 0
+}
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func dustLog(token, value string) neth.Log {
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"` + value + `"}`)
+	return neth.Log{
+		Hash:      "0x1",
+		TxHash:    "0x2",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+func TestDustThresholds(t *testing.T) {
+	eure := "0xcB444e90D8198415266c6a2724b7900fb12FC56E"
+	other := "0x00000000000000000000000000000000000000bb"
+
+	thresholds := NewDustThresholds()
+	if thresholds.IsDust(dustLog(eure, "0")) {
+		t.Error("Expected no dust without thresholds")
+	}
+
+	thresholds.Set("100", eure, big.NewInt(1000))
+	tests := []struct {
+		token    string
+		value    string
+		expected bool
+	}{
+		{eure, "999", true},
+		{eure, "1000", false},
+		{other, "1", false},
+	}
+	for _, tt := range tests {
+		if got := thresholds.IsDust(dustLog(tt.token, tt.value)); got != tt.expected {
+			t.Errorf("Expected dust %v for %s of %s, got %v", tt.expected, tt.value, tt.token, got)
+		}
+	}
+
+	// The default applies to tokens without their own threshold
+	thresholds.SetDefault(big.NewInt(10))
+	if !thresholds.IsDust(dustLog(other, "9")) || thresholds.IsDust(dustLog(eure, "1000")) {
+		t.Error("Expected the default threshold for other tokens only")
+	}
+	thresholds.Set("100", eure, nil)
+	if thresholds.IsDust(dustLog(eure, "999")) {
+		t.Error("Expected the removed threshold to fall back to the default")
+	}
+
+	// Transfer and tx log events are tagged
+	transfer, err := CreateTxTransferEvent(dustLog(other, "1"), WithDustThresholds(thresholds))
+	if err != nil {
+		t.Fatalf("Failed to create transfer event: %v", err)
+	}
+	if !HasTypeTag(transfer, "dust") {
+		t.Error("Expected the transfer to be tagged dust")
+	}
+	txLog, err := CreateTxLogEvent(dustLog(other, "10"), WithDustThresholds(thresholds))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if HasTypeTag(txLog, "dust") {
+		t.Error("Expected the tx log at the threshold not to be dust")
+	}
+}
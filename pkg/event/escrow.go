@@ -0,0 +1,401 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindEscrowProposal = 111018 // Terms of an escrow between a payer and a payee
+	KindEscrowFunding  = 111019 // Links an escrow to the transfer funding it
+	KindEscrowSignal   = 111020 // Release, refund or dispute of an escrow
+)
+
+// EscrowStatus represents the status of an escrow
+type EscrowStatus string
+
+const (
+	EscrowStatusProposed EscrowStatus = "proposed" // The terms are published, the escrow is not funded
+	EscrowStatusFunded   EscrowStatus = "funded"   // The funds are held
+	EscrowStatusDisputed EscrowStatus = "disputed" // A party disputes the escrow, the arbiter decides
+	EscrowStatusReleased EscrowStatus = "released" // The funds go to the payee
+	EscrowStatusRefunded EscrowStatus = "refunded" // The funds go back to the payer
+)
+
+// EscrowAction is what a signal asks for
+type EscrowAction string
+
+const (
+	EscrowActionRelease EscrowAction = "release" // By the payer, or the arbiter
+	EscrowActionRefund  EscrowAction = "refund"  // By the payee, or the arbiter
+	EscrowActionDispute EscrowAction = "dispute" // By the payer or the payee
+)
+
+// EscrowParty is a party of an escrow, identified by its Nostr public key and paying or paid
+// with its address
+type EscrowParty struct {
+	PubKey  string `json:"pubkey"`
+	Address string `json:"address"`
+}
+
+// EscrowProposalEvent represents the terms of an escrow
+type EscrowProposalEvent struct {
+	Payer      EscrowParty `json:"payer"`
+	Payee      EscrowParty `json:"payee"`
+	Arbiter    string      `json:"arbiter,omitempty"` // Public key settling disputes
+	ChainID    string      `json:"chain_id"`
+	Token      string      `json:"token"` // ERC-20 contract address
+	Amount     *big.Int    `json:"amount"`
+	Holder     string      `json:"holder"`               // Address holding the funds, e.g. an escrow contract or a multisig
+	Conditions []string    `json:"conditions,omitempty"` // Release conditions agreed by the parties
+	Deadline   int64       `json:"deadline,omitempty"`   // Unix time after which the payer expects a refund
+}
+
+// EscrowFundingEvent represents the funding of an escrow
+type EscrowFundingEvent struct {
+	EscrowID string   `json:"escrow_id"`
+	LogID    string   `json:"log_id"` // Tx log or transfer event of the funding transfer
+	ChainID  string   `json:"chain_id"`
+	TxHash   string   `json:"tx_hash"`
+	Amount   *big.Int `json:"amount"`
+}
+
+// EscrowSignalEvent represents a release, refund or dispute of an escrow
+type EscrowSignalEvent struct {
+	EscrowID string       `json:"escrow_id"`
+	Action   EscrowAction `json:"action"`
+	Reason   string       `json:"reason,omitempty"`
+	TxHash   string       `json:"tx_hash,omitempty"` // Settlement transaction, once sent
+}
+
+// CreateEscrowProposalEvent creates the terms of an escrow (kind 111018), to be signed by one of
+// the parties
+func CreateEscrowProposalEvent(escrow EscrowProposalEvent, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	for _, party := range []EscrowParty{escrow.Payer, escrow.Payee} {
+		if !nostr.IsValidPublicKey(party.PubKey) {
+			return nil, fmt.Errorf("invalid public key of escrow party: %s", party.PubKey)
+		}
+		if !isEthereumAddress(party.Address) {
+			return nil, fmt.Errorf("invalid address of escrow party: %s", party.Address)
+		}
+	}
+	if escrow.Arbiter != "" && !nostr.IsValidPublicKey(escrow.Arbiter) {
+		return nil, fmt.Errorf("invalid public key of escrow arbiter: %s", escrow.Arbiter)
+	}
+	if escrow.ChainID == "" {
+		return nil, fmt.Errorf("escrow chain ID cannot be empty")
+	}
+	if !isEthereumAddress(escrow.Token) || !isEthereumAddress(escrow.Holder) {
+		return nil, fmt.Errorf("escrow token and holder must be addresses")
+	}
+	if escrow.Amount == nil || escrow.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("escrow amount must be positive")
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(escrow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal escrow: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindEscrowProposal),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Parties
+	evt.Tags = append(evt.Tags, escrowPartyTags(&escrow, references)...)
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("escrow"))          // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tags
+	evt.Tags = append(evt.Tags, []string{"layer", escrow.ChainID})          // Chain ID
+	evt.Tags = append(evt.Tags, []string{"token", escrow.Token})            // Token contract
+	evt.Tags = append(evt.Tags, []string{"amount", escrow.Amount.String()}) // Amount
+	evt.Tags = append(evt.Tags, []string{"status", string(EscrowStatusProposed)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an escrow of %s of token %s on chain %s", escrow.Amount, escrow.Token, escrow.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseEscrowProposalEvent parses the terms of an escrow
+func ParseEscrowProposalEvent(evt *nostr.Event) (*EscrowProposalEvent, error) {
+	if DefaultKind(evt.Kind) != KindEscrowProposal {
+		return nil, fmt.Errorf("event is not an escrow proposal event (kind %d)", evt.Kind)
+	}
+
+	var escrow EscrowProposalEvent
+	if err := unmarshalContent(evt, &escrow); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escrow proposal event: %w", err)
+	}
+
+	return &escrow, nil
+}
+
+// CreateEscrowFundingEvent links an escrow to the tx log or transfer event of the transfer that
+// funded it (kind 111019), the transfer must send at least the amount of the escrow to its holder
+func CreateEscrowFundingEvent(escrow *nostr.Event, txLog *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if escrow == nil || escrow.ID == "" || txLog == nil || txLog.ID == "" {
+		return nil, fmt.Errorf("escrow and funding events must have an ID")
+	}
+
+	escrowData, err := ParseEscrowProposalEvent(escrow)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := logFromEvent(txLog)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := matchEscrowFunding(escrowData, log)
+	if err != nil {
+		return nil, err
+	}
+
+	funding := EscrowFundingEvent{
+		EscrowID: escrow.ID,
+		LogID:    txLog.ID,
+		ChainID:  log.ChainID,
+		TxHash:   log.TxHash,
+		Amount:   amount,
+	}
+
+	content, err := json.Marshal(funding)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindEscrowFunding),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// References to the escrow, the funding log and the parties
+	evt.Tags = append(evt.Tags, []string{"e", escrow.ID, references.eventRelay(escrow), "root"})
+	evt.Tags = append(evt.Tags, []string{"e", txLog.ID, references.eventRelay(txLog), "funding"})
+	evt.Tags = append(evt.Tags, escrowPartyTags(escrowData, references)...)
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("escrow"))          // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tags
+	evt.Tags = append(evt.Tags, []string{"layer", funding.ChainID}) // Chain ID
+	evt.Tags = append(evt.Tags, []string{"r", funding.TxHash})      // Transaction hash
+	evt.Tags = append(evt.Tags, []string{"status", string(EscrowStatusFunded)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This links an escrow to its funding transaction %s on chain %s", funding.TxHash, funding.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseEscrowFundingEvent parses the funding of an escrow
+func ParseEscrowFundingEvent(evt *nostr.Event) (*EscrowFundingEvent, error) {
+	if DefaultKind(evt.Kind) != KindEscrowFunding {
+		return nil, fmt.Errorf("event is not an escrow funding event (kind %d)", evt.Kind)
+	}
+
+	var funding EscrowFundingEvent
+	if err := unmarshalContent(evt, &funding); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escrow funding event: %w", err)
+	}
+
+	return &funding, nil
+}
+
+// CreateEscrowSignalEvent signals the release, refund or dispute of an escrow (kind 111020),
+// txHash references the settlement transaction when it was already sent
+func CreateEscrowSignalEvent(escrow *nostr.Event, action EscrowAction, reason, txHash string, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if escrow == nil || escrow.ID == "" {
+		return nil, fmt.Errorf("escrow must have an ID")
+	}
+
+	escrowData, err := ParseEscrowProposalEvent(escrow)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case EscrowActionRelease, EscrowActionRefund, EscrowActionDispute:
+	default:
+		return nil, fmt.Errorf("unknown escrow action: %s", action)
+	}
+
+	signal := EscrowSignalEvent{
+		EscrowID: escrow.ID,
+		Action:   action,
+		Reason:   reason,
+		TxHash:   txHash,
+	}
+
+	content, err := json.Marshal(signal)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindEscrowSignal),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// References to the escrow and the parties
+	evt.Tags = append(evt.Tags, []string{"e", escrow.ID, references.eventRelay(escrow), "root"})
+	evt.Tags = append(evt.Tags, escrowPartyTags(escrowData, references)...)
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("escrow"))          // Type
+	evt.Tags = append(evt.Tags, typeTag(string(action)))    // Action
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tags
+	evt.Tags = append(evt.Tags, []string{"layer", escrowData.ChainID}) // Chain ID
+	if txHash != "" {
+		evt.Tags = append(evt.Tags, []string{"r", txHash}) // Settlement transaction hash
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a %s of an escrow on chain %s", action, escrowData.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseEscrowSignalEvent parses a release, refund or dispute of an escrow
+func ParseEscrowSignalEvent(evt *nostr.Event) (*EscrowSignalEvent, error) {
+	if DefaultKind(evt.Kind) != KindEscrowSignal {
+		return nil, fmt.Errorf("event is not an escrow signal event (kind %d)", evt.Kind)
+	}
+
+	var signal EscrowSignalEvent
+	if err := unmarshalContent(evt, &signal); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escrow signal event: %w", err)
+	}
+
+	return &signal, nil
+}
+
+// ComputeEscrowStatus folds the funding and signal events of an escrow in chronological order
+// into its status. Signals are only applied when their signer may send them: the payer releases,
+// the payee refunds, both dispute and the arbiter settles funded or disputed escrows. Signals
+// before the funding and events of other escrows are ignored.
+func ComputeEscrowStatus(escrow *nostr.Event, events []*nostr.Event) (EscrowStatus, error) {
+	escrowData, err := ParseEscrowProposalEvent(escrow)
+	if err != nil {
+		return "", err
+	}
+
+	related := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if evt != nil && (DefaultKind(evt.Kind) == KindEscrowFunding || DefaultKind(evt.Kind) == KindEscrowSignal) {
+			related = append(related, evt)
+		}
+	}
+
+	// Apply events oldest first so the result is deterministic
+	SortEventsByCreatedAt(related, false)
+
+	status := EscrowStatusProposed
+	for _, evt := range related {
+		if DefaultKind(evt.Kind) == KindEscrowFunding {
+			funding, err := ParseEscrowFundingEvent(evt)
+			if err != nil || funding.EscrowID != escrow.ID {
+				continue
+			}
+			if status == EscrowStatusProposed {
+				status = EscrowStatusFunded
+			}
+			continue
+		}
+
+		signal, err := ParseEscrowSignalEvent(evt)
+		if err != nil || signal.EscrowID != escrow.ID {
+			continue
+		}
+
+		arbiter := escrowData.Arbiter != "" && evt.PubKey == escrowData.Arbiter
+		settleable := status == EscrowStatusFunded || (arbiter && status == EscrowStatusDisputed)
+
+		switch signal.Action {
+		case EscrowActionRelease:
+			if settleable && (arbiter || evt.PubKey == escrowData.Payer.PubKey) {
+				status = EscrowStatusReleased
+			}
+		case EscrowActionRefund:
+			if settleable && (arbiter || evt.PubKey == escrowData.Payee.PubKey) {
+				status = EscrowStatusRefunded
+			}
+		case EscrowActionDispute:
+			if status == EscrowStatusFunded && (evt.PubKey == escrowData.Payer.PubKey || evt.PubKey == escrowData.Payee.PubKey) {
+				status = EscrowStatusDisputed
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// escrowPartyTags returns the p tags of the parties of an escrow, marked with their role
+func escrowPartyTags(escrow *EscrowProposalEvent, references *referenceOptions) []nostr.Tag {
+	tags := []nostr.Tag{
+		{"p", escrow.Payer.PubKey, references.pubKeyRelay(escrow.Payer.PubKey), "payer"},
+		{"p", escrow.Payee.PubKey, references.pubKeyRelay(escrow.Payee.PubKey), "payee"},
+	}
+	if escrow.Arbiter != "" {
+		tags = append(tags, nostr.Tag{"p", escrow.Arbiter, references.pubKeyRelay(escrow.Arbiter), "arbiter"})
+	}
+	return tags
+}
+
+// matchEscrowFunding checks that a transfer funds an escrow, returning the funded amount
+func matchEscrowFunding(escrow *EscrowProposalEvent, log *neth.Log) (*big.Int, error) {
+	if log.ChainID != escrow.ChainID {
+		return nil, fmt.Errorf("transfer is on chain %s, the escrow on chain %s", log.ChainID, escrow.ChainID)
+	}
+	if !strings.EqualFold(log.To, escrow.Token) {
+		return nil, fmt.Errorf("transfer is of token %s, the escrow of token %s", log.To, escrow.Token)
+	}
+
+	data, err := log.GetTransferData()
+	if err != nil || data == nil {
+		return nil, fmt.Errorf("transfer has no transfer data")
+	}
+	if !strings.EqualFold(data.To, escrow.Holder) {
+		return nil, fmt.Errorf("transfer is to %s, the escrow is held by %s", data.To, escrow.Holder)
+	}
+
+	amount, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok || amount.Cmp(escrow.Amount) < 0 {
+		return nil, fmt.Errorf("transfer is of %s, the escrow of %s", data.Value, escrow.Amount)
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	escrowPayee   = "c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+	escrowArbiter = "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"
+)
+
+// escrowSignal creates a signal on an escrow signed by a public key at a time
+func escrowSignal(t *testing.T, escrow *nostr.Event, action EscrowAction, pubkey string, createdAt int64) *nostr.Event {
+	t.Helper()
+
+	evt, err := CreateEscrowSignalEvent(escrow, action, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create %s signal: %v", action, err)
+	}
+	evt.PubKey = pubkey
+	evt.CreatedAt = nostr.Timestamp(createdAt)
+	evt.ID = evt.GetID()
+	return evt
+}
+
+func TestEscrow(t *testing.T) {
+	escrow, err := CreateEscrowProposalEvent(EscrowProposalEvent{
+		Payer:      EscrowParty{PubKey: goldenPubKey, Address: "0x1111111111111111111111111111111111111111"},
+		Payee:      EscrowParty{PubKey: escrowPayee, Address: "0x3333333333333333333333333333333333333333"},
+		Arbiter:    escrowArbiter,
+		ChainID:    "100",
+		Token:      "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d",
+		Amount:     big.NewInt(1000000000000000000),
+		Holder:     "0x2222222222222222222222222222222222222222",
+		Conditions: []string{"The logo is delivered"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create escrow: %v", err)
+	}
+	escrow.ID = escrow.GetID()
+
+	if tag := escrow.Tags.FindWithValue("p", escrowPayee); len(tag) < 4 || tag[3] != "payee" {
+		t.Errorf("Expected p tag of the payee, got %v", tag)
+	}
+
+	transfer, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	transfer.ID = transfer.GetID()
+
+	funding, err := CreateEscrowFundingEvent(escrow, transfer)
+	if err != nil {
+		t.Fatalf("Failed to create funding: %v", err)
+	}
+	funding.CreatedAt = 100
+	funding.ID = funding.GetID()
+
+	fundingData, err := ParseEscrowFundingEvent(funding)
+	if err != nil {
+		t.Fatalf("Failed to parse funding: %v", err)
+	}
+	if fundingData.EscrowID != escrow.ID || fundingData.LogID != transfer.ID || fundingData.TxHash != goldenLog().TxHash {
+		t.Errorf("Expected funding of the escrow by the transfer, got %+v", fundingData)
+	}
+
+	// The holder must receive the funds
+	log := goldenLog()
+	log.ChainID = "1"
+	other, err := CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log: %v", err)
+	}
+	other.ID = other.GetID()
+	if _, err := CreateEscrowFundingEvent(escrow, other); err == nil {
+		t.Error("Expected an error for a transfer on another chain")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		events   []*nostr.Event
+		expected EscrowStatus
+	}{
+		{"proposed", nil, EscrowStatusProposed},
+		{"release before funding", []*nostr.Event{escrowSignal(t, escrow, EscrowActionRelease, goldenPubKey, 50)}, EscrowStatusProposed},
+		{"funded", []*nostr.Event{funding}, EscrowStatusFunded},
+		{"released by payer", []*nostr.Event{funding, escrowSignal(t, escrow, EscrowActionRelease, goldenPubKey, 200)}, EscrowStatusReleased},
+		{"released by payee", []*nostr.Event{funding, escrowSignal(t, escrow, EscrowActionRelease, escrowPayee, 200)}, EscrowStatusFunded},
+		{"refunded by payee", []*nostr.Event{funding, escrowSignal(t, escrow, EscrowActionRefund, escrowPayee, 200)}, EscrowStatusRefunded},
+		{"disputed", []*nostr.Event{funding, escrowSignal(t, escrow, EscrowActionDispute, escrowPayee, 200)}, EscrowStatusDisputed},
+		{"disputed then released by payer", []*nostr.Event{
+			funding,
+			escrowSignal(t, escrow, EscrowActionDispute, escrowPayee, 200),
+			escrowSignal(t, escrow, EscrowActionRelease, goldenPubKey, 300),
+		}, EscrowStatusDisputed},
+		{"settled by arbiter", []*nostr.Event{
+			funding,
+			escrowSignal(t, escrow, EscrowActionDispute, escrowPayee, 200),
+			escrowSignal(t, escrow, EscrowActionRefund, escrowArbiter, 300),
+		}, EscrowStatusRefunded},
+	} {
+		status, err := ComputeEscrowStatus(escrow, tc.events)
+		if err != nil {
+			t.Fatalf("%s: failed to compute status: %v", tc.name, err)
+		}
+		if status != tc.expected {
+			t.Errorf("%s: expected status %s, got %s", tc.name, tc.expected, status)
+		}
+	}
+}
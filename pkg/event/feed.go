@@ -0,0 +1,167 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultFeedLimit is the number of transfers of a feed when no limit is given
+const DefaultFeedLimit = 20
+
+// FeedContract is a token contract on a chain
+type FeedContract struct {
+	ChainID string
+	Address string
+}
+
+// FeedOptions selects the transfers of a feed, zero values select everything
+type FeedOptions struct {
+	Address   string         // Only the transfers from or to this address
+	Contracts []FeedContract // Only the transfers of these tokens
+	Limit     int            // Latest transfers kept, DefaultFeedLimit when zero
+
+	// Tokens gives the symbol and decimals of the tokens, DefaultTokenRegistry when nil. Amounts
+	// of unknown tokens are in base units.
+	Tokens *TokenRegistry
+}
+
+// FeedItem is a transfer of a feed, formatted for display
+type FeedItem struct {
+	ID        string `json:"id"` // Event of the transfer
+	ChainID   string `json:"chain_id"`
+	TxHash    string `json:"tx_hash"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Token     string `json:"token"`
+	Symbol    string `json:"symbol,omitempty"`
+	Amount    string `json:"amount"` // In whole tokens for known tokens
+	Alt       string `json:"alt,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Feed is a compact list of the latest transfers, e.g. for a widget on a community website
+type Feed struct {
+	Items     []FeedItem `json:"items"`
+	UpdatedAt int64      `json:"updated_at"` // Time of the newest transfer, 0 when empty
+}
+
+// ETag returns a strong entity tag of the feed, which only changes with its content
+func (f Feed) ETag() string {
+	data, _ := json.Marshal(f)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// BuildTransferFeed returns the latest ERC20 transfers of transfer and tx log events, newest
+// first. Transfers seen in several events appear once, with the alt text of the latest event, and
+// orphaned logs are left out. Other events are skipped.
+func BuildTransferFeed(events []*nostr.Event, opts FeedOptions) Feed {
+	tokens := opts.Tokens
+	if tokens == nil {
+		tokens = DefaultTokenRegistry
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultFeedLimit
+	}
+
+	// Oldest first, so that the latest status of a log wins
+	sorted := append([]*nostr.Event(nil), events...)
+	SortEventsByCreatedAt(sorted, false)
+
+	items := make(map[string]*FeedItem)
+	for _, evt := range sorted {
+		log, err := logFromEvent(evt)
+		if err != nil || !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+			continue
+		}
+
+		key := log.ChainID + ":" + strings.ToLower(log.Hash)
+
+		if DefaultKind(evt.Kind) == KindTxLog {
+			if txLog, err := ParseTxLogEvent(evt); err == nil && txLog.Status == TxLogStatusOrphaned {
+				delete(items, key)
+				continue
+			}
+		}
+
+		data, err := log.GetTransferData()
+		if err != nil || data == nil {
+			continue
+		}
+		if !matchesFeed(*log, data, opts) {
+			continue
+		}
+
+		item := feedItem(evt, *log, data, tokens)
+		items[key] = &item
+	}
+
+	feed := Feed{Items: make([]FeedItem, 0, len(items))}
+	for _, item := range items {
+		feed.Items = append(feed.Items, *item)
+	}
+	sort.Slice(feed.Items, func(i, j int) bool {
+		if feed.Items[i].CreatedAt != feed.Items[j].CreatedAt {
+			return feed.Items[i].CreatedAt > feed.Items[j].CreatedAt
+		}
+		return feed.Items[i].ID < feed.Items[j].ID
+	})
+
+	if len(feed.Items) > limit {
+		feed.Items = feed.Items[:limit]
+	}
+	if len(feed.Items) > 0 {
+		feed.UpdatedAt = feed.Items[0].CreatedAt
+	}
+	return feed
+}
+
+// matchesFeed checks if a transfer is selected by the options
+func matchesFeed(log neth.Log, data *neth.LogTransferData, opts FeedOptions) bool {
+	if opts.Address != "" && !strings.EqualFold(data.From, opts.Address) && !strings.EqualFold(data.To, opts.Address) {
+		return false
+	}
+	if len(opts.Contracts) == 0 {
+		return true
+	}
+	for _, contract := range opts.Contracts {
+		if contract.ChainID == log.ChainID && strings.EqualFold(contract.Address, log.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// feedItem returns the feed item of a transfer
+func feedItem(evt *nostr.Event, log neth.Log, data *neth.LogTransferData, tokens *TokenRegistry) FeedItem {
+	item := FeedItem{
+		ID:        evt.ID,
+		ChainID:   log.ChainID,
+		TxHash:    log.TxHash,
+		From:      data.From,
+		To:        data.To,
+		Token:     log.To,
+		Amount:    data.Value,
+		CreatedAt: log.CreatedAt.Unix(),
+	}
+
+	if token, ok := tokens.Token(log.ChainID, log.To); ok {
+		item.Symbol = token.Symbol
+		if value, ok := new(big.Int).SetString(data.Value, 10); ok {
+			item.Amount = neth.FormatUnits(value, token.Decimals)
+		}
+	}
+
+	if alt := evt.Tags.Find("alt"); len(alt) >= 2 {
+		item.Alt = alt[1]
+	}
+	return item
+}
@@ -0,0 +1,80 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestBuildTransferFeed(t *testing.T) {
+	tokens := NewTokenRegistry()
+	list, err := neth.ParseTokenList([]byte(`{"name":"Test","tokens":[{"chainId":100,"address":"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d","name":"EURe","symbol":"EURe","decimals":18}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse token list: %v", err)
+	}
+	tokens.AddTokenList(list)
+
+	// The same transfer as a tx log and a transfer event
+	txLog, _ := CreateTxLogEvent(goldenLog())
+	txLog.ID = "log"
+	transfer, _ := CreateTxTransferEvent(goldenLog())
+	transfer.ID = "transfer"
+
+	later := goldenLog()
+	later.Hash = "0x02"
+	later.CreatedAt = later.CreatedAt.Add(time.Hour)
+	laterTransfer, _ := CreateTxTransferEvent(later)
+	laterTransfer.ID = "later"
+
+	orphan := goldenLog()
+	orphan.Hash = "0x03"
+	orphanLog, _ := CreateTxLogEvent(orphan, WithBlockNumber(10))
+	orphaned, err := UpdateTxLogStatus(orphanLog, TxLogStatusOrphaned)
+	if err != nil {
+		t.Fatalf("Failed to orphan log: %v", err)
+	}
+	orphaned.CreatedAt++
+
+	events := []*nostr.Event{laterTransfer, txLog, transfer, orphanLog, orphaned}
+	feed := BuildTransferFeed(events, FeedOptions{Tokens: tokens})
+	if len(feed.Items) != 2 {
+		t.Fatalf("Expected 2 transfers, got %v", feed.Items)
+	}
+
+	first := feed.Items[0]
+	if first.ID != "later" || feed.UpdatedAt != later.CreatedAt.Unix() {
+		t.Errorf("Expected the later transfer first, got %+v", first)
+	}
+	if first.Symbol != "EURe" || first.Amount != "1" || first.From != "0x1111111111111111111111111111111111111111" {
+		t.Errorf("Expected 1 EURe from 0x1111..., got %+v", first)
+	}
+	if first.Alt == "" {
+		t.Error("Expected the alt text of the transfer")
+	}
+
+	// Limit, address and contract selection
+	if feed := BuildTransferFeed(events, FeedOptions{Limit: 1}); len(feed.Items) != 1 || feed.Items[0].ID != "later" {
+		t.Errorf("Expected the later transfer only, got %v", feed.Items)
+	}
+	if feed := BuildTransferFeed(events, FeedOptions{Address: "0x3333333333333333333333333333333333333333"}); len(feed.Items) != 0 {
+		t.Errorf("Expected no transfer of another address, got %v", feed.Items)
+	}
+	contract := FeedContract{ChainID: "100", Address: "0xe91d153e0b41518a2ce8dd3d7944fa863463a97d"}
+	if feed := BuildTransferFeed(events, FeedOptions{Contracts: []FeedContract{contract}}); len(feed.Items) != 2 {
+		t.Errorf("Expected the 2 transfers of the token, got %v", feed.Items)
+	}
+	contract.ChainID = "1"
+	if feed := BuildTransferFeed(events, FeedOptions{Contracts: []FeedContract{contract}}); len(feed.Items) != 0 {
+		t.Errorf("Expected no transfer of the token on another chain, got %v", feed.Items)
+	}
+
+	// The entity tag follows the content
+	if BuildTransferFeed(events, FeedOptions{Tokens: tokens}).ETag() != feed.ETag() {
+		t.Error("Expected the same feed to have the same entity tag")
+	}
+	if BuildTransferFeed(events[:1], FeedOptions{Tokens: tokens}).ETag() == feed.ETag() {
+		t.Error("Expected another feed to have another entity tag")
+	}
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// FiatValue is the fiat annotation of a transfer, the rate and value when the event was created
+type FiatValue struct {
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`  // Price of one whole token
+	Value    string `json:"value"` // Value of the transferred amount
+}
+
+// fiatTag returns the fiat tag of an amount of a token, ["fiat", currency, rate, value]. No tag
+// is returned when the provider has no rate for the token.
+func fiatTag(provider neth.RateProvider, log neth.Log, amount *big.Int) (nostr.Tag, error) {
+	rate, err := provider.Rate(context.Background(), log.ChainID, log.To, log.CreatedAt)
+	if errors.Is(err, neth.ErrNoRate) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the fiat rate: %w", err)
+	}
+
+	return nostr.Tag{"fiat", rate.Currency, rate.String(), rate.Value(amount)}, nil
+}
+
+// GetFiatValueFromEvent returns the fiat annotation of a transfer, nil when it has none
+func GetFiatValueFromEvent(evt *nostr.Event) (*FiatValue, error) {
+	tag := evt.Tags.Find("fiat")
+	if tag == nil {
+		return nil, nil
+	}
+	if len(tag) < 4 {
+		return nil, fmt.Errorf("invalid fiat tag: %v", tag)
+	}
+
+	return &FiatValue{Currency: tag[1], Rate: tag[2], Value: tag[3]}, nil
+}
+
+// StableAmount is the currency annotation of a stablecoin transfer
+type StableAmount struct {
+	Currency string `json:"currency"` // Currency of the peg, e.g. "USD"
+	Amount   string `json:"amount"`   // Transferred amount in whole tokens
+}
+
+// GetStableAmountFromEvent returns the currency annotation of a stablecoin transfer, nil when it
+// has none
+func GetStableAmountFromEvent(evt *nostr.Event) (*StableAmount, error) {
+	tag := evt.Tags.Find("currency")
+	if tag == nil {
+		return nil, nil
+	}
+	if len(tag) < 3 {
+		return nil, fmt.Errorf("invalid currency tag: %v", tag)
+	}
+
+	return &StableAmount{Currency: tag[1], Amount: tag[2]}, nil
+}
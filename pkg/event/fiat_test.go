@@ -0,0 +1,40 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func TestTransferFiatValue(t *testing.T) {
+	provider := neth.NewStaticRateProvider()
+	provider.SetRate("100", "0xe91d153e0b41518a2ce8dd3d7944fa863463a97d", neth.FiatRate{
+		Currency:      "EUR",
+		Rate:          big.NewInt(9200),
+		Decimals:      4,
+		TokenDecimals: 18,
+	})
+
+	transfer, err := CreateTxTransferEvent(goldenLog(), WithRateProvider(provider))
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+
+	fiat, err := GetFiatValueFromEvent(transfer)
+	if err != nil {
+		t.Fatalf("Failed to get fiat value: %v", err)
+	}
+	if fiat == nil || fiat.Currency != "EUR" || fiat.Rate != "0.92" || fiat.Value != "0.92" {
+		t.Errorf("Expected 0.92 EUR at 0.92, got %+v", fiat)
+	}
+
+	// Tokens without a rate are not annotated
+	transfer, err = CreateTxTransferEvent(goldenLog(), WithRateProvider(neth.NewStaticRateProvider()))
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	if fiat, _ := GetFiatValueFromEvent(transfer); fiat != nil {
+		t.Errorf("Expected no fiat value, got %+v", fiat)
+	}
+}
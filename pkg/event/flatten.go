@@ -0,0 +1,216 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NestedKeySeparator joins the keys of nested objects and the indexes of arrays in the names of
+// flattened tags, e.g. "order.amount" or "ids.0"
+const NestedKeySeparator = "."
+
+// FlattenPolicy selects the keys of log data flattened into tags, so that arbitrary contract data
+// does not publish arbitrary tag names. Keys are matched case-insensitively, nested keys with
+// their full path, e.g. "order.amount", or the path of an ancestor, e.g. "order". The data is
+// always kept in the content of the events.
+type FlattenPolicy struct {
+	Allow []string // Only these keys are flattened, every key when empty
+	Deny  []string // These keys are never flattened, they win over Allow
+
+	// MaxDepth is how deep nested objects and arrays are flattened, they are left out at 0. At 1
+	// the values of {"order":{"amount":1},"ids":[4,5]} become the tags "order.amount", "ids.0"
+	// and "ids.1", deeper values need a higher depth.
+	MaxDepth int
+
+	// Rename maps keys, or paths of nested keys, to the names of their tags, e.g. "value" to
+	// "amount" or "tokenId" to "token_id", so that heterogeneous contracts publish the same
+	// vocabulary. Allow and Deny match the keys before renaming.
+	Rename map[string]string
+}
+
+// reservedTagNames are the tags with a meaning to relays and clients, or set by the constructors
+// themselves, that log data never publishes. Single letter names, indexed by relays, are reserved
+// as well.
+var reservedTagNames = map[string]bool{
+	"alt":             true,
+	"amount_sortable": true,
+	"block":           true,
+	"block_hash":      true,
+	"challenge":       true,
+	"client":          true,
+	"content-warning": true,
+	"delete":          true,
+	"expiration":      true,
+	"layer":           true,
+	"network":         true,
+	"proxy":           true,
+	"relays":          true,
+	"status":          true,
+	"subject":         true,
+}
+
+// IsReservedTagName checks if a tag name is reserved, so that log data is not flattened into it
+func IsReservedTagName(name string) bool {
+	return len(name) == 1 || reservedTagNames[strings.ToLower(name)]
+}
+
+// TagName returns the name of the tag a key of log data is flattened into
+func (p FlattenPolicy) TagName(key string) string {
+	for from, to := range p.Rename {
+		if strings.EqualFold(key, from) {
+			return to
+		}
+	}
+	return key
+}
+
+// DataKey returns the key of log data a tag was flattened from, the tag name when it was not
+// renamed. Keys renamed to the same tag name are ambiguous, the first in order is returned.
+func (p FlattenPolicy) DataKey(tagName string) string {
+	keys := make([]string, 0, len(p.Rename))
+	for from, to := range p.Rename {
+		if to == tagName {
+			keys = append(keys, from)
+		}
+	}
+	if len(keys) == 0 {
+		return tagName
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// Flattens checks if a key of log data, or the path of a nested key, is flattened into a tag
+func (p FlattenPolicy) Flattens(key string) bool {
+	if matchesKeyPath(p.Deny, key) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchesKeyPath(p.Allow, key)
+}
+
+// matchesKeyPath checks if a key path or one of its ancestors is in a list
+func matchesKeyPath(keys []string, path string) bool {
+	for _, key := range keys {
+		if strings.EqualFold(path, key) || (len(path) > len(key) && strings.EqualFold(path[:len(key)], key) && strings.HasPrefix(path[len(key):], NestedKeySeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+var flattenPolicy struct {
+	sync.RWMutex
+	policy FlattenPolicy
+}
+
+// SetFlattenPolicy sets the keys of log data flattened into tags by the constructors that are not
+// given WithFlattenPolicy, every key is flattened by default
+func SetFlattenPolicy(policy FlattenPolicy) {
+	flattenPolicy.Lock()
+	defer flattenPolicy.Unlock()
+
+	flattenPolicy.policy = policy
+}
+
+// CurrentFlattenPolicy returns the keys of log data flattened into tags by the constructors
+func CurrentFlattenPolicy() FlattenPolicy {
+	flattenPolicy.RLock()
+	defer flattenPolicy.RUnlock()
+
+	return flattenPolicy.policy
+}
+
+// flattenDataToTags flattens the data map into tags, using "p" for address values
+// The data is dynamic and can be any event, but will always contain "topic" which is a hash. The
+// keys are selected by the given FlattenPolicy and the tags sanitized with the given
+// SanitizePolicy. Numbers are kept as written, so that large token amounts keep their precision.
+func flattenDataToTags(b []byte, policy FlattenPolicy, sanitize SanitizePolicy) []nostr.Tag {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var data map[string]interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil
+	}
+
+	var tags []nostr.Tag
+	flattenValue(&tags, policy, "", data, 0)
+
+	return sanitizeDataTags(sanitize, tags)
+}
+
+// flattenValue appends the tags of a value of the data at a key path, nested objects and arrays
+// are flattened down to the maximum depth of the policy
+func flattenValue(tags *[]nostr.Tag, policy FlattenPolicy, key string, value interface{}, depth int) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if key != "" && depth > policy.MaxDepth {
+			return
+		}
+
+		// Keys are visited in order so that the same data always yields the same tags and event ID
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			flattenValue(tags, policy, nestedKey(key, k), value[k], depth+1)
+		}
+	case []interface{}:
+		if depth > policy.MaxDepth {
+			return
+		}
+		for i, item := range value {
+			flattenValue(tags, policy, nestedKey(key, strconv.Itoa(i)), item, depth+1)
+		}
+	case string:
+		if !policy.Flattens(key) {
+			return
+		}
+		if isEthereumAddress(value) {
+			// Use "p" tag for 0x addresses
+			*tags = append(*tags, []string{"p", value})
+		} else {
+			// Use the key as tag name for other string values, e.g. the topic hash
+			appendDataTag(tags, policy, key, value)
+		}
+	case json.Number:
+		if !policy.Flattens(key) {
+			return
+		}
+		// Full precision, a float64 would round amounts above 2^53
+		appendDataTag(tags, policy, key, value.String())
+	case bool:
+		if !policy.Flattens(key) {
+			return
+		}
+		appendDataTag(tags, policy, key, fmt.Sprintf("%t", value))
+	}
+}
+
+// appendDataTag appends the tag of a value of the data, unless its name is reserved. The value is
+// still in the content of the event.
+func appendDataTag(tags *[]nostr.Tag, policy FlattenPolicy, key, value string) {
+	name := policy.TagName(key)
+	if IsReservedTagName(name) {
+		return
+	}
+	*tags = append(*tags, []string{name, value})
+}
+
+// nestedKey returns the path of a key of a nested object or an index of an array
+func nestedKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + NestedKeySeparator + key
+}
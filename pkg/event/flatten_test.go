@@ -0,0 +1,220 @@
+package event
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestFlattenPolicy(t *testing.T) {
+	data := []byte(`{"from":"0x1111111111111111111111111111111111111111","value":"1","memo":"hi","nonce":7}`)
+
+	tests := []struct {
+		name     string
+		policy   FlattenPolicy
+		expected []string
+	}{
+		{"default", FlattenPolicy{}, []string{"p", "memo", "nonce", "value"}},
+		{"allow", FlattenPolicy{Allow: []string{"From", "value"}}, []string{"p", "value"}},
+		{"deny", FlattenPolicy{Deny: []string{"memo", "NONCE"}}, []string{"p", "value"}},
+		{"deny wins", FlattenPolicy{Allow: []string{"memo", "value"}, Deny: []string{"memo"}}, []string{"value"}},
+	}
+
+	for _, tt := range tests {
+		tags := flattenDataToTags(data, tt.policy, DefaultSanitizePolicy)
+
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, tag[0])
+		}
+		if len(names) != len(tt.expected) {
+			t.Errorf("%s: Expected tags %v, got %v", tt.name, tt.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expected[i] {
+				t.Errorf("%s: Expected tags %v, got %v", tt.name, tt.expected, names)
+				break
+			}
+		}
+	}
+
+	// The content keeps the data of the keys left out of the tags
+	log := goldenLog()
+	raw := json.RawMessage(data)
+	log.Data = &raw
+	evt, err := CreateTxLogEvent(log, WithFlattenPolicy(FlattenPolicy{Deny: []string{"memo"}}))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if evt.Tags.GetFirst([]string{"memo"}) != nil {
+		t.Error("Expected no memo tag")
+	}
+	txLog, err := ParseTxLogEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse tx log event: %v", err)
+	}
+	var parsed map[string]any
+	json.Unmarshal(*txLog.LogData.Data, &parsed)
+	if parsed["memo"] != "hi" {
+		t.Errorf("Expected the memo in the content, got %v", parsed["memo"])
+	}
+
+	// SetFlattenPolicy is the default of the constructors, the option wins over it
+	SetFlattenPolicy(FlattenPolicy{Deny: []string{"memo"}})
+	defer SetFlattenPolicy(FlattenPolicy{})
+
+	evt, err = CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if evt.Tags.GetFirst([]string{"memo"}) != nil {
+		t.Error("Expected no memo tag with the default policy")
+	}
+	evt, err = CreateTxLogEvent(log, WithFlattenPolicy(FlattenPolicy{}))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if evt.Tags.GetFirst([]string{"memo"}) == nil {
+		t.Error("Expected a memo tag with the policy of the option")
+	}
+}
+
+func TestFlattenNestedData(t *testing.T) {
+	data := []byte(`{"value":"1","order":{"amount":2,"maker":"0x1111111111111111111111111111111111111111","fees":{"bps":30}},"ids":[4,5],"items":[{"id":"a"}]}`)
+
+	tests := []struct {
+		name     string
+		policy   FlattenPolicy
+		expected [][2]string
+	}{
+		{"top level", FlattenPolicy{}, [][2]string{{"value", "1"}}},
+		{"depth 1", FlattenPolicy{MaxDepth: 1}, [][2]string{{"ids.0", "4"}, {"ids.1", "5"}, {"order.amount", "2"}, {"p", "0x1111111111111111111111111111111111111111"}, {"value", "1"}}},
+		{"depth 2", FlattenPolicy{MaxDepth: 2}, [][2]string{{"ids.0", "4"}, {"ids.1", "5"}, {"items.0.id", "a"}, {"order.amount", "2"}, {"order.fees.bps", "30"}, {"p", "0x1111111111111111111111111111111111111111"}, {"value", "1"}}},
+		{"allow ancestor", FlattenPolicy{MaxDepth: 2, Allow: []string{"order"}, Deny: []string{"order.fees"}}, [][2]string{{"order.amount", "2"}, {"p", "0x1111111111111111111111111111111111111111"}}},
+		{"allow path", FlattenPolicy{MaxDepth: 2, Allow: []string{"ORDER.fees.bps"}}, [][2]string{{"order.fees.bps", "30"}}},
+	}
+
+	for _, tt := range tests {
+		tags := flattenDataToTags(data, tt.policy, DefaultSanitizePolicy)
+
+		if len(tags) != len(tt.expected) {
+			t.Errorf("%s: Expected tags %v, got %v", tt.name, tt.expected, tags)
+			continue
+		}
+		for i, tag := range tags {
+			if tag[0] != tt.expected[i][0] || tag[1] != tt.expected[i][1] {
+				t.Errorf("%s: Expected tags %v, got %v", tt.name, tt.expected, tags)
+				break
+			}
+		}
+	}
+}
+
+func TestFlattenNumbersKeepPrecision(t *testing.T) {
+	tags := flattenDataToTags([]byte(`{"value":123456789012345678901234567890,"rate":1.5,"count":7}`), FlattenPolicy{}, DefaultSanitizePolicy)
+
+	expected := [][2]string{{"count", "7"}, {"rate", "1.5"}, {"value", "123456789012345678901234567890"}}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected tags %v, got %v", expected, tags)
+	}
+	for i, tag := range tags {
+		if tag[0] != expected[i][0] || tag[1] != expected[i][1] {
+			t.Errorf("Expected tag %v, got %v", expected[i], tag)
+		}
+	}
+
+	// Transfers with a numeric amount keep it in the amount tag
+	log := goldenLog()
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":123456789012345678901234567890}`)
+	log.Data = &data
+
+	evt, err := CreateTxTransferEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create transfer event: %v", err)
+	}
+	if tag := evt.Tags.GetFirst([]string{"amount"}); tag == nil || (*tag)[1] != "123456789012345678901234567890" {
+		t.Errorf("Expected the full precision amount, got %v", tag)
+	}
+}
+
+func TestFlattenRename(t *testing.T) {
+	policy := FlattenPolicy{Rename: map[string]string{"wad": "value", "tokenId": "token_id"}}
+	SetFlattenPolicy(policy)
+	defer SetFlattenPolicy(FlattenPolicy{})
+
+	tags := flattenDataToTags([]byte(`{"wad":"5","TokenId":7,"memo":"hi"}`), policy, DefaultSanitizePolicy)
+	expected := [][2]string{{"token_id", "7"}, {"memo", "hi"}, {"value", "5"}}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected tags %v, got %v", expected, tags)
+	}
+	for i, tag := range tags {
+		if tag[0] != expected[i][0] || tag[1] != expected[i][1] {
+			t.Errorf("Expected tag %v, got %v", expected[i], tag)
+		}
+	}
+
+	if key := policy.DataKey("token_id"); key != "tokenId" {
+		t.Errorf("Expected data key tokenId, got %s", key)
+	}
+	if key := policy.DataKey("memo"); key != "memo" {
+		t.Errorf("Expected data key memo, got %s", key)
+	}
+
+	// Tag templates of the topic registry keep using the data keys
+	topic := "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"
+	registry := NewTopicRegistry()
+	if err := registry.Register(topic, TopicEntry{Name: "deposit", Tags: []nostr.Tag{{"deposited", "{wad}"}}}); err != nil {
+		t.Fatalf("Failed to register topic: %v", err)
+	}
+
+	log := goldenLog()
+	log.Topic = topic
+	data := json.RawMessage(`{"wad":"5"}`)
+	log.Data = &data
+
+	_, registered, err := registry.Tags(log)
+	if err != nil {
+		t.Fatalf("Failed to render tags: %v", err)
+	}
+	if len(registered) != 2 || registered[1][0] != "deposited" || registered[1][1] != "5" {
+		t.Errorf("Expected the deposited tag, got %v", registered)
+	}
+}
+
+func TestFlattenReservedTagNames(t *testing.T) {
+	policy := FlattenPolicy{Rename: map[string]string{"memo": "e"}}
+
+	log := goldenLog()
+	data := json.RawMessage(`{"expiration":"1","d":"spoofed","e":"spoofed","-":"","delete":"true","Status":"failed","memo":"hi","note":"kept"}`)
+	log.Data = &data
+
+	tags := flattenDataToTags(*log.Data, policy, DefaultSanitizePolicy)
+	if len(tags) != 1 || tags[0][0] != "note" || tags[0][1] != "kept" {
+		t.Errorf("Expected only the note tag, got %v", tags)
+	}
+
+	evt, err := CreateTxLogEvent(log, WithFlattenPolicy(policy))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if tag := evt.Tags.Find("expiration"); tag != nil {
+		t.Errorf("Expected no expiration tag, got %v", tag)
+	}
+	if tag := evt.Tags.Find("-"); tag != nil {
+		t.Errorf("Expected no protected tag, got %v", tag)
+	}
+	if d := evt.Tags.GetAll([]string{"d"}); len(d) != 1 || d[0][1] != log.Hash {
+		t.Errorf("Expected the d tag %s only, got %v", log.Hash, d)
+	}
+	if e := evt.Tags.GetAll([]string{"e"}); len(e) != 0 {
+		t.Errorf("Expected no e tags, got %v", e)
+	}
+
+	// The data is kept in the content
+	if !strings.Contains(evt.Content, `"spoofed"`) {
+		t.Errorf("Expected the data in the content, got %s", evt.Content)
+	}
+}
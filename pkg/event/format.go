@@ -0,0 +1,288 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// FormatTarget is what a human-readable text is rendered for
+type FormatTarget string
+
+const (
+	FormatAlt       FormatTarget = "alt"        // Alt tag of the created events
+	FormatMessage   FormatTarget = "message"    // Direct messages, e.g. of the notifier
+	FormatPushTitle FormatTarget = "push_title" // Title of push notifications
+	FormatPushBody  FormatTarget = "push_body"  // Body of push notifications
+)
+
+// FormatData is what the templates of a formatter are executed with
+type FormatData struct {
+	Event   *nostr.Event
+	Kind    int
+	Type    string            // Value of the first t tag, e.g. "tx_log"
+	ChainID string            // Layer tag
+	Content map[string]any    // Content of the event when it is a JSON object
+	Tags    map[string]string // First value of every tag
+
+	// Set for tx log and transfer events
+	Log       *neth.Log
+	TopicName string                // Curated name of the topic of the log, e.g. "erc20_transfer"
+	Transfer  *neth.LogTransferData // ERC20 transfers only
+	Amount    string                // Transferred amount in whole tokens, in base units for unknown tokens
+	Symbol    string                // Symbol of the token, its address when unknown
+	Currency  string                // Currency of the peg of stablecoins, e.g. "USD"
+
+	Locale string // Locale the text is rendered in
+}
+
+// T translates a message of DefaultCatalog to the locale of the text, e.g.
+// {{.T "transfer.received" .Transfer.To .Amount .Symbol .Transfer.From .ChainID}}
+func (d FormatData) T(id string, args ...any) string {
+	return DefaultCatalog.Translate(d.Locale, id, args...)
+}
+
+// formatFuncs are the functions available in the templates
+var formatFuncs = template.FuncMap{
+	// units formats an amount in base units with the given decimals, e.g. {{units .Log.Value.String 18}}
+	"units": func(value string, decimals int64) string {
+		amount, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return value
+		}
+		return neth.FormatUnits(amount, decimals)
+	},
+	// nevent returns the NIP-19 nevent of an event, e.g. nostr:{{nevent .Event}}
+	"nevent": func(evt *nostr.Event) string {
+		nevent, _ := EncodeEventIDToNevent(evt.ID, "", evt.PubKey, evt.Kind)
+		return nevent
+	},
+	// short shortens an address or public key to its first and last characters
+	"short": func(value string) string {
+		if len(value) <= 12 {
+			return value
+		}
+		return value[:6] + "…" + value[len(value)-4:]
+	},
+}
+
+// Formatter renders the human-readable texts of events from text/template templates registered
+// by target and key. Keys are log topics, e.g. neth.TopicERC20Transfer, or kinds as decimal
+// strings, e.g. "9000". The template of the topic of a log wins over the template of its kind.
+//
+// Templates can be registered for a locale, they win over the templates without locale when
+// formatting in that locale or a more specific one, e.g. "pt" templates are used for "pt-BR".
+type Formatter struct {
+	mu        *sync.RWMutex                 // Shared with the localized views
+	templates map[string]*template.Template // By target, key and locale, shared with the localized views
+	tokens    *TokenRegistry
+	locale    string // Locale of the constructors when empty
+}
+
+// DefaultFormatter is consulted by the constructors for the alt tags of transfers, user ops and
+// group events, they keep their built-in alt tag when it has no template
+var DefaultFormatter = NewFormatter(nil)
+
+// NewFormatter creates an empty formatter reading token symbols and decimals from a registry,
+// DefaultTokenRegistry when nil
+func NewFormatter(tokens *TokenRegistry) *Formatter {
+	if tokens == nil {
+		tokens = DefaultTokenRegistry
+	}
+	return &Formatter{mu: &sync.RWMutex{}, templates: make(map[string]*template.Template), tokens: tokens}
+}
+
+// Localized returns a view of the formatter rendering in a locale, it shares the templates of
+// the formatter
+func (f *Formatter) Localized(locale string) *Formatter {
+	localized := *f
+	localized.locale = normalizeLocale(locale)
+	return &localized
+}
+
+// Locale returns the locale the formatter renders in
+func (f *Formatter) Locale() string {
+	if f.locale == "" {
+		return Locale()
+	}
+	return f.locale
+}
+
+// formatKey returns the key of a template, locale is empty for the templates without locale
+func formatKey(target FormatTarget, key, locale string) string {
+	formatKey := string(target) + "/" + strings.ToLower(key)
+	if locale != "" {
+		formatKey += "@" + normalizeLocale(locale)
+	}
+	return formatKey
+}
+
+// Register parses a template and registers it for a target and key, replacing any previous one
+func (f *Formatter) Register(target FormatTarget, key, text string) error {
+	return f.RegisterLocale("", target, key, text)
+}
+
+// RegisterLocale parses a template and registers it for a target and key in a locale, replacing
+// any previous one
+func (f *Formatter) RegisterLocale(locale string, target FormatTarget, key, text string) error {
+	t, err := template.New(formatKey(target, key, locale)).Funcs(formatFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid %s template of %s: %w", target, key, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.templates[formatKey(target, key, locale)] = t
+	return nil
+}
+
+// Unregister removes the template without locale of a target and key
+func (f *Formatter) Unregister(target FormatTarget, key string) {
+	f.UnregisterLocale("", target, key)
+}
+
+// UnregisterLocale removes the template of a target and key in a locale
+func (f *Formatter) UnregisterLocale(locale string, target FormatTarget, key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.templates, formatKey(target, key, locale))
+}
+
+// Format renders the text of an event for a target, false when no template matches the event
+func (f *Formatter) Format(target FormatTarget, evt *nostr.Event) (string, bool, error) {
+	log, _ := logFromEvent(evt)
+	locale := f.Locale()
+
+	t := f.template(target, log, DefaultKind(evt.Kind), locale)
+	if t == nil {
+		return "", false, nil
+	}
+
+	data := f.data(evt, log)
+	data.Locale = locale
+
+	var text strings.Builder
+	if err := t.Execute(&text, data); err != nil {
+		return "", false, fmt.Errorf("failed to format %s of event kind %d: %w", target, evt.Kind, err)
+	}
+
+	return text.String(), true, nil
+}
+
+// template returns the template of a target for the topic of a log or a kind in a locale. The
+// locale, its language, the templates without locale and the templates of DefaultLocale are
+// tried in order.
+func (f *Formatter) template(target FormatTarget, log *neth.Log, kind int, locale string) *template.Template {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if len(f.templates) == 0 {
+		return nil
+	}
+
+	keys := []string{strconv.Itoa(kind)}
+	if log != nil {
+		keys = append([]string{log.Topic}, keys...)
+	}
+
+	fallbacks := localeFallbacks(locale)
+	locales := append(fallbacks[:len(fallbacks)-1:len(fallbacks)-1], "", DefaultLocale)
+
+	for _, key := range keys {
+		for _, locale := range locales {
+			if t, ok := f.templates[formatKey(target, key, locale)]; ok {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// data returns the template data of an event
+func (f *Formatter) data(evt *nostr.Event, log *neth.Log) FormatData {
+	data := FormatData{Event: evt, Kind: DefaultKind(evt.Kind), Tags: make(map[string]string), Log: log}
+
+	json.Unmarshal([]byte(evt.Content), &data.Content)
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 {
+			if _, ok := data.Tags[tag[0]]; !ok {
+				data.Tags[tag[0]] = tag[1]
+			}
+		}
+	}
+	data.Type = TypeTagValue(data.Tags["t"])
+	data.ChainID = data.Tags["layer"]
+
+	if log == nil {
+		return data
+	}
+	data.TopicName, _ = neth.TopicName(log.Topic)
+
+	if !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+		return data
+	}
+
+	transfer, err := log.GetTransferData()
+	if err != nil || transfer == nil {
+		return data
+	}
+	data.Transfer = transfer
+	data.Amount, data.Symbol = transfer.Value, log.To
+
+	if token, ok := f.tokens.Token(log.ChainID, log.To); ok {
+		data.Symbol = token.Symbol
+		if value, ok := new(big.Int).SetString(transfer.Value, 10); ok {
+			data.Amount = neth.FormatUnits(value, token.Decimals)
+		}
+	}
+
+	if coin, ok := f.tokens.Stablecoin(log.ChainID, log.To); ok {
+		data.Currency = coin.Currency
+		if !f.tokens.IsKnownToken(log.ChainID, log.To) {
+			data.Symbol = coin.Symbol
+			if value, ok := new(big.Int).SetString(transfer.Value, 10); ok {
+				data.Amount = neth.FormatUnits(value, coin.Decimals)
+			}
+		}
+	}
+
+	return data
+}
+
+// formatAlt replaces the alt tag of a created event with the alt template of DefaultFormatter in
+// the locale of the constructors, if any, and sanitizes it with the current policy
+func formatAlt(evt *nostr.Event) (*nostr.Event, error) {
+	return formatSanitizedAlt(evt, CurrentSanitizePolicy())
+}
+
+// formatSanitizedAlt is formatAlt sanitizing the alt tag with a policy
+func formatSanitizedAlt(evt *nostr.Event, policy SanitizePolicy) (*nostr.Event, error) {
+	alt, ok, err := DefaultFormatter.Format(FormatAlt, evt)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "alt" {
+			if !ok {
+				alt = tag[1]
+			}
+			evt.Tags[i] = nostr.Tag{"alt", sanitizeAlt(policy, alt)}
+			return evt, nil
+		}
+	}
+
+	if ok {
+		evt.Tags = append(evt.Tags, nostr.Tag{"alt", sanitizeAlt(policy, alt)})
+	}
+	return evt, nil
+}
@@ -0,0 +1,107 @@
+package event
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func TestFormatterTemplates(t *testing.T) {
+	tokens := NewTokenRegistry()
+	list, err := neth.ParseTokenList([]byte(`{"name":"Test","tokens":[{"chainId":100,"address":"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d","name":"EURe","symbol":"EURe","decimals":18}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse token list: %v", err)
+	}
+	tokens.AddTokenList(list)
+
+	formatter := NewFormatter(tokens)
+	if err := formatter.Register(FormatMessage, neth.TopicERC20Transfer, "{{short .Transfer.From}} sent you {{.Amount}} {{.Symbol}} on chain {{.ChainID}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	if err := formatter.Register(FormatMessage, "111000", "Log {{.Log.Hash}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	if err := formatter.Register(FormatMessage, "9000", "{{short .Tags.p}} joined {{.Tags.h}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	if err := formatter.Register(FormatAlt, "9000", "{{.Tags.h"); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+
+	transfer, _ := CreateTxLogEvent(goldenLog())
+	text, ok, err := formatter.Format(FormatMessage, transfer)
+	if err != nil || !ok {
+		t.Fatalf("Failed to format transfer: %v", err)
+	}
+	if text != "0x1111…1111 sent you 1 EURe on chain 100" {
+		t.Errorf("Expected the transfer message, got %q", text)
+	}
+
+	// The template of the topic wins, other logs fall back to the kind
+	other := goldenLog()
+	other.Topic = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+	otherLog, _ := CreateTxLogEvent(other)
+	if text, _, _ := formatter.Format(FormatMessage, otherLog); text != "Log "+other.Hash {
+		t.Errorf("Expected the kind template, got %q", text)
+	}
+
+	join, _ := CreateAddUserEvent("group", "0xabcdef0123456789abcdef", "")
+	if text, _, _ := formatter.Format(FormatMessage, join); text != "0xabcd…cdef joined group" {
+		t.Errorf("Expected the group message, got %q", text)
+	}
+
+	if _, ok, _ := formatter.Format(FormatPushBody, transfer); ok {
+		t.Error("Expected no push template")
+	}
+}
+
+func TestFormatterStablecoins(t *testing.T) {
+	tokens := NewTokenRegistry()
+	tokens.AddStablecoins(neth.Stablecoin{ChainID: 100, Address: "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d", Symbol: "WXDAI", Decimals: 18, Currency: "USD"})
+
+	formatter := NewFormatter(tokens)
+	if err := formatter.Register(FormatPushBody, neth.TopicERC20Transfer, "{{.Amount}} {{.Symbol}} ({{.Currency}})"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	transfer, _ := CreateTxLogEvent(goldenLog())
+	if text, _, _ := formatter.Format(FormatPushBody, transfer); text != "1 WXDAI (USD)" {
+		t.Errorf("Expected the stablecoin amount, got %q", text)
+	}
+}
+
+func TestFormatterAltTags(t *testing.T) {
+	if err := DefaultFormatter.Register(FormatAlt, neth.TopicERC20Transfer, "Transfer of {{.Transfer.Value}} to {{.Transfer.To}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	defer DefaultFormatter.Unregister(FormatAlt, neth.TopicERC20Transfer)
+
+	evt, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+
+	alts := 0
+	for _, tag := range evt.Tags {
+		if tag[0] == "alt" {
+			alts++
+			if tag[1] != "Transfer of 1000000000000000000 to 0x2222222222222222222222222222222222222222" {
+				t.Errorf("Expected the templated alt tag, got %q", tag[1])
+			}
+		}
+	}
+	if alts != 1 {
+		t.Errorf("Expected 1 alt tag, got %d", alts)
+	}
+
+	// Events without a template keep their built-in alt tag
+	userOp, err := CreateUserOpEvent(big.NewInt(100), nil, nil, nil, nil, 0, goldenUserOp(), EventTypeUserOpRequested)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+	if tag := userOp.Tags.GetFirst([]string{"alt"}); tag == nil || strings.HasPrefix((*tag)[1], "Transfer of") {
+		t.Errorf("Expected the built-in alt tag, got %v", tag)
+	}
+}
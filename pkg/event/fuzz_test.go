@@ -0,0 +1,136 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// eventParsers calls every parser and tag helper of the package on an event
+var eventParsers = map[string]func(evt *nostr.Event) error{
+	"ParseTxLogEvent":                  func(evt *nostr.Event) error { _, err := ParseTxLogEvent(evt); return err },
+	"ParseTxTransferEvent":             func(evt *nostr.Event) error { _, err := ParseTxTransferEvent(evt); return err },
+	"ParseTxApprovalEvent":             func(evt *nostr.Event) error { _, err := ParseTxApprovalEvent(evt); return err },
+	"ParseTxPermitEvent":               func(evt *nostr.Event) error { _, err := ParseTxPermitEvent(evt); return err },
+	"ParseAllowanceStateEvent":         func(evt *nostr.Event) error { _, err := ParseAllowanceStateEvent(evt); return err },
+	"ParseTxLogAttestationEvent":       func(evt *nostr.Event) error { _, err := ParseTxLogAttestationEvent(evt); return err },
+	"ParseBridgeTransferEvent":         func(evt *nostr.Event) error { _, err := ParseBridgeTransferEvent(evt); return err },
+	"ParseAlertEvent":                  func(evt *nostr.Event) error { _, err := ParseAlertEvent(evt); return err },
+	"ParseAlertAcknowledgementEvent":   func(evt *nostr.Event) error { _, err := ParseAlertAcknowledgementEvent(evt); return err },
+	"ParseBalanceSnapshotEvent":        func(evt *nostr.Event) error { _, err := ParseBalanceSnapshotEvent(evt); return err },
+	"ParseCheckpointEvent":             func(evt *nostr.Event) error { _, err := ParseCheckpointEvent(evt); return err },
+	"ParseUserOpSignatureRequestEvent": func(evt *nostr.Event) error { _, err := ParseUserOpSignatureRequestEvent(evt); return err },
+	"ParseUserOpPartialSignatureEvent": func(evt *nostr.Event) error { _, err := ParseUserOpPartialSignatureEvent(evt); return err },
+	"ParseGasEstimateRequestEvent":     func(evt *nostr.Event) error { _, err := ParseGasEstimateRequestEvent(evt); return err },
+	"ParseGasEstimateResponseEvent":    func(evt *nostr.Event) error { _, err := ParseGasEstimateResponseEvent(evt); return err },
+	"ParseGroupEvent":                  func(evt *nostr.Event) error { _, err := ParseGroupEvent(evt); return err },
+	"ParseEditMetadataEvent":           func(evt *nostr.Event) error { _, err := ParseEditMetadataEvent(evt); return err },
+	"ParseAddUserEvent":                func(evt *nostr.Event) error { _, err := ParseAddUserEvent(evt); return err },
+	"ParseRemoveUserEvent":             func(evt *nostr.Event) error { _, err := ParseRemoveUserEvent(evt); return err },
+	"ParseGroupMetadataEvent":          func(evt *nostr.Event) error { _, err := ParseGroupMetadataEvent(evt); return err },
+	"ParseGroupNameEvent":              func(evt *nostr.Event) error { _, err := ParseGroupNameEvent(evt); return err },
+	"ParseGroupAboutEvent":             func(evt *nostr.Event) error { _, err := ParseGroupAboutEvent(evt); return err },
+	"ParseGroupPictureEvent":           func(evt *nostr.Event) error { _, err := ParseGroupPictureEvent(evt); return err },
+	"ParseGroupAdminsEvent":            func(evt *nostr.Event) error { _, err := ParseGroupAdminsEvent(evt); return err },
+	"ParseGroupModeratorsEvent":        func(evt *nostr.Event) error { _, err := ParseGroupModeratorsEvent(evt); return err },
+	"ParseGroupPrivateEvent":           func(evt *nostr.Event) error { _, err := ParseGroupPrivateEvent(evt); return err },
+	"ParseGroupClosedEvent":            func(evt *nostr.Event) error { _, err := ParseGroupClosedEvent(evt); return err },
+	"ParseGroupCreatedEvent":           func(evt *nostr.Event) error { _, err := ParseGroupCreatedEvent(evt); return err },
+	"ParseGroupUpdatedEvent":           func(evt *nostr.Event) error { _, err := ParseGroupUpdatedEvent(evt); return err },
+	"ParseGroupAdminsListEvent":        func(evt *nostr.Event) error { _, err := ParseGroupAdminsListEvent(evt); return err },
+	"ParseGroupMembersEvent":           func(evt *nostr.Event) error { _, err := ParseGroupMembersEvent(evt); return err },
+	"ParseGroupRolesEvent":             func(evt *nostr.Event) error { _, err := ParseGroupRolesEvent(evt); return err },
+	"ParseNativeTransferEvent":         func(evt *nostr.Event) error { _, err := ParseNativeTransferEvent(evt); return err },
+	"ParsePendingTxEvent":              func(evt *nostr.Event) error { _, err := ParsePendingTxEvent(evt); return err },
+	"ParseReconciliationReportEvent":   func(evt *nostr.Event) error { _, err := ParseReconciliationReportEvent(evt); return err },
+	"ParseSessionKeyEvent":             func(evt *nostr.Event) error { _, err := ParseSessionKeyEvent(evt); return err },
+	"ParseTxEvent":                     func(evt *nostr.Event) error { _, err := ParseTxEvent(evt); return err },
+	"ParseUserOpEvent":                 func(evt *nostr.Event) error { _, err := ParseUserOpEvent(evt); return err },
+	"ParseTokenGateEvent":              func(evt *nostr.Event) error { _, err := ParseTokenGateEvent(evt); return err },
+	"ParseTipEvent":                    func(evt *nostr.Event) error { _, err := ParseTipEvent(evt); return err },
+	"ParseTipTransferEvent":            func(evt *nostr.Event) error { _, err := ParseTipTransferEvent(evt); return err },
+	"ParseProposalEvent":               func(evt *nostr.Event) error { _, err := ParseProposalEvent(evt); return err },
+	"ParseVoteEvent":                   func(evt *nostr.Event) error { _, err := ParseVoteEvent(evt); return err },
+	"ParseProposalExecutionEvent":      func(evt *nostr.Event) error { _, err := ParseProposalExecutionEvent(evt); return err },
+	"ParseEscrowProposalEvent":         func(evt *nostr.Event) error { _, err := ParseEscrowProposalEvent(evt); return err },
+	"ParseEscrowFundingEvent":          func(evt *nostr.Event) error { _, err := ParseEscrowFundingEvent(evt); return err },
+	"ParseEscrowSignalEvent":           func(evt *nostr.Event) error { _, err := ParseEscrowSignalEvent(evt); return err },
+	"ParseAddressBookEvent":            func(evt *nostr.Event) error { _, err := ParseAddressBookEvent(evt, ""); return err },
+	"ParseRelayListEvent":              func(evt *nostr.Event) error { _, err := ParseRelayListEvent(evt); return err },
+	"GetSortableAmountFromEvent":       func(evt *nostr.Event) error { _, err := GetSortableAmountFromEvent(evt); return err },
+	"GetGroupIDFromEvent":              func(evt *nostr.Event) error { _, err := GetGroupIDFromEvent(evt); return err },
+	"GetGroupFromEvent":                func(evt *nostr.Event) error { _, err := GetGroupFromEvent(evt); return err },
+	"GetChainIDFromEvent":              func(evt *nostr.Event) error { _, err := GetChainIDFromEvent(evt); return err },
+	"GetTxHashFromEvent":               func(evt *nostr.Event) error { _, err := GetTxHashFromEvent(evt); return err },
+	"GetReplyChainFromEvent":           func(evt *nostr.Event) error { _, _, err := GetReplyChainFromEvent(evt); return err },
+	"GetParticipantsFromEvent":         func(evt *nostr.Event) error { GetParticipantsFromEvent(evt); return nil },
+	"GetEventTypeFromGroupEvent":       func(evt *nostr.Event) error { GetEventTypeFromGroupEvent(evt); return nil },
+	"ValidateContentAgainstSchema":     func(evt *nostr.Event) error { return ValidateContentAgainstSchema(evt) },
+	"ParseEvent":                       func(evt *nostr.Event) error { _, err := ParseEvent(evt); return err },
+}
+
+// parseAll runs every parser on the event, failing the test on panics
+func parseAll(t *testing.T, evt *nostr.Event) {
+	for name, parse := range eventParsers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%s panicked: %v", name, r)
+				}
+			}()
+			parse(evt)
+		}()
+	}
+}
+
+func FuzzParseEvent(f *testing.F) {
+	for _, seed := range []string{
+		`{"kind":111000,"content":"{\"log_data\":{\"hash\":\"0x1\",\"value\":\"1\"},\"event_type\":\"tx_log_created\"}","tags":[["d","0x1"],["amount_sortable","zz"]]}`,
+		`{"kind":9000,"content":"{}","tags":[["h"],["e","x","y"],["p"]]}`,
+		`{"kind":111001,"content":"{\"user_op\":{\"sender\":\"0x\",\"nonce\":\"zz\"}}","tags":[]}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var evt nostr.Event
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return
+		}
+		parseAll(t, &evt)
+	})
+}
+
+func FuzzParseContent(f *testing.F) {
+	f.Add(KindTxLog, `{"log_data":{"value":1e400}}`)
+	f.Add(EventUserOpKind, `{"user_op":{"callGasLimit":"0x"}}`)
+	f.Add(KindGroupCreate, `{"name":"\ud800"}`)
+
+	f.Fuzz(func(t *testing.T, kind int, content string) {
+		parseAll(t, &nostr.Event{Kind: kind, Content: content, Tags: nostr.Tags{}})
+	})
+}
+
+func TestParsersRejectHostileEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		evt  *nostr.Event
+	}{
+		{"huge content", &nostr.Event{Kind: KindTxLog, Content: `{"a":"` + strings.Repeat("x", MaxContentSize) + `"}`}},
+		{"deep nesting", &nostr.Event{Kind: KindTxLog, Content: strings.Repeat("[", 10000) + strings.Repeat("]", 10000)}},
+		{"invalid UTF-8", &nostr.Event{Kind: KindTxLog, Content: "{\"log_data\":{\"hash\":\"\xff\xfe\"}}"}},
+		{"too many tags", &nostr.Event{Kind: KindTxLog, Content: `{}`, Tags: make(nostr.Tags, MaxTags+1)}},
+		{"huge tag", &nostr.Event{Kind: KindTxLog, Content: `{}`, Tags: nostr.Tags{{"t", strings.Repeat("x", MaxTagSize)}}}},
+	}
+
+	for _, tt := range tests {
+		parseAll(t, tt.evt)
+
+		if _, err := ParseTxLogEvent(tt.evt); !errors.Is(err, ErrMalformedEvent) {
+			t.Errorf("%s: Expected ErrMalformedEvent, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for gas estimation
+const (
+	KindGasEstimateRequest  = 111009
+	KindGasEstimateResponse = 111010
+
+	EventTypeGasEstimateRequested EventTypeGasEstimate = "gas_estimate_requested"
+	EventTypeGasEstimated         EventTypeGasEstimate = "gas_estimated"
+)
+
+type EventTypeGasEstimate string
+
+// GasEstimateRequestEvent represents a Nostr event asking a remote service to estimate the gas
+// of an unsigned user operation
+type GasEstimateRequestEvent struct {
+	UserOpData neth.UserOp          `json:"user_op_data"`
+	UserOpHash string               `json:"user_op_hash"`
+	ChainID    string               `json:"chain_id"`
+	EntryPoint *common.Address      `json:"entry_point,omitempty"`
+	EventType  EventTypeGasEstimate `json:"event_type"`
+	Tags       []string             `json:"tags,omitempty"`
+}
+
+// GasEstimateResponseEvent represents a Nostr event with the gas estimated for a user operation,
+// the validity window is covered by the signature of the event
+type GasEstimateResponseEvent struct {
+	UserOpHash string               `json:"user_op_hash"`
+	ChainID    string               `json:"chain_id"`
+	Estimate   neth.GasEstimate     `json:"estimate"`
+	EventType  EventTypeGasEstimate `json:"event_type"`
+	Tags       []string             `json:"tags,omitempty"`
+}
+
+// CreateGasEstimateRequestEvent creates a new Nostr event requesting a gas estimate, the estimator
+// is the public key of the service expected to answer and may be empty
+func CreateGasEstimateRequestEvent(chainID *big.Int, entryPoint *common.Address, userOp neth.UserOp, estimator string) (*nostr.Event, error) {
+	// Create the event data, the user op is not signed yet and its gas fields may be unset
+	unsigned := userOp
+	unsigned.Signature = nil
+	for _, field := range []**big.Int{
+		&unsigned.Nonce,
+		&unsigned.CallGasLimit,
+		&unsigned.VerificationGasLimit,
+		&unsigned.PreVerificationGas,
+		&unsigned.MaxFeePerGas,
+		&unsigned.MaxPriorityFeePerGas,
+	} {
+		if *field == nil {
+			*field = new(big.Int)
+		}
+	}
+
+	userOpHash := unsigned.GetHash(chainID)
+
+	eventData := GasEstimateRequestEvent{
+		UserOpData: unsigned,
+		UserOpHash: userOpHash,
+		ChainID:    chainID.String(),
+		EntryPoint: entryPoint,
+		EventType:  EventTypeGasEstimateRequested,
+		Tags:       []string{"user_op", "gas_estimate_request", "evm", chainID.String(), "account_abstraction"},
+	}
+
+	// Marshal the event data using the custom marshaling of the user op
+	content, err := json.Marshal(&eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindGasEstimateRequest), // Custom kind for gas estimate requests
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", userOpHash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("user_op"))                             // Type
+	evt.Tags = append(evt.Tags, typeTag("gas_estimate_request"))                // Category
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})                     // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction"))                 // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(EventTypeGasEstimateRequested))) // Event type
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", chainID.String()}) // Chain ID
+
+	// Entry point tag if present
+	if entryPoint != nil {
+		evt.Tags = append(evt.Tags, []string{"entry_point", entryPoint.Hex()})
+	}
+
+	// Estimator tag if present
+	if estimator != "" {
+		evt.Tags = append(evt.Tags, []string{"estimator", estimator})
+	}
+
+	// Sender address tag
+	evt.Tags = append(evt.Tags, []string{"p", userOp.Sender.String()}) // Sender address
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a gas estimation request for a user operation on chain %s", chainID.String())
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseGasEstimateRequestEvent parses a Nostr event back into a GasEstimateRequestEvent
+func ParseGasEstimateRequestEvent(evt *nostr.Event) (*GasEstimateRequestEvent, error) {
+	var requestEvent GasEstimateRequestEvent
+	err := unmarshalContent(evt, &requestEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &requestEvent, nil
+}
+
+// CreateGasEstimateResponseEvent creates a new Nostr event answering a gas estimate request
+func CreateGasEstimateResponseEvent(request *nostr.Event, estimate neth.GasEstimate, opts ...ReferenceOption) (*nostr.Event, error) {
+	requestEvent, err := ParseGasEstimateRequestEvent(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the event data
+	eventData := GasEstimateResponseEvent{
+		UserOpHash: requestEvent.UserOpHash,
+		ChainID:    requestEvent.ChainID,
+		Estimate:   estimate,
+		EventType:  EventTypeGasEstimated,
+		Tags:       []string{"user_op", "gas_estimate", "evm", requestEvent.ChainID, "account_abstraction"},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindGasEstimateResponse), // Custom kind for gas estimate responses
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", requestEvent.UserOpHash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("user_op"))                     // Type
+	evt.Tags = append(evt.Tags, typeTag("gas_estimate"))                // Category
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})             // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction"))         // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(EventTypeGasEstimated))) // Event type
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", requestEvent.ChainID}) // Chain ID
+
+	// Reference to the request and its author
+	evt.Tags = append(evt.Tags, hintedTag("e", request.ID, newReferenceOptions(opts).eventRelay(request)))
+	if request.PubKey != "" {
+		evt.Tags = append(evt.Tags, []string{"requester", request.PubKey})
+	}
+
+	// Sender address tag
+	evt.Tags = append(evt.Tags, []string{"p", requestEvent.UserOpData.Sender.String()}) // Sender address
+
+	// Validity tags
+	if !estimate.ValidAfter.IsZero() {
+		evt.Tags = append(evt.Tags, []string{"valid_after", strconv.FormatInt(estimate.ValidAfter.Unix(), 10)})
+	}
+	if !estimate.ValidUntil.IsZero() {
+		evt.Tags = append(evt.Tags, []string{"valid_until", strconv.FormatInt(estimate.ValidUntil.Unix(), 10)})
+		evt.Tags = append(evt.Tags, []string{"expiration", strconv.FormatInt(estimate.ValidUntil.Unix(), 10)}) // NIP-40
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a gas estimate for user operation %s on chain %s", requestEvent.UserOpHash, requestEvent.ChainID)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseGasEstimateResponseEvent parses a Nostr event back into a GasEstimateResponseEvent
+func ParseGasEstimateResponseEvent(evt *nostr.Event) (*GasEstimateResponseEvent, error) {
+	var responseEvent GasEstimateResponseEvent
+	err := unmarshalContent(evt, &responseEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &responseEvent, nil
+}
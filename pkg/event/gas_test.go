@@ -0,0 +1,62 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestGasEstimateRoundTrip(t *testing.T) {
+	chainID := big.NewInt(100)
+	userOp := neth.UserOp{
+		Sender:   common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:    big.NewInt(7),
+		CallData: []byte{0x01, 0x02},
+	}
+
+	request, err := CreateGasEstimateRequestEvent(chainID, nil, userOp, "")
+	if err != nil {
+		t.Fatalf("Failed to create gas estimate request: %v", err)
+	}
+
+	requestEvent, err := ParseGasEstimateRequestEvent(request)
+	if err != nil {
+		t.Fatalf("Failed to parse gas estimate request: %v", err)
+	}
+
+	validUntil := time.Unix(1700000600, 0)
+	response, err := CreateGasEstimateResponseEvent(request, neth.GasEstimate{
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(50000)),
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(100000)),
+		PreVerificationGas:   (*hexutil.Big)(big.NewInt(21000)),
+		ValidUntil:           validUntil,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create gas estimate response: %v", err)
+	}
+
+	responseEvent, err := ParseGasEstimateResponseEvent(response)
+	if err != nil {
+		t.Fatalf("Failed to parse gas estimate response: %v", err)
+	}
+
+	if responseEvent.UserOpHash != requestEvent.UserOpHash {
+		t.Errorf("Expected user op hash %s, got %s", requestEvent.UserOpHash, responseEvent.UserOpHash)
+	}
+
+	if !responseEvent.Estimate.IsValid(validUntil.Add(-time.Second)) || responseEvent.Estimate.IsValid(validUntil) {
+		t.Errorf("Expected estimate to be valid until %s", validUntil)
+	}
+
+	estimated := responseEvent.Estimate.Apply(requestEvent.UserOpData)
+	if estimated.CallGasLimit.Int64() != 50000 {
+		t.Errorf("Expected call gas limit 50000, got %s", estimated.CallGasLimit)
+	}
+	if estimated.Nonce.Int64() != 7 {
+		t.Errorf("Expected nonce 7, got %s", estimated.Nonce)
+	}
+}
@@ -0,0 +1,142 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindGroupTokenGate = 31103 // Addressable, one event per group
+)
+
+// GateMatch tells how many rules of a token gate must be satisfied
+type GateMatch string
+
+const (
+	GateMatchAny GateMatch = "any" // One rule is enough, the default
+	GateMatchAll GateMatch = "all" // Every rule must be satisfied
+)
+
+// ErrNotEligible is returned when an address does not satisfy a token gate
+var ErrNotEligible = errors.New("address is not eligible")
+
+// TokenGateEvent represents the token gating rules a group admin declared for a group
+type TokenGateEvent struct {
+	GroupID string          `json:"group_id"`
+	Match   GateMatch       `json:"match"`
+	Rules   []neth.GateRule `json:"rules"`
+}
+
+// CreateTokenGateEvent creates the token gate of a group, to be signed by an admin of the group.
+// Join requests are checked against it with VerifyGateEligibility.
+func CreateTokenGateEvent(groupID string, match GateMatch, rules ...neth.GateRule) (*nostr.Event, error) {
+	if err := ValidateGroupID(groupID); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("token gate needs at least one rule")
+	}
+	if match == "" {
+		match = GateMatchAny
+	}
+	if match != GateMatchAny && match != GateMatchAll {
+		return nil, fmt.Errorf("unknown gate match: %s", match)
+	}
+
+	// Create the event data
+	eventData := TokenGateEvent{
+		GroupID: groupID,
+		Match:   match,
+		Rules:   rules,
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal token gate: %w", err)
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be set by the client
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindGroupTokenGate),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Addressable identifier, one per group
+	evt.Tags = append(evt.Tags, []string{"d", groupID})
+
+	// Add group identifier tag (h tag with group ID)
+	evt.Tags = append(evt.Tags, []string{"h", groupID})
+
+	// Add token gate type tags
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("token_gate"))
+
+	// Chain and contract tags
+	for _, rule := range rules {
+		evt.Tags = appendUniqueTags(evt.Tags, []string{"layer", rule.ChainID})
+		evt.Tags = appendUniqueTags(evt.Tags, typeTag(rule.Contract.Hex()))
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is the token gate of group %s, with %d rules", groupID, len(rules))
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTokenGateEvent parses the token gate of a group
+func ParseTokenGateEvent(evt *nostr.Event) (*TokenGateEvent, error) {
+	if DefaultKind(evt.Kind) != KindGroupTokenGate {
+		return nil, fmt.Errorf("event is not a token gate event (kind %d)", evt.Kind)
+	}
+
+	var gate TokenGateEvent
+	if err := unmarshalContent(evt, &gate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token gate event: %w", err)
+	}
+
+	return &gate, nil
+}
+
+// VerifyGateEligibility checks an address against the rules of a token gate, e.g. the address
+// of a user asking to join the group. The holdings are read with the reader of the chain of each
+// rule, readers are keyed by chain ID. ErrNotEligible is returned when the gate is not passed.
+func VerifyGateEligibility(ctx context.Context, gate *TokenGateEvent, readers map[string]neth.TokenReader, address common.Address) error {
+	if len(gate.Rules) == 0 {
+		return nil
+	}
+
+	for i, rule := range gate.Rules {
+		reader, ok := readers[rule.ChainID]
+		if !ok {
+			return fmt.Errorf("no token reader for chain %s", rule.ChainID)
+		}
+
+		passed, err := rule.Check(ctx, reader, address)
+		if err != nil {
+			return fmt.Errorf("failed to check rule %d of group %s: %w", i, gate.GroupID, err)
+		}
+
+		if passed && gate.Match != GateMatchAll {
+			return nil
+		}
+		if !passed && gate.Match == GateMatchAll {
+			return fmt.Errorf("%w: %s fails rule %d of group %s", ErrNotEligible, address.Hex(), i, gate.GroupID)
+		}
+	}
+
+	if gate.Match == GateMatchAll {
+		return nil
+	}
+	return fmt.Errorf("%w: %s fails every rule of group %s", ErrNotEligible, address.Hex(), gate.GroupID)
+}
@@ -0,0 +1,71 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// staticTokenReader reads holdings from maps
+type staticTokenReader struct {
+	balances map[common.Address]*big.Int
+}
+
+func (r staticTokenReader) BalanceOf(ctx context.Context, contract, owner common.Address) (*big.Int, error) {
+	if balance, ok := r.balances[owner]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (r staticTokenReader) OwnerOf(ctx context.Context, contract common.Address, tokenID *big.Int) (common.Address, error) {
+	return common.Address{}, nil
+}
+
+func TestTokenGate(t *testing.T) {
+	holder := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	stranger := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d")
+
+	evt, err := CreateTokenGateEvent("community", GateMatchAny,
+		neth.GateRule{Type: neth.GateRuleTokenBalance, ChainID: "100", Contract: token, MinBalance: big.NewInt(10)},
+		neth.GateRule{Type: neth.GateRuleNFTOwnership, ChainID: "1", Contract: token},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create token gate: %v", err)
+	}
+	if evt.Tags.GetD() != "community" || !IsGroupEvent(evt) {
+		t.Errorf("Expected a group event addressed by the group, got %v", evt.Tags)
+	}
+
+	gate, err := ParseTokenGateEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse token gate: %v", err)
+	}
+
+	readers := map[string]neth.TokenReader{
+		"100": staticTokenReader{balances: map[common.Address]*big.Int{holder: big.NewInt(10)}},
+		"1":   staticTokenReader{},
+	}
+
+	if err := VerifyGateEligibility(context.Background(), gate, readers, holder); err != nil {
+		t.Errorf("Expected the holder to be eligible, got %v", err)
+	}
+	if err := VerifyGateEligibility(context.Background(), gate, readers, stranger); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("Expected ErrNotEligible, got %v", err)
+	}
+
+	gate.Match = GateMatchAll
+	if err := VerifyGateEligibility(context.Background(), gate, readers, holder); !errors.Is(err, ErrNotEligible) {
+		t.Errorf("Expected ErrNotEligible without the NFT, got %v", err)
+	}
+
+	delete(readers, "1")
+	if err := VerifyGateEligibility(context.Background(), gate, readers, holder); err == nil || errors.Is(err, ErrNotEligible) {
+		t.Errorf("Expected an error for a missing reader, got %v", err)
+	}
+}
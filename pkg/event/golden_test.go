@@ -0,0 +1,158 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// goldenPubKey is the author the golden events are hashed with, the public key of the secret
+// key 1
+const goldenPubKey = "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+// goldenCreatedAt replaces the creation time of constructors that use the current time
+const goldenCreatedAt = nostr.Timestamp(1700000000)
+
+// goldenEvents are the representative events whose serialization is locked down
+var goldenEvents = map[string]func() (*nostr.Event, error){
+	"tx_log": func() (*nostr.Event, error) {
+		return CreateTxLogEvent(goldenLog(), WithSortableAmount())
+	},
+	"tx_log_included": func() (*nostr.Event, error) {
+		return CreateTxLogEvent(goldenLog(), WithBlockNumber(19000000))
+	},
+	"tx_log_updated": func() (*nostr.Event, error) {
+		evt, err := CreateTxLogEvent(goldenLog(), WithBlockNumber(19000000))
+		if err != nil {
+			return nil, err
+		}
+		evt.PubKey = goldenPubKey
+		evt.ID = evt.GetID()
+		return UpdateTxLogStatus(evt, TxLogStatusFinalized)
+	},
+	"tx_transfer": func() (*nostr.Event, error) {
+		return CreateTxTransferEvent(goldenLog())
+	},
+	"tx_transfer_legacy": func() (*nostr.Event, error) {
+		return CreateTxTransferEvent(goldenLog(), WithTransferKind(KindTxTransferLegacy))
+	},
+	"user_op": func() (*nostr.Event, error) {
+		entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+		paymaster := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+		return CreateUserOpEvent(big.NewInt(100), &paymaster, &entryPoint, nil, nil, 0, goldenUserOp(), EventTypeUserOpRequested)
+	},
+	"group_create": func() (*nostr.Event, error) {
+		return CreateGroupEvent("community", "Community", "A test group", "https://example.com/picture.png",
+			[]string{goldenPubKey}, nil, false, true)
+	},
+	"group_add_user": func() (*nostr.Event, error) {
+		return CreateAddUserEvent("community", goldenPubKey, "member")
+	},
+	"group_metadata": func() (*nostr.Event, error) {
+		return CreateGroupMetadataEvent("community", GroupMetadata{
+			Name:  "Community",
+			About: "A test group",
+		})
+	},
+	"group_metadata_nip29": func() (*nostr.Event, error) {
+		SetGroupKindMode(GroupKindsNIP29)
+		defer SetGroupKindMode(GroupKindsLegacy)
+
+		return CreateGroupMetadataEvent("community", GroupMetadata{
+			Name:   "Community",
+			About:  "A test group",
+			Closed: true,
+		})
+	},
+	"group_admins_list": func() (*nostr.Event, error) {
+		return CreateGroupAdminsListEvent("community", []GroupAdmin{{PubKey: goldenPubKey, Roles: []string{"ceo"}}})
+	},
+	"group_message": func() (*nostr.Event, error) {
+		group := "community"
+		return CreateMessageEvent("gm", &group)
+	},
+}
+
+func goldenLog() neth.Log {
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"1000000000000000000","memo":"rent"}`)
+	return neth.Log{
+		Hash:      "0x8d2a5c34c5b6b5cdf1a8a1f2a9e3d3fbf6d0e6c1b8c5d2e9a7f4b1c6d3e8a5f2",
+		TxHash:    "0x3e5c7f1a9b2d4c6e8f0a1b3c5d7e9f1a2b4c6d8e0f1a3b5c7d9e1f2a4b6c8d0e",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		UpdatedAt: time.Unix(1700000000, 0).UTC(),
+		Nonce:     7,
+		Sender:    "0x1111111111111111111111111111111111111111",
+		To:        "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d",
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+func goldenUserOp() neth.UserOp {
+	return neth.UserOp{
+		Sender:               common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:                big.NewInt(1),
+		InitCode:             []byte{},
+		CallData:             []byte{0xb6, 0x1d, 0x27, 0xf6},
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000),
+		PaymasterAndData:     []byte{},
+		Signature:            []byte{0x01},
+	}
+}
+
+// TestGoldenEvents checks that the representative events serialize to the exact content, tags
+// and ID of their golden files in testdata/golden, run with UPDATE_GOLDEN=1 to regenerate them
+// after an intentional format change
+func TestGoldenEvents(t *testing.T) {
+	update := os.Getenv("UPDATE_GOLDEN") != ""
+
+	defer func(clock func() time.Time) { timeNow = clock }(timeNow)
+	timeNow = func() time.Time { return time.Unix(int64(goldenCreatedAt), 0) }
+
+	for name, create := range goldenEvents {
+		evt, err := create()
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+
+		evt.PubKey = goldenPubKey
+		evt.ID = evt.GetID()
+
+		serialized, err := json.MarshalIndent(evt, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to serialize %s: %v", name, err)
+		}
+		serialized = append(serialized, '\n')
+
+		path := filepath.Join("testdata", "golden", name+".json")
+		if update {
+			if err := os.WriteFile(path, serialized, 0o644); err != nil {
+				t.Fatalf("Failed to write golden file %s: %v", name, err)
+			}
+			continue
+		}
+
+		golden, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read golden file %s: %v", name, err)
+		}
+
+		if !bytes.Equal(serialized, golden) {
+			t.Errorf("Event %s does not match %s, run with UPDATE_GOLDEN=1 if the change is intentional\ngot:\n%s", name, path, serialized)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindProposal = 111015 // Governance proposal of a group
+	KindVote     = 111016 // Vote on a proposal
+
+	KindProposalExecution = 111017 // Links a passed proposal to the transaction executing it
+)
+
+// VotingStrategy tells how the votes of a proposal are weighted
+type VotingStrategy string
+
+const (
+	VotingStrategySingle       VotingStrategy = "single"        // One vote per member
+	VotingStrategyTokenBalance VotingStrategy = "token_balance" // Votes weighted by the token balance at the snapshot block
+)
+
+// ProposalEvent represents a snapshot-style governance proposal of a group
+type ProposalEvent struct {
+	GroupID       string         `json:"group_id"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description,omitempty"`
+	Choices       []string       `json:"choices"`
+	Start         int64          `json:"start"` // Unix time the voting opens
+	End           int64          `json:"end"`   // Unix time the voting closes
+	Strategy      VotingStrategy `json:"strategy"`
+	ChainID       string         `json:"chain_id,omitempty"`       // Token balance strategy only
+	Token         string         `json:"token,omitempty"`          // ERC-20 contract weighting the votes
+	SnapshotBlock uint64         `json:"snapshot_block,omitempty"` // Block the balances are read at
+	BalanceSlot   uint64         `json:"balance_slot,omitempty"`   // Storage slot of the balances mapping of the token
+	Quorum        *big.Int       `json:"quorum,omitempty"`         // Minimum total weight of the votes
+	Payload       []neth.Call    `json:"payload,omitempty"`        // Calls executed on-chain when the first choice wins
+}
+
+// VoteEvent represents a vote on a proposal, token balance votes carry the address of the voter
+// signed with VoteAddressMessage and a proof of its balance at the snapshot block
+type VoteEvent struct {
+	ProposalID string             `json:"proposal_id"`
+	Choice     int                `json:"choice"` // Index in the choices of the proposal
+	Address    string             `json:"address,omitempty"`
+	Signature  hexutil.Bytes      `json:"signature,omitempty"` // personal_sign of VoteAddressMessage by the address
+	Balance    *big.Int           `json:"balance,omitempty"`   // Claimed balance, informative only
+	Proof      *neth.BalanceProof `json:"proof,omitempty"`
+}
+
+// RejectedVote is a vote left out of a tally
+type RejectedVote struct {
+	EventID string `json:"event_id"`
+	Reason  string `json:"reason"`
+}
+
+// TallyResult is the outcome of the votes of a proposal
+type TallyResult struct {
+	Totals        []*big.Int     `json:"totals"` // Weight per choice
+	Votes         int            `json:"votes"`  // Number of counted votes
+	Rejected      []RejectedVote `json:"rejected,omitempty"`
+	Winner        int            `json:"winner"` // Index of the winning choice, -1 without votes or on a tie
+	QuorumReached bool           `json:"quorum_reached"`
+}
+
+// Passed checks if a proposal passed, the first choice approves its payload
+func (r *TallyResult) Passed() bool {
+	return r.Winner == 0 && r.QuorumReached
+}
+
+// ProposalExecutionEvent represents the on-chain execution of a passed proposal, by a
+// transaction or a user operation
+type ProposalExecutionEvent struct {
+	ProposalID string `json:"proposal_id"`
+	GroupID    string `json:"group_id"`
+	ChainID    string `json:"chain_id"`
+	TxHash     string `json:"tx_hash,omitempty"`
+	UserOpHash string `json:"user_op_hash,omitempty"`
+}
+
+// TallyOption configures how the votes of a proposal are counted
+type TallyOption func(*tallyOptions)
+
+type tallyOptions struct {
+	snapshotBlockHash *common.Hash
+}
+
+// WithSnapshotBlockHash verifies the balance proofs of the votes against the hash of the
+// snapshot block, votes without a valid proof are rejected. Token balance proposals cannot be
+// tallied without it.
+func WithSnapshotBlockHash(hash common.Hash) TallyOption {
+	return func(o *tallyOptions) {
+		o.snapshotBlockHash = &hash
+	}
+}
+
+// VoteAddressMessage returns the message an address signs with personal_sign (EIP-191) to vote
+// with a public key on a proposal, so that nobody can vote with the balance of someone else
+func VoteAddressMessage(proposalID, pubkey string) []byte {
+	return []byte(fmt.Sprintf("Vote on proposal %s with Nostr public key %s", proposalID, pubkey))
+}
+
+// CreateProposalEvent creates a governance proposal in a group (kind 111015)
+func CreateProposalEvent(proposal ProposalEvent) (*nostr.Event, error) {
+	if err := ValidateGroupID(proposal.GroupID); err != nil {
+		return nil, err
+	}
+	if proposal.Title == "" {
+		return nil, fmt.Errorf("proposal title cannot be empty")
+	}
+	if len(proposal.Choices) < 2 {
+		return nil, fmt.Errorf("proposal needs at least two choices")
+	}
+	if proposal.End <= proposal.Start {
+		return nil, fmt.Errorf("proposal must end after it starts")
+	}
+	if proposal.Strategy == "" {
+		proposal.Strategy = VotingStrategySingle
+	}
+
+	switch proposal.Strategy {
+	case VotingStrategySingle:
+	case VotingStrategyTokenBalance:
+		if proposal.ChainID == "" || !isEthereumAddress(proposal.Token) {
+			return nil, fmt.Errorf("token balance proposals need a chain ID and a token address")
+		}
+	default:
+		return nil, fmt.Errorf("unknown voting strategy: %s", proposal.Strategy)
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proposal: %w", err)
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be set by the client
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindProposal),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add group identifier tag (h tag with group ID)
+	evt.Tags = append(evt.Tags, []string{"h", proposal.GroupID})
+
+	// Add proposal type tags
+	evt.Tags = append(evt.Tags, typeTag("governance"))
+	evt.Tags = append(evt.Tags, typeTag("proposal"))
+
+	// Token tags
+	if proposal.Strategy == VotingStrategyTokenBalance {
+		evt.Tags = append(evt.Tags, []string{"network", "evm"})          // Blockchain
+		evt.Tags = append(evt.Tags, []string{"layer", proposal.ChainID}) // Chain ID
+		evt.Tags = append(evt.Tags, []string{"token", proposal.Token})   // Token contract
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a proposal of group %s: %s", proposal.GroupID, proposal.Title)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseProposalEvent parses a governance proposal
+func ParseProposalEvent(evt *nostr.Event) (*ProposalEvent, error) {
+	if DefaultKind(evt.Kind) != KindProposal {
+		return nil, fmt.Errorf("event is not a proposal event (kind %d)", evt.Kind)
+	}
+
+	var proposal ProposalEvent
+	if err := unmarshalContent(evt, &proposal); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal event: %w", err)
+	}
+
+	return &proposal, nil
+}
+
+// CreateVoteEvent creates a vote on a proposal (kind 111016). Votes on token balance proposals
+// need the address of the voter, its signature of VoteAddressMessage and the proof of its balance.
+func CreateVoteEvent(proposal *nostr.Event, vote VoteEvent, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if proposal == nil || proposal.ID == "" {
+		return nil, fmt.Errorf("voted proposal must have an ID")
+	}
+
+	proposalData, err := ParseProposalEvent(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote.Choice < 0 || vote.Choice >= len(proposalData.Choices) {
+		return nil, fmt.Errorf("choice %d is not one of the %d choices of the proposal", vote.Choice, len(proposalData.Choices))
+	}
+	if proposalData.Strategy == VotingStrategyTokenBalance {
+		if !isEthereumAddress(vote.Address) {
+			return nil, fmt.Errorf("token balance votes need the address of the voter")
+		}
+		if len(vote.Signature) == 0 {
+			return nil, fmt.Errorf("token balance votes need the signature of the address")
+		}
+		if vote.Proof == nil {
+			return nil, fmt.Errorf("token balance votes need a balance proof")
+		}
+	}
+
+	vote.ProposalID = proposal.ID
+
+	// Marshal the event data
+	content, err := json.Marshal(vote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal vote: %w", err)
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be set by the client
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindVote),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Reference to the proposal
+	evt.Tags = append(evt.Tags, []string{"e", proposal.ID, references.eventRelay(proposal), "root"})
+
+	// Add group identifier tag (h tag with group ID)
+	evt.Tags = append(evt.Tags, []string{"h", proposalData.GroupID})
+
+	// Add vote type tags
+	evt.Tags = append(evt.Tags, typeTag("governance"))
+	evt.Tags = append(evt.Tags, typeTag("vote"))
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a vote for %s on a proposal of group %s", proposalData.Choices[vote.Choice], proposalData.GroupID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseVoteEvent parses a vote on a proposal
+func ParseVoteEvent(evt *nostr.Event) (*VoteEvent, error) {
+	if DefaultKind(evt.Kind) != KindVote {
+		return nil, fmt.Errorf("event is not a vote event (kind %d)", evt.Kind)
+	}
+
+	var vote VoteEvent
+	if err := unmarshalContent(evt, &vote); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vote event: %w", err)
+	}
+
+	return &vote, nil
+}
+
+// Tally counts the votes of a proposal. Votes cast outside of the voting window, on another
+// proposal or for an unknown choice are rejected. Only the latest vote of each voter counts,
+// voters are public keys, or addresses for token balance proposals. Token balance votes count
+// the balance proven at the snapshot block, by an address that signed the vote of the public key.
+func Tally(proposal *nostr.Event, votes []*nostr.Event, opts ...TallyOption) (*TallyResult, error) {
+	o := &tallyOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	proposalData, err := ParseProposalEvent(proposal)
+	if err != nil {
+		return nil, err
+	}
+	if proposalData.Strategy == VotingStrategyTokenBalance && o.snapshotBlockHash == nil {
+		return nil, fmt.Errorf("token balance proposals need the snapshot block hash to verify the balances")
+	}
+
+	type countedVote struct {
+		createdAt nostr.Timestamp
+		choice    int
+		weight    *big.Int
+	}
+
+	counted := make(map[string]countedVote)
+	result := &TallyResult{Winner: -1}
+
+	reject := func(evt *nostr.Event, reason string) {
+		result.Rejected = append(result.Rejected, RejectedVote{EventID: evt.ID, Reason: reason})
+	}
+
+	for _, evt := range votes {
+		vote, err := ParseVoteEvent(evt)
+		if err != nil {
+			reject(evt, err.Error())
+			continue
+		}
+		if vote.ProposalID != proposal.ID {
+			reject(evt, "vote is on another proposal")
+			continue
+		}
+		if int64(evt.CreatedAt) < proposalData.Start || int64(evt.CreatedAt) > proposalData.End {
+			reject(evt, "vote is outside of the voting window")
+			continue
+		}
+		if vote.Choice < 0 || vote.Choice >= len(proposalData.Choices) {
+			reject(evt, fmt.Sprintf("unknown choice %d", vote.Choice))
+			continue
+		}
+
+		voter := evt.PubKey
+		weight := big.NewInt(1)
+		if proposalData.Strategy == VotingStrategyTokenBalance {
+			voter = strings.ToLower(vote.Address)
+			weight, err = voteWeight(proposal.ID, proposalData, evt.PubKey, vote, o)
+			if err != nil {
+				reject(evt, err.Error())
+				continue
+			}
+		}
+
+		if previous, ok := counted[voter]; ok && previous.createdAt > evt.CreatedAt {
+			continue
+		}
+		counted[voter] = countedVote{createdAt: evt.CreatedAt, choice: vote.Choice, weight: weight}
+	}
+
+	result.Totals = make([]*big.Int, len(proposalData.Choices))
+	for i := range result.Totals {
+		result.Totals[i] = new(big.Int)
+	}
+
+	total := new(big.Int)
+	for _, vote := range counted {
+		result.Totals[vote.choice].Add(result.Totals[vote.choice], vote.weight)
+		total.Add(total, vote.weight)
+	}
+	result.Votes = len(counted)
+
+	// The winner is the choice with the most weight, ties have no winner
+	best := new(big.Int)
+	for i, sum := range result.Totals {
+		switch sum.Cmp(best) {
+		case 1:
+			best = sum
+			result.Winner = i
+		case 0:
+			result.Winner = -1
+		}
+	}
+
+	result.QuorumReached = proposalData.Quorum == nil || total.Cmp(proposalData.Quorum) >= 0
+
+	return result, nil
+}
+
+// voteWeight returns the weight of a token balance vote, the balance proven at the snapshot
+// block of an address owned by the voter
+func voteWeight(proposalID string, proposal *ProposalEvent, pubkey string, vote *VoteEvent, o *tallyOptions) (*big.Int, error) {
+	if !isEthereumAddress(vote.Address) {
+		return nil, fmt.Errorf("vote has no address")
+	}
+
+	signer, err := neth.RecoverMessageAddress(VoteAddressMessage(proposalID, pubkey), vote.Signature)
+	if err != nil || signer != common.HexToAddress(vote.Address) {
+		return nil, fmt.Errorf("address %s did not sign the vote", vote.Address)
+	}
+
+	if vote.Proof == nil {
+		return nil, fmt.Errorf("vote has no balance proof")
+	}
+	if vote.Proof.Holder != common.HexToAddress(vote.Address) {
+		return nil, fmt.Errorf("balance proof is of %s, the vote of %s", vote.Proof.Holder.Hex(), vote.Address)
+	}
+	if vote.Proof.Token != common.HexToAddress(proposal.Token) {
+		return nil, fmt.Errorf("balance proof is of token %s, the proposal of %s", vote.Proof.Token.Hex(), proposal.Token)
+	}
+	if vote.Proof.Slot != proposal.BalanceSlot {
+		return nil, fmt.Errorf("balance proof is of slot %d, the proposal of %d", vote.Proof.Slot, proposal.BalanceSlot)
+	}
+
+	balance, err := vote.Proof.Verify(*o.snapshotBlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance proof: %w", err)
+	}
+	if balance.Sign() <= 0 {
+		return nil, fmt.Errorf("voter has no balance at the snapshot block")
+	}
+
+	return balance, nil
+}
+
+// CreateProposalExecutionEvent links a passed proposal to the transaction or user operation
+// executing its payload (kind 111017), the tally of the proposal must show it passed
+func CreateProposalExecutionEvent(proposal *nostr.Event, result *TallyResult, execution ProposalExecutionEvent, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if proposal == nil || proposal.ID == "" {
+		return nil, fmt.Errorf("executed proposal must have an ID")
+	}
+
+	proposalData, err := ParseProposalEvent(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(proposalData.Payload) == 0 {
+		return nil, fmt.Errorf("proposal has no payload to execute")
+	}
+	if result == nil || !result.Passed() {
+		return nil, fmt.Errorf("proposal did not pass")
+	}
+	if execution.ChainID == "" {
+		return nil, fmt.Errorf("execution chain ID cannot be empty")
+	}
+	if execution.TxHash == "" && execution.UserOpHash == "" {
+		return nil, fmt.Errorf("execution needs a transaction or user operation hash")
+	}
+
+	execution.ProposalID = proposal.ID
+	execution.GroupID = proposalData.GroupID
+
+	// Marshal the event data
+	content, err := json.Marshal(execution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal proposal execution: %w", err)
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be set by the client
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindProposalExecution),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Reference to the proposal
+	evt.Tags = append(evt.Tags, []string{"e", proposal.ID, references.eventRelay(proposal), "root"})
+
+	// Add group identifier tag (h tag with group ID)
+	evt.Tags = append(evt.Tags, []string{"h", proposalData.GroupID})
+
+	// Add execution type tags
+	evt.Tags = append(evt.Tags, typeTag("governance"))
+	evt.Tags = append(evt.Tags, typeTag("execution"))
+
+	// Chain-specific tags
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})           // Blockchain
+	evt.Tags = append(evt.Tags, []string{"layer", execution.ChainID}) // Chain ID
+	if execution.TxHash != "" {
+		evt.Tags = append(evt.Tags, []string{"r", execution.TxHash}) // Transaction hash
+	}
+	if execution.UserOpHash != "" {
+		evt.Tags = append(evt.Tags, []string{"r", execution.UserOpHash}) // User operation hash
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This links a proposal of group %s to its execution on chain %s", proposalData.GroupID, execution.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseProposalExecutionEvent parses the execution of a proposal
+func ParseProposalExecutionEvent(evt *nostr.Event) (*ProposalExecutionEvent, error) {
+	if DefaultKind(evt.Kind) != KindProposalExecution {
+		return nil, fmt.Errorf("event is not a proposal execution event (kind %d)", evt.Kind)
+	}
+
+	var execution ProposalExecutionEvent
+	if err := unmarshalContent(evt, &execution); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal proposal execution event: %w", err)
+	}
+
+	return &execution, nil
+}
+
+// VerifyProposalExecution checks that an execution links the proposal and that the executed
+// calls match the payload of the proposal, in order. The calls are read from the chain by the
+// caller: the target, value and input of a transaction, or the calls decoded from the calldata of
+// a smart account with neth.DecodeExecuteCalls.
+func VerifyProposalExecution(proposal, execution *nostr.Event, executed []neth.Call) error {
+	proposalData, err := ParseProposalEvent(proposal)
+	if err != nil {
+		return err
+	}
+
+	executionData, err := ParseProposalExecutionEvent(execution)
+	if err != nil {
+		return err
+	}
+
+	if executionData.ProposalID != proposal.ID {
+		return fmt.Errorf("execution is of proposal %s, not %s", executionData.ProposalID, proposal.ID)
+	}
+	if len(executed) != len(proposalData.Payload) {
+		return fmt.Errorf("%d calls were executed, the payload has %d", len(executed), len(proposalData.Payload))
+	}
+
+	for i, call := range proposalData.Payload {
+		if !call.Equal(executed[i]) {
+			return fmt.Errorf("call %d to %s does not match the payload", i, executed[i].To.Hex())
+		}
+	}
+
+	return nil
+}
+
+// VerifyUserOpExecution checks an execution done by a user operation, the user operation event
+// must have the hash referenced by the execution and its calldata must match the payload
+func VerifyUserOpExecution(proposal, execution, userOp *nostr.Event) error {
+	executionData, err := ParseProposalExecutionEvent(execution)
+	if err != nil {
+		return err
+	}
+
+	userOpData, err := ParseUserOpEvent(userOp)
+	if err != nil {
+		return err
+	}
+
+	chainID, ok := new(big.Int).SetString(executionData.ChainID, 10)
+	if !ok {
+		return fmt.Errorf("invalid execution chain ID: %s", executionData.ChainID)
+	}
+	if hash := userOpData.UserOpData.GetHash(chainID); !strings.EqualFold(hash, executionData.UserOpHash) {
+		return fmt.Errorf("user operation %s is not the one of the execution", hash)
+	}
+
+	calls, err := neth.DecodeExecuteCalls(userOpData.UserOpData.CallData)
+	if err != nil {
+		return err
+	}
+
+	return VerifyProposalExecution(proposal, execution, calls)
+}
@@ -0,0 +1,274 @@
+package event
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// castVote creates a vote of a public key at a time
+func castVote(t *testing.T, proposal *nostr.Event, pubkey string, createdAt int64, vote VoteEvent) *nostr.Event {
+	t.Helper()
+
+	evt, err := CreateVoteEvent(proposal, vote)
+	if err != nil {
+		t.Fatalf("Failed to create vote: %v", err)
+	}
+	evt.PubKey = pubkey
+	evt.CreatedAt = nostr.Timestamp(createdAt)
+	evt.ID = evt.GetID()
+	return evt
+}
+
+func TestGovernanceSingleVote(t *testing.T) {
+	proposal, err := CreateProposalEvent(ProposalEvent{
+		GroupID: "community",
+		Title:   "Fund the garden",
+		Choices: []string{"yes", "no"},
+		Start:   1000,
+		End:     2000,
+		Quorum:  big.NewInt(3),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+	proposal.ID = proposal.GetID()
+
+	if tag := proposal.Tags.Find("h"); tag == nil || tag[1] != "community" {
+		t.Errorf("Expected h tag community, got %v", tag)
+	}
+	if !HasTypeTag(proposal, "proposal") {
+		t.Error("Expected proposal type tag")
+	}
+
+	parsed, err := ParseProposalEvent(proposal)
+	if err != nil {
+		t.Fatalf("Failed to parse proposal: %v", err)
+	}
+	if parsed.Strategy != VotingStrategySingle {
+		t.Errorf("Expected strategy %s, got %s", VotingStrategySingle, parsed.Strategy)
+	}
+
+	if _, err := CreateVoteEvent(proposal, VoteEvent{Choice: 2}); err == nil {
+		t.Error("Expected an error for an unknown choice")
+	}
+
+	votes := []*nostr.Event{
+		castVote(t, proposal, "alice", 1100, VoteEvent{Choice: 1}),
+		castVote(t, proposal, "alice", 1200, VoteEvent{Choice: 0}), // Changed vote
+		castVote(t, proposal, "bob", 1300, VoteEvent{Choice: 0}),
+		castVote(t, proposal, "carol", 1400, VoteEvent{Choice: 1}),
+		castVote(t, proposal, "dave", 2500, VoteEvent{Choice: 1}), // Too late
+	}
+
+	vote, err := ParseVoteEvent(votes[0])
+	if err != nil || vote.ProposalID != proposal.ID {
+		t.Fatalf("Expected vote on %s, got %v (%v)", proposal.ID, vote, err)
+	}
+	if tag := votes[0].Tags.Find("e"); tag == nil || tag[1] != proposal.ID {
+		t.Errorf("Expected e tag of the proposal, got %v", tag)
+	}
+
+	result, err := Tally(proposal, votes)
+	if err != nil {
+		t.Fatalf("Failed to tally: %v", err)
+	}
+	if result.Votes != 3 {
+		t.Errorf("Expected 3 votes, got %d", result.Votes)
+	}
+	if result.Totals[0].Int64() != 2 || result.Totals[1].Int64() != 1 {
+		t.Errorf("Expected totals 2/1, got %s/%s", result.Totals[0], result.Totals[1])
+	}
+	if result.Winner != 0 {
+		t.Errorf("Expected winner 0, got %d", result.Winner)
+	}
+	if !result.QuorumReached {
+		t.Error("Expected quorum to be reached")
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].EventID != votes[4].ID {
+		t.Errorf("Expected the late vote to be rejected, got %v", result.Rejected)
+	}
+
+	// A tie has no winner
+	result, err = Tally(proposal, votes[2:4])
+	if err != nil {
+		t.Fatalf("Failed to tally: %v", err)
+	}
+	if result.Winner != -1 || result.QuorumReached {
+		t.Errorf("Expected a tie without quorum, got winner %d and quorum %v", result.Winner, result.QuorumReached)
+	}
+}
+
+// signVoteAddress signs the vote of a public key on a proposal with the key of an address
+func signVoteAddress(t *testing.T, key *ecdsa.PrivateKey, proposalID, pubkey string) []byte {
+	t.Helper()
+
+	signature, err := crypto.Sign(neth.MessageHash(VoteAddressMessage(proposalID, pubkey)), key)
+	if err != nil {
+		t.Fatalf("Failed to sign vote address: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature
+}
+
+func TestGovernanceTokenBalance(t *testing.T) {
+	token := "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	holderKey, _ := crypto.GenerateKey()
+	holder := crypto.PubkeyToAddress(holderKey.PublicKey).Hex()
+
+	if _, err := CreateProposalEvent(ProposalEvent{
+		GroupID:  "community",
+		Title:    "Change the fee",
+		Choices:  []string{"yes", "no"},
+		Start:    1000,
+		End:      2000,
+		Strategy: VotingStrategyTokenBalance,
+	}); err == nil {
+		t.Error("Expected an error for a token balance proposal without token")
+	}
+
+	proposal, err := CreateProposalEvent(ProposalEvent{
+		GroupID:       "community",
+		Title:         "Change the fee",
+		Choices:       []string{"yes", "no"},
+		Start:         1000,
+		End:           2000,
+		Strategy:      VotingStrategyTokenBalance,
+		ChainID:       "100",
+		Token:         token,
+		SnapshotBlock: 12345,
+		BalanceSlot:   2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+	proposal.ID = proposal.GetID()
+
+	if tag := proposal.Tags.Find("token"); tag == nil || tag[1] != token {
+		t.Errorf("Expected token tag %s, got %v", token, tag)
+	}
+
+	if _, err := CreateVoteEvent(proposal, VoteEvent{Choice: 0}); err == nil {
+		t.Error("Expected an error for a vote without address")
+	}
+	if _, err := CreateVoteEvent(proposal, VoteEvent{Choice: 0, Address: holder, Balance: big.NewInt(10)}); err == nil {
+		t.Error("Expected an error for a vote without signature and proof")
+	}
+
+	proof := func(slot uint64) *neth.BalanceProof {
+		return &neth.BalanceProof{Header: []byte{0xc0}, Token: common.HexToAddress(token), Holder: common.HexToAddress(holder), Slot: slot}
+	}
+
+	votes := []*nostr.Event{
+		// Signed for another public key
+		castVote(t, proposal, "alice", 1100, VoteEvent{Choice: 0, Address: holder, Signature: signVoteAddress(t, holderKey, proposal.ID, "mallory"), Proof: proof(2)}),
+		// Proof of another slot than the proposal
+		castVote(t, proposal, "bob", 1200, VoteEvent{Choice: 0, Address: holder, Signature: signVoteAddress(t, holderKey, proposal.ID, "bob"), Proof: proof(0)}),
+		// Owned address and slot, but the proof does not verify
+		castVote(t, proposal, "carol", 1300, VoteEvent{Choice: 1, Address: holder, Signature: signVoteAddress(t, holderKey, proposal.ID, "carol"), Balance: big.NewInt(1000), Proof: proof(2)}),
+	}
+
+	// Claimed balances are never trusted
+	if _, err := Tally(proposal, votes); err == nil {
+		t.Error("Expected an error tallying without the snapshot block hash")
+	}
+
+	result, err := Tally(proposal, votes, WithSnapshotBlockHash(common.Hash{1}))
+	if err != nil {
+		t.Fatalf("Failed to tally: %v", err)
+	}
+	if result.Votes != 0 || len(result.Rejected) != 3 || result.Winner != -1 {
+		t.Fatalf("Expected every vote to be rejected, got %d votes and %v", result.Votes, result.Rejected)
+	}
+
+	reasons := []string{"did not sign the vote", "slot 0", "invalid balance proof"}
+	for i, reason := range reasons {
+		if !strings.Contains(result.Rejected[i].Reason, reason) {
+			t.Errorf("Expected vote %d to be rejected with %q, got %q", i, reason, result.Rejected[i].Reason)
+		}
+	}
+}
+
+func TestGovernanceExecution(t *testing.T) {
+	target := common.HexToAddress("0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d")
+	transfer := append(common.FromHex("0xa9059cbb"), make([]byte, 64)...)
+	payload := []neth.Call{{To: target, Data: transfer}}
+
+	proposal, err := CreateProposalEvent(ProposalEvent{
+		GroupID: "community",
+		Title:   "Pay the gardener",
+		Choices: []string{"yes", "no"},
+		Start:   1000,
+		End:     2000,
+		Payload: payload,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create proposal: %v", err)
+	}
+	proposal.ID = proposal.GetID()
+
+	result, err := Tally(proposal, []*nostr.Event{castVote(t, proposal, "alice", 1100, VoteEvent{Choice: 0})})
+	if err != nil {
+		t.Fatalf("Failed to tally: %v", err)
+	}
+	if !result.Passed() {
+		t.Fatal("Expected the proposal to pass")
+	}
+
+	if _, err := CreateProposalExecutionEvent(proposal, &TallyResult{Winner: 1, QuorumReached: true}, ProposalExecutionEvent{ChainID: "100", TxHash: "0xabc"}); err == nil {
+		t.Error("Expected an error for a rejected proposal")
+	}
+
+	// execute(address,uint256,bytes) of a smart account
+	callData := append([]byte{}, neth.FuncSigSingle...)
+	callData = append(callData, common.LeftPadBytes(target.Bytes(), 32)...)
+	callData = append(callData, make([]byte, 32)...)
+	callData = append(callData, common.LeftPadBytes([]byte{96}, 32)...)
+	callData = append(callData, common.LeftPadBytes([]byte{byte(len(transfer))}, 32)...)
+	callData = append(callData, common.RightPadBytes(transfer, 96)...)
+
+	userOp := goldenUserOp()
+	userOp.CallData = callData
+	userOpEvt, err := CreateUserOpEvent(big.NewInt(100), nil, nil, nil, nil, 0, userOp, EventTypeUserOpExecuted)
+	if err != nil {
+		t.Fatalf("Failed to create user op: %v", err)
+	}
+
+	execution, err := CreateProposalExecutionEvent(proposal, result, ProposalExecutionEvent{
+		ChainID:    "100",
+		TxHash:     "0x8f0c2a5e6b6d4b5b1e7f0d3c2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a3b",
+		UserOpHash: userOp.GetHash(big.NewInt(100)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create execution: %v", err)
+	}
+
+	if tag := execution.Tags.FindWithValue("e", proposal.ID); tag == nil {
+		t.Error("Expected e tag of the proposal")
+	}
+	if tag := execution.Tags.FindWithValue("r", userOp.GetHash(big.NewInt(100))); tag == nil {
+		t.Error("Expected r tag of the user operation")
+	}
+
+	if err := VerifyUserOpExecution(proposal, execution, userOpEvt); err != nil {
+		t.Errorf("Expected the execution to match the payload, got %v", err)
+	}
+	if err := VerifyProposalExecution(proposal, execution, payload); err != nil {
+		t.Errorf("Expected the calls to match the payload, got %v", err)
+	}
+
+	// Other calldata does not match
+	tampered := []neth.Call{{To: target, Data: append(transfer[:len(transfer)-1:len(transfer)-1], 1)}}
+	if err := VerifyProposalExecution(proposal, execution, tampered); err == nil {
+		t.Error("Expected an error for calldata that does not match the payload")
+	}
+	if err := VerifyProposalExecution(proposal, execution, append(payload, payload...)); err == nil {
+		t.Error("Expected an error for extra calls")
+	}
+}
@@ -3,8 +3,6 @@ package event
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 )
@@ -23,7 +21,8 @@ const (
 	KindGroupDelete       = 9008 // Delete Group
 	KindGroupJoinRequest  = 9021 // Join Request
 
-	// Group Metadata Events (39000s)
+	// Group Metadata Events (39000s), 39001-39009 are the legacy kinds of the package, see
+	// group_nip29.go for the kinds NIP-29 defines
 	KindGroupMetadata   = 39000 // Group metadata
 	KindGroupName       = 39001 // Group name
 	KindGroupAbout      = 39002 // Group about/description
@@ -151,7 +150,7 @@ type GroupUpdatedEvent struct {
 
 // CreateGroupEvent creates a group event (kind 9007)
 func CreateGroupEvent(groupID, name, about, picture string, admins, moderators []string, private, closed bool) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	metadata := GroupMetadata{
 		Name:       name,
@@ -173,7 +172,7 @@ func CreateGroupEvent(groupID, name, about, picture string, admins, moderators [
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupCreate,
+		Kind:      MappedKind(KindGroupCreate),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -192,23 +191,23 @@ func CreateGroupEvent(groupID, name, about, picture string, admins, moderators [
 	}
 
 	// Add group type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "metadata"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("metadata"))
 
 	if private {
-		evt.Tags = append(evt.Tags, []string{"t", "private"})
+		evt.Tags = append(evt.Tags, typeTag("private"))
 	}
 
 	if closed {
-		evt.Tags = append(evt.Tags, []string{"t", "closed"})
+		evt.Tags = append(evt.Tags, typeTag("closed"))
 	}
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateAddUserEvent creates an add user event (kind 9000)
 func CreateAddUserEvent(groupID, user, role string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	join := GroupJoin{
 		User:     user,
@@ -224,7 +223,7 @@ func CreateAddUserEvent(groupID, user, role string) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupAddUser,
+		Kind:      MappedKind(KindGroupAddUser),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -237,19 +236,19 @@ func CreateAddUserEvent(groupID, user, role string) (*nostr.Event, error) {
 
 	// Add role tag if specified
 	if role != "" {
-		evt.Tags = append(evt.Tags, []string{"t", role})
+		evt.Tags = append(evt.Tags, typeTag(role))
 	}
 
 	// Add add user type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "add_user"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("add_user"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateRemoveUserEvent creates a remove user event (kind 9001)
 func CreateRemoveUserEvent(groupID, user, reason string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	leave := GroupLeave{
 		User:   user,
@@ -265,7 +264,7 @@ func CreateRemoveUserEvent(groupID, user, reason string) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupRemoveUser,
+		Kind:      MappedKind(KindGroupRemoveUser),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -277,15 +276,15 @@ func CreateRemoveUserEvent(groupID, user, reason string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"p", user, "former_member"})
 
 	// Add remove user type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "remove_user"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("remove_user"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateEditMetadataEvent creates an edit metadata event (kind 9002)
 func CreateEditMetadataEvent(groupID, name, about, picture string, admins, moderators []string, private, closed bool) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	metadata := GroupMetadata{
 		Name:       name,
@@ -307,7 +306,7 @@ func CreateEditMetadataEvent(groupID, name, about, picture string, admins, moder
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupEditMetadata,
+		Kind:      MappedKind(KindGroupEditMetadata),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -326,28 +325,28 @@ func CreateEditMetadataEvent(groupID, name, about, picture string, admins, moder
 	}
 
 	// Add group type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "edit_metadata"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("edit_metadata"))
 
 	if private {
-		evt.Tags = append(evt.Tags, []string{"t", "private"})
+		evt.Tags = append(evt.Tags, typeTag("private"))
 	}
 
 	if closed {
-		evt.Tags = append(evt.Tags, []string{"t", "closed"})
+		evt.Tags = append(evt.Tags, typeTag("closed"))
 	}
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateAddAdminEvent creates an add admin event (kind 9003)
 func CreateAddAdminEvent(groupID, user string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupAddAdmin,
+		Kind:      MappedKind(KindGroupAddAdmin),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   "",
 	}
@@ -359,20 +358,20 @@ func CreateAddAdminEvent(groupID, user string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"p", user, "admin"})
 
 	// Add add admin type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "add_admin"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("add_admin"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateRemoveAdminEvent creates a remove admin event (kind 9004)
 func CreateRemoveAdminEvent(groupID, user string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupRemoveAdmin,
+		Kind:      MappedKind(KindGroupRemoveAdmin),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   "",
 	}
@@ -384,20 +383,20 @@ func CreateRemoveAdminEvent(groupID, user string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"p", user, "former_admin"})
 
 	// Add remove admin type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "remove_admin"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("remove_admin"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateDeleteEventEvent creates a delete event event (kind 9005)
 func CreateDeleteEventEvent(groupID, eventID string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupDeleteEvent,
+		Kind:      MappedKind(KindGroupDeleteEvent),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   "",
 	}
@@ -409,20 +408,20 @@ func CreateDeleteEventEvent(groupID, eventID string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"e", eventID, "delete"})
 
 	// Add delete event type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "delete_event"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("delete_event"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateUpdateGroupStatusEvent creates an update group status event (kind 9006)
 func CreateUpdateGroupStatusEvent(groupID, status string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupUpdateStatus,
+		Kind:      MappedKind(KindGroupUpdateStatus),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   status,
 	}
@@ -431,23 +430,23 @@ func CreateUpdateGroupStatusEvent(groupID, status string) (*nostr.Event, error)
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add status tag
-	evt.Tags = append(evt.Tags, []string{"t", status})
+	evt.Tags = append(evt.Tags, typeTag(status))
 
 	// Add update status type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "update_status"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("update_status"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateDeleteGroupEvent creates a delete group event (kind 9008)
 func CreateDeleteGroupEvent(groupID string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupDelete,
+		Kind:      MappedKind(KindGroupDelete),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   "",
 	}
@@ -456,20 +455,20 @@ func CreateDeleteGroupEvent(groupID string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add delete group type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "delete_group"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("delete_group"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateJoinRequestEvent creates a join request event (kind 9021)
 func CreateJoinRequestEvent(groupID, message string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupJoinRequest,
+		Kind:      MappedKind(KindGroupJoinRequest),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   message,
 	}
@@ -478,15 +477,20 @@ func CreateJoinRequestEvent(groupID, message string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add join request type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "join_request"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("join_request"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
-// CreateGroupMetadataEvent creates a group metadata event (kind 39000)
+// CreateGroupMetadataEvent creates a group metadata event (kind 39000), in the NIP-29 format
+// when the group kind mode is GroupKindsNIP29
 func CreateGroupMetadataEvent(groupID string, metadata GroupMetadata) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if CurrentGroupKindMode() == GroupKindsNIP29 {
+		return createNIP29GroupMetadataEvent(groupID, metadata), nil
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupMetadataEvent{
 		GroupID:   groupID,
@@ -502,7 +506,7 @@ func CreateGroupMetadataEvent(groupID string, metadata GroupMetadata) (*nostr.Ev
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupMetadata,
+		Kind:      MappedKind(KindGroupMetadata),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -511,15 +515,19 @@ func CreateGroupMetadataEvent(groupID string, metadata GroupMetadata) (*nostr.Ev
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group metadata type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "metadata"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("metadata"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupNameEvent creates a group name event (kind 39001)
 func CreateGroupNameEvent(groupID, name string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupName, "name"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupNameEvent{
 		GroupID:   groupID,
@@ -535,7 +543,7 @@ func CreateGroupNameEvent(groupID, name string) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupName,
+		Kind:      MappedKind(KindGroupName),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -544,15 +552,19 @@ func CreateGroupNameEvent(groupID, name string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group name type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "name"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("name"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupAboutEvent creates a group about event (kind 39002)
 func CreateGroupAboutEvent(groupID, about string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupAbout, "about"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupAboutEvent{
 		GroupID:   groupID,
@@ -568,7 +580,7 @@ func CreateGroupAboutEvent(groupID, about string) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupAbout,
+		Kind:      MappedKind(KindGroupAbout),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -577,15 +589,19 @@ func CreateGroupAboutEvent(groupID, about string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group about type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "about"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("about"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupPictureEvent creates a group picture event (kind 39003)
 func CreateGroupPictureEvent(groupID, picture string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupPicture, "picture"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupPictureEvent{
 		GroupID:   groupID,
@@ -601,7 +617,7 @@ func CreateGroupPictureEvent(groupID, picture string) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupPicture,
+		Kind:      MappedKind(KindGroupPicture),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -610,15 +626,19 @@ func CreateGroupPictureEvent(groupID, picture string) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group picture type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "picture"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("picture"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupAdminsEvent creates a group admins event (kind 39004)
 func CreateGroupAdminsEvent(groupID string, admins []string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupAdmins, "admins"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupAdminsEvent{
 		GroupID:   groupID,
@@ -634,7 +654,7 @@ func CreateGroupAdminsEvent(groupID string, admins []string) (*nostr.Event, erro
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupAdmins,
+		Kind:      MappedKind(KindGroupAdmins),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -648,15 +668,19 @@ func CreateGroupAdminsEvent(groupID string, admins []string) (*nostr.Event, erro
 	}
 
 	// Add group admins type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "admins"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("admins"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupModeratorsEvent creates a group moderators event (kind 39005)
 func CreateGroupModeratorsEvent(groupID string, moderators []string) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupModerators, "moderators"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupModeratorsEvent{
 		GroupID:    groupID,
@@ -672,7 +696,7 @@ func CreateGroupModeratorsEvent(groupID string, moderators []string) (*nostr.Eve
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupModerators,
+		Kind:      MappedKind(KindGroupModerators),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -686,15 +710,19 @@ func CreateGroupModeratorsEvent(groupID string, moderators []string) (*nostr.Eve
 	}
 
 	// Add group moderators type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "moderators"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("moderators"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupPrivateEvent creates a group private event (kind 39006)
 func CreateGroupPrivateEvent(groupID string, private bool) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupPrivate, "private"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupPrivateEvent{
 		GroupID:   groupID,
@@ -710,7 +738,7 @@ func CreateGroupPrivateEvent(groupID string, private bool) (*nostr.Event, error)
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupPrivate,
+		Kind:      MappedKind(KindGroupPrivate),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -719,19 +747,23 @@ func CreateGroupPrivateEvent(groupID string, private bool) (*nostr.Event, error)
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group private type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "private"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("private"))
 
 	if private {
-		evt.Tags = append(evt.Tags, []string{"t", "private"})
+		evt.Tags = append(evt.Tags, typeTag("private"))
 	}
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupClosedEvent creates a group closed event (kind 39007)
 func CreateGroupClosedEvent(groupID string, closed bool) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupClosed, "closed"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupClosedEvent{
 		GroupID:   groupID,
@@ -747,7 +779,7 @@ func CreateGroupClosedEvent(groupID string, closed bool) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupClosed,
+		Kind:      MappedKind(KindGroupClosed),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -756,19 +788,23 @@ func CreateGroupClosedEvent(groupID string, closed bool) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group closed type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "closed"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("closed"))
 
 	if closed {
-		evt.Tags = append(evt.Tags, []string{"t", "closed"})
+		evt.Tags = append(evt.Tags, typeTag("closed"))
 	}
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupCreatedEvent creates a group created event (kind 39008)
 func CreateGroupCreatedEvent(groupID string, createdAt int64) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupCreated, "created"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupCreatedEvent{
 		GroupID:   groupID,
@@ -783,7 +819,7 @@ func CreateGroupCreatedEvent(groupID string, createdAt int64) (*nostr.Event, err
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupCreated,
+		Kind:      MappedKind(KindGroupCreated),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -792,15 +828,19 @@ func CreateGroupCreatedEvent(groupID string, createdAt int64) (*nostr.Event, err
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group created type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "created"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("created"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // CreateGroupUpdatedEvent creates a group updated event (kind 39009)
 func CreateGroupUpdatedEvent(groupID string, updatedAt int64) (*nostr.Event, error) {
-	now := time.Now().Unix()
+	if err := requireLegacyGroupKinds(KindGroupUpdated, "updated"); err != nil {
+		return nil, err
+	}
+
+	now := timeNow().Unix()
 
 	eventData := GroupUpdatedEvent{
 		GroupID:   groupID,
@@ -816,7 +856,7 @@ func CreateGroupUpdatedEvent(groupID string, updatedAt int64) (*nostr.Event, err
 	evt := &nostr.Event{
 		PubKey:    "", // Will be set by the client
 		CreatedAt: nostr.Timestamp(now),
-		Kind:      KindGroupUpdated,
+		Kind:      MappedKind(KindGroupUpdated),
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -825,20 +865,20 @@ func CreateGroupUpdatedEvent(groupID string, updatedAt int64) (*nostr.Event, err
 	evt.Tags = append(evt.Tags, []string{"h", groupID})
 
 	// Add group updated type tags
-	evt.Tags = append(evt.Tags, []string{"t", "group"})
-	evt.Tags = append(evt.Tags, []string{"t", "updated"})
+	evt.Tags = append(evt.Tags, typeTag("group"))
+	evt.Tags = append(evt.Tags, typeTag("updated"))
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // ParseGroupEvent parses a group creation event (kind 9007)
 func ParseGroupEvent(evt *nostr.Event) (*GroupMetadata, error) {
-	if evt.Kind != KindGroupCreate {
+	if DefaultKind(evt.Kind) != KindGroupCreate {
 		return nil, fmt.Errorf("event is not a group creation event (kind %d)", evt.Kind)
 	}
 
 	var metadata GroupMetadata
-	err := json.Unmarshal([]byte(evt.Content), &metadata)
+	err := unmarshalContent(evt, &metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group metadata: %w", err)
 	}
@@ -848,12 +888,12 @@ func ParseGroupEvent(evt *nostr.Event) (*GroupMetadata, error) {
 
 // ParseEditMetadataEvent parses an edit metadata event (kind 9002)
 func ParseEditMetadataEvent(evt *nostr.Event) (*GroupMetadata, error) {
-	if evt.Kind != KindGroupEditMetadata {
+	if DefaultKind(evt.Kind) != KindGroupEditMetadata {
 		return nil, fmt.Errorf("event is not an edit metadata event (kind %d)", evt.Kind)
 	}
 
 	var metadata GroupMetadata
-	err := json.Unmarshal([]byte(evt.Content), &metadata)
+	err := unmarshalContent(evt, &metadata)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group metadata: %w", err)
 	}
@@ -863,12 +903,12 @@ func ParseEditMetadataEvent(evt *nostr.Event) (*GroupMetadata, error) {
 
 // ParseAddUserEvent parses an add user event (kind 9000)
 func ParseAddUserEvent(evt *nostr.Event) (*GroupJoin, error) {
-	if evt.Kind != KindGroupAddUser {
+	if DefaultKind(evt.Kind) != KindGroupAddUser {
 		return nil, fmt.Errorf("event is not an add user event (kind %d)", evt.Kind)
 	}
 
 	var join GroupJoin
-	err := json.Unmarshal([]byte(evt.Content), &join)
+	err := unmarshalContent(evt, &join)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal add user: %w", err)
 	}
@@ -878,12 +918,12 @@ func ParseAddUserEvent(evt *nostr.Event) (*GroupJoin, error) {
 
 // ParseRemoveUserEvent parses a remove user event (kind 9001)
 func ParseRemoveUserEvent(evt *nostr.Event) (*GroupLeave, error) {
-	if evt.Kind != KindGroupRemoveUser {
+	if DefaultKind(evt.Kind) != KindGroupRemoveUser {
 		return nil, fmt.Errorf("event is not a remove user event (kind %d)", evt.Kind)
 	}
 
 	var leave GroupLeave
-	err := json.Unmarshal([]byte(evt.Content), &leave)
+	err := unmarshalContent(evt, &leave)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal remove user: %w", err)
 	}
@@ -893,12 +933,15 @@ func ParseRemoveUserEvent(evt *nostr.Event) (*GroupLeave, error) {
 
 // ParseGroupMetadataEvent parses a group metadata event (kind 39000)
 func ParseGroupMetadataEvent(evt *nostr.Event) (*GroupMetadataEvent, error) {
-	if evt.Kind != KindGroupMetadata {
+	if DefaultKind(evt.Kind) != KindGroupMetadata {
 		return nil, fmt.Errorf("event is not a group metadata event (kind %d)", evt.Kind)
 	}
+	if IsNIP29GroupEvent(evt) {
+		return parseNIP29GroupMetadataEvent(evt), nil
+	}
 
 	var eventData GroupMetadataEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group metadata event: %w", err)
 	}
@@ -908,12 +951,15 @@ func ParseGroupMetadataEvent(evt *nostr.Event) (*GroupMetadataEvent, error) {
 
 // ParseGroupNameEvent parses a group name event (kind 39001)
 func ParseGroupNameEvent(evt *nostr.Event) (*GroupNameEvent, error) {
-	if evt.Kind != KindGroupName {
+	if DefaultKind(evt.Kind) != KindGroupName {
 		return nil, fmt.Errorf("event is not a group name event (kind %d)", evt.Kind)
 	}
+	if IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a NIP-29 group admins event", ErrLegacyGroupKind, evt.Kind)
+	}
 
 	var eventData GroupNameEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group name event: %w", err)
 	}
@@ -923,12 +969,15 @@ func ParseGroupNameEvent(evt *nostr.Event) (*GroupNameEvent, error) {
 
 // ParseGroupAboutEvent parses a group about event (kind 39002)
 func ParseGroupAboutEvent(evt *nostr.Event) (*GroupAboutEvent, error) {
-	if evt.Kind != KindGroupAbout {
+	if DefaultKind(evt.Kind) != KindGroupAbout {
 		return nil, fmt.Errorf("event is not a group about event (kind %d)", evt.Kind)
 	}
+	if IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a NIP-29 group members event", ErrLegacyGroupKind, evt.Kind)
+	}
 
 	var eventData GroupAboutEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group about event: %w", err)
 	}
@@ -938,12 +987,15 @@ func ParseGroupAboutEvent(evt *nostr.Event) (*GroupAboutEvent, error) {
 
 // ParseGroupPictureEvent parses a group picture event (kind 39003)
 func ParseGroupPictureEvent(evt *nostr.Event) (*GroupPictureEvent, error) {
-	if evt.Kind != KindGroupPicture {
+	if DefaultKind(evt.Kind) != KindGroupPicture {
 		return nil, fmt.Errorf("event is not a group picture event (kind %d)", evt.Kind)
 	}
+	if IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a NIP-29 group roles event", ErrLegacyGroupKind, evt.Kind)
+	}
 
 	var eventData GroupPictureEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group picture event: %w", err)
 	}
@@ -953,12 +1005,12 @@ func ParseGroupPictureEvent(evt *nostr.Event) (*GroupPictureEvent, error) {
 
 // ParseGroupAdminsEvent parses a group admins event (kind 39004)
 func ParseGroupAdminsEvent(evt *nostr.Event) (*GroupAdminsEvent, error) {
-	if evt.Kind != KindGroupAdmins {
+	if DefaultKind(evt.Kind) != KindGroupAdmins {
 		return nil, fmt.Errorf("event is not a group admins event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupAdminsEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group admins event: %w", err)
 	}
@@ -968,12 +1020,12 @@ func ParseGroupAdminsEvent(evt *nostr.Event) (*GroupAdminsEvent, error) {
 
 // ParseGroupModeratorsEvent parses a group moderators event (kind 39005)
 func ParseGroupModeratorsEvent(evt *nostr.Event) (*GroupModeratorsEvent, error) {
-	if evt.Kind != KindGroupModerators {
+	if DefaultKind(evt.Kind) != KindGroupModerators {
 		return nil, fmt.Errorf("event is not a group moderators event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupModeratorsEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group moderators event: %w", err)
 	}
@@ -983,12 +1035,12 @@ func ParseGroupModeratorsEvent(evt *nostr.Event) (*GroupModeratorsEvent, error)
 
 // ParseGroupPrivateEvent parses a group private event (kind 39006)
 func ParseGroupPrivateEvent(evt *nostr.Event) (*GroupPrivateEvent, error) {
-	if evt.Kind != KindGroupPrivate {
+	if DefaultKind(evt.Kind) != KindGroupPrivate {
 		return nil, fmt.Errorf("event is not a group private event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupPrivateEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group private event: %w", err)
 	}
@@ -998,12 +1050,12 @@ func ParseGroupPrivateEvent(evt *nostr.Event) (*GroupPrivateEvent, error) {
 
 // ParseGroupClosedEvent parses a group closed event (kind 39007)
 func ParseGroupClosedEvent(evt *nostr.Event) (*GroupClosedEvent, error) {
-	if evt.Kind != KindGroupClosed {
+	if DefaultKind(evt.Kind) != KindGroupClosed {
 		return nil, fmt.Errorf("event is not a group closed event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupClosedEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group closed event: %w", err)
 	}
@@ -1013,12 +1065,12 @@ func ParseGroupClosedEvent(evt *nostr.Event) (*GroupClosedEvent, error) {
 
 // ParseGroupCreatedEvent parses a group created event (kind 39008)
 func ParseGroupCreatedEvent(evt *nostr.Event) (*GroupCreatedEvent, error) {
-	if evt.Kind != KindGroupCreated {
+	if DefaultKind(evt.Kind) != KindGroupCreated {
 		return nil, fmt.Errorf("event is not a group created event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupCreatedEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group created event: %w", err)
 	}
@@ -1028,12 +1080,12 @@ func ParseGroupCreatedEvent(evt *nostr.Event) (*GroupCreatedEvent, error) {
 
 // ParseGroupUpdatedEvent parses a group updated event (kind 39009)
 func ParseGroupUpdatedEvent(evt *nostr.Event) (*GroupUpdatedEvent, error) {
-	if evt.Kind != KindGroupUpdated {
+	if DefaultKind(evt.Kind) != KindGroupUpdated {
 		return nil, fmt.Errorf("event is not a group updated event (kind %d)", evt.Kind)
 	}
 
 	var eventData GroupUpdatedEvent
-	err := json.Unmarshal([]byte(evt.Content), &eventData)
+	err := unmarshalContent(evt, &eventData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal group updated event: %w", err)
 	}
@@ -1048,40 +1100,19 @@ func GetGroupIDFromEvent(evt *nostr.Event) (string, error) {
 			return tag[1], nil
 		}
 	}
+
+	// NIP-29 group metadata events are addressed by their d tag
+	if IsNIP29GroupEvent(evt) {
+		return evt.Tags.GetD(), nil
+	}
+
 	return "", fmt.Errorf("group ID tag (h) not found in event")
 }
 
 // IsGroupEvent checks if a Nostr event is a group-related event
 func IsGroupEvent(evt *nostr.Event) bool {
-	// Group Moderation Events (9000s)
-	if evt.Kind == KindGroupAddUser ||
-		evt.Kind == KindGroupRemoveUser ||
-		evt.Kind == KindGroupEditMetadata ||
-		evt.Kind == KindGroupAddAdmin ||
-		evt.Kind == KindGroupRemoveAdmin ||
-		evt.Kind == KindGroupDeleteEvent ||
-		evt.Kind == KindGroupUpdateStatus ||
-		evt.Kind == KindGroupCreate ||
-		evt.Kind == KindGroupDelete ||
-		evt.Kind == KindGroupJoinRequest {
-		return true
-	}
-
-	// Group Metadata Events (39000s)
-	if evt.Kind == KindGroupMetadata ||
-		evt.Kind == KindGroupName ||
-		evt.Kind == KindGroupAbout ||
-		evt.Kind == KindGroupPicture ||
-		evt.Kind == KindGroupAdmins ||
-		evt.Kind == KindGroupModerators ||
-		evt.Kind == KindGroupPrivate ||
-		evt.Kind == KindGroupClosed ||
-		evt.Kind == KindGroupCreated ||
-		evt.Kind == KindGroupUpdated {
-		return true
-	}
-
-	return false
+	spec, ok := KindInfo(DefaultKind(evt.Kind))
+	return ok && spec.Category == KindCategoryGroup
 }
 
 // FilterGroupEventsByGroupID filters a list of events by group ID
@@ -1095,16 +1126,17 @@ func FilterGroupEventsByGroupID(events []*nostr.Event, groupID string) []*nostr.
 	return filtered
 }
 
-// ParseGroupIdentifier parses a group identifier in the format "host'group-id"
+// ParseGroupIdentifier parses a group identifier in the NIP-29 format "host'group-id" or as
+// the naddr of the group metadata, see DecodeGroupIdentifier
 func ParseGroupIdentifier(groupIdentifier string) (host, groupID string, err error) {
-	parts := strings.Split(groupIdentifier, "'")
-	if len(parts) != 2 {
-		return "", "", fmt.Errorf("invalid group identifier format: expected 'host'group-id', got '%s'", groupIdentifier)
+	address, err := DecodeGroupIdentifier(groupIdentifier)
+	if err != nil {
+		return "", "", err
 	}
-	return parts[0], parts[1], nil
+	return address.Host, address.GroupID, nil
 }
 
-// FormatGroupIdentifier formats a host and group ID into a group identifier
+// FormatGroupIdentifier formats a host and group ID into a NIP-29 group identifier
 func FormatGroupIdentifier(host, groupID string) string {
 	return fmt.Sprintf("%s'%s", host, groupID)
 }
@@ -1130,7 +1162,7 @@ func ValidateGroupID(groupID string) error {
 
 // GetEventTypeFromGroupEvent determines the type of group event
 func GetEventTypeFromGroupEvent(evt *nostr.Event) string {
-	switch evt.Kind {
+	switch DefaultKind(evt.Kind) {
 	// Group Moderation Events (9000s)
 	case KindGroupAddUser:
 		return "add_user"
@@ -1157,10 +1189,19 @@ func GetEventTypeFromGroupEvent(evt *nostr.Event) string {
 	case KindGroupMetadata:
 		return "group_metadata"
 	case KindGroupName:
+		if IsNIP29GroupEvent(evt) {
+			return "group_admins_list"
+		}
 		return "group_name"
 	case KindGroupAbout:
+		if IsNIP29GroupEvent(evt) {
+			return "group_members"
+		}
 		return "group_about"
 	case KindGroupPicture:
+		if IsNIP29GroupEvent(evt) {
+			return "group_roles"
+		}
 		return "group_picture"
 	case KindGroupAdmins:
 		return "group_admins"
@@ -1174,6 +1215,14 @@ func GetEventTypeFromGroupEvent(evt *nostr.Event) string {
 		return "group_created"
 	case KindGroupUpdated:
 		return "group_updated"
+	case KindGroupTokenGate:
+		return "token_gate"
+	case KindProposal:
+		return "proposal"
+	case KindVote:
+		return "vote"
+	case KindProposalExecution:
+		return "proposal_execution"
 
 	default:
 		return "unknown"
@@ -0,0 +1,88 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// GroupIdentifierFormat is the format group identifiers are written in
+type GroupIdentifierFormat int
+
+const (
+	// GroupIdentifierHost is the NIP-29 "host'group-id" format, the default
+	GroupIdentifierHost GroupIdentifierFormat = iota
+
+	// GroupIdentifierNaddr is the NIP-19 naddr of the kind 39000 metadata event of the group,
+	// signed by the relay, with the relay URL as hint
+	GroupIdentifierNaddr
+)
+
+// GroupAddress locates a group on a relay
+type GroupAddress struct {
+	Host        string // Relay host, e.g. "groups.example.com" or "wss://groups.example.com"
+	GroupID     string
+	RelayPubKey string // Key the relay signs the group metadata with, required for naddr
+}
+
+// RelayURL returns the websocket URL of the relay hosting the group
+func (a GroupAddress) RelayURL() string {
+	if strings.Contains(a.Host, "://") {
+		return a.Host
+	}
+	return "wss://" + a.Host
+}
+
+// DecodeGroupIdentifier parses a group identifier in any supported format, the format is
+// detected from the identifier: an naddr, optionally with a "nostr:" prefix, or "host'group-id"
+func DecodeGroupIdentifier(groupIdentifier string) (*GroupAddress, error) {
+	identifier := strings.TrimPrefix(groupIdentifier, "nostr:")
+
+	if strings.HasPrefix(identifier, "naddr1") {
+		prefix, value, err := nip19.Decode(identifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group naddr: %w", err)
+		}
+
+		pointer, ok := value.(nostr.EntityPointer)
+		if prefix != "naddr" || !ok {
+			return nil, fmt.Errorf("invalid group naddr: %s", groupIdentifier)
+		}
+		if DefaultKind(pointer.Kind) != KindGroupMetadata {
+			return nil, fmt.Errorf("naddr of kind %d is not a group, expected %d", pointer.Kind, KindGroupMetadata)
+		}
+		if len(pointer.Relays) == 0 {
+			return nil, fmt.Errorf("group naddr has no relay hint")
+		}
+
+		return &GroupAddress{
+			Host:        pointer.Relays[0],
+			GroupID:     pointer.Identifier,
+			RelayPubKey: pointer.PublicKey,
+		}, nil
+	}
+
+	host, groupID, ok := strings.Cut(identifier, "'")
+	if !ok || host == "" || groupID == "" || strings.Contains(groupID, "'") {
+		return nil, fmt.Errorf("invalid group identifier format: expected 'host'group-id' or an naddr, got '%s'", groupIdentifier)
+	}
+
+	return &GroupAddress{Host: host, GroupID: groupID}, nil
+}
+
+// EncodeGroupIdentifier writes a group identifier in the given format
+func EncodeGroupIdentifier(address GroupAddress, format GroupIdentifierFormat) (string, error) {
+	switch format {
+	case GroupIdentifierHost:
+		return FormatGroupIdentifier(address.Host, address.GroupID), nil
+	case GroupIdentifierNaddr:
+		if address.RelayPubKey == "" {
+			return "", fmt.Errorf("relay public key is required for a group naddr")
+		}
+		return nip19.EncodeEntity(address.RelayPubKey, KindGroupMetadata, address.GroupID, []string{address.RelayURL()})
+	default:
+		return "", fmt.Errorf("unknown group identifier format %d", format)
+	}
+}
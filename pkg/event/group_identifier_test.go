@@ -0,0 +1,47 @@
+package event
+
+import "testing"
+
+func TestGroupIdentifierFormats(t *testing.T) {
+	host, groupID, err := ParseGroupIdentifier("groups.example.com'my-group")
+	if err != nil {
+		t.Fatalf("Failed to parse identifier: %v", err)
+	}
+	if host != "groups.example.com" || groupID != "my-group" {
+		t.Errorf("Expected groups.example.com and my-group, got %s and %s", host, groupID)
+	}
+
+	address := GroupAddress{Host: "groups.example.com", GroupID: "my-group", RelayPubKey: goldenPubKey}
+	naddr, err := EncodeGroupIdentifier(address, GroupIdentifierNaddr)
+	if err != nil {
+		t.Fatalf("Failed to encode naddr: %v", err)
+	}
+
+	for _, identifier := range []string{naddr, "nostr:" + naddr} {
+		decoded, err := DecodeGroupIdentifier(identifier)
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", identifier, err)
+		}
+		if decoded.Host != "wss://groups.example.com" || decoded.GroupID != "my-group" || decoded.RelayPubKey != goldenPubKey {
+			t.Errorf("Expected the encoded address, got %+v", decoded)
+		}
+	}
+
+	identifier, err := EncodeGroupIdentifier(address, GroupIdentifierHost)
+	if err != nil {
+		t.Fatalf("Failed to encode identifier: %v", err)
+	}
+	if identifier != "groups.example.com'my-group" {
+		t.Errorf("Expected groups.example.com'my-group, got %s", identifier)
+	}
+
+	if _, err := EncodeGroupIdentifier(GroupAddress{Host: "groups.example.com", GroupID: "my-group"}, GroupIdentifierNaddr); err == nil {
+		t.Error("Expected an error for an naddr without relay key")
+	}
+
+	for _, invalid := range []string{"", "my-group", "'my-group", "host'", "a'b'c", "naddr1invalid"} {
+		if _, err := DecodeGroupIdentifier(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NIP-29 Group Metadata Kinds, signed by the relay. The legacy kinds 39001-39003 of the package
+// (name, about and picture) share their numbers, events of both are told apart by their d tag.
+const (
+	KindGroupAdminsList = 39001 // Group admins and their roles
+	KindGroupMembers    = 39002 // Group members
+	KindGroupRoles      = 39003 // Roles supported by the group
+)
+
+// GroupKindMode selects the kinds the group metadata constructors create
+type GroupKindMode int
+
+const (
+	// GroupKindsLegacy creates the kinds 39000-39009 of the package, with JSON content and an h
+	// tag, the default
+	GroupKindsLegacy GroupKindMode = iota
+
+	// GroupKindsNIP29 creates the kinds 39000-39003 as defined by NIP-29, with the data in tags
+	// and a d tag, so that other NIP-29 relays and clients understand them
+	GroupKindsNIP29
+)
+
+// ErrLegacyGroupKind is returned when a legacy group kind is created in the NIP-29 mode, or a
+// NIP-29 event is parsed as a legacy one
+var ErrLegacyGroupKind = errors.New("legacy group kind")
+
+// groupKindMode is the mode of the group metadata constructors
+var groupKindMode = struct {
+	sync.RWMutex
+	mode GroupKindMode
+}{}
+
+// SetGroupKindMode sets the kinds the group metadata constructors create. Parsing is not
+// affected, both legacy and NIP-29 events are always accepted.
+func SetGroupKindMode(mode GroupKindMode) {
+	groupKindMode.Lock()
+	defer groupKindMode.Unlock()
+
+	groupKindMode.mode = mode
+}
+
+// CurrentGroupKindMode returns the kinds the group metadata constructors create
+func CurrentGroupKindMode() GroupKindMode {
+	groupKindMode.RLock()
+	defer groupKindMode.RUnlock()
+
+	return groupKindMode.mode
+}
+
+// requireLegacyGroupKinds fails in the NIP-29 mode, for constructors of legacy kinds that NIP-29
+// does not define or uses for something else
+func requireLegacyGroupKinds(kind int, name string) error {
+	if CurrentGroupKindMode() == GroupKindsNIP29 {
+		return fmt.Errorf("%w: group %s (kind %d) is not a NIP-29 event, use the group metadata", ErrLegacyGroupKind, name, kind)
+	}
+	return nil
+}
+
+// GroupAdmin is an admin of a group with its roles
+type GroupAdmin struct {
+	PubKey string   `json:"pubkey"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// GroupRole is a role supported by a group
+type GroupRole struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// GroupAdminsListEvent represents a NIP-29 group admins event (kind 39001)
+type GroupAdminsListEvent struct {
+	GroupID   string       `json:"group_id"`
+	Admins    []GroupAdmin `json:"admins"`
+	CreatedAt int64        `json:"created_at"`
+}
+
+// GroupMembersEvent represents a NIP-29 group members event (kind 39002)
+type GroupMembersEvent struct {
+	GroupID   string   `json:"group_id"`
+	Members   []string `json:"members"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+// GroupRolesEvent represents a NIP-29 group roles event (kind 39003)
+type GroupRolesEvent struct {
+	GroupID   string      `json:"group_id"`
+	Roles     []GroupRole `json:"roles"`
+	CreatedAt int64       `json:"created_at"`
+}
+
+// IsNIP29GroupEvent checks if a group metadata event (kinds 39000-39003) is in the NIP-29
+// format: identified by a d tag, without the h tag of the legacy events
+func IsNIP29GroupEvent(evt *nostr.Event) bool {
+	if evt == nil || DefaultKind(evt.Kind) < KindGroupMetadata || DefaultKind(evt.Kind) > KindGroupRoles {
+		return false
+	}
+	return evt.Tags.Find("d") != nil && evt.Tags.Find("h") == nil
+}
+
+// newNIP29GroupEvent creates an empty NIP-29 group metadata event of the given kind
+func newNIP29GroupEvent(kind int, groupID string) *nostr.Event {
+	now := timeNow().Unix()
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be set by the relay
+		CreatedAt: nostr.Timestamp(now),
+		Kind:      MappedKind(kind),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   "",
+	}
+
+	// Add group identifier tag (d tag with group ID)
+	evt.Tags = append(evt.Tags, []string{"d", groupID})
+
+	return evt
+}
+
+// createNIP29GroupMetadataEvent creates a NIP-29 group metadata event (kind 39000)
+func createNIP29GroupMetadataEvent(groupID string, metadata GroupMetadata) *nostr.Event {
+	evt := newNIP29GroupEvent(KindGroupMetadata, groupID)
+
+	// Add metadata tags
+	if metadata.Name != "" {
+		evt.Tags = append(evt.Tags, []string{"name", metadata.Name})
+	}
+	if metadata.Picture != "" {
+		evt.Tags = append(evt.Tags, []string{"picture", metadata.Picture})
+	}
+	if metadata.About != "" {
+		evt.Tags = append(evt.Tags, []string{"about", metadata.About})
+	}
+
+	// Add visibility and access tags
+	if metadata.Private {
+		evt.Tags = append(evt.Tags, []string{"private"})
+	} else {
+		evt.Tags = append(evt.Tags, []string{"public"})
+	}
+	if metadata.Closed {
+		evt.Tags = append(evt.Tags, []string{"closed"})
+	} else {
+		evt.Tags = append(evt.Tags, []string{"open"})
+	}
+
+	return evt
+}
+
+// CreateGroupAdminsListEvent creates a NIP-29 group admins event (kind 39001)
+func CreateGroupAdminsListEvent(groupID string, admins []GroupAdmin) (*nostr.Event, error) {
+	evt := newNIP29GroupEvent(KindGroupAdminsList, groupID)
+
+	// Add admin tags with their roles
+	for _, admin := range admins {
+		if admin.PubKey == "" {
+			return nil, fmt.Errorf("admin public key cannot be empty")
+		}
+		evt.Tags = append(evt.Tags, append(nostr.Tag{"p", admin.PubKey}, admin.Roles...))
+	}
+
+	return formatAlt(evt)
+}
+
+// CreateGroupMembersEvent creates a NIP-29 group members event (kind 39002)
+func CreateGroupMembersEvent(groupID string, members []string) (*nostr.Event, error) {
+	evt := newNIP29GroupEvent(KindGroupMembers, groupID)
+
+	// Add member tags
+	for _, member := range members {
+		if member == "" {
+			return nil, fmt.Errorf("member public key cannot be empty")
+		}
+		evt.Tags = append(evt.Tags, []string{"p", member})
+	}
+
+	return formatAlt(evt)
+}
+
+// CreateGroupRolesEvent creates a NIP-29 group roles event (kind 39003)
+func CreateGroupRolesEvent(groupID string, roles []GroupRole) (*nostr.Event, error) {
+	evt := newNIP29GroupEvent(KindGroupRoles, groupID)
+
+	// Add role tags with their descriptions
+	for _, role := range roles {
+		if role.Name == "" {
+			return nil, fmt.Errorf("role name cannot be empty")
+		}
+
+		tag := nostr.Tag{"role", role.Name}
+		if role.Description != "" {
+			tag = append(tag, role.Description)
+		}
+		evt.Tags = append(evt.Tags, tag)
+	}
+
+	return formatAlt(evt)
+}
+
+// parseNIP29GroupMetadataEvent parses a NIP-29 group metadata event (kind 39000)
+func parseNIP29GroupMetadataEvent(evt *nostr.Event) *GroupMetadataEvent {
+	eventData := GroupMetadataEvent{
+		GroupID:   evt.Tags.GetD(),
+		CreatedAt: int64(evt.CreatedAt),
+	}
+
+	if tag := evt.Tags.Find("name"); tag != nil {
+		eventData.Metadata.Name = tag[1]
+	}
+	if tag := evt.Tags.Find("picture"); tag != nil {
+		eventData.Metadata.Picture = tag[1]
+	}
+	if tag := evt.Tags.Find("about"); tag != nil {
+		eventData.Metadata.About = tag[1]
+	}
+
+	for _, tag := range evt.Tags {
+		if len(tag) == 0 {
+			continue
+		}
+
+		switch tag[0] {
+		case "private":
+			eventData.Metadata.Private = true
+		case "closed":
+			eventData.Metadata.Closed = true
+		}
+	}
+
+	eventData.Metadata.UpdatedAt = eventData.CreatedAt
+
+	return &eventData
+}
+
+// ParseGroupAdminsListEvent parses a NIP-29 group admins event (kind 39001)
+func ParseGroupAdminsListEvent(evt *nostr.Event) (*GroupAdminsListEvent, error) {
+	if DefaultKind(evt.Kind) != KindGroupAdminsList {
+		return nil, fmt.Errorf("event is not a group admins list event (kind %d)", evt.Kind)
+	}
+	if !IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a legacy group name event", ErrLegacyGroupKind, evt.Kind)
+	}
+
+	eventData := GroupAdminsListEvent{
+		GroupID:   evt.Tags.GetD(),
+		Admins:    make([]GroupAdmin, 0),
+		CreatedAt: int64(evt.CreatedAt),
+	}
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "p" {
+			continue
+		}
+		eventData.Admins = append(eventData.Admins, GroupAdmin{PubKey: tag[1], Roles: tag[2:]})
+	}
+
+	return &eventData, nil
+}
+
+// ParseGroupMembersEvent parses a NIP-29 group members event (kind 39002)
+func ParseGroupMembersEvent(evt *nostr.Event) (*GroupMembersEvent, error) {
+	if DefaultKind(evt.Kind) != KindGroupMembers {
+		return nil, fmt.Errorf("event is not a group members event (kind %d)", evt.Kind)
+	}
+	if !IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a legacy group about event", ErrLegacyGroupKind, evt.Kind)
+	}
+
+	eventData := GroupMembersEvent{
+		GroupID:   evt.Tags.GetD(),
+		Members:   make([]string, 0),
+		CreatedAt: int64(evt.CreatedAt),
+	}
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "p" {
+			continue
+		}
+		eventData.Members = append(eventData.Members, tag[1])
+	}
+
+	return &eventData, nil
+}
+
+// ParseGroupRolesEvent parses a NIP-29 group roles event (kind 39003)
+func ParseGroupRolesEvent(evt *nostr.Event) (*GroupRolesEvent, error) {
+	if DefaultKind(evt.Kind) != KindGroupRoles {
+		return nil, fmt.Errorf("event is not a group roles event (kind %d)", evt.Kind)
+	}
+	if !IsNIP29GroupEvent(evt) {
+		return nil, fmt.Errorf("%w: kind %d event is a legacy group picture event", ErrLegacyGroupKind, evt.Kind)
+	}
+
+	eventData := GroupRolesEvent{
+		GroupID:   evt.Tags.GetD(),
+		Roles:     make([]GroupRole, 0),
+		CreatedAt: int64(evt.CreatedAt),
+	}
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "role" {
+			continue
+		}
+
+		role := GroupRole{Name: tag[1]}
+		if len(tag) >= 3 {
+			role.Description = tag[2]
+		}
+		eventData.Roles = append(eventData.Roles, role)
+	}
+
+	return &eventData, nil
+}
+
+// groupKindParser parses the kinds shared by legacy and NIP-29 events with the parser of the
+// format of the event
+func groupKindParser(legacy, nip29 func(evt *nostr.Event) (any, error)) func(evt *nostr.Event) (any, error) {
+	return func(evt *nostr.Event) (any, error) {
+		if IsNIP29GroupEvent(evt) {
+			return nip29(evt)
+		}
+		return legacy(evt)
+	}
+}
@@ -0,0 +1,141 @@
+package event
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestNIP29GroupMetadata(t *testing.T) {
+	SetGroupKindMode(GroupKindsNIP29)
+	t.Cleanup(func() { SetGroupKindMode(GroupKindsLegacy) })
+
+	evt, err := CreateGroupMetadataEvent("community", GroupMetadata{
+		Name:    "Community",
+		About:   "A test group",
+		Picture: "https://example.com/picture.png",
+		Private: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	if evt.Kind != KindGroupMetadata {
+		t.Errorf("Expected kind %d, got %d", KindGroupMetadata, evt.Kind)
+	}
+	if evt.Content != "" {
+		t.Errorf("Expected empty content, got %s", evt.Content)
+	}
+	if evt.Tags.Find("h") != nil {
+		t.Errorf("Expected no h tag, got %v", evt.Tags)
+	}
+	if !IsNIP29GroupEvent(evt) {
+		t.Error("Expected a NIP-29 group event")
+	}
+
+	parsed, err := ParseGroupMetadataEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if parsed.GroupID != "community" {
+		t.Errorf("Expected group community, got %s", parsed.GroupID)
+	}
+	if parsed.Metadata.Name != "Community" || parsed.Metadata.About != "A test group" || parsed.Metadata.Picture != "https://example.com/picture.png" {
+		t.Errorf("Expected the metadata from the tags, got %+v", parsed.Metadata)
+	}
+	if !parsed.Metadata.Private || parsed.Metadata.Closed {
+		t.Errorf("Expected a private open group, got %+v", parsed.Metadata)
+	}
+
+	groupID, err := GetGroupIDFromEvent(evt)
+	if err != nil || groupID != "community" {
+		t.Errorf("Expected group community from the d tag, got %s (%v)", groupID, err)
+	}
+
+	if err := ValidateContentAgainstSchema(evt); !errors.Is(err, ErrNoContentSchema) {
+		t.Errorf("Expected ErrNoContentSchema, got %v", err)
+	}
+
+	if _, err := CreateGroupNameEvent("community", "Community"); !errors.Is(err, ErrLegacyGroupKind) {
+		t.Errorf("Expected ErrLegacyGroupKind, got %v", err)
+	}
+	if _, err := CreateGroupAdminsEvent("community", []string{goldenPubKey}); !errors.Is(err, ErrLegacyGroupKind) {
+		t.Errorf("Expected ErrLegacyGroupKind, got %v", err)
+	}
+}
+
+func TestNIP29GroupLists(t *testing.T) {
+	admins, err := CreateGroupAdminsListEvent("community", []GroupAdmin{{PubKey: goldenPubKey, Roles: []string{"ceo", "moderator"}}})
+	if err != nil {
+		t.Fatalf("Failed to create admins event: %v", err)
+	}
+
+	parsed, err := ParseEvent(admins)
+	if err != nil {
+		t.Fatalf("Failed to parse admins event: %v", err)
+	}
+	adminsList, ok := parsed.(*GroupAdminsListEvent)
+	if !ok {
+		t.Fatalf("Expected *GroupAdminsListEvent, got %T", parsed)
+	}
+	if len(adminsList.Admins) != 1 || adminsList.Admins[0].PubKey != goldenPubKey || len(adminsList.Admins[0].Roles) != 2 {
+		t.Errorf("Expected one admin with two roles, got %+v", adminsList.Admins)
+	}
+	if eventType := GetEventTypeFromGroupEvent(admins); eventType != "group_admins_list" {
+		t.Errorf("Expected group_admins_list, got %s", eventType)
+	}
+	if _, err := ParseGroupNameEvent(admins); !errors.Is(err, ErrLegacyGroupKind) {
+		t.Errorf("Expected ErrLegacyGroupKind, got %v", err)
+	}
+
+	members, err := CreateGroupMembersEvent("community", []string{goldenPubKey})
+	if err != nil {
+		t.Fatalf("Failed to create members event: %v", err)
+	}
+	membersList, err := ParseGroupMembersEvent(members)
+	if err != nil {
+		t.Fatalf("Failed to parse members event: %v", err)
+	}
+	if len(membersList.Members) != 1 || membersList.Members[0] != goldenPubKey {
+		t.Errorf("Expected member %s, got %v", goldenPubKey, membersList.Members)
+	}
+
+	roles, err := CreateGroupRolesEvent("community", []GroupRole{{Name: "ceo", Description: "Runs the group"}, {Name: "moderator"}})
+	if err != nil {
+		t.Fatalf("Failed to create roles event: %v", err)
+	}
+	rolesList, err := ParseGroupRolesEvent(roles)
+	if err != nil {
+		t.Fatalf("Failed to parse roles event: %v", err)
+	}
+	if len(rolesList.Roles) != 2 || rolesList.Roles[0].Description != "Runs the group" || rolesList.Roles[1].Name != "moderator" {
+		t.Errorf("Expected the ceo and moderator roles, got %+v", rolesList.Roles)
+	}
+}
+
+func TestLegacyGroupKindsStillParse(t *testing.T) {
+	name, err := CreateGroupNameEvent("community", "Community")
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if IsNIP29GroupEvent(name) {
+		t.Error("Expected a legacy group event")
+	}
+
+	parsed, err := ParseEvent(name)
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if nameEvent, ok := parsed.(*GroupNameEvent); !ok || nameEvent.Name != "Community" {
+		t.Errorf("Expected *GroupNameEvent with name Community, got %#v", parsed)
+	}
+
+	if _, err := ParseGroupAdminsListEvent(name); !errors.Is(err, ErrLegacyGroupKind) {
+		t.Errorf("Expected ErrLegacyGroupKind, got %v", err)
+	}
+
+	if IsNIP29GroupEvent(&nostr.Event{Kind: KindGroupAdmins, Tags: nostr.Tags{{"d", "community"}}}) {
+		t.Error("Expected kind 39004 to never be a NIP-29 event")
+	}
+}
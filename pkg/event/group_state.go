@@ -0,0 +1,373 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// GroupState represents the current state of a group derived from its moderation history
+type GroupState struct {
+	GroupID       string            `json:"group_id"`
+	Creator       string            `json:"creator,omitempty"`
+	Metadata      GroupMetadata     `json:"metadata"`
+	Members       map[string]string `json:"members"` // pubkey -> role ("admin", "moderator", "member")
+	Admins        map[string]bool   `json:"admins"`
+	Moderators    map[string]bool   `json:"moderators"`
+	Status        string            `json:"status,omitempty"`
+	Deleted       bool              `json:"deleted,omitempty"`
+	DeletedEvents []string          `json:"deleted_events,omitempty"`
+}
+
+// newGroupState creates an empty group state for the given group ID
+func newGroupState(groupID string) *GroupState {
+	return &GroupState{
+		GroupID:    groupID,
+		Members:    make(map[string]string),
+		Admins:     make(map[string]bool),
+		Moderators: make(map[string]bool),
+	}
+}
+
+// isModerationKind checks if a kind is one of the NIP-29 moderation kinds (9000-9008)
+func isModerationKind(kind int) bool {
+	return kind >= KindGroupAddUser && kind <= KindGroupDelete
+}
+
+// ComputeGroupState folds moderation events (kinds 9000-9008) in chronological order
+// into the current state of a group. Events of other kinds are ignored, and all
+// moderation events must belong to the same group. Moderation events from pubkeys
+// without the required role at that point are ignored, see Apply.
+func ComputeGroupState(events []*nostr.Event) (*GroupState, error) {
+	moderation := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if evt != nil && isModerationKind(DefaultKind(evt.Kind)) {
+			moderation = append(moderation, evt)
+		}
+	}
+
+	if len(moderation) == 0 {
+		return nil, fmt.Errorf("no moderation events found")
+	}
+
+	// Apply events oldest first so the result is deterministic
+	SortEventsByCreatedAt(moderation, false)
+
+	groupID, err := GetGroupIDFromEvent(moderation[0])
+	if err != nil {
+		return nil, err
+	}
+
+	state := newGroupState(groupID)
+	for _, evt := range moderation {
+		id, err := GetGroupIDFromEvent(evt)
+		if err != nil {
+			return nil, err
+		}
+		if id != groupID {
+			return nil, fmt.Errorf("event %s belongs to group %s, expected %s", evt.ID, id, groupID)
+		}
+
+		if err := state.Apply(evt); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// Apply applies a single moderation event to the group state. The group is created by
+// the first create event, later ones are ignored unless the group was deleted and they
+// come from its creator or an admin. Other events are ignored unless their author is the
+// creator or an admin of the group, moderators may also add and remove members and
+// delete events.
+func (s *GroupState) Apply(evt *nostr.Event) error {
+	kind := DefaultKind(evt.Kind)
+
+	switch {
+	case kind == KindGroupCreate:
+		if evt.PubKey == "" || (s.Creator != "" && (!s.Deleted || !s.hasRole(evt.PubKey, "admin"))) {
+			return nil
+		}
+	case !isModerationKind(kind):
+		return fmt.Errorf("event is not a group moderation event (kind %d)", evt.Kind)
+	case s.Creator == "" || s.Deleted:
+		// Nothing but a create event applies to a group that does not exist
+		return nil
+	case !s.hasRole(evt.PubKey, requiredRole(kind)):
+		return nil
+	}
+
+	switch kind {
+	case KindGroupCreate:
+		metadata, err := ParseGroupEvent(evt)
+		if err != nil {
+			return err
+		}
+
+		*s = *newGroupState(s.GroupID)
+		s.Creator = evt.PubKey
+		s.setMetadata(*metadata)
+		s.Metadata.CreatedAt = int64(evt.CreatedAt)
+
+	case KindGroupEditMetadata:
+		metadata, err := ParseEditMetadataEvent(evt)
+		if err != nil {
+			return err
+		}
+
+		createdAt := s.Metadata.CreatedAt
+		s.setMetadata(*metadata)
+		s.Metadata.CreatedAt = createdAt
+
+	case KindGroupAddUser:
+		join, err := ParseAddUserEvent(evt)
+		if err != nil {
+			return err
+		}
+
+		// Only admins hand out roles
+		if join.Role != "" && join.Role != "member" && !s.hasRole(evt.PubKey, "admin") {
+			return nil
+		}
+
+		s.addMember(join.User, join.Role)
+
+	case KindGroupRemoveUser:
+		leave, err := ParseRemoveUserEvent(evt)
+		if err != nil {
+			return err
+		}
+
+		// Only admins remove admins and moderators
+		if (s.Admins[leave.User] || s.Moderators[leave.User]) && !s.hasRole(evt.PubKey, "admin") {
+			return nil
+		}
+
+		delete(s.Members, leave.User)
+		delete(s.Admins, leave.User)
+		delete(s.Moderators, leave.User)
+
+	case KindGroupAddAdmin:
+		user, err := getTargetPubkey(evt)
+		if err != nil {
+			return err
+		}
+
+		s.addMember(user, "admin")
+
+	case KindGroupRemoveAdmin:
+		user, err := getTargetPubkey(evt)
+		if err != nil {
+			return err
+		}
+
+		delete(s.Admins, user)
+		if s.Members[user] == "admin" {
+			s.Members[user] = "member"
+		}
+
+	case KindGroupDeleteEvent:
+		for _, tag := range evt.Tags {
+			if len(tag) >= 2 && tag[0] == "e" {
+				s.DeletedEvents = append(s.DeletedEvents, tag[1])
+			}
+		}
+
+	case KindGroupUpdateStatus:
+		s.Status = evt.Content
+
+		switch evt.Content {
+		case "private":
+			s.Metadata.Private = true
+		case "public":
+			s.Metadata.Private = false
+		case "closed":
+			s.Metadata.Closed = true
+		case "open":
+			s.Metadata.Closed = false
+		}
+
+	case KindGroupDelete:
+		s.Deleted = true
+	}
+
+	s.Metadata.UpdatedAt = int64(evt.CreatedAt)
+	s.syncMetadata()
+
+	return nil
+}
+
+// requiredRole returns the role needed to apply a moderation event of the given kind
+func requiredRole(kind int) string {
+	switch kind {
+	case KindGroupAddUser, KindGroupRemoveUser, KindGroupDeleteEvent:
+		return "moderator"
+	default:
+		return "admin"
+	}
+}
+
+// hasRole checks if a pubkey is the creator of the group or has at least the given role
+func (s *GroupState) hasRole(pubkey, role string) bool {
+	if pubkey == "" {
+		return false
+	}
+	if pubkey == s.Creator || s.Admins[pubkey] {
+		return true
+	}
+	return role == "moderator" && s.Moderators[pubkey]
+}
+
+// setMetadata replaces the group metadata along with its admin and moderator sets
+func (s *GroupState) setMetadata(metadata GroupMetadata) {
+	s.Metadata = metadata
+
+	for user := range s.Admins {
+		if s.Members[user] == "admin" {
+			s.Members[user] = "member"
+		}
+	}
+	for user := range s.Moderators {
+		if s.Members[user] == "moderator" {
+			s.Members[user] = "member"
+		}
+	}
+
+	s.Admins = make(map[string]bool)
+	s.Moderators = make(map[string]bool)
+
+	for _, moderator := range metadata.Moderators {
+		s.addMember(moderator, "moderator")
+	}
+	for _, admin := range metadata.Admins {
+		s.addMember(admin, "admin")
+	}
+}
+
+// addMember adds a user to the group with the given role
+func (s *GroupState) addMember(user, role string) {
+	if role == "" {
+		role = "member"
+	}
+
+	s.Members[user] = role
+
+	switch role {
+	case "admin":
+		s.Admins[user] = true
+	case "moderator":
+		s.Moderators[user] = true
+	}
+}
+
+// syncMetadata keeps the admin and moderator lists in the metadata in line with the sets
+func (s *GroupState) syncMetadata() {
+	s.Metadata.Admins = sortedKeys(s.Admins)
+	s.Metadata.Moderators = sortedKeys(s.Moderators)
+}
+
+// GetMembers returns the sorted list of group members
+func (s *GroupState) GetMembers() []string {
+	members := make([]string, 0, len(s.Members))
+	for user := range s.Members {
+		members = append(members, user)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// IsMember checks if a user is a member of the group
+func (s *GroupState) IsMember(user string) bool {
+	_, ok := s.Members[user]
+	return ok
+}
+
+// IsAdmin checks if a user is an admin of the group
+func (s *GroupState) IsAdmin(user string) bool {
+	return s.Admins[user]
+}
+
+// IsEventDeleted checks if an event was deleted by a moderator
+func (s *GroupState) IsEventDeleted(eventID string) bool {
+	for _, id := range s.DeletedEvents {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// ToMetadataEvents generates the replaceable group metadata events (kinds 39000-39009)
+// reflecting the current state. Each event carries a "d" tag with the group ID so
+// relays can replace the previous version.
+func (s *GroupState) ToMetadataEvents() ([]*nostr.Event, error) {
+	if s.Deleted {
+		return nil, fmt.Errorf("group %s has been deleted", s.GroupID)
+	}
+
+	var events []*nostr.Event
+
+	add := func(evt *nostr.Event, err error) error {
+		if err != nil {
+			return err
+		}
+		evt.Tags = append(evt.Tags, []string{"d", s.GroupID}) // Addressable identifier
+		events = append(events, evt)
+		return nil
+	}
+
+	if err := add(CreateGroupMetadataEvent(s.GroupID, s.Metadata)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupNameEvent(s.GroupID, s.Metadata.Name)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupAboutEvent(s.GroupID, s.Metadata.About)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupPictureEvent(s.GroupID, s.Metadata.Picture)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupAdminsEvent(s.GroupID, s.Metadata.Admins)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupModeratorsEvent(s.GroupID, s.Metadata.Moderators)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupPrivateEvent(s.GroupID, s.Metadata.Private)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupClosedEvent(s.GroupID, s.Metadata.Closed)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupCreatedEvent(s.GroupID, s.Metadata.CreatedAt)); err != nil {
+		return nil, err
+	}
+	if err := add(CreateGroupUpdatedEvent(s.GroupID, s.Metadata.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// getTargetPubkey extracts the target user from the first p tag of a moderation event
+func getTargetPubkey(evt *nostr.Event) (string, error) {
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "p" {
+			return tag[1], nil
+		}
+	}
+	return "", fmt.Errorf("user tag (p) not found in event")
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
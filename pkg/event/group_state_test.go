@@ -0,0 +1,234 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestComputeGroupState(t *testing.T) {
+	groupID := "test-group"
+
+	create, err := CreateGroupEvent(groupID, "Test Group", "A test group", "", []string{"admin1"}, []string{"mod1"}, false, false)
+	if err != nil {
+		t.Fatalf("Failed to create group event: %v", err)
+	}
+	create.ID = "01"
+	create.PubKey = "creator"
+	create.CreatedAt = 100
+
+	addUser, err := CreateAddUserEvent(groupID, "user1", "")
+	if err != nil {
+		t.Fatalf("Failed to create add user event: %v", err)
+	}
+	addUser.ID = "02"
+	addUser.PubKey = "mod1"
+	addUser.CreatedAt = 200
+
+	addAdmin, err := CreateAddAdminEvent(groupID, "user1")
+	if err != nil {
+		t.Fatalf("Failed to create add admin event: %v", err)
+	}
+	addAdmin.ID = "03"
+	addAdmin.PubKey = "admin1"
+	addAdmin.CreatedAt = 300
+
+	removeUser, err := CreateRemoveUserEvent(groupID, "mod1", "inactive")
+	if err != nil {
+		t.Fatalf("Failed to create remove user event: %v", err)
+	}
+	removeUser.ID = "04"
+	removeUser.PubKey = "admin1"
+	removeUser.CreatedAt = 400
+
+	status, err := CreateUpdateGroupStatusEvent(groupID, "closed")
+	if err != nil {
+		t.Fatalf("Failed to create update status event: %v", err)
+	}
+	status.ID = "05"
+	status.PubKey = "creator"
+	status.CreatedAt = 500
+
+	message, err := CreateMessageEvent("hello", &groupID)
+	if err != nil {
+		t.Fatalf("Failed to create message event: %v", err)
+	}
+
+	// Out of order on purpose, with a non-moderation event mixed in
+	state, err := ComputeGroupState([]*nostr.Event{status, removeUser, message, addAdmin, create, addUser})
+	if err != nil {
+		t.Fatalf("Failed to compute group state: %v", err)
+	}
+
+	if state.Creator != "creator" {
+		t.Errorf("Expected creator %s, got %s", "creator", state.Creator)
+	}
+	if state.Metadata.Name != "Test Group" {
+		t.Errorf("Expected name %s, got %s", "Test Group", state.Metadata.Name)
+	}
+	if !state.IsAdmin("admin1") || !state.IsAdmin("user1") {
+		t.Errorf("Expected admin1 and user1 to be admins, got %v", state.Metadata.Admins)
+	}
+	if state.IsMember("mod1") {
+		t.Error("Expected mod1 to have been removed")
+	}
+	if len(state.Metadata.Moderators) != 0 {
+		t.Errorf("Expected no moderators, got %v", state.Metadata.Moderators)
+	}
+	if !state.Metadata.Closed {
+		t.Error("Expected group to be closed")
+	}
+	if state.Metadata.CreatedAt != 100 || state.Metadata.UpdatedAt != 500 {
+		t.Errorf("Unexpected timestamps: created %d, updated %d", state.Metadata.CreatedAt, state.Metadata.UpdatedAt)
+	}
+
+	events, err := state.ToMetadataEvents()
+	if err != nil {
+		t.Fatalf("Failed to generate metadata events: %v", err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("Expected 10 metadata events, got %d", len(events))
+	}
+
+	for _, evt := range events {
+		if evt.Kind < KindGroupMetadata || evt.Kind > KindGroupUpdated {
+			t.Errorf("Unexpected kind %d", evt.Kind)
+		}
+		if d := evt.Tags.GetD(); d != groupID {
+			t.Errorf("Expected d tag %s on kind %d, got %s", groupID, evt.Kind, d)
+		}
+	}
+}
+
+func TestComputeGroupStateDeleted(t *testing.T) {
+	groupID := "test-group"
+
+	create, err := CreateGroupEvent(groupID, "Test Group", "", "", nil, nil, false, false)
+	if err != nil {
+		t.Fatalf("Failed to create group event: %v", err)
+	}
+	create.PubKey = "creator"
+	create.CreatedAt = 100
+
+	del, err := CreateDeleteGroupEvent(groupID)
+	if err != nil {
+		t.Fatalf("Failed to create delete group event: %v", err)
+	}
+	del.PubKey = "creator"
+	del.CreatedAt = 200
+
+	state, err := ComputeGroupState([]*nostr.Event{create, del})
+	if err != nil {
+		t.Fatalf("Failed to compute group state: %v", err)
+	}
+
+	if !state.Deleted {
+		t.Error("Expected group to be deleted")
+	}
+	if _, err := state.ToMetadataEvents(); err == nil {
+		t.Error("Expected error generating metadata events for a deleted group")
+	}
+
+	// Only the creator or an admin brings a deleted group back
+	recreate, err := CreateGroupEvent(groupID, "Taken Over", "", "", nil, nil, false, false)
+	if err != nil {
+		t.Fatalf("Failed to create group event: %v", err)
+	}
+	recreate.PubKey = "outsider"
+	recreate.CreatedAt = 300
+	if err := state.Apply(recreate); err != nil {
+		t.Fatalf("Failed to apply create event: %v", err)
+	}
+	if !state.Deleted {
+		t.Error("Expected group to stay deleted after a create event from an outsider")
+	}
+
+	recreate.PubKey = "creator"
+	if err := state.Apply(recreate); err != nil {
+		t.Fatalf("Failed to apply create event: %v", err)
+	}
+	if state.Deleted || state.Metadata.Name != "Taken Over" {
+		t.Errorf("Expected group to be created again, got deleted %v and name %s", state.Deleted, state.Metadata.Name)
+	}
+}
+
+func TestComputeGroupStateUnauthorized(t *testing.T) {
+	groupID := "test-group"
+
+	create, _ := CreateGroupEvent(groupID, "Test Group", "", "", []string{"admin1"}, []string{"mod1"}, false, false)
+	create.PubKey, create.CreatedAt = "creator", 100
+	addUser, _ := CreateAddUserEvent(groupID, "user1", "")
+	addUser.PubKey, addUser.CreatedAt = "mod1", 200
+
+	baseline, err := ComputeGroupState([]*nostr.Event{create, addUser})
+	if err != nil {
+		t.Fatalf("Failed to compute group state: %v", err)
+	}
+
+	addOther, _ := CreateAddUserEvent(groupID, "user2", "")
+	addModerator, _ := CreateAddUserEvent(groupID, "user2", "moderator")
+	removeUser, _ := CreateRemoveUserEvent(groupID, "user1", "")
+	removeAdmin, _ := CreateRemoveUserEvent(groupID, "admin1", "")
+	editMetadata, _ := CreateEditMetadataEvent(groupID, "Renamed", "", "", []string{"user1"}, nil, false, false)
+	addAdmin, _ := CreateAddAdminEvent(groupID, "user1")
+	revokeAdmin, _ := CreateRemoveAdminEvent(groupID, "admin1")
+	deleteEvent, _ := CreateDeleteEventEvent(groupID, "01")
+	status, _ := CreateUpdateGroupStatusEvent(groupID, "closed")
+	recreate, _ := CreateGroupEvent(groupID, "Taken Over", "", "", []string{"user1"}, nil, false, false)
+	del, _ := CreateDeleteGroupEvent(groupID)
+
+	testCases := []struct {
+		name   string
+		evt    *nostr.Event
+		author string
+	}{
+		{"add user by a member", addOther, "user1"},
+		{"add moderator by a moderator", addModerator, "mod1"},
+		{"remove user by a member", removeUser, "user1"},
+		{"remove admin by a moderator", removeAdmin, "mod1"},
+		{"edit metadata by a moderator", editMetadata, "mod1"},
+		{"add admin by a moderator", addAdmin, "mod1"},
+		{"add admin by an outsider", addAdmin, "outsider"},
+		{"remove admin by a member", revokeAdmin, "user1"},
+		{"delete event by a member", deleteEvent, "user1"},
+		{"update status by a moderator", status, "mod1"},
+		{"create an existing group", recreate, "admin1"},
+		{"delete group by a moderator", del, "mod1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := *tc.evt
+			evt.PubKey, evt.CreatedAt = tc.author, 300
+
+			state, err := ComputeGroupState([]*nostr.Event{create, addUser, &evt})
+			if err != nil {
+				t.Fatalf("Failed to compute group state: %v", err)
+			}
+			if !reflect.DeepEqual(state, baseline) {
+				t.Errorf("Expected state %+v, got %+v", baseline, state)
+			}
+		})
+	}
+
+	// Nothing applies before the group is created
+	early := *addAdmin
+	early.PubKey, early.CreatedAt = "creator", 50
+	state, err := ComputeGroupState([]*nostr.Event{&early, create, addUser})
+	if err != nil {
+		t.Fatalf("Failed to compute group state: %v", err)
+	}
+	if state.IsAdmin("user1") {
+		t.Error("Expected an event before the create event to be ignored")
+	}
+}
+
+func TestComputeGroupStateMixedGroups(t *testing.T) {
+	a, _ := CreateAddUserEvent("group-a", "user1", "")
+	b, _ := CreateAddUserEvent("group-b", "user2", "")
+
+	if _, err := ComputeGroupState([]*nostr.Event{a, b}); err == nil {
+		t.Error("Expected error for events from different groups")
+	}
+}
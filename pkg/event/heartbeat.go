@@ -0,0 +1,127 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindHeartbeat = 31107 // Addressable, one event per bridge instance
+)
+
+// DefaultHeartbeatInstance is the d tag of the heartbeats of a bridge without a named instance
+const DefaultHeartbeatInstance = "default"
+
+// Heartbeat is the liveness of a bridge instance
+type Heartbeat struct {
+	Instance  string            `json:"instance"`
+	StartedAt time.Time         `json:"started_at"`
+	Uptime    int64             `json:"uptime"`           // Seconds
+	Chains    map[string]uint64 `json:"chains,omitempty"` // Last processed block, by chain ID
+	Relays    map[string]bool   `json:"relays,omitempty"` // Whether each relay is connected
+	At        time.Time         `json:"at"`
+}
+
+// CreateHeartbeatEvent creates a heartbeat (kind 31107), later heartbeats of the same instance
+// replace earlier ones
+func CreateHeartbeatEvent(heartbeat Heartbeat) (*nostr.Event, error) {
+	if heartbeat.Instance == "" {
+		heartbeat.Instance = DefaultHeartbeatInstance
+	}
+	if heartbeat.At.IsZero() {
+		heartbeat.At = timeNow()
+	}
+	if heartbeat.StartedAt.After(heartbeat.At) {
+		return nil, fmt.Errorf("heartbeat cannot start after it is sent")
+	}
+	if !heartbeat.StartedAt.IsZero() {
+		heartbeat.Uptime = int64(heartbeat.At.Sub(heartbeat.StartedAt).Seconds())
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(heartbeat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(heartbeat.At.Unix()),
+		Kind:      MappedKind(KindHeartbeat),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Instance of the bridge
+	evt.Tags = append(evt.Tags, []string{"d", heartbeat.Instance})
+
+	// Type tag
+	evt.Tags = append(evt.Tags, typeTag("heartbeat"))
+
+	// Chain-specific tags, sorted for stable events
+	chains := make([]string, 0, len(heartbeat.Chains))
+	for chainID := range heartbeat.Chains {
+		chains = append(chains, chainID)
+	}
+	sort.Strings(chains)
+	for _, chainID := range chains {
+		evt.Tags = append(evt.Tags, []string{"layer", chainID}) // Chain ID
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a heartbeat of bridge instance %s, up for %s", heartbeat.Instance, time.Duration(heartbeat.Uptime)*time.Second)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseHeartbeatEvent parses a heartbeat
+func ParseHeartbeatEvent(evt *nostr.Event) (*Heartbeat, error) {
+	if DefaultKind(evt.Kind) != KindHeartbeat {
+		return nil, fmt.Errorf("event is not a heartbeat event (kind %d)", evt.Kind)
+	}
+
+	var heartbeat Heartbeat
+	if err := unmarshalContent(evt, &heartbeat); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal heartbeat event: %w", err)
+	}
+
+	return &heartbeat, nil
+}
+
+// IsBridgeHealthy checks the heartbeats of a bridge, e.g. the events of its pubkey: it is healthy
+// when its latest heartbeat is at most maxAge old and, if it reports relays, one of them is
+// connected. Monitors query the heartbeats of a bridge from any relay to check it without
+// reaching its host.
+func IsBridgeHealthy(events []*nostr.Event, maxAge time.Duration) bool {
+	var latest *Heartbeat
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) != KindHeartbeat {
+			continue
+		}
+		heartbeat, err := ParseHeartbeatEvent(evt)
+		if err != nil {
+			continue
+		}
+		if latest == nil || heartbeat.At.After(latest.At) {
+			latest = heartbeat
+		}
+	}
+
+	if latest == nil || timeNow().Sub(latest.At) > maxAge {
+		return false
+	}
+	if len(latest.Relays) == 0 {
+		return true
+	}
+	for _, connected := range latest.Relays {
+		if connected {
+			return true
+		}
+	}
+	return false
+}
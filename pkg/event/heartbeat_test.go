@@ -0,0 +1,61 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestIsBridgeHealthy(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	defer func(clock func() time.Time) { timeNow = clock }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	heartbeat := func(at time.Time, relays map[string]bool) *nostr.Event {
+		t.Helper()
+		evt, err := CreateHeartbeatEvent(Heartbeat{StartedAt: now.Add(-time.Hour), Relays: relays, Chains: map[string]uint64{"100": 42}, At: at})
+		if err != nil {
+			t.Fatalf("Failed to create heartbeat: %v", err)
+		}
+		return evt
+	}
+
+	fresh := heartbeat(now.Add(-30*time.Second), map[string]bool{"wss://relay.example.com": true})
+	if fresh.Kind != KindHeartbeat || fresh.Tags.GetD() != DefaultHeartbeatInstance {
+		t.Errorf("Expected a heartbeat of the default instance, got kind %d and d %q", fresh.Kind, fresh.Tags.GetD())
+	}
+	if tag := fresh.Tags.GetFirst([]string{"layer", "100"}); tag == nil {
+		t.Error("Expected the chain to be tagged")
+	}
+	parsed, err := ParseEvent(fresh)
+	if err != nil {
+		t.Fatalf("Failed to parse heartbeat: %v", err)
+	}
+	if hb := parsed.(*Heartbeat); hb.Uptime != 3570 || hb.Chains["100"] != 42 {
+		t.Errorf("Expected 3570s of uptime at block 42, got %+v", hb)
+	}
+
+	stale := heartbeat(now.Add(-10*time.Minute), nil)
+	disconnected := heartbeat(now, map[string]bool{"wss://relay.example.com": false})
+
+	cases := []struct {
+		name     string
+		events   []*nostr.Event
+		expected bool
+	}{
+		{"fresh", []*nostr.Event{stale, fresh}, true},
+		{"stale", []*nostr.Event{stale}, false},
+		{"disconnected", []*nostr.Event{fresh, disconnected}, false},
+		{"none", nil, false},
+	}
+	for _, c := range cases {
+		if got := IsBridgeHealthy(c.events, time.Minute); got != c.expected {
+			t.Errorf("Expected %s to be healthy %v, got %v", c.name, c.expected, got)
+		}
+	}
+
+	if _, err := CreateHeartbeatEvent(Heartbeat{StartedAt: now.Add(time.Hour), At: now}); err == nil {
+		t.Error("Expected a heartbeat starting in the future to fail")
+	}
+}
@@ -0,0 +1,175 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of the built-in messages, and the last fallback of translations
+const DefaultLocale = "en"
+
+// Message IDs of the built-in human-readable strings, translations use the same fmt verbs
+const (
+	MessageTxLogAlt           = "tx_log.alt"            // Topic, chain ID
+	MessageTxLogAltEvent      = "tx_log.alt.event"      // Event name
+	MessageTxLogAltValue      = "tx_log.alt.value"      // Native value
+	MessageTxLogStatusAlt     = "tx_log.status.alt"     // Chain ID, status
+	MessageTxLogStatusAltAt   = "tx_log.status.alt.at"  // Block number
+	MessageAltData            = "alt.data"              // Header of the data of a log
+	MessageUserOpAlt          = "user_op.alt"           // Chain ID
+	MessageUserOpUpdateAlt    = "user_op.update.alt"    // Event type, chain ID
+	MessageUserOpAltFailure   = "user_op.alt.failure"   // Failure reason
+	MessageUserOpAltPaymaster = "user_op.alt.paymaster" // Paymaster address
+	MessageTransferReceived   = "transfer.received"     // Receiver, amount, symbol, sender, chain ID
+)
+
+// builtinMessages are the English messages of DefaultCatalog
+var builtinMessages = map[string]string{
+	MessageTxLogAlt:           "This is an evm transaction log for topic %s on chain %s",
+	MessageTxLogAltEvent:      "\n Event: %s",
+	MessageTxLogAltValue:      "\n Value: %s",
+	MessageTxLogStatusAlt:     "This is an evm transaction log on chain %s, now %s",
+	MessageTxLogStatusAltAt:   " at block %d",
+	MessageAltData:            "\n Data:",
+	MessageUserOpAlt:          "This is a new user operation request on chain %s",
+	MessageUserOpUpdateAlt:    "This is a user operation update of type %s on chain %s",
+	MessageUserOpAltFailure:   "\n failure: %s",
+	MessageUserOpAltPaymaster: "\n this is intended for processing by paymaster: %s",
+	MessageTransferReceived:   "%s received %s %s from %s on chain %s",
+}
+
+// Catalog holds the translations of messages by locale, e.g. "de" or "pt-BR"
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]string // By locale and message ID
+}
+
+// DefaultCatalog holds the built-in messages, bridges add the translations of their locales to it
+var DefaultCatalog = NewCatalog()
+
+func init() {
+	DefaultCatalog.Add(DefaultLocale, builtinMessages)
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[string]string)}
+}
+
+// normalizeLocale lowercases a locale and uses dashes, "pt_BR" becomes "pt-br"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// Add adds the messages of a locale, replacing the existing translations of the same IDs
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[string]string)
+	}
+	for id, message := range messages {
+		c.messages[locale][id] = message
+	}
+}
+
+// LoadJSON adds the messages of a locale from a JSON object of message IDs to translations
+func (c *Catalog) LoadJSON(locale string, r io.Reader) error {
+	var messages map[string]string
+	if err := json.NewDecoder(r).Decode(&messages); err != nil {
+		return fmt.Errorf("invalid catalog of %s: %w", locale, err)
+	}
+
+	c.Add(locale, messages)
+	return nil
+}
+
+// Locales returns the locales of the catalog, sorted
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Message returns the translation of a message, falling back from the locale to its language,
+// e.g. "pt-br" to "pt", then to DefaultLocale. False is returned when no locale has it.
+func (c *Catalog) Message(locale, id string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candidate := range localeFallbacks(locale) {
+		if message, ok := c.messages[candidate][id]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// Translate returns the translation of a message formatted with the arguments, the message ID
+// when no locale has it
+func (c *Catalog) Translate(locale, id string, args ...any) string {
+	message, ok := c.Message(locale, id)
+	if !ok {
+		return id
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// localeFallbacks returns the locales to look a message up in, from the most specific
+func localeFallbacks(locale string) []string {
+	locale = normalizeLocale(locale)
+
+	var fallbacks []string
+	if locale != "" {
+		fallbacks = append(fallbacks, locale)
+		if language, _, ok := strings.Cut(locale, "-"); ok {
+			fallbacks = append(fallbacks, language)
+		}
+	}
+	return append(fallbacks, DefaultLocale)
+}
+
+var currentLocale struct {
+	sync.RWMutex
+	locale string
+}
+
+// SetLocale sets the locale of the alt tags created by the constructors, translated with
+// DefaultCatalog and the alt templates of DefaultFormatter. The default is DefaultLocale.
+func SetLocale(locale string) {
+	currentLocale.Lock()
+	defer currentLocale.Unlock()
+
+	currentLocale.locale = normalizeLocale(locale)
+}
+
+// Locale returns the locale of the alt tags created by the constructors
+func Locale() string {
+	currentLocale.RLock()
+	defer currentLocale.RUnlock()
+
+	if currentLocale.locale == "" {
+		return DefaultLocale
+	}
+	return currentLocale.locale
+}
+
+// localize translates a built-in message to the locale of the constructors
+func localize(id string, args ...any) string {
+	return DefaultCatalog.Translate(Locale(), id, args...)
+}
@@ -0,0 +1,102 @@
+package event
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func TestCatalogTranslate(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add(DefaultLocale, map[string]string{"greeting": "Hello %s", "farewell": "Bye"})
+	if err := catalog.LoadJSON("pt", strings.NewReader(`{"greeting": "Olá %s"}`)); err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	if err := catalog.LoadJSON("de", strings.NewReader(`["Hallo"]`)); err == nil {
+		t.Error("Expected a catalog that is not an object to fail")
+	}
+
+	tests := []struct {
+		locale, id, expected string
+	}{
+		{"pt", "greeting", "Olá Ana"},
+		{"pt_BR", "greeting", "Olá Ana"}, // Falls back to the language
+		{"pt-BR", "farewell", "Bye"},     // Falls back to the default locale
+		{"", "greeting", "Hello Ana"},
+		{"de", "unknown", "unknown"}, // Falls back to the ID
+	}
+	for _, tt := range tests {
+		args := []any{}
+		if tt.id == "greeting" {
+			args = append(args, "Ana")
+		}
+		if text := catalog.Translate(tt.locale, tt.id, args...); text != tt.expected {
+			t.Errorf("Expected %q for %s in %s, got %q", tt.expected, tt.id, tt.locale, text)
+		}
+	}
+
+	if locales := catalog.Locales(); len(locales) != 2 || locales[0] != "en" || locales[1] != "pt" {
+		t.Errorf("Expected locales en and pt, got %v", locales)
+	}
+}
+
+func TestLocalizedAltTags(t *testing.T) {
+	DefaultCatalog.Add("fr", map[string]string{MessageUserOpAlt: "Ceci est une nouvelle opération utilisateur sur la chaîne %s"})
+	SetLocale("fr-FR")
+	defer SetLocale("")
+
+	userOp, err := CreateUserOpEvent(big.NewInt(100), nil, nil, nil, nil, 0, goldenUserOp(), EventTypeUserOpRequested)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+	if tag := userOp.Tags.GetFirst([]string{"alt"}); tag == nil || (*tag)[1] != "Ceci est une nouvelle opération utilisateur sur la chaîne 100" {
+		t.Errorf("Expected the French alt tag, got %v", tag)
+	}
+
+	// Messages without a translation stay in English
+	evt, _ := CreateTxLogEvent(goldenLog())
+	if tag := evt.Tags.GetFirst([]string{"alt"}); tag == nil || !strings.HasPrefix((*tag)[1], "This is an evm transaction log") {
+		t.Errorf("Expected the English alt tag, got %v", tag)
+	}
+}
+
+func TestFormatterLocales(t *testing.T) {
+	DefaultCatalog.Add("es", map[string]string{"test.received": "Recibiste %s"})
+
+	formatter := NewFormatter(nil)
+	if err := formatter.Register(FormatMessage, neth.TopicERC20Transfer, "You received {{.Transfer.Value}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	if err := formatter.RegisterLocale("de", FormatMessage, neth.TopicERC20Transfer, "Du hast {{.Transfer.Value}} erhalten"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+	if err := formatter.RegisterLocale("es", FormatMessage, "111000", `{{.T "test.received" .Transfer.Value}}`); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	evt, _ := CreateTxLogEvent(goldenLog())
+	tests := []struct {
+		locale, expected string
+	}{
+		{"", "You received 1000000000000000000"},
+		{"de-AT", "Du hast 1000000000000000000 erhalten"},
+		{"fr", "You received 1000000000000000000"},
+		{"es", "You received 1000000000000000000"}, // The topic wins over the kind in any locale
+	}
+	for _, tt := range tests {
+		if text, _, _ := formatter.Localized(tt.locale).Format(FormatMessage, evt); text != tt.expected {
+			t.Errorf("Expected %q in %q, got %q", tt.expected, tt.locale, text)
+		}
+	}
+
+	// Templates translate catalog messages in the locale of the view
+	formatter.Unregister(FormatMessage, neth.TopicERC20Transfer)
+	if text, _, _ := formatter.Localized("es").Format(FormatMessage, evt); text != "Recibiste 1000000000000000000" {
+		t.Errorf("Expected the translated message, got %q", text)
+	}
+	if _, ok, _ := formatter.Format(FormatMessage, evt); ok {
+		t.Error("Expected no template without locale for the kind")
+	}
+}
@@ -0,0 +1,93 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+)
+
+// KindMap remaps the kinds of the package to the kinds of a deployment, e.g.
+// KindMap{KindTxLog: 31000} for relays whose policies reject the default kind
+type KindMap map[int]int
+
+// kindMap is the kind map consulted by the constructors, filters and parsers, in both directions
+var kindMap = struct {
+	sync.RWMutex
+	deployed map[int]int // Deployed kind by package kind
+	defaults map[int]int // Package kind by deployed kind
+}{}
+
+// SetKindMap sets the kinds the constructors create events with, the filters query and the parsers
+// accept in place of the kinds of the package. Kinds left out keep their value, a nil map restores
+// every default. Two kinds cannot be mapped to the same kind, nor to a kind of the package that
+// keeps its value.
+func SetKindMap(m KindMap) error {
+	deployed := make(map[int]int, len(m))
+	defaults := make(map[int]int, len(m))
+	for kind, mapped := range m {
+		if kind == mapped {
+			continue
+		}
+		if other, ok := defaults[mapped]; ok {
+			return fmt.Errorf("kinds %d and %d are both mapped to %d", other, kind, mapped)
+		}
+		deployed[kind] = mapped
+		defaults[mapped] = kind
+	}
+	for mapped, kind := range defaults {
+		if _, remapped := deployed[mapped]; IsKnownKind(mapped) && !remapped {
+			return fmt.Errorf("kind %d is mapped to %d, a kind of the package", kind, mapped)
+		}
+	}
+
+	kindMap.Lock()
+	defer kindMap.Unlock()
+
+	kindMap.deployed = deployed
+	kindMap.defaults = defaults
+	return nil
+}
+
+// CurrentKindMap returns a copy of the kind map
+func CurrentKindMap() KindMap {
+	kindMap.RLock()
+	defer kindMap.RUnlock()
+
+	m := make(KindMap, len(kindMap.deployed))
+	for kind, mapped := range kindMap.deployed {
+		m[kind] = mapped
+	}
+	return m
+}
+
+// MappedKind returns the kind a kind of the package is deployed under
+func MappedKind(kind int) int {
+	kindMap.RLock()
+	defer kindMap.RUnlock()
+
+	if mapped, ok := kindMap.deployed[kind]; ok {
+		return mapped
+	}
+	return kind
+}
+
+// MappedKinds returns the deployed kinds to filter events of kinds of the package with, e.g.
+// nostr.Filter{Kinds: MappedKinds(KindTxLog)}
+func MappedKinds(kinds ...int) []int {
+	mapped := make([]int, len(kinds))
+	for i, kind := range kinds {
+		mapped[i] = MappedKind(kind)
+	}
+	return mapped
+}
+
+// DefaultKind returns the kind of the package of an event kind, the parsers read the kind of
+// events through it. Events created before a kind was remapped keep being parsed.
+func DefaultKind(kind int) int {
+	kindMap.RLock()
+	defer kindMap.RUnlock()
+
+	if original, ok := kindMap.defaults[kind]; ok {
+		return original
+	}
+	return kind
+}
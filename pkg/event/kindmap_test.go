@@ -0,0 +1,62 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKindMap(t *testing.T) {
+	if err := SetKindMap(KindMap{KindTxLog: 31000, KindTxTransfer: 31001}); err != nil {
+		t.Fatalf("Failed to set kind map: %v", err)
+	}
+	t.Cleanup(func() { SetKindMap(nil) })
+
+	txLog, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if txLog.Kind != 31000 {
+		t.Errorf("Expected kind 31000, got %d", txLog.Kind)
+	}
+	transfer, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create transfer event: %v", err)
+	}
+	if transfer.Kind != 31001 || !IsTxTransferEvent(transfer) {
+		t.Errorf("Expected a transfer event of kind 31001, got %d", transfer.Kind)
+	}
+
+	// Parsers and filters use the deployed kinds
+	if parsed, err := ParseEvent(txLog); err != nil {
+		t.Errorf("Failed to parse remapped tx log: %v", err)
+	} else if _, ok := parsed.(*TxLogEvent); !ok {
+		t.Errorf("Expected a *TxLogEvent, got %T", parsed)
+	}
+	if got := MappedKinds(KindTxLog, KindTxPermit); fmt.Sprint(got) != fmt.Sprint([]int{31000, KindTxPermit}) {
+		t.Errorf("Expected kinds [31000 %d], got %v", KindTxPermit, got)
+	}
+	if DefaultKind(31001) != KindTxTransfer || DefaultKind(KindTxPermit) != KindTxPermit {
+		t.Error("Expected the deployed kinds to map back to the package kinds")
+	}
+	if got := CurrentKindMap(); len(got) != 2 || got[KindTxLog] != 31000 {
+		t.Errorf("Expected the kind map, got %v", got)
+	}
+
+	// Kinds cannot collide
+	if err := SetKindMap(KindMap{KindTxLog: 31000, KindTxTransfer: 31000}); err == nil {
+		t.Error("Expected two kinds mapped to the same kind to fail")
+	}
+	if err := SetKindMap(KindMap{KindTxLog: KindTxPermit}); err == nil {
+		t.Error("Expected a kind mapped to a kind of the package to fail")
+	}
+	if err := SetKindMap(KindMap{KindTxLog: KindTxTransfer, KindTxTransfer: KindTxLog}); err != nil {
+		t.Errorf("Expected swapped kinds to be accepted, got %v", err)
+	}
+
+	if err := SetKindMap(nil); err != nil {
+		t.Fatalf("Failed to reset kind map: %v", err)
+	}
+	if MappedKind(KindTxLog) != KindTxLog {
+		t.Error("Expected the default kinds after a reset")
+	}
+}
@@ -0,0 +1,173 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindCategory groups the kinds of the package by what they describe
+type KindCategory string
+
+const (
+	KindCategoryChain              KindCategory = "chain"               // On-chain activity and its finality
+	KindCategoryAccountAbstraction KindCategory = "account_abstraction" // ERC-4337 user operations
+	KindCategoryGroup              KindCategory = "group"               // NIP-29 groups
+	KindCategoryMessage            KindCategory = "message"             // Notes and reposts
+	KindCategoryOperations         KindCategory = "operations"          // Reports of the services
+	KindCategoryList               KindCategory = "list"                // NIP-51 style lists of the users
+)
+
+// ErrUnknownKind is returned when an event kind is not part of the registry
+var ErrUnknownKind = errors.New("unknown event kind")
+
+// KindSpec describes a kind used by the package
+type KindSpec struct {
+	Kind     int
+	Name     string
+	Category KindCategory
+	Parse    func(evt *nostr.Event) (any, error) // Nil for kinds without structured content
+}
+
+// kindRegistry holds every kind the package creates or parses, by kind
+var kindRegistry = make(map[int]KindSpec)
+
+func init() {
+	for _, spec := range []KindSpec{
+		{1, "text_note", KindCategoryMessage, nil},
+		{KindReaction, "reaction", KindCategoryMessage, nil},
+		{KindGenericRepost, "generic_repost", KindCategoryMessage, nil},
+		{KindFileMetadata, "file_metadata", KindCategoryMessage, nil},
+
+		{KindGroupAddUser, "group_add_user", KindCategoryGroup, parser(ParseAddUserEvent)},
+		{KindGroupRemoveUser, "group_remove_user", KindCategoryGroup, parser(ParseRemoveUserEvent)},
+		{KindGroupEditMetadata, "group_edit_metadata", KindCategoryGroup, parser(ParseEditMetadataEvent)},
+		{KindGroupAddAdmin, "group_add_admin", KindCategoryGroup, nil},
+		{KindGroupRemoveAdmin, "group_remove_admin", KindCategoryGroup, nil},
+		{KindGroupDeleteEvent, "group_delete_event", KindCategoryGroup, nil},
+		{KindGroupUpdateStatus, "group_update_status", KindCategoryGroup, nil},
+		{KindGroupCreate, "group_create", KindCategoryGroup, parser(ParseGroupEvent)},
+		{KindGroupDelete, "group_delete", KindCategoryGroup, nil},
+		{KindGroupJoinRequest, "group_join_request", KindCategoryGroup, nil},
+		{KindGroupMetadata, "group_metadata", KindCategoryGroup, parser(ParseGroupMetadataEvent)},
+		{KindGroupAdminsList, "group_admins_list", KindCategoryGroup, groupKindParser(parser(ParseGroupNameEvent), parser(ParseGroupAdminsListEvent))},
+		{KindGroupMembers, "group_members", KindCategoryGroup, groupKindParser(parser(ParseGroupAboutEvent), parser(ParseGroupMembersEvent))},
+		{KindGroupRoles, "group_roles", KindCategoryGroup, groupKindParser(parser(ParseGroupPictureEvent), parser(ParseGroupRolesEvent))},
+		{KindGroupAdmins, "group_admins", KindCategoryGroup, parser(ParseGroupAdminsEvent)},
+		{KindGroupModerators, "group_moderators", KindCategoryGroup, parser(ParseGroupModeratorsEvent)},
+		{KindGroupPrivate, "group_private", KindCategoryGroup, parser(ParseGroupPrivateEvent)},
+		{KindGroupClosed, "group_closed", KindCategoryGroup, parser(ParseGroupClosedEvent)},
+		{KindGroupCreated, "group_created", KindCategoryGroup, parser(ParseGroupCreatedEvent)},
+		{KindGroupUpdated, "group_updated", KindCategoryGroup, parser(ParseGroupUpdatedEvent)},
+
+		{KindGroupTokenGate, "group_token_gate", KindCategoryGroup, parser(ParseTokenGateEvent)},
+		{KindProposal, "proposal", KindCategoryGroup, parser(ParseProposalEvent)},
+		{KindVote, "vote", KindCategoryGroup, parser(ParseVoteEvent)},
+		{KindProposalExecution, "proposal_execution", KindCategoryGroup, parser(ParseProposalExecutionEvent)},
+
+		{KindTxTransferLegacy, "tx_transfer_legacy", KindCategoryChain, parser(ParseTxTransferEvent)},
+		{KindAllowanceState, "allowance_state", KindCategoryChain, parser(ParseAllowanceStateEvent)},
+		{KindSessionKey, "session_key", KindCategoryAccountAbstraction, parser(ParseSessionKeyEvent)},
+		{KindChainCheckpoint, "chain_checkpoint", KindCategoryChain, parser(ParseCheckpointEvent)},
+
+		{KindTxLog, "tx_log", KindCategoryChain, parser(ParseTxLogEvent)},
+		{EventUserOpKind, "user_op", KindCategoryAccountAbstraction, parser(ParseUserOpEvent)},
+		{KindTxApproval, "tx_approval", KindCategoryChain, parser(ParseTxApprovalEvent)},
+		{KindTxPermit, "tx_permit", KindCategoryChain, parser(ParseTxPermitEvent)},
+		{KindBridgeTransfer, "bridge_transfer", KindCategoryChain, parser(ParseBridgeTransferEvent)},
+		{KindTx, "tx", KindCategoryChain, parser(ParseTxEvent)},
+		{KindPendingTx, "pending_tx", KindCategoryChain, parser(ParsePendingTxEvent)},
+		{KindNativeTransfer, "native_transfer", KindCategoryChain, parser(ParseNativeTransferEvent)},
+		{KindUserOpSignatureRequest, "user_op_signature_request", KindCategoryAccountAbstraction, parser(ParseUserOpSignatureRequestEvent)},
+		{KindUserOpPartialSignature, "user_op_partial_signature", KindCategoryAccountAbstraction, parser(ParseUserOpPartialSignatureEvent)},
+		{KindGasEstimateRequest, "gas_estimate_request", KindCategoryAccountAbstraction, parser(ParseGasEstimateRequestEvent)},
+		{KindGasEstimateResponse, "gas_estimate_response", KindCategoryAccountAbstraction, parser(ParseGasEstimateResponseEvent)},
+		{KindTxLogAttestation, "tx_log_attestation", KindCategoryChain, parser(ParseTxLogAttestationEvent)},
+		{KindReconciliationReport, "reconciliation_report", KindCategoryOperations, parser(ParseReconciliationReportEvent)},
+		{KindTxTransfer, "tx_transfer", KindCategoryChain, parser(ParseTxTransferEvent)},
+		{KindTipTransfer, "tip_transfer", KindCategoryChain, parser(ParseTipTransferEvent)},
+		{KindEscrowProposal, "escrow_proposal", KindCategoryChain, parser(ParseEscrowProposalEvent)},
+		{KindEscrowFunding, "escrow_funding", KindCategoryChain, parser(ParseEscrowFundingEvent)},
+		{KindEscrowSignal, "escrow_signal", KindCategoryChain, parser(ParseEscrowSignalEvent)},
+		{KindAlert, "alert", KindCategoryOperations, parser(ParseAlertEvent)},
+		{KindAlertAcknowledgement, "alert_acknowledgement", KindCategoryOperations, parser(ParseAlertAcknowledgementEvent)},
+		{KindBalanceSnapshot, "balance_snapshot", KindCategoryChain, parser(ParseBalanceSnapshotEvent)},
+		{KindAdminCommand, "admin_command", KindCategoryOperations, parser(ParseAdminCommandEvent)},
+		{KindAuditRecord, "audit_record", KindCategoryOperations, parser(ParseAuditRecordEvent)},
+		{KindHeartbeat, "heartbeat", KindCategoryOperations, parser(ParseHeartbeatEvent)},
+		{KindLeaderClaim, "leader_claim", KindCategoryOperations, parser(ParseLeaderClaimEvent)},
+
+		{KindRelayList, "relay_list", KindCategoryList, parser(ParseRelayListEvent)},
+		{KindAddressBook, "address_book", KindCategoryList, parsePublicAddressBook},
+	} {
+		registerKind(spec)
+	}
+}
+
+// registerKind adds a kind to the registry, it panics when the kind or its name is already
+// taken so that a new kind can never silently reuse an existing one
+func registerKind(spec KindSpec) {
+	if existing, ok := kindRegistry[spec.Kind]; ok {
+		panic(fmt.Sprintf("event: kind %d of %s is already used by %s", spec.Kind, spec.Name, existing.Name))
+	}
+
+	for _, existing := range kindRegistry {
+		if existing.Name == spec.Name {
+			panic(fmt.Sprintf("event: kind name %s of %d is already used by %d", spec.Name, spec.Kind, existing.Kind))
+		}
+	}
+
+	kindRegistry[spec.Kind] = spec
+}
+
+// parser adapts a typed parser to the signature of KindSpec.Parse
+func parser[T any](parse func(evt *nostr.Event) (*T, error)) func(evt *nostr.Event) (any, error) {
+	return func(evt *nostr.Event) (any, error) {
+		return parse(evt)
+	}
+}
+
+// KindInfo returns the description of a kind used by the package
+func KindInfo(kind int) (KindSpec, bool) {
+	spec, ok := kindRegistry[kind]
+	return spec, ok
+}
+
+// IsKnownKind checks if a kind is used by the package
+func IsKnownKind(kind int) bool {
+	_, ok := kindRegistry[kind]
+	return ok
+}
+
+// Kinds returns the descriptions of every kind used by the package, ordered by kind
+func Kinds() []KindSpec {
+	specs := make([]KindSpec, 0, len(kindRegistry))
+	for _, spec := range kindRegistry {
+		specs = append(specs, spec)
+	}
+
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Kind < specs[j].Kind })
+
+	return specs
+}
+
+// ParseEvent parses an event with the parser of its kind, e.g. into a *TxLogEvent for a tx log
+// event
+func ParseEvent(evt *nostr.Event) (any, error) {
+	if evt == nil {
+		return nil, fmt.Errorf("%w: nil event", ErrMalformedEvent)
+	}
+
+	spec, ok := kindRegistry[DefaultKind(evt.Kind)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKind, evt.Kind)
+	}
+
+	if spec.Parse == nil {
+		return nil, fmt.Errorf("kind %d (%s) has no structured content", spec.Kind, spec.Name)
+	}
+
+	return spec.Parse(evt)
+}
@@ -0,0 +1,125 @@
+package event
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestKindRegistry(t *testing.T) {
+	spec, ok := KindInfo(KindTxLog)
+	if !ok {
+		t.Fatal("Expected the tx log kind to be registered")
+	}
+	if spec.Name != "tx_log" || spec.Category != KindCategoryChain {
+		t.Errorf("Expected tx_log in the chain category, got %s in %s", spec.Name, spec.Category)
+	}
+
+	for _, kind := range []int{1, KindGenericRepost, KindGroupJoinRequest, KindTxTransfer, KindChainCheckpoint, KindGroupUpdated, KindTxLogAttestation, KindReconciliationReport} {
+		if !IsKnownKind(kind) {
+			t.Errorf("Expected kind %d to be known", kind)
+		}
+	}
+	if IsKnownKind(30000) {
+		t.Error("Expected kind 30000 to be unknown")
+	}
+
+	kinds := Kinds()
+	for i := 1; i < len(kinds); i++ {
+		if kinds[i-1].Kind >= kinds[i].Kind {
+			t.Fatalf("Expected kinds in order, got %d before %d", kinds[i-1].Kind, kinds[i].Kind)
+		}
+	}
+}
+
+// standardKinds are kinds defined by NIPs that the custom kinds of the package must not reuse
+var standardKinds = map[int]string{
+	9734:  "NIP-57 zap request",
+	9735:  "NIP-57 zap receipt",
+	10002: "NIP-65 relay list",
+	30000: "NIP-51 follow sets",
+	30001: "NIP-51 generic lists",
+	30002: "NIP-51 relay sets",
+	30003: "NIP-51 bookmark sets",
+	30023: "NIP-23 long-form content",
+	30078: "NIP-78 application data",
+}
+
+func TestKindsAvoidStandardKinds(t *testing.T) {
+	for _, spec := range Kinds() {
+		if spec.Category == KindCategoryMessage || spec.Category == KindCategoryGroup {
+			continue // Standard kinds used for what they were defined for
+		}
+		if strings.HasSuffix(spec.Name, "_legacy") {
+			continue // Only parsed for compatibility
+		}
+		if spec.Kind == KindRelayList {
+			continue // Read to select the relays of the users, as NIP-65 defines
+		}
+
+		if nip, ok := standardKinds[spec.Kind]; ok {
+			t.Errorf("Expected %s not to reuse kind %d of the %s", spec.Name, spec.Kind, nip)
+		}
+	}
+}
+
+func TestRegisterKindCollision(t *testing.T) {
+	for _, spec := range []KindSpec{
+		{Kind: KindTxLog, Name: "other"},
+		{Kind: 1, Name: "other"},
+		{Kind: 123456789, Name: "tx_log"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected registering %d %s to panic", spec.Kind, spec.Name)
+				}
+			}()
+			registerKind(spec)
+		}()
+	}
+
+	if IsKnownKind(123456789) {
+		t.Error("Expected the colliding kind not to be registered")
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	evt, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	parsed, err := ParseEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	txLog, ok := parsed.(*TxLogEvent)
+	if !ok {
+		t.Fatalf("Expected *TxLogEvent, got %T", parsed)
+	}
+	if txLog.LogData.Hash != goldenLog().Hash {
+		t.Errorf("Expected hash %s, got %s", goldenLog().Hash, txLog.LogData.Hash)
+	}
+
+	if _, err := ParseEvent(&nostr.Event{Kind: 30000}); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("Expected ErrUnknownKind, got %v", err)
+	}
+
+	if _, err := ParseEvent(&nostr.Event{Kind: 1, Content: "gm"}); err == nil {
+		t.Error("Expected an error for a kind without structured content")
+	}
+
+	group, err := CreateAddUserEvent("community", "pubkey", "member")
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if !IsGroupEvent(group) {
+		t.Error("Expected the add user event to be a group event")
+	}
+	if IsGroupEvent(evt) {
+		t.Error("Expected the tx log event not to be a group event")
+	}
+}
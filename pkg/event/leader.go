@@ -0,0 +1,133 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindLeaderClaim = 31108 // Addressable, one event per group and bridge instance
+)
+
+// LeaderClaim is the claim of a bridge instance to publish for a group of instances watching the
+// same contracts. Claims are renewed before they expire, the instance holding the oldest
+// unexpired claim is the leader.
+type LeaderClaim struct {
+	Group     string    `json:"group"`
+	Instance  string    `json:"instance"`
+	Since     time.Time `json:"since"` // Start of the term, kept by renewals
+	ExpiresAt time.Time `json:"expires_at"`
+	At        time.Time `json:"at"`
+}
+
+// Expired returns whether the claim is expired at a time, released claims expire when they are
+// sent
+func (c LeaderClaim) Expired(at time.Time) bool {
+	return !at.Before(c.ExpiresAt)
+}
+
+// LeaderClaimID returns the d tag of the claims of an instance in a group
+func LeaderClaimID(group, instance string) string {
+	return group + ":" + instance
+}
+
+// CreateLeaderClaimEvent creates a leader claim (kind 31108), later claims of the same instance
+// replace earlier ones. Claims expire on relays supporting NIP-40.
+func CreateLeaderClaimEvent(claim LeaderClaim) (*nostr.Event, error) {
+	if claim.Group == "" || claim.Instance == "" {
+		return nil, fmt.Errorf("leader claim group and instance cannot be empty")
+	}
+	if claim.At.IsZero() {
+		claim.At = timeNow()
+	}
+	if claim.Since.IsZero() {
+		claim.Since = claim.At
+	}
+	if claim.ExpiresAt.Before(claim.At) {
+		return nil, fmt.Errorf("leader claim cannot expire before it is sent")
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(claim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal leader claim: %w", err)
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(claim.At.Unix()),
+		Kind:      MappedKind(KindLeaderClaim),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Group and instance
+	evt.Tags = append(evt.Tags, []string{"d", LeaderClaimID(claim.Group, claim.Instance)})
+
+	// Type tag
+	evt.Tags = append(evt.Tags, typeTag("leader_claim"))
+
+	// Relays can drop the claim once it is expired, released claims are kept until the next second
+	expiration := claim.ExpiresAt.Unix()
+	if expiration <= claim.At.Unix() {
+		expiration = claim.At.Unix() + 1
+	}
+	evt.Tags = append(evt.Tags, []string{"expiration", strconv.FormatInt(expiration, 10)}) // NIP-40
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a claim of bridge instance %s to lead group %s until %s", claim.Instance, claim.Group, claim.ExpiresAt.UTC().Format(time.RFC3339))
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseLeaderClaimEvent parses a leader claim
+func ParseLeaderClaimEvent(evt *nostr.Event) (*LeaderClaim, error) {
+	if DefaultKind(evt.Kind) != KindLeaderClaim {
+		return nil, fmt.Errorf("event is not a leader claim event (kind %d)", evt.Kind)
+	}
+
+	var claim LeaderClaim
+	if err := unmarshalContent(evt, &claim); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal leader claim event: %w", err)
+	}
+
+	return &claim, nil
+}
+
+// CurrentLeader returns the leader of a group from a list of events, or nil when no instance
+// holds an unexpired claim. Only the latest claim of each author and instance is kept, the oldest
+// term wins and ties go to the lowest instance, so that instances claiming at once agree.
+func CurrentLeader(events []*nostr.Event, group string) *LeaderClaim {
+	latest := make(map[string]*LeaderClaim)
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) != KindLeaderClaim {
+			continue
+		}
+		claim, err := ParseLeaderClaimEvent(evt)
+		if err != nil || claim.Group != group {
+			continue
+		}
+		key := evt.PubKey + ":" + claim.Instance
+		if existing, ok := latest[key]; !ok || claim.At.After(existing.At) {
+			latest[key] = claim
+		}
+	}
+
+	now := timeNow()
+	var leader *LeaderClaim
+	for _, claim := range latest {
+		if claim.Expired(now) {
+			continue
+		}
+		if leader == nil || claim.Since.Before(leader.Since) ||
+			(claim.Since.Equal(leader.Since) && claim.Instance < leader.Instance) {
+			leader = claim
+		}
+	}
+	return leader
+}
@@ -0,0 +1,63 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestCurrentLeader(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	defer func(clock func() time.Time) { timeNow = clock }(timeNow)
+	timeNow = func() time.Time { return now }
+
+	claim := func(group, instance string, since, at, expiresAt time.Time) *nostr.Event {
+		t.Helper()
+		evt, err := CreateLeaderClaimEvent(LeaderClaim{Group: group, Instance: instance, Since: since, At: at, ExpiresAt: expiresAt})
+		if err != nil {
+			t.Fatalf("Failed to create leader claim: %v", err)
+		}
+		return evt
+	}
+
+	first := claim("usdc", "b", now.Add(-time.Hour), now.Add(-10*time.Second), now.Add(time.Minute))
+	if first.Kind != KindLeaderClaim || first.Tags.GetD() != "usdc:b" {
+		t.Errorf("Expected a leader claim of usdc:b, got kind %d and d %q", first.Kind, first.Tags.GetD())
+	}
+	if tag := first.Tags.GetFirst([]string{"expiration", "1700000060"}); tag == nil {
+		t.Error("Expected the claim to expire on relays")
+	}
+
+	released := claim("usdc", "b", now.Add(-time.Hour), now, now)
+	tied := claim("usdc", "a", now.Add(-time.Minute), now, now.Add(time.Minute))
+	newer := claim("usdc", "c", now.Add(-time.Minute), now, now.Add(time.Minute))
+	expired := claim("usdc", "d", now.Add(-2*time.Hour), now.Add(-time.Minute), now)
+	other := claim("dai", "e", now.Add(-3*time.Hour), now, now.Add(time.Minute))
+
+	cases := []struct {
+		name     string
+		events   []*nostr.Event
+		expected string
+	}{
+		{"oldest term", []*nostr.Event{newer, first, tied}, "b"},
+		{"tie", []*nostr.Event{newer, tied}, "a"},
+		{"released", []*nostr.Event{first, released, newer}, "c"},
+		{"expired", []*nostr.Event{expired, other}, ""},
+		{"none", nil, ""},
+	}
+	for _, c := range cases {
+		leader := CurrentLeader(c.events, "usdc")
+		got := ""
+		if leader != nil {
+			got = leader.Instance
+		}
+		if got != c.expected {
+			t.Errorf("Expected %s leader %q, got %q", c.name, c.expected, got)
+		}
+	}
+
+	if _, err := CreateLeaderClaimEvent(LeaderClaim{Group: "usdc", Instance: "a", At: now, ExpiresAt: now.Add(-time.Second)}); err == nil {
+		t.Error("Expected a claim expiring before it is sent to fail")
+	}
+}
@@ -1,11 +1,18 @@
 package event
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/nbd-wtf/go-nostr"
 )
 
@@ -13,27 +20,88 @@ import (
 const (
 	KindTxLog = 111000
 
-	EventTypeTxLogCreated EventTypeTxLog = "tx_log_created"
+	EventTypeTxLogCreated  EventTypeTxLog = "tx_log_created"
+	EventTypeTxLogUpdated  EventTypeTxLog = "tx_log_updated"
+	EventTypeTxLogOrphaned EventTypeTxLog = "tx_log_orphaned"
+
+	TxLogStatusIncluded  TxLogStatus = "included"
+	TxLogStatusConfirmed TxLogStatus = "confirmed"
+	TxLogStatusSafe      TxLogStatus = "safe"
+	TxLogStatusFinalized TxLogStatus = "finalized"
+	TxLogStatusOrphaned  TxLogStatus = "orphaned" // The including block left the canonical chain
 )
 
 type EventTypeTxLog string
 
+// timeNow is the clock of the constructors stamping events with the current time, the golden
+// tests replace it to get reproducible events
+var timeNow = time.Now
+
+// TxLogStatus is how final the block including a log is
+type TxLogStatus string
+
+// txLogStatusOrder ranks the statuses from the least to the most final, orphaned logs are
+// final in the sense that they never come back
+var txLogStatusOrder = map[TxLogStatus]int{
+	"":                   0,
+	TxLogStatusIncluded:  1,
+	TxLogStatusConfirmed: 2,
+	TxLogStatusSafe:      3,
+	TxLogStatusFinalized: 4,
+	TxLogStatusOrphaned:  5,
+}
+
+// Before checks if the status is less final than another one
+func (s TxLogStatus) Before(other TxLogStatus) bool {
+	return txLogStatusOrder[s] < txLogStatusOrder[other]
+}
+
 // TxLogEvent represents a Nostr event for transaction logs
 type TxLogEvent struct {
-	LogData   neth.Log       `json:"log_data"`
-	EventType EventTypeTxLog `json:"event_type"`
-	Tags      []string       `json:"tags,omitempty"`
+	LogData   neth.Log           `json:"log_data"`
+	Proof     *neth.ReceiptProof `json:"proof,omitempty"` // Receipt proof of the log, when attached
+	EventType EventTypeTxLog     `json:"event_type"`
+	Tags      []string           `json:"tags,omitempty"`
+
+	// BlockNumber and Status are set when the block including the log is known
+	BlockNumber uint64      `json:"block_number,omitempty"`
+	Status      TxLogStatus `json:"status,omitempty"`
+
+	// RawContent holds the original content when the event was parsed, fields unknown to
+	// this version of the package are merged back when the event is marshaled again
+	RawContent json.RawMessage `json:"-"`
+}
+
+var txLogEventFields = jsonFieldNames(reflect.TypeOf(TxLogEvent{}))
+
+// MarshalJSON converts the event to JSON, preserving unknown fields from RawContent
+func (t TxLogEvent) MarshalJSON() ([]byte, error) {
+	type Alias TxLogEvent
+	content, err := json.Marshal(Alias(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeUnknownFields(content, t.RawContent, txLogEventFields)
 }
 
 // CreateTxLogEvent creates a new Nostr event for a transaction log
-func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
+func CreateTxLogEvent(log neth.Log, opts ...LogOption) (*nostr.Event, error) {
+	options := newLogOptions(opts)
+
 	// Create the event data
 	eventData := TxLogEvent{
 		LogData:   log,
+		Proof:     options.receiptProof,
 		EventType: EventTypeTxLogCreated,
 		Tags:      []string{"tx_log", "evm", log.ChainID},
 	}
 
+	if options.blockNumber > 0 {
+		eventData.BlockNumber = options.blockNumber
+		eventData.Status = TxLogStatusIncluded
+	}
+
 	// Marshal the event data
 	content, err := json.Marshal(eventData)
 	if err != nil {
@@ -44,7 +112,7 @@ func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
 		CreatedAt: nostr.Timestamp(log.CreatedAt.Unix()),
-		Kind:      KindTxLog, // Custom kind for transaction logs
+		Kind:      MappedKind(KindTxLog), // Custom kind for transaction logs
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -53,7 +121,7 @@ func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"d", log.Hash}) // Identifier
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "tx_log"})    // Type
+	evt.Tags = append(evt.Tags, typeTag("tx_log"))          // Type
 	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
 
 	// Chain-specific tag
@@ -69,23 +137,64 @@ func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
 	// Amount/value tag for filtering
 	evt.Tags = append(evt.Tags, []string{"amount", log.Value.String()})
 
+	if options.sortableAmount {
+		sortable, err := EncodeSortableAmount(log.Value)
+		if err != nil {
+			return nil, err
+		}
+		evt.Tags = append(evt.Tags, []string{"amount_sortable", sortable})
+	}
+
 	// Topic tag
-	evt.Tags = append(evt.Tags, []string{"t", log.Topic})
+	evt.Tags = append(evt.Tags, typeTag(log.Topic))
 
 	// Contract address tag
-	evt.Tags = append(evt.Tags, []string{"t", log.To})
+	evt.Tags = append(evt.Tags, typeTag(log.To))
+
+	// Dust tag
+	if options.dust != nil && options.dust.IsDust(log) {
+		evt.Tags = append(evt.Tags, typeTag("dust"))
+	}
 
 	// Flatten data into tags
 	dataTags := []nostr.Tag{}
 	if log.Data != nil {
-		dataTags = flattenDataToTags(*log.Data)
+		dataTags = flattenDataToTags(*log.Data, options.flattenPolicy, options.sanitizePolicy)
 		evt.Tags = append(evt.Tags, dataTags...)
 	}
 
+	// Block and status tags when the including block is known
+	if eventData.BlockNumber > 0 {
+		evt.Tags = append(evt.Tags, []string{"block", strconv.FormatUint(eventData.BlockNumber, 10)})
+		evt.Tags = append(evt.Tags, []string{"status", string(eventData.Status)})
+	}
+
+	// Block hash tag when the log comes with a receipt proof
+	if options.receiptProof != nil {
+		evt.Tags = append(evt.Tags, []string{"block_hash", options.receiptProof.BlockHash().Hex()})
+	}
+
+	// Contract-specific tags from the topic registry
+	topicName := ""
+	if options.topicRegistry != nil {
+		name, topicTags, err := options.topicRegistry.tags(log, options.flattenPolicy, options.sanitizePolicy)
+		if err != nil {
+			return nil, err
+		}
+		topicName = name
+		evt.Tags = appendUniqueTags(evt.Tags, topicTags...)
+	}
+
 	// Alt tag
-	alt := fmt.Sprintf("This is an evm transaction log for topic %s on chain %s", log.Topic, log.ChainID)
+	alt := localize(MessageTxLogAlt, log.Topic, log.ChainID)
+	if topicName != "" {
+		alt += localize(MessageTxLogAltEvent, topicName)
+	}
+	if log.Value != nil && log.Value.Sign() > 0 {
+		alt += localize(MessageTxLogAltValue, neth.FormatEther(log.Value))
+	}
 	if len(dataTags) > 0 {
-		alt += "\n Data:"
+		alt += localize(MessageAltData)
 	}
 	for _, tag := range dataTags {
 		alt += fmt.Sprintf("\n %s: %s", tag[0], tag[1])
@@ -93,19 +202,206 @@ func CreateTxLogEvent(log neth.Log) (*nostr.Event, error) {
 
 	evt.Tags = append(evt.Tags, []string{"alt", alt})
 
-	return evt, nil
+	return formatSanitizedAlt(evt, options.sanitizePolicy)
 }
 
 // ParseTxLogEvent parses a Nostr event back into a TxLogEvent
 func ParseTxLogEvent(evt *nostr.Event) (*TxLogEvent, error) {
 	var txLogEvent TxLogEvent
-	err := json.Unmarshal([]byte(evt.Content), &txLogEvent)
+	err := unmarshalContent(evt, &txLogEvent)
 	if err != nil {
 		return nil, err
 	}
+	txLogEvent.RawContent = json.RawMessage(evt.Content)
 	return &txLogEvent, nil
 }
 
+// UpdateTxLogStatus creates an update event for a tx log event with a new status. The tags of
+// the original event are carried over and the update references it with an e tag.
+func UpdateTxLogStatus(evt *nostr.Event, status TxLogStatus, opts ...ReferenceOption) (*nostr.Event, error) {
+	return updateTxLogEvent(evt, status, EventTypeTxLogUpdated, newReferenceOptions(opts))
+}
+
+// OrphanTxLogEvent creates a rollback event for a tx log event whose block was removed from
+// the canonical chain by a reorg
+func OrphanTxLogEvent(evt *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	return updateTxLogEvent(evt, TxLogStatusOrphaned, EventTypeTxLogOrphaned, newReferenceOptions(opts))
+}
+
+// updateTxLogEvent creates an update event for a tx log event with a new status and event type
+func updateTxLogEvent(evt *nostr.Event, status TxLogStatus, eventType EventTypeTxLog, references *referenceOptions) (*nostr.Event, error) {
+	txLogEvent, err := ParseTxLogEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	txLogEvent.Status = status
+	txLogEvent.EventType = eventType
+
+	// Marshal the event data, fields unknown to this version are carried over
+	content, err := json.Marshal(txLogEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	update := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      evt.Kind,
+		Tags:      make([]nostr.Tag, 0, len(evt.Tags)+3),
+		Content:   string(content),
+	}
+
+	// Carry over the tags of the original event, except the ones describing this update
+	for _, tag := range evt.Tags {
+		if len(tag) >= 1 && (tag[0] == "status" || tag[0] == "e" || tag[0] == "alt") {
+			continue
+		}
+		if isTypeTag(tag, "update") {
+			continue
+		}
+		update.Tags = append(update.Tags, tag)
+	}
+
+	// Update tags
+	update.Tags = append(update.Tags, typeTag("update"))
+	update.Tags = append(update.Tags, []string{"status", string(status)})
+	update.Tags = append(update.Tags, hintedTag("e", evt.ID, references.eventRelay(evt))) // Reference to the original event
+
+	// Alt tag
+	alt := localize(MessageTxLogStatusAlt, txLogEvent.LogData.ChainID, status)
+	if txLogEvent.BlockNumber > 0 {
+		alt += localize(MessageTxLogStatusAltAt, txLogEvent.BlockNumber)
+	}
+
+	update.Tags = append(update.Tags, []string{"alt", alt})
+
+	return update, nil
+}
+
+// VerifyReceiptProof verifies the receipt proof attached to a tx log event against a block hash
+// obtained from a trusted source, so the log does not have to be trusted to the publisher. The
+// proven transaction must be the transaction of the log, in the block of the event when it is
+// known. The proven log must be emitted by the contract of the log data, with its topic, and its
+// indexed topics followed by its data words must be the from, to and value of the log data, or
+// the owner, spender and value of approvals.
+func VerifyReceiptProof(evt *nostr.Event, blockHash common.Hash) error {
+	txLogEvent, err := ParseTxLogEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	if txLogEvent.Proof == nil {
+		return fmt.Errorf("event %s has no receipt proof", evt.ID)
+	}
+
+	receiptLog, err := txLogEvent.Proof.Verify(blockHash)
+	if err != nil {
+		return err
+	}
+
+	if txLogEvent.BlockNumber > 0 {
+		number, err := txLogEvent.Proof.BlockNumber()
+		if err != nil {
+			return err
+		}
+		if number != txLogEvent.BlockNumber {
+			return fmt.Errorf("proven log is in block %d, not %d", number, txLogEvent.BlockNumber)
+		}
+	}
+
+	return verifyReceiptLog(txLogEvent.LogData, txLogEvent.Proof, receiptLog)
+}
+
+// parseWord parses a decimal or 0x prefixed hex uint256
+func parseWord(value string) (*big.Int, bool) {
+	n, ok := new(big.Int).SetString(value, 10)
+	if hex, found := strings.CutPrefix(value, "0x"); found {
+		n, ok = new(big.Int).SetString(hex, 16)
+	}
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, false
+	}
+	return n, true
+}
+
+// provenDataKeys are the data keys of the ABI arguments of a topic in order, for the topics whose
+// arguments are not from, to and value
+var provenDataKeys = map[string][]string{
+	strings.ToLower(neth.TopicERC20Approval): {neth.DataKeyOwner, neth.DataKeySpender, neth.DataKeyValue},
+}
+
+// verifyReceiptLog checks that a proven receipt log is the log of the log data, and that the
+// receipt belongs to the transaction of the log
+func verifyReceiptLog(log neth.Log, proof *neth.ReceiptProof, receiptLog *neth.ReceiptLog) error {
+	if txHash := proof.TxHash(); !strings.EqualFold(txHash.Hex(), log.TxHash) {
+		return fmt.Errorf("proven log is from transaction %s at index %d, not %s", txHash.Hex(), proof.TxIndex, log.TxHash)
+	}
+
+	if !common.IsHexAddress(log.To) || receiptLog.Address != common.HexToAddress(log.To) {
+		return fmt.Errorf("proven log is emitted by %s, not %s", receiptLog.Address.Hex(), log.To)
+	}
+
+	if len(receiptLog.Topics) == 0 || !strings.EqualFold(receiptLog.Topics[0].Hex(), log.Topic) {
+		return fmt.Errorf("proven log does not have topic %s", log.Topic)
+	}
+
+	if len(receiptLog.Data)%32 != 0 {
+		return fmt.Errorf("proven log data is not made of 32 byte words")
+	}
+
+	// Indexed arguments come first, then the ones in the data
+	words := make([]common.Hash, 0, len(receiptLog.Topics)-1+len(receiptLog.Data)/32)
+	words = append(words, receiptLog.Topics[1:]...)
+	for i := 0; i < len(receiptLog.Data); i += 32 {
+		words = append(words, common.BytesToHash(receiptLog.Data[i:i+32]))
+	}
+
+	values := make(map[string]interface{})
+	if log.Data != nil {
+		decoder := json.NewDecoder(bytes.NewReader(*log.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return fmt.Errorf("invalid log data: %w", err)
+		}
+	}
+	if _, ok := values[neth.DataKeyValue]; !ok && log.Value != nil {
+		values[neth.DataKeyValue] = log.Value.String()
+	}
+
+	keys, ok := provenDataKeys[strings.ToLower(log.Topic)]
+	if !ok {
+		keys = []string{neth.DataKeyFrom, neth.DataKeyTo, neth.DataKeyValue}
+	}
+
+	if len(words) != len(keys) {
+		return fmt.Errorf("proven log has %d arguments, expected %d", len(words), len(keys))
+	}
+
+	for i, key := range keys {
+		value, ok := scalarString(values[key])
+		if !ok {
+			return fmt.Errorf("log data has no %s", key)
+		}
+
+		var expected common.Hash
+		if common.IsHexAddress(value) {
+			expected = common.BytesToHash(common.HexToAddress(value).Bytes())
+		} else if n, ok := parseWord(value); ok {
+			expected = common.BigToHash(n)
+		} else {
+			return fmt.Errorf("invalid %s in log data: %s", key, value)
+		}
+
+		if words[i] != expected {
+			return fmt.Errorf("proven log has %s %s, not %s", key, words[i].Hex(), value)
+		}
+	}
+
+	return nil
+}
+
 // isEthereumAddress checks if a string looks like an Ethereum address
 func isEthereumAddress(value string) bool {
 	// Ethereum addresses are 42 characters long (0x + 40 hex chars)
@@ -128,46 +424,12 @@ func isEthereumAddress(value string) bool {
 	return true
 }
 
-// flattenDataToTags flattens the data map into tags, using "p" for address values
-// The data is dynamic and can be any event, but will always contain "topic" which is a hash
-func flattenDataToTags(b []byte) []nostr.Tag {
-	var tags []nostr.Tag
-
-	var data map[string]interface{}
-	err := json.Unmarshal(b, &data)
-	if err != nil {
-		return tags
-	}
-
-	for key, value := range data {
-		if strValue, ok := value.(string); ok {
-			if isEthereumAddress(strValue) {
-				// Use "p" tag for 0x addresses
-				tags = append(tags, []string{"p", strValue})
-			} else if key == "topic" {
-				// Topic is always a hash, keep as regular tag for better indexing
-				tags = append(tags, []string{key, strValue})
-			} else {
-				// Use the key as tag name for other string values
-				tags = append(tags, []string{key, strValue})
-			}
-		} else if intValue, ok := value.(int64); ok {
-			// Convert int64 to string
-			tags = append(tags, []string{key, fmt.Sprintf("%d", intValue)})
-		} else if floatValue, ok := value.(float64); ok {
-			// Convert float64 to string, but check if it's actually an integer
-			if floatValue == float64(int64(floatValue)) {
-				// It's an integer, format without decimal places
-				tags = append(tags, []string{key, fmt.Sprintf("%d", int64(floatValue))})
-			} else {
-				// It's a real float, format with decimal places
-				tags = append(tags, []string{key, fmt.Sprintf("%f", floatValue)})
-			}
-		} else if boolValue, ok := value.(bool); ok {
-			// Convert bool to string
-			tags = append(tags, []string{key, fmt.Sprintf("%t", boolValue)})
+// appendUniqueTags appends the tags that are not already present
+func appendUniqueTags(tags nostr.Tags, newTags ...nostr.Tag) nostr.Tags {
+	for _, tag := range newTags {
+		if !slices.ContainsFunc(tags, func(existing nostr.Tag) bool { return slices.Equal(existing, tag) }) {
+			tags = append(tags, tag)
 		}
 	}
-
 	return tags
 }
@@ -2,11 +2,14 @@ package event
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/nbd-wtf/go-nostr"
 )
 
@@ -343,3 +346,224 @@ func TestDynamicDataFlattening(t *testing.T) {
 		})
 	}
 }
+
+func TestSortableAmount(t *testing.T) {
+	small, err := EncodeSortableAmount(big.NewInt(9))
+	if err != nil {
+		t.Fatalf("Failed to encode amount: %v", err)
+	}
+	large, err := EncodeSortableAmount(big.NewInt(1000000000000000000))
+	if err != nil {
+		t.Fatalf("Failed to encode amount: %v", err)
+	}
+
+	if len(small) != SortableAmountWidth || len(large) != SortableAmountWidth {
+		t.Errorf("Expected width %d, got %d and %d", SortableAmountWidth, len(small), len(large))
+	}
+	if small >= large {
+		t.Errorf("Expected %s to sort before %s", small, large)
+	}
+
+	decoded, err := DecodeSortableAmount(large)
+	if err != nil {
+		t.Fatalf("Failed to decode amount: %v", err)
+	}
+	if decoded.Cmp(big.NewInt(1000000000000000000)) != 0 {
+		t.Errorf("Expected 1000000000000000000, got %s", decoded.String())
+	}
+
+	if _, err := EncodeSortableAmount(big.NewInt(-1)); err == nil {
+		t.Error("Expected error for negative amount")
+	}
+
+	logData := neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    "0xabcdef1234567890",
+		ChainID:   "1",
+		CreatedAt: time.Now(),
+		Value:     big.NewInt(42),
+	}
+
+	event, err := CreateTxLogEvent(logData, WithSortableAmount())
+	if err != nil {
+		t.Fatalf("Failed to create Nostr event: %v", err)
+	}
+
+	amount, err := GetSortableAmountFromEvent(event)
+	if err != nil {
+		t.Fatalf("Failed to get sortable amount: %v", err)
+	}
+	if amount.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("Expected 42, got %s", amount.String())
+	}
+}
+
+func TestTxLogEventPreservesUnknownFields(t *testing.T) {
+	nostrEvent := &nostr.Event{
+		Kind:    KindTxLog,
+		Content: `{"log_data":{"hash":"0x1234","value":1},"event_type":"tx_log_created","block_number":123}`,
+	}
+
+	parsedEvent, err := ParseTxLogEvent(nostrEvent)
+	if err != nil {
+		t.Fatalf("Failed to parse Nostr event: %v", err)
+	}
+
+	content, err := json.Marshal(parsedEvent)
+	if err != nil {
+		t.Fatalf("Failed to marshal event data: %v", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		t.Fatalf("Failed to unmarshal content: %v", err)
+	}
+
+	if string(fields["block_number"]) != "123" {
+		t.Errorf("Expected block_number to be preserved, got %s", string(content))
+	}
+	if _, ok := fields["log_data"]; !ok {
+		t.Errorf("Expected log_data in content, got %s", string(content))
+	}
+}
+
+// proveReceiptLog builds a receipt proof of block 12345 holding a single transaction whose
+// receipt has the log
+func proveReceiptLog(t *testing.T, log neth.ReceiptLog) *neth.ReceiptProof {
+	t.Helper()
+
+	tx, err := rlp.EncodeToBytes([]any{uint64(0), uint64(1), uint64(50000), log.Address, uint64(0), []byte{}})
+	if err != nil {
+		t.Fatalf("Failed to encode transaction: %v", err)
+	}
+
+	receipt, err := rlp.EncodeToBytes([]any{
+		uint64(1),         // status
+		uint64(21000),     // cumulative gas used
+		make([]byte, 256), // bloom
+		[]any{[]any{log.Address, log.Topics, log.Data}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode receipt: %v", err)
+	}
+
+	header, err := rlp.EncodeToBytes([]any{
+		common.Hash{}, common.Hash{}, common.Address{}, common.Hash{},
+		neth.DeriveRoot([][]byte{tx}),
+		neth.DeriveRoot([][]byte{receipt}),
+		make([]byte, 256), uint64(0), uint64(12345),
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+
+	proof, err := neth.BuildReceiptProof(header, [][]byte{tx}, [][]byte{receipt}, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to build proof: %v", err)
+	}
+	return proof
+}
+
+func TestVerifyReceiptProof(t *testing.T) {
+	token := common.HexToAddress("0x5615dEB798BB3E4dFa0139dFa1b3D433Cc23b72f")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	proof := proveReceiptLog(t, neth.ReceiptLog{
+		Address: token,
+		Topics:  []common.Hash{common.HexToHash(neth.TopicERC20Transfer), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(big.NewInt(1000)).Bytes(),
+	})
+
+	txHash := proof.TxHash().Hex()
+
+	transfer := func(contract, topic, value string, opts ...LogOption) *nostr.Event {
+		data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":%q}`, from.Hex(), to.Hex(), value))
+		evt, err := CreateTxLogEvent(neth.Log{
+			Hash:      "0x1234567890abcdef",
+			TxHash:    txHash,
+			ChainID:   "1",
+			Topic:     topic,
+			CreatedAt: time.Now(),
+			To:        contract,
+			Value:     big.NewInt(0),
+			Data:      &data,
+		}, append([]LogOption{WithReceiptProof(proof)}, opts...)...)
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		return evt
+	}
+
+	evt := transfer(token.Hex(), neth.TopicERC20Transfer, "1000")
+
+	if tag := evt.Tags.Find("block_hash"); tag == nil || tag[1] != proof.BlockHash().Hex() {
+		t.Errorf("Expected block_hash tag %s, got %v", proof.BlockHash().Hex(), tag)
+	}
+
+	if err := VerifyReceiptProof(evt, proof.BlockHash()); err != nil {
+		t.Errorf("Expected proof to verify, got %v", err)
+	}
+
+	if err := VerifyReceiptProof(evt, common.Hash{}); err == nil {
+		t.Error("Expected proof against another block hash to fail")
+	}
+
+	if err := VerifyReceiptProof(transfer(token.Hex(), neth.TopicERC20Transfer, "1000", WithBlockNumber(12345)), proof.BlockHash()); err != nil {
+		t.Errorf("Expected proof in the block of the event to verify, got %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		evt  *nostr.Event
+	}{
+		{"another topic", transfer(token.Hex(), neth.TopicERC20Approval, "1000")},
+		{"another contract", transfer("0x0000000000000000000000000000000000000001", neth.TopicERC20Transfer, "1000")},
+		{"another value", transfer(token.Hex(), neth.TopicERC20Transfer, "1000000")},
+		{"another block", transfer(token.Hex(), neth.TopicERC20Transfer, "1000", WithBlockNumber(12346))},
+	}
+
+	for _, tc := range testCases {
+		if err := VerifyReceiptProof(tc.evt, proof.BlockHash()); err == nil {
+			t.Errorf("Expected proof of a log with %s to fail", tc.name)
+		}
+	}
+
+	// The parties are compared with the indexed topics
+	data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"1000"}`, to.Hex(), from.Hex()))
+	swapped, err := CreateTxLogEvent(neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    txHash,
+		ChainID:   "1",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Now(),
+		To:        token.Hex(),
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}, WithReceiptProof(proof))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := VerifyReceiptProof(swapped, proof.BlockHash()); err == nil {
+		t.Error("Expected proof of a log with swapped parties to fail")
+	}
+
+	// The receipt is bound to the transaction of the log
+	data = json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"1000"}`, from.Hex(), to.Hex()))
+	otherTx, err := CreateTxLogEvent(neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    "0xabcdef1234567890",
+		ChainID:   "1",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Now(),
+		To:        token.Hex(),
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}, WithReceiptProof(proof))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if err := VerifyReceiptProof(otherTx, proof.BlockHash()); err == nil {
+		t.Error("Expected proof of a log of another transaction to fail")
+	}
+}
@@ -2,10 +2,12 @@ package event
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 )
 
 const (
@@ -13,12 +15,13 @@ const (
 )
 
 // CreateMessageEvent creates a new Nostr event for a simple text message
-func CreateMessageEvent(content string, group *string) (*nostr.Event, error) {
+func CreateMessageEvent(content string, group *string, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
 
 	// Create the Nostr event with plain text content
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
 		Kind:      1, // Standard kind for text messages
 		Tags:      make([]nostr.Tag, 0),
 		Content:   content, // Plain text content
@@ -27,24 +30,30 @@ func CreateMessageEvent(content string, group *string) (*nostr.Event, error) {
 	// Add tags for better indexing and filtering
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "message"}) // Type
-	evt.Tags = append(evt.Tags, []string{"t", "text"})    // Content type
+	evt.Tags = append(evt.Tags, typeTag("message")) // Type
+	evt.Tags = append(evt.Tags, typeTag("text"))    // Content type
 
 	// Group tag for filtering by group (NIP-29 compliant)
 	if group != nil {
 		evt.Tags = append(evt.Tags, []string{"h", *group}) // Group ID
 	}
 
+	// Mentions (NIP-27)
+	if err := addMentions(evt, references); err != nil {
+		return nil, err
+	}
+
 	return evt, nil
 }
 
 // UpdateMessageEvent creates a Nostr event for updating a message
-func UpdateMessageEvent(content string, group *string, originalEvent *nostr.Event) (*nostr.Event, error) {
+func UpdateMessageEvent(content string, group *string, originalEvent *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
 
 	// Create the Nostr event with plain text content
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
 		Kind:      1, // Standard kind for text messages
 		Tags:      make([]nostr.Tag, 0),
 		Content:   content, // Plain text content
@@ -53,21 +62,26 @@ func UpdateMessageEvent(content string, group *string, originalEvent *nostr.Even
 	// Add reference to original event if provided (NIP-10 compliant)
 	if originalEvent != nil {
 		// Use marked e tag format: [event-id, relay-url, marker, pubkey]
-		evt.Tags = append(evt.Tags, []string{"e", originalEvent.ID, "", "reply", originalEvent.PubKey})
+		evt.Tags = append(evt.Tags, []string{"e", originalEvent.ID, references.eventRelay(originalEvent), "reply", originalEvent.PubKey})
 	}
 
 	// Add tags for better indexing and filtering
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "message"}) // Type
-	evt.Tags = append(evt.Tags, []string{"t", "text"})    // Content type
-	evt.Tags = append(evt.Tags, []string{"t", "update"})  // Update marker
+	evt.Tags = append(evt.Tags, typeTag("message")) // Type
+	evt.Tags = append(evt.Tags, typeTag("text"))    // Content type
+	evt.Tags = append(evt.Tags, typeTag("update"))  // Update marker
 
 	// Group tag for filtering by group (NIP-29 compliant)
 	if group != nil {
 		evt.Tags = append(evt.Tags, []string{"h", *group}) // Group ID
 	}
 
+	// Mentions (NIP-27)
+	if err := addMentions(evt, references); err != nil {
+		return nil, err
+	}
+
 	return evt, nil
 }
 
@@ -140,12 +154,13 @@ func FilterEventsByTxHash(events []*nostr.Event, txHash string) []*nostr.Event {
 }
 
 // CreateReplyEvent creates a NIP-10 compliant reply event
-func CreateReplyEvent(content string, group *string, replyTo *nostr.Event) (*nostr.Event, error) {
+func CreateReplyEvent(content string, group *string, replyTo *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
 
 	// Create the Nostr event with plain text content
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
 		Kind:      1, // Standard kind for text messages
 		Tags:      make([]nostr.Tag, 0),
 		Content:   content, // Plain text content
@@ -157,32 +172,37 @@ func CreateReplyEvent(content string, group *string, replyTo *nostr.Event) (*nos
 		rootEvent := findRootEvent(replyTo)
 
 		// Add reply marker to the immediate parent
-		evt.Tags = append(evt.Tags, []string{"e", replyTo.ID, "", "reply", replyTo.PubKey})
+		evt.Tags = append(evt.Tags, []string{"e", replyTo.ID, references.eventRelay(replyTo), "reply", replyTo.PubKey})
 
 		// Add root marker if this is not the root
 		if rootEvent.ID != replyTo.ID {
-			evt.Tags = append(evt.Tags, []string{"e", rootEvent.ID, "", "root", rootEvent.PubKey})
+			evt.Tags = append(evt.Tags, []string{"e", rootEvent.ID, references.eventRelay(rootEvent), "root", rootEvent.PubKey})
 		}
 
 		// Add NIP-10 compliant p tags for participant tracking
 		participants := getParticipantsFromEvent(replyTo)
 		for participant := range participants {
-			evt.Tags = append(evt.Tags, []string{"p", participant})
+			evt.Tags = append(evt.Tags, hintedTag("p", participant, references.pubKeyRelay(participant)))
 		}
 	}
 
 	// Add tags for better indexing and filtering
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "message"}) // Type
-	evt.Tags = append(evt.Tags, []string{"t", "text"})    // Content type
-	evt.Tags = append(evt.Tags, []string{"t", "reply"})   // Reply marker
+	evt.Tags = append(evt.Tags, typeTag("message")) // Type
+	evt.Tags = append(evt.Tags, typeTag("text"))    // Content type
+	evt.Tags = append(evt.Tags, typeTag("reply"))   // Reply marker
 
 	// Group tag for filtering by group (NIP-29 compliant)
 	if group != nil {
 		evt.Tags = append(evt.Tags, []string{"h", *group}) // Group ID
 	}
 
+	// Mentions (NIP-27)
+	if err := addMentions(evt, references); err != nil {
+		return nil, err
+	}
+
 	return evt, nil
 }
 
@@ -252,12 +272,15 @@ func GetParticipantsFromEvent(evt *nostr.Event) []string {
 	return participants
 }
 
-// CreateQuoteRepostEvent creates a NIP-18 compliant quote event
-func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.Event, relayURL string) (*nostr.Event, error) {
+// CreateQuoteRepostEvent creates a NIP-18 compliant quote event, the relay hint options are used
+// when relayURL is empty
+func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.Event, relayURL string, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
 	// Create the Nostr event with plain text content
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
 		Kind:      1, // Standard kind for text messages
 		Tags:      make([]nostr.Tag, 0),
 		Content:   content, // Plain text content
@@ -265,11 +288,15 @@ func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.
 
 	// Add NIP-18 compliant q tag for quote
 	if repostedEvent != nil {
+		if relayURL == "" {
+			relayURL = references.eventRelay(repostedEvent)
+		}
+
 		evt.Tags = append(evt.Tags, []string{"k", strconv.Itoa(repostedEvent.Kind)})
 
 		evt.Tags = append(evt.Tags, []string{"q", repostedEvent.ID, relayURL, repostedEvent.PubKey})
 		// Add p tag for mentioned event author (NIP-10 compliant)
-		evt.Tags = append(evt.Tags, []string{"p", repostedEvent.PubKey})
+		evt.Tags = append(evt.Tags, hintedTag("p", repostedEvent.PubKey, references.pubKeyRelay(repostedEvent.PubKey)))
 
 		// Encode the reposted event ID using NIP-19 nevent format
 		nevent, err := EncodeEventIDToNevent(repostedEvent.ID, relayURL, repostedEvent.PubKey, repostedEvent.Kind)
@@ -282,15 +309,20 @@ func CreateQuoteRepostEvent(content string, group *string, repostedEvent *nostr.
 	// Add tags for better indexing and filtering
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "message"}) // Type
-	evt.Tags = append(evt.Tags, []string{"t", "text"})    // Content type
-	evt.Tags = append(evt.Tags, []string{"t", "mention"}) // Mention marker
+	evt.Tags = append(evt.Tags, typeTag("message")) // Type
+	evt.Tags = append(evt.Tags, typeTag("text"))    // Content type
+	evt.Tags = append(evt.Tags, typeTag("mention")) // Mention marker
 
 	// Group tag for filtering by group (NIP-29 compliant)
 	if group != nil {
 		evt.Tags = append(evt.Tags, []string{"h", *group}) // Group ID
 	}
 
+	// Mentions (NIP-27)
+	if err := addMentions(evt, references); err != nil {
+		return nil, err
+	}
+
 	return evt, nil
 }
 
@@ -327,3 +359,49 @@ func IsRootEvent(evt *nostr.Event) bool {
 	}
 	return !hasReplyMarker
 }
+
+// addMentions adds a nostr:npub URI to the content and a p tag for every mentioned public key,
+// URIs already in the content and existing p tags are not repeated
+func addMentions(evt *nostr.Event, references *referenceOptions) error {
+	mentioned := GetMentionedPubkeys(evt)
+
+	for _, pubkey := range references.mentions {
+		if !nostr.IsValidPublicKey(pubkey) {
+			return fmt.Errorf("invalid mentioned public key: %s", pubkey)
+		}
+
+		if !slices.Contains(mentioned, pubkey) {
+			npub, err := nip19.EncodePublicKey(pubkey)
+			if err != nil {
+				return fmt.Errorf("failed to encode mentioned public key: %w", err)
+			}
+
+			if evt.Content != "" && !strings.HasSuffix(evt.Content, " ") && !strings.HasSuffix(evt.Content, "\n") {
+				evt.Content += " "
+			}
+			evt.Content += "nostr:" + npub
+			mentioned = append(mentioned, pubkey)
+		}
+
+		if evt.Tags.FindWithValue("p", pubkey) == nil {
+			evt.Tags = append(evt.Tags, hintedTag("p", pubkey, references.pubKeyRelay(pubkey)))
+		}
+	}
+
+	return nil
+}
+
+// GetMentionedPubkeys returns the public keys mentioned in the content of an event with
+// nostr:npub and nostr:nprofile URIs (NIP-27), in order of appearance
+func GetMentionedPubkeys(evt *nostr.Event) []string {
+	var pubkeys []string
+
+	for _, reference := range ExtractNostrURIs(evt.Content) {
+		profile, ok := reference.Pointer.(nostr.ProfilePointer)
+		if ok && !slices.Contains(pubkeys, profile.PublicKey) {
+			pubkeys = append(pubkeys, profile.PublicKey)
+		}
+	}
+
+	return pubkeys
+}
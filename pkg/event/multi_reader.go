@@ -0,0 +1,187 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// MultiReadResult is the merged answer of several relays to a filter
+type MultiReadResult struct {
+	Events []*nostr.Event // Merged events, newest first
+
+	// Missing holds, by relay, the IDs of the merged events the relay did not return. Relays
+	// returning an older version of a replaceable or addressable event miss the newest one.
+	Missing map[string][]string
+
+	// Errors holds the relays that could not be queried, they are not part of Missing
+	Errors map[string]error
+}
+
+// Complete checks if every relay answered with every merged event
+func (r *MultiReadResult) Complete() bool {
+	return len(r.Missing) == 0 && len(r.Errors) == 0
+}
+
+// MultiReader queries several relays with the same filter and merges their answers, so that tx
+// data survives relays dropping events. Events are deduplicated by ID, and replaceable and
+// addressable events by coordinate with the newest version winning.
+type MultiReader struct {
+	urls   []string
+	relays map[string]EventQuerier
+}
+
+// NewMultiReader creates a reader over relays by URL
+func NewMultiReader(relays map[string]EventQuerier) *MultiReader {
+	urls := make([]string, 0, len(relays))
+	for url := range relays {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	return &MultiReader{urls: urls, relays: relays}
+}
+
+// NewPoolMultiReader creates a reader over relay URLs, connected through a go-nostr relay pool
+func NewPoolMultiReader(pool *nostr.SimplePool, urls []string) *MultiReader {
+	relays := make(map[string]EventQuerier, len(urls))
+	for _, url := range urls {
+		relays[nostr.NormalizeURL(url)] = &poolRelayQuerier{pool: pool, url: url}
+	}
+	return NewMultiReader(relays)
+}
+
+// poolRelayQuerier queries a single relay of a pool, reporting connection errors
+type poolRelayQuerier struct {
+	pool *nostr.SimplePool
+	url  string
+}
+
+func (q *poolRelayQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	relay, err := q.pool.EnsureRelay(q.url)
+	if err != nil {
+		return nil, err
+	}
+	return relay.QuerySync(ctx, filter)
+}
+
+// Query returns the merged events of the relays, it fails only when no relay answers
+func (m *MultiReader) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	result, err := m.Read(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return result.Events, nil
+}
+
+// Read queries every relay concurrently and merges their answers. Events that do not match the
+// filter or have an invalid ID or signature are dropped, so relays cannot forge the newest
+// version of an event. It fails only when no relay answers.
+func (m *MultiReader) Read(ctx context.Context, filter nostr.Filter) (*MultiReadResult, error) {
+	if len(m.urls) == 0 {
+		return nil, fmt.Errorf("no relays to read from")
+	}
+
+	answers := make([][]*nostr.Event, len(m.urls))
+	errs := make([]error, len(m.urls))
+
+	var wg sync.WaitGroup
+	for i, url := range m.urls {
+		wg.Add(1)
+		go func(i int, querier EventQuerier) {
+			defer wg.Done()
+			answers[i], errs[i] = querier.Query(ctx, filter)
+		}(i, m.relays[url])
+	}
+	wg.Wait()
+
+	result := &MultiReadResult{
+		Missing: make(map[string][]string),
+		Errors:  make(map[string]error),
+	}
+
+	merged := make(map[string]*nostr.Event) // By ID or coordinate
+	seen := make([]map[string]bool, len(m.urls))
+	for i, url := range m.urls {
+		if errs[i] != nil {
+			result.Errors[url] = errs[i]
+			continue
+		}
+
+		seen[i] = make(map[string]bool, len(answers[i]))
+		for _, evt := range answers[i] {
+			if evt == nil || !filter.Matches(evt) {
+				continue
+			}
+			if !evt.CheckID() {
+				continue
+			}
+			if ok, err := evt.CheckSignature(); err != nil || !ok {
+				continue
+			}
+
+			seen[i][evt.ID] = true
+
+			key := eventKey(evt)
+			if current, ok := merged[key]; !ok || isNewer(evt, current) {
+				merged[key] = evt
+			}
+		}
+	}
+
+	if len(result.Errors) == len(m.urls) {
+		errList := make([]error, 0, len(m.urls))
+		for _, url := range m.urls {
+			errList = append(errList, fmt.Errorf("%s: %w", url, result.Errors[url]))
+		}
+		return nil, fmt.Errorf("all relays failed: %w", errors.Join(errList...))
+	}
+
+	result.Events = make([]*nostr.Event, 0, len(merged))
+	for _, evt := range merged {
+		result.Events = append(result.Events, evt)
+	}
+	SortEventsByCreatedAt(result.Events, true)
+	if filter.Limit > 0 && len(result.Events) > filter.Limit {
+		result.Events = result.Events[:filter.Limit]
+	}
+
+	for i, url := range m.urls {
+		if seen[i] == nil {
+			continue
+		}
+		for _, evt := range result.Events {
+			if !seen[i][evt.ID] {
+				result.Missing[url] = append(result.Missing[url], evt.ID)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// eventKey returns the key deduplicating an event, the coordinate of replaceable and addressable
+// events and the ID of the others
+func eventKey(evt *nostr.Event) string {
+	switch {
+	case nostr.IsReplaceableKind(evt.Kind):
+		return fmt.Sprintf("%d:%s:", evt.Kind, evt.PubKey)
+	case nostr.IsAddressableKind(evt.Kind):
+		return fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, evt.Tags.GetD())
+	default:
+		return evt.ID
+	}
+}
+
+// isNewer checks if an event replaces another one with the same key, the newest wins and ties
+// go to the lowest ID as in NIP-01
+func isNewer(evt, current *nostr.Event) bool {
+	if evt.CreatedAt != current.CreatedAt {
+		return evt.CreatedAt > current.CreatedAt
+	}
+	return evt.ID < current.ID
+}
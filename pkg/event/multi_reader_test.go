@@ -0,0 +1,85 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// failingQuerier is a relay that cannot be reached
+type failingQuerier struct{}
+
+func (failingQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMultiReader(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+
+	signed := func(evt *nostr.Event, err error) *nostr.Event {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		if err := evt.Sign(privateKey); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+		return evt
+	}
+
+	transfer := signed(CreateTxTransferEvent(goldenLog()))
+	other := goldenLog()
+	other.Hash = "0x0000000000000000000000000000000000000000000000000000000000000002"
+	otherTransfer := signed(CreateTxTransferEvent(other))
+
+	oldBook := signed(CreateAddressBookEvent(AddressBook{Payees: []Payee{{Name: "Alice", Address: "alice.eth"}}}, ""))
+	newBook := signed(CreateAddressBookEvent(AddressBook{Payees: []Payee{{Name: "Bob", Address: "bob.eth"}}}, ""))
+	newBook.CreatedAt = oldBook.CreatedAt + 10
+	newBook.Sign(privateKey)
+
+	forged := *otherTransfer
+	forged.ID = "ff" + forged.ID[2:]
+
+	reader := NewMultiReader(map[string]EventQuerier{
+		"wss://a.example.com": memoryQuerier{transfer, otherTransfer, newBook},
+		"wss://b.example.com": memoryQuerier{transfer, oldBook, &forged},
+		"wss://c.example.com": failingQuerier{},
+	})
+
+	result, err := reader.Read(context.Background(), nostr.Filter{Authors: []string{transfer.PubKey}})
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	ids := make([]string, 0, len(result.Events))
+	for _, evt := range result.Events {
+		ids = append(ids, evt.ID)
+	}
+	if len(ids) != 3 || !slices.Contains(ids, transfer.ID) || !slices.Contains(ids, otherTransfer.ID) || !slices.Contains(ids, newBook.ID) {
+		t.Fatalf("Expected the transfers and the newest address book, got %v", ids)
+	}
+	if result.Events[0].ID != newBook.ID {
+		t.Errorf("Expected the newest event first, got %s", result.Events[0].ID)
+	}
+
+	// Relay b has an older address book and a forged transfer
+	missing := result.Missing["wss://b.example.com"]
+	if len(missing) != 2 || !slices.Contains(missing, newBook.ID) || !slices.Contains(missing, otherTransfer.ID) {
+		t.Errorf("Expected relay b to miss the new address book and the other transfer, got %v", missing)
+	}
+	if _, ok := result.Missing["wss://a.example.com"]; ok {
+		t.Errorf("Expected relay a to miss nothing, got %v", result.Missing["wss://a.example.com"])
+	}
+	if result.Errors["wss://c.example.com"] == nil || result.Complete() {
+		t.Errorf("Expected relay c to fail, got %v", result.Errors)
+	}
+
+	// Only fails when every relay fails
+	reader = NewMultiReader(map[string]EventQuerier{"wss://c.example.com": failingQuerier{}})
+	if _, err := reader.Query(context.Background(), nostr.Filter{}); err == nil {
+		t.Error("Expected an error when every relay fails")
+	}
+}
@@ -0,0 +1,108 @@
+package event
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// TagNamespaceSeparator separates the namespace from the value of a namespaced t tag
+const TagNamespaceSeparator = ":"
+
+// tagNamespace is the namespace of the t tags created by the constructors
+var tagNamespace = struct {
+	sync.RWMutex
+	namespace string
+	compat    bool
+}{}
+
+// SetTagNamespace sets the namespace of the t tags created by the constructors, e.g. "eth"
+// turns ["t", "update"] into ["t", "eth:update"] so that the type tags of the package do not
+// pollute the hashtags of public relays. An empty namespace creates plain tags, the default.
+// With compat, filters built by TypeTagValues also match the plain tags of older events.
+func SetTagNamespace(namespace string, compat bool) {
+	tagNamespace.Lock()
+	defer tagNamespace.Unlock()
+
+	tagNamespace.namespace = namespace
+	tagNamespace.compat = compat
+}
+
+// TagNamespace returns the namespace of the t tags created by the constructors
+func TagNamespace() string {
+	tagNamespace.RLock()
+	defer tagNamespace.RUnlock()
+
+	return tagNamespace.namespace
+}
+
+// NamespacedTag returns a t tag value in the current namespace, contract addresses and topic
+// hashes are not namespaced
+func NamespacedTag(value string) string {
+	namespace := TagNamespace()
+	if namespace == "" || strings.HasPrefix(value, "0x") {
+		return value
+	}
+	return namespace + TagNamespaceSeparator + value
+}
+
+// typeTag returns a t tag in the current namespace
+func typeTag(value string) nostr.Tag {
+	return nostr.Tag{"t", NamespacedTag(value)}
+}
+
+// isTypeTag checks if a tag is the t tag of a value, namespaced or plain
+func isTypeTag(tag nostr.Tag, value string) bool {
+	if len(tag) < 2 || tag[0] != "t" {
+		return false
+	}
+	return TypeTagValue(tag[1]) == value
+}
+
+// TypeTagValue returns the value of a t tag without its namespace, t tags of contract
+// addresses and topic hashes are never namespaced by other clients and are returned as is
+func TypeTagValue(value string) string {
+	namespace, rest, ok := strings.Cut(value, TagNamespaceSeparator)
+	if !ok || namespace == "" || strings.HasPrefix(value, "0x") {
+		return value
+	}
+	return rest
+}
+
+// HasTypeTag checks if an event has the t tag of a value, namespaced or plain
+func HasTypeTag(evt *nostr.Event, value string) bool {
+	if evt == nil {
+		return false
+	}
+
+	for _, tag := range evt.Tags {
+		if isTypeTag(tag, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeTagValues returns the t tag values to filter events of the given values with, e.g.
+// nostr.Filter{Tags: nostr.TagMap{"t": TypeTagValues("tx_log")}}. In compat mode the plain
+// values are included to also match events created before the namespace was set.
+func TypeTagValues(values ...string) []string {
+	tagNamespace.RLock()
+	namespace, compat := tagNamespace.namespace, tagNamespace.compat
+	tagNamespace.RUnlock()
+
+	result := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		if namespace == "" || strings.HasPrefix(value, "0x") {
+			result = append(result, value)
+			continue
+		}
+
+		result = append(result, namespace+TagNamespaceSeparator+value)
+		if compat {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package event
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTagNamespace(t *testing.T) {
+	SetTagNamespace("eth", true)
+	t.Cleanup(func() { SetTagNamespace("", false) })
+
+	evt, err := CreateTxLogEvent(goldenLog(), WithBlockNumber(19000000))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	tags := evt.Tags.GetAll([]string{"t"})
+	values := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, tag[1])
+	}
+
+	for _, expected := range []string{"eth:tx_log", neth.TopicERC20Transfer, goldenLog().To} {
+		if !slices.Contains(values, expected) {
+			t.Errorf("Expected t tag %s, got %v", expected, values)
+		}
+	}
+	if slices.Contains(values, "tx_log") {
+		t.Errorf("Expected no plain tx_log tag, got %v", values)
+	}
+
+	evt.ID = evt.GetID()
+	update, err := UpdateTxLogStatus(evt, TxLogStatusFinalized)
+	if err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	update.ID = update.GetID()
+	update, err = UpdateTxLogStatus(update, TxLogStatusOrphaned)
+	if err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if count := len(update.Tags.GetAll([]string{"t", "eth:update"})); count != 1 {
+		t.Errorf("Expected one eth:update tag, got %d", count)
+	}
+
+	if !HasTypeTag(update, "update") {
+		t.Error("Expected HasTypeTag to match the namespaced update tag")
+	}
+	if !HasTypeTag(&nostr.Event{Tags: nostr.Tags{{"t", "update"}}}, "update") {
+		t.Error("Expected HasTypeTag to match a plain update tag")
+	}
+
+	transfer, err := CreateTxTransferEvent(goldenLog(), WithTransferKind(KindTxTransferLegacy))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if !IsTxTransferEvent(transfer) {
+		t.Error("Expected a namespaced legacy transfer to be recognized")
+	}
+
+	if got := TypeTagValues("tx_log", neth.TopicERC20Transfer); !slices.Equal(got, []string{"eth:tx_log", "tx_log", neth.TopicERC20Transfer}) {
+		t.Errorf("Expected namespaced and plain values, got %v", got)
+	}
+
+	SetTagNamespace("eth", false)
+	if got := TypeTagValues("tx_log"); !slices.Equal(got, []string{"eth:tx_log"}) {
+		t.Errorf("Expected only the namespaced value, got %v", got)
+	}
+}
+
+func TestTypeTagValue(t *testing.T) {
+	for value, expected := range map[string]string{
+		"update":                   "update",
+		"eth:update":               "update",
+		":update":                  ":update",
+		neth.TopicERC20Transfer:    neth.TopicERC20Transfer,
+		"0x1111111111111111111111": "0x1111111111111111111111",
+	} {
+		if got := TypeTagValue(value); got != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, value, got)
+		}
+	}
+}
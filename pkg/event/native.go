@@ -0,0 +1,111 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for native transfers
+const (
+	KindNativeTransfer = 111006
+
+	EventTypeNativeTransferCreated EventTypeNativeTransfer = "native_transfer_created"
+)
+
+type EventTypeNativeTransfer string
+
+// NativeTransferEvent represents a Nostr event for a native value transfer
+type NativeTransferEvent struct {
+	TransferData neth.NativeTransfer     `json:"transfer_data"`
+	EventType    EventTypeNativeTransfer `json:"event_type"`
+	Tags         []string                `json:"tags,omitempty"`
+}
+
+// CreateNativeTransferEvent creates a new Nostr event for a native value transfer
+func CreateNativeTransferEvent(transfer neth.NativeTransfer, opts ...LogOption) (*nostr.Event, error) {
+	options := newLogOptions(opts)
+
+	if transfer.Value == nil {
+		return nil, fmt.Errorf("value is missing")
+	}
+
+	// Create the event data
+	eventData := NativeTransferEvent{
+		TransferData: transfer,
+		EventType:    EventTypeNativeTransferCreated,
+		Tags:         []string{"native_transfer", "evm", transfer.ChainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(transfer.CreatedAt.Unix()),
+		Kind:      MappedKind(KindNativeTransfer), // Custom kind for native transfers
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", transfer.Hash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("native_transfer")) // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", transfer.ChainID}) // Chain ID
+
+	// Reference tags for transaction hash
+	evt.Tags = append(evt.Tags, []string{"r", transfer.TxHash}) // Transaction hash as reference
+
+	// Address tags
+	evt.Tags = append(evt.Tags, []string{"P", transfer.From}) // Sender address
+	evt.Tags = append(evt.Tags, []string{"p", transfer.To})   // Recipient address
+
+	// Amount tags
+	evt.Tags = append(evt.Tags, []string{"amount", transfer.Value.String()})
+
+	if options.sortableAmount {
+		sortable, err := EncodeSortableAmount(transfer.Value)
+		if err != nil {
+			return nil, err
+		}
+		evt.Tags = append(evt.Tags, []string{"amount_sortable", sortable})
+	}
+
+	// Topic tag
+	evt.Tags = append(evt.Tags, typeTag(neth.TopicNativeTransfer))
+
+	// Block tag
+	evt.Tags = append(evt.Tags, []string{"block", strconv.FormatUint(transfer.BlockNumber, 10)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a native evm transfer on chain %s", transfer.ChainID)
+	alt += fmt.Sprintf("\n From: %s", transfer.From)
+	alt += fmt.Sprintf("\n To: %s", transfer.To)
+	alt += fmt.Sprintf("\n Value: %s", neth.FormatEther(transfer.Value))
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseNativeTransferEvent parses a Nostr event back into a NativeTransferEvent
+func ParseNativeTransferEvent(evt *nostr.Event) (*NativeTransferEvent, error) {
+	var nativeTransferEvent NativeTransferEvent
+	err := unmarshalContent(evt, &nativeTransferEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &nativeTransferEvent, nil
+}
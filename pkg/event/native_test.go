@@ -0,0 +1,65 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func TestNativeTransferEvent(t *testing.T) {
+	transfer := neth.NativeTransfer{
+		TxHash:      "0xabc",
+		ChainID:     "100",
+		BlockNumber: 42,
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       big.NewInt(1500000000000000000),
+		CreatedAt:   time.Unix(1700000000, 0),
+	}
+	transfer.Hash = transfer.GenerateUniqueHash()
+
+	evt, err := CreateNativeTransferEvent(transfer)
+	if err != nil {
+		t.Fatalf("Failed to create native transfer event: %v", err)
+	}
+	if evt.Kind != KindNativeTransfer || evt.Tags.GetD() != transfer.Hash {
+		t.Errorf("Expected a native transfer event of %s, got kind %d and d %s", transfer.Hash, evt.Kind, evt.Tags.GetD())
+	}
+	for _, tag := range [][]string{{"P", transfer.From}, {"p", transfer.To}, {"amount", "1500000000000000000"}, {"block", "42"}, typeTag(neth.TopicNativeTransfer)} {
+		if evt.Tags.GetFirst(tag) == nil {
+			t.Errorf("Expected tag %v", tag)
+		}
+	}
+
+	parsed, err := ParseNativeTransferEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse native transfer event: %v", err)
+	}
+	if parsed.EventType != EventTypeNativeTransferCreated || parsed.TransferData.Hash != transfer.Hash || parsed.TransferData.Value.Cmp(transfer.Value) != 0 || !parsed.TransferData.CreatedAt.Equal(transfer.CreatedAt) {
+		t.Errorf("Expected the transfer back, got %+v", parsed.TransferData)
+	}
+
+	// The transfer round trips through a log like a token transfer
+	log, err := parsed.TransferData.ToLog()
+	if err != nil {
+		t.Fatalf("Failed to convert transfer: %v", err)
+	}
+	txLog, err := CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	txLogEvent, err := ParseTxLogEvent(txLog)
+	if err != nil {
+		t.Fatalf("Failed to parse tx log event: %v", err)
+	}
+	if txLogEvent.LogData.Topic != neth.TopicNativeTransfer || txLogEvent.LogData.GetAmount().Cmp(transfer.Value) != 0 {
+		t.Errorf("Expected the native transfer log back, got %+v", txLogEvent.LogData)
+	}
+
+	transfer.Value = nil
+	if _, err := CreateNativeTransferEvent(transfer); err == nil {
+		t.Error("Expected a transfer without value to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// nostrURIPattern matches the NIP-21 URIs of public entities, nsec is never shared in content
+var nostrURIPattern = regexp.MustCompile(`nostr:(?:npub|nprofile|note|nevent|naddr)1[023456789acdefghjklmnpqrstuvwxyz]+`)
+
+// NostrReference is an entity referenced by a nostr: URI in the content of an event
+type NostrReference struct {
+	URI     string        // The URI as found in the content, e.g. "nostr:nevent1..."
+	Start   int           // Byte offset of the URI in the content
+	End     int           // Byte offset of the end of the URI in the content
+	Pointer nostr.Pointer // nostr.ProfilePointer, nostr.EventPointer or nostr.EntityPointer
+}
+
+// IsEvent checks if the reference points to an event rather than a profile
+func (r NostrReference) IsEvent() bool {
+	switch r.Pointer.(type) {
+	case nostr.EventPointer, nostr.EntityPointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExtractNostrURIs returns the references of the nostr: URIs in the content, in order of
+// appearance. URIs that do not decode are skipped.
+func ExtractNostrURIs(content string) []NostrReference {
+	var references []NostrReference
+
+	for _, match := range nostrURIPattern.FindAllStringIndex(content, -1) {
+		uri := content[match[0]:match[1]]
+
+		pointer, err := nip19.ToPointer(uri[len("nostr:"):])
+		if err != nil {
+			continue
+		}
+
+		references = append(references, NostrReference{
+			URI:     uri,
+			Start:   match[0],
+			End:     match[1],
+			Pointer: pointer,
+		})
+	}
+
+	return references
+}
+
+// EventQuerier answers queries for events, e.g. a relay or a local store
+type EventQuerier interface {
+	Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+}
+
+// HydrateNostrURIs fetches the events referenced by the nostr: URIs of the content, e.g. the
+// transfer quoted by CreateQuoteRepostEvent, and returns them by URI. Profiles and events that
+// are not found are left out.
+func HydrateNostrURIs(ctx context.Context, querier EventQuerier, content string) (map[string]*nostr.Event, error) {
+	references := ExtractNostrURIs(content)
+	hydrated := make(map[string]*nostr.Event)
+
+	// Events referenced by ID are fetched with a single query
+	var ids []string
+	for _, reference := range references {
+		if pointer, ok := reference.Pointer.(nostr.EventPointer); ok {
+			ids = append(ids, pointer.ID)
+		}
+	}
+
+	var byID []*nostr.Event
+	if len(ids) > 0 {
+		events, err := querier.Query(ctx, nostr.Filter{IDs: ids})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query referenced events: %w", err)
+		}
+		byID = events
+	}
+
+	for _, reference := range references {
+		switch pointer := reference.Pointer.(type) {
+		case nostr.EventPointer:
+			for _, evt := range byID {
+				if pointer.MatchesEvent(*evt) {
+					hydrated[reference.URI] = evt
+					break
+				}
+			}
+
+		case nostr.EntityPointer:
+			filter := pointer.AsFilter()
+			filter.Limit = 1
+
+			events, err := querier.Query(ctx, filter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query referenced event %s: %w", reference.URI, err)
+			}
+			if len(events) > 0 {
+				hydrated[reference.URI] = events[0]
+			}
+		}
+	}
+
+	return hydrated, nil
+}
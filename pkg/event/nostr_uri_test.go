@@ -0,0 +1,91 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// memoryQuerier answers queries from a list of events
+type memoryQuerier []*nostr.Event
+
+func (q memoryQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var events []*nostr.Event
+	for _, evt := range q {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func TestExtractNostrURIs(t *testing.T) {
+	transfer, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	transfer.PubKey = goldenPubKey
+	transfer.ID = transfer.GetID()
+
+	quote, err := CreateQuoteRepostEvent("paid the rent", nil, transfer, "wss://relay.example.com")
+	if err != nil {
+		t.Fatalf("Failed to create quote: %v", err)
+	}
+
+	npub, _ := nip19.EncodePublicKey(goldenPubKey)
+	content := quote.Content + " thanks nostr:" + npub + " nostr:nevent1invalid"
+
+	references := ExtractNostrURIs(content)
+	if len(references) != 2 {
+		t.Fatalf("Expected 2 references, got %d", len(references))
+	}
+
+	pointer, ok := references[0].Pointer.(nostr.EventPointer)
+	if !ok || pointer.ID != transfer.ID || !references[0].IsEvent() {
+		t.Errorf("Expected a pointer to the transfer, got %#v", references[0].Pointer)
+	}
+	if content[references[0].Start:references[0].End] != references[0].URI {
+		t.Errorf("Expected offsets of %s, got %d-%d", references[0].URI, references[0].Start, references[0].End)
+	}
+
+	if profile, ok := references[1].Pointer.(nostr.ProfilePointer); !ok || profile.PublicKey != goldenPubKey || references[1].IsEvent() {
+		t.Errorf("Expected a pointer to the profile, got %#v", references[1].Pointer)
+	}
+
+	hydrated, err := HydrateNostrURIs(context.Background(), memoryQuerier{transfer}, content)
+	if err != nil {
+		t.Fatalf("Failed to hydrate: %v", err)
+	}
+	if len(hydrated) != 1 || hydrated[references[0].URI] != transfer {
+		t.Errorf("Expected the transfer to be hydrated, got %v", hydrated)
+	}
+}
+
+func TestWithMentions(t *testing.T) {
+	other := "c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+
+	group := "community"
+	evt, err := CreateMessageEvent("gm", &group, WithMentions(goldenPubKey, other, goldenPubKey))
+	if err != nil {
+		t.Fatalf("Failed to create message: %v", err)
+	}
+
+	mentioned := GetMentionedPubkeys(evt)
+	if len(mentioned) != 2 || mentioned[0] != goldenPubKey || mentioned[1] != other {
+		t.Errorf("Expected mentions %s and %s, got %v", goldenPubKey, other, mentioned)
+	}
+	if count := len(evt.Tags.GetAll([]string{"p"})); count != 2 {
+		t.Errorf("Expected 2 p tags, got %d", count)
+	}
+
+	npub, _ := nip19.EncodePublicKey(goldenPubKey)
+	if evt.Content != "gm nostr:"+npub+" "+ExtractNostrURIs(evt.Content)[1].URI {
+		t.Errorf("Expected the npubs appended to the content, got %s", evt.Content)
+	}
+
+	if _, err := CreateMessageEvent("gm", nil, WithMentions("not a key")); err == nil {
+		t.Error("Expected an error for an invalid public key")
+	}
+}
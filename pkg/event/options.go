@@ -0,0 +1,122 @@
+package event
+
+import "github.com/comunifi/nostr-eth/pkg/neth"
+
+// LogOption configures optional behaviour of the tx log and transfer event constructors
+type LogOption func(*logOptions)
+
+type logOptions struct {
+	sortableAmount bool
+	topicRegistry  *TopicRegistry
+	tokenRegistry  *TokenRegistry
+	receiptProof   *neth.ReceiptProof
+	blockNumber    uint64
+	transferKind   int
+	splits         []neth.Split
+	rateProvider   neth.RateProvider
+	dust           *DustThresholds
+	sanitizePolicy SanitizePolicy
+	flattenPolicy  FlattenPolicy
+}
+
+// newLogOptions applies the given options on top of the defaults
+func newLogOptions(opts []LogOption) *logOptions {
+	o := &logOptions{
+		topicRegistry:  DefaultTopicRegistry,
+		tokenRegistry:  DefaultTokenRegistry,
+		transferKind:   KindTxTransfer,
+		sanitizePolicy: CurrentSanitizePolicy(),
+		flattenPolicy:  CurrentFlattenPolicy(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSortableAmount adds an "amount_sortable" tag holding the amount as a fixed-width,
+// zero-padded decimal string, allowing relays and clients to order events by amount
+func WithSortableAmount() LogOption {
+	return func(o *logOptions) {
+		o.sortableAmount = true
+	}
+}
+
+// WithTopicRegistry sets the topic registry consulted for contract-specific tags,
+// passing nil disables the lookup
+func WithTopicRegistry(registry *TopicRegistry) LogOption {
+	return func(o *logOptions) {
+		o.topicRegistry = registry
+	}
+}
+
+// WithTokenRegistry sets the token registry consulted to tag transfers of verified, spam and
+// stable tokens, passing nil disables the lookup
+func WithTokenRegistry(registry *TokenRegistry) LogOption {
+	return func(o *logOptions) {
+		o.tokenRegistry = registry
+	}
+}
+
+// WithReceiptProof attaches a receipt proof to a tx log event so consumers can verify the log
+// against a block hash with VerifyReceiptProof
+func WithReceiptProof(proof *neth.ReceiptProof) LogOption {
+	return func(o *logOptions) {
+		o.receiptProof = proof
+	}
+}
+
+// WithBlockNumber records the number of the block including the log, the tx log event then
+// starts with the "included" status and can be upgraded by checkpoints
+func WithBlockNumber(blockNumber uint64) LogOption {
+	return func(o *logOptions) {
+		o.blockNumber = blockNumber
+	}
+}
+
+// WithTransferKind sets the kind of transfer events, e.g. KindTxTransferLegacy for consumers
+// that were not migrated yet
+func WithTransferKind(kind int) LogOption {
+	return func(o *logOptions) {
+		o.transferKind = kind
+	}
+}
+
+// WithSplits attaches the split of the payment to a transfer event, the share of each recipient
+// in basis points. Batch transactions paying the split out are checked with VerifyTransferSplits.
+func WithSplits(splits ...neth.Split) LogOption {
+	return func(o *logOptions) {
+		o.splits = splits
+	}
+}
+
+// WithDustThresholds adds a "dust" t tag to ERC20 transfers below the threshold of their token
+func WithDustThresholds(thresholds *DustThresholds) LogOption {
+	return func(o *logOptions) {
+		o.dust = thresholds
+	}
+}
+
+// WithRateProvider annotates transfer events with a "fiat" tag holding the currency, the rate
+// and the value of the amount at the time of the transfer, tokens without a rate are left out
+func WithRateProvider(provider neth.RateProvider) LogOption {
+	return func(o *logOptions) {
+		o.rateProvider = provider
+	}
+}
+
+// WithSanitizePolicy sets how the tags and the alt tag derived from log data are cleaned, instead
+// of the policy set with SetSanitizePolicy
+func WithSanitizePolicy(policy SanitizePolicy) LogOption {
+	return func(o *logOptions) {
+		o.sanitizePolicy = policy
+	}
+}
+
+// WithFlattenPolicy sets the keys of log data flattened into tags, instead of the policy set with
+// SetFlattenPolicy
+func WithFlattenPolicy(policy FlattenPolicy) LogOption {
+	return func(o *logOptions) {
+		o.flattenPolicy = policy
+	}
+}
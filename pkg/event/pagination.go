@@ -0,0 +1,141 @@
+package event
+
+import (
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// SortEventsByCreatedAt sorts events in place by creation time, ties are broken by event ID
+// so the order is stable across relays
+func SortEventsByCreatedAt(events []*nostr.Event, newestFirst bool) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			if newestFirst {
+				return events[i].CreatedAt > events[j].CreatedAt
+			}
+			return events[i].CreatedAt < events[j].CreatedAt
+		}
+		return events[i].ID < events[j].ID
+	})
+}
+
+// Cursor marks a position in a newest-first list of events
+type Cursor struct {
+	Until *nostr.Timestamp `json:"until,omitempty"`
+	Since *nostr.Timestamp `json:"since,omitempty"`
+
+	// SeenIDs holds the events already returned at the Until timestamp, since relay
+	// filters treat until as inclusive and several events may share a timestamp
+	SeenIDs []string `json:"seen_ids,omitempty"`
+}
+
+// ToFilter applies the cursor to a relay filter, returning a copy of the filter with
+// since, until and limit set
+func (c *Cursor) ToFilter(filter nostr.Filter, limit int) nostr.Filter {
+	f := filter
+	if c != nil {
+		f.Since = c.Since
+		f.Until = c.Until
+	}
+
+	// Ask for the boundary events again on top of the page, they are skipped by Page
+	if c != nil && limit > 0 {
+		limit += len(c.SeenIDs)
+	}
+	f.Limit = limit
+
+	return f
+}
+
+// Page represents a window of events and the cursor to the next window
+type Page struct {
+	Events []*nostr.Event `json:"events"`
+	Next   *Cursor        `json:"next,omitempty"` // nil when there are no more events
+}
+
+// Paginator windows a set of events newest first using until/since cursors
+type Paginator struct {
+	events   []*nostr.Event
+	pageSize int
+}
+
+// NewPaginator creates a new paginator over a copy of the given events
+func NewPaginator(events []*nostr.Event, pageSize int) *Paginator {
+	sorted := make([]*nostr.Event, len(events))
+	copy(sorted, events)
+	SortEventsByCreatedAt(sorted, true)
+
+	if pageSize <= 0 {
+		pageSize = len(sorted)
+	}
+
+	return &Paginator{
+		events:   sorted,
+		pageSize: pageSize,
+	}
+}
+
+// First returns the newest page of events
+func (p *Paginator) First() Page {
+	return p.Page(nil)
+}
+
+// Page returns the page of events starting at the given cursor, a nil cursor starts at the newest event
+func (p *Paginator) Page(cursor *Cursor) Page {
+	seen := make(map[string]bool)
+	if cursor != nil {
+		for _, id := range cursor.SeenIDs {
+			seen[id] = true
+		}
+	}
+
+	page := Page{Events: make([]*nostr.Event, 0, p.pageSize)}
+	hasMore := false
+
+	for _, evt := range p.events {
+		if cursor != nil {
+			if cursor.Until != nil && evt.CreatedAt > *cursor.Until {
+				continue
+			}
+			if cursor.Since != nil && evt.CreatedAt < *cursor.Since {
+				break
+			}
+			if cursor.Until != nil && evt.CreatedAt == *cursor.Until && seen[evt.ID] {
+				continue
+			}
+		}
+
+		if len(page.Events) == p.pageSize {
+			hasMore = true
+			break
+		}
+
+		page.Events = append(page.Events, evt)
+	}
+
+	if !hasMore || len(page.Events) == 0 {
+		return page
+	}
+
+	last := page.Events[len(page.Events)-1].CreatedAt
+	next := &Cursor{Until: &last}
+	if cursor != nil {
+		next.Since = cursor.Since
+
+		// Keep the boundary events from the previous cursor if the timestamp did not move
+		if cursor.Until != nil && *cursor.Until == last {
+			next.SeenIDs = append(next.SeenIDs, cursor.SeenIDs...)
+		}
+	}
+
+	for _, evt := range page.Events {
+		if evt.CreatedAt == last {
+			next.SeenIDs = append(next.SeenIDs, evt.ID)
+		}
+	}
+
+	page.Next = next
+
+	return page
+}
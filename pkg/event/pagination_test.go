@@ -0,0 +1,81 @@
+package event
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSortEventsByCreatedAt(t *testing.T) {
+	events := []*nostr.Event{
+		{ID: "b", CreatedAt: 100},
+		{ID: "c", CreatedAt: 300},
+		{ID: "a", CreatedAt: 100},
+	}
+
+	SortEventsByCreatedAt(events, false)
+	if events[0].ID != "a" || events[1].ID != "b" || events[2].ID != "c" {
+		t.Errorf("Unexpected oldest first order: %s %s %s", events[0].ID, events[1].ID, events[2].ID)
+	}
+
+	SortEventsByCreatedAt(events, true)
+	if events[0].ID != "c" || events[1].ID != "a" || events[2].ID != "b" {
+		t.Errorf("Unexpected newest first order: %s %s %s", events[0].ID, events[1].ID, events[2].ID)
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	// Several events share timestamps to exercise the boundary handling
+	var events []*nostr.Event
+	for i := 0; i < 10; i++ {
+		events = append(events, &nostr.Event{
+			ID:        fmt.Sprintf("%02d", i),
+			CreatedAt: nostr.Timestamp(1000 + (i/3)*10),
+		})
+	}
+
+	paginator := NewPaginator(events, 4)
+
+	seen := make(map[string]bool)
+	page := paginator.First()
+	pages := 1
+	for {
+		for _, evt := range page.Events {
+			if seen[evt.ID] {
+				t.Fatalf("Event %s returned twice", evt.ID)
+			}
+			seen[evt.ID] = true
+		}
+
+		if page.Next == nil {
+			break
+		}
+
+		page = paginator.Page(page.Next)
+		pages++
+	}
+
+	if len(seen) != len(events) {
+		t.Errorf("Expected %d events, got %d", len(events), len(seen))
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+}
+
+func TestCursorToFilter(t *testing.T) {
+	until := nostr.Timestamp(1000)
+	cursor := &Cursor{Until: &until, SeenIDs: []string{"a", "b"}}
+
+	filter := cursor.ToFilter(nostr.Filter{Kinds: []int{KindTxLog}}, 10)
+	if filter.Until == nil || *filter.Until != until {
+		t.Error("Expected until to be set on the filter")
+	}
+	if filter.Limit != 12 {
+		t.Errorf("Expected limit 12, got %d", filter.Limit)
+	}
+	if len(filter.Kinds) != 1 || filter.Kinds[0] != KindTxLog {
+		t.Error("Expected base filter to be preserved")
+	}
+}
@@ -0,0 +1,115 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Limits of the events accepted by the parsers, events from relays are untrusted and larger
+// events are rejected before any decoding
+const (
+	MaxContentSize  = 512 * 1024 // Bytes of content
+	MaxContentDepth = 64         // Nesting of JSON objects and arrays in the content
+	MaxTags         = 2000
+	MaxTagSize      = 64 * 1024 // Bytes of all the values of a tag
+)
+
+// ErrMalformedEvent is returned by the parsers for events they refuse to decode
+var ErrMalformedEvent = errors.New("malformed event")
+
+// CheckEvent checks an event against the parser limits, the content and tags must be valid
+// UTF-8 within the size and nesting limits
+func CheckEvent(evt *nostr.Event) error {
+	if evt == nil {
+		return fmt.Errorf("%w: nil event", ErrMalformedEvent)
+	}
+
+	if len(evt.Content) > MaxContentSize {
+		return fmt.Errorf("%w: content of %d bytes exceeds %d", ErrMalformedEvent, len(evt.Content), MaxContentSize)
+	}
+
+	if !utf8.ValidString(evt.Content) {
+		return fmt.Errorf("%w: content is not valid UTF-8", ErrMalformedEvent)
+	}
+
+	if depth := jsonDepth(evt.Content); depth > MaxContentDepth {
+		return fmt.Errorf("%w: content nesting of %d exceeds %d", ErrMalformedEvent, depth, MaxContentDepth)
+	}
+
+	if len(evt.Tags) > MaxTags {
+		return fmt.Errorf("%w: %d tags exceed %d", ErrMalformedEvent, len(evt.Tags), MaxTags)
+	}
+
+	for i, tag := range evt.Tags {
+		size := 0
+		for _, value := range tag {
+			if !utf8.ValidString(value) {
+				return fmt.Errorf("%w: tag %d is not valid UTF-8", ErrMalformedEvent, i)
+			}
+			size += len(value)
+		}
+
+		if size > MaxTagSize {
+			return fmt.Errorf("%w: tag %d of %d bytes exceeds %d", ErrMalformedEvent, i, size, MaxTagSize)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalContent checks an event and decodes its JSON content, a panic while decoding is
+// returned as ErrMalformedEvent
+func unmarshalContent(evt *nostr.Event, v any) (err error) {
+	if err := CheckEvent(evt); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrMalformedEvent, r)
+		}
+	}()
+
+	return json.Unmarshal([]byte(evt.Content), v)
+}
+
+// jsonDepth returns the deepest nesting of objects and arrays in a JSON document, it does not
+// validate the document
+func jsonDepth(s string) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+
+	return maxDepth
+}
@@ -0,0 +1,186 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for pending transactions
+const (
+	KindPendingTx = 111005
+
+	EventTypePendingTxSeen EventTypePendingTx = "pending_tx_seen"
+
+	// DefaultPendingTxExpiration is how long a pending transaction event stays valid (NIP-40)
+	DefaultPendingTxExpiration = 10 * time.Minute
+)
+
+type EventTypePendingTx string
+
+// minedKinds are the kinds of events published once a transaction is mined
+var minedKinds = map[int]bool{
+	KindTxLog:            true,
+	KindTxTransfer:       true,
+	KindTxTransferLegacy: true,
+	KindTxApproval:       true,
+	KindTx:               true,
+	KindNativeTransfer:   true,
+}
+
+// PendingTxEvent represents a Nostr event for a transaction observed in the mempool
+type PendingTxEvent struct {
+	TxData    neth.PendingTx     `json:"tx_data"`
+	EventType EventTypePendingTx `json:"event_type"`
+	Tags      []string           `json:"tags,omitempty"`
+}
+
+// CreatePendingTxEvent creates a new short-lived Nostr event for a pending transaction,
+// the event expires after DefaultPendingTxExpiration when expiration is zero
+func CreatePendingTxEvent(tx neth.PendingTx, expiration time.Duration) (*nostr.Event, error) {
+	if expiration <= 0 {
+		expiration = DefaultPendingTxExpiration
+	}
+
+	// Create the event data
+	eventData := PendingTxEvent{
+		TxData:    tx,
+		EventType: EventTypePendingTxSeen,
+		Tags:      []string{"pending_tx", "evm", tx.ChainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(tx.SeenAt.Unix()),
+		Kind:      MappedKind(KindPendingTx), // Custom kind for pending transactions
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", tx.Hash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("pending_tx"))      // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", tx.ChainID}) // Chain ID
+
+	// Reference tag for transaction hash
+	evt.Tags = append(evt.Tags, []string{"r", tx.Hash}) // Transaction hash as reference
+
+	// Address tags
+	evt.Tags = append(evt.Tags, []string{"P", tx.From}) // Sender address
+	if tx.To != "" {
+		evt.Tags = append(evt.Tags, []string{"p", tx.To}) // Recipient address, empty for deployments
+	}
+
+	// Amount and gas tags
+	if tx.Value != nil {
+		evt.Tags = append(evt.Tags, []string{"amount", tx.Value.String()})
+	}
+	if tx.GasPrice != nil {
+		evt.Tags = append(evt.Tags, []string{"gas_price", tx.GasPrice.String()})
+	}
+
+	// Expiration tag (NIP-40)
+	expiresAt := tx.SeenAt.Add(expiration).Unix()
+	evt.Tags = append(evt.Tags, []string{"expiration", strconv.FormatInt(expiresAt, 10)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a pending evm transaction %s on chain %s", tx.Hash, tx.ChainID)
+	if tx.Value != nil && tx.Value.Sign() > 0 {
+		alt += fmt.Sprintf("\n Value: %s", neth.FormatEther(tx.Value))
+	}
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParsePendingTxEvent parses a Nostr event back into a PendingTxEvent
+func ParsePendingTxEvent(evt *nostr.Event) (*PendingTxEvent, error) {
+	var pendingTxEvent PendingTxEvent
+	err := unmarshalContent(evt, &pendingTxEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &pendingTxEvent, nil
+}
+
+// IsPendingTxExpired checks if the expiration of a pending transaction event has passed
+func IsPendingTxExpired(evt *nostr.Event, now time.Time) bool {
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == "expiration" {
+			expiresAt, err := strconv.ParseInt(tag[1], 10, 64)
+			if err != nil {
+				return false
+			}
+			return now.Unix() >= expiresAt
+		}
+	}
+	return false
+}
+
+// IsPendingTxSuperseded checks if a mined transaction event references the same
+// transaction as a pending transaction event
+func IsPendingTxSuperseded(pending *nostr.Event, mined *nostr.Event) bool {
+	if DefaultKind(pending.Kind) != KindPendingTx || !minedKinds[DefaultKind(mined.Kind)] {
+		return false
+	}
+
+	txHash, err := GetTxHashFromEvent(pending)
+	if err != nil {
+		return false
+	}
+
+	for _, tag := range mined.Tags {
+		if len(tag) >= 2 && tag[0] == "r" && strings.EqualFold(tag[1], txHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrunePendingTxEvents removes the pending transaction events that have expired or have been
+// superseded by a mined transaction event in the same list, other events are kept as is
+func PrunePendingTxEvents(events []*nostr.Event, now time.Time) []*nostr.Event {
+	mined := make(map[string]bool)
+	for _, evt := range events {
+		if !minedKinds[DefaultKind(evt.Kind)] {
+			continue
+		}
+		for _, tag := range evt.Tags {
+			if len(tag) >= 2 && tag[0] == "r" {
+				mined[strings.ToLower(tag[1])] = true
+			}
+		}
+	}
+
+	var pruned []*nostr.Event
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) == KindPendingTx {
+			if IsPendingTxExpired(evt, now) {
+				continue
+			}
+			if txHash, err := GetTxHashFromEvent(evt); err == nil && mined[strings.ToLower(txHash)] {
+				continue
+			}
+		}
+		pruned = append(pruned, evt)
+	}
+	return pruned
+}
@@ -0,0 +1,47 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestPrunePendingTxEvents(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	pending, err := CreatePendingTxEvent(neth.PendingTx{
+		Hash:    "0xAAAA",
+		ChainID: "1",
+		From:    "0x1111111111111111111111111111111111111111",
+		To:      "0x2222222222222222222222222222222222222222",
+		Value:   big.NewInt(1000),
+		SeenAt:  now,
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create pending tx event: %v", err)
+	}
+
+	other, err := CreatePendingTxEvent(neth.PendingTx{Hash: "0xbbbb", ChainID: "1", SeenAt: now}, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create pending tx event: %v", err)
+	}
+
+	mined := &nostr.Event{Kind: KindTxLog, Tags: nostr.Tags{{"r", "0xaaaa"}}}
+
+	if !IsPendingTxSuperseded(pending, mined) {
+		t.Errorf("Expected pending tx to be superseded by the mined log")
+	}
+
+	pruned := PrunePendingTxEvents([]*nostr.Event{pending, other, mined}, now)
+	if len(pruned) != 2 || pruned[0] != other || pruned[1] != mined {
+		t.Errorf("Expected only the unmined pending tx and the mined log, got %d events", len(pruned))
+	}
+
+	pruned = PrunePendingTxEvents([]*nostr.Event{other}, now.Add(2*time.Minute))
+	if len(pruned) != 0 {
+		t.Errorf("Expected the expired pending tx to be pruned, got %d events", len(pruned))
+	}
+}
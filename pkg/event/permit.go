@@ -0,0 +1,222 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for signature based allowances
+const (
+	KindTxPermit = 111023
+
+	EventTypeTxPermitCreated EventTypeTxPermit = "tx_permit_created"
+)
+
+type EventTypeTxPermit string
+
+// PermitSource is the mechanism that granted a signature based allowance
+type PermitSource string
+
+const (
+	PermitSourceEIP2612 PermitSource = "eip2612" // permit of the token, sets its ERC20 allowance
+	PermitSourcePermit2 PermitSource = "permit2" // Uniswap Permit2, allowances held by Permit2
+)
+
+// Permit is an allowance granted, or revoked, by a signature of the owner instead of an approve
+// transaction of the owner
+type Permit struct {
+	Source     PermitSource `json:"source"`
+	ChainID    string       `json:"chain_id"`
+	Token      string       `json:"token"`
+	Owner      string       `json:"owner"`
+	Spender    string       `json:"spender"`
+	Value      string       `json:"value"`
+	Expiration int64        `json:"expiration,omitempty"` // Unix time, deadline of an EIP-2612 signature or expiration of a Permit2 allowance
+	Nonce      string       `json:"nonce,omitempty"`
+	TxHash     string       `json:"tx_hash"`
+	LogHash    string       `json:"log_hash,omitempty"` // Set for the permits decoded from a Permit2 log
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// TxPermitEvent represents a Nostr event for a signature based allowance
+type TxPermitEvent struct {
+	Permit    Permit            `json:"permit"`
+	EventType EventTypeTxPermit `json:"event_type"`
+}
+
+// permit2Decoder normalizes the data of the Permit2 allowance logs
+var permit2Decoder = newFieldDecoder(fieldAliases{
+	neth.DataKeyOwner:   {"owner"},
+	neth.DataKeySpender: {"spender"},
+	"token":             {"token"},
+	"amount":            {"amount", "value"},
+	"expiration":        {"expiration"},
+	"nonce":             {"nonce"},
+})
+
+// GetPermitFromLog decodes the allowance set by a Permit2 Approval, Permit or Lockdown log, a
+// lockdown revokes the allowance
+func GetPermitFromLog(log neth.Log) (*Permit, error) {
+	topic := strings.ToLower(log.Topic)
+	if topic != neth.TopicPermit2Approval && topic != neth.TopicPermit2Permit && topic != neth.TopicPermit2Lockdown {
+		return nil, fmt.Errorf("topic is not a Permit2 allowance")
+	}
+
+	values, err := permit2Decoder(log)
+	if err != nil {
+		return nil, err
+	}
+
+	permit := &Permit{
+		Source:    PermitSourcePermit2,
+		ChainID:   log.ChainID,
+		Token:     values["token"],
+		Owner:     values[neth.DataKeyOwner],
+		Spender:   values[neth.DataKeySpender],
+		Value:     values["amount"],
+		Nonce:     values["nonce"],
+		TxHash:    log.TxHash,
+		LogHash:   log.Hash,
+		UpdatedAt: log.CreatedAt,
+	}
+	if permit.Owner == "" || permit.Spender == "" || permit.Token == "" {
+		return nil, fmt.Errorf("owner, spender or token not found in Permit2 data")
+	}
+
+	if topic == neth.TopicPermit2Lockdown {
+		permit.Value = "0"
+	} else if _, ok := new(big.Int).SetString(permit.Value, 10); !ok {
+		return nil, fmt.Errorf("amount is not a valid integer")
+	}
+
+	if expiration, ok := values["expiration"]; ok {
+		if permit.Expiration, err = strconv.ParseInt(expiration, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid expiration: %w", err)
+		}
+	}
+
+	return permit, nil
+}
+
+// GetPermitFromCall decodes the allowance set by an EIP-2612 permit call to a token, e.g. from
+// the input of a transaction or a call of a user operation
+func GetPermitFromCall(chainID, token, txHash string, callData []byte, at time.Time) (*Permit, error) {
+	call, err := neth.DecodePermitCall(callData)
+	if err != nil {
+		return nil, err
+	}
+	if !call.Deadline.IsInt64() {
+		return nil, fmt.Errorf("deadline out of range: %s", call.Deadline)
+	}
+
+	return &Permit{
+		Source:     PermitSourceEIP2612,
+		ChainID:    chainID,
+		Token:      token,
+		Owner:      call.Owner.Hex(),
+		Spender:    call.Spender.Hex(),
+		Value:      call.Value.String(),
+		Expiration: call.Deadline.Int64(),
+		TxHash:     txHash,
+		UpdatedAt:  at,
+	}, nil
+}
+
+// CreateTxPermitEvent creates a new Nostr event for a signature based allowance
+func CreateTxPermitEvent(permit Permit, opts ...LogOption) (*nostr.Event, error) {
+	options := newLogOptions(opts)
+
+	if permit.ChainID == "" || permit.Token == "" || permit.Owner == "" || permit.Spender == "" || permit.TxHash == "" {
+		return nil, fmt.Errorf("permit needs a chain, a token, an owner, a spender and a transaction")
+	}
+	value, ok := new(big.Int).SetString(permit.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit value: %s", permit.Value)
+	}
+
+	// Create the event data
+	eventData := TxPermitEvent{
+		Permit:    permit,
+		EventType: EventTypeTxPermitCreated,
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(permit.UpdatedAt.Unix()),
+		Kind:      MappedKind(KindTxPermit),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Identifier, the log of Permit2 permits, the transaction and owner of EIP-2612 permits
+	id := permit.LogHash
+	if id == "" {
+		id = fmt.Sprintf("%s:%s", permit.TxHash, strings.ToLower(permit.Owner))
+	}
+	evt.Tags = append(evt.Tags, []string{"d", id})
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tx_permit"))              // Type
+	evt.Tags = append(evt.Tags, typeTag(string(permit.Source)))    // Mechanism
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})        // Blockchain
+	evt.Tags = append(evt.Tags, []string{"layer", permit.ChainID}) // Chain ID
+
+	// Reference tags for transaction hash
+	evt.Tags = append(evt.Tags, []string{"r", permit.TxHash}) // Transaction hash as reference
+
+	evt.Tags = append(evt.Tags, []string{"P", permit.Owner})   // Owner address
+	evt.Tags = append(evt.Tags, []string{"p", permit.Spender}) // Spender address
+
+	evt.Tags = append(evt.Tags, []string{"amount", permit.Value}) // Allowance
+
+	if options.sortableAmount {
+		sortable, err := EncodeSortableAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		evt.Tags = append(evt.Tags, []string{"amount_sortable", sortable})
+	}
+
+	// Token address tag
+	evt.Tags = append(evt.Tags, typeTag(permit.Token))
+
+	if permit.Expiration > 0 {
+		// Not the NIP-40 expiration tag, relays would delete the event
+		evt.Tags = append(evt.Tags, []string{"deadline", strconv.FormatInt(permit.Expiration, 10)})
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an evm token allowance signed with %s on chain %s\n Owner: %s\n Spender: %s\n Amount: %s", permit.Source, permit.ChainID, permit.Owner, permit.Spender, permit.Value)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTxPermitEvent parses a Nostr event back into a TxPermitEvent
+func ParseTxPermitEvent(evt *nostr.Event) (*TxPermitEvent, error) {
+	if DefaultKind(evt.Kind) != KindTxPermit {
+		return nil, fmt.Errorf("event is not a permit event (kind %d)", evt.Kind)
+	}
+
+	var permitEvent TxPermitEvent
+	if err := unmarshalContent(evt, &permitEvent); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal permit event: %w", err)
+	}
+
+	return &permitEvent, nil
+}
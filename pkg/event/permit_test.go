@@ -0,0 +1,123 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	permitOwner   = "0x1111111111111111111111111111111111111111"
+	permitSpender = "0x2222222222222222222222222222222222222222"
+	permitToken   = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+)
+
+func permit2Log(topic, hash, amount string, at time.Time) neth.Log {
+	data := json.RawMessage(`{"owner":"` + permitOwner + `","token":"` + permitToken + `","spender":"` + permitSpender + `","amount":` + amount + `,"expiration":1800000000,"nonce":3}`)
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     topic,
+		CreatedAt: at,
+		To:        neth.Permit2Address,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+func TestPermit2Events(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	permit, err := GetPermitFromLog(permit2Log(neth.TopicPermit2Permit, "0x01", "1000", at))
+	if err != nil {
+		t.Fatalf("Failed to decode Permit2 log: %v", err)
+	}
+	if permit.Source != PermitSourcePermit2 || permit.Value != "1000" || permit.Expiration != 1800000000 || permit.Nonce != "3" || permit.Token != permitToken {
+		t.Errorf("Expected the Permit2 permit, got %+v", permit)
+	}
+
+	evt, err := CreateTxPermitEvent(*permit, WithSortableAmount())
+	if err != nil {
+		t.Fatalf("Failed to create permit event: %v", err)
+	}
+	if evt.Kind != KindTxPermit || !evt.Tags.ContainsAny("t", []string{"permit2"}) || evt.Tags.GetFirst([]string{"expiration"}) != nil {
+		t.Errorf("Expected a permit2 event without NIP-40 expiration, got %v", evt.Tags)
+	}
+	if tag := evt.Tags.GetFirst([]string{"d"}); tag == nil || (*tag)[1] != "0x01" {
+		t.Errorf("Expected the log hash as identifier, got %v", tag)
+	}
+
+	parsed, err := ParseTxPermitEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse permit event: %v", err)
+	}
+	if parsed.Permit.Owner != permitOwner || parsed.EventType != EventTypeTxPermitCreated {
+		t.Errorf("Expected the permit back, got %+v", parsed)
+	}
+
+	if _, err := GetPermitFromLog(goldenLog()); err == nil {
+		t.Error("Expected a transfer log to fail")
+	}
+
+	// Tx log events of Permit2 logs are tagged by the default topic registry
+	txLog, err := CreateTxLogEvent(permit2Log(neth.TopicPermit2Approval, "0x02", "5", at))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if !txLog.Tags.ContainsAny("t", []string{"permit2_approval"}) {
+		t.Errorf("Expected the permit2_approval tag, got %v", txLog.Tags)
+	}
+}
+
+func TestAllowanceTrackerPermits(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	tracker := NewAllowanceTracker()
+
+	// EIP-2612 permits set the ERC20 allowance
+	callData := append([]byte{}, neth.FuncSigPermit...)
+	for _, word := range [][]byte{common.HexToAddress(permitOwner).Bytes(), common.HexToAddress(permitSpender).Bytes(), big.NewInt(700).Bytes(), big.NewInt(1800000000).Bytes(), {27}, {1}, {2}} {
+		callData = append(callData, common.LeftPadBytes(word, 32)...)
+	}
+	permit, err := GetPermitFromCall("100", permitToken, "0xdef", callData, at)
+	if err != nil {
+		t.Fatalf("Failed to decode permit call: %v", err)
+	}
+	evt, err := CreateTxPermitEvent(*permit)
+	if err != nil {
+		t.Fatalf("Failed to create permit event: %v", err)
+	}
+	if err := tracker.ApplyEvent(evt); err != nil {
+		t.Fatalf("Failed to apply permit event: %v", err)
+	}
+	if allowance := tracker.Allowance("100", permitToken, permitOwner, permitSpender); allowance.Int64() != 700 {
+		t.Errorf("Expected an ERC20 allowance of 700, got %s", allowance)
+	}
+
+	// Permit2 allowances are tracked apart, a lockdown revokes them
+	approval, _ := CreateTxLogEvent(permit2Log(neth.TopicPermit2Approval, "0x01", "1000", at))
+	if err := tracker.ApplyEvent(approval); err != nil {
+		t.Fatalf("Failed to apply Permit2 approval: %v", err)
+	}
+	if allowance := tracker.Permit2Allowance("100", permitToken, permitOwner, permitSpender); allowance.Int64() != 1000 {
+		t.Errorf("Expected a Permit2 allowance of 1000, got %s", allowance)
+	}
+	if allowances := tracker.AllowancesForOwner("100", permitOwner); len(allowances) != 2 || allowances[1].Via != PermitSourcePermit2 {
+		t.Errorf("Expected the ERC20 and Permit2 allowances, got %+v", allowances)
+	}
+
+	lockdown, _ := CreateTxLogEvent(permit2Log(neth.TopicPermit2Lockdown, "0x02", "0", at.Add(time.Minute)))
+	if err := tracker.ApplyEvent(lockdown); err != nil {
+		t.Fatalf("Failed to apply Permit2 lockdown: %v", err)
+	}
+	if allowance := tracker.Permit2Allowance("100", permitToken, permitOwner, permitSpender); allowance.Sign() != 0 {
+		t.Errorf("Expected the Permit2 allowance revoked, got %s", allowance)
+	}
+	if allowance := tracker.Allowance("100", permitToken, permitOwner, permitSpender); allowance.Int64() != 700 {
+		t.Errorf("Expected the ERC20 allowance kept, got %s", allowance)
+	}
+}
@@ -0,0 +1,99 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// IsAddressInEvent checks if an address is referenced by the p or P tags of an event,
+// the comparison is case-insensitive since addresses may or may not be checksummed
+func IsAddressInEvent(evt *nostr.Event, address string) bool {
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && (tag[0] == "p" || tag[0] == "P") && strings.EqualFold(tag[1], address) {
+			return true
+		}
+	}
+	return false
+}
+
+// LatestLogsForAddress returns the latest version of every transaction log involving an address.
+// Tx log and transfer events are grouped by their d tag, the newest event of each group wins,
+// and the decoded logs are returned newest first, logs created at the same time by block number.
+// Events that fail to parse are skipped, the newest event of their group that parses wins.
+func LatestLogsForAddress(events []*nostr.Event, address string) ([]neth.Log, error) {
+	sorted := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if evt == nil || (DefaultKind(evt.Kind) != KindTxLog && !IsTxTransferEvent(evt)) {
+			continue
+		}
+		if !IsAddressInEvent(evt, address) {
+			continue
+		}
+		sorted = append(sorted, evt)
+	}
+
+	SortEventsByCreatedAt(sorted, true)
+
+	seen := make(map[string]bool)
+	logs := make([]neth.Log, 0, len(sorted))
+	blocks := make(map[string]uint64, len(sorted))
+	for _, evt := range sorted {
+		d := evt.Tags.GetD()
+		if seen[d] {
+			continue
+		}
+
+		log, err := logFromEvent(evt)
+		if err != nil {
+			continue
+		}
+		seen[d] = true
+
+		logs = append(logs, *log)
+		blocks[log.Hash] = blockNumber(evt)
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if !logs[i].CreatedAt.Equal(logs[j].CreatedAt) {
+			return logs[i].CreatedAt.After(logs[j].CreatedAt)
+		}
+		return blocks[logs[i].Hash] > blocks[logs[j].Hash]
+	})
+
+	return logs, nil
+}
+
+// blockNumber returns the block number of the block tag of an event, 0 when it is unknown
+func blockNumber(evt *nostr.Event) uint64 {
+	if tag := evt.Tags.GetFirst([]string{"block", ""}); tag != nil {
+		if number, err := strconv.ParseUint((*tag)[1], 10, 64); err == nil {
+			return number
+		}
+	}
+	return 0
+}
+
+// logFromEvent returns the log of a tx log or transfer event
+func logFromEvent(evt *nostr.Event) (*neth.Log, error) {
+	switch {
+	case IsTxTransferEvent(evt):
+		transfer, err := ParseTxTransferEvent(evt)
+		if err != nil {
+			return nil, err
+		}
+		return &transfer.LogData, nil
+	case DefaultKind(evt.Kind) == KindTxLog:
+		txLog, err := ParseTxLogEvent(evt)
+		if err != nil {
+			return nil, err
+		}
+		return &txLog.LogData, nil
+	default:
+		return nil, fmt.Errorf("event is not a tx log or transfer event (kind %d)", evt.Kind)
+	}
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestLatestLogsForAddress(t *testing.T) {
+	bob := "0x2222222222222222222222222222222222222222"
+	createdAt := time.Unix(1700000000, 0)
+
+	newLog := func(hash, sender string, createdAt time.Time, blockNumber uint64, eventAt nostr.Timestamp) *nostr.Event {
+		t.Helper()
+		evt, err := CreateTxLogEvent(neth.Log{
+			Hash:      hash,
+			TxHash:    "0xabc",
+			ChainID:   "100",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: createdAt,
+			Sender:    sender,
+			To:        "0x1111111111111111111111111111111111111111",
+			Value:     big.NewInt(int64(blockNumber)),
+		}, WithBlockNumber(blockNumber))
+		if err != nil {
+			t.Fatalf("Failed to create tx log event: %v", err)
+		}
+		evt.CreatedAt = eventAt
+		evt.ID = evt.GetID()
+		return evt
+	}
+
+	events := []*nostr.Event{
+		newLog("0x5", bob, createdAt, 5, 100),
+		newLog("0x9", bob, createdAt, 9, 100),
+		newLog("0x7", bob, createdAt, 7, 100),
+		newLog("0x1", bob, createdAt.Add(time.Minute), 1, 100),
+		newLog("0x5", bob, createdAt, 6, 200), // Newer version of 0x5
+		newLog("0x8", "0x3333333333333333333333333333333333333333", createdAt, 8, 100),
+	}
+
+	logs, err := LatestLogsForAddress(events, bob)
+	if err != nil {
+		t.Fatalf("Failed to get logs: %v", err)
+	}
+
+	expected := []string{"0x1", "0x9", "0x7", "0x5"}
+	if len(logs) != len(expected) {
+		t.Fatalf("Expected %d logs, got %d", len(expected), len(logs))
+	}
+	for i, hash := range expected {
+		if logs[i].Hash != hash {
+			t.Errorf("Expected log %d to be %s, got %s", i, hash, logs[i].Hash)
+		}
+	}
+	if logs[3].Value.Int64() != 6 {
+		t.Errorf("Expected the newest version of 0x5, got value %s", logs[3].Value)
+	}
+
+	// Events that fail to parse are skipped, an older version of their log is kept
+	broken := newLog("0x9", bob, createdAt, 10, 300)
+	broken.Content = "{"
+	invalid := newLog("0x4", bob, createdAt, 4, 100)
+	invalid.Content = "not json"
+
+	logs, err = LatestLogsForAddress(append(events, broken, invalid), bob)
+	if err != nil {
+		t.Fatalf("Failed to get logs: %v", err)
+	}
+	if len(logs) != len(expected) {
+		t.Fatalf("Expected %d logs, got %d", len(expected), len(logs))
+	}
+	if logs[1].Hash != "0x9" || logs[1].Value.Int64() != 9 {
+		t.Errorf("Expected the version of 0x9 that parses, got %s with value %s", logs[1].Hash, logs[1].Value)
+	}
+}
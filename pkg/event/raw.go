@@ -0,0 +1,63 @@
+package event
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// jsonFieldNames returns the JSON keys of the fields of a struct type
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// mergeUnknownFields adds the fields of raw that are not part of the known fields to the
+// marshaled content, so data from newer versions of the format survives a round-trip.
+// The content is returned untouched when raw holds no unknown fields.
+func mergeUnknownFields(content []byte, raw json.RawMessage, known map[string]bool) ([]byte, error) {
+	if len(raw) == 0 {
+		return content, nil
+	}
+
+	var rawFields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &rawFields); err != nil {
+		// Raw content that is not an object cannot carry extra fields
+		return content, nil
+	}
+
+	unknown := make(map[string]json.RawMessage)
+	for key, value := range rawFields {
+		if !known[key] {
+			unknown[key] = value
+		}
+	}
+
+	if len(unknown) == 0 {
+		return content, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range unknown {
+		fields[key] = value
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,200 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindFileMetadata = 1063 // NIP-94 file metadata, describes the metadata JSON of receipts
+
+	// DefaultReceiptName is the name of receipts created without one
+	DefaultReceiptName = "Payment receipt"
+)
+
+// ReceiptConfig configures the receipts of a project minting payment receipts
+type ReceiptConfig struct {
+	BaseURI     string // Prefix of the token URIs, the metadata JSON is served at BaseURI/<token ID>.json
+	Name        string // Name of the receipts, DefaultReceiptName when empty
+	Image       string // Image of the receipts
+	ExternalURL string // Page of the project
+
+	// Symbol and Decimals of the token, looked up in the default token registry when not set
+	Symbol   string
+	Decimals int64
+}
+
+// ReceiptAttribute is an attribute of an ERC-721 metadata JSON
+type ReceiptAttribute struct {
+	TraitType   string `json:"trait_type"`
+	Value       any    `json:"value"`
+	DisplayType string `json:"display_type,omitempty"`
+}
+
+// ReceiptMetadata is the ERC-721 metadata JSON of a receipt
+type ReceiptMetadata struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Image       string             `json:"image,omitempty"`
+	ExternalURL string             `json:"external_url,omitempty"`
+	Attributes  []ReceiptAttribute `json:"attributes"`
+}
+
+// ReceiptNFT is a receipt ready to be minted, its token ID, URI and metadata JSON
+type ReceiptNFT struct {
+	TokenID  *big.Int
+	TokenURI string
+	Metadata ReceiptMetadata
+	JSON     []byte // Metadata JSON to serve at the token URI
+}
+
+// ReceiptTokenID returns the deterministic token ID of the receipt of a log, keccak256 of the
+// chain ID and the log hash, so that a transfer can only have one receipt
+func ReceiptTokenID(chainID, logHash string) *big.Int {
+	return new(big.Int).SetBytes(crypto.Keccak256([]byte(chainID), []byte(strings.ToLower(logHash))))
+}
+
+// ReceiptTokenURI returns the token URI of a receipt, BaseURI/<token ID>.json
+func ReceiptTokenURI(baseURI string, tokenID *big.Int) string {
+	return strings.TrimRight(baseURI, "/") + "/" + tokenID.String() + ".json"
+}
+
+// CreateReceiptNFT creates the receipt NFT of a confirmed transfer, given as a transfer event or
+// a tx log event of a transfer. Tx log events must be confirmed, transfer events are only
+// published for included logs.
+func CreateReceiptNFT(transfer *nostr.Event, config ReceiptConfig) (*ReceiptNFT, error) {
+	if DefaultKind(transfer.Kind) == KindTxLog {
+		txLog, err := ParseTxLogEvent(transfer)
+		if err != nil {
+			return nil, err
+		}
+		if txLog.Status.Before(TxLogStatusConfirmed) || txLog.Status == TxLogStatusOrphaned {
+			return nil, fmt.Errorf("transfer is not confirmed (status %q)", txLog.Status)
+		}
+	}
+
+	log, err := logFromEvent(transfer)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+		return nil, fmt.Errorf("log is not an ERC20 transfer")
+	}
+
+	data, err := log.GetTransferData()
+	if err != nil || data == nil {
+		return nil, fmt.Errorf("transfer has no transfer data")
+	}
+	amount, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid transfer amount: %s", data.Value)
+	}
+
+	symbol, decimals := config.Symbol, config.Decimals
+	if token, ok := DefaultTokenRegistry.Token(log.ChainID, log.To); ok {
+		if symbol == "" {
+			symbol = token.Symbol
+		}
+		if decimals == 0 {
+			decimals = token.Decimals
+		}
+	}
+	if symbol == "" {
+		symbol = log.To
+	}
+
+	name := config.Name
+	if name == "" {
+		name = DefaultReceiptName
+	}
+
+	tokenID := ReceiptTokenID(log.ChainID, log.Hash)
+	formatted := neth.FormatUnits(amount, decimals)
+
+	metadata := ReceiptMetadata{
+		Name:        fmt.Sprintf("%s #%s", name, tokenID.Text(16)[:8]),
+		Description: fmt.Sprintf("Receipt of %s %s from %s to %s on chain %s, transaction %s", formatted, symbol, data.From, data.To, log.ChainID, log.TxHash),
+		Image:       config.Image,
+		ExternalURL: config.ExternalURL,
+		Attributes: []ReceiptAttribute{
+			{TraitType: "chain_id", Value: log.ChainID},
+			{TraitType: "token", Value: log.To},
+			{TraitType: "symbol", Value: symbol},
+			{TraitType: "amount", Value: formatted},
+			{TraitType: "from", Value: data.From},
+			{TraitType: "to", Value: data.To},
+			{TraitType: "tx_hash", Value: log.TxHash},
+			{TraitType: "date", Value: log.CreatedAt.Unix(), DisplayType: "date"},
+		},
+	}
+
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptNFT{
+		TokenID:  tokenID,
+		TokenURI: ReceiptTokenURI(config.BaseURI, tokenID),
+		Metadata: metadata,
+		JSON:     content,
+	}, nil
+}
+
+// CreateReceiptFileEvent creates a NIP-94 file metadata event (kind 1063) describing the metadata
+// JSON of a receipt, so that clients can find and check it from the transfer
+func CreateReceiptFileEvent(transfer *nostr.Event, receipt *ReceiptNFT, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if transfer == nil || transfer.ID == "" {
+		return nil, fmt.Errorf("transfer must have an ID")
+	}
+
+	log, err := logFromEvent(transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(receipt.JSON)
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindFileMetadata),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   receipt.Metadata.Description,
+	}
+
+	// File tags (NIP-94)
+	evt.Tags = append(evt.Tags, []string{"url", receipt.TokenURI})
+	evt.Tags = append(evt.Tags, []string{"m", "application/json"})
+	evt.Tags = append(evt.Tags, []string{"x", hex.EncodeToString(hash[:])})
+	evt.Tags = append(evt.Tags, []string{"size", strconv.Itoa(len(receipt.JSON))})
+	if receipt.Metadata.Image != "" {
+		evt.Tags = append(evt.Tags, []string{"image", receipt.Metadata.Image})
+	}
+
+	// References to the transfer
+	evt.Tags = append(evt.Tags, []string{"e", transfer.ID, references.eventRelay(transfer)})
+	evt.Tags = append(evt.Tags, []string{"r", log.TxHash}) // Transaction hash
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("receipt"))         // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+	evt.Tags = append(evt.Tags, []string{"layer", log.ChainID})
+	evt.Tags = append(evt.Tags, []string{"token_id", receipt.TokenID.String()})
+
+	// Alt tag
+	evt.Tags = append(evt.Tags, []string{"alt", receipt.Metadata.Name})
+
+	return evt, nil
+}
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCreateReceiptNFT(t *testing.T) {
+	transfer, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	transfer.ID = strings.Repeat("ab", 32)
+
+	config := ReceiptConfig{BaseURI: "https://receipts.example.com/", Symbol: "EURe", Decimals: 18, Image: "ipfs://receipt"}
+	receipt, err := CreateReceiptNFT(transfer, config)
+	if err != nil {
+		t.Fatalf("Failed to create receipt: %v", err)
+	}
+
+	// Token IDs and URIs are deterministic
+	expectedID := ReceiptTokenID(goldenLog().ChainID, goldenLog().Hash)
+	if receipt.TokenID.Cmp(expectedID) != 0 {
+		t.Errorf("Expected token ID %s, got %s", expectedID, receipt.TokenID)
+	}
+	if expected := "https://receipts.example.com/" + expectedID.String() + ".json"; receipt.TokenURI != expected {
+		t.Errorf("Expected token URI %s, got %s", expected, receipt.TokenURI)
+	}
+	again, _ := CreateReceiptNFT(transfer, config)
+	if string(again.JSON) != string(receipt.JSON) {
+		t.Error("Expected the same metadata JSON for the same transfer")
+	}
+
+	var metadata map[string]any
+	if err := json.Unmarshal(receipt.JSON, &metadata); err != nil {
+		t.Fatalf("Failed to unmarshal metadata: %v", err)
+	}
+	if metadata["image"] != "ipfs://receipt" || !strings.HasPrefix(metadata["name"].(string), DefaultReceiptName+" #") {
+		t.Errorf("Expected ERC-721 name and image, got %v", metadata)
+	}
+	attributes := map[string]any{}
+	for _, attribute := range receipt.Metadata.Attributes {
+		attributes[attribute.TraitType] = attribute.Value
+	}
+	if attributes["amount"] != "1" || attributes["symbol"] != "EURe" || attributes["chain_id"] != "100" {
+		t.Errorf("Expected 1 EURe on chain 100, got %v", attributes)
+	}
+
+	// NIP-94 event describing the metadata JSON
+	file, err := CreateReceiptFileEvent(transfer, receipt)
+	if err != nil {
+		t.Fatalf("Failed to create file event: %v", err)
+	}
+	if file.Kind != KindFileMetadata {
+		t.Errorf("Expected kind %d, got %d", KindFileMetadata, file.Kind)
+	}
+	hash := sha256.Sum256(receipt.JSON)
+	if tag := file.Tags.Find("x"); tag == nil || tag[1] != hex.EncodeToString(hash[:]) {
+		t.Errorf("Expected x tag with the SHA-256 of the JSON, got %v", tag)
+	}
+	if tag := file.Tags.Find("size"); tag == nil || tag[1] != strconv.Itoa(len(receipt.JSON)) {
+		t.Errorf("Expected size tag of %d, got %v", len(receipt.JSON), tag)
+	}
+	if tag := file.Tags.Find("url"); tag == nil || tag[1] != receipt.TokenURI {
+		t.Errorf("Expected url tag %s, got %v", receipt.TokenURI, tag)
+	}
+	if tag := file.Tags.Find("e"); tag == nil || tag[1] != transfer.ID {
+		t.Errorf("Expected e tag of the transfer, got %v", tag)
+	}
+}
+
+func TestCreateReceiptNFTRequiresConfirmation(t *testing.T) {
+	txLog, err := CreateTxLogEvent(goldenLog(), WithBlockNumber(100))
+	if err != nil {
+		t.Fatalf("Failed to create tx log: %v", err)
+	}
+
+	if _, err := CreateReceiptNFT(txLog, ReceiptConfig{}); err == nil {
+		t.Error("Expected an error for an included log")
+	}
+
+	confirmed, err := UpdateTxLogStatus(txLog, TxLogStatusConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if _, err := CreateReceiptNFT(confirmed, ReceiptConfig{}); err != nil {
+		t.Errorf("Expected a receipt for a confirmed log, got %v", err)
+	}
+
+	orphaned, err := UpdateTxLogStatus(txLog, TxLogStatusOrphaned)
+	if err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if _, err := CreateReceiptNFT(orphaned, ReceiptConfig{}); err == nil {
+		t.Error("Expected an error for an orphaned log")
+	}
+}
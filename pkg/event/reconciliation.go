@@ -0,0 +1,103 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for reconciliation reports
+const (
+	KindReconciliationReport = 111012
+
+	EventTypeReconciliationCompleted EventTypeReconciliation = "reconciliation_completed"
+)
+
+type EventTypeReconciliation string
+
+// AddressReconciliation is the result of the reconciliation of one contract address
+type AddressReconciliation struct {
+	Address   string   `json:"address"`
+	LastBlock uint64   `json:"last_block"`        // Highest block with a published log, 0 when none was found
+	Checked   int      `json:"checked"`           // Number of logs found on chain in the range
+	Missing   []string `json:"missing,omitempty"` // Hashes of the logs that had no published event
+}
+
+// ReconciliationReport is the result of comparing the published tx log events with the chain
+type ReconciliationReport struct {
+	ChainID      string                  `json:"chain_id"`
+	FromBlock    uint64                  `json:"from_block"`
+	ToBlock      uint64                  `json:"to_block"`
+	Addresses    []AddressReconciliation `json:"addresses"`
+	Backfilled   int                     `json:"backfilled"` // Number of events published to fill the gaps
+	ReconciledAt time.Time               `json:"reconciled_at"`
+}
+
+// ReconciliationReportEvent represents a Nostr event reporting a reconciliation run
+type ReconciliationReportEvent struct {
+	Report    ReconciliationReport    `json:"report"`
+	EventType EventTypeReconciliation `json:"event_type"`
+	Tags      []string                `json:"tags,omitempty"`
+}
+
+// CreateReconciliationReportEvent creates a new Nostr event reporting a reconciliation run
+func CreateReconciliationReportEvent(report ReconciliationReport) (*nostr.Event, error) {
+	// Create the event data
+	eventData := ReconciliationReportEvent{
+		Report:    report,
+		EventType: EventTypeReconciliationCompleted,
+		Tags:      []string{"reconciliation", "evm", report.ChainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(report.ReconciledAt.Unix()),
+		Kind:      MappedKind(KindReconciliationReport), // Custom kind for reconciliation reports
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("reconciliation"))  // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", report.ChainID}) // Chain ID
+
+	// Block range tags
+	evt.Tags = append(evt.Tags, []string{"from_block", strconv.FormatUint(report.FromBlock, 10)})
+	evt.Tags = append(evt.Tags, []string{"to_block", strconv.FormatUint(report.ToBlock, 10)})
+
+	// Contract address tags
+	for _, address := range report.Addresses {
+		evt.Tags = append(evt.Tags, []string{"p", address.Address})
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a reconciliation report of blocks %d to %d on chain %s, %d missing logs were backfilled",
+		report.FromBlock, report.ToBlock, report.ChainID, report.Backfilled)
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseReconciliationReportEvent parses a Nostr event back into a ReconciliationReportEvent
+func ParseReconciliationReportEvent(evt *nostr.Event) (*ReconciliationReportEvent, error) {
+	var reportEvent ReconciliationReportEvent
+	err := unmarshalContent(evt, &reportEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &reportEvent, nil
+}
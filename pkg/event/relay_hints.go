@@ -0,0 +1,129 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// RelayHintProvider tells where referenced events and public keys can be found, the relay
+// hints of e, p and q tags (NIP-10, NIP-18). An empty URL means no hint.
+type RelayHintProvider interface {
+	EventRelay(eventID string) string
+	PubKeyRelay(pubkey string) string
+}
+
+// RelayHints is a RelayHintProvider backed by maps, Default is used for events and public keys
+// without an entry
+type RelayHints struct {
+	Default string
+	Events  map[string]string // Event ID -> relay URL
+	PubKeys map[string]string // Public key -> relay URL, e.g. from the NIP-65 relay lists
+}
+
+// EventRelay returns the relay of an event
+func (h *RelayHints) EventRelay(eventID string) string {
+	if relay, ok := h.Events[eventID]; ok {
+		return relay
+	}
+	return h.Default
+}
+
+// PubKeyRelay returns the relay of a public key
+func (h *RelayHints) PubKeyRelay(pubkey string) string {
+	if relay, ok := h.PubKeys[pubkey]; ok {
+		return relay
+	}
+	return h.Default
+}
+
+// relayHintProvider is the provider used by constructors called without a relay hint option
+var relayHintProvider = struct {
+	sync.RWMutex
+	provider RelayHintProvider
+}{}
+
+// SetRelayHintProvider sets the provider used by the constructors called without a relay hint
+// option, nil leaves the hints out, the default
+func SetRelayHintProvider(provider RelayHintProvider) {
+	relayHintProvider.Lock()
+	defer relayHintProvider.Unlock()
+
+	relayHintProvider.provider = provider
+}
+
+// ReferenceOption configures the tags referencing other events and public keys
+type ReferenceOption func(*referenceOptions)
+
+type referenceOptions struct {
+	hints    RelayHintProvider
+	mentions []string
+}
+
+// newReferenceOptions applies the given options on top of the provider set with
+// SetRelayHintProvider
+func newReferenceOptions(opts []ReferenceOption) *referenceOptions {
+	relayHintProvider.RLock()
+	o := &referenceOptions{hints: relayHintProvider.provider}
+	relayHintProvider.RUnlock()
+
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRelayHint uses the same relay hint for every referenced event and public key
+func WithRelayHint(relayURL string) ReferenceOption {
+	return func(o *referenceOptions) {
+		o.hints = &RelayHints{Default: relayURL}
+	}
+}
+
+// WithRelayHintProvider looks up the relay hint of every referenced event and public key,
+// passing nil leaves the hints out
+func WithRelayHintProvider(provider RelayHintProvider) ReferenceOption {
+	return func(o *referenceOptions) {
+		o.hints = provider
+	}
+}
+
+// WithMentions mentions public keys in a message, with a nostr:npub URI in the content and a p
+// tag each (NIP-27)
+func WithMentions(pubkeys ...string) ReferenceOption {
+	return func(o *referenceOptions) {
+		o.mentions = append(o.mentions, pubkeys...)
+	}
+}
+
+// eventRelay returns the relay hint of an event, falling back to the relay of its author
+func (o *referenceOptions) eventRelay(evt *nostr.Event) string {
+	if o.hints == nil {
+		return ""
+	}
+
+	if relay := o.hints.EventRelay(evt.ID); relay != "" {
+		return relay
+	}
+	if evt.PubKey != "" {
+		return o.hints.PubKeyRelay(evt.PubKey)
+	}
+	return ""
+}
+
+// pubKeyRelay returns the relay hint of a public key
+func (o *referenceOptions) pubKeyRelay(pubkey string) string {
+	if o.hints == nil {
+		return ""
+	}
+	return o.hints.PubKeyRelay(pubkey)
+}
+
+// hintedTag returns a reference tag with its relay hint, the hint is left out when empty so that
+// tags without one keep their short form
+func hintedTag(name, value, relay string) nostr.Tag {
+	if relay == "" {
+		return nostr.Tag{name, value}
+	}
+	return nostr.Tag{name, value, relay}
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRelayHints(t *testing.T) {
+	parent := &nostr.Event{ID: "aa", PubKey: goldenPubKey, Kind: 1, Tags: nostr.Tags{{"p", "bb"}}}
+
+	reply, err := CreateReplyEvent("gm", nil, parent, WithRelayHintProvider(&RelayHints{
+		Events:  map[string]string{"aa": "wss://events.example.com"},
+		PubKeys: map[string]string{"bb": "wss://bb.example.com"},
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create reply: %v", err)
+	}
+
+	if tag := reply.Tags.Find("e"); tag[2] != "wss://events.example.com" || tag[3] != "reply" {
+		t.Errorf("Expected e tag with relay hint and reply marker, got %v", tag)
+	}
+	if tag := reply.Tags.Find("p"); len(tag) != 3 || tag[2] != "wss://bb.example.com" {
+		t.Errorf("Expected p tag with relay hint, got %v", tag)
+	}
+
+	quote, err := CreateQuoteRepostEvent("look", nil, parent, "", WithRelayHint("wss://relay.example.com"))
+	if err != nil {
+		t.Fatalf("Failed to create quote: %v", err)
+	}
+	if tag := quote.Tags.Find("q"); tag[2] != "wss://relay.example.com" {
+		t.Errorf("Expected q tag with relay hint, got %v", tag)
+	}
+}
+
+func TestRelayHintProviderDefault(t *testing.T) {
+	SetRelayHintProvider(&RelayHints{PubKeys: map[string]string{goldenPubKey: "wss://author.example.com"}})
+	t.Cleanup(func() { SetRelayHintProvider(nil) })
+
+	evt, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	evt.PubKey = goldenPubKey
+	evt.ID = evt.GetID()
+
+	update, err := UpdateTxLogStatus(evt, TxLogStatusFinalized)
+	if err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if tag := update.Tags.Find("e"); len(tag) != 3 || tag[2] != "wss://author.example.com" {
+		t.Errorf("Expected e tag with the relay of the author, got %v", tag)
+	}
+
+	update, err = UpdateTxLogStatus(evt, TxLogStatusFinalized, WithRelayHintProvider(nil))
+	if err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if tag := update.Tags.Find("e"); len(tag) != 2 {
+		t.Errorf("Expected e tag without relay hint, got %v", tag)
+	}
+}
@@ -0,0 +1,158 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindRelayList = 10002 // NIP-65 relay list metadata
+
+	// DefaultRelayListTTL is how long resolved relay lists are cached
+	DefaultRelayListTTL = 10 * time.Minute
+
+	// MaxOutboxRelaysPerUser caps the read relays of each tagged user an event is copied to
+	MaxOutboxRelaysPerUser = 3
+)
+
+// RelayList is the NIP-65 relay list of a user, the relays they read from (their inbox) and the
+// relays they write to (their outbox)
+type RelayList struct {
+	PubKey string   `json:"pubkey"`
+	Read   []string `json:"read"`
+	Write  []string `json:"write"`
+}
+
+// ParseRelayListEvent parses a NIP-65 relay list (kind 10002), relays marked neither read nor
+// write are used for both
+func ParseRelayListEvent(evt *nostr.Event) (*RelayList, error) {
+	if DefaultKind(evt.Kind) != KindRelayList {
+		return nil, fmt.Errorf("event is not a relay list event (kind %d)", evt.Kind)
+	}
+
+	list := &RelayList{PubKey: evt.PubKey}
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "r" || !nostr.IsValidRelayURL(tag[1]) {
+			continue
+		}
+
+		url := nostr.NormalizeURL(tag[1])
+		marker := ""
+		if len(tag) > 2 {
+			marker = tag[2]
+		}
+
+		if marker != "write" && !slices.Contains(list.Read, url) {
+			list.Read = append(list.Read, url)
+		}
+		if marker != "read" && !slices.Contains(list.Write, url) {
+			list.Write = append(list.Write, url)
+		}
+	}
+
+	return list, nil
+}
+
+// cachedRelayList is a resolved relay list, nil when the user has none
+type cachedRelayList struct {
+	list       *RelayList
+	resolvedAt time.Time
+}
+
+// RelayListResolver resolves the NIP-65 relay lists of users from a querier, e.g. a MultiReader
+// over well-connected relays, and caches them. It selects the relays events tagging users are
+// copied to in the outbox model.
+type RelayListResolver struct {
+	querier EventQuerier
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedRelayList
+}
+
+// NewRelayListResolver creates a resolver caching relay lists for ttl, DefaultRelayListTTL when
+// ttl is zero
+func NewRelayListResolver(querier EventQuerier, ttl time.Duration) *RelayListResolver {
+	if ttl == 0 {
+		ttl = DefaultRelayListTTL
+	}
+	return &RelayListResolver{
+		querier: querier,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   make(map[string]cachedRelayList),
+	}
+}
+
+// RelayList returns the newest relay list of a user, nil when they have none
+func (r *RelayListResolver) RelayList(ctx context.Context, pubkey string) (*RelayList, error) {
+	r.mu.Lock()
+	cached, ok := r.cache[pubkey]
+	r.mu.Unlock()
+
+	if ok && r.now().Sub(cached.resolvedAt) < r.ttl {
+		return cached.list, nil
+	}
+
+	events, err := r.querier.Query(ctx, nostr.Filter{Kinds: MappedKinds(KindRelayList), Authors: []string{pubkey}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the relay list of %s: %w", pubkey, err)
+	}
+
+	var newest *nostr.Event
+	for _, evt := range events {
+		if DefaultKind(evt.Kind) == KindRelayList && evt.PubKey == pubkey && (newest == nil || isNewer(evt, newest)) {
+			newest = evt
+		}
+	}
+
+	var list *RelayList
+	if newest != nil {
+		if list, err = ParseRelayListEvent(newest); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[pubkey] = cachedRelayList{list: list, resolvedAt: r.now()}
+	r.mu.Unlock()
+
+	return list, nil
+}
+
+// OutboxRelays returns the read relays of the users tagged by an event, at most
+// MaxOutboxRelaysPerUser each, where copies of the event reach them. The author and Ethereum
+// addresses in p tags are left out, and users whose relay list cannot be resolved are skipped.
+func (r *RelayListResolver) OutboxRelays(ctx context.Context, evt *nostr.Event) []string {
+	var relays []string
+	seen := make(map[string]bool)
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || tag[0] != "p" || !nostr.IsValid32ByteHex(tag[1]) || tag[1] == evt.PubKey || seen[tag[1]] {
+			continue
+		}
+		seen[tag[1]] = true
+
+		list, err := r.RelayList(ctx, tag[1])
+		if err != nil || list == nil {
+			continue
+		}
+
+		for i, url := range list.Read {
+			if i == MaxOutboxRelaysPerUser {
+				break
+			}
+			if !slices.Contains(relays, url) {
+				relays = append(relays, url)
+			}
+		}
+	}
+
+	return relays
+}
@@ -0,0 +1,89 @@
+package event
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// countingQuerier counts the queries answered by a querier
+type countingQuerier struct {
+	EventQuerier
+	queries int
+}
+
+func (q *countingQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	q.queries++
+	return q.EventQuerier.Query(ctx, filter)
+}
+
+func TestParseRelayListEvent(t *testing.T) {
+	list, err := ParseRelayListEvent(&nostr.Event{
+		Kind: KindRelayList,
+		Tags: nostr.Tags{
+			{"r", "wss://both.example.com"},
+			{"r", "wss://inbox.example.com/", "read"},
+			{"r", "wss://outbox.example.com", "write"},
+			{"r", "not a relay"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse relay list: %v", err)
+	}
+
+	if !slices.Equal(list.Read, []string{"wss://both.example.com", "wss://inbox.example.com"}) {
+		t.Errorf("Expected the both and inbox relays to be read, got %v", list.Read)
+	}
+	if !slices.Equal(list.Write, []string{"wss://both.example.com", "wss://outbox.example.com"}) {
+		t.Errorf("Expected the both and outbox relays to be written, got %v", list.Write)
+	}
+
+	if _, err := ParseRelayListEvent(&nostr.Event{Kind: 1}); err == nil {
+		t.Error("Expected an error for a text note")
+	}
+}
+
+func TestRelayListResolverOutboxRelays(t *testing.T) {
+	alice := nostr.GeneratePrivateKey()
+	alicePubKey, _ := nostr.GetPublicKey(alice)
+	bobPubKey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	older := &nostr.Event{PubKey: alicePubKey, CreatedAt: 100, Kind: KindRelayList, Tags: nostr.Tags{{"r", "wss://old.example.com"}}}
+	newer := &nostr.Event{PubKey: alicePubKey, CreatedAt: 200, Kind: KindRelayList, Tags: nostr.Tags{
+		{"r", "wss://a.example.com", "read"},
+		{"r", "wss://b.example.com"},
+		{"r", "wss://c.example.com", "read"},
+		{"r", "wss://d.example.com", "read"},
+		{"r", "wss://write.example.com", "write"},
+	}}
+
+	querier := &countingQuerier{EventQuerier: memoryQuerier{older, newer}}
+	resolver := NewRelayListResolver(querier, time.Minute)
+
+	payment := &nostr.Event{Kind: KindTxTransfer, Tags: nostr.Tags{{"p", alicePubKey}, {"p", bobPubKey}, {"p", alicePubKey}}}
+	relays := resolver.OutboxRelays(context.Background(), payment)
+	if !slices.Equal(relays, []string{"wss://a.example.com", "wss://b.example.com", "wss://c.example.com"}) {
+		t.Errorf("Expected the first %d read relays of alice, got %v", MaxOutboxRelaysPerUser, relays)
+	}
+
+	// Relay lists, and their absence, are cached
+	resolver.OutboxRelays(context.Background(), payment)
+	if querier.queries != 2 {
+		t.Errorf("Expected 2 queries, got %d", querier.queries)
+	}
+
+	resolver.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	resolver.OutboxRelays(context.Background(), payment)
+	if querier.queries != 4 {
+		t.Errorf("Expected expired relay lists to be resolved again, got %d queries", querier.queries)
+	}
+
+	// The author does not need a copy
+	payment.PubKey = alicePubKey
+	if relays := resolver.OutboxRelays(context.Background(), payment); len(relays) != 0 {
+		t.Errorf("Expected no relays for the author, got %v", relays)
+	}
+}
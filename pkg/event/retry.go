@@ -0,0 +1,179 @@
+package event
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// RetryPolicy configures how failed user operations are retried
+type RetryPolicy struct {
+	MaxRetries int           // Retries allowed before the user operation expires
+	BaseDelay  time.Duration // Delay before the first retry, doubled on every retry
+	MaxDelay   time.Duration // Upper bound of the delay between retries
+}
+
+// DefaultRetryPolicy is the retry policy used when none is provided
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  5 * time.Second,
+	MaxDelay:   5 * time.Minute,
+}
+
+// Backoff returns the delay before the retry following the given number of retries
+func (p RetryPolicy) Backoff(retryCount int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retryCount; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// scheduledRetry is a failed user operation waiting for its next attempt
+type scheduledRetry struct {
+	event      *nostr.Event
+	userOp     *UserOpEvent
+	chainID    *big.Int
+	retryCount int
+	due        time.Time
+}
+
+// RetryScheduler schedules the resubmission of failed user operations with exponential backoff
+// and expires them once they run out of retries or fail for a terminal reason
+type RetryScheduler struct {
+	mu sync.Mutex
+
+	policy    RetryPolicy
+	scheduled map[string]*scheduledRetry
+}
+
+// NewRetryScheduler creates a new retry scheduler with the given policy
+func NewRetryScheduler(policy RetryPolicy) *RetryScheduler {
+	return &RetryScheduler{
+		policy:    policy,
+		scheduled: make(map[string]*scheduledRetry),
+	}
+}
+
+// Add consumes a failed user operation event. When the user operation can be retried it is
+// scheduled and nil is returned, otherwise the expired event is returned.
+func (s *RetryScheduler) Add(evt *nostr.Event) (*nostr.Event, error) {
+	userOpEvent, err := ParseUserOpEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	if userOpEvent.EventType != EventTypeUserOpFailed {
+		return nil, fmt.Errorf("user operation event is %s, not %s", userOpEvent.EventType, EventTypeUserOpFailed)
+	}
+
+	chainID, err := getUserOpChainID(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	retryCount := getRetryCount(evt, userOpEvent)
+	identifier := evt.Tags.GetD()
+
+	if retryCount >= s.policy.MaxRetries || !CanTransitionUserOp(userOpEvent, EventTypeUserOpSubmitted) {
+		s.mu.Lock()
+		delete(s.scheduled, identifier)
+		s.mu.Unlock()
+
+		return UpdateUserOpEvent(chainID, userOpEvent.UserOpData, userOpEvent.TxHash, retryCount, EventTypeUserOpExpired, evt)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scheduled[identifier] = &scheduledRetry{
+		event:      evt,
+		userOp:     userOpEvent,
+		chainID:    chainID,
+		retryCount: retryCount,
+		due:        evt.CreatedAt.Time().Add(s.policy.Backoff(retryCount)),
+	}
+
+	return nil, nil
+}
+
+// Due returns the resubmission events of the user operations whose backoff has elapsed,
+// with their retry count incremented, and removes them from the schedule
+func (s *RetryScheduler) Due(now time.Time) ([]*nostr.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*nostr.Event
+	for identifier, retry := range s.scheduled {
+		if now.Before(retry.due) {
+			continue
+		}
+
+		evt, err := UpdateUserOpEvent(retry.chainID, retry.userOp.UserOpData, retry.userOp.TxHash, retry.retryCount+1, EventTypeUserOpSubmitted, retry.event)
+		if err != nil {
+			return events, err
+		}
+
+		delete(s.scheduled, identifier)
+		events = append(events, evt)
+	}
+
+	return events, nil
+}
+
+// Next returns the time of the next scheduled retry
+func (s *RetryScheduler) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, retry := range s.scheduled {
+		if next.IsZero() || retry.due.Before(next) {
+			next = retry.due
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// Len returns the number of scheduled retries
+func (s *RetryScheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.scheduled)
+}
+
+// getUserOpChainID extracts the chain ID from the layer tag of a user operation event
+func getUserOpChainID(evt *nostr.Event) (*big.Int, error) {
+	layer := evt.Tags.GetFirst([]string{"layer", ""})
+	if layer == nil || len(*layer) < 2 {
+		return nil, fmt.Errorf("layer tag not found in event")
+	}
+
+	chainID, ok := new(big.Int).SetString((*layer)[1], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain ID: %s", (*layer)[1])
+	}
+
+	return chainID, nil
+}
+
+// getRetryCount returns the retry count from the retry_count tag, falling back to the content
+func getRetryCount(evt *nostr.Event, userOpEvent *UserOpEvent) int {
+	tag := evt.Tags.GetFirst([]string{"retry_count", ""})
+	if tag != nil && len(*tag) >= 2 {
+		if retryCount, err := strconv.Atoi((*tag)[1]); err == nil {
+			return retryCount
+		}
+	}
+
+	return userOpEvent.RetryCount
+}
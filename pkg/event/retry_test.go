@@ -0,0 +1,87 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRetryScheduler(t *testing.T) {
+	chainID := big.NewInt(1)
+	userOp := neth.UserOp{
+		Sender:               common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(0),
+		VerificationGasLimit: big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+	}
+
+	submitted, err := CreateUserOpEvent(chainID, nil, nil, nil, nil, 0, userOp, EventTypeUserOpSubmitted)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute}
+	scheduler := NewRetryScheduler(policy)
+
+	failedAt := time.Unix(1700000000, 0)
+	retryable := NewFailureReason("FailedOp(0, AA21 didn't pay prefund)", nil)
+
+	failed, err := FailUserOpEvent(chainID, userOp, nil, 1, retryable, submitted)
+	if err != nil {
+		t.Fatalf("Failed to create failed user op event: %v", err)
+	}
+	failed.CreatedAt = nostr.Timestamp(failedAt.Unix())
+
+	expired, err := scheduler.Add(failed)
+	if err != nil || expired != nil {
+		t.Fatalf("Expected user op to be scheduled, got %v, %v", expired, err)
+	}
+
+	// Second retry, base delay doubled once
+	events, err := scheduler.Due(failedAt.Add(time.Second))
+	if err != nil || len(events) != 0 {
+		t.Fatalf("Expected no retries before the backoff, got %d, %v", len(events), err)
+	}
+
+	events, err = scheduler.Due(failedAt.Add(2 * time.Second))
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Expected one retry after the backoff, got %d, %v", len(events), err)
+	}
+
+	resubmitted, err := ParseUserOpEvent(events[0])
+	if err != nil {
+		t.Fatalf("Failed to parse resubmitted event: %v", err)
+	}
+	if resubmitted.EventType != EventTypeUserOpSubmitted || resubmitted.RetryCount != 2 {
+		t.Errorf("Expected submitted event with retry count 2, got %s with %d", resubmitted.EventType, resubmitted.RetryCount)
+	}
+
+	// Out of retries
+	failed, err = FailUserOpEvent(chainID, userOp, nil, 2, retryable, events[0])
+	if err != nil {
+		t.Fatalf("Failed to create failed user op event: %v", err)
+	}
+
+	expired, err = scheduler.Add(failed)
+	if err != nil || expired == nil {
+		t.Fatalf("Expected an expired event, got %v", err)
+	}
+
+	expiredEvent, err := ParseUserOpEvent(expired)
+	if err != nil {
+		t.Fatalf("Failed to parse expired event: %v", err)
+	}
+	if expiredEvent.EventType != EventTypeUserOpExpired {
+		t.Errorf("Expected %s, got %s", EventTypeUserOpExpired, expiredEvent.EventType)
+	}
+	if scheduler.Len() != 0 {
+		t.Errorf("Expected no scheduled retries, got %d", scheduler.Len())
+	}
+}
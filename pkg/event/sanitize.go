@@ -0,0 +1,129 @@
+package event
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// SanitizePolicy is how the tags and alt tags derived from log data are cleaned, so that hostile
+// contract data can't corrupt events or the displays of clients. Invalid UTF-8 is replaced with
+// U+FFFD, control characters and bidirectional overrides are removed. Emoji, including ZWJ
+// sequences, are kept.
+type SanitizePolicy struct {
+	MaxTagName  int // Longest tag name in bytes, tags with longer names are dropped
+	MaxTagValue int // Longest tag value in bytes before escaping, longer values are truncated with an ellipsis
+	MaxAlt      int // Longest alt tag in bytes, longer alt tags are truncated with an ellipsis
+
+	// Escape is applied to the cleaned data values, e.g. html.EscapeString for clients rendering
+	// tags as HTML. Values are kept as is when nil.
+	Escape func(string) string
+}
+
+// DefaultSanitizePolicy is the policy used by the constructors until SetSanitizePolicy is called,
+// unless they are given WithSanitizePolicy
+var DefaultSanitizePolicy = SanitizePolicy{
+	MaxTagName:  64,
+	MaxTagValue: 1024,
+	MaxAlt:      4096,
+}
+
+var sanitizePolicy = struct {
+	sync.RWMutex
+	policy SanitizePolicy
+}{policy: DefaultSanitizePolicy}
+
+// SetSanitizePolicy sets the default policy of the tags derived from log data, zero limits
+// disable the truncation
+func SetSanitizePolicy(policy SanitizePolicy) {
+	sanitizePolicy.Lock()
+	defer sanitizePolicy.Unlock()
+
+	sanitizePolicy.policy = policy
+}
+
+// CurrentSanitizePolicy returns the policy of the tags derived from log data
+func CurrentSanitizePolicy() SanitizePolicy {
+	sanitizePolicy.RLock()
+	defer sanitizePolicy.RUnlock()
+
+	return sanitizePolicy.policy
+}
+
+// SanitizeText replaces invalid UTF-8, removes control characters and bidirectional overrides
+// and truncates the text to max bytes, 0 for no limit. Newlines are kept when multiline is set,
+// replaced with spaces otherwise.
+func SanitizeText(text string, max int, multiline bool) string {
+	text = strings.ToValidUTF8(text, "\ufffd")
+
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' && multiline:
+			return r
+		case r == '\n' || r == '\t' || r == '\r':
+			return ' '
+		case unicode.IsControl(r), isBidiControl(r):
+			return -1
+		}
+		return r
+	}, text)
+
+	return truncateText(clean, max)
+}
+
+// isBidiControl checks if a rune changes the direction of the text around it, the characters
+// of Trojan Source attacks
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069') || r == '\u200e' || r == '\u200f' || r == '\u061c'
+}
+
+// truncateText truncates a text to max bytes including the ellipsis, at a rune boundary and
+// without a dangling zero width joiner of an emoji sequence
+func truncateText(text string, max int) string {
+	const ellipsis = "\u2026"
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	if max <= len(ellipsis) {
+		return ""
+	}
+
+	cut := max - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return strings.TrimRight(text[:cut], "\u200d") + ellipsis
+}
+
+// sanitizeTag cleans a tag derived from log data, false when the tag must be dropped
+func sanitizeTag(policy SanitizePolicy, name, value string) (nostr.Tag, bool) {
+	if name == "" || (policy.MaxTagName > 0 && len(name) > policy.MaxTagName) || strings.ContainsAny(name, " \ufffd") || SanitizeText(name, 0, false) != name {
+		return nil, false
+	}
+
+	value = SanitizeText(value, policy.MaxTagValue, false)
+	if policy.Escape != nil {
+		value = policy.Escape(value)
+	}
+	return nostr.Tag{name, value}, true
+}
+
+// sanitizeDataTags cleans the tags derived from log data with a policy
+func sanitizeDataTags(policy SanitizePolicy, tags []nostr.Tag) []nostr.Tag {
+	sanitized := make([]nostr.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if clean, ok := sanitizeTag(policy, tag[0], tag[1]); ok {
+			sanitized = append(sanitized, clean)
+		}
+	}
+	return sanitized
+}
+
+// sanitizeAlt cleans an alt tag with a policy
+func sanitizeAlt(policy SanitizePolicy, alt string) string {
+	return SanitizeText(alt, policy.MaxAlt, true)
+}
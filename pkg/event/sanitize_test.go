@@ -0,0 +1,113 @@
+package event
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		max       int
+		multiline bool
+		expected  string
+	}{
+		{"control characters", "a\x00b\x1bc\x7f", 0, false, "abc"},
+		{"newlines", "a\nb\tc", 0, false, "a b c"},
+		{"multiline", "a\nb\x07", 0, true, "a\nb"},
+		{"invalid UTF-8", "a\xffb", 0, false, "a�b"},
+		{"bidi override", "abc‮dcba⁦x⁩", 0, false, "abcdcbax"},
+		{"emoji", "👩‍👩‍👧 🇧🇪 👍🏽", 0, false, "👩‍👩‍👧 🇧🇪 👍🏽"},
+		{"truncated", "abcdefghij", 7, false, "abcd…"},
+		{"truncated rune", "ééééé", 7, false, "éé…"},
+		{"truncated joiner", "👩‍👩", 8, false, "👩…"},
+	}
+
+	for _, tt := range tests {
+		if text := SanitizeText(tt.text, tt.max, tt.multiline); text != tt.expected {
+			t.Errorf("%s: Expected %q, got %q", tt.name, tt.expected, text)
+		}
+	}
+}
+
+func TestSanitizedDataTags(t *testing.T) {
+	SetSanitizePolicy(SanitizePolicy{MaxTagName: 16, MaxTagValue: 32, MaxAlt: 256, Escape: html.EscapeString})
+	defer SetSanitizePolicy(DefaultSanitizePolicy)
+
+	log := goldenLog()
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","memo":"<script>‮\u0000` + strings.Repeat("x", 64) + `","bad key":"1","` + strings.Repeat("k", 17) + `":"1","note":"🎉\n"}`)
+	log.Data = &data
+
+	evt, err := CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+
+	if tag := evt.Tags.GetFirst([]string{"memo"}); tag == nil || (*tag)[1] != "&lt;script&gt;"+strings.Repeat("x", 21)+"…" {
+		t.Errorf("Expected the sanitized memo, got %v", tag)
+	}
+	if tag := evt.Tags.GetFirst([]string{"note"}); tag == nil || (*tag)[1] != "🎉 " {
+		t.Errorf("Expected the emoji note, got %v", tag)
+	}
+	if evt.Tags.GetFirst([]string{"bad key"}) != nil || evt.Tags.GetFirst([]string{strings.Repeat("k", 17)}) != nil {
+		t.Error("Expected the invalid tag names to be dropped")
+	}
+
+	alt := evt.Tags.GetFirst([]string{"alt"})
+	if alt == nil || len((*alt)[1]) > 256 || strings.ContainsRune((*alt)[1], '‮') || !utf8.ValidString((*alt)[1]) {
+		t.Errorf("Expected a sanitized alt tag, got %v", alt)
+	}
+}
+
+func TestWithSanitizePolicy(t *testing.T) {
+	log := goldenLog()
+	data := json.RawMessage(`{"memo":"<b>` + strings.Repeat("x", 64) + `</b>"}`)
+	log.Data = &data
+
+	policy := SanitizePolicy{MaxTagName: 16, MaxTagValue: 16, MaxAlt: 64, Escape: html.EscapeString}
+	evt, err := CreateTxLogEvent(log, WithSanitizePolicy(policy))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if tag := evt.Tags.GetFirst([]string{"memo"}); tag == nil || (*tag)[1] != "&lt;b&gt;"+strings.Repeat("x", 10)+"…" {
+		t.Errorf("Expected the memo sanitized with the option, got %v", tag)
+	}
+	if alt := evt.Tags.GetFirst([]string{"alt"}); alt == nil || len((*alt)[1]) > 64 {
+		t.Errorf("Expected an alt tag of at most 64 bytes, got %v", alt)
+	}
+
+	// The default policy is left as is
+	if CurrentSanitizePolicy().MaxTagValue != DefaultSanitizePolicy.MaxTagValue {
+		t.Errorf("Expected the default policy, got %+v", CurrentSanitizePolicy())
+	}
+	evt, err = CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	if tag := evt.Tags.GetFirst([]string{"memo"}); tag == nil || (*tag)[1] != "<b>"+strings.Repeat("x", 64)+"</b>" {
+		t.Errorf("Expected the memo sanitized with the default policy, got %v", tag)
+	}
+}
+
+func FuzzSanitizeText(f *testing.F) {
+	f.Add("👩‍👩‍👧‮\x00abc", 5)
+	f.Add("\xff\xfe", 1)
+
+	f.Fuzz(func(t *testing.T, text string, max int) {
+		max = max % 4096
+		sanitized := SanitizeText(text, max, false)
+		if !utf8.ValidString(sanitized) {
+			t.Fatalf("Expected valid UTF-8, got %q", sanitized)
+		}
+		if max > 0 && len(sanitized) > max {
+			t.Fatalf("Expected at most %d bytes, got %d", max, len(sanitized))
+		}
+		if strings.ContainsAny(sanitized, "\n\x00‮") {
+			t.Fatalf("Expected no control characters, got %q", sanitized)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package event
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:generate env UPDATE_SCHEMAS=1 go test -run TestContentSchemasUpToDate -count=1 .
+
+const (
+	// ContentSchemaDialect is the JSON Schema draft the content schemas are written in
+	ContentSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	// ContentSchemaBaseURL is the base of the $id of every content schema
+	ContentSchemaBaseURL = "https://github.com/comunifi/nostr-eth/schemas/"
+)
+
+// ErrNoContentSchema is returned when no schema is published for the kind of an event
+var ErrNoContentSchema = errors.New("no content schema for event kind")
+
+//go:embed schemas/*.schema.json
+var schemaFiles embed.FS
+
+// contentSchemaTypes maps the schema names to the content type they are generated from
+var contentSchemaTypes = map[string]any{
+	"tx_log":               TxLogEvent{},
+	"tx_transfer":          TxTransferEvent{},
+	"user_op":              UserOpEvent{},
+	"group_metadata":       GroupMetadata{},
+	"group_join":           GroupJoin{},
+	"group_leave":          GroupLeave{},
+	"group_metadata_event": GroupMetadataEvent{},
+	"group_name":           GroupNameEvent{},
+	"group_about":          GroupAboutEvent{},
+	"group_picture":        GroupPictureEvent{},
+	"group_admins":         GroupAdminsEvent{},
+	"group_moderators":     GroupModeratorsEvent{},
+	"group_private":        GroupPrivateEvent{},
+	"group_closed":         GroupClosedEvent{},
+	"group_created":        GroupCreatedEvent{},
+	"group_updated":        GroupUpdatedEvent{},
+}
+
+// contentSchemaKinds maps event kinds to the schema describing their content
+var contentSchemaKinds = map[int]string{
+	KindTxLog:             "tx_log",
+	KindTxTransfer:        "tx_transfer",
+	KindTxTransferLegacy:  "tx_transfer",
+	EventUserOpKind:       "user_op",
+	KindGroupCreate:       "group_metadata",
+	KindGroupEditMetadata: "group_metadata",
+	KindGroupAddUser:      "group_join",
+	KindGroupRemoveUser:   "group_leave",
+	KindGroupMetadata:     "group_metadata_event",
+	KindGroupName:         "group_name",
+	KindGroupAbout:        "group_about",
+	KindGroupPicture:      "group_picture",
+	KindGroupAdmins:       "group_admins",
+	KindGroupModerators:   "group_moderators",
+	KindGroupPrivate:      "group_private",
+	KindGroupClosed:       "group_closed",
+	KindGroupCreated:      "group_created",
+	KindGroupUpdated:      "group_updated",
+}
+
+// schemaEnums lists the allowed values of the string types used as event types and statuses
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(EventTypeTxLog("")): {string(EventTypeTxLogCreated), string(EventTypeTxLogUpdated), string(EventTypeTxLogOrphaned)},
+	reflect.TypeOf(TxLogStatus("")): {
+		string(TxLogStatusIncluded),
+		string(TxLogStatusConfirmed),
+		string(TxLogStatusSafe),
+		string(TxLogStatusFinalized),
+		string(TxLogStatusOrphaned),
+	},
+	reflect.TypeOf(EventTypeTxTransfer("")): {string(EventTypeTxTransferCreated)},
+	reflect.TypeOf(EventTypeUserOp("")): {
+		string(EventTypeUserOpRequested),
+		string(EventTypeUserOpSigned),
+		string(EventTypeUserOpSubmitted),
+		string(EventTypeUserOpExecuted),
+		string(EventTypeUserOpConfirmed),
+		string(EventTypeUserOpExpired),
+		string(EventTypeUserOpFailed),
+	},
+}
+
+var (
+	hexPattern     = "^0x[0-9a-fA-F]*$"
+	addressPattern = "^0x[0-9a-fA-F]{40}$"
+	hashPattern    = "^0x[0-9a-fA-F]{64}$"
+)
+
+// schemaOverrides holds the schemas of types with a custom JSON encoding
+var schemaOverrides = map[reflect.Type]func() map[string]any{
+	reflect.TypeOf(big.Int{}):         func() map[string]any { return map[string]any{"type": "integer"} },
+	reflect.TypeOf(time.Time{}):       func() map[string]any { return map[string]any{"type": "string", "format": "date-time"} },
+	reflect.TypeOf(common.Address{}):  func() map[string]any { return map[string]any{"type": "string", "pattern": addressPattern} },
+	reflect.TypeOf(common.Hash{}):     func() map[string]any { return map[string]any{"type": "string", "pattern": hashPattern} },
+	reflect.TypeOf(hexutil.Bytes{}):   func() map[string]any { return map[string]any{"type": "string", "pattern": hexPattern} },
+	reflect.TypeOf(hexutil.Big{}):     func() map[string]any { return map[string]any{"type": "string", "pattern": hexPattern} },
+	reflect.TypeOf(json.RawMessage{}): func() map[string]any { return map[string]any{} },
+	reflect.TypeOf(neth.UserOp{}):     userOpSchema,
+}
+
+// userOpSchema describes the hex encoding of neth.UserOp
+func userOpSchema() map[string]any {
+	fields := []string{
+		"sender", "nonce", "initCode", "callData", "callGasLimit", "verificationGasLimit",
+		"preVerificationGas", "maxFeePerGas", "maxPriorityFeePerGas", "paymasterAndData", "signature",
+	}
+
+	properties := make(map[string]any)
+	for _, field := range fields {
+		properties[field] = map[string]any{"type": "string", "pattern": hexPattern}
+	}
+	properties["sender"] = map[string]any{"type": "string", "pattern": addressPattern}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   fields,
+	}
+}
+
+// generateContentSchema generates the JSON Schema of a content type from its JSON encoding.
+// Unknown properties are allowed so that older readers accept newer payloads.
+func generateContentSchema(name string, v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+
+	schema := typeSchema(t)
+	schema["$schema"] = ContentSchemaDialect
+	schema["$id"] = ContentSchemaBaseURL + name + ".schema.json"
+	schema["title"] = t.Name()
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// typeSchema returns the schema of the JSON encoding of a type
+func typeSchema(t reflect.Type) map[string]any {
+	if override, ok := schemaOverrides[t]; ok {
+		return override()
+	}
+
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullableSchema(typeSchema(t.Elem()))
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings
+			return nullableSchema(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+		return nullableSchema(map[string]any{"type": "array", "items": typeSchema(t.Elem())})
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return nullableSchema(map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())})
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema returns the schema of a struct, fields without omitempty are required
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := make([]string, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = typeSchema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	sort.Strings(required)
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// nullableSchema allows null in addition to the type of a schema
+func nullableSchema(schema map[string]any) map[string]any {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+	}
+	return schema
+}
+
+// ContentSchemaNames returns the names of all published content schemas
+func ContentSchemaNames() []string {
+	names := make([]string, 0, len(contentSchemaTypes))
+	for name := range contentSchemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ContentSchema returns the JSON Schema of the content of the given event kind
+func ContentSchema(kind int) ([]byte, error) {
+	name, ok := contentSchemaKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNoContentSchema, kind)
+	}
+
+	return schemaFiles.ReadFile("schemas/" + name + ".schema.json")
+}
+
+var (
+	compileSchemasOnce sync.Once
+	compiledSchemas    map[string]*jsonschema.Schema
+	compileSchemasErr  error
+)
+
+// compileContentSchemas compiles the embedded schemas once
+func compileContentSchemas() (map[string]*jsonschema.Schema, error) {
+	compileSchemasOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		compiler.AssertFormat()
+
+		for name := range contentSchemaTypes {
+			b, err := schemaFiles.ReadFile("schemas/" + name + ".schema.json")
+			if err != nil {
+				compileSchemasErr = err
+				return
+			}
+
+			doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+			if err != nil {
+				compileSchemasErr = fmt.Errorf("failed to parse schema %s: %w", name, err)
+				return
+			}
+
+			if err := compiler.AddResource(ContentSchemaBaseURL+name+".schema.json", doc); err != nil {
+				compileSchemasErr = fmt.Errorf("failed to add schema %s: %w", name, err)
+				return
+			}
+		}
+
+		compiledSchemas = make(map[string]*jsonschema.Schema)
+		for name := range contentSchemaTypes {
+			schema, err := compiler.Compile(ContentSchemaBaseURL + name + ".schema.json")
+			if err != nil {
+				compileSchemasErr = fmt.Errorf("failed to compile schema %s: %w", name, err)
+				return
+			}
+			compiledSchemas[name] = schema
+		}
+	})
+
+	return compiledSchemas, compileSchemasErr
+}
+
+// ValidateContentAgainstSchema validates the content of an event against the published schema
+// of its kind, ErrNoContentSchema is returned for kinds without a schema and for NIP-29 group
+// metadata events, which carry their data in tags
+func ValidateContentAgainstSchema(evt *nostr.Event) error {
+	name, ok := contentSchemaKinds[DefaultKind(evt.Kind)]
+	if !ok || IsNIP29GroupEvent(evt) {
+		return fmt.Errorf("%w: %d", ErrNoContentSchema, evt.Kind)
+	}
+
+	schemas, err := compileContentSchemas()
+	if err != nil {
+		return err
+	}
+
+	content, err := jsonschema.UnmarshalJSON(strings.NewReader(evt.Content))
+	if err != nil {
+		return fmt.Errorf("invalid content JSON: %w", err)
+	}
+
+	if err := schemas[name].Validate(content); err != nil {
+		return fmt.Errorf("content does not match schema %s: %w", name, err)
+	}
+
+	return nil
+}
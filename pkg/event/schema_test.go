@@ -0,0 +1,105 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// TestContentSchemasUpToDate checks that the embedded schemas match the content types,
+// run with UPDATE_SCHEMAS=1 to regenerate them
+func TestContentSchemasUpToDate(t *testing.T) {
+	update := os.Getenv("UPDATE_SCHEMAS") != ""
+
+	for _, name := range ContentSchemaNames() {
+		generated, err := generateContentSchema(name, contentSchemaTypes[name])
+		if err != nil {
+			t.Fatalf("Failed to generate schema %s: %v", name, err)
+		}
+
+		path := filepath.Join("schemas", name+".schema.json")
+		if update {
+			if err := os.WriteFile(path, generated, 0o644); err != nil {
+				t.Fatalf("Failed to write schema %s: %v", name, err)
+			}
+			continue
+		}
+
+		embedded, err := schemaFiles.ReadFile("schemas/" + name + ".schema.json")
+		if err != nil {
+			t.Fatalf("Failed to read schema %s: %v", name, err)
+		}
+
+		if !bytes.Equal(generated, embedded) {
+			t.Errorf("Schema %s is out of date, run go generate ./pkg/event", name)
+		}
+	}
+}
+
+func TestValidateContentAgainstSchema(t *testing.T) {
+	data := json.RawMessage(`{"from":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"}`)
+	log := neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    "0xabcdef1234567890",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		Sender:    "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6",
+		To:        "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b7",
+		Value:     new(big.Int).Lsh(big.NewInt(1), 100),
+		Data:      &data,
+	}
+
+	txLog, err := CreateTxLogEvent(log)
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+
+	userOp, err := CreateUserOpEvent(big.NewInt(100), nil, nil, nil, nil, 0, neth.UserOp{
+		Sender:               common.HexToAddress("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000),
+	}, EventTypeUserOpRequested)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+
+	group, err := CreateGroupEvent("group", "Group", "About", "", []string{"admin"}, nil, false, false)
+	if err != nil {
+		t.Fatalf("Failed to create group event: %v", err)
+	}
+
+	for _, evt := range []*nostr.Event{txLog, userOp, group} {
+		if err := ValidateContentAgainstSchema(evt); err != nil {
+			t.Errorf("Expected kind %d content to be valid, got %v", evt.Kind, err)
+		}
+	}
+
+	invalid := *txLog
+	invalid.Content = `{"log_data":{"hash":"0x1"},"event_type":"tx_log_created"}`
+	if err := ValidateContentAgainstSchema(&invalid); err == nil {
+		t.Error("Expected missing log fields to fail validation")
+	}
+
+	invalid.Content = `{"log_data":` + `{}` + `,"event_type":"unknown"}`
+	if err := ValidateContentAgainstSchema(&invalid); err == nil {
+		t.Error("Expected unknown event type to fail validation")
+	}
+
+	if err := ValidateContentAgainstSchema(&nostr.Event{Kind: 1, Content: "hello"}); !errors.Is(err, ErrNoContentSchema) {
+		t.Errorf("Expected ErrNoContentSchema, got %v", err)
+	}
+}
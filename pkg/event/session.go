@@ -0,0 +1,175 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for session keys
+const (
+	KindSessionKey = 31101 // Addressable, one event per chain, account and key
+
+	EventTypeSessionKeyGranted EventTypeSessionKey = "session_key_granted"
+	EventTypeSessionKeyRevoked EventTypeSessionKey = "session_key_revoked"
+)
+
+type EventTypeSessionKey string
+
+// SessionKeyEvent represents a Nostr event announcing a session key of a smart account
+type SessionKeyEvent struct {
+	ChainID     string              `json:"chain_id"`
+	SessionData neth.SessionKey     `json:"session_data"`
+	EventType   EventTypeSessionKey `json:"event_type"`
+	Tags        []string            `json:"tags,omitempty"`
+}
+
+// CreateSessionKeyEvent creates a new Nostr event announcing a session key, or its revocation
+func CreateSessionKeyEvent(chainID *big.Int, session neth.SessionKey) (*nostr.Event, error) {
+	eventType := EventTypeSessionKeyGranted
+	if session.Revoked {
+		eventType = EventTypeSessionKeyRevoked
+	}
+
+	// Create the event data
+	eventData := SessionKeyEvent{
+		ChainID:     chainID.String(),
+		SessionData: session,
+		EventType:   eventType,
+		Tags:        []string{"session_key", "evm", chainID.String(), "account_abstraction"},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindSessionKey), // Custom kind for session keys
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Addressable identifier, one per chain, account and key
+	evt.Tags = append(evt.Tags, []string{"d", fmt.Sprintf("%s:%s:%s", chainID.String(), strings.ToLower(session.Account.Hex()), strings.ToLower(session.Key.Hex()))})
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("session_key"))         // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})     // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction")) // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(eventType)))     // Event type
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", chainID.String()}) // Chain ID
+
+	// Address tags
+	evt.Tags = append(evt.Tags, []string{"P", session.Account.Hex()}) // Smart account
+	evt.Tags = append(evt.Tags, []string{"p", session.Key.Hex()})     // Session key
+
+	// Module tag if present
+	if session.Module != nil {
+		evt.Tags = append(evt.Tags, []string{"module", session.Module.Hex()})
+	}
+
+	// Scope tags
+	for _, permission := range session.Permissions {
+		evt.Tags = appendUniqueTags(evt.Tags, []string{"target", permission.Target.Hex()})
+		if len(permission.Selector) > 0 {
+			evt.Tags = appendUniqueTags(evt.Tags, []string{"selector", permission.Selector.String()})
+		}
+	}
+
+	// Validity tags
+	if !session.ValidAfter.IsZero() {
+		evt.Tags = append(evt.Tags, []string{"valid_after", strconv.FormatInt(session.ValidAfter.Unix(), 10)})
+	}
+	if !session.ValidUntil.IsZero() {
+		evt.Tags = append(evt.Tags, []string{"valid_until", strconv.FormatInt(session.ValidUntil.Unix(), 10)})
+		evt.Tags = append(evt.Tags, []string{"expiration", strconv.FormatInt(session.ValidUntil.Unix(), 10)}) // NIP-40
+	}
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a session key %s for account %s on chain %s", session.Key.Hex(), session.Account.Hex(), chainID.String())
+	if session.Revoked {
+		alt += "\n The session key has been revoked"
+	} else if !session.ValidUntil.IsZero() {
+		alt += fmt.Sprintf("\n Valid until: %s", session.ValidUntil.UTC().Format(time.RFC3339))
+	}
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseSessionKeyEvent parses a Nostr event back into a SessionKeyEvent
+func ParseSessionKeyEvent(evt *nostr.Event) (*SessionKeyEvent, error) {
+	var sessionKeyEvent SessionKeyEvent
+	err := unmarshalContent(evt, &sessionKeyEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionKeyEvent, nil
+}
+
+// ActiveSessionKeys returns the session keys of an account that are active at the given time.
+// Session key events are grouped by author and d tag and the newest event of each group wins, so
+// a revocation replaces an earlier grant of the same author. When authors are given, e.g. the
+// pubkey of the account owner, only their events are trusted. Events that are not validly signed
+// or cannot be parsed are skipped.
+func ActiveSessionKeys(events []*nostr.Event, account string, now time.Time, authors ...string) ([]neth.SessionKey, error) {
+	trusted := make(map[string]bool, len(authors))
+	for _, author := range authors {
+		trusted[author] = true
+	}
+
+	sorted := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if evt == nil || DefaultKind(evt.Kind) != KindSessionKey {
+			continue
+		}
+		if len(trusted) > 0 && !trusted[evt.PubKey] {
+			continue
+		}
+		sorted = append(sorted, evt)
+	}
+
+	SortEventsByCreatedAt(sorted, true)
+
+	seen := make(map[string]bool)
+	var sessions []neth.SessionKey
+	for _, evt := range sorted {
+		key := evt.PubKey + ":" + evt.Tags.GetD()
+		if seen[key] {
+			continue
+		}
+
+		if ok, err := evt.CheckSignature(); err != nil || !ok {
+			continue
+		}
+
+		sessionKeyEvent, err := ParseSessionKeyEvent(evt)
+		if err != nil {
+			continue
+		}
+		seen[key] = true
+
+		session := sessionKeyEvent.SessionData
+		if !strings.EqualFold(session.Account.Hex(), account) || !session.IsActive(now) {
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,107 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSessionKeyEvent(t *testing.T) {
+	module := common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	session := neth.SessionKey{
+		Account: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Key:     common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Module:  &module,
+		Permissions: []neth.SessionPermission{
+			{Target: common.HexToAddress("0x3333333333333333333333333333333333333333"), Selector: []byte{0xa9, 0x05, 0x9c, 0xbb}},
+		},
+		ValidUntil: time.Unix(1800000000, 0),
+	}
+
+	evt, err := CreateSessionKeyEvent(big.NewInt(100), session)
+	if err != nil {
+		t.Fatalf("Failed to create session key event: %v", err)
+	}
+	if d := evt.Tags.GetD(); d != "100:0x1111111111111111111111111111111111111111:0x2222222222222222222222222222222222222222" {
+		t.Errorf("Expected one identifier per chain, account and key, got %s", d)
+	}
+	for _, tag := range [][]string{{"selector", "0xa9059cbb"}, {"module", module.Hex()}, {"expiration", "1800000000"}, {"t", string(EventTypeSessionKeyGranted)}} {
+		if evt.Tags.GetFirst(tag) == nil {
+			t.Errorf("Expected tag %v", tag)
+		}
+	}
+
+	parsed, err := ParseSessionKeyEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse session key event: %v", err)
+	}
+	if parsed.ChainID != "100" || parsed.SessionData.Key != session.Key || len(parsed.SessionData.Permissions) != 1 {
+		t.Errorf("Expected the session key back, got %+v", parsed)
+	}
+}
+
+func TestActiveSessionKeys(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	account := "0x1111111111111111111111111111111111111111"
+	owner := nostr.GeneratePrivateKey()
+	ownerPubkey, _ := nostr.GetPublicKey(owner)
+	attacker := nostr.GeneratePrivateKey()
+
+	sessionEvent := func(privateKey, key string, revoked bool, createdAt nostr.Timestamp) *nostr.Event {
+		t.Helper()
+		evt, err := CreateSessionKeyEvent(big.NewInt(100), neth.SessionKey{
+			Account:    common.HexToAddress(account),
+			Key:        common.HexToAddress(key),
+			ValidUntil: now.Add(time.Hour),
+			Revoked:    revoked,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create session key event: %v", err)
+		}
+		evt.CreatedAt = createdAt
+		evt.Sign(privateKey)
+		return evt
+	}
+
+	granted := sessionEvent(owner, "0x2222222222222222222222222222222222222222", false, 100)
+	revoked := sessionEvent(owner, "0x3333333333333333333333333333333333333333", true, 200)
+	replacedGrant := sessionEvent(owner, "0x3333333333333333333333333333333333333333", false, 100)
+	shadow := sessionEvent(attacker, "0x2222222222222222222222222222222222222222", true, 300)
+	fake := sessionEvent(attacker, "0x4444444444444444444444444444444444444444", false, 300)
+
+	malformed := sessionEvent(owner, "0x5555555555555555555555555555555555555555", false, 300)
+	malformed.Content = "{"
+	malformed.Sign(owner)
+
+	forged := sessionEvent(attacker, "0x6666666666666666666666666666666666666666", false, 300)
+	forged.PubKey = ownerPubkey
+
+	events := []*nostr.Event{granted, revoked, replacedGrant, shadow, fake, malformed, forged}
+
+	// Only the events of the owner are trusted
+	sessions, err := ActiveSessionKeys(events, account, now, ownerPubkey)
+	if err != nil {
+		t.Fatalf("Failed to get session keys: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Key != common.HexToAddress("0x2222222222222222222222222222222222222222") {
+		t.Errorf("Expected the session key granted by the owner, got %+v", sessions)
+	}
+
+	// Without authors, other pubkeys cannot revoke the keys of the owner
+	sessions, err = ActiveSessionKeys(events, account, now)
+	if err != nil {
+		t.Fatalf("Failed to get session keys: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("Expected the session keys of the owner and the attacker, got %+v", sessions)
+	}
+
+	// Expired keys are not active
+	if sessions, _ := ActiveSessionKeys(events, account, now.Add(2*time.Hour), ownerPubkey); len(sessions) != 0 {
+		t.Errorf("Expected no active session keys, got %+v", sessions)
+	}
+}
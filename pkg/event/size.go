@@ -0,0 +1,98 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+// ErrRelayLimit is returned by ValidateForRelay for events a relay would reject for their size
+var ErrRelayLimit = errors.New("event exceeds relay limits")
+
+// RelayLimits are the size limits of a relay, zero values are unlimited. They match the
+// limitation fields of NIP-11 relay information documents, except MaxTagLength.
+type RelayLimits struct {
+	MaxMessageLength int // Bytes of the ["EVENT", ...] message
+	MaxContentLength int // Characters of the content
+	MaxEventTags     int
+	MaxTagLength     int // Bytes of all the values of a tag
+}
+
+// RelayLimitsFromNIP11 returns the limits of a relay from its information document
+func RelayLimitsFromNIP11(info nip11.RelayInformationDocument) RelayLimits {
+	if info.Limitation == nil {
+		return RelayLimits{}
+	}
+	return RelayLimits{
+		MaxMessageLength: info.Limitation.MaxMessageLength,
+		MaxContentLength: info.Limitation.MaxContentLength,
+		MaxEventTags:     info.Limitation.MaxEventTags,
+	}
+}
+
+// EstimateSize returns the bytes of the ["EVENT", ...] message publishing an event. Events that
+// are not signed yet are counted with a pubkey, an ID and a signature.
+func EstimateSize(evt *nostr.Event) int {
+	if evt == nil {
+		return 0
+	}
+
+	signed := *evt
+	if signed.PubKey == "" {
+		signed.PubKey = strings.Repeat("0", 64)
+	}
+	if signed.ID == "" {
+		signed.ID = strings.Repeat("0", 64)
+	}
+	if signed.Sig == "" {
+		signed.Sig = strings.Repeat("0", 128)
+	}
+
+	data, err := json.Marshal(signed)
+	if err != nil {
+		return 0
+	}
+	return len(`["EVENT",]`) + len(data)
+}
+
+// ValidateForRelay checks an event against the limits of a relay before it is published, so that
+// publishers can fail fast, or compress or offload the content, instead of being rejected
+func ValidateForRelay(evt *nostr.Event, limits RelayLimits) error {
+	if evt == nil {
+		return fmt.Errorf("%w: nil event", ErrRelayLimit)
+	}
+
+	if limits.MaxContentLength > 0 {
+		if length := len([]rune(evt.Content)); length > limits.MaxContentLength {
+			return fmt.Errorf("%w: content of %d characters exceeds %d", ErrRelayLimit, length, limits.MaxContentLength)
+		}
+	}
+
+	if limits.MaxEventTags > 0 && len(evt.Tags) > limits.MaxEventTags {
+		return fmt.Errorf("%w: %d tags exceed %d", ErrRelayLimit, len(evt.Tags), limits.MaxEventTags)
+	}
+
+	if limits.MaxTagLength > 0 {
+		for i, tag := range evt.Tags {
+			size := 0
+			for _, value := range tag {
+				size += len(value)
+			}
+			if size > limits.MaxTagLength {
+				return fmt.Errorf("%w: tag %d of %d bytes exceeds %d", ErrRelayLimit, i, size, limits.MaxTagLength)
+			}
+		}
+	}
+
+	if limits.MaxMessageLength > 0 {
+		if size := EstimateSize(evt); size > limits.MaxMessageLength {
+			return fmt.Errorf("%w: message of %d bytes exceeds %d", ErrRelayLimit, size, limits.MaxMessageLength)
+		}
+	}
+
+	return nil
+}
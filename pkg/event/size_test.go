@@ -0,0 +1,58 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+func TestEstimateSize(t *testing.T) {
+	evt, err := CreateTxLogEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	estimate := EstimateSize(evt)
+
+	if err := evt.Sign(nostr.GeneratePrivateKey()); err != nil {
+		t.Fatalf("Failed to sign event: %v", err)
+	}
+	message, err := json.Marshal([]any{"EVENT", evt})
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+	if estimate != len(message) || EstimateSize(evt) != len(message) {
+		t.Errorf("Expected %d bytes before and after signing, got %d and %d", len(message), estimate, EstimateSize(evt))
+	}
+}
+
+func TestValidateForRelay(t *testing.T) {
+	evt := &nostr.Event{Kind: 1, Content: "héllo", Tags: nostr.Tags{{"t", "a"}, {"alt", strings.Repeat("x", 100)}}}
+
+	tests := []struct {
+		limits RelayLimits
+		ok     bool
+	}{
+		{RelayLimits{}, true},
+		{RelayLimits{MaxContentLength: 5}, true},
+		{RelayLimits{MaxContentLength: 4}, false},
+		{RelayLimits{MaxEventTags: 1}, false},
+		{RelayLimits{MaxTagLength: 100}, false},
+		{RelayLimits{MaxMessageLength: EstimateSize(evt)}, true},
+		{RelayLimits{MaxMessageLength: EstimateSize(evt) - 1}, false},
+	}
+	for _, tt := range tests {
+		err := ValidateForRelay(evt, tt.limits)
+		if (err == nil) != tt.ok || (err != nil && !errors.Is(err, ErrRelayLimit)) {
+			t.Errorf("Expected ok %v for %+v, got %v", tt.ok, tt.limits, err)
+		}
+	}
+
+	limits := RelayLimitsFromNIP11(nip11.RelayInformationDocument{Limitation: &nip11.RelayLimitationDocument{MaxMessageLength: 16384, MaxEventTags: 100}})
+	if limits != (RelayLimits{MaxMessageLength: 16384, MaxEventTags: 100}) {
+		t.Errorf("Expected the NIP-11 limits, got %+v", limits)
+	}
+}
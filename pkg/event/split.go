@@ -0,0 +1,82 @@
+package event
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// splitTags returns the split tags of a payment, ["split", recipient, bps]
+func splitTags(splits []neth.Split) []nostr.Tag {
+	tags := make([]nostr.Tag, 0, len(splits))
+	for _, split := range splits {
+		tags = append(tags, nostr.Tag{"split", split.Recipient, strconv.Itoa(int(split.BPS))})
+	}
+	return tags
+}
+
+// GetSplitsFromEvent returns the split attached to a payment from its split tags, nil when the
+// payment is not split
+func GetSplitsFromEvent(evt *nostr.Event) ([]neth.Split, error) {
+	var splits []neth.Split
+	for _, tag := range evt.Tags {
+		if len(tag) < 3 || tag[0] != "split" {
+			continue
+		}
+
+		bps, err := strconv.ParseUint(tag[2], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid split of %s: %s", tag[1], tag[2])
+		}
+		splits = append(splits, neth.Split{Recipient: tag[1], BPS: uint16(bps)})
+	}
+
+	if splits == nil {
+		return nil, nil
+	}
+	if err := neth.ValidateSplits(splits); err != nil {
+		return nil, err
+	}
+
+	return splits, nil
+}
+
+// VerifyTransferSplits checks that a batch transaction paid out the split of a transfer, each
+// recipient receiving its share of the transferred amount in the same token. The batch is given
+// as the tx log or transfer events of its transfers.
+func VerifyTransferSplits(transfer *nostr.Event, batch []*nostr.Event) error {
+	if !IsTxTransferEvent(transfer) {
+		return fmt.Errorf("event is not a transfer event (kind %d)", transfer.Kind)
+	}
+
+	transferData, err := ParseTxTransferEvent(transfer)
+	if err != nil {
+		return err
+	}
+	if len(transferData.Splits) == 0 {
+		return fmt.Errorf("transfer has no split")
+	}
+
+	data, err := transferData.LogData.GetTransferData()
+	if err != nil || data == nil {
+		return fmt.Errorf("transfer has no transfer data")
+	}
+	total, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok {
+		return fmt.Errorf("invalid transfer amount: %s", data.Value)
+	}
+
+	logs := make([]neth.Log, 0, len(batch))
+	for _, evt := range batch {
+		log, err := logFromEvent(evt)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, *log)
+	}
+
+	return neth.VerifySplitTransfers(transferData.LogData.To, total, transferData.Splits, logs)
+}
@@ -0,0 +1,62 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTransferSplits(t *testing.T) {
+	splits := []neth.Split{
+		{Recipient: "0x3333333333333333333333333333333333333333", BPS: 7000},
+		{Recipient: "0x4444444444444444444444444444444444444444", BPS: 3000},
+	}
+
+	if _, err := CreateTxTransferEvent(goldenLog(), WithSplits(splits[0])); err == nil {
+		t.Error("Expected an error for a split short of 100%")
+	}
+
+	transfer, err := CreateTxTransferEvent(goldenLog(), WithSplits(splits...))
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+
+	parsed, err := GetSplitsFromEvent(transfer)
+	if err != nil {
+		t.Fatalf("Failed to get splits: %v", err)
+	}
+	if len(parsed) != 2 || parsed[0] != splits[0] || parsed[1] != splits[1] {
+		t.Errorf("Expected splits %v, got %v", splits, parsed)
+	}
+
+	// The holder of the payment pays the recipients out in one batch transaction
+	payout := func(to, value string) *nostr.Event {
+		log := goldenLog()
+		data := json.RawMessage(fmt.Sprintf(`{"from":"0x2222222222222222222222222222222222222222","to":"%s","value":"%s"}`, to, value))
+		log.Hash = "0x" + value
+		log.TxHash = "0xbatch"
+		log.Data = &data
+
+		evt, err := CreateTxLogEvent(log)
+		if err != nil {
+			t.Fatalf("Failed to create payout: %v", err)
+		}
+		return evt
+	}
+
+	batch := []*nostr.Event{
+		payout(splits[0].Recipient, "700000000000000000"),
+		payout(splits[1].Recipient, "300000000000000000"),
+	}
+	if err := VerifyTransferSplits(transfer, batch); err != nil {
+		t.Errorf("Expected the batch to pay the split out, got %v", err)
+	}
+
+	batch[1] = payout(splits[1].Recipient, "200000000000000000")
+	if err := VerifyTransferSplits(transfer, batch); err == nil {
+		t.Error("Expected an error for an underpaid recipient")
+	}
+}
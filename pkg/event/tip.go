@@ -0,0 +1,296 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	KindReaction    = 7      // NIP-25 reaction, tips are reactions with a payment intent
+	KindTipTransfer = 111014 // Links a tip to the transfer that paid it
+
+	// DefaultTipReaction is the reaction of tips created without one
+	DefaultTipReaction = "⚡"
+)
+
+// TipStatus represents the status of a tip
+type TipStatus string
+
+const (
+	TipStatusPending   TipStatus = "pending"   // The tip was sent, the transfer is not confirmed
+	TipStatusConfirmed TipStatus = "confirmed" // The transfer paying the tip is linked
+)
+
+// Reaction is the reaction of a tip, an emoji or a NIP-30 custom emoji
+type Reaction struct {
+	Content  string // Emoji, or the shortcode of a custom emoji
+	EmojiURL string // Image of a custom emoji, empty for plain emojis
+}
+
+// TipIntent is the payment a tip promises
+type TipIntent struct {
+	ChainID string   `json:"chain_id"`
+	Token   string   `json:"token"` // ERC-20 contract address
+	Amount  *big.Int `json:"amount"`
+	To      string   `json:"to"` // Address of the recipient
+}
+
+// TipEvent represents a tip on a message
+type TipEvent struct {
+	MessageID string    `json:"message_id"`
+	Author    string    `json:"author"` // Public key of the author of the message
+	Reaction  Reaction  `json:"reaction"`
+	Intent    TipIntent `json:"intent"`
+	Status    TipStatus `json:"status"`
+}
+
+// TipTransferEvent represents the link between a tip and the transfer that paid it
+type TipTransferEvent struct {
+	TipID      string `json:"tip_id"`
+	TransferID string `json:"transfer_id"`
+	ChainID    string `json:"chain_id"`
+	TxHash     string `json:"tx_hash"`
+}
+
+// CreateTipEvent creates a tip on a message (kind 7), a NIP-25 reaction carrying the payment
+// intent in its tags. Once the transfer is confirmed it is linked with CreateTipTransferEvent.
+func CreateTipEvent(message *nostr.Event, reaction Reaction, intent TipIntent, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	if message == nil || message.ID == "" {
+		return nil, fmt.Errorf("tipped message must have an ID")
+	}
+	if intent.Amount == nil || intent.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("tip amount must be positive")
+	}
+	if !isEthereumAddress(intent.Token) || !isEthereumAddress(intent.To) {
+		return nil, fmt.Errorf("tip token and recipient must be addresses")
+	}
+	if intent.ChainID == "" {
+		return nil, fmt.Errorf("tip chain ID cannot be empty")
+	}
+
+	content := reaction.Content
+	if content == "" {
+		content = DefaultTipReaction
+	}
+	if reaction.EmojiURL != "" {
+		content = ":" + reaction.Content + ":"
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindReaction),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   content, // Reaction
+	}
+
+	// Reaction tags (NIP-25)
+	evt.Tags = append(evt.Tags, []string{"e", message.ID, references.eventRelay(message), message.PubKey})
+	if message.PubKey != "" {
+		evt.Tags = append(evt.Tags, hintedTag("p", message.PubKey, references.pubKeyRelay(message.PubKey)))
+	}
+	evt.Tags = append(evt.Tags, []string{"k", strconv.Itoa(message.Kind)})
+
+	// Custom emoji tag (NIP-30)
+	if reaction.EmojiURL != "" {
+		evt.Tags = append(evt.Tags, []string{"emoji", reaction.Content, reaction.EmojiURL})
+	}
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tip"))             // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Payment intent tags
+	evt.Tags = append(evt.Tags, []string{"layer", intent.ChainID})          // Chain ID
+	evt.Tags = append(evt.Tags, []string{"token", intent.Token})            // Token contract
+	evt.Tags = append(evt.Tags, []string{"amount", intent.Amount.String()}) // Amount
+	evt.Tags = append(evt.Tags, []string{"recipient", intent.To})           // Recipient address
+	evt.Tags = append(evt.Tags, []string{"status", string(TipStatusPending)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is a tip of %s of token %s on chain %s", intent.Amount, intent.Token, intent.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// IsTipEvent checks if an event is a tip, a reaction with a payment intent
+func IsTipEvent(evt *nostr.Event) bool {
+	return evt != nil && DefaultKind(evt.Kind) == KindReaction && HasTypeTag(evt, "tip")
+}
+
+// ParseTipEvent parses a tip, the data of tips is in their tags
+func ParseTipEvent(evt *nostr.Event) (*TipEvent, error) {
+	if err := CheckEvent(evt); err != nil {
+		return nil, err
+	}
+	if !IsTipEvent(evt) {
+		return nil, fmt.Errorf("event is not a tip event (kind %d)", evt.Kind)
+	}
+
+	tip := TipEvent{
+		Reaction: Reaction{Content: evt.Content},
+		Status:   TipStatusPending,
+	}
+
+	if tag := evt.Tags.Find("e"); tag != nil {
+		tip.MessageID = tag[1]
+	}
+	if tag := evt.Tags.Find("p"); tag != nil {
+		tip.Author = tag[1]
+	}
+
+	// Custom emojis are stored as their shortcode
+	shortcode := strings.Trim(evt.Content, ":")
+	if tag := evt.Tags.FindWithValue("emoji", shortcode); len(tag) >= 3 {
+		tip.Reaction = Reaction{Content: shortcode, EmojiURL: tag[2]}
+	}
+
+	if tag := evt.Tags.Find("layer"); tag != nil {
+		tip.Intent.ChainID = tag[1]
+	}
+	if tag := evt.Tags.Find("token"); tag != nil {
+		tip.Intent.Token = tag[1]
+	}
+	if tag := evt.Tags.Find("recipient"); tag != nil {
+		tip.Intent.To = tag[1]
+	}
+	if tag := evt.Tags.Find("status"); tag != nil {
+		tip.Status = TipStatus(tag[1])
+	}
+
+	tag := evt.Tags.Find("amount")
+	if tag == nil {
+		return nil, fmt.Errorf("tip has no amount")
+	}
+	amount, ok := new(big.Int).SetString(tag[1], 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid tip amount: %s", tag[1])
+	}
+	tip.Intent.Amount = amount
+
+	if tip.MessageID == "" {
+		return nil, fmt.Errorf("tip has no message")
+	}
+
+	return &tip, nil
+}
+
+// CreateTipTransferEvent links a tip to the transfer that paid it (kind 111014), the transfer
+// must match the payment intent of the tip
+func CreateTipTransferEvent(tip *nostr.Event, transfer *nostr.Event, opts ...ReferenceOption) (*nostr.Event, error) {
+	references := newReferenceOptions(opts)
+
+	tipData, err := ParseTipEvent(tip)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsTxTransferEvent(transfer) {
+		return nil, fmt.Errorf("event is not a transfer event (kind %d)", transfer.Kind)
+	}
+	transferData, err := ParseTxTransferEvent(transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := matchTipTransfer(tipData.Intent, transferData.LogData); err != nil {
+		return nil, err
+	}
+
+	link := TipTransferEvent{
+		TipID:      tip.ID,
+		TransferID: transfer.ID,
+		ChainID:    transferData.LogData.ChainID,
+		TxHash:     transferData.LogData.TxHash,
+	}
+
+	content, err := json.Marshal(link)
+	if err != nil {
+		return nil, err
+	}
+
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(KindTipTransfer),
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// References to the tip, the transfer and the tipped message
+	evt.Tags = append(evt.Tags, []string{"e", tip.ID, references.eventRelay(tip), "tip"})
+	evt.Tags = append(evt.Tags, []string{"e", transfer.ID, references.eventRelay(transfer), "transfer"})
+	evt.Tags = append(evt.Tags, []string{"e", tipData.MessageID, "", "root"})
+	if tipData.Author != "" {
+		evt.Tags = append(evt.Tags, hintedTag("p", tipData.Author, references.pubKeyRelay(tipData.Author)))
+	}
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tip"))             // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tags
+	evt.Tags = append(evt.Tags, []string{"layer", link.ChainID}) // Chain ID
+	evt.Tags = append(evt.Tags, []string{"r", link.TxHash})      // Transaction hash
+	evt.Tags = append(evt.Tags, []string{"status", string(TipStatusConfirmed)})
+
+	// Alt tag
+	alt := fmt.Sprintf("This links a tip to transaction %s on chain %s", link.TxHash, link.ChainID)
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTipTransferEvent parses the link between a tip and its transfer
+func ParseTipTransferEvent(evt *nostr.Event) (*TipTransferEvent, error) {
+	if DefaultKind(evt.Kind) != KindTipTransfer {
+		return nil, fmt.Errorf("event is not a tip transfer event (kind %d)", evt.Kind)
+	}
+
+	var link TipTransferEvent
+	if err := unmarshalContent(evt, &link); err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// matchTipTransfer checks that a transfer pays the payment intent of a tip
+func matchTipTransfer(intent TipIntent, log neth.Log) error {
+	if log.ChainID != intent.ChainID {
+		return fmt.Errorf("transfer is on chain %s, the tip on chain %s", log.ChainID, intent.ChainID)
+	}
+	if !strings.EqualFold(log.To, intent.Token) {
+		return fmt.Errorf("transfer is of token %s, the tip of token %s", log.To, intent.Token)
+	}
+	if log.Data == nil {
+		return fmt.Errorf("transfer has no data")
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(*log.Data, &data); err != nil {
+		return fmt.Errorf("invalid transfer data: %w", err)
+	}
+
+	to, _ := data[neth.DataKeyTo].(string)
+	if !strings.EqualFold(to, intent.To) {
+		return fmt.Errorf("transfer is to %s, the tip to %s", to, intent.To)
+	}
+
+	value, _ := data[neth.DataKeyValue].(string)
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Cmp(intent.Amount) != 0 {
+		return fmt.Errorf("transfer is of %s, the tip of %s", value, intent.Amount)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTipEvent(t *testing.T) {
+	message := &nostr.Event{ID: "aa", PubKey: goldenPubKey, Kind: 1}
+	intent := TipIntent{
+		ChainID: "100",
+		Token:   "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d",
+		Amount:  big.NewInt(1000000000000000000),
+		To:      "0x2222222222222222222222222222222222222222",
+	}
+
+	tip, err := CreateTipEvent(message, Reaction{Content: "pizza", EmojiURL: "https://example.com/pizza.png"}, intent)
+	if err != nil {
+		t.Fatalf("Failed to create tip: %v", err)
+	}
+	tip.ID = tip.GetID()
+
+	if tip.Kind != KindReaction || tip.Content != ":pizza:" {
+		t.Errorf("Expected a :pizza: reaction, got kind %d with %s", tip.Kind, tip.Content)
+	}
+
+	parsed, err := ParseTipEvent(tip)
+	if err != nil {
+		t.Fatalf("Failed to parse tip: %v", err)
+	}
+	if parsed.MessageID != "aa" || parsed.Author != goldenPubKey || parsed.Status != TipStatusPending {
+		t.Errorf("Expected a pending tip on aa, got %+v", parsed)
+	}
+	if parsed.Reaction.EmojiURL != "https://example.com/pizza.png" || parsed.Intent.Amount.Cmp(intent.Amount) != 0 {
+		t.Errorf("Expected the custom emoji and intent back, got %+v", parsed)
+	}
+
+	transfer, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create transfer: %v", err)
+	}
+	transfer.ID = transfer.GetID()
+
+	link, err := CreateTipTransferEvent(tip, transfer)
+	if err != nil {
+		t.Fatalf("Failed to link transfer: %v", err)
+	}
+
+	result, err := ParseEvent(link)
+	if err != nil {
+		t.Fatalf("Failed to parse link: %v", err)
+	}
+	linkData, ok := result.(*TipTransferEvent)
+	if !ok || linkData.TipID != tip.ID || linkData.TransferID != transfer.ID {
+		t.Errorf("Expected a link of the tip and transfer, got %#v", result)
+	}
+
+	intent.Amount = big.NewInt(1)
+	other, err := CreateTipEvent(message, Reaction{}, intent)
+	if err != nil {
+		t.Fatalf("Failed to create tip: %v", err)
+	}
+	if other.Content != DefaultTipReaction {
+		t.Errorf("Expected reaction %s, got %s", DefaultTipReaction, other.Content)
+	}
+	if _, err := CreateTipTransferEvent(other, transfer); err == nil {
+		t.Error("Expected an error for a transfer of another amount")
+	}
+}
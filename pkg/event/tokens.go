@@ -0,0 +1,119 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+// TokenRegistry holds the known tokens, the spam tokens and the stablecoins of each chain
+type TokenRegistry struct {
+	mu     sync.RWMutex
+	known  map[string]neth.TokenInfo
+	spam   map[string]bool
+	stable map[string]neth.Stablecoin
+}
+
+// DefaultTokenRegistry is the registry consulted by CreateTxTransferEvent unless another one is provided
+var DefaultTokenRegistry = NewTokenRegistry()
+
+// NewTokenRegistry creates a new empty token registry
+func NewTokenRegistry() *TokenRegistry {
+	return &TokenRegistry{
+		known:  make(map[string]neth.TokenInfo),
+		spam:   make(map[string]bool),
+		stable: make(map[string]neth.Stablecoin),
+	}
+}
+
+// tokenKey identifies a token by its chain ID and lowercased address
+func tokenKey(chainID, address string) string {
+	return fmt.Sprintf("%s:%s", chainID, strings.ToLower(address))
+}
+
+// AddTokenList adds the tokens of a list to the known tokens
+func (r *TokenRegistry) AddTokenList(list *neth.TokenList) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range list.Tokens {
+		r.known[tokenKey(fmt.Sprint(token.ChainID), token.Address)] = token
+	}
+}
+
+// AddSpamTokenList adds the tokens of a list to the spam tokens, deny lists use the same format
+// as token lists
+func (r *TokenRegistry) AddSpamTokenList(list *neth.TokenList) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range list.Tokens {
+		r.spam[tokenKey(fmt.Sprint(token.ChainID), token.Address)] = true
+	}
+}
+
+// AddSpamTokens adds token addresses of a chain to the spam tokens
+func (r *TokenRegistry) AddSpamTokens(chainID string, addresses ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, address := range addresses {
+		r.spam[tokenKey(chainID, address)] = true
+	}
+}
+
+// AddStablecoins adds tokens to the stablecoins, e.g. neth.KnownStablecoins. Currencies are
+// uppercased.
+func (r *TokenRegistry) AddStablecoins(coins ...neth.Stablecoin) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, coin := range coins {
+		coin.Currency = strings.ToUpper(coin.Currency)
+		r.stable[tokenKey(fmt.Sprint(coin.ChainID), coin.Address)] = coin
+	}
+}
+
+// Stablecoin returns the stablecoin entry of a token, with the currency of its peg
+func (r *TokenRegistry) Stablecoin(chainID, address string) (neth.Stablecoin, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coin, ok := r.stable[tokenKey(chainID, address)]
+	return coin, ok
+}
+
+// Token returns the token list entry of a token
+func (r *TokenRegistry) Token(chainID, address string) (neth.TokenInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.known[tokenKey(chainID, address)]
+	return token, ok
+}
+
+// IsKnownToken checks if a token is part of a loaded token list
+func (r *TokenRegistry) IsKnownToken(chainID, address string) bool {
+	_, ok := r.Token(chainID, address)
+	return ok
+}
+
+// IsSpamToken checks if a token is on the spam list
+func (r *TokenRegistry) IsSpamToken(chainID, address string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.spam[tokenKey(chainID, address)]
+}
+
+// IsKnownToken checks if a token is part of a token list loaded in the default registry
+func IsKnownToken(chainID, address string) bool {
+	return DefaultTokenRegistry.IsKnownToken(chainID, address)
+}
+
+// IsSpamToken checks if a token is on the spam list of the default registry
+func IsSpamToken(chainID, address string) bool {
+	return DefaultTokenRegistry.IsSpamToken(chainID, address)
+}
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+func TestTransferTokenListTags(t *testing.T) {
+	list, err := neth.ParseTokenList([]byte(`{
+		"name": "Test List",
+		"timestamp": "2024-01-01T00:00:00Z",
+		"version": {"major": 1, "minor": 0, "patch": 0},
+		"tokens": [
+			{"chainId": 100, "address": "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d", "name": "Wrapped XDAI", "symbol": "WXDAI", "decimals": 18}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse token list: %v", err)
+	}
+
+	registry := NewTokenRegistry()
+	registry.AddTokenList(list)
+	registry.AddSpamTokens("100", "0x00000000000000000000000000000000000000aa")
+
+	tests := []struct {
+		token    string
+		expected string
+	}{
+		{"0xe91d153e0b41518a2ce8dd3d7944fa863463a97d", "verified"},
+		{"0x00000000000000000000000000000000000000AA", "spam"},
+		{"0x00000000000000000000000000000000000000bb", ""},
+	}
+
+	for _, tt := range tests {
+		data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"1"}`)
+		evt, err := CreateTxTransferEvent(neth.Log{
+			Hash:      "0x1",
+			TxHash:    "0x2",
+			ChainID:   "100",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: time.Unix(1700000000, 0),
+			To:        tt.token,
+			Value:     big.NewInt(0),
+			Data:      &data,
+		}, WithTokenRegistry(registry))
+		if err != nil {
+			t.Fatalf("Failed to create transfer event: %v", err)
+		}
+
+		found := ""
+		for _, tag := range evt.Tags {
+			if len(tag) >= 2 && tag[0] == "t" && (tag[1] == "verified" || tag[1] == "spam") {
+				found = tag[1]
+			}
+		}
+		if found != tt.expected {
+			t.Errorf("Expected token %s to be tagged %q, got %q", tt.token, tt.expected, found)
+		}
+	}
+
+	if _, err := neth.ParseTokenList([]byte(`{"name":"bad","tokens":[{"chainId":1,"address":"nope"}]}`)); err == nil {
+		t.Error("Expected invalid token address to fail")
+	}
+}
+
+func TestTransferStablecoinTags(t *testing.T) {
+	registry := NewTokenRegistry()
+	registry.AddStablecoins(neth.KnownStablecoins...)
+	registry.AddStablecoins(neth.Stablecoin{ChainID: 100, Address: "0x00000000000000000000000000000000000000cc", Symbol: "CHF", Decimals: 2, Currency: "chf"})
+
+	tests := []struct {
+		token    string
+		value    string
+		currency string
+		amount   string
+	}{
+		{"0xcb444e90d8198415266c6a2724b7900fb12fc56e", "12500000000000000000", "EUR", "12.5"},
+		{"0x00000000000000000000000000000000000000CC", "1999", "CHF", "19.99"},
+		{"0x00000000000000000000000000000000000000bb", "1", "", ""},
+	}
+
+	for _, tt := range tests {
+		data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"` + tt.value + `"}`)
+		evt, err := CreateTxTransferEvent(neth.Log{
+			Hash:      "0x1",
+			TxHash:    "0x2",
+			ChainID:   "100",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: time.Unix(1700000000, 0),
+			To:        tt.token,
+			Value:     big.NewInt(0),
+			Data:      &data,
+		}, WithTokenRegistry(registry))
+		if err != nil {
+			t.Fatalf("Failed to create transfer event: %v", err)
+		}
+
+		stable, err := GetStableAmountFromEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to get the currency: %v", err)
+		}
+		if tt.currency == "" {
+			if stable != nil || HasTypeTag(evt, "stablecoin") {
+				t.Errorf("Expected token %s without currency, got %+v", tt.token, stable)
+			}
+			continue
+		}
+		if stable == nil || stable.Currency != tt.currency || stable.Amount != tt.amount {
+			t.Errorf("Expected %s %s for token %s, got %+v", tt.amount, tt.currency, tt.token, stable)
+		}
+		if !HasTypeTag(evt, "stablecoin") {
+			t.Errorf("Expected token %s to be tagged stablecoin", tt.token)
+		}
+	}
+}
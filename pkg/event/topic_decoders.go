@@ -0,0 +1,197 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// fieldAliases maps a normalized data key to the parameter names used by known contracts
+type fieldAliases map[string][]string
+
+// newFieldDecoder creates a decoder that renames the log data parameters to normalized keys,
+// the first alias present in the data wins
+func newFieldDecoder(aliases fieldAliases) TopicDecoder {
+	return func(log neth.Log) (map[string]string, error) {
+		values := make(map[string]string)
+		if log.Data == nil {
+			return values, nil
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(*log.Data))
+		decoder.UseNumber()
+
+		var data map[string]interface{}
+		if err := decoder.Decode(&data); err != nil {
+			return nil, err
+		}
+
+		for key, names := range aliases {
+			for _, name := range names {
+				if value, ok := scalarString(data[name]); ok {
+					values[key] = value
+					break
+				}
+			}
+		}
+
+		return values, nil
+	}
+}
+
+// scalarString converts a decoded JSON scalar to a string
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	default:
+		return "", false
+	}
+}
+
+// defaultTopicEntries are the decoders shipped with the package for the most common topics
+var defaultTopicEntries = map[string]TopicEntry{
+	neth.TopicERC20Approval: {
+		Name: "erc20_approval",
+		Decoder: newFieldDecoder(fieldAliases{
+			neth.DataKeyOwner:   {"owner", "src"},
+			neth.DataKeySpender: {"spender", "guy"},
+			neth.DataKeyValue:   {"value", "wad", "amount"},
+		}),
+		Tags: []nostr.Tag{
+			{"p", "{owner}"},
+			{"p", "{spender}"},
+			{"owner", "{owner}"},
+			{"spender", "{spender}"},
+			{"value", "{value}"},
+		},
+	},
+	neth.TopicPermit2Approval: {
+		Name:    "permit2_approval",
+		Decoder: permit2Decoder,
+		Tags: []nostr.Tag{
+			{"t", "permit2"},
+			{"p", "{owner}"},
+			{"p", "{spender}"},
+			{"owner", "{owner}"},
+			{"spender", "{spender}"},
+			{"token", "{token}"},
+			{"amount", "{amount}"},
+		},
+	},
+	neth.TopicPermit2Permit: {
+		Name:    "permit2_permit",
+		Decoder: permit2Decoder,
+		Tags: []nostr.Tag{
+			{"t", "permit2"},
+			{"p", "{owner}"},
+			{"p", "{spender}"},
+			{"owner", "{owner}"},
+			{"spender", "{spender}"},
+			{"token", "{token}"},
+			{"amount", "{amount}"},
+		},
+	},
+	neth.TopicPermit2Lockdown: {
+		Name:    "permit2_lockdown",
+		Decoder: permit2Decoder,
+		Tags: []nostr.Tag{
+			{"t", "permit2"},
+			{"p", "{owner}"},
+			{"p", "{spender}"},
+			{"owner", "{owner}"},
+			{"spender", "{spender}"},
+			{"token", "{token}"},
+		},
+	},
+	neth.TopicUniswapV2Swap: {
+		Name: "uniswap_v2_swap",
+		Decoder: newFieldDecoder(fieldAliases{
+			neth.DataKeySender: {"sender"},
+			neth.DataKeyTo:     {"to", "recipient"},
+			"amount0_in":       {"amount0In", "amount0_in"},
+			"amount1_in":       {"amount1In", "amount1_in"},
+			"amount0_out":      {"amount0Out", "amount0_out"},
+			"amount1_out":      {"amount1Out", "amount1_out"},
+		}),
+		Tags: []nostr.Tag{
+			{"t", "swap"},
+			{"p", "{sender}"},
+			{"p", "{to}"},
+			{"amount0_in", "{amount0_in}"},
+			{"amount1_in", "{amount1_in}"},
+			{"amount0_out", "{amount0_out}"},
+			{"amount1_out", "{amount1_out}"},
+		},
+	},
+	neth.TopicUniswapV3Swap: {
+		Name: "uniswap_v3_swap",
+		Decoder: newFieldDecoder(fieldAliases{
+			neth.DataKeySender: {"sender"},
+			neth.DataKeyTo:     {"recipient", "to"},
+			"amount0":          {"amount0"},
+			"amount1":          {"amount1"},
+			"sqrt_price_x96":   {"sqrtPriceX96", "sqrt_price_x96"},
+			"liquidity":        {"liquidity"},
+			"tick":             {"tick"},
+		}),
+		Tags: []nostr.Tag{
+			{"t", "swap"},
+			{"p", "{sender}"},
+			{"p", "{to}"},
+			{"amount0", "{amount0}"},
+			{"amount1", "{amount1}"},
+			{"sqrt_price_x96", "{sqrt_price_x96}"},
+			{"liquidity", "{liquidity}"},
+			{"tick", "{tick}"},
+		},
+	},
+	neth.TopicWETHDeposit: {
+		Name: "weth_deposit",
+		Decoder: newFieldDecoder(fieldAliases{
+			neth.DataKeyTo:    {"dst", "to", "account"},
+			neth.DataKeyValue: {"wad", "value", "amount"},
+		}),
+		Tags: []nostr.Tag{
+			{"t", "wrap"},
+			{"p", "{to}"},
+			{"value", "{value}"},
+		},
+	},
+	neth.TopicWETHWithdrawal: {
+		Name: "weth_withdrawal",
+		Decoder: newFieldDecoder(fieldAliases{
+			neth.DataKeyFrom:  {"src", "from", "account"},
+			neth.DataKeyValue: {"wad", "value", "amount"},
+		}),
+		Tags: []nostr.Tag{
+			{"t", "unwrap"},
+			{"p", "{from}"},
+			{"value", "{value}"},
+		},
+	},
+}
+
+// RegisterDefaultTopics adds the built-in decoders (ERC20 Approval, Permit2 allowances, Uniswap
+// V2/V3 Swap, WETH Deposit/Withdrawal) to a registry
+func RegisterDefaultTopics(registry *TopicRegistry) error {
+	for topic, entry := range defaultTopicEntries {
+		if err := registry.Register(topic, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	if err := RegisterDefaultTopics(DefaultTopicRegistry); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,215 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// TopicDecoder decodes the data of a log into normalized key/value pairs
+type TopicDecoder func(log neth.Log) (map[string]string, error)
+
+// TopicEntry describes how logs of a given topic are decoded and tagged
+type TopicEntry struct {
+	// Name is a short identifier for the event (e.g. "uniswap_v2_swap"), added as a t tag
+	Name string
+
+	// Decoder turns the log into normalized values, the raw log data is used when nil
+	Decoder TopicDecoder
+
+	// Tags is a template of tags to add, elements may reference decoded values as "{key}".
+	// Tags referencing a value that was not decoded are skipped.
+	Tags []nostr.Tag
+}
+
+// TopicRegistry maps log topics to decoders and tag templates
+type TopicRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]TopicEntry
+}
+
+// DefaultTopicRegistry is the registry consulted by CreateTxLogEvent unless another one is provided
+var DefaultTopicRegistry = NewTopicRegistry()
+
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// NewTopicRegistry creates a new empty topic registry
+func NewTopicRegistry() *TopicRegistry {
+	return &TopicRegistry{
+		entries: make(map[string]TopicEntry),
+	}
+}
+
+// Register adds or replaces the entry for a topic hash
+func (r *TopicRegistry) Register(topic string, entry TopicEntry) error {
+	if !isTopicHash(topic) {
+		return fmt.Errorf("invalid topic hash: %s", topic)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[strings.ToLower(topic)] = entry
+
+	return nil
+}
+
+// Unregister removes the entry for a topic hash
+func (r *TopicRegistry) Unregister(topic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, strings.ToLower(topic))
+}
+
+// Lookup returns the entry registered for a topic hash
+func (r *TopicRegistry) Lookup(topic string) (TopicEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[strings.ToLower(topic)]
+	return entry, ok
+}
+
+// Topics returns all registered topic hashes
+func (r *TopicRegistry) Topics() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	topics := make([]string, 0, len(r.entries))
+	for topic := range r.entries {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// Tags decodes a log using the entry registered for its topic and renders the tag template.
+// The entry name is returned along with the tags, both are empty if the topic is not registered.
+func (r *TopicRegistry) Tags(log neth.Log) (string, []nostr.Tag, error) {
+	return r.tags(log, CurrentFlattenPolicy(), CurrentSanitizePolicy())
+}
+
+// tags is Tags flattening and sanitizing the values of the log data with policies
+func (r *TopicRegistry) tags(log neth.Log, policy FlattenPolicy, sanitize SanitizePolicy) (string, []nostr.Tag, error) {
+	entry, ok := r.Lookup(log.Topic)
+	if !ok {
+		return "", nil, nil
+	}
+
+	var values map[string]string
+	if entry.Decoder != nil {
+		decoded, err := entry.Decoder(log)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decode topic %s: %w", log.Topic, err)
+		}
+		values = decoded
+	} else {
+		values = logDataValues(log, policy, sanitize)
+	}
+
+	var tags []nostr.Tag
+	if entry.Name != "" {
+		tags = append(tags, typeTag(entry.Name))
+	}
+
+	for _, template := range entry.Tags {
+		if tag, ok := renderTagTemplate(template, values); ok {
+			if len(tag) >= 2 && tag[0] == "t" {
+				tag = typeTag(tag[1]) // Templates hold plain values, they follow the namespace
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	return entry.Name, tags, nil
+}
+
+// RegisterTopic adds or replaces the entry for a topic hash in the default registry
+func RegisterTopic(topic string, entry TopicEntry) error {
+	return DefaultTopicRegistry.Register(topic, entry)
+}
+
+// renderTagTemplate replaces the "{key}" placeholders of a tag template with decoded values
+func renderTagTemplate(template nostr.Tag, values map[string]string) (nostr.Tag, bool) {
+	tag := make(nostr.Tag, len(template))
+	for i, element := range template {
+		missing := false
+		tag[i] = templatePlaceholder.ReplaceAllStringFunc(element, func(placeholder string) string {
+			value, ok := values[placeholder[1:len(placeholder)-1]]
+			if !ok {
+				missing = true
+			}
+			return value
+		})
+
+		if missing {
+			return nil, false
+		}
+	}
+	return tag, true
+}
+
+// logDataValues converts the flattened log data into key/value pairs
+func logDataValues(log neth.Log, policy FlattenPolicy, sanitize SanitizePolicy) map[string]string {
+	values := make(map[string]string)
+	if log.Data == nil {
+		return values
+	}
+
+	// Renamed tags are keyed by their data key, the names the templates use
+	for _, tag := range flattenDataToTags(*log.Data, policy, sanitize) {
+		values[policy.DataKey(tag[0])] = tag[1]
+	}
+
+	// Addresses are flattened into p tags, keep them under their original keys
+	data, err := log.GetEventData()
+	if err == nil {
+		for key, value := range data {
+			if str, ok := value.(string); ok {
+				values[key] = str
+			}
+		}
+	}
+
+	return values
+}
+
+// TopicTagValues returns the t tag values to filter the events of logs of topics with, e.g.
+// nostr.Filter{Tags: nostr.TagMap{"t": values}}. Topics are given as hashes, curated names, e.g.
+// "erc1155_transfer_single", or event signatures, e.g. "Transfer(address,address,uint256)".
+func TopicTagValues(topics ...string) ([]string, error) {
+	values := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		hash := strings.ToLower(topic)
+		if !isTopicHash(topic) {
+			var ok bool
+			if hash, ok = neth.NameToTopic(topic); !ok {
+				return nil, fmt.Errorf("unknown topic: %s", topic)
+			}
+		}
+		if !slices.Contains(values, hash) {
+			values = append(values, hash)
+		}
+	}
+	return values, nil
+}
+
+// isTopicHash checks if a string looks like a 32 byte topic hash
+func isTopicHash(value string) bool {
+	if len(value) != 66 || !strings.HasPrefix(value, "0x") {
+		return false
+	}
+
+	for _, char := range value[2:] {
+		if !((char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')) {
+			return false
+		}
+	}
+
+	return true
+}
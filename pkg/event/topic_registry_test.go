@@ -0,0 +1,138 @@
+package event
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTopicRegistry(t *testing.T) {
+	topic := "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822"
+
+	registry := NewTopicRegistry()
+	err := registry.Register(topic, TopicEntry{
+		Name: "test_swap",
+		Decoder: func(log neth.Log) (map[string]string, error) {
+			return map[string]string{"pair": log.To, "amount_in": "100"}, nil
+		},
+		Tags: []nostr.Tag{
+			{"pair", "{pair}"},
+			{"amount_in", "{amount_in}"},
+			{"amount_out", "{amount_out}"}, // not decoded, should be skipped
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to register topic: %v", err)
+	}
+
+	if err := registry.Register("not-a-topic", TopicEntry{}); err == nil {
+		t.Error("Expected error registering an invalid topic")
+	}
+
+	data := json.RawMessage(`{"topic":"` + topic + `"}`)
+	logData := neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    "0xabcdef1234567890",
+		ChainID:   "1",
+		Topic:     topic,
+		To:        "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b7",
+		CreatedAt: time.Now(),
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+
+	event, err := CreateTxLogEvent(logData, WithTopicRegistry(registry))
+	if err != nil {
+		t.Fatalf("Failed to create Nostr event: %v", err)
+	}
+
+	expected := map[string]string{
+		"pair":      "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b7",
+		"amount_in": "100",
+	}
+	foundName := false
+	for _, tag := range event.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		if tag[0] == "t" && tag[1] == "test_swap" {
+			foundName = true
+		}
+		if tag[0] == "amount_out" {
+			t.Error("Expected amount_out tag to be skipped")
+		}
+		if value, ok := expected[tag[0]]; ok && tag[1] == value {
+			delete(expected, tag[0])
+		}
+	}
+
+	if !foundName {
+		t.Error("Expected test_swap tag not found")
+	}
+	for key, value := range expected {
+		t.Errorf("Expected tag %s=%s not found", key, value)
+	}
+}
+
+func TestDefaultTopicDecoders(t *testing.T) {
+	data := json.RawMessage(`{"topic":"` + neth.TopicERC20Approval + `","src":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6","guy":"0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b7","wad":115792089237316195423570985008687907853269984665640564039457584007913129639935}`)
+	logData := neth.Log{
+		Hash:      "0x1234567890abcdef",
+		TxHash:    "0xabcdef1234567890",
+		ChainID:   "1",
+		Topic:     neth.TopicERC20Approval,
+		CreatedAt: time.Now(),
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+
+	event, err := CreateTxLogEvent(logData)
+	if err != nil {
+		t.Fatalf("Failed to create Nostr event: %v", err)
+	}
+
+	expected := map[string]string{
+		"owner":   "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6",
+		"spender": "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b7",
+		"value":   "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+	}
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 {
+			if value, ok := expected[tag[0]]; ok && tag[1] == value {
+				delete(expected, tag[0])
+			}
+		}
+	}
+	for key, value := range expected {
+		t.Errorf("Expected tag %s=%s not found", key, value)
+	}
+
+	if !event.Tags.ContainsAny("t", []string{"erc20_approval"}) {
+		t.Error("Expected erc20_approval tag not found")
+	}
+}
+
+func TestTopicTagValues(t *testing.T) {
+	values, err := TopicTagValues("erc721_transfer", neth.TopicERC20Transfer, "Approval(address,address,uint256)", "safe_execution_success")
+	if err != nil {
+		t.Fatalf("Failed to resolve topics: %v", err)
+	}
+
+	expected := []string{neth.TopicERC20Transfer, neth.TopicERC20Approval, neth.TopicSafeExecutionSuccess}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, values)
+	}
+	for i := range values {
+		if values[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], values[i])
+		}
+	}
+
+	if _, err := TopicTagValues("not_a_topic"); err == nil {
+		t.Error("Expected an unknown topic to fail")
+	}
+}
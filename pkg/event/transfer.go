@@ -1,8 +1,10 @@
 package event
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/comunifi/nostr-eth/pkg/neth"
 	"github.com/nbd-wtf/go-nostr"
@@ -10,7 +12,11 @@ import (
 
 // NostrEventType represents the type of Nostr event for transaction logs
 const (
-	KindTxTransfer = 9735
+	KindTxTransfer = 111013
+
+	// KindTxTransferLegacy is the kind transfers were published under before, it collides with
+	// NIP-57 zap receipts. Legacy transfer events are still parsed, see IsTxTransferEvent.
+	KindTxTransferLegacy = 9735
 
 	EventTypeTxTransferCreated EventTypeTxTransfer = "tx_transfer_created"
 )
@@ -22,19 +28,29 @@ type TxTransferEvent struct {
 	LogData   neth.Log            `json:"log_data"`
 	EventType EventTypeTxTransfer `json:"event_type"`
 	Tags      []string            `json:"tags,omitempty"`
+	Splits    []neth.Split        `json:"splits,omitempty"` // How the payment is split between recipients
 }
 
 // CreateTxTransferEvent creates a new Nostr event for a transfer
-func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
+func CreateTxTransferEvent(log neth.Log, opts ...LogOption) (*nostr.Event, error) {
+	options := newLogOptions(opts)
+
 	if log.Topic != neth.TopicERC20Transfer {
 		return nil, fmt.Errorf("topic is not an ERC20 transfer")
 	}
 
+	if len(options.splits) > 0 {
+		if err := neth.ValidateSplits(options.splits); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the event data
 	eventData := TxTransferEvent{
 		LogData:   log,
 		EventType: EventTypeTxTransferCreated,
 		Tags:      []string{"tx_transfer", "evm", log.ChainID},
+		Splits:    options.splits,
 	}
 
 	// Marshal the event data
@@ -47,7 +63,7 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
 		CreatedAt: nostr.Timestamp(log.CreatedAt.Unix()),
-		Kind:      KindTxTransfer, // Custom kind for transaction logs
+		Kind:      MappedKind(options.transferKind), // Custom kind for transfers
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -56,8 +72,8 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"d", log.Hash}) // Identifier
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "tx_transfer"}) // Type
-	evt.Tags = append(evt.Tags, []string{"network", "evm"})   // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("tx_transfer"))     // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
 
 	// Chain-specific tag
 	evt.Tags = append(evt.Tags, []string{"layer", log.ChainID}) // Chain ID
@@ -66,9 +82,11 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 	evt.Tags = append(evt.Tags, []string{"r", log.TxHash}) // Transaction hash as reference
 
 	if log.Data != nil {
+		decoder := json.NewDecoder(bytes.NewReader(*log.Data))
+		decoder.UseNumber()
+
 		var data map[string]interface{}
-		err := json.Unmarshal(*log.Data, &data)
-		if err != nil {
+		if err := decoder.Decode(&data); err != nil {
 			return nil, err
 		}
 
@@ -82,7 +100,11 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 			return nil, fmt.Errorf("to is not a string")
 		}
 
+		// Amounts written as JSON numbers are kept as written, without rounding
 		amount, ok := data[neth.DataKeyValue].(string)
+		if number, isNumber := data[neth.DataKeyValue].(json.Number); isNumber {
+			amount, ok = number.String(), true
+		}
 		if !ok {
 			return nil, fmt.Errorf("amount is not a string")
 		}
@@ -91,25 +113,85 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 		evt.Tags = append(evt.Tags, []string{"p", to})     // Recipient/Contract address
 
 		evt.Tags = append(evt.Tags, []string{"amount", amount}) // Amount
+
+		if options.sortableAmount {
+			value, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("amount is not a valid integer")
+			}
+
+			sortable, err := EncodeSortableAmount(value)
+			if err != nil {
+				return nil, err
+			}
+			evt.Tags = append(evt.Tags, []string{"amount_sortable", sortable})
+		}
+
+		if options.rateProvider != nil {
+			value, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("amount is not a valid integer")
+			}
+
+			tag, err := fiatTag(options.rateProvider, log, value)
+			if err != nil {
+				return nil, err
+			}
+			if tag != nil {
+				evt.Tags = append(evt.Tags, tag)
+			}
+		}
+
+		if options.tokenRegistry != nil {
+			if coin, ok := options.tokenRegistry.Stablecoin(log.ChainID, log.To); ok {
+				value, ok := new(big.Int).SetString(amount, 10)
+				if !ok {
+					return nil, fmt.Errorf("amount is not a valid integer")
+				}
+
+				// Currency of the peg and amount in whole tokens
+				evt.Tags = append(evt.Tags, []string{"currency", coin.Currency, neth.FormatUnits(value, coin.Decimals)})
+			}
+		}
 	}
 
+	// Split tags, one per recipient with its basis points
+	evt.Tags = append(evt.Tags, splitTags(options.splits)...)
+
 	// Topic tag
-	evt.Tags = append(evt.Tags, []string{"t", log.Topic})
+	evt.Tags = append(evt.Tags, typeTag(log.Topic))
 
 	// Contract address tag
-	evt.Tags = append(evt.Tags, []string{"t", log.To})
+	evt.Tags = append(evt.Tags, typeTag(log.To))
+
+	// Token list tags
+	if options.tokenRegistry != nil {
+		if options.tokenRegistry.IsSpamToken(log.ChainID, log.To) {
+			evt.Tags = append(evt.Tags, typeTag("spam"))
+		} else if options.tokenRegistry.IsKnownToken(log.ChainID, log.To) {
+			evt.Tags = append(evt.Tags, typeTag("verified"))
+		}
+		if _, ok := options.tokenRegistry.Stablecoin(log.ChainID, log.To); ok {
+			evt.Tags = append(evt.Tags, typeTag("stablecoin"))
+		}
+	}
+
+	// Dust tag
+	if options.dust != nil && options.dust.IsDust(log) {
+		evt.Tags = append(evt.Tags, typeTag("dust"))
+	}
 
 	// Flatten data into tags
 	dataTags := []nostr.Tag{}
 	if log.Data != nil {
-		dataTags = flattenDataToTags(*log.Data)
+		dataTags = flattenDataToTags(*log.Data, options.flattenPolicy, options.sanitizePolicy)
 		evt.Tags = append(evt.Tags, dataTags...)
 	}
 
 	// Alt tag
-	alt := fmt.Sprintf("This is an evm transaction log for topic %s on chain %s", log.Topic, log.ChainID)
+	alt := localize(MessageTxLogAlt, log.Topic, log.ChainID)
 	if len(dataTags) > 0 {
-		alt += "\n Data:"
+		alt += localize(MessageAltData)
 	}
 	for _, tag := range dataTags {
 		alt += fmt.Sprintf("\n %s: %s", tag[0], tag[1])
@@ -117,15 +199,68 @@ func CreateTxTransferEvent(log neth.Log) (*nostr.Event, error) {
 
 	evt.Tags = append(evt.Tags, []string{"alt", alt})
 
-	return evt, nil
+	return formatSanitizedAlt(evt, options.sanitizePolicy)
+}
+
+// IsTxTransferEvent checks if an event is a transfer event, including transfers published
+// under the legacy kind or a custom kind, which carry the tx_transfer type tag unlike zap
+// receipts
+func IsTxTransferEvent(evt *nostr.Event) bool {
+	if evt == nil {
+		return false
+	}
+	if DefaultKind(evt.Kind) == KindTxTransfer {
+		return true
+	}
+	if DefaultKind(evt.Kind) == KindTxLog {
+		return false
+	}
+
+	return HasTypeTag(evt, "tx_transfer")
+}
+
+// MigrateTxTransferEvent returns a copy of a legacy transfer event under KindTxTransfer, the
+// copy is unsigned and has to be signed and published again
+func MigrateTxTransferEvent(evt *nostr.Event) (*nostr.Event, error) {
+	if !IsTxTransferEvent(evt) {
+		return nil, fmt.Errorf("event is not a transfer event")
+	}
+
+	migrated := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: evt.CreatedAt,
+		Kind:      MappedKind(KindTxTransfer),
+		Tags:      make([]nostr.Tag, 0, len(evt.Tags)),
+		Content:   evt.Content,
+	}
+	for _, tag := range evt.Tags {
+		migrated.Tags = append(migrated.Tags, append(nostr.Tag(nil), tag...))
+	}
+
+	return migrated, nil
 }
 
 // ParseTxTransferEvent parses a Nostr event back into a TxTransferEvent
 func ParseTxTransferEvent(evt *nostr.Event) (*TxTransferEvent, error) {
 	var txTransferEvent TxTransferEvent
-	err := json.Unmarshal([]byte(evt.Content), &txTransferEvent)
+	err := unmarshalContent(evt, &txTransferEvent)
 	if err != nil {
 		return nil, err
 	}
 	return &txTransferEvent, nil
 }
+
+// Direction returns the direction of the transfer from the point of view of the given address
+func (t *TxTransferEvent) Direction(address string) neth.TransferDirection {
+	return t.LogData.Direction(address)
+}
+
+// Counterparty returns the other side of the transfer from the point of view of the given address
+func (t *TxTransferEvent) Counterparty(address string) string {
+	return t.LogData.Counterparty(address)
+}
+
+// FormatAmount formats the transferred amount as a decimal string using the token decimals
+func (t *TxTransferEvent) FormatAmount(decimals int64) string {
+	return t.LogData.FormatAmount(decimals)
+}
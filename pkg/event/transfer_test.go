@@ -0,0 +1,69 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTxTransferKinds(t *testing.T) {
+	evt, err := CreateTxTransferEvent(goldenLog())
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if evt.Kind != KindTxTransfer {
+		t.Errorf("Expected kind %d, got %d", KindTxTransfer, evt.Kind)
+	}
+
+	legacy, err := CreateTxTransferEvent(goldenLog(), WithTransferKind(KindTxTransferLegacy))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if legacy.Kind != KindTxTransferLegacy {
+		t.Errorf("Expected kind %d, got %d", KindTxTransferLegacy, legacy.Kind)
+	}
+
+	zapReceipt := &nostr.Event{
+		Kind:    KindTxTransferLegacy,
+		Tags:    nostr.Tags{{"p", goldenPubKey}, {"bolt11", "lnbc10u1p..."}},
+		Content: "",
+	}
+
+	for _, tc := range []struct {
+		name     string
+		evt      *nostr.Event
+		expected bool
+	}{
+		{"transfer", evt, true},
+		{"legacy transfer", legacy, true},
+		{"zap receipt", zapReceipt, false},
+		{"nil", nil, false},
+	} {
+		if got := IsTxTransferEvent(tc.evt); got != tc.expected {
+			t.Errorf("Expected IsTxTransferEvent of %s to be %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	migrated, err := MigrateTxTransferEvent(legacy)
+	if err != nil {
+		t.Fatalf("Failed to migrate event: %v", err)
+	}
+	if migrated.Kind != KindTxTransfer {
+		t.Errorf("Expected kind %d, got %d", KindTxTransfer, migrated.Kind)
+	}
+	if migrated.Content != legacy.Content || len(migrated.Tags) != len(legacy.Tags) {
+		t.Error("Expected the migrated event to keep the content and tags")
+	}
+
+	parsed, err := ParseTxTransferEvent(legacy)
+	if err != nil {
+		t.Fatalf("Failed to parse legacy event: %v", err)
+	}
+	if parsed.LogData.Hash != goldenLog().Hash {
+		t.Errorf("Expected hash %s, got %s", goldenLog().Hash, parsed.LogData.Hash)
+	}
+
+	if _, err := MigrateTxTransferEvent(zapReceipt); err == nil {
+		t.Error("Expected an error when migrating a zap receipt")
+	}
+}
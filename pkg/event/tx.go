@@ -0,0 +1,147 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NostrEventType represents the type of Nostr event for whole transactions
+const (
+	KindTx = 111004
+
+	EventTypeTxCreated EventTypeTx = "tx_created"
+)
+
+type EventTypeTx string
+
+// TxEvent represents a Nostr event summarizing a transaction and all of its logs
+type TxEvent struct {
+	TxHash    string      `json:"tx_hash"`
+	ChainID   string      `json:"chain_id"`
+	Logs      []neth.Log  `json:"logs"`
+	EventType EventTypeTx `json:"event_type"`
+	Tags      []string    `json:"tags,omitempty"`
+}
+
+// CreateTxEvent creates a new Nostr event for a transaction, nesting all of its logs
+func CreateTxEvent(txHash string, logs []neth.Log) (*nostr.Event, error) {
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("transaction %s has no logs", txHash)
+	}
+
+	chainID := logs[0].ChainID
+	createdAt := logs[0].CreatedAt
+	for _, log := range logs {
+		if !strings.EqualFold(log.TxHash, txHash) {
+			return nil, fmt.Errorf("log %s belongs to transaction %s, not %s", log.Hash, log.TxHash, txHash)
+		}
+		if log.ChainID != chainID {
+			return nil, fmt.Errorf("log %s is on chain %s, not %s", log.Hash, log.ChainID, chainID)
+		}
+		if log.CreatedAt.Before(createdAt) {
+			createdAt = log.CreatedAt
+		}
+	}
+
+	// Create the event data
+	eventData := TxEvent{
+		TxHash:    txHash,
+		ChainID:   chainID,
+		Logs:      logs,
+		EventType: EventTypeTxCreated,
+		Tags:      []string{"tx", "evm", chainID},
+	}
+
+	// Marshal the event data
+	content, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the Nostr event
+	evt := &nostr.Event{
+		PubKey:    "", // Will be derived from private key
+		CreatedAt: nostr.Timestamp(createdAt.Unix()),
+		Kind:      MappedKind(KindTx), // Custom kind for transactions
+		Tags:      make([]nostr.Tag, 0),
+		Content:   string(content),
+	}
+
+	// Add tags for better indexing and filtering
+	evt.Tags = append(evt.Tags, []string{"d", txHash}) // Identifier
+
+	// Type and category tags
+	evt.Tags = append(evt.Tags, typeTag("tx"))              // Type
+	evt.Tags = append(evt.Tags, []string{"network", "evm"}) // Blockchain
+
+	// Chain-specific tag
+	evt.Tags = append(evt.Tags, []string{"layer", chainID}) // Chain ID
+
+	// Reference tag for transaction hash
+	evt.Tags = append(evt.Tags, []string{"r", txHash}) // Transaction hash as reference
+
+	// Aggregated tags from all logs
+	totals := make(map[string]*big.Int)
+	tokens := []string{}
+	topics := []string{}
+	for _, log := range logs {
+		if log.Value != nil {
+			token := strings.ToLower(log.To)
+			if _, ok := totals[token]; !ok {
+				totals[token] = new(big.Int)
+				tokens = append(tokens, token)
+			}
+			totals[token].Add(totals[token], log.Value)
+		}
+
+		evt.Tags = appendUniqueTags(evt.Tags, []string{"P", log.Sender}) // Sender address
+		evt.Tags = appendUniqueTags(evt.Tags, []string{"p", log.To})     // Contract address
+		evt.Tags = appendUniqueTags(evt.Tags, typeTag(log.Topic))        // Topic
+		evt.Tags = appendUniqueTags(evt.Tags, typeTag(log.To))           // Contract address
+
+		if log.Data != nil {
+			for _, tag := range flattenDataToTags(*log.Data, CurrentFlattenPolicy(), CurrentSanitizePolicy()) {
+				if tag[0] == "p" {
+					evt.Tags = appendUniqueTags(evt.Tags, tag) // Addresses from the log data
+				}
+			}
+		}
+
+		topics = append(topics, log.Topic)
+	}
+
+	// Amount tags with the sum of the log values of each token, values of different tokens
+	// cannot be added up
+	for _, token := range tokens {
+		evt.Tags = append(evt.Tags, []string{"amount", totals[token].String(), token})
+	}
+
+	// Number of logs
+	evt.Tags = append(evt.Tags, []string{"logs", fmt.Sprintf("%d", len(logs))})
+
+	// Alt tag
+	alt := fmt.Sprintf("This is an evm transaction with %d logs on chain %s", len(logs), chainID)
+	alt += "\n Topics:"
+	for _, topic := range topics {
+		alt += fmt.Sprintf("\n %s", topic)
+	}
+
+	evt.Tags = append(evt.Tags, []string{"alt", alt})
+
+	return evt, nil
+}
+
+// ParseTxEvent parses a Nostr event back into a TxEvent
+func ParseTxEvent(evt *nostr.Event) (*TxEvent, error) {
+	var txEvent TxEvent
+	err := unmarshalContent(evt, &txEvent)
+	if err != nil {
+		return nil, err
+	}
+	return &txEvent, nil
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestTxEvent(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	usdc := "0xDDAfbb505ad214D7b80b1f830fcCc89B60fb7A83"
+	weth := "0x6A023CCd1ff6F2045C3309768eAd9E68F978f6e1"
+
+	newLog := func(hash, token string, value int64, createdAt time.Time) neth.Log {
+		return neth.Log{
+			Hash:      hash,
+			TxHash:    "0xabc",
+			ChainID:   "100",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: createdAt,
+			Sender:    "0x1111111111111111111111111111111111111111",
+			To:        token,
+			Value:     big.NewInt(value),
+		}
+	}
+
+	// A swap: two transfers of USDC and one of WETH
+	logs := []neth.Log{
+		newLog("0x01", usdc, 100, at.Add(time.Second)),
+		newLog("0x02", weth, 5, at),
+		newLog("0x03", usdc, 50, at),
+	}
+
+	evt, err := CreateTxEvent("0xabc", logs)
+	if err != nil {
+		t.Fatalf("Failed to create tx event: %v", err)
+	}
+	if evt.Kind != KindTx || evt.Tags.GetD() != "0xabc" || evt.CreatedAt != nostr.Timestamp(at.Unix()) {
+		t.Errorf("Expected a tx event of 0xabc at the first log, got kind %d, d %s at %d", evt.Kind, evt.Tags.GetD(), evt.CreatedAt)
+	}
+
+	var amounts []nostr.Tag
+	for _, tag := range evt.Tags {
+		if tag[0] == "amount" {
+			amounts = append(amounts, tag)
+		}
+	}
+	expected := []nostr.Tag{{"amount", "150", "0xddafbb505ad214d7b80b1f830fccc89b60fb7a83"}, {"amount", "5", "0x6a023ccd1ff6f2045c3309768ead9e68f978f6e1"}}
+	if len(amounts) != len(expected) {
+		t.Fatalf("Expected %d amount tags, got %v", len(expected), amounts)
+	}
+	for i := range expected {
+		if fmt.Sprint(amounts[i]) != fmt.Sprint(expected[i]) {
+			t.Errorf("Expected amount tag %v, got %v", expected[i], amounts[i])
+		}
+	}
+	if tag := evt.Tags.GetFirst([]string{"logs", "3"}); tag == nil {
+		t.Error("Expected the number of logs to be tagged")
+	}
+
+	parsed, err := ParseTxEvent(evt)
+	if err != nil {
+		t.Fatalf("Failed to parse tx event: %v", err)
+	}
+	if parsed.TxHash != "0xabc" || parsed.ChainID != "100" || len(parsed.Logs) != 3 || parsed.Logs[1].Value.Int64() != 5 {
+		t.Errorf("Expected the transaction back, got %+v", parsed)
+	}
+
+	if _, err := CreateTxEvent("0xabc", nil); err == nil {
+		t.Error("Expected a transaction without logs to fail")
+	}
+	if _, err := CreateTxEvent("0xdef", logs); err == nil {
+		t.Error("Expected logs of another transaction to fail")
+	}
+	other := newLog("0x04", usdc, 1, at)
+	other.ChainID = "1"
+	if _, err := CreateTxEvent("0xabc", append(logs, other)); err == nil {
+		t.Error("Expected logs of another chain to fail")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"reflect"
 
 	"github.com/comunifi/nostr-eth/pkg/neth"
 	"github.com/ethereum/go-ethereum/common"
@@ -34,9 +35,16 @@ type UserOpEvent struct {
 	TxHash     *string          `json:"tx_hash,omitempty"`
 	EventType  EventTypeUserOp  `json:"event_type"`
 	RetryCount int              `json:"retry_count,omitempty"`
+	Failure    *FailureReason   `json:"failure_reason,omitempty"`
 	Tags       []string         `json:"tags,omitempty"`
+
+	// RawContent holds the original content when the event was parsed, fields unknown to
+	// this version of the package are merged back when the event is marshaled again
+	RawContent json.RawMessage `json:"-"`
 }
 
+var userOpEventFields = jsonFieldNames(reflect.TypeOf(UserOpEvent{}))
+
 func (u *UserOpEvent) MarshalJSON() ([]byte, error) {
 	// Marshal UserOpData using its custom marshaling
 	userOpDataBytes, err := json.Marshal(&u.UserOpData)
@@ -45,8 +53,7 @@ func (u *UserOpEvent) MarshalJSON() ([]byte, error) {
 	}
 
 	// Create a temporary struct that embeds all fields but overrides UserOpData
-	type Alias UserOpEvent
-	return json.Marshal(&struct {
+	content, err := json.Marshal(&struct {
 		UserOpData json.RawMessage  `json:"user_op_data"`
 		Paymaster  *common.Address  `json:"paymaster,omitempty"`
 		EntryPoint *common.Address  `json:"entry_point,omitempty"`
@@ -54,6 +61,7 @@ func (u *UserOpEvent) MarshalJSON() ([]byte, error) {
 		TxHash     *string          `json:"tx_hash,omitempty"`
 		EventType  EventTypeUserOp  `json:"event_type"`
 		RetryCount int              `json:"retry_count,omitempty"`
+		Failure    *FailureReason   `json:"failure_reason,omitempty"`
 		Tags       []string         `json:"tags,omitempty"`
 	}{
 		UserOpData: userOpDataBytes,
@@ -63,8 +71,14 @@ func (u *UserOpEvent) MarshalJSON() ([]byte, error) {
 		TxHash:     u.TxHash,
 		EventType:  u.EventType,
 		RetryCount: u.RetryCount,
+		Failure:    u.Failure,
 		Tags:       u.Tags,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeUnknownFields(content, u.RawContent, userOpEventFields)
 }
 
 func (u *UserOpEvent) UnmarshalJSON(data []byte) error {
@@ -77,6 +91,7 @@ func (u *UserOpEvent) UnmarshalJSON(data []byte) error {
 		TxHash     *string          `json:"tx_hash,omitempty"`
 		EventType  EventTypeUserOp  `json:"event_type"`
 		RetryCount int              `json:"retry_count,omitempty"`
+		Failure    *FailureReason   `json:"failure_reason,omitempty"`
 		Tags       []string         `json:"tags,omitempty"`
 	}{}
 
@@ -96,6 +111,7 @@ func (u *UserOpEvent) UnmarshalJSON(data []byte) error {
 	u.TxHash = aux.TxHash
 	u.EventType = aux.EventType
 	u.RetryCount = aux.RetryCount
+	u.Failure = aux.Failure
 	u.Tags = aux.Tags
 
 	return nil
@@ -124,8 +140,8 @@ func CreateUserOpEvent(chainID *big.Int, paymaster, entryPoint *common.Address,
 	// Create the Nostr event
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Now(),
-		Kind:      EventUserOpKind, // Custom kind for user operations
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(EventUserOpKind), // Custom kind for user operations
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -134,11 +150,11 @@ func CreateUserOpEvent(chainID *big.Int, paymaster, entryPoint *common.Address,
 	evt.Tags = append(evt.Tags, []string{"d", userOp.GetHash(chainID)}) // Identifier using sender address
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "user_op"})             // Type
-	evt.Tags = append(evt.Tags, []string{"t", "user_op_0_0_6"})       // Version
-	evt.Tags = append(evt.Tags, []string{"network", "evm"})           // Blockchain
-	evt.Tags = append(evt.Tags, []string{"t", "account_abstraction"}) // AA specific
-	evt.Tags = append(evt.Tags, []string{"t", string(eventType)})     // Event type
+	evt.Tags = append(evt.Tags, typeTag("user_op"))             // Type
+	evt.Tags = append(evt.Tags, typeTag("user_op_0_0_6"))       // Version
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})     // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction")) // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(eventType)))     // Event type
 
 	// Chain-specific tag
 	evt.Tags = append(evt.Tags, []string{"layer", chainID.String()}) // Chain ID
@@ -151,33 +167,47 @@ func CreateUserOpEvent(chainID *big.Int, paymaster, entryPoint *common.Address,
 	// Entry point tag if present
 	if entryPoint != nil {
 		evt.Tags = append(evt.Tags, []string{"entry_point", entryPoint.Hex()})
+		evt.Tags = append(evt.Tags, []string{"r", userOp.GetUserOpHash(*entryPoint, chainID).Hex()}) // ERC-4337 user op hash
 	}
 
 	// Tx hash tag if present
 	if txHash != nil {
-		evt.Tags = append(evt.Tags, []string{"t", *txHash})
+		evt.Tags = append(evt.Tags, typeTag(*txHash))
 	}
 
 	// Sender address tag
 	evt.Tags = append(evt.Tags, []string{"p", userOp.Sender.String()}) // Sender address
 
+	// Account deployment tags
+	evt.Tags = append(evt.Tags, deploymentTags(userOp)...)
+
 	// Nonce tag for ordering
 	evt.Tags = append(evt.Tags, []string{"nonce", userOp.Nonce.String()})
 
 	// Alt tag
-	alt := fmt.Sprintf("This is a new user operation request on chain %s", chainID.String())
+	alt := localize(MessageUserOpAlt, chainID.String())
 	if paymaster != nil {
-		alt += fmt.Sprintf("\n this is intended for processing by paymaster: %s", paymaster.Hex())
+		alt += localize(MessageUserOpAltPaymaster, paymaster.Hex())
 	}
 
 	evt.Tags = append(evt.Tags, []string{"alt", alt})
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // UpdateUserOpEvent creates a Nostr event for updating a user operation status
 func UpdateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, retryCount int, eventType EventTypeUserOp, event *nostr.Event) (*nostr.Event, error) {
+	return updateUserOpEvent(chainID, userOp, txHash, retryCount, eventType, nil, event)
+}
+
+// FailUserOpEvent creates a Nostr event marking a user operation as failed for the given reason
+func FailUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, retryCount int, reason FailureReason, event *nostr.Event) (*nostr.Event, error) {
+	return updateUserOpEvent(chainID, userOp, txHash, retryCount, EventTypeUserOpFailed, &reason, event)
+}
 
+// updateUserOpEvent creates a Nostr event for updating a user operation status, with an
+// optional failure reason
+func updateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, retryCount int, eventType EventTypeUserOp, failure *FailureReason, event *nostr.Event) (*nostr.Event, error) {
 	userOpEvent, err := ParseUserOpEvent(event)
 	if err != nil {
 		return nil, err
@@ -192,7 +222,9 @@ func UpdateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, ret
 		TxHash:     txHash,
 		EventType:  eventType,
 		RetryCount: retryCount,
+		Failure:    failure,
 		Tags:       []string{"user_op", "user_op_0_0_6", "evm", chainID.String(), "account_abstraction", "update"},
+		RawContent: userOpEvent.RawContent, // Carry over fields unknown to this version
 	}
 
 	// Marshal the event data using the custom marshaling
@@ -204,8 +236,8 @@ func UpdateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, ret
 	// Create the Nostr event
 	evt := &nostr.Event{
 		PubKey:    "", // Will be derived from private key
-		CreatedAt: nostr.Now(),
-		Kind:      EventUserOpKind, // Custom kind for user operations
+		CreatedAt: nostr.Timestamp(timeNow().Unix()),
+		Kind:      MappedKind(EventUserOpKind), // Custom kind for user operations
 		Tags:      make([]nostr.Tag, 0),
 		Content:   string(content),
 	}
@@ -214,11 +246,11 @@ func UpdateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, ret
 	evt.Tags = append(evt.Tags, []string{"d", userOp.GetHash(chainID)}) // Identifier using sender address
 
 	// Type and category tags
-	evt.Tags = append(evt.Tags, []string{"t", "user_op"})             // Type
-	evt.Tags = append(evt.Tags, []string{"t", "user_op_0_0_6"})       // Version
-	evt.Tags = append(evt.Tags, []string{"network", "evm"})           // Blockchain
-	evt.Tags = append(evt.Tags, []string{"t", "account_abstraction"}) // AA specific
-	evt.Tags = append(evt.Tags, []string{"t", string(eventType)})     // Event type
+	evt.Tags = append(evt.Tags, typeTag("user_op"))             // Type
+	evt.Tags = append(evt.Tags, typeTag("user_op_0_0_6"))       // Version
+	evt.Tags = append(evt.Tags, []string{"network", "evm"})     // Blockchain
+	evt.Tags = append(evt.Tags, typeTag("account_abstraction")) // AA specific
+	evt.Tags = append(evt.Tags, typeTag(string(eventType)))     // Event type
 
 	// Chain-specific tag
 	evt.Tags = append(evt.Tags, []string{"layer", chainID.String()}) // Chain ID
@@ -231,40 +263,70 @@ func UpdateUserOpEvent(chainID *big.Int, userOp neth.UserOp, txHash *string, ret
 	// Entry point tag if present
 	if userOpEvent.EntryPoint != nil {
 		evt.Tags = append(evt.Tags, []string{"entry_point", userOpEvent.EntryPoint.Hex()})
+		evt.Tags = append(evt.Tags, []string{"r", userOp.GetUserOpHash(*userOpEvent.EntryPoint, chainID).Hex()}) // ERC-4337 user op hash
 	}
 
 	// Tx hash tag if present
 	if userOpEvent.TxHash != nil {
-		evt.Tags = append(evt.Tags, []string{"t", *userOpEvent.TxHash})
+		evt.Tags = append(evt.Tags, typeTag(*userOpEvent.TxHash))
 	}
 
 	// Sender address tag
 	evt.Tags = append(evt.Tags, []string{"p", userOp.Sender.String()}) // Sender address
 
+	// Account deployment tags
+	evt.Tags = append(evt.Tags, deploymentTags(userOp)...)
+
 	// Nonce tag for ordering
 	evt.Tags = append(evt.Tags, []string{"nonce", userOp.Nonce.String()})
 
+	// Retry count tag
+	evt.Tags = append(evt.Tags, []string{"retry_count", fmt.Sprintf("%d", retryCount)})
+
+	// Failure tags if present
+	if failure != nil {
+		evt.Tags = append(evt.Tags, failure.tags()...)
+	}
+
 	// Alt tag
-	alt := fmt.Sprintf("This is a user operation update of type %s on chain %s", eventType, chainID.String())
+	alt := localize(MessageUserOpUpdateAlt, eventType, chainID.String())
+	if failure != nil {
+		alt += localize(MessageUserOpAltFailure, failure.String())
+	}
 	if userOpEvent.Paymaster != nil {
-		alt += fmt.Sprintf("\n this is intended for processing by paymaster: %s", userOpEvent.Paymaster.Hex())
+		alt += localize(MessageUserOpAltPaymaster, userOpEvent.Paymaster.Hex())
 	}
 
 	evt.Tags = append(evt.Tags, []string{"alt", alt})
 
-	return evt, nil
+	return formatAlt(evt)
 }
 
 // ParseUserOpEvent parses a Nostr event back into a UserOpEvent
 func ParseUserOpEvent(evt *nostr.Event) (*UserOpEvent, error) {
 	var userOpEvent UserOpEvent
-	err := json.Unmarshal([]byte(evt.Content), &userOpEvent)
+	err := unmarshalContent(evt, &userOpEvent)
 	if err != nil {
 		return nil, err
 	}
+	userOpEvent.RawContent = json.RawMessage(evt.Content)
 	return &userOpEvent, nil
 }
 
+// deploymentTags returns the tags of a user operation that deploys its sender account
+func deploymentTags(userOp neth.UserOp) []nostr.Tag {
+	factory, ok := userOp.GetFactory()
+	if !ok {
+		return nil
+	}
+
+	return []nostr.Tag{
+		typeTag("deploys_account"),
+		{"deploys_account", userOp.Sender.String()}, // Counterfactual address of the account
+		{"factory", factory.Hex()},
+	}
+}
+
 // isKnownFunctionSignature checks if the function signature is one of the known ones
 func isKnownFunctionSignature(sig []byte) bool {
 	knownSigs := [][]byte{
@@ -0,0 +1,106 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// FailureReason describes why a user operation failed
+type FailureReason struct {
+	Code       string        `json:"code,omitempty"`        // EntryPoint error code, e.g. "AA21"
+	Message    string        `json:"message,omitempty"`     // Message returned by the bundler
+	RevertData hexutil.Bytes `json:"revert_data,omitempty"` // Revert data of the failed call
+}
+
+var entryPointErrorCode = regexp.MustCompile(`\bAA[0-9]{2}\b`)
+
+// retryableErrorCodes are the EntryPoint errors that may succeed when the user operation is
+// submitted again unchanged, once funds or time have caught up
+var retryableErrorCodes = map[string]bool{
+	"AA21": true, // didn't pay prefund
+	"AA22": true, // expired or not due
+	"AA31": true, // paymaster deposit too low
+	"AA32": true, // paymaster expired or not due
+	"AA51": true, // prefund below actualGasCost
+}
+
+// NewFailureReason creates a failure reason from a bundler message and revert data, the
+// EntryPoint error code is extracted from the message when present
+func NewFailureReason(message string, revertData []byte) FailureReason {
+	return FailureReason{
+		Code:       entryPointErrorCode.FindString(message),
+		Message:    message,
+		RevertData: revertData,
+	}
+}
+
+// IsRetryable checks if submitting the user operation again may succeed. Known transient
+// EntryPoint errors are retryable, other EntryPoint errors and reverts are terminal, and
+// failures without a code or revert data (e.g. network errors) are retryable.
+func (f *FailureReason) IsRetryable() bool {
+	if f.Code != "" {
+		return retryableErrorCodes[f.Code]
+	}
+
+	return len(f.RevertData) == 0
+}
+
+// String returns a human-readable description of the failure
+func (f *FailureReason) String() string {
+	kind := "terminal"
+	if f.IsRetryable() {
+		kind = "retryable"
+	}
+
+	if f.Code != "" {
+		return fmt.Sprintf("%s (%s): %s", f.Code, kind, f.Message)
+	}
+
+	return fmt.Sprintf("(%s): %s", kind, f.Message)
+}
+
+// tags returns the tags describing the failure
+func (f *FailureReason) tags() []nostr.Tag {
+	tags := []nostr.Tag{}
+
+	if f.Code != "" {
+		tags = append(tags, nostr.Tag{"failure_code", f.Code})
+	}
+
+	if f.IsRetryable() {
+		tags = append(tags, typeTag("retryable"))
+	} else {
+		tags = append(tags, typeTag("terminal"))
+	}
+
+	return tags
+}
+
+// userOpTransitions are the allowed status changes of a user operation
+var userOpTransitions = map[EventTypeUserOp][]EventTypeUserOp{
+	EventTypeUserOpRequested: {EventTypeUserOpSigned, EventTypeUserOpSubmitted, EventTypeUserOpFailed, EventTypeUserOpExpired},
+	EventTypeUserOpSigned:    {EventTypeUserOpSubmitted, EventTypeUserOpFailed, EventTypeUserOpExpired},
+	EventTypeUserOpSubmitted: {EventTypeUserOpExecuted, EventTypeUserOpFailed, EventTypeUserOpExpired},
+	EventTypeUserOpExecuted:  {EventTypeUserOpConfirmed, EventTypeUserOpFailed},
+	EventTypeUserOpFailed:    {EventTypeUserOpSubmitted, EventTypeUserOpExpired},
+}
+
+// CanTransitionUserOp checks if a user operation can move from the status of an event to
+// another status. A failed user operation can only be submitted again when its failure is
+// retryable, confirmed and expired user operations are final.
+func CanTransitionUserOp(from *UserOpEvent, to EventTypeUserOp) bool {
+	if from.EventType == EventTypeUserOpFailed && to == EventTypeUserOpSubmitted {
+		return from.Failure == nil || from.Failure.IsRetryable()
+	}
+
+	for _, next := range userOpTransitions[from.EventType] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFailureReasonIsRetryable(t *testing.T) {
+	testCases := []struct {
+		message    string
+		revertData []byte
+		code       string
+		retryable  bool
+	}{
+		{"FailedOp(0, AA21 didn't pay prefund)", nil, "AA21", true},
+		{"FailedOp(0, AA24 signature error)", nil, "AA24", false},
+		{"execution reverted", []byte{0x08, 0xc3, 0x79, 0xa0}, "", false},
+		{"connection refused", nil, "", true},
+	}
+
+	for _, tc := range testCases {
+		reason := NewFailureReason(tc.message, tc.revertData)
+		if reason.Code != tc.code {
+			t.Errorf("Expected code %q for %q, got %q", tc.code, tc.message, reason.Code)
+		}
+		if reason.IsRetryable() != tc.retryable {
+			t.Errorf("Expected retryable %t for %q, got %t", tc.retryable, tc.message, reason.IsRetryable())
+		}
+	}
+}
+
+func TestFailUserOpEvent(t *testing.T) {
+	chainID := big.NewInt(1)
+	userOp := neth.UserOp{
+		Sender:               common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(0),
+		VerificationGasLimit: big.NewInt(0),
+		PreVerificationGas:   big.NewInt(0),
+		MaxFeePerGas:         big.NewInt(0),
+		MaxPriorityFeePerGas: big.NewInt(0),
+	}
+
+	submitted, err := CreateUserOpEvent(chainID, nil, nil, nil, nil, 0, userOp, EventTypeUserOpSubmitted)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+
+	failed, err := FailUserOpEvent(chainID, userOp, nil, 0, NewFailureReason("FailedOp(0, AA25 invalid account nonce)", nil), submitted)
+	if err != nil {
+		t.Fatalf("Failed to create failed user op event: %v", err)
+	}
+
+	userOpEvent, err := ParseUserOpEvent(failed)
+	if err != nil {
+		t.Fatalf("Failed to parse user op event: %v", err)
+	}
+
+	if userOpEvent.Failure == nil || userOpEvent.Failure.Code != "AA25" {
+		t.Fatalf("Expected failure code AA25, got %+v", userOpEvent.Failure)
+	}
+
+	if CanTransitionUserOp(userOpEvent, EventTypeUserOpSubmitted) {
+		t.Errorf("Expected terminal failure not to allow resubmission")
+	}
+	if !CanTransitionUserOp(userOpEvent, EventTypeUserOpExpired) {
+		t.Errorf("Expected failed user op to be allowed to expire")
+	}
+}
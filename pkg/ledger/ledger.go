@@ -0,0 +1,292 @@
+// Package ledger projects transfer events into double-entry journal entries and per-account
+// balances, so that treasuries can be accounted for from Nostr history alone and checked
+// against the chain
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Account is the holding of a token by an address on a chain
+type Account struct {
+	ChainID string `json:"chain_id"`
+	Token   string `json:"token"`
+	Address string `json:"address"`
+}
+
+// newAccount returns an account with lowercase addresses, so that it can be used as a key
+func newAccount(chainID, token, address string) Account {
+	return Account{ChainID: chainID, Token: strings.ToLower(token), Address: strings.ToLower(address)}
+}
+
+// String returns the account as chain:token:address
+func (a Account) String() string {
+	return a.ChainID + ":" + a.Token + ":" + a.Address
+}
+
+// Posting is a line of a journal entry, debits increase the balance of the account and are
+// positive, credits decrease it and are negative
+type Posting struct {
+	Account Account  `json:"account"`
+	Amount  *big.Int `json:"amount"`
+}
+
+// JournalEntry records a transfer, or the reversal of a transfer whose log was orphaned. The
+// amounts of its postings sum to zero.
+type JournalEntry struct {
+	ID       string    `json:"id"`       // Chain ID and log hash of the transfer
+	EventID  string    `json:"event_id"` // Event the entry was projected from
+	TxHash   string    `json:"tx_hash"`
+	Time     time.Time `json:"time"`
+	Reversal bool      `json:"reversal,omitempty"`
+	Postings []Posting `json:"postings"`
+}
+
+// AccountBalance is the balance of an account
+type AccountBalance struct {
+	Account Account  `json:"account"`
+	Balance *big.Int `json:"balance"`
+}
+
+// Ledger projects transfer and tx log events into journal entries and balances. Events can be
+// applied in any order and more than once, each transfer is recorded once. Mints and burns
+// leave the zero address with a negative balance, the supply seen by the ledger.
+type Ledger struct {
+	mu       sync.RWMutex
+	balances map[Account]*big.Int
+	entries  []JournalEntry
+	applied  map[string]bool // Transfers with a standing entry, by entry ID
+}
+
+// New creates an empty ledger
+func New() *Ledger {
+	return &Ledger{
+		balances: make(map[Account]*big.Int),
+		applied:  make(map[string]bool),
+	}
+}
+
+// Apply records the transfer of an event and returns the journal entry, nil when the event is not
+// an ERC20 transfer or its transfer is already recorded. Orphaned tx log events reverse the entry
+// of their transfer.
+func (l *Ledger) Apply(evt *nostr.Event) (*JournalEntry, error) {
+	log, orphaned, err := transferLog(evt)
+	if err != nil || log == nil {
+		return nil, err
+	}
+
+	data, err := log.GetTransferData()
+	if err != nil || data == nil {
+		return nil, nil // e.g. an ERC721 transfer, same topic without an amount
+	}
+	amount, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid transfer amount: %s", data.Value)
+	}
+
+	id := log.ChainID + ":" + strings.ToLower(log.Hash)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.applied[id] != orphaned {
+		// Already recorded, or orphaned without a standing entry
+		return nil, nil
+	}
+
+	from := newAccount(log.ChainID, log.To, data.From)
+	to := newAccount(log.ChainID, log.To, data.To)
+
+	entry := JournalEntry{
+		ID:       id,
+		EventID:  evt.ID,
+		TxHash:   log.TxHash,
+		Time:     log.CreatedAt,
+		Reversal: orphaned,
+		Postings: []Posting{
+			{Account: to, Amount: new(big.Int).Set(amount)},
+			{Account: from, Amount: new(big.Int).Neg(amount)},
+		},
+	}
+	if orphaned {
+		for _, posting := range entry.Postings {
+			posting.Amount.Neg(posting.Amount)
+		}
+	}
+
+	l.post(entry)
+	l.applied[id] = !orphaned
+
+	return &entry, nil
+}
+
+// ApplyAll applies events from the oldest, so that orphaned logs come after their transfer
+func (l *Ledger) ApplyAll(events []*nostr.Event) error {
+	sorted := append([]*nostr.Event(nil), events...)
+	event.SortEventsByCreatedAt(sorted, false)
+
+	for _, evt := range sorted {
+		if _, err := l.Apply(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post adds an entry to the journal and its postings to the balances
+func (l *Ledger) post(entry JournalEntry) {
+	for _, posting := range entry.Postings {
+		balance, ok := l.balances[posting.Account]
+		if !ok {
+			balance = new(big.Int)
+			l.balances[posting.Account] = balance
+		}
+		balance.Add(balance, posting.Amount)
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// transferLog returns the log of a transfer or tx log event and if it was orphaned, nil when the
+// event is not an ERC20 transfer
+func transferLog(evt *nostr.Event) (*neth.Log, bool, error) {
+	var log neth.Log
+	orphaned := false
+
+	switch {
+	case event.IsTxTransferEvent(evt):
+		transfer, err := event.ParseTxTransferEvent(evt)
+		if err != nil {
+			return nil, false, err
+		}
+		log = transfer.LogData
+	case event.DefaultKind(evt.Kind) == event.KindTxLog:
+		txLog, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			return nil, false, err
+		}
+		log = txLog.LogData
+		orphaned = txLog.Status == event.TxLogStatusOrphaned
+	default:
+		return nil, false, nil
+	}
+
+	if !strings.EqualFold(log.Topic, neth.TopicERC20Transfer) {
+		return nil, false, nil
+	}
+	return &log, orphaned, nil
+}
+
+// Balance returns the balance of an address in a token
+func (l *Ledger) Balance(chainID, token, address string) *big.Int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if balance, ok := l.balances[newAccount(chainID, token, address)]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return new(big.Int)
+}
+
+// Balances returns the balances of every account, ordered by account
+func (l *Ledger) Balances() []AccountBalance {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	balances := make([]AccountBalance, 0, len(l.balances))
+	for account, balance := range l.balances {
+		balances = append(balances, AccountBalance{Account: account, Balance: new(big.Int).Set(balance)})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Account.String() < balances[j].Account.String()
+	})
+	return balances
+}
+
+// Entries returns the journal in the order the entries were recorded
+func (l *Ledger) Entries() []JournalEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]JournalEntry(nil), l.entries...)
+}
+
+// Snapshot is the state of a ledger, it can be stored as JSON, e.g. in the data of a
+// state.Checkpoint, and restored to resume the projection
+type Snapshot struct {
+	Entries []JournalEntry `json:"entries"`
+	TakenAt time.Time      `json:"taken_at"`
+}
+
+// Snapshot returns the state of the ledger
+func (l *Ledger) Snapshot() Snapshot {
+	return Snapshot{Entries: l.Entries(), TakenAt: time.Now()}
+}
+
+// Restore creates a ledger from a snapshot, replaying its journal. It fails when an entry does
+// not balance.
+func Restore(snapshot Snapshot) (*Ledger, error) {
+	l := New()
+	for _, entry := range snapshot.Entries {
+		sum := new(big.Int)
+		for _, posting := range entry.Postings {
+			if posting.Amount == nil {
+				return nil, fmt.Errorf("entry %s has a posting without amount", entry.ID)
+			}
+			sum.Add(sum, posting.Amount)
+		}
+		if sum.Sign() != 0 {
+			return nil, fmt.Errorf("entry %s does not balance", entry.ID)
+		}
+
+		l.post(entry)
+		l.applied[entry.ID] = !entry.Reversal
+	}
+	return l, nil
+}
+
+// Discrepancy is an account whose balance in the ledger differs from the chain
+type Discrepancy struct {
+	Account Account  `json:"account"`
+	Ledger  *big.Int `json:"ledger"`
+	Chain   *big.Int `json:"chain"`
+}
+
+// Difference returns the chain balance minus the ledger balance, positive when transfers are
+// missing from the ledger
+func (d Discrepancy) Difference() *big.Int {
+	return new(big.Int).Sub(d.Chain, d.Ledger)
+}
+
+// Reconcile compares the balances of accounts with the chain, read with the token reader of their
+// chain, and returns the accounts that differ. Accounts of chains without a reader fail.
+func (l *Ledger) Reconcile(ctx context.Context, readers map[string]neth.TokenReader, accounts []Account) ([]Discrepancy, error) {
+	var discrepancies []Discrepancy
+	for _, account := range accounts {
+		reader, ok := readers[account.ChainID]
+		if !ok {
+			return nil, fmt.Errorf("no token reader for chain %s", account.ChainID)
+		}
+
+		chain, err := reader.BalanceOf(ctx, common.HexToAddress(account.Token), common.HexToAddress(account.Address))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the balance of %s: %w", account, err)
+		}
+
+		ledger := l.Balance(account.ChainID, account.Token, account.Address)
+		if ledger.Cmp(chain) != 0 {
+			discrepancies = append(discrepancies, Discrepancy{Account: account, Ledger: ledger, Chain: chain})
+		}
+	}
+	return discrepancies, nil
+}
@@ -0,0 +1,129 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	token    = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	treasury = "0x1111111111111111111111111111111111111111"
+	member   = "0x2222222222222222222222222222222222222222"
+	zero     = "0x0000000000000000000000000000000000000000"
+)
+
+func testTransfer(hash, from, to string, value int64) neth.Log {
+	data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"%d"}`, from, to, value))
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+// staticReader returns fixed balances
+type staticReader map[string]*big.Int
+
+func (r staticReader) BalanceOf(ctx context.Context, contract, owner common.Address) (*big.Int, error) {
+	if balance, ok := r[strings.ToLower(owner.Hex())]; ok {
+		return balance, nil
+	}
+	return new(big.Int), nil
+}
+
+func (r staticReader) OwnerOf(ctx context.Context, contract common.Address, tokenID *big.Int) (common.Address, error) {
+	return common.Address{}, nil
+}
+
+func TestLedgerProjection(t *testing.T) {
+	mint, _ := event.CreateTxTransferEvent(testTransfer("0x01", zero, treasury, 1000))
+	grant, _ := event.CreateTxTransferEvent(testTransfer("0x02", treasury, member, 300))
+	grantLog, _ := event.CreateTxLogEvent(testTransfer("0x02", treasury, member, 300), event.WithBlockNumber(5))
+	refund, _ := event.CreateTxLogEvent(testTransfer("0x03", member, treasury, 50), event.WithBlockNumber(6))
+	orphaned, err := event.UpdateTxLogStatus(refund, event.TxLogStatusOrphaned)
+	if err != nil {
+		t.Fatalf("Failed to orphan log: %v", err)
+	}
+	orphaned.CreatedAt = refund.CreatedAt + 1
+
+	l := New()
+	if err := l.ApplyAll([]*nostr.Event{mint, grant, grantLog, refund, orphaned}); err != nil {
+		t.Fatalf("Failed to apply events: %v", err)
+	}
+
+	if balance := l.Balance("100", token, treasury); balance.Int64() != 700 {
+		t.Errorf("Expected treasury balance 700, got %s", balance)
+	}
+	if balance := l.Balance("100", strings.ToLower(token), member); balance.Int64() != 300 {
+		t.Errorf("Expected member balance 300, got %s", balance)
+	}
+	if balance := l.Balance("100", token, zero); balance.Int64() != -1000 {
+		t.Errorf("Expected the zero address at -1000, got %s", balance)
+	}
+
+	// The duplicated grant is recorded once, the orphaned refund is reversed
+	entries := l.Entries()
+	if len(entries) != 4 || !entries[3].Reversal {
+		t.Fatalf("Expected 4 entries ending with a reversal, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		sum := new(big.Int)
+		for _, posting := range entry.Postings {
+			sum.Add(sum, posting.Amount)
+		}
+		if sum.Sign() != 0 {
+			t.Errorf("Expected entry %s to balance, got %s", entry.ID, sum)
+		}
+	}
+
+	// Snapshot and restore through JSON
+	data, err := json.Marshal(l.Snapshot())
+	if err != nil {
+		t.Fatalf("Failed to marshal snapshot: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("Failed to unmarshal snapshot: %v", err)
+	}
+	restored, err := Restore(snapshot)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if balance := restored.Balance("100", token, treasury); balance.Int64() != 700 {
+		t.Errorf("Expected restored treasury balance 700, got %s", balance)
+	}
+	if entry, _ := restored.Apply(grant); entry != nil {
+		t.Error("Expected the restored ledger to know the grant")
+	}
+
+	snapshot.Entries[0].Postings[0].Amount = big.NewInt(1)
+	if _, err := Restore(snapshot); err == nil {
+		t.Error("Expected an unbalanced entry to fail the restore")
+	}
+
+	// Discrepancies with the chain
+	discrepancies, err := l.Reconcile(context.Background(), map[string]neth.TokenReader{
+		"100": staticReader{treasury: big.NewInt(650), member: big.NewInt(300)},
+	}, []Account{{ChainID: "100", Token: token, Address: treasury}, {ChainID: "100", Token: token, Address: member}})
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(discrepancies) != 1 || discrepancies[0].Account.Address != treasury || discrepancies[0].Difference().Int64() != -50 {
+		t.Errorf("Expected the treasury 50 short on chain, got %+v", discrepancies)
+	}
+}
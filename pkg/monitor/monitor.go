@@ -0,0 +1,170 @@
+// Package monitor evaluates anomaly rules on the tx log events of a pipeline, e.g. large
+// transfers out of a treasury, and dispatches the alerts they raise to handlers
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Severity tells how urgent an alert is, it is the severity of the alert events
+type Severity = event.AlertSeverity
+
+const (
+	SeverityInfo     = event.AlertSeverityInfo
+	SeverityWarning  = event.AlertSeverityWarning
+	SeverityCritical = event.AlertSeverityCritical
+)
+
+// Alert is raised by a rule on a tx log event
+type Alert struct {
+	Rule     string       // Name of the rule, set by the monitor
+	Severity Severity     // SeverityWarning when not set by the rule
+	Message  string       // Human-readable description
+	Event    *nostr.Event // Tx log event the rule was evaluated on, set by the monitor
+}
+
+// Rule inspects new tx log events, it returns an alert when the event is anomalous and nil
+// otherwise
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, txLog *event.TxLogEvent) (*Alert, error)
+}
+
+// Handler is called with every alert, e.g. to log it, notify someone or publish an event
+type Handler func(ctx context.Context, alert Alert) error
+
+// Monitor evaluates its rules on every new tx log event and dispatches the alerts to its
+// handlers. Updates and rollbacks of tx log events and other events are ignored.
+type Monitor struct {
+	rules    []Rule
+	handlers []Handler
+	onError  func(error)
+}
+
+// Option configures a Monitor
+type Option func(*Monitor)
+
+// WithRules adds rules to the monitor
+func WithRules(rules ...Rule) Option {
+	return func(m *Monitor) {
+		m.rules = append(m.rules, rules...)
+	}
+}
+
+// WithHandlers adds the handlers alerts are dispatched to
+func WithHandlers(handlers ...Handler) Option {
+	return func(m *Monitor) {
+		m.handlers = append(m.handlers, handlers...)
+	}
+}
+
+// WithErrorHandler sets the function the monitor reports rule and handler errors to when it is
+// used as a sink, they are dropped by default
+func WithErrorHandler(handler func(error)) Option {
+	return func(m *Monitor) {
+		m.onError = handler
+	}
+}
+
+// New creates a new monitor
+func New(opts ...Option) *Monitor {
+	m := &Monitor{onError: func(error) {}}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Check evaluates the rules on an event and dispatches the alerts they raise, it returns the
+// alerts. A failing rule or handler does not stop the others, their errors are joined.
+func (m *Monitor) Check(ctx context.Context, evt *nostr.Event) ([]Alert, error) {
+	if event.DefaultKind(evt.Kind) != event.KindTxLog {
+		return nil, nil
+	}
+
+	txLog, err := event.ParseTxLogEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+	if txLog.EventType != event.EventTypeTxLogCreated {
+		return nil, nil
+	}
+
+	var alerts []Alert
+	var errs []error
+	for _, rule := range m.rules {
+		alert, err := rule.Evaluate(ctx, txLog)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", rule.Name(), err))
+			continue
+		}
+		if alert == nil {
+			continue
+		}
+
+		alert.Rule = rule.Name()
+		alert.Event = evt
+		if alert.Severity == "" {
+			alert.Severity = SeverityWarning
+		}
+		alerts = append(alerts, *alert)
+
+		for _, handler := range m.handlers {
+			if err := handler(ctx, *alert); err != nil {
+				errs = append(errs, fmt.Errorf("failed to handle alert of rule %s: %w", alert.Rule, err))
+			}
+		}
+	}
+
+	return alerts, errors.Join(errs...)
+}
+
+// EmitAlerts returns a handler publishing every alert as an alert event referencing the event
+// it was raised on, signed with the private key and sent to a sink
+func EmitAlerts(s sink.Sink, privateKey string) Handler {
+	return func(ctx context.Context, alert Alert) error {
+		evt, err := event.CreateAlertEvent(alert.Rule, alert.Severity, alert.Message, alert.Event)
+		if err != nil {
+			return err
+		}
+
+		if err := evt.Sign(privateKey); err != nil {
+			return fmt.Errorf("failed to sign alert: %w", err)
+		}
+
+		return s.Send(ctx, evt)
+	}
+}
+
+// Sink returns a sink that delivers events to the next sink and checks them once delivered.
+// Errors of the checks are reported to the error handler, they do not fail the delivery.
+func (m *Monitor) Sink(next sink.Sink) sink.Sink {
+	return &monitoredSink{next: next, monitor: m}
+}
+
+// monitoredSink checks the events delivered to a sink
+type monitoredSink struct {
+	next    sink.Sink
+	monitor *Monitor
+}
+
+// Send delivers an event and checks it
+func (s *monitoredSink) Send(ctx context.Context, evt *nostr.Event) error {
+	if err := s.next.Send(ctx, evt); err != nil {
+		return err
+	}
+
+	if _, err := s.monitor.Check(ctx, evt); err != nil {
+		s.monitor.onError(err)
+	}
+
+	return nil
+}
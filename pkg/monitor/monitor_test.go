@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	token    = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	treasury = "0x1111111111111111111111111111111111111111"
+	member   = "0x2222222222222222222222222222222222222222"
+	scammer  = "0x3333333333333333333333333333333333333333"
+)
+
+func testTransfer(t *testing.T, hash, from, to string, value int64) *nostr.Event {
+	data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"%d"}`, from, to, value))
+	evt, err := event.CreateTxLogEvent(neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	return evt
+}
+
+// recordingSink keeps the events it is sent
+type recordingSink struct {
+	events []*nostr.Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, evt *nostr.Event) error {
+	s.events = append(s.events, evt)
+	return nil
+}
+
+func TestMonitorRules(t *testing.T) {
+	counterparties := NewCounterpartyRule(treasury)
+	counterparties.Know(treasury, member)
+
+	var handled []Alert
+	m := New(
+		WithRules(
+			&LargeTransferRule{Token: token, Threshold: big.NewInt(1000), Severity: SeverityCritical},
+			counterparties,
+			NewBlocklistRule(scammer),
+		),
+		WithHandlers(func(ctx context.Context, alert Alert) error {
+			handled = append(handled, alert)
+			return nil
+		}),
+	)
+
+	check := func(evt *nostr.Event) []string {
+		alerts, err := m.Check(context.Background(), evt)
+		if err != nil {
+			t.Fatalf("Failed to check event: %v", err)
+		}
+		var rules []string
+		for _, alert := range alerts {
+			if alert.Event != evt {
+				t.Errorf("Expected the alert to reference the event")
+			}
+			rules = append(rules, fmt.Sprintf("%s:%s", alert.Rule, alert.Severity))
+		}
+		return rules
+	}
+
+	if rules := check(testTransfer(t, "0x01", treasury, member, 100)); len(rules) != 0 {
+		t.Errorf("Expected no alert, got %v", rules)
+	}
+
+	large := testTransfer(t, "0x02", treasury, member, 5000)
+	if rules := check(large); fmt.Sprint(rules) != "[large_transfer:critical]" {
+		t.Errorf("Expected a large transfer alert, got %v", rules)
+	}
+
+	// Only the first transfer to a new counterparty raises an alert
+	if rules := check(testTransfer(t, "0x03", treasury, scammer, 10)); fmt.Sprint(rules) != "[new_counterparty:warning blocked_address:critical]" {
+		t.Errorf("Expected new counterparty and blocked address alerts, got %v", rules)
+	}
+	if rules := check(testTransfer(t, "0x04", treasury, scammer, 10)); fmt.Sprint(rules) != "[blocked_address:critical]" {
+		t.Errorf("Expected a blocked address alert, got %v", rules)
+	}
+
+	// Updates of tx log events are not evaluated again
+	update, err := event.UpdateTxLogStatus(large, event.TxLogStatusConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to update tx log event: %v", err)
+	}
+	if rules := check(update); len(rules) != 0 {
+		t.Errorf("Expected no alert for an update, got %v", rules)
+	}
+
+	if len(handled) != 4 {
+		t.Errorf("Expected 4 handled alerts, got %d", len(handled))
+	}
+}
+
+func TestMonitorSink(t *testing.T) {
+	var reported []error
+	m := New(
+		WithRules(NewBlocklistRule(scammer)),
+		WithHandlers(func(ctx context.Context, alert Alert) error {
+			return errors.New("handler down")
+		}),
+		WithErrorHandler(func(err error) {
+			reported = append(reported, err)
+		}),
+	)
+
+	next := &recordingSink{}
+	s := m.Sink(next)
+
+	// The event is delivered even though handling its alert fails
+	if err := s.Send(context.Background(), testTransfer(t, "0x01", scammer, member, 10)); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+	if len(next.events) != 1 {
+		t.Errorf("Expected the event to be delivered, got %d events", len(next.events))
+	}
+	if len(reported) != 1 {
+		t.Errorf("Expected 1 reported error, got %d", len(reported))
+	}
+}
+
+func TestEmitAlerts(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+	alerts := &recordingSink{}
+
+	m := New(WithRules(NewBlocklistRule(scammer)), WithHandlers(EmitAlerts(alerts, privateKey)))
+
+	evt := testTransfer(t, "0x01", scammer, member, 10)
+	evt.Sign(privateKey)
+	if _, err := m.Check(context.Background(), evt); err != nil {
+		t.Fatalf("Failed to check event: %v", err)
+	}
+
+	if len(alerts.events) != 1 {
+		t.Fatalf("Expected 1 alert event, got %d", len(alerts.events))
+	}
+	alert, err := event.ParseAlertEvent(alerts.events[0])
+	if err != nil {
+		t.Fatalf("Failed to parse alert: %v", err)
+	}
+	if alert.Rule != "blocked_address" || alert.Severity != SeverityCritical || alert.Subject != evt.ID {
+		t.Errorf("Expected a critical blocked address alert about %s, got %+v", evt.ID, alert)
+	}
+	if ok, _ := alerts.events[0].CheckSignature(); !ok {
+		t.Error("Expected the alert to be signed")
+	}
+}
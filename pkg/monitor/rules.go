@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+// transferData returns the ERC20 transfer of a tx log event, nil for other logs
+func transferData(txLog *event.TxLogEvent) (*neth.LogTransferData, *big.Int) {
+	if !strings.EqualFold(txLog.LogData.Topic, neth.TopicERC20Transfer) {
+		return nil, nil
+	}
+
+	data, err := txLog.LogData.GetTransferData()
+	if err != nil || data == nil {
+		return nil, nil
+	}
+
+	value, ok := new(big.Int).SetString(data.Value, 10)
+	if !ok {
+		return nil, nil
+	}
+
+	return data, value
+}
+
+// LargeTransferRule raises an alert on transfers of a token at or above a threshold
+type LargeTransferRule struct {
+	Token     string   // Token contract, any token when empty
+	Threshold *big.Int // In base units
+	Severity  Severity
+}
+
+// Name returns the name of the rule
+func (r *LargeTransferRule) Name() string {
+	return "large_transfer"
+}
+
+// Evaluate checks the value of a transfer against the threshold
+func (r *LargeTransferRule) Evaluate(ctx context.Context, txLog *event.TxLogEvent) (*Alert, error) {
+	if r.Token != "" && !strings.EqualFold(r.Token, txLog.LogData.To) {
+		return nil, nil
+	}
+
+	data, value := transferData(txLog)
+	if data == nil || value.Cmp(r.Threshold) < 0 {
+		return nil, nil
+	}
+
+	return &Alert{
+		Severity: r.Severity,
+		Message:  fmt.Sprintf("Transfer of %s of %s from %s to %s", value, txLog.LogData.To, data.From, data.To),
+	}, nil
+}
+
+// CounterpartyRule raises an alert the first time a watched address sends to or receives from
+// an address, e.g. a treasury paying someone new
+type CounterpartyRule struct {
+	Severity Severity
+
+	mu    sync.Mutex
+	known map[string]map[string]bool // Counterparties by watched address
+}
+
+// NewCounterpartyRule creates a rule watching the given addresses
+func NewCounterpartyRule(addresses ...string) *CounterpartyRule {
+	r := &CounterpartyRule{known: make(map[string]map[string]bool)}
+	for _, address := range addresses {
+		r.known[strings.ToLower(address)] = make(map[string]bool)
+	}
+	return r
+}
+
+// Know records a counterparty of a watched address, e.g. from the past transfers, so that it
+// does not raise an alert
+func (r *CounterpartyRule) Know(address, counterparty string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if known, ok := r.known[strings.ToLower(address)]; ok {
+		known[strings.ToLower(counterparty)] = true
+	}
+}
+
+// Name returns the name of the rule
+func (r *CounterpartyRule) Name() string {
+	return "new_counterparty"
+}
+
+// Evaluate checks if the counterparty of a watched address was seen before
+func (r *CounterpartyRule) Evaluate(ctx context.Context, txLog *event.TxLogEvent) (*Alert, error) {
+	data, _ := transferData(txLog)
+	if data == nil {
+		return nil, nil
+	}
+	from, to := strings.ToLower(data.From), strings.ToLower(data.To)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var messages []string
+	for _, pair := range [][2]string{{from, to}, {to, from}} {
+		known, ok := r.known[pair[0]]
+		if !ok || known[pair[1]] {
+			continue
+		}
+
+		known[pair[1]] = true
+		messages = append(messages, fmt.Sprintf("First transfer between %s and %s", pair[0], pair[1]))
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	return &Alert{Severity: r.Severity, Message: strings.Join(messages, ", ")}, nil
+}
+
+// BlocklistRule raises an alert on transfers from or to a blocked address, e.g. a sanctioned
+// address or a known scam
+type BlocklistRule struct {
+	Severity Severity
+
+	blocked map[string]bool
+}
+
+// NewBlocklistRule creates a rule blocking the given addresses
+func NewBlocklistRule(addresses ...string) *BlocklistRule {
+	r := &BlocklistRule{Severity: SeverityCritical, blocked: make(map[string]bool)}
+	for _, address := range addresses {
+		r.blocked[strings.ToLower(address)] = true
+	}
+	return r
+}
+
+// Name returns the name of the rule
+func (r *BlocklistRule) Name() string {
+	return "blocked_address"
+}
+
+// Evaluate checks the sender and receiver of a transfer against the blocked addresses
+func (r *BlocklistRule) Evaluate(ctx context.Context, txLog *event.TxLogEvent) (*Alert, error) {
+	data, _ := transferData(txLog)
+	if data == nil {
+		return nil, nil
+	}
+
+	for _, address := range []string{data.From, data.To} {
+		if r.blocked[strings.ToLower(address)] {
+			return &Alert{
+				Severity: r.Severity,
+				Message:  fmt.Sprintf("Transfer from %s to %s involves blocked address %s", data.From, data.To, address),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,148 @@
+package neth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Positions of the state root and block number in an RLP encoded block header
+const (
+	headerStateRootIndex = 3
+	headerNumberIndex    = 8
+)
+
+// BalanceProof is an EIP-1186 proof (as returned by eth_getProof) of the ERC-20 balance of a
+// holder at a block, it lets consumers verify a balance against a block hash without trusting
+// the publisher
+type BalanceProof struct {
+	Header       hexutil.Bytes   `json:"header"`        // RLP encoded block header
+	Token        common.Address  `json:"token"`         // ERC-20 contract
+	Holder       common.Address  `json:"holder"`        // Address whose balance is proven
+	Slot         uint64          `json:"slot"`          // Storage slot of the balances mapping, checked against the one expected by the consumer
+	AccountProof []hexutil.Bytes `json:"account_proof"` // State trie nodes from the root to the token account
+	StorageProof []hexutil.Bytes `json:"storage_proof"` // Storage trie nodes from the root to the balance
+}
+
+// account is the consensus encoding of an account in the state trie
+type account struct {
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot common.Hash
+	CodeHash    []byte
+}
+
+// BalanceStorageKey returns the storage key of the balance of a holder in a balances mapping
+// declared at the given slot, keccak256(holder . slot)
+func BalanceStorageKey(holder common.Address, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(holder.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(slot).Bytes(), 32),
+	)
+}
+
+// BlockHash returns the hash of the block header of the proof
+func (p *BalanceProof) BlockHash() common.Hash {
+	return crypto.Keccak256Hash(p.Header)
+}
+
+// BlockNumber returns the number of the block of the proof
+func (p *BalanceProof) BlockNumber() (uint64, error) {
+	return headerNumber(p.Header)
+}
+
+// Verify checks that the header hashes to the block hash, that the account of the token is part
+// of the state of the block and that the balance is part of its storage, the proven balance is
+// returned
+func (p *BalanceProof) Verify(blockHash common.Hash) (*big.Int, error) {
+	if p.BlockHash() != blockHash {
+		return nil, fmt.Errorf("header hash %s does not match block hash %s", p.BlockHash().Hex(), blockHash.Hex())
+	}
+
+	stateRoot, err := headerRoot(p.Header, headerStateRootIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedAccount, err := verifyTrieProof(stateRoot, crypto.Keccak256(p.Token.Bytes()), proofNodes(p.AccountProof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof: %w", err)
+	}
+
+	var acc account
+	if err := rlp.DecodeBytes(encodedAccount, &acc); err != nil {
+		return nil, fmt.Errorf("invalid account: %w", err)
+	}
+
+	key := BalanceStorageKey(p.Holder, p.Slot)
+	encodedValue, err := verifyTrieProof(acc.StorageRoot, crypto.Keccak256(key.Bytes()), proofNodes(p.StorageProof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage proof: %w", err)
+	}
+
+	// Storage values are RLP strings of the value without leading zeros
+	var value []byte
+	if err := rlp.DecodeBytes(encodedValue, &value); err != nil {
+		return nil, fmt.Errorf("invalid storage value: %w", err)
+	}
+
+	return new(big.Int).SetBytes(value), nil
+}
+
+// headerField returns a raw field of an RLP encoded block header
+func headerField(header []byte, index int) (rlp.RawValue, error) {
+	var fields []rlp.RawValue
+	if err := rlp.DecodeBytes(header, &fields); err != nil {
+		return nil, fmt.Errorf("invalid block header: %w", err)
+	}
+
+	if len(fields) <= index {
+		return nil, errors.New("invalid block header: too few fields")
+	}
+
+	return fields[index], nil
+}
+
+// headerRoot returns a trie root field of an RLP encoded block header
+func headerRoot(header []byte, index int) (common.Hash, error) {
+	field, err := headerField(header, index)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var root common.Hash
+	if err := rlp.DecodeBytes(field, &root); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid block header root: %w", err)
+	}
+
+	return root, nil
+}
+
+// headerNumber returns the block number of an RLP encoded block header
+func headerNumber(header []byte) (uint64, error) {
+	field, err := headerField(header, headerNumberIndex)
+	if err != nil {
+		return 0, err
+	}
+
+	var number uint64
+	if err := rlp.DecodeBytes(field, &number); err != nil {
+		return 0, fmt.Errorf("invalid block number: %w", err)
+	}
+
+	return number, nil
+}
+
+// proofNodes converts the nodes of a proof
+func proofNodes(nodes []hexutil.Bytes) [][]byte {
+	proof := make([][]byte, len(nodes))
+	for i, node := range nodes {
+		proof[i] = node
+	}
+	return proof
+}
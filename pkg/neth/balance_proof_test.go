@@ -0,0 +1,123 @@
+package neth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// testSecureTrie builds a trie keyed by the hashes of the keys, returning its root and the proof
+// of the proven key
+func testSecureTrie(t *testing.T, values map[string][]byte, proven []byte) (common.Hash, []hexutil.Bytes) {
+	t.Helper()
+
+	tr := trie.NewEmpty(nil)
+	for key, value := range values {
+		if err := tr.Update(crypto.Keccak256([]byte(key)), value); err != nil {
+			t.Fatalf("Failed to update trie: %v", err)
+		}
+	}
+
+	var proof proofList
+	if err := tr.Prove(crypto.Keccak256(proven), &proof); err != nil {
+		t.Fatalf("Failed to prove key: %v", err)
+	}
+
+	nodes := make([]hexutil.Bytes, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return tr.Hash(), nodes
+}
+
+func TestBalanceProof(t *testing.T) {
+	token := common.HexToAddress("0x5815E61eF72c9E6107b5c5A05FD121F334f7a7f1")
+	holder := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	balance := big.NewInt(2500000)
+
+	// Storage of the token, the balances mapping is at slot 0
+	storage := map[string][]byte{}
+	for i, h := range []common.Address{holder, common.HexToAddress("0x2222222222222222222222222222222222222222"), common.HexToAddress("0x3333333333333333333333333333333333333333")} {
+		value, err := rlp.EncodeToBytes(new(big.Int).Add(balance, big.NewInt(int64(i))).Bytes())
+		if err != nil {
+			t.Fatalf("Failed to encode value: %v", err)
+		}
+		storage[string(BalanceStorageKey(h, 0).Bytes())] = value
+	}
+	storageRoot, storageProof := testSecureTrie(t, storage, BalanceStorageKey(holder, 0).Bytes())
+
+	// State with the token and two other accounts
+	state := map[string][]byte{}
+	for _, a := range []common.Address{token, common.HexToAddress("0x4444444444444444444444444444444444444444"), common.HexToAddress("0x5555555555555555555555555555555555555555")} {
+		root := common.Hash{}
+		if a == token {
+			root = storageRoot
+		}
+		encoded, err := rlp.EncodeToBytes(account{Nonce: 1, Balance: big.NewInt(0), StorageRoot: root, CodeHash: crypto.Keccak256(nil)})
+		if err != nil {
+			t.Fatalf("Failed to encode account: %v", err)
+		}
+		state[string(a.Bytes())] = encoded
+	}
+	stateRoot, accountProof := testSecureTrie(t, state, token.Bytes())
+
+	header, err := rlp.EncodeToBytes([]any{
+		common.Hash{},    // parent hash
+		common.Hash{},    // uncle hash
+		common.Address{}, // coinbase
+		stateRoot,
+		common.Hash{},     // transactions root
+		common.Hash{},     // receipts root
+		make([]byte, 256), // bloom
+		uint64(0),         // difficulty
+		uint64(12345),     // number
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+
+	proof := &BalanceProof{
+		Header:       header,
+		Token:        token,
+		Holder:       holder,
+		Slot:         0,
+		AccountProof: accountProof,
+		StorageProof: storageProof,
+	}
+
+	number, err := proof.BlockNumber()
+	if err != nil || number != 12345 {
+		t.Errorf("Expected block 12345, got %d (%v)", number, err)
+	}
+
+	proven, err := proof.Verify(proof.BlockHash())
+	if err != nil {
+		t.Fatalf("Failed to verify proof: %v", err)
+	}
+	if proven.Cmp(balance) != 0 {
+		t.Errorf("Expected balance %s, got %s", balance, proven)
+	}
+
+	if _, err := proof.Verify(common.Hash{1}); err == nil {
+		t.Error("Expected an error for another block hash")
+	}
+
+	// Another holder is not covered by the storage proof
+	other := *proof
+	other.Holder = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	if _, err := other.Verify(proof.BlockHash()); err == nil {
+		t.Error("Expected an error for a holder outside of the proof")
+	}
+
+	// Another slot points to another storage key
+	other = *proof
+	other.Slot = 1
+	if _, err := other.Verify(proof.BlockHash()); err == nil {
+		t.Error("Expected an error for another slot")
+	}
+}
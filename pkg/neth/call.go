@@ -0,0 +1,185 @@
+package neth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Call is a call made by an account, to a contract or to another account
+type Call struct {
+	To    common.Address `json:"to"`
+	Value *big.Int       `json:"value,omitempty"`
+	Data  hexutil.Bytes  `json:"data,omitempty"`
+}
+
+// Equal checks if two calls have the same target, value and calldata, a nil value is zero
+func (c Call) Equal(other Call) bool {
+	return c.To == other.To && callValue(c).Cmp(callValue(other)) == 0 && bytes.Equal(c.Data, other.Data)
+}
+
+// callValue returns the value of a call, zero when not set
+func callValue(c Call) *big.Int {
+	if c.Value == nil {
+		return new(big.Int)
+	}
+	return c.Value
+}
+
+// errInvalidCallData is returned when the arguments of an execute call cannot be decoded
+var errInvalidCallData = errors.New("invalid execute calldata")
+
+// DecodeExecuteCalls decodes the calls made by a smart account from the calldata of its execute,
+// executeBatch or execTransactionFromModule function, e.g. the calldata of a user operation
+func DecodeExecuteCalls(callData []byte) ([]Call, error) {
+	if len(callData) < 4 {
+		return nil, errInvalidCallData
+	}
+
+	selector, args := callData[:4], callData[4:]
+	switch {
+	case bytes.Equal(selector, FuncSigSingle), bytes.Equal(selector, FuncSigSafeExecFromModule):
+		to, err := abiWord(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		value, err := abiWord(args, 32)
+		if err != nil {
+			return nil, err
+		}
+		data, err := abiBytes(args, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Call{{To: common.BytesToAddress(to), Value: new(big.Int).SetBytes(value), Data: data}}, nil
+
+	case bytes.Equal(selector, FuncSigBatch):
+		targets, err := abiArray(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		values, err := abiArray(args, 32)
+		if err != nil {
+			return nil, err
+		}
+		datas, err := abiArray(args, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// Some accounts leave the values out of batches without value
+		if len(targets.words) != len(datas.words) || (len(values.words) != 0 && len(values.words) != len(targets.words)) {
+			return nil, fmt.Errorf("%w: batch of %d targets, %d values and %d calldatas", errInvalidCallData, len(targets.words), len(values.words), len(datas.words))
+		}
+
+		calls := make([]Call, len(targets.words))
+		for i, target := range targets.words {
+			calls[i] = Call{To: common.BytesToAddress(target), Value: new(big.Int)}
+			if len(values.words) != 0 {
+				calls[i].Value.SetBytes(values.words[i])
+			}
+
+			// The elements of dynamic arrays are offsets relative to the start of the elements
+			offset := new(big.Int).SetBytes(datas.words[i])
+			if !offset.IsUint64() || offset.Uint64() > uint64(len(datas.elems)) {
+				return nil, errInvalidCallData
+			}
+			data, err := abiBytesAt(datas.elems, offset.Uint64())
+			if err != nil {
+				return nil, err
+			}
+			calls[i].Data = data
+		}
+
+		return calls, nil
+
+	default:
+		return nil, fmt.Errorf("unknown execute function %s", hexutil.Encode(selector))
+	}
+}
+
+// abiWord returns the 32 bytes word at a position of ABI encoded arguments
+func abiWord(args []byte, pos uint64) ([]byte, error) {
+	if pos+32 > uint64(len(args)) {
+		return nil, errInvalidCallData
+	}
+	return args[pos : pos+32], nil
+}
+
+// abiOffset reads the offset of a dynamic argument from its head
+func abiOffset(args []byte, pos uint64) (uint64, error) {
+	word, err := abiWord(args, pos)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := new(big.Int).SetBytes(word)
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(args)) {
+		return 0, errInvalidCallData
+	}
+	return offset.Uint64(), nil
+}
+
+// abiBytes reads a dynamic bytes argument, the head at pos holds its offset
+func abiBytes(args []byte, pos uint64) ([]byte, error) {
+	start, err := abiOffset(args, pos)
+	if err != nil {
+		return nil, err
+	}
+	return abiBytesAt(args, start)
+}
+
+// abiBytesAt reads the bytes encoded at an offset
+func abiBytesAt(args []byte, start uint64) ([]byte, error) {
+	word, err := abiWord(args, start)
+	if err != nil {
+		return nil, err
+	}
+
+	length := new(big.Int).SetBytes(word)
+	if !length.IsUint64() || length.Uint64() > uint64(len(args)) || start+32+length.Uint64() > uint64(len(args)) {
+		return nil, errInvalidCallData
+	}
+
+	return append([]byte{}, args[start+32:start+32+length.Uint64()]...), nil
+}
+
+// abiArrayData is a decoded dynamic array, its words and the data they are relative to
+type abiArrayData struct {
+	words [][]byte
+	elems []byte // Encoding following the length, offsets of dynamic elements are relative to it
+}
+
+// abiArray reads a dynamic array argument, the head at pos holds its offset
+func abiArray(args []byte, pos uint64) (*abiArrayData, error) {
+	start, err := abiOffset(args, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := abiWord(args, start)
+	if err != nil {
+		return nil, err
+	}
+
+	length := new(big.Int).SetBytes(word)
+	if !length.IsUint64() || length.Uint64() > uint64(len(args)-int(start))/32 {
+		return nil, errInvalidCallData
+	}
+
+	array := &abiArrayData{elems: args[start+32:]}
+	for i := uint64(0); i < length.Uint64(); i++ {
+		word, err := abiWord(array.elems, i*32)
+		if err != nil {
+			return nil, err
+		}
+		array.words = append(array.words, word)
+	}
+
+	return array, nil
+}
@@ -0,0 +1,77 @@
+package neth
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// abiEncodeBytes encodes a bytes value, its length followed by the padded data
+func abiEncodeBytes(data []byte) []byte {
+	encoded := common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)
+	return append(encoded, common.RightPadBytes(data, (len(data)+31)/32*32)...)
+}
+
+func abiUint(n int) []byte {
+	return common.LeftPadBytes(big.NewInt(int64(n)).Bytes(), 32)
+}
+
+func TestDecodeExecuteCalls(t *testing.T) {
+	target := common.HexToAddress("0x5815E61eF72c9E6107b5c5A05FD121F334f7a7f1")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	transfer := append(common.FromHex("0xa9059cbb"), make([]byte, 64)...)
+
+	// execute(address,uint256,bytes)
+	single := append([]byte{}, FuncSigSingle...)
+	single = append(single, common.LeftPadBytes(target.Bytes(), 32)...)
+	single = append(single, abiUint(7)...)
+	single = append(single, abiUint(96)...)
+	single = append(single, abiEncodeBytes(transfer)...)
+
+	calls, err := DecodeExecuteCalls(single)
+	if err != nil {
+		t.Fatalf("Failed to decode execute: %v", err)
+	}
+	expected := Call{To: target, Value: big.NewInt(7), Data: transfer}
+	if len(calls) != 1 || !calls[0].Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+
+	// executeBatch(address[],uint256[],bytes[]) without values
+	batch := append([]byte{}, FuncSigBatch...)
+	batch = append(batch, abiUint(96)...)  // targets
+	batch = append(batch, abiUint(192)...) // values
+	batch = append(batch, abiUint(224)...) // calldatas
+	batch = append(batch, abiUint(2)...)
+	batch = append(batch, common.LeftPadBytes(target.Bytes(), 32)...)
+	batch = append(batch, common.LeftPadBytes(other.Bytes(), 32)...)
+	batch = append(batch, abiUint(0)...)
+	batch = append(batch, abiUint(2)...)
+	batch = append(batch, abiUint(64)...)
+	batch = append(batch, abiUint(64+32+96)...)
+	batch = append(batch, abiEncodeBytes(transfer)...)
+	batch = append(batch, abiEncodeBytes([]byte{0xd0, 0xe3, 0x0d, 0xb0})...)
+
+	calls, err = DecodeExecuteCalls(batch)
+	if err != nil {
+		t.Fatalf("Failed to decode executeBatch: %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 calls, got %d", len(calls))
+	}
+	if !calls[0].Equal(Call{To: target, Data: transfer}) {
+		t.Errorf("Expected transfer call, got %v", calls[0])
+	}
+	if calls[1].To != other || !bytes.Equal(calls[1].Data, []byte{0xd0, 0xe3, 0x0d, 0xb0}) {
+		t.Errorf("Expected deposit call, got %v", calls[1])
+	}
+
+	if _, err := DecodeExecuteCalls(transfer); err == nil {
+		t.Error("Expected an error for an unknown function")
+	}
+	if _, err := DecodeExecuteCalls(single[:100]); err == nil {
+		t.Error("Expected an error for truncated calldata")
+	}
+}
@@ -0,0 +1,26 @@
+package neth
+
+import "time"
+
+// Finality is the level of finality of a block
+type Finality string
+
+const (
+	FinalitySafe      Finality = "safe"
+	FinalityFinalized Finality = "finalized"
+)
+
+// Checkpoint is a block head reported by a chain at a given level of finality, every block at
+// or below it is at least as final
+type Checkpoint struct {
+	ChainID     string    `json:"chain_id"`
+	BlockNumber uint64    `json:"block_number"`
+	BlockHash   string    `json:"block_hash"`
+	Finality    Finality  `json:"finality"`
+	ObservedAt  time.Time `json:"observed_at"`
+}
+
+// Covers checks if a block of a chain is covered by the checkpoint
+func (c *Checkpoint) Covers(chainID string, blockNumber uint64) bool {
+	return c.ChainID == chainID && blockNumber <= c.BlockNumber
+}
@@ -0,0 +1,55 @@
+package neth
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// GasEstimate holds the gas fields estimated for a user operation by a bundler or paymaster
+type GasEstimate struct {
+	CallGasLimit         *hexutil.Big  `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big  `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big  `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big  `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big  `json:"maxPriorityFeePerGas,omitempty"`
+	PaymasterAndData     hexutil.Bytes `json:"paymasterAndData,omitempty"`
+	ValidAfter           time.Time     `json:"validAfter"`
+	ValidUntil           time.Time     `json:"validUntil"`
+}
+
+// IsValid checks if the estimate can be used at the given time
+func (g *GasEstimate) IsValid(now time.Time) bool {
+	if !g.ValidAfter.IsZero() && now.Before(g.ValidAfter) {
+		return false
+	}
+	if !g.ValidUntil.IsZero() && !now.Before(g.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// Apply returns a copy of the user operation with the estimated fields set, fields missing
+// from the estimate are left untouched
+func (g *GasEstimate) Apply(userOp UserOp) UserOp {
+	op := userOp
+
+	set := func(dst **big.Int, value *hexutil.Big) {
+		if value != nil {
+			*dst = new(big.Int).Set(value.ToInt())
+		}
+	}
+
+	set(&op.CallGasLimit, g.CallGasLimit)
+	set(&op.VerificationGasLimit, g.VerificationGasLimit)
+	set(&op.PreVerificationGas, g.PreVerificationGas)
+	set(&op.MaxFeePerGas, g.MaxFeePerGas)
+	set(&op.MaxPriorityFeePerGas, g.MaxPriorityFeePerGas)
+
+	if len(g.PaymasterAndData) > 0 {
+		op.PaymasterAndData = append([]byte(nil), g.PaymasterAndData...)
+	}
+
+	return op
+}
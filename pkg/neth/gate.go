@@ -0,0 +1,180 @@
+package neth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// GateRuleType is the kind of holding a token gate requires
+type GateRuleType string
+
+const (
+	GateRuleTokenBalance GateRuleType = "token_balance" // A minimum balance of an ERC-20 token
+	GateRuleNFTOwnership GateRuleType = "nft_ownership" // An NFT of a collection, or a specific one
+)
+
+// Selectors of the ERC-20 and ERC-721 view functions read by the gate rules
+var (
+	selectorBalanceOf = hexutil.MustDecode("0x70a08231") // balanceOf(address)
+	selectorOwnerOf   = hexutil.MustDecode("0x6352211e") // ownerOf(uint256)
+)
+
+// GateRule is a holding required to pass a token gate
+type GateRule struct {
+	Type       GateRuleType   `json:"type"`
+	ChainID    string         `json:"chain_id"`
+	Contract   common.Address `json:"contract"`
+	MinBalance *big.Int       `json:"min_balance,omitempty"` // Defaults to 1 for NFTs
+	TokenID    *big.Int       `json:"token_id,omitempty"`    // A specific NFT, ownerOf is checked
+}
+
+// TokenReader reads token holdings from a node of one chain
+type TokenReader interface {
+	BalanceOf(ctx context.Context, contract, owner common.Address) (*big.Int, error)
+	OwnerOf(ctx context.Context, contract common.Address, tokenID *big.Int) (common.Address, error)
+}
+
+// Check checks if an address satisfies the rule, reading its holdings with the reader of the
+// chain of the rule
+func (r GateRule) Check(ctx context.Context, reader TokenReader, address common.Address) (bool, error) {
+	switch r.Type {
+	case GateRuleTokenBalance:
+		if r.MinBalance == nil {
+			return false, fmt.Errorf("token balance rule has no minimum balance")
+		}
+
+		balance, err := reader.BalanceOf(ctx, r.Contract, address)
+		if err != nil {
+			return false, err
+		}
+		return balance.Cmp(r.MinBalance) >= 0, nil
+
+	case GateRuleNFTOwnership:
+		if r.TokenID != nil {
+			owner, err := reader.OwnerOf(ctx, r.Contract, r.TokenID)
+			if err != nil {
+				return false, err
+			}
+			return owner == address, nil
+		}
+
+		minBalance := r.MinBalance
+		if minBalance == nil {
+			minBalance = big.NewInt(1)
+		}
+
+		balance, err := reader.BalanceOf(ctx, r.Contract, address)
+		if err != nil {
+			return false, err
+		}
+		return balance.Cmp(minBalance) >= 0, nil
+
+	default:
+		return false, fmt.Errorf("unknown gate rule type: %s", r.Type)
+	}
+}
+
+// RPCTokenReader is a TokenReader calling the view functions of the contracts with eth_call
+type RPCTokenReader struct {
+	client *http.Client
+	url    string
+}
+
+// NewRPCTokenReader creates a token reader for the JSON-RPC endpoint of a node, the default
+// HTTP client is used when client is nil
+func NewRPCTokenReader(client *http.Client, url string) *RPCTokenReader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RPCTokenReader{client: client, url: url}
+}
+
+// BalanceOf returns the token balance of an owner, the number of NFTs for ERC-721 contracts
+func (r *RPCTokenReader) BalanceOf(ctx context.Context, contract, owner common.Address) (*big.Int, error) {
+	data := append(append([]byte{}, selectorBalanceOf...), common.LeftPadBytes(owner.Bytes(), 32)...)
+
+	result, err := r.call(ctx, contract, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("invalid balanceOf result of %d bytes", len(result))
+	}
+
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+// OwnerOf returns the owner of an NFT
+func (r *RPCTokenReader) OwnerOf(ctx context.Context, contract common.Address, tokenID *big.Int) (common.Address, error) {
+	data := append(append([]byte{}, selectorOwnerOf...), common.LeftPadBytes(tokenID.Bytes(), 32)...)
+
+	result, err := r.call(ctx, contract, data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) < 32 {
+		return common.Address{}, fmt.Errorf("invalid ownerOf result of %d bytes", len(result))
+	}
+
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// call runs eth_call against the latest block
+func (r *RPCTokenReader) call(ctx context.Context, contract common.Address, data []byte) ([]byte, error) {
+	return ethCall(ctx, r.client, r.url, contract, data)
+}
+
+// ethCall calls a view function of a contract with eth_call against the latest block
+func ethCall(ctx context.Context, client *http.Client, url string, contract common.Address, data []byte) ([]byte, error) {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_call",
+		"params": []any{
+			map[string]string{"to": contract.Hex(), "data": hexutil.Encode(data)},
+			"latest",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", contract.Hex(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to call %s: %s", contract.Hex(), resp.Status)
+	}
+
+	var response struct {
+		Result hexutil.Bytes `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid eth_call response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("eth_call failed (%d): %s", response.Error.Code, response.Error.Message)
+	}
+
+	return response.Result, nil
+}
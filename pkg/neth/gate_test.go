@@ -0,0 +1,78 @@
+package neth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRPCTokenReader(t *testing.T) {
+	holder := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Method != "eth_call" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		var call struct {
+			Data string `json:"data"`
+		}
+		json.Unmarshal(request.Params[0], &call)
+
+		switch {
+		case strings.HasPrefix(call.Data, "0x70a08231"):
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x00000000000000000000000000000000000000000000000000000000000003e8"}`))
+		case strings.HasPrefix(call.Data, "0x6352211e"):
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x0000000000000000000000001111111111111111111111111111111111111111"}`))
+		default:
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+		}
+	}))
+	defer server.Close()
+
+	reader := NewRPCTokenReader(server.Client(), server.URL)
+	contract := common.HexToAddress("0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d")
+
+	balance, err := reader.BalanceOf(context.Background(), contract, holder)
+	if err != nil {
+		t.Fatalf("Failed to read balance: %v", err)
+	}
+	if balance.Int64() != 1000 {
+		t.Errorf("Expected balance 1000, got %s", balance)
+	}
+
+	rules := []struct {
+		rule     GateRule
+		expected bool
+	}{
+		{GateRule{Type: GateRuleTokenBalance, Contract: contract, MinBalance: big.NewInt(1000)}, true},
+		{GateRule{Type: GateRuleTokenBalance, Contract: contract, MinBalance: big.NewInt(1001)}, false},
+		{GateRule{Type: GateRuleNFTOwnership, Contract: contract}, true},
+		{GateRule{Type: GateRuleNFTOwnership, Contract: contract, TokenID: big.NewInt(7)}, true},
+	}
+	for _, tt := range rules {
+		passed, err := tt.rule.Check(context.Background(), reader, holder)
+		if err != nil {
+			t.Fatalf("Failed to check rule %+v: %v", tt.rule, err)
+		}
+		if passed != tt.expected {
+			t.Errorf("Expected %v for rule %+v, got %v", tt.expected, tt.rule, passed)
+		}
+	}
+
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	if passed, _ := (GateRule{Type: GateRuleNFTOwnership, Contract: contract, TokenID: big.NewInt(7)}).Check(context.Background(), reader, other); passed {
+		t.Error("Expected another address to not own the NFT")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,11 +15,13 @@ import (
 )
 
 const (
-	TopicERC20Transfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
-	DataKeyFrom        = "from"
-	DataKeyTo          = "to"
-	DataKeyTopic       = "topic"
-	DataKeyValue       = "value"
+	DataKeyFrom    = "from"
+	DataKeyTo      = "to"
+	DataKeyTopic   = "topic"
+	DataKeyValue   = "value"
+	DataKeyOwner   = "owner"
+	DataKeySpender = "spender"
+	DataKeySender  = "sender"
 )
 
 type Log struct {
@@ -61,19 +64,13 @@ func (t *Log) GenerateUniqueHash() string {
 	return hash.Hex()
 }
 
+// ToRounded converts the value to a float64 using the given number of decimals.
+// The conversion is exact up to float64 precision, use FormatUnits when the full
+// precision is needed.
 func (t *Log) ToRounded(decimals int64) float64 {
-	v, _ := t.Value.Float64()
+	v, _ := strconv.ParseFloat(FormatUnits(t.Value, decimals), 64)
 
-	if decimals == 0 {
-		return v
-	}
-
-	// Calculate value * 10^x
-	multiplier, _ := new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil).Float64()
-
-	result, _ := new(big.Float).Quo(big.NewFloat(v), big.NewFloat(multiplier)).Float64()
-
-	return result
+	return v
 }
 
 // Update updates the transfer using the given transfer
@@ -128,7 +125,8 @@ func (t *Log) GetEventData() (map[string]interface{}, error) {
 
 // Convert a log to json bytes
 func (t *Log) MarshalJSON() ([]byte, error) {
-	b, err := json.Marshal(t)
+	type Alias Log
+	b, err := json.Marshal((*Alias)(t))
 	if err != nil {
 		return nil, err
 	}
@@ -141,8 +139,13 @@ func sortedJSONBytes(data *json.RawMessage) []byte {
 		return nil
 	}
 
+	// Numbers that a float64 can't hold exactly are kept as written, so that large amounts don't
+	// collide. The others are written as a float64, as the hashes of existing logs were.
+	decoder := json.NewDecoder(bytes.NewReader(*data))
+	decoder.UseNumber()
+
 	var m map[string]interface{}
-	if err := json.Unmarshal(*data, &m); err != nil {
+	if err := decoder.Decode(&m); err != nil {
 		// If it's not a JSON object, return the raw bytes
 		return *data
 	}
@@ -159,10 +162,36 @@ func sortedJSONBytes(data *json.RawMessage) []byte {
 	for _, k := range keys {
 		v := m[k]
 		keyBytes, _ := json.Marshal(k)
-		valueBytes, _ := json.Marshal(v)
+		valueBytes, _ := json.Marshal(hashedNumbers(v))
 		buf.Write(keyBytes)
 		buf.Write(valueBytes)
 	}
 
 	return buf.Bytes()
 }
+
+// hashedNumbers replaces the numbers of a decoded value that a float64 holds exactly with their
+// float64, the others are kept as written
+func hashedNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = hashedNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = hashedNumbers(item)
+		}
+	case json.Number:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return v
+		}
+		exact, ok := new(big.Rat).SetString(v.String())
+		if !ok || exact.Cmp(new(big.Rat).SetFloat64(f)) != 0 {
+			return v
+		}
+		return f
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package neth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestLogPointerMarshalJSON(t *testing.T) {
+	log := &Log{Hash: "0x01", ChainID: "1", Value: big.NewInt(42)}
+
+	b, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("Failed to marshal log: %v", err)
+	}
+
+	var decoded Log
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal log: %v", err)
+	}
+
+	if decoded.Hash != log.Hash || decoded.Value.Cmp(log.Value) != 0 {
+		t.Errorf("Expected %+v, got %+v", log, decoded)
+	}
+}
+
+func TestGenerateUniqueHashLargeNumbers(t *testing.T) {
+	// Both amounts round to the same float64
+	a := json.RawMessage(`{"value":100000000000000000001}`)
+	b := json.RawMessage(`{"value":100000000000000000000}`)
+
+	logA := &Log{TxHash: "0x01", ChainID: "1", Value: big.NewInt(0), Data: &a}
+	logB := &Log{TxHash: "0x01", ChainID: "1", Value: big.NewInt(0), Data: &b}
+
+	if logA.GenerateUniqueHash() == logB.GenerateUniqueHash() {
+		t.Error("Expected different amounts to yield different hashes")
+	}
+}
+
+func TestGenerateUniqueHashGolden(t *testing.T) {
+	// Hashes of logs published before numbers kept their precision, they are the d tags of
+	// existing events and must not change
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"1000000000000000000"}`, "0x9f0885788af9a807d38070dfb6ba0f7568859a2bb5b8e9696f91fa7acc958d24"},
+		{`{"value":1000000,"memo":"hi"}`, "0x21c1019d68bf1dfb08b16e08af3732b1dccf0b91dd3dbc47e922a976e54f8518"},
+		{`{"rate":1.5,"exact":1.50,"scaled":1e3}`, "0x4878377af78d4494c0cd863e3650ba3d6ce7dfc64283381f6741b6981d7394e7"},
+		{`{"value":100000000000000000000}`, "0x4153a4d6ff3ea350fabec57e2cc12598ae997d4cce9dc6c1c15b430937bb61db"},
+		{`{"order":{"amount":2,"fees":{"bps":30}},"ids":[4,5],"ok":true,"none":null}`, "0xb35e955b701915084a345f7da2f762f6afb6b1b34adcbd7a04837cca07eb176b"},
+		{`[1,2,3]`, "0x98041d411d7f28afd5e121af2d4b63fb2fe3d3aedf3779ab6266d4bf5d56ca67"},
+		{"", "0xddc23fa136c277ba0ee489325dacbc61b793f0032276ffcb06069883ab0d7255"},
+	}
+
+	for _, tc := range testCases {
+		log := &Log{TxHash: "0xabc1", ChainID: "100", Value: big.NewInt(1000)}
+		if tc.data != "" {
+			data := json.RawMessage(tc.data)
+			log.Data = &data
+		}
+
+		if hash := log.GenerateUniqueHash(); hash != tc.expected {
+			t.Errorf("%s: Expected hash %s, got %s", tc.data, tc.expected, hash)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package neth
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TopicNativeTransfer is a pseudo topic for native value transfers, which emit no log.
+// It is the keccak hash of "NativeTransfer(address,address,uint256)".
+const TopicNativeTransfer = "0xce8688f853ffa65c042b72302433c25d7a230c322caba0901587534b6551091d"
+
+// NativeTransfer is a plain value transfer derived from a transaction, its traces or block scanning
+type NativeTransfer struct {
+	Hash         string    `json:"hash"`
+	TxHash       string    `json:"tx_hash"`
+	ChainID      string    `json:"chain_id"`
+	BlockNumber  uint64    `json:"block_number"`
+	TraceAddress []int     `json:"trace_address,omitempty"` // Empty for the top level call
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Value        *big.Int  `json:"value"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// GenerateUniqueHash generates a hash for the transfer using the tx hash, chain id and trace address
+func (t *NativeTransfer) GenerateUniqueHash() string {
+	buf := new(bytes.Buffer)
+
+	buf.Write(common.FromHex(t.TxHash))
+	buf.Write(common.FromHex(t.ChainID))
+	for _, index := range t.TraceAddress {
+		buf.Write(big.NewInt(int64(index)).FillBytes(make([]byte, 32)))
+	}
+
+	hash := crypto.Keccak256Hash(buf.Bytes())
+	return hash.Hex()
+}
+
+// ToLog converts the transfer to a log with the native transfer pseudo topic, so that it
+// can be handled like a token transfer
+func (t *NativeTransfer) ToLog() (Log, error) {
+	data, err := json.Marshal(map[string]string{
+		DataKeyFrom:  t.From,
+		DataKeyTo:    t.To,
+		DataKeyValue: t.Value.String(),
+	})
+	if err != nil {
+		return Log{}, err
+	}
+
+	raw := json.RawMessage(data)
+
+	return Log{
+		Hash:      t.Hash,
+		TxHash:    t.TxHash,
+		ChainID:   t.ChainID,
+		Topic:     TopicNativeTransfer,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.CreatedAt,
+		Sender:    t.From,
+		To:        t.To,
+		Value:     t.Value,
+		Data:      &raw,
+	}, nil
+}
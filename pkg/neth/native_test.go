@@ -0,0 +1,52 @@
+package neth
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTopicNativeTransfer(t *testing.T) {
+	expected := crypto.Keccak256Hash([]byte("NativeTransfer(address,address,uint256)")).Hex()
+	if TopicNativeTransfer != expected {
+		t.Errorf("Expected topic %s, got %s", expected, TopicNativeTransfer)
+	}
+}
+
+func TestNativeTransferToLog(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	transfer := NativeTransfer{
+		TxHash:       "0xabc",
+		ChainID:      "100",
+		BlockNumber:  42,
+		TraceAddress: []int{0, 1},
+		From:         "0x1111111111111111111111111111111111111111",
+		To:           "0x2222222222222222222222222222222222222222",
+		Value:        big.NewInt(1000),
+		CreatedAt:    at,
+	}
+	transfer.Hash = transfer.GenerateUniqueHash()
+
+	// Transfers of other calls of the transaction get other hashes
+	other := transfer
+	other.TraceAddress = []int{0, 2}
+	if other.GenerateUniqueHash() == transfer.Hash {
+		t.Error("Expected transfers of different calls to have different hashes")
+	}
+
+	log, err := transfer.ToLog()
+	if err != nil {
+		t.Fatalf("Failed to convert transfer: %v", err)
+	}
+	if log.Topic != TopicNativeTransfer || log.Hash != transfer.Hash || log.Sender != transfer.From || log.To != transfer.To {
+		t.Errorf("Expected the log of the transfer, got %+v", log)
+	}
+	if direction := log.Direction(transfer.To); direction != TransferDirectionIn {
+		t.Errorf("Expected an incoming transfer, got %s", direction)
+	}
+	if amount := log.GetAmount(); amount.String() != "1000" {
+		t.Errorf("Expected amount 1000, got %s", amount)
+	}
+}
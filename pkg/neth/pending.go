@@ -0,0 +1,18 @@
+package neth
+
+import (
+	"math/big"
+	"time"
+)
+
+// PendingTx is a transaction observed in the mempool that has not been mined yet
+type PendingTx struct {
+	Hash     string    `json:"hash"`
+	ChainID  string    `json:"chain_id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Value    *big.Int  `json:"value"`
+	GasPrice *big.Int  `json:"gas_price"`
+	Nonce    int64     `json:"nonce"`
+	SeenAt   time.Time `json:"seen_at"`
+}
@@ -0,0 +1,55 @@
+package neth
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Permit2Address is the address of Uniswap Permit2, the same on every chain
+const Permit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+// FuncSigPermit is the selector of the EIP-2612 permit function
+var FuncSigPermit = crypto.Keccak256([]byte("permit(address,address,uint256,uint256,uint8,bytes32,bytes32)"))[:4]
+
+// PermitCall is an EIP-2612 permit, an allowance granted by the signature of the owner and
+// submitted by anyone. The token only emits an Approval, the call tells it came from a signature.
+type PermitCall struct {
+	Owner    common.Address `json:"owner"`
+	Spender  common.Address `json:"spender"`
+	Value    *big.Int       `json:"value"`
+	Deadline *big.Int       `json:"deadline"` // Unix time after which the signature is rejected
+}
+
+// DecodePermitCall decodes the calldata of an EIP-2612 permit call
+func DecodePermitCall(callData []byte) (*PermitCall, error) {
+	if len(callData) < 4 || !bytes.Equal(callData[:4], FuncSigPermit) {
+		return nil, fmt.Errorf("not a permit call")
+	}
+
+	// Owner, spender, value, deadline and the v, r and s of the signature
+	args := callData[4:]
+	words := make([][]byte, 7)
+	for i := range words {
+		word, err := abiWord(args, uint64(i)*32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permit calldata: %w", err)
+		}
+		words[i] = word
+	}
+
+	return &PermitCall{
+		Owner:    common.BytesToAddress(words[0]),
+		Spender:  common.BytesToAddress(words[1]),
+		Value:    new(big.Int).SetBytes(words[2]),
+		Deadline: new(big.Int).SetBytes(words[3]),
+	}, nil
+}
+
+// IsPermit2 checks if an address is Permit2
+func IsPermit2(address string) bool {
+	return common.IsHexAddress(address) && common.HexToAddress(address) == common.HexToAddress(Permit2Address)
+}
@@ -0,0 +1,45 @@
+package neth
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// permitCallData encodes an EIP-2612 permit call
+func permitCallData(owner, spender common.Address, value, deadline int64) []byte {
+	data := append([]byte{}, FuncSigPermit...)
+	data = append(data, common.LeftPadBytes(owner.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(spender.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(value).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(deadline).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes([]byte{27}, 32)...)
+	data = append(data, bytes.Repeat([]byte{1}, 64)...)
+	return data
+}
+
+func TestDecodePermitCall(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	spender := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	call, err := DecodePermitCall(permitCallData(owner, spender, 500, 1700000000))
+	if err != nil {
+		t.Fatalf("Failed to decode permit: %v", err)
+	}
+	if call.Owner != owner || call.Spender != spender || call.Value.Int64() != 500 || call.Deadline.Int64() != 1700000000 {
+		t.Errorf("Expected the permit of the calldata, got %+v", call)
+	}
+
+	if _, err := DecodePermitCall(permitCallData(owner, spender, 500, 1)[:100]); err == nil {
+		t.Error("Expected truncated calldata to fail")
+	}
+	if _, err := DecodePermitCall(FuncSigSingle); err == nil {
+		t.Error("Expected another function to fail")
+	}
+
+	if !IsPermit2("0x000000000022d473030f116ddee9f6b43ac78ba3") || IsPermit2(owner.Hex()) {
+		t.Error("Expected only Permit2 to be Permit2")
+	}
+}
@@ -0,0 +1,262 @@
+package neth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Positions of the transactions and receipts roots in an RLP encoded block header
+const (
+	headerTransactionsRootIndex = 4
+	headerReceiptsRootIndex     = 5
+)
+
+// ReceiptProof is a Merkle-Patricia proof that a receipt is part of a block, it lets consumers
+// verify a log against a block hash without trusting the publisher. The transaction of the
+// receipt is proven at the same index, which binds the log to its transaction hash.
+type ReceiptProof struct {
+	Header           hexutil.Bytes   `json:"header"`            // RLP encoded block header
+	TxIndex          uint64          `json:"tx_index"`          // Index of the transaction in the block
+	LogIndex         uint64          `json:"log_index"`         // Index of the log in the receipt
+	Proof            []hexutil.Bytes `json:"proof"`             // Receipt trie nodes from the root to the receipt
+	Transaction      hexutil.Bytes   `json:"transaction"`       // Consensus encoded transaction of the receipt
+	TransactionProof []hexutil.Bytes `json:"transaction_proof"` // Transaction trie nodes from the root to the transaction
+}
+
+// ReceiptLog is a log as stored in a receipt
+type ReceiptLog struct {
+	Address common.Address
+	Topics  []common.Hash
+	Data    []byte
+}
+
+// receipt is the consensus encoding of a receipt
+type receipt struct {
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Bloom             []byte
+	Logs              []ReceiptLog
+}
+
+// BlockHash returns the hash of the block header of the proof
+func (p *ReceiptProof) BlockHash() common.Hash {
+	return crypto.Keccak256Hash(p.Header)
+}
+
+// BlockNumber returns the number of the block of the proof
+func (p *ReceiptProof) BlockNumber() (uint64, error) {
+	return headerNumber(p.Header)
+}
+
+// TxHash returns the hash of the transaction of the proof
+func (p *ReceiptProof) TxHash() common.Hash {
+	return crypto.Keccak256Hash(p.Transaction)
+}
+
+// ReceiptsRoot returns the receipts root of the block header of the proof
+func (p *ReceiptProof) ReceiptsRoot() (common.Hash, error) {
+	return headerRoot(p.Header, headerReceiptsRootIndex)
+}
+
+// TransactionsRoot returns the transactions root of the block header of the proof
+func (p *ReceiptProof) TransactionsRoot() (common.Hash, error) {
+	return headerRoot(p.Header, headerTransactionsRootIndex)
+}
+
+// Verify checks that the header hashes to the block hash and that the receipt and the
+// transaction are part of the receipt and transaction tries of the block at the transaction
+// index, the proven log is returned
+func (p *ReceiptProof) Verify(blockHash common.Hash) (*ReceiptLog, error) {
+	if p.BlockHash() != blockHash {
+		return nil, fmt.Errorf("header hash %s does not match block hash %s", p.BlockHash().Hex(), blockHash.Hex())
+	}
+
+	key, err := rlp.EncodeToBytes(p.TxIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	txRoot, err := p.TransactionsRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := verifyTrieProof(txRoot, key, proofNodes(p.TransactionProof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction proof: %w", err)
+	}
+	if !bytes.Equal(tx, p.Transaction) {
+		return nil, fmt.Errorf("transaction %d of the block is not the transaction of the proof", p.TxIndex)
+	}
+
+	root, err := p.ReceiptsRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := verifyTrieProof(root, key, proofNodes(p.Proof))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := decodeReceipt(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.LogIndex >= uint64(len(r.Logs)) {
+		return nil, fmt.Errorf("log index %d out of range, the receipt has %d logs", p.LogIndex, len(r.Logs))
+	}
+
+	return &r.Logs[p.LogIndex], nil
+}
+
+// decodeReceipt decodes a legacy or typed (EIP-2718) receipt
+func decodeReceipt(encoded []byte) (*receipt, error) {
+	if len(encoded) == 0 {
+		return nil, errors.New("empty receipt")
+	}
+
+	// Typed receipts are prefixed with their type, legacy receipts are RLP lists
+	if encoded[0] < 0x80 {
+		encoded = encoded[1:]
+	}
+
+	var r receipt
+	if err := rlp.DecodeBytes(encoded, &r); err != nil {
+		return nil, fmt.Errorf("invalid receipt: %w", err)
+	}
+
+	return &r, nil
+}
+
+// verifyTrieProof returns the value stored at key in the trie with the given root, as proven
+// by the trie nodes of the proof
+func verifyTrieProof(root common.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	db := memorydb.New()
+	for _, node := range proof {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := trie.VerifyProof(root, key, db)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("key not found in proof")
+	}
+
+	return value, nil
+}
+
+// proofList collects the nodes of a trie proof in root to leaf order
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, common.CopyBytes(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	return errors.New("proof nodes cannot be deleted")
+}
+
+// indexedList is a list of consensus encoded transactions or receipts, in block order
+type indexedList [][]byte
+
+func (l indexedList) Len() int { return len(l) }
+
+func (l indexedList) EncodeIndex(i int, w *bytes.Buffer) {
+	w.Write(l[i])
+}
+
+// DeriveRoot returns the root of the trie of consensus encoded transactions or receipts keyed
+// by their index, which is the transactions or receipts root of the block header they belong to
+func DeriveRoot(values [][]byte) common.Hash {
+	return types.DeriveSha(indexedList(values), trie.NewStackTrie(nil))
+}
+
+// proveIndex builds the proof of the value at an index of the trie of a list
+func proveIndex(values [][]byte, index uint64) ([]hexutil.Bytes, error) {
+	tr := trie.NewEmpty(nil)
+	for i, value := range values {
+		key, err := rlp.EncodeToBytes(uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		if err := tr.Update(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof proofList
+	if err := tr.Prove(key, &proof); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]hexutil.Bytes, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// BuildReceiptProof builds the proof for a log from the RLP encoded block header and all the
+// consensus encoded transactions and receipts of the block (as returned by
+// debug_getRawBlock and debug_getRawReceipts)
+func BuildReceiptProof(header []byte, transactions, receipts [][]byte, txIndex, logIndex uint64) (*ReceiptProof, error) {
+	if len(transactions) != len(receipts) {
+		return nil, fmt.Errorf("the block has %d transactions and %d receipts", len(transactions), len(receipts))
+	}
+	if txIndex >= uint64(len(receipts)) {
+		return nil, fmt.Errorf("transaction index %d out of range, the block has %d receipts", txIndex, len(receipts))
+	}
+
+	p := &ReceiptProof{
+		Header:      header,
+		TxIndex:     txIndex,
+		LogIndex:    logIndex,
+		Transaction: transactions[txIndex],
+	}
+
+	txRoot, err := p.TransactionsRoot()
+	if err != nil {
+		return nil, err
+	}
+	if derived := DeriveRoot(transactions); derived != txRoot {
+		return nil, fmt.Errorf("transactions root %s does not match the transactions root %s of the header", derived.Hex(), txRoot.Hex())
+	}
+
+	root, err := p.ReceiptsRoot()
+	if err != nil {
+		return nil, err
+	}
+	if derived := DeriveRoot(receipts); derived != root {
+		return nil, fmt.Errorf("receipts root %s does not match the receipts root %s of the header", derived.Hex(), root.Hex())
+	}
+
+	if p.TransactionProof, err = proveIndex(transactions, txIndex); err != nil {
+		return nil, err
+	}
+	if p.Proof, err = proveIndex(receipts, txIndex); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
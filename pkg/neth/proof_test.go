@@ -0,0 +1,122 @@
+package neth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// testHeader encodes a minimal block header, only the transactions and receipts roots are read by
+// the proofs
+func testHeader(t *testing.T, transactionsRoot, receiptsRoot common.Hash) []byte {
+	t.Helper()
+
+	header, err := rlp.EncodeToBytes([]any{
+		common.Hash{},    // parent hash
+		common.Hash{},    // uncle hash
+		common.Address{}, // coinbase
+		common.Hash{},    // state root
+		transactionsRoot,
+		receiptsRoot,
+		make([]byte, 256), // bloom
+		uint64(0),         // difficulty
+		uint64(12345),     // number
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode header: %v", err)
+	}
+	return header
+}
+
+func TestReceiptProof(t *testing.T) {
+	topic := common.HexToHash(TopicERC20Transfer)
+
+	// Enough receipts for the trie to contain branch, extension and hashed nodes
+	receipts := make([][]byte, 0, 130)
+	transactions := make([][]byte, 0, 130)
+	for i := 0; i < 130; i++ {
+		tx, err := rlp.EncodeToBytes([]any{uint64(i), uint64(1), uint64(21000), common.Address{}, uint64(0), []byte{}})
+		if err != nil {
+			t.Fatalf("Failed to encode transaction: %v", err)
+		}
+
+		r, err := rlp.EncodeToBytes(receipt{
+			PostStateOrStatus: []byte{1},
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Bloom:             make([]byte, 256),
+			Logs: []ReceiptLog{
+				{Address: common.BigToAddress(common.Big1), Topics: []common.Hash{topic}, Data: []byte{byte(i)}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to encode receipt: %v", err)
+		}
+
+		// Alternate between legacy and typed transactions and receipts
+		if i%2 == 1 {
+			tx = append([]byte{0x02}, tx...)
+			r = append([]byte{0x02}, r...)
+		}
+		transactions = append(transactions, tx)
+		receipts = append(receipts, r)
+	}
+
+	header := testHeader(t, DeriveRoot(transactions), DeriveRoot(receipts))
+	blockHash := crypto.Keccak256Hash(header)
+
+	if _, err := BuildReceiptProof(testHeader(t, DeriveRoot(transactions), common.Hash{}), transactions, receipts, 0, 0); err == nil {
+		t.Error("Expected an error for receipts of another block")
+	}
+	if _, err := BuildReceiptProof(header, transactions[1:], receipts, 0, 0); err == nil {
+		t.Error("Expected an error for a missing transaction")
+	}
+
+	for _, txIndex := range []uint64{0, 1, 15, 16, 127, 128, 129} {
+		proof, err := BuildReceiptProof(header, transactions, receipts, txIndex, 0)
+		if err != nil {
+			t.Fatalf("Failed to build proof for %d: %v", txIndex, err)
+		}
+		if proof.TxHash() != crypto.Keccak256Hash(transactions[txIndex]) {
+			t.Errorf("Expected transaction hash %s, got %s", crypto.Keccak256Hash(transactions[txIndex]).Hex(), proof.TxHash().Hex())
+		}
+
+		log, err := proof.Verify(blockHash)
+		if err != nil {
+			t.Fatalf("Failed to verify proof for %d: %v", txIndex, err)
+		}
+		if log.Topics[0] != topic || log.Data[0] != byte(txIndex) {
+			t.Errorf("Expected log of receipt %d, got data %x", txIndex, log.Data)
+		}
+	}
+
+	proof, _ := BuildReceiptProof(header, transactions, receipts, 5, 0)
+	if _, err := proof.Verify(common.Hash{}); err == nil {
+		t.Error("Expected proof against another block hash to fail")
+	}
+
+	proof.TxIndex = 6
+	if _, err := proof.Verify(blockHash); err == nil {
+		t.Error("Expected proof for another transaction index to fail")
+	}
+
+	proof, _ = BuildReceiptProof(header, transactions, receipts, 5, 0)
+	proof.Proof[len(proof.Proof)-1][10] ^= 0xff
+	if _, err := proof.Verify(blockHash); err == nil {
+		t.Error("Expected tampered proof to fail")
+	}
+
+	// The transaction is bound to the index of the receipt
+	proof, _ = BuildReceiptProof(header, transactions, receipts, 5, 0)
+	proof.Transaction = transactions[6]
+	if _, err := proof.Verify(blockHash); err == nil {
+		t.Error("Expected proof with another transaction to fail")
+	}
+
+	other, _ := BuildReceiptProof(header, transactions, receipts, 6, 0)
+	proof.TransactionProof = other.TransactionProof
+	if _, err := proof.Verify(blockHash); err == nil {
+		t.Error("Expected proof with the transaction proof of another index to fail")
+	}
+}
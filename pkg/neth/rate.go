@@ -0,0 +1,178 @@
+package neth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Selectors of the Chainlink aggregator view functions
+var (
+	selectorLatestRoundData = hexutil.MustDecode("0xfeaf968c") // latestRoundData()
+	selectorDecimals        = hexutil.MustDecode("0x313ce567") // decimals()
+)
+
+// ErrNoRate is returned by rate providers without a rate for a token
+var ErrNoRate = errors.New("no exchange rate for token")
+
+// FiatRate is the price of one whole token in a fiat currency
+type FiatRate struct {
+	Currency      string    `json:"currency"`       // ISO 4217 code, e.g. "EUR"
+	Rate          *big.Int  `json:"rate"`           // Price scaled by 10^Decimals
+	Decimals      int64     `json:"decimals"`       // Decimals of the rate
+	TokenDecimals int64     `json:"token_decimals"` // Decimals of the token, to convert amounts
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// String returns the rate as a decimal string
+func (r *FiatRate) String() string {
+	return FormatUnits(r.Rate, r.Decimals)
+}
+
+// Value returns the fiat value of an amount of the token as a decimal string
+func (r *FiatRate) Value(amount *big.Int) string {
+	value := new(big.Int).Mul(amount, r.Rate)
+	value.Quo(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(r.TokenDecimals), nil))
+	return FormatUnits(value, r.Decimals)
+}
+
+// RateProvider returns the exchange rate of a token to fiat at a time, ErrNoRate is returned for
+// tokens without a rate
+type RateProvider interface {
+	Rate(ctx context.Context, chainID, token string, at time.Time) (*FiatRate, error)
+}
+
+// rateKey returns the key of a token in the rate providers
+func rateKey(chainID, token string) string {
+	return chainID + ":" + strings.ToLower(token)
+}
+
+// StaticRateProvider is a RateProvider with fixed rates, e.g. for tests
+type StaticRateProvider struct {
+	mu    sync.RWMutex
+	rates map[string]FiatRate
+}
+
+// NewStaticRateProvider creates a rate provider without rates
+func NewStaticRateProvider() *StaticRateProvider {
+	return &StaticRateProvider{rates: make(map[string]FiatRate)}
+}
+
+// SetRate sets the rate of a token
+func (p *StaticRateProvider) SetRate(chainID, token string, rate FiatRate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rates[rateKey(chainID, token)] = rate
+}
+
+// Rate returns the rate of a token, whatever the time
+func (p *StaticRateProvider) Rate(ctx context.Context, chainID, token string, at time.Time) (*FiatRate, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	rate, ok := p.rates[rateKey(chainID, token)]
+	if !ok {
+		return nil, fmt.Errorf("%w %s on chain %s", ErrNoRate, token, chainID)
+	}
+	return &rate, nil
+}
+
+// ChainlinkFeed is a Chainlink price feed of a token
+type ChainlinkFeed struct {
+	Aggregator    common.Address // Address of the aggregator (or its proxy)
+	Currency      string         // Currency the feed is quoted in
+	TokenDecimals int64
+}
+
+// ChainlinkRateProvider is a RateProvider reading Chainlink price feeds with eth_call. The feeds
+// of every token are read on the chain of the node, e.g. the mainnet feeds of bridged tokens.
+type ChainlinkRateProvider struct {
+	client *http.Client
+	url    string
+
+	mu       sync.RWMutex
+	feeds    map[string]ChainlinkFeed
+	decimals map[common.Address]int64 // Decimals of the aggregators, they never change
+}
+
+// NewChainlinkRateProvider creates a rate provider for the JSON-RPC endpoint of a node, the
+// default HTTP client is used when client is nil
+func NewChainlinkRateProvider(client *http.Client, url string) *ChainlinkRateProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ChainlinkRateProvider{
+		client:   client,
+		url:      url,
+		feeds:    make(map[string]ChainlinkFeed),
+		decimals: make(map[common.Address]int64),
+	}
+}
+
+// AddFeed sets the price feed of a token
+func (p *ChainlinkRateProvider) AddFeed(chainID, token string, feed ChainlinkFeed) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.feeds[rateKey(chainID, token)] = feed
+}
+
+// Rate returns the latest answer of the feed of a token. Feeds only expose their latest round,
+// the time is not used and UpdatedAt tells when the answer was given.
+func (p *ChainlinkRateProvider) Rate(ctx context.Context, chainID, token string, at time.Time) (*FiatRate, error) {
+	p.mu.RLock()
+	feed, ok := p.feeds[rateKey(chainID, token)]
+	decimals, known := p.decimals[feed.Aggregator]
+	p.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %s on chain %s", ErrNoRate, token, chainID)
+	}
+
+	if !known {
+		result, err := ethCall(ctx, p.client, p.url, feed.Aggregator, selectorDecimals)
+		if err != nil {
+			return nil, err
+		}
+		if len(result) < 32 {
+			return nil, fmt.Errorf("invalid decimals result of %d bytes", len(result))
+		}
+		decimals = new(big.Int).SetBytes(result[:32]).Int64()
+
+		p.mu.Lock()
+		p.decimals[feed.Aggregator] = decimals
+		p.mu.Unlock()
+	}
+
+	// (roundId, answer, startedAt, updatedAt, answeredInRound)
+	result, err := ethCall(ctx, p.client, p.url, feed.Aggregator, selectorLatestRoundData)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 160 {
+		return nil, fmt.Errorf("invalid latestRoundData result of %d bytes", len(result))
+	}
+
+	answer := new(big.Int).SetBytes(result[32:64])
+	if answer.Sign() <= 0 || result[32]&0x80 != 0 {
+		return nil, fmt.Errorf("feed %s has no positive answer", feed.Aggregator.Hex())
+	}
+	updatedAt := new(big.Int).SetBytes(result[96:128]).Int64()
+
+	return &FiatRate{
+		Currency:      feed.Currency,
+		Rate:          answer,
+		Decimals:      decimals,
+		TokenDecimals: feed.TokenDecimals,
+		UpdatedAt:     time.Unix(updatedAt, 0).UTC(),
+	}, nil
+}
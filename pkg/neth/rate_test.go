@@ -0,0 +1,72 @@
+package neth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestChainlinkRateProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		var call struct {
+			Data string `json:"data"`
+		}
+		json.Unmarshal(request.Params[0], &call)
+
+		var result []byte
+		switch {
+		case strings.HasPrefix(call.Data, "0x313ce567"):
+			result = common.LeftPadBytes([]byte{8}, 32)
+		case strings.HasPrefix(call.Data, "0xfeaf968c"):
+			result = append(result, common.LeftPadBytes([]byte{1}, 32)...)
+			result = append(result, common.LeftPadBytes(big.NewInt(92000000).Bytes(), 32)...) // 0.92
+			result = append(result, common.LeftPadBytes(big.NewInt(1700000000).Bytes(), 32)...)
+			result = append(result, common.LeftPadBytes(big.NewInt(1700000000).Bytes(), 32)...)
+			result = append(result, common.LeftPadBytes([]byte{1}, 32)...)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": hexutil.Encode(result)})
+	}))
+	defer server.Close()
+
+	token := "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	provider := NewChainlinkRateProvider(server.Client(), server.URL)
+	provider.AddFeed("100", token, ChainlinkFeed{
+		Aggregator:    common.HexToAddress("0xb49f677943BC038e9857d61E7d053CaA2C1734C1"),
+		Currency:      "EUR",
+		TokenDecimals: 18,
+	})
+
+	rate, err := provider.Rate(context.Background(), "100", strings.ToLower(token), time.Now())
+	if err != nil {
+		t.Fatalf("Failed to get rate: %v", err)
+	}
+	if rate.Currency != "EUR" || rate.String() != "0.92" {
+		t.Errorf("Expected 0.92 EUR, got %s %s", rate, rate.Currency)
+	}
+	if rate.UpdatedAt.Unix() != 1700000000 {
+		t.Errorf("Expected update time 1700000000, got %d", rate.UpdatedAt.Unix())
+	}
+
+	amount, _ := new(big.Int).SetString("2500000000000000000", 10)
+	if value := rate.Value(amount); value != "2.3" {
+		t.Errorf("Expected value 2.3, got %s", value)
+	}
+
+	if _, err := provider.Rate(context.Background(), "1", token, time.Now()); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Expected ErrNoRate for a token without feed, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package neth
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SessionPermission is an operation a session key is allowed to perform
+type SessionPermission struct {
+	Target     common.Address `json:"target"`
+	Selector   hexutil.Bytes  `json:"selector,omitempty"`    // Empty allows any function of the target
+	ValueLimit *big.Int       `json:"value_limit,omitempty"` // Maximum native value per call, nil for no limit
+}
+
+// SessionKey is a key granted scoped permissions on a smart account
+type SessionKey struct {
+	Account     common.Address      `json:"account"`
+	Key         common.Address      `json:"key"`
+	Module      *common.Address     `json:"module,omitempty"` // Module enforcing the permissions, if any
+	Permissions []SessionPermission `json:"permissions"`
+	ValidAfter  time.Time           `json:"valid_after"`
+	ValidUntil  time.Time           `json:"valid_until"`
+	Revoked     bool                `json:"revoked,omitempty"`
+}
+
+// IsActive checks if the session key can be used at the given time
+func (s *SessionKey) IsActive(now time.Time) bool {
+	if s.Revoked {
+		return false
+	}
+	if !s.ValidAfter.IsZero() && now.Before(s.ValidAfter) {
+		return false
+	}
+	if !s.ValidUntil.IsZero() && !now.Before(s.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// Permits checks if the session key allows calling a function of a target with a given value
+func (s *SessionKey) Permits(target common.Address, selector []byte, value *big.Int, now time.Time) bool {
+	if !s.IsActive(now) {
+		return false
+	}
+
+	for _, permission := range s.Permissions {
+		if permission.Target != target {
+			continue
+		}
+		if len(permission.Selector) > 0 && !bytes.Equal(permission.Selector, selector) {
+			continue
+		}
+		if permission.ValueLimit != nil && value != nil && value.Cmp(permission.ValueLimit) > 0 {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
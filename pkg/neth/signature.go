@@ -0,0 +1,53 @@
+package neth
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RecoverAddress recovers the address that signed a 32 byte digest with a 65 byte [R || S || V]
+// signature, V may be 0/1 or 27/28
+func RecoverAddress(digest []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes, got %d", crypto.SignatureLength, len(signature))
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubkey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %w", err)
+	}
+
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// MessageHash returns the digest signed by personal_sign (EIP-191) for a message
+func MessageHash(message []byte) []byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return crypto.Keccak256([]byte(prefix), message)
+}
+
+// RecoverMessageAddress recovers the address that signed a message with personal_sign (EIP-191)
+func RecoverMessageAddress(message []byte, signature []byte) (common.Address, error) {
+	return RecoverAddress(MessageHash(message), signature)
+}
+
+// VerifyHashSignature checks that a hash, e.g. a user operation hash, was signed by an address,
+// either directly or with personal_sign as most smart accounts expect
+func VerifyHashSignature(hash common.Hash, signature []byte, signer common.Address) bool {
+	if address, err := RecoverMessageAddress(hash.Bytes(), signature); err == nil && address == signer {
+		return true
+	}
+	if address, err := RecoverAddress(hash.Bytes(), signature); err == nil && address == signer {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package neth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifyHashSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	other, _ := crypto.GenerateKey()
+	hash := crypto.Keccak256Hash([]byte("user op"))
+
+	direct, _ := crypto.Sign(hash.Bytes(), key)
+	personal, _ := crypto.Sign(MessageHash(hash.Bytes()), key)
+	personal[crypto.RecoveryIDOffset] += 27 // As returned by wallets
+	forged, _ := crypto.Sign(hash.Bytes(), other)
+
+	testCases := []struct {
+		name      string
+		signature []byte
+		expected  bool
+	}{
+		{"direct", direct, true},
+		{"personal_sign", personal, true},
+		{"other signer", forged, false},
+		{"truncated", direct[:64], false},
+		{"empty", nil, false},
+	}
+
+	for _, tc := range testCases {
+		if valid := VerifyHashSignature(hash, tc.signature, signer); valid != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, valid)
+		}
+	}
+
+	// The digest wallets sign for personal_sign("hello")
+	if hex := common.Bytes2Hex(MessageHash([]byte("hello"))); hex != "50b2c43fd39106bafbba0da34fc430e1f91e3c96ea2acee2bc34119f92b37750" {
+		t.Errorf("Expected the EIP-191 hash of hello, got %s", hex)
+	}
+
+	if VerifyHashSignature(common.Hash{}, direct, signer) {
+		t.Error("Expected a signature of another hash to be rejected")
+	}
+}
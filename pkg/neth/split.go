@@ -0,0 +1,122 @@
+package neth
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SplitDenominator is the total of the basis points of a split, 100%
+const SplitDenominator = 10000
+
+// Split is the share of a payment going to a recipient, in basis points
+type Split struct {
+	Recipient string `json:"recipient"` // Address
+	BPS       uint16 `json:"bps"`
+}
+
+// ValidateSplits checks that the splits go to distinct recipients and add up to 100%
+func ValidateSplits(splits []Split) error {
+	if len(splits) == 0 {
+		return fmt.Errorf("split has no recipients")
+	}
+
+	seen := make(map[string]bool, len(splits))
+	total := 0
+	for _, split := range splits {
+		if !common.IsHexAddress(split.Recipient) {
+			return fmt.Errorf("invalid split recipient: %s", split.Recipient)
+		}
+
+		recipient := strings.ToLower(split.Recipient)
+		if seen[recipient] {
+			return fmt.Errorf("split recipient %s is listed twice", split.Recipient)
+		}
+		seen[recipient] = true
+
+		if split.BPS == 0 {
+			return fmt.Errorf("split of %s is empty", split.Recipient)
+		}
+		total += int(split.BPS)
+	}
+
+	if total != SplitDenominator {
+		return fmt.Errorf("splits add up to %d bps, not %d", total, SplitDenominator)
+	}
+
+	return nil
+}
+
+// SplitAmounts returns the amount of each recipient of a payment, in the order of the splits.
+// Amounts are rounded down and the remainder goes to the first recipient, so that the amounts
+// always add up to the total.
+func SplitAmounts(total *big.Int, splits []Split) ([]*big.Int, error) {
+	if err := ValidateSplits(splits); err != nil {
+		return nil, err
+	}
+	if total == nil || total.Sign() < 0 {
+		return nil, fmt.Errorf("split total must not be negative")
+	}
+
+	amounts := make([]*big.Int, len(splits))
+	remainder := new(big.Int).Set(total)
+	for i, split := range splits {
+		amounts[i] = new(big.Int).Mul(total, big.NewInt(int64(split.BPS)))
+		amounts[i].Quo(amounts[i], big.NewInt(SplitDenominator))
+		remainder.Sub(remainder, amounts[i])
+	}
+	amounts[0].Add(amounts[0], remainder)
+
+	return amounts, nil
+}
+
+// VerifySplitTransfers checks that the transfer logs of a batch transaction paid each recipient
+// of a split its amount of the total, in the given token. Transfers to the same recipient are
+// added up, transfers of other tokens or to other addresses are ignored.
+func VerifySplitTransfers(token string, total *big.Int, splits []Split, logs []Log) error {
+	amounts, err := SplitAmounts(total, splits)
+	if err != nil {
+		return err
+	}
+
+	paid := make(map[string]*big.Int)
+	txHash := ""
+	for _, log := range logs {
+		if log.Topic != TopicERC20Transfer || !strings.EqualFold(log.To, token) {
+			continue
+		}
+		if txHash != "" && !strings.EqualFold(log.TxHash, txHash) {
+			return fmt.Errorf("transfers belong to transactions %s and %s, not one batch", txHash, log.TxHash)
+		}
+		txHash = log.TxHash
+
+		data, err := log.GetTransferData()
+		if err != nil || data == nil {
+			return fmt.Errorf("log %s has no transfer data", log.Hash)
+		}
+		value, ok := new(big.Int).SetString(data.Value, 10)
+		if !ok {
+			return fmt.Errorf("log %s has an invalid value: %s", log.Hash, data.Value)
+		}
+
+		recipient := strings.ToLower(data.To)
+		if paid[recipient] == nil {
+			paid[recipient] = new(big.Int)
+		}
+		paid[recipient].Add(paid[recipient], value)
+	}
+
+	for i, split := range splits {
+		got := paid[strings.ToLower(split.Recipient)]
+		if got == nil {
+			got = new(big.Int)
+		}
+		if got.Cmp(amounts[i]) != 0 {
+			return fmt.Errorf("%s was paid %s, its split is %s", split.Recipient, got, amounts[i])
+		}
+	}
+
+	return nil
+}
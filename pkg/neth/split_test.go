@@ -0,0 +1,59 @@
+package neth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// splitLog returns a transfer log of a batch transaction
+func splitLog(token, to string, value int64) Log {
+	data := json.RawMessage(fmt.Sprintf(`{"from":"0x1111111111111111111111111111111111111111","to":"%s","value":"%d"}`, to, value))
+	return Log{Hash: fmt.Sprintf("0x%x", value), TxHash: "0xbatch", ChainID: "100", Topic: TopicERC20Transfer, To: token, Data: &data}
+}
+
+func TestSplits(t *testing.T) {
+	token := "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	alice := "0x2222222222222222222222222222222222222222"
+	bob := "0x3333333333333333333333333333333333333333"
+	splits := []Split{{Recipient: alice, BPS: 3333}, {Recipient: bob, BPS: 6667}}
+
+	amounts, err := SplitAmounts(big.NewInt(100), splits)
+	if err != nil {
+		t.Fatalf("Failed to split: %v", err)
+	}
+	if amounts[0].Int64() != 34 || amounts[1].Int64() != 66 {
+		t.Errorf("Expected 34/66 with the remainder to the first recipient, got %s/%s", amounts[0], amounts[1])
+	}
+
+	for _, invalid := range [][]Split{
+		nil,
+		{{Recipient: alice, BPS: 5000}},
+		{{Recipient: alice, BPS: 5000}, {Recipient: alice, BPS: 5000}},
+		{{Recipient: "alice", BPS: 10000}},
+		{{Recipient: alice, BPS: 10000}, {Recipient: bob, BPS: 0}},
+	} {
+		if err := ValidateSplits(invalid); err == nil {
+			t.Errorf("Expected an error for %v", invalid)
+		}
+	}
+
+	batch := []Log{splitLog(token, alice, 30), splitLog(token, alice, 4), splitLog(token, bob, 66)}
+	if err := VerifySplitTransfers(token, big.NewInt(100), splits, batch); err != nil {
+		t.Errorf("Expected the batch to satisfy the split, got %v", err)
+	}
+
+	// Another token does not count
+	batch[2] = splitLog("0x4444444444444444444444444444444444444444", bob, 66)
+	if err := VerifySplitTransfers(token, big.NewInt(100), splits, batch); err == nil {
+		t.Error("Expected an error for a recipient paid in another token")
+	}
+
+	// Transfers of another transaction
+	batch[2] = splitLog(token, bob, 66)
+	batch[2].TxHash = "0xother"
+	if err := VerifySplitTransfers(token, big.NewInt(100), splits, batch); err == nil {
+		t.Error("Expected an error for transfers of several transactions")
+	}
+}
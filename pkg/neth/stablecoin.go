@@ -0,0 +1,35 @@
+package neth
+
+// Stablecoin is a token pegged to a fiat currency
+type Stablecoin struct {
+	ChainID  int64  `json:"chainId"`
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals int64  `json:"decimals"`
+	Currency string `json:"currency"` // ISO 4217 code of the peg, e.g. "USD"
+}
+
+// KnownStablecoins are widely used stablecoins of the main chains
+var KnownStablecoins = []Stablecoin{
+	// Ethereum
+	{ChainID: 1, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+	{ChainID: 1, Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Symbol: "USDT", Decimals: 6, Currency: "USD"},
+	{ChainID: 1, Address: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Symbol: "DAI", Decimals: 18, Currency: "USD"},
+	{ChainID: 1, Address: "0x1aBaEA1f7C830bD89Acc67eC4af516284b1bC33c", Symbol: "EURC", Decimals: 6, Currency: "EUR"},
+
+	// Optimism
+	{ChainID: 10, Address: "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+
+	// Gnosis
+	{ChainID: 100, Address: "0xDDAfbb505ad214D7b80b1f830fcCc89B60fb7A83", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+	{ChainID: 100, Address: "0xcB444e90D8198415266c6a2724b7900fb12FC56E", Symbol: "EURe", Decimals: 18, Currency: "EUR"},
+
+	// Polygon
+	{ChainID: 137, Address: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+
+	// Base
+	{ChainID: 8453, Address: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+
+	// Arbitrum
+	{ChainID: 42161, Address: "0xaf88d065e77c8cC2239327C5EDb3A432268e5831", Symbol: "USDC", Decimals: 6, Currency: "USD"},
+}
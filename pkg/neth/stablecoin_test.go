@@ -0,0 +1,26 @@
+package neth
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestKnownStablecoins(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, coin := range KnownStablecoins {
+		if !common.IsHexAddress(coin.Address) || common.HexToAddress(coin.Address).Hex() != coin.Address {
+			t.Errorf("Expected a checksummed address for %s on chain %d, got %s", coin.Symbol, coin.ChainID, coin.Address)
+		}
+		if len(coin.Currency) != 3 {
+			t.Errorf("Expected an ISO 4217 currency for %s on chain %d, got %q", coin.Symbol, coin.ChainID, coin.Currency)
+		}
+
+		key := fmt.Sprintf("%d:%s", coin.ChainID, coin.Address)
+		if seen[key] {
+			t.Errorf("Expected %s on chain %d once", coin.Symbol, coin.ChainID)
+		}
+		seen[key] = true
+	}
+}
@@ -0,0 +1,90 @@
+package neth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenInfo is a token entry of a token list
+type TokenInfo struct {
+	ChainID  int64    `json:"chainId"`
+	Address  string   `json:"address"`
+	Name     string   `json:"name"`
+	Symbol   string   `json:"symbol"`
+	Decimals int64    `json:"decimals"`
+	LogoURI  string   `json:"logoURI,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// TokenListVersion is the semantic version of a token list
+type TokenListVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// TokenList is a list of tokens in the Uniswap token list format (https://tokenlists.org)
+type TokenList struct {
+	Name      string           `json:"name"`
+	Timestamp string           `json:"timestamp"`
+	Version   TokenListVersion `json:"version"`
+	Tokens    []TokenInfo      `json:"tokens"`
+	Keywords  []string         `json:"keywords,omitempty"`
+	LogoURI   string           `json:"logoURI,omitempty"`
+}
+
+// ParseTokenList parses a token list and checks the addresses of its tokens
+func ParseTokenList(data []byte) (*TokenList, error) {
+	var list TokenList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse token list: %w", err)
+	}
+
+	for _, token := range list.Tokens {
+		if !common.IsHexAddress(token.Address) {
+			return nil, fmt.Errorf("invalid token address in list %s: %s", list.Name, token.Address)
+		}
+	}
+
+	return &list, nil
+}
+
+// LoadTokenList reads and parses a token list
+func LoadTokenList(r io.Reader) (*TokenList, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseTokenList(data)
+}
+
+// FetchTokenList downloads and parses a token list, the default HTTP client is used when client
+// is nil
+func FetchTokenList(ctx context.Context, client *http.Client, url string) (*TokenList, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch token list: %s", resp.Status)
+	}
+
+	return LoadTokenList(resp.Body)
+}
@@ -0,0 +1,37 @@
+package neth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchTokenList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tokens.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"name":"Test","timestamp":"2024-01-01T00:00:00Z","version":{"major":1},"tokens":[{"chainId":100,"address":"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d","name":"Wrapped XDAI","symbol":"WXDAI","decimals":18}]}`))
+	}))
+	defer server.Close()
+
+	list, err := FetchTokenList(context.Background(), server.Client(), server.URL+"/tokens.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch token list: %v", err)
+	}
+	if len(list.Tokens) != 1 || list.Tokens[0].Symbol != "WXDAI" {
+		t.Errorf("Expected the WXDAI token, got %+v", list.Tokens)
+	}
+
+	if _, err := FetchTokenList(context.Background(), server.Client(), server.URL+"/missing.json"); err == nil {
+		t.Error("Expected an error for a missing list")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FetchTokenList(ctx, server.Client(), server.URL+"/tokens.json"); err == nil {
+		t.Error("Expected an error for a cancelled context")
+	}
+}
@@ -0,0 +1,124 @@
+package neth
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Topics of the events of common standards, the keccak256 hash of the event signature
+const (
+	// ERC20, ERC721 shares the Transfer and Approval signatures with an indexed token ID
+	TopicERC20Transfer  = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" // Transfer(address,address,uint256)
+	TopicERC20Approval  = "0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925" // Approval(address,address,uint256)
+	TopicERC721Transfer = TopicERC20Transfer
+	TopicERC721Approval = TopicERC20Approval
+	TopicApprovalForAll = "0x17307eab39ab6107e8899845ad3d59bd9653f200f220920489ca2b5937696c31" // ApprovalForAll(address,address,bool), ERC721 and ERC1155
+	TopicERC1155Single  = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62" // TransferSingle(address,address,address,uint256,uint256)
+	TopicERC1155Batch   = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb" // TransferBatch(address,address,address,uint256[],uint256[])
+	TopicERC1155URI     = "0x6bb7ff708619ba0610cba295a58592e0451dee2622938c8755667688daf3529b" // URI(string,uint256)
+	TopicWETHDeposit    = "0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c" // Deposit(address,uint256)
+	TopicWETHWithdrawal = "0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65" // Withdrawal(address,uint256)
+	TopicUniswapV2Swap  = "0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822" // Swap(address,uint256,uint256,uint256,uint256,address)
+	TopicUniswapV3Swap  = "0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67" // Swap(address,address,int256,int256,uint160,uint128,int24)
+
+	// Uniswap Permit2, signature based allowances of any ERC20 token
+	TopicPermit2Approval          = "0xda9fa7c1b00402c17d0161b249b1ab8bbec047c5a52207b9c112deffd817036b" // Approval(address,address,address,uint160,uint48)
+	TopicPermit2Permit            = "0xc6a377bfc4eb120024a8ac08eef205be16b817020812c73223e81d1bdb9708ec" // Permit(address,address,address,uint160,uint48,uint48)
+	TopicPermit2Lockdown          = "0x89b1add15eff56b3dfe299ad94e01f2b52fbcb80ae1a3baea6ae8c04cb2b98a4" // Lockdown(address,address,address)
+	TopicPermit2NonceInvalidation = "0x55eb90d810e1700b35a8e7e25395ff7f2b2259abd7415ca2284dfb1c246418f3" // NonceInvalidation(address,address,address,uint48,uint48)
+
+	// ERC-4337 EntryPoint, v0.6 and v0.7
+	TopicUserOperationEvent        = "0x49628fd1471006c1482da88028e9ce4dbb080b815c9b0344d39e5a8e6ec1419f" // UserOperationEvent(bytes32,address,address,uint256,bool,uint256,uint256)
+	TopicAccountDeployed           = "0xd51a9c61267aa6196961883ecf5ff2da6619c37dac0fa92122513fb32c032d2d" // AccountDeployed(bytes32,address,address,address)
+	TopicUserOperationRevertReason = "0x1c4fada7374c0a9ee8841fc38afe82932dc0f8e69012e927f061a8bae611a201" // UserOperationRevertReason(bytes32,address,uint256,bytes)
+	TopicPostOpRevertReason        = "0xf62676f440ff169a3a9afdbf812e89e7f95975ee8e5c31214ffdef631c5f4792" // PostOpRevertReason(bytes32,address,uint256,bytes), v0.7
+	TopicBeforeExecution           = "0xbb47ee3e183a558b1a2ff0874b079f3fc5478b7454eacf2bfc5af2ff5878f972" // BeforeExecution()
+	TopicEntryPointDeposited       = "0x2da466a7b24304f47e87fa2e1e5a81b9831ce54fec19055ce277ca2f39ba42c4" // Deposited(address,uint256)
+	TopicEntryPointWithdrawn       = "0xd1c19fbcd4551a5edfb66d43d2e337c04837afda3482b42bdf569a8fccdae5fb" // Withdrawn(address,address,uint256)
+
+	// Safe, v1.3 and v1.4
+	TopicSafeSetup                  = "0x141df868a6331af528e38c83b7aa03edc19be66e37ae67f9285bf4f8e3c6a1a8" // SafeSetup(address,address[],uint256,address,address)
+	TopicSafeExecutionSuccess       = "0x442e715f626346e8c54381002da614f62bee8d27386535b2521ec8540898556e" // ExecutionSuccess(bytes32,uint256)
+	TopicSafeExecutionFailure       = "0x23428b18acfb3ea64b08dc0c1d296ea9c09702c09083ca5272e64d115b687d23" // ExecutionFailure(bytes32,uint256)
+	TopicSafeAddedOwner             = "0x9465fa0c962cc76958e6373a993326400c1c94f8be2fe3a952adfa7f60b2ea26" // AddedOwner(address)
+	TopicSafeRemovedOwner           = "0xf8d49fc529812e9a7c5c50e69c20f0dccc0db8fa95c98bc58cc9a4f1c1299eaf" // RemovedOwner(address)
+	TopicSafeChangedThreshold       = "0x610f7ff2b304ae8903c3de74c60c6ab1f7d6226b3f52c5161905bb5ad4039c93" // ChangedThreshold(uint256)
+	TopicSafeReceived               = "0x3d0ce9bfc3ed7d6862dbb28b2dea94561fe714a1b4d019aa8af39730d1ad7c3d" // SafeReceived(address,uint256)
+	TopicSafeEnabledModule          = "0xecdf3a3effea5783a3c4c2140e677577666428d44ed9d474a0b3a4c9943f8440" // EnabledModule(address)
+	TopicSafeDisabledModule         = "0xaab4fa2b463f581b2b32cb3b7e3b704b9ce37cc209b5fb4d77e593ace4054276" // DisabledModule(address)
+	TopicSafeModuleExecutionSuccess = "0x6895c13664aa4f67288b25d7a21d7aaa34916e355fb9b6fae0a139a9085becb8" // ExecutionFromModuleSuccess(address)
+	TopicSafeModuleExecutionFailure = "0xacd2c8702804128fdb0db2bb49f6d127dd0181c13fd45dbfe16de0930e2bd375" // ExecutionFromModuleFailure(address)
+)
+
+// topicNames names the curated topics, topics shared by several standards are named after the
+// first. Names match the t tags of the default entries of the topic registry.
+var topicNames = []struct {
+	name  string
+	topic string
+}{
+	{"erc20_transfer", TopicERC20Transfer},
+	{"erc721_transfer", TopicERC721Transfer},
+	{"erc20_approval", TopicERC20Approval},
+	{"erc721_approval", TopicERC721Approval},
+	{"approval_for_all", TopicApprovalForAll},
+	{"erc1155_transfer_single", TopicERC1155Single},
+	{"erc1155_transfer_batch", TopicERC1155Batch},
+	{"erc1155_uri", TopicERC1155URI},
+	{"weth_deposit", TopicWETHDeposit},
+	{"weth_withdrawal", TopicWETHWithdrawal},
+	{"uniswap_v2_swap", TopicUniswapV2Swap},
+	{"uniswap_v3_swap", TopicUniswapV3Swap},
+	{"permit2_approval", TopicPermit2Approval},
+	{"permit2_permit", TopicPermit2Permit},
+	{"permit2_lockdown", TopicPermit2Lockdown},
+	{"permit2_nonce_invalidation", TopicPermit2NonceInvalidation},
+	{"user_operation_event", TopicUserOperationEvent},
+	{"account_deployed", TopicAccountDeployed},
+	{"user_operation_revert_reason", TopicUserOperationRevertReason},
+	{"post_op_revert_reason", TopicPostOpRevertReason},
+	{"before_execution", TopicBeforeExecution},
+	{"entry_point_deposited", TopicEntryPointDeposited},
+	{"entry_point_withdrawn", TopicEntryPointWithdrawn},
+	{"safe_setup", TopicSafeSetup},
+	{"safe_execution_success", TopicSafeExecutionSuccess},
+	{"safe_execution_failure", TopicSafeExecutionFailure},
+	{"safe_added_owner", TopicSafeAddedOwner},
+	{"safe_removed_owner", TopicSafeRemovedOwner},
+	{"safe_changed_threshold", TopicSafeChangedThreshold},
+	{"safe_received", TopicSafeReceived},
+	{"safe_enabled_module", TopicSafeEnabledModule},
+	{"safe_disabled_module", TopicSafeDisabledModule},
+	{"safe_module_execution_success", TopicSafeModuleExecutionSuccess},
+	{"safe_module_execution_failure", TopicSafeModuleExecutionFailure},
+}
+
+// TopicName returns the name of a curated topic, e.g. "erc20_transfer", false when the topic is
+// not curated
+func TopicName(topic string) (string, bool) {
+	for _, entry := range topicNames {
+		if strings.EqualFold(entry.topic, topic) {
+			return entry.name, true
+		}
+	}
+	return "", false
+}
+
+// NameToTopic returns the topic of a curated name, e.g. "erc721_transfer", or of an event
+// signature, e.g. "Transfer(address,address,uint256)". False is returned for unknown names.
+func NameToTopic(name string) (string, bool) {
+	if strings.Contains(name, "(") {
+		return EventTopic(name), true
+	}
+	for _, entry := range topicNames {
+		if strings.EqualFold(entry.name, name) {
+			return entry.topic, true
+		}
+	}
+	return "", false
+}
+
+// EventTopic returns the topic of an event signature, without spaces or parameter names
+func EventTopic(signature string) string {
+	return crypto.Keccak256Hash([]byte(signature)).Hex()
+}
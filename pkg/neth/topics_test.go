@@ -0,0 +1,31 @@
+package neth
+
+import "testing"
+
+func TestTopicNames(t *testing.T) {
+	for _, entry := range topicNames {
+		if len(entry.topic) != 66 {
+			t.Errorf("Expected a 32 byte topic for %s, got %s", entry.name, entry.topic)
+		}
+		if topic, ok := NameToTopic(entry.name); !ok || topic != entry.topic {
+			t.Errorf("Expected %s for %s, got %s", entry.topic, entry.name, topic)
+		}
+	}
+
+	if name, ok := TopicName("0xDDF252AD1BE2C89B69C2B068FC378DAA952BA7F163C4A11628F55A4DF523B3EF"); !ok || name != "erc20_transfer" {
+		t.Errorf("Expected erc20_transfer, got %s", name)
+	}
+	if _, ok := TopicName("0x01"); ok {
+		t.Error("Expected an unknown topic")
+	}
+
+	if topic, ok := NameToTopic("TransferSingle(address,address,address,uint256,uint256)"); !ok || topic != TopicERC1155Single {
+		t.Errorf("Expected the TransferSingle topic, got %s", topic)
+	}
+	if EventTopic("ExecutionSuccess(bytes32,uint256)") != TopicSafeExecutionSuccess {
+		t.Error("Expected the ExecutionSuccess topic")
+	}
+	if _, ok := NameToTopic("unknown"); ok {
+		t.Error("Expected an unknown name")
+	}
+}
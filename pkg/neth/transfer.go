@@ -0,0 +1,100 @@
+package neth
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+)
+
+type TransferDirection string
+
+const (
+	TransferDirectionIn   TransferDirection = "in"
+	TransferDirectionOut  TransferDirection = "out"
+	TransferDirectionSelf TransferDirection = "self"
+	TransferDirectionNone TransferDirection = "none"
+)
+
+// GetTransferData extracts the transfer data (from, to, value) from the log data
+func (t *Log) GetTransferData() (*LogTransferData, error) {
+	if t.Data == nil {
+		return nil, nil
+	}
+
+	var data LogTransferData
+	err := json.Unmarshal(*t.Data, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// parties returns the sender and recipient of the transfer, falling back to the
+// log sender and recipient when the data does not describe a transfer
+func (t *Log) parties() (string, string) {
+	data, err := t.GetTransferData()
+	if err == nil && data != nil && data.From != "" && data.To != "" {
+		return data.From, data.To
+	}
+
+	return t.Sender, t.To
+}
+
+// Direction returns the direction of the transfer from the point of view of the given address
+func (t *Log) Direction(address string) TransferDirection {
+	from, to := t.parties()
+
+	isFrom := strings.EqualFold(from, address)
+	isTo := strings.EqualFold(to, address)
+
+	switch {
+	case isFrom && isTo:
+		return TransferDirectionSelf
+	case isFrom:
+		return TransferDirectionOut
+	case isTo:
+		return TransferDirectionIn
+	default:
+		return TransferDirectionNone
+	}
+}
+
+// Counterparty returns the other side of the transfer from the point of view of the given address,
+// an empty string is returned if the address is not part of the transfer
+func (t *Log) Counterparty(address string) string {
+	from, to := t.parties()
+
+	switch t.Direction(address) {
+	case TransferDirectionSelf:
+		return address
+	case TransferDirectionOut:
+		return to
+	case TransferDirectionIn:
+		return from
+	default:
+		return ""
+	}
+}
+
+// GetAmount returns the transferred amount, taken from the transfer data when present
+// and from the log value otherwise
+func (t *Log) GetAmount() *big.Int {
+	data, err := t.GetTransferData()
+	if err == nil && data != nil && data.Value != "" {
+		if amount, ok := new(big.Int).SetString(data.Value, 10); ok {
+			return amount
+		}
+	}
+
+	if t.Value == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(t.Value)
+}
+
+// FormatAmount formats the transferred amount as a decimal string using the token decimals
+func (t *Log) FormatAmount(decimals int64) string {
+	return FormatUnits(t.GetAmount(), decimals)
+}
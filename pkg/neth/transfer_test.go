@@ -0,0 +1,76 @@
+package neth
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func transferLog(from, to, value string, logValue *big.Int) *Log {
+	log := &Log{Sender: "0x9999999999999999999999999999999999999999", To: "0x8888888888888888888888888888888888888888", Value: logValue}
+	if from != "" || to != "" || value != "" {
+		raw, _ := json.Marshal(LogTransferData{From: from, To: to, Value: value})
+		data := json.RawMessage(raw)
+		log.Data = &data
+	}
+	return log
+}
+
+func TestTransferDirection(t *testing.T) {
+	alice := "0xAbCdEf0000000000000000000000000000000001"
+	bob := "0x0000000000000000000000000000000000000002"
+
+	testCases := []struct {
+		name         string
+		log          *Log
+		address      string
+		direction    TransferDirection
+		counterparty string
+	}{
+		{"out", transferLog(alice, bob, "1", nil), alice, TransferDirectionOut, bob},
+		{"in", transferLog(alice, bob, "1", nil), bob, TransferDirectionIn, alice},
+		{"mixed case", transferLog(alice, bob, "1", nil), "0xabcdef0000000000000000000000000000000001", TransferDirectionOut, bob},
+		{"self", transferLog(alice, alice, "1", nil), alice, TransferDirectionSelf, alice},
+		{"none", transferLog(alice, bob, "1", nil), "0x0000000000000000000000000000000000000003", TransferDirectionNone, ""},
+		{"log parties", transferLog("", "", "", nil), "0x9999999999999999999999999999999999999999", TransferDirectionOut, "0x8888888888888888888888888888888888888888"},
+	}
+
+	for _, tc := range testCases {
+		if direction := tc.log.Direction(tc.address); direction != tc.direction {
+			t.Errorf("%s: expected direction %s, got %s", tc.name, tc.direction, direction)
+		}
+		if counterparty := tc.log.Counterparty(tc.address); counterparty != tc.counterparty {
+			t.Errorf("%s: expected counterparty %s, got %s", tc.name, tc.counterparty, counterparty)
+		}
+	}
+}
+
+func TestTransferAmount(t *testing.T) {
+	testCases := []struct {
+		name      string
+		log       *Log
+		amount    string
+		formatted string
+	}{
+		{"data value", transferLog("0x1", "0x2", "1500000", big.NewInt(1)), "1500000", "1.5"},
+		{"log value", transferLog("", "", "", big.NewInt(2500000)), "2500000", "2.5"},
+		{"invalid data value", transferLog("0x1", "0x2", "abc", big.NewInt(3000000)), "3000000", "3"},
+		{"nil value", transferLog("", "", "", nil), "0", "0"},
+	}
+
+	for _, tc := range testCases {
+		if amount := tc.log.GetAmount(); amount.String() != tc.amount {
+			t.Errorf("%s: expected amount %s, got %s", tc.name, tc.amount, amount)
+		}
+		if formatted := tc.log.FormatAmount(6); formatted != tc.formatted {
+			t.Errorf("%s: expected formatted amount %s, got %s", tc.name, tc.formatted, formatted)
+		}
+	}
+
+	// The amount is a copy of the log value
+	log := transferLog("", "", "", big.NewInt(1))
+	log.GetAmount().SetInt64(2)
+	if log.Value.Int64() != 1 {
+		t.Errorf("Expected the log value to be unchanged, got %s", log.Value)
+	}
+}
@@ -0,0 +1,109 @@
+package neth
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	DecimalsEther = 18
+	DecimalsGwei  = 9
+)
+
+// FormatUnits formats an integer amount as a decimal string with the given number of decimals,
+// trailing zeros in the fraction are removed
+func FormatUnits(amount *big.Int, decimals int64) string {
+	if amount == nil {
+		return "0"
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+
+	if decimals > 0 {
+		if int64(len(digits)) <= decimals {
+			digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+		}
+
+		whole := digits[:int64(len(digits))-decimals]
+		fraction := strings.TrimRight(digits[int64(len(digits))-decimals:], "0")
+
+		digits = whole
+		if fraction != "" {
+			digits += "." + fraction
+		}
+	}
+
+	if negative {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// ParseUnits parses a decimal string into an integer amount with the given number of decimals,
+// an error is returned if the value has more fractional digits than decimals allows
+func ParseUnits(value string, decimals int64) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("value cannot be empty")
+	}
+
+	negative := false
+	switch value[0] {
+	case '-':
+		negative = true
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return nil, fmt.Errorf("invalid value: %s", value)
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if int64(len(fraction)) > decimals {
+		return nil, fmt.Errorf("value %s has more than %d decimals", value, decimals)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	for _, char := range digits {
+		if char < '0' || char > '9' {
+			return nil, fmt.Errorf("invalid value: %s", value)
+		}
+	}
+
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid value: %s", value)
+	}
+
+	if negative {
+		amount.Neg(amount)
+	}
+
+	return amount, nil
+}
+
+// FormatEther formats an amount in wei as ether
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, DecimalsEther)
+}
+
+// ParseEther parses an amount in ether into wei
+func ParseEther(value string) (*big.Int, error) {
+	return ParseUnits(value, DecimalsEther)
+}
+
+// FormatGwei formats an amount in wei as gwei
+func FormatGwei(wei *big.Int) string {
+	return FormatUnits(wei, DecimalsGwei)
+}
+
+// ParseGwei parses an amount in gwei into wei
+func ParseGwei(value string) (*big.Int, error) {
+	return ParseUnits(value, DecimalsGwei)
+}
@@ -0,0 +1,75 @@
+package neth
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatUnits(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		decimals int64
+		expected string
+	}{
+		{"1000000000000000000", 18, "1"},
+		{"1500000", 6, "1.5"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 18, "0"},
+		{"-25", 1, "-2.5"},
+		{"123", 0, "123"},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 18, "115792089237316195423570985008687907853269984665640564039457.584007913129639935"},
+	}
+
+	for _, tc := range testCases {
+		amount, _ := new(big.Int).SetString(tc.amount, 10)
+
+		result := FormatUnits(amount, tc.decimals)
+		if result != tc.expected {
+			t.Errorf("FormatUnits(%s, %d): expected %s, got %s", tc.amount, tc.decimals, tc.expected, result)
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	testCases := []struct {
+		value    string
+		decimals int64
+		expected string
+		err      bool
+	}{
+		{"1", 18, "1000000000000000000", false},
+		{"1.5", 6, "1500000", false},
+		{".5", 1, "5", false},
+		{"0.000000000000000001", 18, "1", false},
+		{"-2.50", 1, "-25", false},
+		{"1.23", 1, "", true},
+		{"abc", 18, "", true},
+		{"", 18, "", true},
+	}
+
+	for _, tc := range testCases {
+		result, err := ParseUnits(tc.value, tc.decimals)
+		if tc.err {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d): expected error", tc.value, tc.decimals)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): unexpected error: %v", tc.value, tc.decimals, err)
+			continue
+		}
+		if result.String() != tc.expected {
+			t.Errorf("ParseUnits(%q, %d): expected %s, got %s", tc.value, tc.decimals, tc.expected, result.String())
+		}
+	}
+}
+
+func TestToRounded(t *testing.T) {
+	value, _ := new(big.Int).SetString("1234567890123456789", 10)
+	log := Log{Value: value}
+
+	if result := log.ToRounded(18); result != 1.234567890123456789 {
+		t.Errorf("Expected 1.234567890123456789, got %v", result)
+	}
+}
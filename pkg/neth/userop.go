@@ -2,6 +2,7 @@ package neth
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -135,3 +136,87 @@ func (u *UserOp) GetHash(chainID *big.Int) string {
 	// Return the keccak256 hash of the packed data
 	return crypto.Keccak256Hash(packed).Hex()
 }
+
+// GetUserOpHash returns the ERC-4337 hash of the user operation for an entry point, which is what
+// the account signs and what bundlers identify it by: keccak256(abi.encode(keccak256(pack(op)),
+// entryPoint, chainId)), packed as in the v0.6 entry point without the signature
+func (u *UserOp) GetUserOpHash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	word := func(value *big.Int) []byte {
+		padded := make([]byte, 32)
+		value.FillBytes(padded)
+		return padded
+	}
+	address := func(address common.Address) []byte {
+		return common.LeftPadBytes(address.Bytes(), 32)
+	}
+
+	packed := make([]byte, 0, 10*32)
+	packed = append(packed, address(u.Sender)...)
+	packed = append(packed, word(u.Nonce)...)
+	packed = append(packed, crypto.Keccak256(u.InitCode)...)
+	packed = append(packed, crypto.Keccak256(u.CallData)...)
+	packed = append(packed, word(u.CallGasLimit)...)
+	packed = append(packed, word(u.VerificationGasLimit)...)
+	packed = append(packed, word(u.PreVerificationGas)...)
+	packed = append(packed, word(u.MaxFeePerGas)...)
+	packed = append(packed, word(u.MaxPriorityFeePerGas)...)
+	packed = append(packed, crypto.Keccak256(u.PaymasterAndData)...)
+
+	encoded := make([]byte, 0, 3*32)
+	encoded = append(encoded, crypto.Keccak256(packed)...)
+	encoded = append(encoded, address(entryPoint)...)
+	encoded = append(encoded, word(chainID)...)
+
+	return crypto.Keccak256Hash(encoded)
+}
+
+// IsDeployment checks if the user operation deploys its sender account, which is the
+// case when the initCode contains at least a factory address
+func (u *UserOp) IsDeployment() bool {
+	return len(u.InitCode) >= common.AddressLength
+}
+
+// GetFactory returns the factory address from the initCode
+func (u *UserOp) GetFactory() (common.Address, bool) {
+	if !u.IsDeployment() {
+		return common.Address{}, false
+	}
+
+	return common.BytesToAddress(u.InitCode[:common.AddressLength]), true
+}
+
+// GetFactorySalt returns the salt passed to the factory, assumed to be the last 32 byte word
+// of the factory call as in createAccount(address owner, uint256 salt)
+func (u *UserOp) GetFactorySalt() ([32]byte, bool) {
+	var salt [32]byte
+
+	// factory address + function selector + at least one word
+	if len(u.InitCode) < common.AddressLength+4+32 {
+		return salt, false
+	}
+
+	copy(salt[:], u.InitCode[len(u.InitCode)-32:])
+
+	return salt, true
+}
+
+// ComputeCreate2Address computes the address of a contract deployed with CREATE2
+func ComputeCreate2Address(deployer common.Address, salt [32]byte, initCodeHash []byte) common.Address {
+	return crypto.CreateAddress2(deployer, salt, initCodeHash)
+}
+
+// CounterfactualAddress computes the address of the account deployed by the initCode, given the
+// hash of the creation code the factory deploys with CREATE2
+func (u *UserOp) CounterfactualAddress(initCodeHash []byte) (common.Address, error) {
+	factory, ok := u.GetFactory()
+	if !ok {
+		return common.Address{}, fmt.Errorf("user operation does not deploy an account")
+	}
+
+	salt, ok := u.GetFactorySalt()
+	if !ok {
+		return common.Address{}, fmt.Errorf("factory call does not contain a salt")
+	}
+
+	return ComputeCreate2Address(factory, salt, initCodeHash), nil
+}
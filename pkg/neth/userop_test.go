@@ -0,0 +1,93 @@
+package neth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCounterfactualAddress(t *testing.T) {
+	factory := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+
+	// createAccount(address owner, uint256 salt) with salt 0xcafebabe
+	initCode := append([]byte{}, factory.Bytes()...)
+	initCode = append(initCode, crypto.Keccak256([]byte("createAccount(address,uint256)"))[:4]...)
+	initCode = append(initCode, common.LeftPadBytes(common.FromHex("0x1234"), 32)...)
+	initCode = append(initCode, common.LeftPadBytes(common.FromHex("0xcafebabe"), 32)...)
+
+	userOp := UserOp{InitCode: initCode}
+	if !userOp.IsDeployment() {
+		t.Fatalf("Expected user op to be a deployment")
+	}
+
+	if (&UserOp{}).IsDeployment() {
+		t.Errorf("Expected user op without initCode not to be a deployment")
+	}
+
+	initCodeHash := crypto.Keccak256(common.FromHex("0xdeadbeef"))
+
+	address, err := userOp.CounterfactualAddress(initCodeHash)
+	if err != nil {
+		t.Fatalf("Failed to compute counterfactual address: %v", err)
+	}
+
+	// Example 5 of EIP-1014
+	expected := common.HexToAddress("0x60f3f640a8508fC6a86d45DF051962668E1e8AC7")
+	if address != expected {
+		t.Errorf("Expected %s, got %s", expected.Hex(), address.Hex())
+	}
+}
+
+func TestGetUserOpHash(t *testing.T) {
+	entryPoint := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	userOp := UserOp{
+		Sender:               common.HexToAddress("0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6"),
+		Nonce:                big.NewInt(1),
+		InitCode:             []byte{},
+		CallData:             common.FromHex("0xb61d27f6"),
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000000),
+		PaymasterAndData:     []byte{},
+		Signature:            common.FromHex("0x1234"),
+	}
+
+	uint256, _ := abi.NewType("uint256", "", nil)
+	address, _ := abi.NewType("address", "", nil)
+	bytes32, _ := abi.NewType("bytes32", "", nil)
+
+	packed, err := abi.Arguments{{Type: address}, {Type: uint256}, {Type: bytes32}, {Type: bytes32}, {Type: uint256}, {Type: uint256}, {Type: uint256}, {Type: uint256}, {Type: uint256}, {Type: bytes32}}.Pack(
+		userOp.Sender, userOp.Nonce, crypto.Keccak256Hash(userOp.InitCode), crypto.Keccak256Hash(userOp.CallData),
+		userOp.CallGasLimit, userOp.VerificationGasLimit, userOp.PreVerificationGas, userOp.MaxFeePerGas, userOp.MaxPriorityFeePerGas,
+		crypto.Keccak256Hash(userOp.PaymasterAndData),
+	)
+	if err != nil {
+		t.Fatalf("Failed to pack user op: %v", err)
+	}
+	encoded, err := abi.Arguments{{Type: bytes32}, {Type: address}, {Type: uint256}}.Pack(crypto.Keccak256Hash(packed), entryPoint, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("Failed to encode user op hash: %v", err)
+	}
+
+	hash := userOp.GetUserOpHash(entryPoint, big.NewInt(100))
+	if expected := crypto.Keccak256Hash(encoded); hash != expected {
+		t.Errorf("Expected user op hash %s, got %s", expected.Hex(), hash.Hex())
+	}
+
+	// The signature is not part of the hash, the entry point and chain are
+	userOp.Signature = common.FromHex("0x5678")
+	if userOp.GetUserOpHash(entryPoint, big.NewInt(100)) != hash {
+		t.Errorf("Expected the signature not to change the hash")
+	}
+	if userOp.GetUserOpHash(entryPoint, big.NewInt(1)) == hash {
+		t.Errorf("Expected the chain to change the hash")
+	}
+	if userOp.GetUserOpHash(common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032"), big.NewInt(100)) == hash {
+		t.Errorf("Expected the entry point to change the hash")
+	}
+}
@@ -0,0 +1,254 @@
+// Package notify sends encrypted direct messages (NIP-17) about the activity of watched
+// addresses, e.g. to tell someone they were paid
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip17"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// Publisher publishes events to relays, e.g. a service.PoolPublisher
+type Publisher interface {
+	Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult
+}
+
+// Notifier sends a direct message to the recipient of a watched address when the address
+// receives a transfer. It is a sink, e.g. of a watcher, and only reacts to new tx log events
+// of ERC20 transfers.
+type Notifier struct {
+	keyer     nostr.Keyer
+	publisher Publisher
+	relays    []string
+
+	querier   event.EventQuerier // Looks up the DM relays of the recipients, when set
+	tokens    *event.TokenRegistry
+	formatter *event.Formatter // Message templates, the built-in summary is used without one
+	relay     string           // Relay hint of the nevent links
+	locale    string           // Locale of the constructors when empty
+	catalog   *event.Catalog
+
+	mu      sync.RWMutex
+	watched map[string]string // Recipient public keys by lowercase address
+}
+
+// Option configures a Notifier
+type Option func(*Notifier)
+
+// WithDMRelayLookup sends the messages to the DM relays (kind 10050) of the recipients, looked
+// up with the querier, and only falls back to the relays of the notifier when they have none
+func WithDMRelayLookup(querier event.EventQuerier) Option {
+	return func(n *Notifier) {
+		n.querier = querier
+	}
+}
+
+// WithTokenRegistry sets the registry the symbols and decimals of the tokens are read from,
+// DefaultTokenRegistry by default
+func WithTokenRegistry(tokens *event.TokenRegistry) Option {
+	return func(n *Notifier) {
+		n.tokens = tokens
+	}
+}
+
+// WithFormatter renders the messages with the message templates of a formatter, events without
+// a template get the built-in summary
+func WithFormatter(formatter *event.Formatter) Option {
+	return func(n *Notifier) {
+		n.formatter = formatter
+	}
+}
+
+// WithLocale renders the messages in a locale, translating the built-in summary with a catalog,
+// DefaultCatalog when nil. The locale of the constructors, event.Locale, is used by default.
+func WithLocale(locale string, catalog *event.Catalog) Option {
+	return func(n *Notifier) {
+		n.locale = locale
+		if catalog != nil {
+			n.catalog = catalog
+		}
+	}
+}
+
+// WithLinkRelay sets the relay hint of the nevent links to the transfers
+func WithLinkRelay(relayURL string) Option {
+	return func(n *Notifier) {
+		n.relay = relayURL
+	}
+}
+
+// New creates a new notifier sending messages signed and encrypted by the keyer to the relays
+func New(keyer nostr.Keyer, publisher Publisher, relays []string, opts ...Option) *Notifier {
+	n := &Notifier{
+		keyer:     keyer,
+		publisher: publisher,
+		relays:    relays,
+		tokens:    event.DefaultTokenRegistry,
+		catalog:   event.DefaultCatalog,
+		watched:   make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Watch notifies a recipient, given as npub or hex public key, of the transfers received by an
+// address
+func (n *Notifier) Watch(address, recipient string) error {
+	if !strings.HasPrefix(address, "0x") || len(address) != 42 {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+
+	pubkey := recipient
+	if strings.HasPrefix(recipient, "npub1") {
+		prefix, value, err := nip19.Decode(recipient)
+		if err != nil || prefix != "npub" {
+			return fmt.Errorf("invalid npub: %s", recipient)
+		}
+		pubkey = value.(string)
+	}
+	if !nostr.IsValidPublicKey(pubkey) {
+		return fmt.Errorf("invalid public key: %s", recipient)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.watched[strings.ToLower(address)] = pubkey
+	return nil
+}
+
+// Unwatch stops the notifications of an address
+func (n *Notifier) Unwatch(address string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.watched, strings.ToLower(address))
+}
+
+// Send notifies the recipient of the receiver of a transfer, other events are ignored
+func (n *Notifier) Send(ctx context.Context, evt *nostr.Event) error {
+	if event.DefaultKind(evt.Kind) != event.KindTxLog {
+		return nil
+	}
+
+	txLog, err := event.ParseTxLogEvent(evt)
+	if err != nil {
+		return err
+	}
+	if txLog.EventType != event.EventTypeTxLogCreated || !strings.EqualFold(txLog.LogData.Topic, neth.TopicERC20Transfer) {
+		return nil
+	}
+
+	data, err := txLog.LogData.GetTransferData()
+	if err != nil || data == nil {
+		return nil
+	}
+
+	n.mu.RLock()
+	recipient, ok := n.watched[strings.ToLower(data.To)]
+	n.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var summary string
+	if n.formatter != nil {
+		if summary, _, err = n.formatter.Localized(n.locale).Format(event.FormatMessage, evt); err != nil {
+			return err
+		}
+	}
+	if summary == "" {
+		if summary, err = n.summary(evt, txLog.LogData, data); err != nil {
+			return err
+		}
+	}
+
+	return n.message(ctx, recipient, summary)
+}
+
+// summary returns the human-readable message about a transfer, with a nostr:nevent link to the
+// event
+func (n *Notifier) summary(evt *nostr.Event, log neth.Log, data *neth.LogTransferData) (string, error) {
+	amount, symbol := data.Value, log.To
+	if token, ok := n.tokens.Token(log.ChainID, log.To); ok {
+		symbol = token.Symbol
+		if value, ok := new(big.Int).SetString(data.Value, 10); ok {
+			amount = neth.FormatUnits(value, token.Decimals)
+		}
+	}
+
+	nevent, err := event.EncodeEventIDToNevent(evt.ID, n.relay, evt.PubKey, evt.Kind)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode event link: %w", err)
+	}
+
+	locale := n.locale
+	if locale == "" {
+		locale = event.Locale()
+	}
+
+	summary := n.catalog.Translate(locale, event.MessageTransferReceived, data.To, amount, symbol, data.From, log.ChainID)
+	return summary + "\nnostr:" + nevent, nil
+}
+
+// message sends a direct message to a recipient, it fails when no relay accepts it
+func (n *Notifier) message(ctx context.Context, recipient, content string) error {
+	_, toThem, err := nip17.PrepareMessage(ctx, content, nostr.Tags{}, n.keyer, recipient, nil)
+	if err != nil {
+		return fmt.Errorf("failed to prepare message: %w", err)
+	}
+
+	relays := n.relays
+	if n.querier != nil {
+		if dmRelays := n.dmRelays(ctx, recipient); len(dmRelays) > 0 {
+			relays = dmRelays
+		}
+	}
+	if len(relays) == 0 {
+		return fmt.Errorf("no relay to message %s", recipient)
+	}
+
+	var errs []error
+	for _, result := range n.publisher.Publish(ctx, relays, toThem) {
+		if result.Ok {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", result.Relay, result.Error))
+	}
+
+	return fmt.Errorf("failed to message %s: %w", recipient, errors.Join(errs...))
+}
+
+// dmRelays returns the relays of the latest DM relay list of a recipient
+func (n *Notifier) dmRelays(ctx context.Context, recipient string) []string {
+	events, err := n.querier.Query(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindDMRelayList},
+		Authors: []string{recipient},
+	})
+	if err != nil || len(events) == 0 {
+		return nil
+	}
+	event.SortEventsByCreatedAt(events, true)
+
+	var relays []string
+	for _, tag := range events[0].Tags {
+		if len(tag) >= 2 && tag[0] == "relay" {
+			relays = append(relays, tag[1])
+		}
+	}
+	return relays
+}
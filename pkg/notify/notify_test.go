@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/keyer"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip59"
+)
+
+const (
+	token    = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	treasury = "0x1111111111111111111111111111111111111111"
+	member   = "0x2222222222222222222222222222222222222222"
+)
+
+// recordingPublisher keeps the events it publishes and the relays they were sent to
+type recordingPublisher struct {
+	events []nostr.Event
+	relays [][]string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	p.events = append(p.events, evt)
+	p.relays = append(p.relays, relays)
+	return []*pb.RelayResult{{Relay: relays[0], Ok: true}}
+}
+
+// memoryQuerier answers queries from a list of events
+type memoryQuerier []*nostr.Event
+
+func (q memoryQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var events []*nostr.Event
+	for _, evt := range q {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func testTransfer(t *testing.T, hash, from, to string, value int64) *nostr.Event {
+	data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"%d"}`, from, to, value))
+	evt, err := event.CreateTxLogEvent(neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	evt.Sign(nostr.GeneratePrivateKey())
+	return evt
+}
+
+func TestNotifierMessagesRecipient(t *testing.T) {
+	sender, _ := keyer.NewPlainKeySigner(nostr.GeneratePrivateKey())
+	recipientKey := nostr.GeneratePrivateKey()
+	recipient, _ := keyer.NewPlainKeySigner(recipientKey)
+	recipientPubKey, _ := recipient.GetPublicKey(context.Background())
+	npub, _ := nip19.EncodePublicKey(recipientPubKey)
+
+	// The recipient reads direct messages on its own relay
+	dmRelays := &nostr.Event{Kind: nostr.KindDMRelayList, Tags: nostr.Tags{{"relay", "wss://dm.example.com"}}, CreatedAt: nostr.Now()}
+	dmRelays.Sign(recipientKey)
+
+	tokens := event.NewTokenRegistry()
+	list, err := neth.ParseTokenList([]byte(`{"name":"Test","tokens":[{"chainId":100,"address":"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d","name":"EURe","symbol":"EURe","decimals":18}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse token list: %v", err)
+	}
+	tokens.AddTokenList(list)
+
+	publisher := &recordingPublisher{}
+	n := New(sender, publisher, []string{"wss://relay.example.com"}, WithDMRelayLookup(memoryQuerier{dmRelays}), WithTokenRegistry(tokens))
+	if err := n.Watch(member, npub); err != nil {
+		t.Fatalf("Failed to watch address: %v", err)
+	}
+	if err := n.Watch(member, "npub1invalid"); err == nil {
+		t.Error("Expected an invalid npub to fail")
+	}
+
+	// Transfers sent by the address are not notified
+	if err := n.Send(context.Background(), testTransfer(t, "0x01", member, treasury, 5)); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+
+	received := testTransfer(t, "0x02", treasury, member, 1500000000000000000)
+	if err := n.Send(context.Background(), received); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(publisher.events))
+	}
+	if fmt.Sprint(publisher.relays[0]) != "[wss://dm.example.com]" {
+		t.Errorf("Expected the message on the DM relay of the recipient, got %v", publisher.relays[0])
+	}
+
+	rumor, err := nip59.GiftUnwrap(publisher.events[0], func(pubkey, ciphertext string) (string, error) {
+		return recipient.Decrypt(context.Background(), ciphertext, pubkey)
+	})
+	if err != nil {
+		t.Fatalf("Failed to unwrap message: %v", err)
+	}
+
+	if rumor.Kind != nostr.KindDirectMessage || !strings.Contains(rumor.Content, "received 1.5 EURe from "+treasury) {
+		t.Errorf("Expected a summary of the transfer, got %q", rumor.Content)
+	}
+	nevent, _ := event.EncodeEventIDToNevent(received.ID, "", received.PubKey, received.Kind)
+	if !strings.Contains(rumor.Content, "nostr:"+nevent) {
+		t.Errorf("Expected a link to the transfer, got %q", rumor.Content)
+	}
+
+	// No more messages once unwatched
+	n.Unwatch(member)
+	if err := n.Send(context.Background(), testTransfer(t, "0x03", treasury, member, 1)); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+	if len(publisher.events) != 1 {
+		t.Errorf("Expected no new message, got %d", len(publisher.events))
+	}
+}
+
+func TestNotifierFormatter(t *testing.T) {
+	sender, _ := keyer.NewPlainKeySigner(nostr.GeneratePrivateKey())
+	recipient, _ := keyer.NewPlainKeySigner(nostr.GeneratePrivateKey())
+	recipientPubKey, _ := recipient.GetPublicKey(context.Background())
+
+	formatter := event.NewFormatter(nil)
+	if err := formatter.Register(event.FormatMessage, neth.TopicERC20Transfer, "Paid {{.Transfer.Value}} by {{short .Transfer.From}}"); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	publisher := &recordingPublisher{}
+	n := New(sender, publisher, []string{"wss://relay.example.com"}, WithFormatter(formatter))
+	if err := n.Watch(member, recipientPubKey); err != nil {
+		t.Fatalf("Failed to watch address: %v", err)
+	}
+
+	if err := n.Send(context.Background(), testTransfer(t, "0x01", treasury, member, 42)); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+
+	rumor, err := nip59.GiftUnwrap(publisher.events[0], func(pubkey, ciphertext string) (string, error) {
+		return recipient.Decrypt(context.Background(), ciphertext, pubkey)
+	})
+	if err != nil {
+		t.Fatalf("Failed to unwrap message: %v", err)
+	}
+	if rumor.Content != "Paid 42 by 0x1111…1111" {
+		t.Errorf("Expected the templated message, got %q", rumor.Content)
+	}
+}
+
+func TestNotifierLocale(t *testing.T) {
+	sender, _ := keyer.NewPlainKeySigner(nostr.GeneratePrivateKey())
+	recipient, _ := keyer.NewPlainKeySigner(nostr.GeneratePrivateKey())
+	recipientPubKey, _ := recipient.GetPublicKey(context.Background())
+
+	catalog := event.NewCatalog()
+	catalog.Add("de", map[string]string{event.MessageTransferReceived: "%s hat %s %s von %s auf Chain %s erhalten"})
+
+	publisher := &recordingPublisher{}
+	n := New(sender, publisher, []string{"wss://relay.example.com"}, WithLocale("de", catalog))
+	if err := n.Watch(member, recipientPubKey); err != nil {
+		t.Fatalf("Failed to watch address: %v", err)
+	}
+
+	if err := n.Send(context.Background(), testTransfer(t, "0x01", treasury, member, 42)); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+
+	rumor, err := nip59.GiftUnwrap(publisher.events[0], func(pubkey, ciphertext string) (string, error) {
+		return recipient.Decrypt(context.Background(), ciphertext, pubkey)
+	})
+	if err != nil {
+		t.Fatalf("Failed to unwrap message: %v", err)
+	}
+	expected := member + " hat 42 " + token + " von " + treasury + " auf Chain 100 erhalten\nnostr:"
+	if !strings.HasPrefix(rumor.Content, expected) {
+		t.Errorf("Expected the German message, got %q", rumor.Content)
+	}
+}
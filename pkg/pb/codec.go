@@ -0,0 +1,24 @@
+// Package pb holds the protobuf schema of the event payloads and converters to and from the Go structs
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative events.proto service.proto
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// MarshalEvent encodes a Nostr event in the protobuf wire format
+func MarshalEvent(evt *nostr.Event) ([]byte, error) {
+	return proto.Marshal(FromEvent(evt))
+}
+
+// UnmarshalEvent decodes a Nostr event from the protobuf wire format
+func UnmarshalEvent(b []byte) (*nostr.Event, error) {
+	var msg Event
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return nil, err
+	}
+	return msg.ToEvent(), nil
+}
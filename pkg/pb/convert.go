@@ -0,0 +1,334 @@
+package pb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromLog converts a log to its protobuf message
+func FromLog(log neth.Log) *Log {
+	msg := &Log{
+		Hash:      log.Hash,
+		TxHash:    log.TxHash,
+		ChainId:   log.ChainID,
+		Topic:     log.Topic,
+		CreatedAt: timestamppb.New(log.CreatedAt),
+		UpdatedAt: timestamppb.New(log.UpdatedAt),
+		Nonce:     log.Nonce,
+		Sender:    log.Sender,
+		To:        log.To,
+		Value:     bigToString(log.Value),
+	}
+
+	if log.Data != nil {
+		msg.Data = []byte(*log.Data)
+	}
+
+	return msg
+}
+
+// ToLog converts the protobuf message back to a log
+func (m *Log) ToLog() (neth.Log, error) {
+	value, err := stringToBig(m.GetValue())
+	if err != nil {
+		return neth.Log{}, fmt.Errorf("invalid log value: %w", err)
+	}
+
+	log := neth.Log{
+		Hash:      m.GetHash(),
+		TxHash:    m.GetTxHash(),
+		ChainID:   m.GetChainId(),
+		Topic:     m.GetTopic(),
+		CreatedAt: m.GetCreatedAt().AsTime(),
+		UpdatedAt: m.GetUpdatedAt().AsTime(),
+		Nonce:     m.GetNonce(),
+		Sender:    m.GetSender(),
+		To:        m.GetTo(),
+		Value:     value,
+	}
+
+	if len(m.GetData()) > 0 {
+		data := json.RawMessage(m.GetData())
+		log.Data = &data
+	}
+
+	return log, nil
+}
+
+// FromUserOp converts a user operation to its protobuf message
+func FromUserOp(userOp neth.UserOp) *UserOp {
+	return &UserOp{
+		Sender:               userOp.Sender.Hex(),
+		Nonce:                bigToString(userOp.Nonce),
+		InitCode:             userOp.InitCode,
+		CallData:             userOp.CallData,
+		CallGasLimit:         bigToString(userOp.CallGasLimit),
+		VerificationGasLimit: bigToString(userOp.VerificationGasLimit),
+		PreVerificationGas:   bigToString(userOp.PreVerificationGas),
+		MaxFeePerGas:         bigToString(userOp.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigToString(userOp.MaxPriorityFeePerGas),
+		PaymasterAndData:     userOp.PaymasterAndData,
+		Signature:            userOp.Signature,
+	}
+}
+
+// ToUserOp converts the protobuf message back to a user operation, the sender, nonce, gas and
+// fee fields are required
+func (m *UserOp) ToUserOp() (neth.UserOp, error) {
+	if !common.IsHexAddress(m.GetSender()) {
+		return neth.UserOp{}, fmt.Errorf("invalid sender: %q", m.GetSender())
+	}
+
+	userOp := neth.UserOp{
+		Sender:           common.HexToAddress(m.GetSender()),
+		InitCode:         m.GetInitCode(),
+		CallData:         m.GetCallData(),
+		PaymasterAndData: m.GetPaymasterAndData(),
+		Signature:        m.GetSignature(),
+	}
+
+	fields := []struct {
+		name  string
+		value string
+		dst   **big.Int
+	}{
+		{"nonce", m.GetNonce(), &userOp.Nonce},
+		{"call_gas_limit", m.GetCallGasLimit(), &userOp.CallGasLimit},
+		{"verification_gas_limit", m.GetVerificationGasLimit(), &userOp.VerificationGasLimit},
+		{"pre_verification_gas", m.GetPreVerificationGas(), &userOp.PreVerificationGas},
+		{"max_fee_per_gas", m.GetMaxFeePerGas(), &userOp.MaxFeePerGas},
+		{"max_priority_fee_per_gas", m.GetMaxPriorityFeePerGas(), &userOp.MaxPriorityFeePerGas},
+	}
+
+	for _, field := range fields {
+		if field.value == "" {
+			return neth.UserOp{}, fmt.Errorf("%s is required", field.name)
+		}
+		value, err := stringToBig(field.value)
+		if err != nil {
+			return neth.UserOp{}, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		*field.dst = value
+	}
+
+	return userOp, nil
+}
+
+// FromTxLogEvent converts the content of a transaction log event to its protobuf message
+func FromTxLogEvent(e *event.TxLogEvent) *TxLogEvent {
+	return &TxLogEvent{
+		LogData:   FromLog(e.LogData),
+		EventType: string(e.EventType),
+		Tags:      e.Tags,
+	}
+}
+
+// ToTxLogEvent converts the protobuf message back to the content of a transaction log event
+func (m *TxLogEvent) ToTxLogEvent() (*event.TxLogEvent, error) {
+	log, err := m.GetLogData().ToLog()
+	if err != nil {
+		return nil, err
+	}
+
+	return &event.TxLogEvent{
+		LogData:   log,
+		EventType: event.EventTypeTxLog(m.GetEventType()),
+		Tags:      m.GetTags(),
+	}, nil
+}
+
+// FromTxTransferEvent converts the content of a transfer event to its protobuf message
+func FromTxTransferEvent(e *event.TxTransferEvent) *TxTransferEvent {
+	return &TxTransferEvent{
+		LogData:   FromLog(e.LogData),
+		EventType: string(e.EventType),
+		Tags:      e.Tags,
+	}
+}
+
+// ToTxTransferEvent converts the protobuf message back to the content of a transfer event
+func (m *TxTransferEvent) ToTxTransferEvent() (*event.TxTransferEvent, error) {
+	log, err := m.GetLogData().ToLog()
+	if err != nil {
+		return nil, err
+	}
+
+	return &event.TxTransferEvent{
+		LogData:   log,
+		EventType: event.EventTypeTxTransfer(m.GetEventType()),
+		Tags:      m.GetTags(),
+	}, nil
+}
+
+// FromUserOpEvent converts the content of a user operation event to its protobuf message
+func FromUserOpEvent(e *event.UserOpEvent) *UserOpEvent {
+	msg := &UserOpEvent{
+		UserOpData: FromUserOp(e.UserOpData),
+		TxHash:     e.TxHash,
+		EventType:  string(e.EventType),
+		RetryCount: int32(e.RetryCount),
+		Tags:       e.Tags,
+	}
+
+	if e.Paymaster != nil {
+		paymaster := e.Paymaster.Hex()
+		msg.Paymaster = &paymaster
+	}
+
+	if e.EntryPoint != nil {
+		entryPoint := e.EntryPoint.Hex()
+		msg.EntryPoint = &entryPoint
+	}
+
+	if e.Data != nil {
+		msg.Data = []byte(*e.Data)
+	}
+
+	if e.Failure != nil {
+		msg.FailureReason = &FailureReason{
+			Code:       e.Failure.Code,
+			Message:    e.Failure.Message,
+			RevertData: e.Failure.RevertData,
+		}
+	}
+
+	return msg
+}
+
+// ToUserOpEvent converts the protobuf message back to the content of a user operation event
+func (m *UserOpEvent) ToUserOpEvent() (*event.UserOpEvent, error) {
+	userOp, err := m.GetUserOpData().ToUserOp()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &event.UserOpEvent{
+		UserOpData: userOp,
+		TxHash:     m.TxHash,
+		EventType:  event.EventTypeUserOp(m.GetEventType()),
+		RetryCount: int(m.GetRetryCount()),
+		Tags:       m.GetTags(),
+	}
+
+	if m.Paymaster != nil {
+		paymaster := common.HexToAddress(m.GetPaymaster())
+		e.Paymaster = &paymaster
+	}
+
+	if m.EntryPoint != nil {
+		entryPoint := common.HexToAddress(m.GetEntryPoint())
+		e.EntryPoint = &entryPoint
+	}
+
+	if len(m.GetData()) > 0 {
+		data := json.RawMessage(m.GetData())
+		e.Data = &data
+	}
+
+	if failure := m.GetFailureReason(); failure != nil {
+		e.Failure = &event.FailureReason{
+			Code:       failure.GetCode(),
+			Message:    failure.GetMessage(),
+			RevertData: failure.GetRevertData(),
+		}
+	}
+
+	return e, nil
+}
+
+// FromGroupMetadata converts group metadata to its protobuf message
+func FromGroupMetadata(metadata event.GroupMetadata) *GroupMetadata {
+	return &GroupMetadata{
+		Name:       metadata.Name,
+		About:      metadata.About,
+		Picture:    metadata.Picture,
+		Admins:     metadata.Admins,
+		Moderators: metadata.Moderators,
+		Private:    metadata.Private,
+		Closed:     metadata.Closed,
+		CreatedAt:  metadata.CreatedAt,
+		UpdatedAt:  metadata.UpdatedAt,
+	}
+}
+
+// ToGroupMetadata converts the protobuf message back to group metadata
+func (m *GroupMetadata) ToGroupMetadata() event.GroupMetadata {
+	return event.GroupMetadata{
+		Name:       m.GetName(),
+		About:      m.GetAbout(),
+		Picture:    m.GetPicture(),
+		Admins:     m.GetAdmins(),
+		Moderators: m.GetModerators(),
+		Private:    m.GetPrivate(),
+		Closed:     m.GetClosed(),
+		CreatedAt:  m.GetCreatedAt(),
+		UpdatedAt:  m.GetUpdatedAt(),
+	}
+}
+
+// FromEvent converts a Nostr event to its protobuf message
+func FromEvent(evt *nostr.Event) *Event {
+	msg := &Event{
+		Id:        evt.ID,
+		Pubkey:    evt.PubKey,
+		CreatedAt: int64(evt.CreatedAt),
+		Kind:      int32(evt.Kind),
+		Tags:      make([]*Tag, 0, len(evt.Tags)),
+		Content:   evt.Content,
+		Sig:       evt.Sig,
+	}
+
+	for _, tag := range evt.Tags {
+		msg.Tags = append(msg.Tags, &Tag{Values: tag})
+	}
+
+	return msg
+}
+
+// ToEvent converts the protobuf message back to a Nostr event
+func (m *Event) ToEvent() *nostr.Event {
+	evt := &nostr.Event{
+		ID:        m.GetId(),
+		PubKey:    m.GetPubkey(),
+		CreatedAt: nostr.Timestamp(m.GetCreatedAt()),
+		Kind:      int(m.GetKind()),
+		Tags:      make(nostr.Tags, 0, len(m.GetTags())),
+		Content:   m.GetContent(),
+		Sig:       m.GetSig(),
+	}
+
+	for _, tag := range m.GetTags() {
+		evt.Tags = append(evt.Tags, nostr.Tag(tag.GetValues()))
+	}
+
+	return evt
+}
+
+// bigToString encodes a big integer as a decimal string, nil is encoded as an empty string
+func bigToString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+// stringToBig decodes a decimal string, an empty string is decoded as nil
+func stringToBig(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("not a decimal integer: %s", value)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,93 @@
+package pb
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTxLogEventRoundTrip(t *testing.T) {
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","value":"42"}`)
+	original := &event.TxLogEvent{
+		LogData: neth.Log{
+			Hash:      "0xabc",
+			TxHash:    "0xdef",
+			ChainID:   "1",
+			Topic:     neth.TopicERC20Transfer,
+			CreatedAt: time.Unix(1700000000, 0).UTC(),
+			UpdatedAt: time.Unix(1700000001, 0).UTC(),
+			Nonce:     3,
+			Sender:    "0x1111111111111111111111111111111111111111",
+			To:        "0x2222222222222222222222222222222222222222",
+			Value:     new(big.Int).Lsh(big.NewInt(1), 200),
+			Data:      &data,
+		},
+		EventType: event.EventTypeTxLogCreated,
+		Tags:      []string{"tx_log", "evm", "1"},
+	}
+
+	b, err := proto.Marshal(FromTxLogEvent(original))
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var msg TxLogEvent
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	decoded, err := msg.ToTxLogEvent()
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+
+	expected, _ := json.Marshal(original)
+	actual, _ := json.Marshal(decoded)
+	if string(expected) != string(actual) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestToUserOpRequiredFields(t *testing.T) {
+	complete := func() *UserOp {
+		return &UserOp{
+			Sender:               "0x1111111111111111111111111111111111111111",
+			Nonce:                "1",
+			CallGasLimit:         "100000",
+			VerificationGasLimit: "100000",
+			PreVerificationGas:   "21000",
+			MaxFeePerGas:         "1000000000",
+			MaxPriorityFeePerGas: "1000000000",
+		}
+	}
+
+	if _, err := complete().ToUserOp(); err != nil {
+		t.Fatalf("Failed to convert user op: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		clear func(*UserOp)
+	}{
+		{"sender", func(m *UserOp) { m.Sender = "0x01" }},
+		{"nonce", func(m *UserOp) { m.Nonce = "" }},
+		{"call_gas_limit", func(m *UserOp) { m.CallGasLimit = "" }},
+		{"verification_gas_limit", func(m *UserOp) { m.VerificationGasLimit = "" }},
+		{"pre_verification_gas", func(m *UserOp) { m.PreVerificationGas = "" }},
+		{"max_fee_per_gas", func(m *UserOp) { m.MaxFeePerGas = "" }},
+		{"max_priority_fee_per_gas", func(m *UserOp) { m.MaxPriorityFeePerGas = "" }},
+	}
+
+	for _, tc := range testCases {
+		m := complete()
+		tc.clear(m)
+		if _, err := m.ToUserOp(); err == nil {
+			t.Errorf("Expected a user op without %s to be rejected", tc.name)
+		}
+	}
+}
@@ -0,0 +1,691 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateTxLogEventRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Log            *Log                   `protobuf:"bytes,1,opt,name=log,proto3" json:"log,omitempty"`
+	SortableAmount bool                   `protobuf:"varint,2,opt,name=sortable_amount,json=sortableAmount,proto3" json:"sortable_amount,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateTxLogEventRequest) Reset() {
+	*x = CreateTxLogEventRequest{}
+	mi := &file_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTxLogEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTxLogEventRequest) ProtoMessage() {}
+
+func (x *CreateTxLogEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTxLogEventRequest.ProtoReflect.Descriptor instead.
+func (*CreateTxLogEventRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateTxLogEventRequest) GetLog() *Log {
+	if x != nil {
+		return x.Log
+	}
+	return nil
+}
+
+func (x *CreateTxLogEventRequest) GetSortableAmount() bool {
+	if x != nil {
+		return x.SortableAmount
+	}
+	return false
+}
+
+type CreateUserOpEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       string                 `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Paymaster     *string                `protobuf:"bytes,2,opt,name=paymaster,proto3,oneof" json:"paymaster,omitempty"`
+	EntryPoint    *string                `protobuf:"bytes,3,opt,name=entry_point,json=entryPoint,proto3,oneof" json:"entry_point,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"` // Raw JSON
+	TxHash        *string                `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3,oneof" json:"tx_hash,omitempty"`
+	RetryCount    int32                  `protobuf:"varint,6,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`
+	UserOp        *UserOp                `protobuf:"bytes,7,opt,name=user_op,json=userOp,proto3" json:"user_op,omitempty"`
+	EventType     string                 `protobuf:"bytes,8,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserOpEventRequest) Reset() {
+	*x = CreateUserOpEventRequest{}
+	mi := &file_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserOpEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserOpEventRequest) ProtoMessage() {}
+
+func (x *CreateUserOpEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserOpEventRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserOpEventRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateUserOpEventRequest) GetChainId() string {
+	if x != nil {
+		return x.ChainId
+	}
+	return ""
+}
+
+func (x *CreateUserOpEventRequest) GetPaymaster() string {
+	if x != nil && x.Paymaster != nil {
+		return *x.Paymaster
+	}
+	return ""
+}
+
+func (x *CreateUserOpEventRequest) GetEntryPoint() string {
+	if x != nil && x.EntryPoint != nil {
+		return *x.EntryPoint
+	}
+	return ""
+}
+
+func (x *CreateUserOpEventRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CreateUserOpEventRequest) GetTxHash() string {
+	if x != nil && x.TxHash != nil {
+		return *x.TxHash
+	}
+	return ""
+}
+
+func (x *CreateUserOpEventRequest) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+func (x *CreateUserOpEventRequest) GetUserOp() *UserOp {
+	if x != nil {
+		return x.UserOp
+	}
+	return nil
+}
+
+func (x *CreateUserOpEventRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+type EventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventResponse) Reset() {
+	*x = EventResponse{}
+	mi := &file_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventResponse) ProtoMessage() {}
+
+func (x *EventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventResponse.ProtoReflect.Descriptor instead.
+func (*EventResponse) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type ParseEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseEventRequest) Reset() {
+	*x = ParseEventRequest{}
+	mi := &file_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseEventRequest) ProtoMessage() {}
+
+func (x *ParseEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseEventRequest.ProtoReflect.Descriptor instead.
+func (*ParseEventRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParseEventRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type ParseEventResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Content:
+	//
+	//	*ParseEventResponse_TxLog
+	//	*ParseEventResponse_TxTransfer
+	//	*ParseEventResponse_UserOp
+	//	*ParseEventResponse_GroupMetadata
+	Content       isParseEventResponse_Content `protobuf_oneof:"content"`
+	ContentJson   []byte                       `protobuf:"bytes,5,opt,name=content_json,json=contentJson,proto3" json:"content_json,omitempty"` // The content as JSON, set for every kind
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseEventResponse) Reset() {
+	*x = ParseEventResponse{}
+	mi := &file_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseEventResponse) ProtoMessage() {}
+
+func (x *ParseEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseEventResponse.ProtoReflect.Descriptor instead.
+func (*ParseEventResponse) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ParseEventResponse) GetContent() isParseEventResponse_Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ParseEventResponse) GetTxLog() *TxLogEvent {
+	if x != nil {
+		if x, ok := x.Content.(*ParseEventResponse_TxLog); ok {
+			return x.TxLog
+		}
+	}
+	return nil
+}
+
+func (x *ParseEventResponse) GetTxTransfer() *TxTransferEvent {
+	if x != nil {
+		if x, ok := x.Content.(*ParseEventResponse_TxTransfer); ok {
+			return x.TxTransfer
+		}
+	}
+	return nil
+}
+
+func (x *ParseEventResponse) GetUserOp() *UserOpEvent {
+	if x != nil {
+		if x, ok := x.Content.(*ParseEventResponse_UserOp); ok {
+			return x.UserOp
+		}
+	}
+	return nil
+}
+
+func (x *ParseEventResponse) GetGroupMetadata() *GroupMetadata {
+	if x != nil {
+		if x, ok := x.Content.(*ParseEventResponse_GroupMetadata); ok {
+			return x.GroupMetadata
+		}
+	}
+	return nil
+}
+
+func (x *ParseEventResponse) GetContentJson() []byte {
+	if x != nil {
+		return x.ContentJson
+	}
+	return nil
+}
+
+type isParseEventResponse_Content interface {
+	isParseEventResponse_Content()
+}
+
+type ParseEventResponse_TxLog struct {
+	TxLog *TxLogEvent `protobuf:"bytes,1,opt,name=tx_log,json=txLog,proto3,oneof"`
+}
+
+type ParseEventResponse_TxTransfer struct {
+	TxTransfer *TxTransferEvent `protobuf:"bytes,2,opt,name=tx_transfer,json=txTransfer,proto3,oneof"`
+}
+
+type ParseEventResponse_UserOp struct {
+	UserOp *UserOpEvent `protobuf:"bytes,3,opt,name=user_op,json=userOp,proto3,oneof"`
+}
+
+type ParseEventResponse_GroupMetadata struct {
+	GroupMetadata *GroupMetadata `protobuf:"bytes,4,opt,name=group_metadata,json=groupMetadata,proto3,oneof"`
+}
+
+func (*ParseEventResponse_TxLog) isParseEventResponse_Content() {}
+
+func (*ParseEventResponse_TxTransfer) isParseEventResponse_Content() {}
+
+func (*ParseEventResponse_UserOp) isParseEventResponse_Content() {}
+
+func (*ParseEventResponse_GroupMetadata) isParseEventResponse_Content() {}
+
+type PublishEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Relays        []string               `protobuf:"bytes,2,rep,name=relays,proto3" json:"relays,omitempty"` // Relays to publish to, the relays of the server when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventRequest) Reset() {
+	*x = PublishEventRequest{}
+	mi := &file_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventRequest) ProtoMessage() {}
+
+func (x *PublishEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventRequest.ProtoReflect.Descriptor instead.
+func (*PublishEventRequest) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PublishEventRequest) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *PublishEventRequest) GetRelays() []string {
+	if x != nil {
+		return x.Relays
+	}
+	return nil
+}
+
+type RelayResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Relay         string                 `protobuf:"bytes,1,opt,name=relay,proto3" json:"relay,omitempty"`
+	Ok            bool                   `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelayResult) Reset() {
+	*x = RelayResult{}
+	mi := &file_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelayResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelayResult) ProtoMessage() {}
+
+func (x *RelayResult) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelayResult.ProtoReflect.Descriptor instead.
+func (*RelayResult) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RelayResult) GetRelay() string {
+	if x != nil {
+		return x.Relay
+	}
+	return ""
+}
+
+func (x *RelayResult) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *RelayResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PublishEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"` // The published, signed event
+	Results       []*RelayResult         `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishEventResponse) Reset() {
+	*x = PublishEventResponse{}
+	mi := &file_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishEventResponse) ProtoMessage() {}
+
+func (x *PublishEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishEventResponse.ProtoReflect.Descriptor instead.
+func (*PublishEventResponse) Descriptor() ([]byte, []int) {
+	return file_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PublishEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *PublishEventResponse) GetResults() []*RelayResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_service_proto protoreflect.FileDescriptor
+
+const file_service_proto_rawDesc = "" +
+	"\n" +
+	"\rservice.proto\x12\vnostreth.v1\x1a\fevents.proto\"f\n" +
+	"\x17CreateTxLogEventRequest\x12\"\n" +
+	"\x03log\x18\x01 \x01(\v2\x10.nostreth.v1.LogR\x03log\x12'\n" +
+	"\x0fsortable_amount\x18\x02 \x01(\bR\x0esortableAmount\"\xc8\x02\n" +
+	"\x18CreateUserOpEventRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\tR\achainId\x12!\n" +
+	"\tpaymaster\x18\x02 \x01(\tH\x00R\tpaymaster\x88\x01\x01\x12$\n" +
+	"\ventry_point\x18\x03 \x01(\tH\x01R\n" +
+	"entryPoint\x88\x01\x01\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\x12\x1c\n" +
+	"\atx_hash\x18\x05 \x01(\tH\x02R\x06txHash\x88\x01\x01\x12\x1f\n" +
+	"\vretry_count\x18\x06 \x01(\x05R\n" +
+	"retryCount\x12,\n" +
+	"\auser_op\x18\a \x01(\v2\x13.nostreth.v1.UserOpR\x06userOp\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\b \x01(\tR\teventTypeB\f\n" +
+	"\n" +
+	"_paymasterB\x0e\n" +
+	"\f_entry_pointB\n" +
+	"\n" +
+	"\b_tx_hash\"9\n" +
+	"\rEventResponse\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.nostreth.v1.EventR\x05event\"=\n" +
+	"\x11ParseEventRequest\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.nostreth.v1.EventR\x05event\"\xaf\x02\n" +
+	"\x12ParseEventResponse\x120\n" +
+	"\x06tx_log\x18\x01 \x01(\v2\x17.nostreth.v1.TxLogEventH\x00R\x05txLog\x12?\n" +
+	"\vtx_transfer\x18\x02 \x01(\v2\x1c.nostreth.v1.TxTransferEventH\x00R\n" +
+	"txTransfer\x123\n" +
+	"\auser_op\x18\x03 \x01(\v2\x18.nostreth.v1.UserOpEventH\x00R\x06userOp\x12C\n" +
+	"\x0egroup_metadata\x18\x04 \x01(\v2\x1a.nostreth.v1.GroupMetadataH\x00R\rgroupMetadata\x12!\n" +
+	"\fcontent_json\x18\x05 \x01(\fR\vcontentJsonB\t\n" +
+	"\acontent\"W\n" +
+	"\x13PublishEventRequest\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.nostreth.v1.EventR\x05event\x12\x16\n" +
+	"\x06relays\x18\x02 \x03(\tR\x06relays\"I\n" +
+	"\vRelayResult\x12\x14\n" +
+	"\x05relay\x18\x01 \x01(\tR\x05relay\x12\x0e\n" +
+	"\x02ok\x18\x02 \x01(\bR\x02ok\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"t\n" +
+	"\x14PublishEventResponse\x12(\n" +
+	"\x05event\x18\x01 \x01(\v2\x12.nostreth.v1.EventR\x05event\x122\n" +
+	"\aresults\x18\x02 \x03(\v2\x18.nostreth.v1.RelayResultR\aresults2\xdc\x02\n" +
+	"\bNostrEth\x12T\n" +
+	"\x10CreateTxLogEvent\x12$.nostreth.v1.CreateTxLogEventRequest\x1a\x1a.nostreth.v1.EventResponse\x12V\n" +
+	"\x11CreateUserOpEvent\x12%.nostreth.v1.CreateUserOpEventRequest\x1a\x1a.nostreth.v1.EventResponse\x12M\n" +
+	"\n" +
+	"ParseEvent\x12\x1e.nostreth.v1.ParseEventRequest\x1a\x1f.nostreth.v1.ParseEventResponse\x12S\n" +
+	"\fPublishEvent\x12 .nostreth.v1.PublishEventRequest\x1a!.nostreth.v1.PublishEventResponseB&Z$github.com/comunifi/nostr-eth/pkg/pbb\x06proto3"
+
+var (
+	file_service_proto_rawDescOnce sync.Once
+	file_service_proto_rawDescData []byte
+)
+
+func file_service_proto_rawDescGZIP() []byte {
+	file_service_proto_rawDescOnce.Do(func() {
+		file_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_service_proto_rawDesc), len(file_service_proto_rawDesc)))
+	})
+	return file_service_proto_rawDescData
+}
+
+var file_service_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_service_proto_goTypes = []any{
+	(*CreateTxLogEventRequest)(nil),  // 0: nostreth.v1.CreateTxLogEventRequest
+	(*CreateUserOpEventRequest)(nil), // 1: nostreth.v1.CreateUserOpEventRequest
+	(*EventResponse)(nil),            // 2: nostreth.v1.EventResponse
+	(*ParseEventRequest)(nil),        // 3: nostreth.v1.ParseEventRequest
+	(*ParseEventResponse)(nil),       // 4: nostreth.v1.ParseEventResponse
+	(*PublishEventRequest)(nil),      // 5: nostreth.v1.PublishEventRequest
+	(*RelayResult)(nil),              // 6: nostreth.v1.RelayResult
+	(*PublishEventResponse)(nil),     // 7: nostreth.v1.PublishEventResponse
+	(*Log)(nil),                      // 8: nostreth.v1.Log
+	(*UserOp)(nil),                   // 9: nostreth.v1.UserOp
+	(*Event)(nil),                    // 10: nostreth.v1.Event
+	(*TxLogEvent)(nil),               // 11: nostreth.v1.TxLogEvent
+	(*TxTransferEvent)(nil),          // 12: nostreth.v1.TxTransferEvent
+	(*UserOpEvent)(nil),              // 13: nostreth.v1.UserOpEvent
+	(*GroupMetadata)(nil),            // 14: nostreth.v1.GroupMetadata
+}
+var file_service_proto_depIdxs = []int32{
+	8,  // 0: nostreth.v1.CreateTxLogEventRequest.log:type_name -> nostreth.v1.Log
+	9,  // 1: nostreth.v1.CreateUserOpEventRequest.user_op:type_name -> nostreth.v1.UserOp
+	10, // 2: nostreth.v1.EventResponse.event:type_name -> nostreth.v1.Event
+	10, // 3: nostreth.v1.ParseEventRequest.event:type_name -> nostreth.v1.Event
+	11, // 4: nostreth.v1.ParseEventResponse.tx_log:type_name -> nostreth.v1.TxLogEvent
+	12, // 5: nostreth.v1.ParseEventResponse.tx_transfer:type_name -> nostreth.v1.TxTransferEvent
+	13, // 6: nostreth.v1.ParseEventResponse.user_op:type_name -> nostreth.v1.UserOpEvent
+	14, // 7: nostreth.v1.ParseEventResponse.group_metadata:type_name -> nostreth.v1.GroupMetadata
+	10, // 8: nostreth.v1.PublishEventRequest.event:type_name -> nostreth.v1.Event
+	10, // 9: nostreth.v1.PublishEventResponse.event:type_name -> nostreth.v1.Event
+	6,  // 10: nostreth.v1.PublishEventResponse.results:type_name -> nostreth.v1.RelayResult
+	0,  // 11: nostreth.v1.NostrEth.CreateTxLogEvent:input_type -> nostreth.v1.CreateTxLogEventRequest
+	1,  // 12: nostreth.v1.NostrEth.CreateUserOpEvent:input_type -> nostreth.v1.CreateUserOpEventRequest
+	3,  // 13: nostreth.v1.NostrEth.ParseEvent:input_type -> nostreth.v1.ParseEventRequest
+	5,  // 14: nostreth.v1.NostrEth.PublishEvent:input_type -> nostreth.v1.PublishEventRequest
+	2,  // 15: nostreth.v1.NostrEth.CreateTxLogEvent:output_type -> nostreth.v1.EventResponse
+	2,  // 16: nostreth.v1.NostrEth.CreateUserOpEvent:output_type -> nostreth.v1.EventResponse
+	4,  // 17: nostreth.v1.NostrEth.ParseEvent:output_type -> nostreth.v1.ParseEventResponse
+	7,  // 18: nostreth.v1.NostrEth.PublishEvent:output_type -> nostreth.v1.PublishEventResponse
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_service_proto_init() }
+func file_service_proto_init() {
+	if File_service_proto != nil {
+		return
+	}
+	file_events_proto_init()
+	file_service_proto_msgTypes[1].OneofWrappers = []any{}
+	file_service_proto_msgTypes[4].OneofWrappers = []any{
+		(*ParseEventResponse_TxLog)(nil),
+		(*ParseEventResponse_TxTransfer)(nil),
+		(*ParseEventResponse_UserOp)(nil),
+		(*ParseEventResponse_GroupMetadata)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_proto_rawDesc), len(file_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_proto_goTypes,
+		DependencyIndexes: file_service_proto_depIdxs,
+		MessageInfos:      file_service_proto_msgTypes,
+	}.Build()
+	File_service_proto = out.File
+	file_service_proto_goTypes = nil
+	file_service_proto_depIdxs = nil
+}
@@ -0,0 +1,249 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NostrEth_CreateTxLogEvent_FullMethodName  = "/nostreth.v1.NostrEth/CreateTxLogEvent"
+	NostrEth_CreateUserOpEvent_FullMethodName = "/nostreth.v1.NostrEth/CreateUserOpEvent"
+	NostrEth_ParseEvent_FullMethodName        = "/nostreth.v1.NostrEth/ParseEvent"
+	NostrEth_PublishEvent_FullMethodName      = "/nostreth.v1.NostrEth/PublishEvent"
+)
+
+// NostrEthClient is the client API for NostrEth service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NostrEth exposes event creation, parsing and publishing to non-Go services, so that they
+// reuse the exact tagging logic of this package
+type NostrEthClient interface {
+	// CreateTxLogEvent creates an unsigned transaction log event
+	CreateTxLogEvent(ctx context.Context, in *CreateTxLogEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
+	// CreateUserOpEvent creates an unsigned user operation event
+	CreateUserOpEvent(ctx context.Context, in *CreateUserOpEventRequest, opts ...grpc.CallOption) (*EventResponse, error)
+	// ParseEvent parses the content of an event according to its kind
+	ParseEvent(ctx context.Context, in *ParseEventRequest, opts ...grpc.CallOption) (*ParseEventResponse, error)
+	// PublishEvent signs an event with the key of the server when it is unsigned and publishes it
+	PublishEvent(ctx context.Context, in *PublishEventRequest, opts ...grpc.CallOption) (*PublishEventResponse, error)
+}
+
+type nostrEthClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNostrEthClient(cc grpc.ClientConnInterface) NostrEthClient {
+	return &nostrEthClient{cc}
+}
+
+func (c *nostrEthClient) CreateTxLogEvent(ctx context.Context, in *CreateTxLogEventRequest, opts ...grpc.CallOption) (*EventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, NostrEth_CreateTxLogEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nostrEthClient) CreateUserOpEvent(ctx context.Context, in *CreateUserOpEventRequest, opts ...grpc.CallOption) (*EventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EventResponse)
+	err := c.cc.Invoke(ctx, NostrEth_CreateUserOpEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nostrEthClient) ParseEvent(ctx context.Context, in *ParseEventRequest, opts ...grpc.CallOption) (*ParseEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseEventResponse)
+	err := c.cc.Invoke(ctx, NostrEth_ParseEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nostrEthClient) PublishEvent(ctx context.Context, in *PublishEventRequest, opts ...grpc.CallOption) (*PublishEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishEventResponse)
+	err := c.cc.Invoke(ctx, NostrEth_PublishEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NostrEthServer is the server API for NostrEth service.
+// All implementations must embed UnimplementedNostrEthServer
+// for forward compatibility.
+//
+// NostrEth exposes event creation, parsing and publishing to non-Go services, so that they
+// reuse the exact tagging logic of this package
+type NostrEthServer interface {
+	// CreateTxLogEvent creates an unsigned transaction log event
+	CreateTxLogEvent(context.Context, *CreateTxLogEventRequest) (*EventResponse, error)
+	// CreateUserOpEvent creates an unsigned user operation event
+	CreateUserOpEvent(context.Context, *CreateUserOpEventRequest) (*EventResponse, error)
+	// ParseEvent parses the content of an event according to its kind
+	ParseEvent(context.Context, *ParseEventRequest) (*ParseEventResponse, error)
+	// PublishEvent signs an event with the key of the server when it is unsigned and publishes it
+	PublishEvent(context.Context, *PublishEventRequest) (*PublishEventResponse, error)
+	mustEmbedUnimplementedNostrEthServer()
+}
+
+// UnimplementedNostrEthServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNostrEthServer struct{}
+
+func (UnimplementedNostrEthServer) CreateTxLogEvent(context.Context, *CreateTxLogEventRequest) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTxLogEvent not implemented")
+}
+func (UnimplementedNostrEthServer) CreateUserOpEvent(context.Context, *CreateUserOpEventRequest) (*EventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUserOpEvent not implemented")
+}
+func (UnimplementedNostrEthServer) ParseEvent(context.Context, *ParseEventRequest) (*ParseEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ParseEvent not implemented")
+}
+func (UnimplementedNostrEthServer) PublishEvent(context.Context, *PublishEventRequest) (*PublishEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishEvent not implemented")
+}
+func (UnimplementedNostrEthServer) mustEmbedUnimplementedNostrEthServer() {}
+func (UnimplementedNostrEthServer) testEmbeddedByValue()                  {}
+
+// UnsafeNostrEthServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NostrEthServer will
+// result in compilation errors.
+type UnsafeNostrEthServer interface {
+	mustEmbedUnimplementedNostrEthServer()
+}
+
+func RegisterNostrEthServer(s grpc.ServiceRegistrar, srv NostrEthServer) {
+	// If the following call pancis, it indicates UnimplementedNostrEthServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NostrEth_ServiceDesc, srv)
+}
+
+func _NostrEth_CreateTxLogEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTxLogEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrEthServer).CreateTxLogEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NostrEth_CreateTxLogEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrEthServer).CreateTxLogEvent(ctx, req.(*CreateTxLogEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NostrEth_CreateUserOpEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserOpEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrEthServer).CreateUserOpEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NostrEth_CreateUserOpEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrEthServer).CreateUserOpEvent(ctx, req.(*CreateUserOpEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NostrEth_ParseEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrEthServer).ParseEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NostrEth_ParseEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrEthServer).ParseEvent(ctx, req.(*ParseEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NostrEth_PublishEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NostrEthServer).PublishEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NostrEth_PublishEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NostrEthServer).PublishEvent(ctx, req.(*PublishEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NostrEth_ServiceDesc is the grpc.ServiceDesc for NostrEth service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NostrEth_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nostreth.v1.NostrEth",
+	HandlerType: (*NostrEthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTxLogEvent",
+			Handler:    _NostrEth_CreateTxLogEvent_Handler,
+		},
+		{
+			MethodName: "CreateUserOpEvent",
+			Handler:    _NostrEth_CreateUserOpEvent_Handler,
+		},
+		{
+			MethodName: "ParseEvent",
+			Handler:    _NostrEth_ParseEvent_Handler,
+		},
+		{
+			MethodName: "PublishEvent",
+			Handler:    _NostrEth_PublishEvent_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "service.proto",
+}
@@ -0,0 +1,65 @@
+// Package pipeline starts and stops the long-running subsystems of a deployment together
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Component is a long-running subsystem, e.g. a watcher or a queue publisher
+type Component interface {
+	Start(ctx context.Context) error
+	Stop() error
+}
+
+// Pipeline starts its components in order and stops them in reverse order. Components that
+// others send events to come first, they run before the first event arrives and stop after
+// the last one was sent.
+type Pipeline struct {
+	mu sync.Mutex
+
+	components []Component
+	started    int
+}
+
+// New creates a new pipeline, e.g. New(publisher, watcher) for a watcher sending to a queue
+// publisher
+func New(components ...Component) *Pipeline {
+	return &Pipeline{components: components}
+}
+
+// Start starts every component, the components already started are stopped again when one
+// of them fails to start
+func (p *Pipeline) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, component := range p.components[p.started:] {
+		if err := component.Start(ctx); err != nil {
+			return errors.Join(err, p.stop())
+		}
+		p.started++
+	}
+
+	return nil
+}
+
+// Stop stops the started components in reverse order
+func (p *Pipeline) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stop()
+}
+
+// stop stops the started components, the caller holds the lock
+func (p *Pipeline) stop() error {
+	var errs []error
+	for ; p.started > 0; p.started-- {
+		if err := p.components[p.started-1].Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type recordingComponent struct {
+	name  string
+	fail  bool
+	calls *[]string
+}
+
+func (c *recordingComponent) Start(ctx context.Context) error {
+	if c.fail {
+		return errors.New("failed to start " + c.name)
+	}
+	*c.calls = append(*c.calls, "start "+c.name)
+	return nil
+}
+
+func (c *recordingComponent) Stop() error {
+	*c.calls = append(*c.calls, "stop "+c.name)
+	return nil
+}
+
+func TestPipelineOrder(t *testing.T) {
+	var calls []string
+
+	p := New(
+		&recordingComponent{name: "publisher", calls: &calls},
+		&recordingComponent{name: "watcher", calls: &calls},
+	)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Failed to stop pipeline: %v", err)
+	}
+
+	expected := "[start publisher start watcher stop watcher stop publisher]"
+	if fmt.Sprint(calls) != expected {
+		t.Errorf("Expected %s, got %v", expected, calls)
+	}
+
+	// A component failing to start stops the ones started before it
+	calls = nil
+	p = New(
+		&recordingComponent{name: "publisher", calls: &calls},
+		&recordingComponent{name: "watcher", fail: true, calls: &calls},
+	)
+	if err := p.Start(context.Background()); err == nil {
+		t.Fatal("Expected the pipeline to fail to start")
+	}
+
+	expected = "[start publisher stop publisher]"
+	if fmt.Sprint(calls) != expected {
+		t.Errorf("Expected %s, got %v", expected, calls)
+	}
+}
@@ -0,0 +1,161 @@
+// Package policy decides which inbound events to keep, it is meant to be used by relays and
+// subscribers to drop noise before it is stored or processed
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultSpamThreshold is the spam score at which events are rejected
+const DefaultSpamThreshold = 1.0
+
+// Decision is the outcome of checking an event against the policy
+type Decision struct {
+	Allow     bool
+	Reason    string
+	SpamScore float64
+	Signals   []string
+}
+
+// Engine checks inbound events against rate limits, duplicate detection and spam scoring
+type Engine struct {
+	pubKeyLimiter  *RateLimiter
+	addressLimiter *RateLimiter
+
+	duplicateWindow time.Duration
+	mu              sync.Mutex
+	seenContent     map[string]time.Time
+
+	scorer        *SpamScorer
+	spamThreshold float64
+}
+
+// Option configures an Engine
+type Option func(*Engine)
+
+// WithPubKeyRateLimit limits the number of events per author public key within the window
+func WithPubKeyRateLimit(limit int, window time.Duration) Option {
+	return func(e *Engine) {
+		e.pubKeyLimiter = NewRateLimiter(limit, window)
+	}
+}
+
+// WithAddressRateLimit limits the number of events per address (P and p tags) within the window
+func WithAddressRateLimit(limit int, window time.Duration) Option {
+	return func(e *Engine) {
+		e.addressLimiter = NewRateLimiter(limit, window)
+	}
+}
+
+// WithDuplicateWindow rejects events whose canonical content was already seen within the window
+func WithDuplicateWindow(window time.Duration) Option {
+	return func(e *Engine) {
+		e.duplicateWindow = window
+	}
+}
+
+// WithSpamScorer sets the scorer and the score at which events are rejected
+func WithSpamScorer(scorer *SpamScorer, threshold float64) Option {
+	return func(e *Engine) {
+		e.scorer = scorer
+		e.spamThreshold = threshold
+	}
+}
+
+// NewEngine creates a new policy engine, every check is disabled unless configured
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		seenContent:   make(map[string]time.Time),
+		spamThreshold: DefaultSpamThreshold,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Check decides whether an event received at the given time should be kept
+func (e *Engine) Check(evt *nostr.Event, now time.Time) Decision {
+	var decision Decision
+
+	if e.scorer != nil {
+		decision.SpamScore, decision.Signals = e.scorer.Score(evt)
+		if decision.SpamScore >= e.spamThreshold {
+			decision.Reason = fmt.Sprintf("spam score %.2f: %s", decision.SpamScore, strings.Join(decision.Signals, ", "))
+			return decision
+		}
+	}
+
+	if e.duplicateWindow > 0 && e.isDuplicate(evt, now) {
+		decision.Reason = "duplicate content"
+		return decision
+	}
+
+	if e.pubKeyLimiter != nil && evt.PubKey != "" && !e.pubKeyLimiter.Allow(evt.PubKey, now) {
+		decision.Reason = fmt.Sprintf("rate limited: pubkey %s", evt.PubKey)
+		return decision
+	}
+
+	if e.addressLimiter != nil {
+		for _, address := range eventAddresses(evt) {
+			if !e.addressLimiter.Allow(address, now) {
+				decision.Reason = fmt.Sprintf("rate limited: address %s", address)
+				return decision
+			}
+		}
+	}
+
+	decision.Allow = true
+
+	return decision
+}
+
+// isDuplicate records the canonical content of an event and reports whether it was already
+// seen within the duplicate window
+func (e *Engine) isDuplicate(evt *nostr.Event, now time.Time) bool {
+	hash := event.CanonicalContentHash(evt)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Drop expired entries as we go to keep the map bounded
+	for key, seenAt := range e.seenContent {
+		if now.Sub(seenAt) > e.duplicateWindow {
+			delete(e.seenContent, key)
+		}
+	}
+
+	if _, ok := e.seenContent[hash]; ok {
+		return true
+	}
+
+	e.seenContent[hash] = now
+
+	return false
+}
+
+// eventAddresses returns the unique lowercased addresses of the P and p tags of an event
+func eventAddresses(evt *nostr.Event) []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || (tag[0] != "P" && tag[0] != "p") || !strings.HasPrefix(tag[1], "0x") {
+			continue
+		}
+
+		address := strings.ToLower(tag[1])
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
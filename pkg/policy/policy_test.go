@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const spamToken = "0x00000000000000000000000000000000000000aa"
+
+func transferEvent(t *testing.T, hash, token, value string) *nostr.Event {
+	t.Helper()
+
+	data := json.RawMessage(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"` + value + `"}`)
+	evt, err := event.CreateTxTransferEvent(neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transfer event: %v", err)
+	}
+	return evt
+}
+
+func TestSpamScorer(t *testing.T) {
+	scorer := NewSpamScorer(big.NewInt(1000), []string{spamToken})
+	token := "0x00000000000000000000000000000000000000bb"
+
+	tests := []struct {
+		name    string
+		evt     *nostr.Event
+		score   float64
+		signals int
+	}{
+		{"regular", transferEvent(t, "0x1", token, "5000"), 0, 0},
+		{"dust", transferEvent(t, "0x2", token, "10"), 0.3, 1},
+		{"zero value", transferEvent(t, "0x3", token, "0"), 0.5, 1},
+		{"spam token", transferEvent(t, "0x4", spamToken, "10"), 1.3, 2},
+		{"other kind", &nostr.Event{Kind: 1, Content: "hello"}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		score, signals := scorer.Score(tt.evt)
+		if score != tt.score || len(signals) != tt.signals {
+			t.Errorf("%s: expected score %.1f with %d signals, got %.1f with %v", tt.name, tt.score, tt.signals, score, signals)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	engine := NewEngine(
+		WithPubKeyRateLimit(2, time.Minute),
+		WithDuplicateWindow(time.Minute),
+		WithSpamScorer(NewSpamScorer(big.NewInt(1000), []string{spamToken}), DefaultSpamThreshold),
+	)
+	now := time.Unix(1700000000, 0)
+	token := "0x00000000000000000000000000000000000000bb"
+
+	first := transferEvent(t, "0x1", token, "5000")
+	first.PubKey = "author"
+	if decision := engine.Check(first, now); !decision.Allow {
+		t.Fatalf("Expected first event to be allowed, got %s", decision.Reason)
+	}
+
+	if decision := engine.Check(first, now); decision.Allow {
+		t.Error("Expected duplicate event to be rejected")
+	}
+
+	spam := transferEvent(t, "0x2", spamToken, "5000")
+	spam.PubKey = "author"
+	if decision := engine.Check(spam, now); decision.Allow || decision.SpamScore != 1 {
+		t.Errorf("Expected spam token event to be rejected with score 1, got %+v", decision)
+	}
+
+	second := transferEvent(t, "0x3", token, "6000")
+	second.PubKey = "author"
+	if decision := engine.Check(second, now); !decision.Allow {
+		t.Fatalf("Expected second event to be allowed, got %s", decision.Reason)
+	}
+
+	third := transferEvent(t, "0x4", token, "7000")
+	third.PubKey = "author"
+	if decision := engine.Check(third, now); decision.Allow {
+		t.Error("Expected third event to be rate limited")
+	}
+
+	if decision := engine.Check(third, now.Add(2*time.Minute)); !decision.Allow {
+		t.Errorf("Expected event to be allowed after the window, got %s", decision.Reason)
+	}
+}
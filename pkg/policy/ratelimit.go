@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the number of events per key within a sliding window
+type RateLimiter struct {
+	mu sync.Mutex
+
+	limit  int
+	window time.Duration
+	seen   map[string][]time.Time
+}
+
+// NewRateLimiter creates a new rate limiter allowing limit events per key within the window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		seen:   make(map[string][]time.Time),
+	}
+}
+
+// Allow records an event for the key and reports whether it is within the limit,
+// rejected events do not count towards the limit
+func (r *RateLimiter) Allow(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	times := r.prune(key, now)
+	if len(times) >= r.limit {
+		return false
+	}
+
+	r.seen[key] = append(times, now)
+
+	return true
+}
+
+// prune drops the events of a key that are outside of the window
+func (r *RateLimiter) prune(key string, now time.Time) []time.Time {
+	times := r.seen[key]
+
+	cutoff := now.Add(-r.window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+
+	times = times[i:]
+	if len(times) == 0 {
+		delete(r.seen, key)
+		return nil
+	}
+
+	r.seen[key] = times
+	return times
+}
+
+// Cleanup drops all the events outside of the window, it should be called periodically
+// by long running processes
+func (r *RateLimiter) Cleanup(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key := range r.seen {
+		r.prune(key, now)
+	}
+}
@@ -0,0 +1,107 @@
+package policy
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Spam signals and their weight in the spam score
+const (
+	SignalZeroValue = "zero_value"
+	SignalDust      = "dust"
+	SignalSpamToken = "spam_token"
+)
+
+var signalWeights = map[string]float64{
+	SignalZeroValue: 0.5,
+	SignalDust:      0.3,
+	SignalSpamToken: 1,
+}
+
+// SpamScorer scores tx log and transfer events, higher scores are more likely spam
+type SpamScorer struct {
+	dustThreshold *big.Int
+	spamTokens    map[string]bool
+}
+
+// NewSpamScorer creates a new scorer, values strictly below the dust threshold are considered
+// dust and a nil threshold disables the dust signal
+func NewSpamScorer(dustThreshold *big.Int, spamTokens []string) *SpamScorer {
+	s := &SpamScorer{
+		dustThreshold: dustThreshold,
+		spamTokens:    make(map[string]bool),
+	}
+
+	for _, token := range spamTokens {
+		s.spamTokens[strings.ToLower(token)] = true
+	}
+
+	return s
+}
+
+// Score returns the spam score of an event and the signals that contributed to it,
+// events that are not tx logs or transfers score 0
+func (s *SpamScorer) Score(evt *nostr.Event) (float64, []string) {
+	log, ok := eventLog(evt)
+	if !ok {
+		return 0, nil
+	}
+
+	var signals []string
+
+	value := transferValue(log)
+	switch {
+	case value == nil:
+	case value.Sign() == 0:
+		signals = append(signals, SignalZeroValue)
+	case s.dustThreshold != nil && value.Cmp(s.dustThreshold) < 0:
+		signals = append(signals, SignalDust)
+	}
+
+	if s.spamTokens[strings.ToLower(log.To)] {
+		signals = append(signals, SignalSpamToken)
+	}
+
+	score := 0.0
+	for _, signal := range signals {
+		score += signalWeights[signal]
+	}
+
+	return score, signals
+}
+
+// eventLog returns the log carried by a tx log or transfer event
+func eventLog(evt *nostr.Event) (neth.Log, bool) {
+	switch event.DefaultKind(evt.Kind) {
+	case event.KindTxLog:
+		content, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			return neth.Log{}, false
+		}
+		return content.LogData, true
+	case event.KindTxTransfer, event.KindTxTransferLegacy:
+		content, err := event.ParseTxTransferEvent(evt)
+		if err != nil {
+			return neth.Log{}, false
+		}
+		return content.LogData, true
+	default:
+		return neth.Log{}, false
+	}
+}
+
+// transferValue returns the transferred value, read from the log data first
+func transferValue(log neth.Log) *big.Int {
+	transfer, err := log.GetTransferData()
+	if err == nil && transfer != nil && transfer.Value != "" {
+		if value, ok := new(big.Int).SetString(transfer.Value, 10); ok {
+			return value
+		}
+	}
+
+	return log.Value
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultAuthMaxAge is how far the time of a NIP-98 auth event may be from the time of the
+// request it authorizes
+const DefaultAuthMaxAge = 60 * time.Second
+
+// Auth errors, ErrAuthForbidden is returned for valid events of pubkeys outside the allowlist
+var (
+	ErrAuthMissing   = errors.New("missing Nostr authorization")
+	ErrAuthInvalid   = errors.New("invalid Nostr authorization")
+	ErrAuthForbidden = errors.New("pubkey is not allowed")
+)
+
+// WriteMethods are the gRPC methods that publish, protected by Authenticator.UnaryInterceptor
+var WriteMethods = []string{pb.NostrEth_PublishEvent_FullMethodName}
+
+// Authenticator checks NIP-98 HTTP auth: requests carry a signed kind 27235 event in an
+// "Authorization: Nostr <base64 event>" header, tagged with the URL and method of the request and
+// the SHA-256 of its body. Events are accepted once, so that a captured header cannot be replayed.
+type Authenticator struct {
+	allowed map[string]bool // Pubkeys allowed to write, every pubkey when empty, and to have events signed by the server
+	maxAge  time.Duration
+	now     func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time // IDs of the accepted events until they expire
+}
+
+// AuthOption configures an Authenticator
+type AuthOption func(*Authenticator)
+
+// WithAuthPubkeys only accepts the events of these pubkeys, hex or npub
+func WithAuthPubkeys(pubkeys ...string) AuthOption {
+	return func(a *Authenticator) {
+		for _, pubkey := range pubkeys {
+			a.allowed[normalizePubkey(pubkey)] = true
+		}
+	}
+}
+
+// WithAuthMaxAge sets how far the time of an auth event may be from the time of the request
+func WithAuthMaxAge(maxAge time.Duration) AuthOption {
+	return func(a *Authenticator) {
+		a.maxAge = maxAge
+	}
+}
+
+// NewAuthenticator creates a new NIP-98 authenticator, accepting every pubkey unless an allowlist
+// is given. Only the pubkeys of the allowlist have their unsigned events signed by the server, so
+// without one only events signed by their authors can be published.
+func NewAuthenticator(opts ...AuthOption) *Authenticator {
+	a := &Authenticator{
+		allowed: make(map[string]bool),
+		maxAge:  DefaultAuthMaxAge,
+		now:     time.Now,
+		seen:    make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// normalizePubkey returns the hex form of a pubkey given as hex or npub
+func normalizePubkey(pubkey string) string {
+	if strings.HasPrefix(pubkey, "npub") {
+		if prefix, value, err := nip19.Decode(pubkey); err == nil && prefix == "npub" {
+			return value.(string)
+		}
+	}
+	return strings.ToLower(pubkey)
+}
+
+// Verify checks the authorization header of a request to a URL with a method and a body,
+// returning the pubkey of the signer. The payload tag is checked when the body is not empty.
+func (a *Authenticator) Verify(header, url, method string, body []byte) (string, error) {
+	token, ok := strings.CutPrefix(header, "Nostr ")
+	if !ok {
+		return "", ErrAuthMissing
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return "", ErrAuthInvalid
+	}
+
+	var evt nostr.Event
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return "", ErrAuthInvalid
+	}
+
+	// The ID keys the replay protection, and the signature only covers the ID
+	if evt.Kind != nostr.KindHTTPAuth || !evt.CheckID() {
+		return "", ErrAuthInvalid
+	}
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return "", ErrAuthInvalid
+	}
+
+	now := a.now()
+	if age := now.Sub(evt.CreatedAt.Time()); age > a.maxAge || age < -a.maxAge {
+		return "", ErrAuthInvalid
+	}
+
+	if u := evt.Tags.Find("u"); len(u) < 2 || u[1] != url {
+		return "", ErrAuthInvalid
+	}
+	if m := evt.Tags.Find("method"); len(m) < 2 || !strings.EqualFold(m[1], method) {
+		return "", ErrAuthInvalid
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		if p := evt.Tags.Find("payload"); len(p) < 2 || !strings.EqualFold(p[1], hex.EncodeToString(sum[:])) {
+			return "", ErrAuthInvalid
+		}
+	}
+
+	if len(a.allowed) > 0 && !a.allowed[evt.PubKey] {
+		return "", ErrAuthForbidden
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, expiry := range a.seen {
+		if now.After(expiry) {
+			delete(a.seen, id)
+		}
+	}
+	if _, ok := a.seen[evt.ID]; ok {
+		return "", ErrAuthInvalid
+	}
+	a.seen[evt.ID] = evt.CreatedAt.Time().Add(a.maxAge)
+
+	return evt.PubKey, nil
+}
+
+// authorize returns the context of a request authorized for a pubkey, pubkeys of the allowlist
+// may have their events signed by the server
+func (a *Authenticator) authorize(ctx context.Context, pubkey string) context.Context {
+	if a.allowed[pubkey] {
+		return AllowSigning(ctx)
+	}
+	return ctx
+}
+
+// Handler protects an HTTP handler, requests without a valid authorization are answered with
+// 401, or 403 for pubkeys outside the allowlist. The URL of a request is rebuilt from its Host
+// header, and from X-Forwarded-Proto behind a TLS terminating proxy. Only the pubkeys of the
+// allowlist have their unsigned events signed by the server.
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxPublishSize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		pubkey, err := a.Verify(r.Header.Get("Authorization"), requestURL(r), r.Method, body)
+		if err != nil {
+			code := http.StatusUnauthorized
+			if errors.Is(err, ErrAuthForbidden) {
+				code = http.StatusForbidden
+			} else {
+				w.Header().Set("WWW-Authenticate", "Nostr")
+			}
+			writeError(w, code, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(a.authorize(r.Context(), pubkey)))
+	})
+}
+
+// requestURL returns the absolute URL of a request, as signed by its client. The URI received
+// by the server is used since handlers mounted under a prefix see a stripped URL.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	return scheme + "://" + r.Host + uri
+}
+
+// UnaryInterceptor protects gRPC methods, WriteMethods when none are given. The auth event is
+// read from the authorization metadata, with the full method name as its u tag, e.g.
+// "/nostreth.v1.NostrEth/PublishEvent", and POST as its method. Payloads are not checked since
+// the encoding of requests varies between clients. Only the pubkeys of the allowlist have their
+// unsigned events signed by the server.
+func (a *Authenticator) UnaryInterceptor(methods ...string) grpc.UnaryServerInterceptor {
+	if len(methods) == 0 {
+		methods = WriteMethods
+	}
+	protected := make(map[string]bool)
+	for _, method := range methods {
+		protected[method] = true
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !protected[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var header string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				header = values[0]
+			}
+		}
+
+		pubkey, err := a.Verify(header, info.FullMethod, http.MethodPost, nil)
+		if err != nil {
+			if errors.Is(err, ErrAuthForbidden) {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return handler(a.authorize(ctx, pubkey), req)
+	}
+}
+
+// CreateAuthHeader returns the NIP-98 authorization header of a request signed with a private
+// key, the payload tag is set when the body is not empty
+func CreateAuthHeader(privateKey, url, method string, body []byte) (string, error) {
+	evt := nostr.Event{
+		Kind:      nostr.KindHTTPAuth,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{{"u", url}, {"method", strings.ToUpper(method)}},
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		evt.Tags = append(evt.Tags, nostr.Tag{"payload", hex.EncodeToString(sum[:])})
+	}
+
+	if err := evt.Sign(privateKey); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return "", err
+	}
+	return "Nostr " + base64.StdEncoding.EncodeToString(data), nil
+}
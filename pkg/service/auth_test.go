@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticatorVerify(t *testing.T) {
+	key := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(key)
+	url := "https://bridge.example.com/publish"
+	body := []byte(`{"event":{}}`)
+
+	auth := NewAuthenticator()
+
+	header, err := CreateAuthHeader(key, url, http.MethodPost, body)
+	if err != nil {
+		t.Fatalf("Failed to create header: %v", err)
+	}
+	signer, err := auth.Verify(header, url, http.MethodPost, body)
+	if err != nil || signer != pubkey {
+		t.Fatalf("Expected the request to be signed by %s, got %s, %v", pubkey, signer, err)
+	}
+	if _, err := auth.Verify(header, url, http.MethodPost, body); !errors.Is(err, ErrAuthInvalid) {
+		t.Errorf("Expected a replayed header to be invalid, got %v", err)
+	}
+
+	// Editing the id of a captured event does not make it new
+	var evt nostr.Event
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Nostr "))
+	json.Unmarshal(data, &evt)
+	evt.ID = strings.Repeat("0", 64)
+	data, _ = json.Marshal(evt)
+	if _, err := auth.Verify("Nostr "+base64.StdEncoding.EncodeToString(data), url, http.MethodPost, body); !errors.Is(err, ErrAuthInvalid) {
+		t.Errorf("Expected a replayed header with an edited id to be invalid, got %v", err)
+	}
+
+	for name, verify := range map[string]func(header string) error{
+		"other url": func(header string) error {
+			_, err := auth.Verify(header, "https://bridge.example.com/other", http.MethodPost, body)
+			return err
+		},
+		"other method": func(header string) error {
+			_, err := auth.Verify(header, url, http.MethodPut, body)
+			return err
+		},
+		"other body": func(header string) error {
+			_, err := auth.Verify(header, url, http.MethodPost, []byte(`{}`))
+			return err
+		},
+	} {
+		header, _ := CreateAuthHeader(key, url, http.MethodPost, body)
+		if err := verify(header); !errors.Is(err, ErrAuthInvalid) {
+			t.Errorf("Expected a request to %s to be invalid, got %v", name, err)
+		}
+	}
+
+	if _, err := auth.Verify("", url, http.MethodPost, body); !errors.Is(err, ErrAuthMissing) {
+		t.Errorf("Expected a missing header, got %v", err)
+	}
+
+	// Events too far from the time of the request
+	auth.now = func() time.Time { return time.Now().Add(2 * DefaultAuthMaxAge) }
+	header, _ = CreateAuthHeader(key, url, http.MethodPost, body)
+	if _, err := auth.Verify(header, url, http.MethodPost, body); !errors.Is(err, ErrAuthInvalid) {
+		t.Errorf("Expected an expired header to be invalid, got %v", err)
+	}
+
+	// Allowlists take hex and npub pubkeys
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	allowed := NewAuthenticator(WithAuthPubkeys(npub))
+	header, _ = CreateAuthHeader(key, url, http.MethodPost, body)
+	if _, err := allowed.Verify(header, url, http.MethodPost, body); err != nil {
+		t.Errorf("Expected an allowed pubkey, got %v", err)
+	}
+
+	other := NewAuthenticator(WithAuthPubkeys(nostr.GeneratePrivateKey()))
+	header, _ = CreateAuthHeader(key, url, http.MethodPost, body)
+	if _, err := other.Verify(header, url, http.MethodPost, body); !errors.Is(err, ErrAuthForbidden) {
+		t.Errorf("Expected the pubkey to be forbidden, got %v", err)
+	}
+}
+
+func TestHTTPHandlerAuth(t *testing.T) {
+	key := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(key)
+	h := NewHTTPHandler(NewServer(key, []string{"wss://relay.example.com"}, &recordingPublisher{}), store.NewMemoryStore(), WithAuth(NewAuthenticator(WithAuthPubkeys(pubkey))))
+
+	body, _ := json.Marshal(PublishRequest{Event: nostr.Event{Kind: 1, Content: "hi", CreatedAt: nostr.Now()}})
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/publish", bytes.NewReader(body)))
+	if recorder.Code != http.StatusUnauthorized || recorder.Header().Get("WWW-Authenticate") != "Nostr" {
+		t.Errorf("Expected status 401 asking for Nostr auth, got %d", recorder.Code)
+	}
+
+	// httptest requests are sent to example.com
+	header, err := CreateAuthHeader(key, "http://example.com/publish", http.MethodPost, body)
+	if err != nil {
+		t.Fatalf("Failed to create header: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/publish", bytes.NewReader(body))
+	req.Header.Set("Authorization", header)
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	// Read endpoints stay open
+	if code := serveJSON(t, h, http.MethodGet, "/events?q=kind%3D1", nil, nil); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+}
+
+func TestAuthenticatorUnaryInterceptor(t *testing.T) {
+	key := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(key)
+	interceptor := NewAuthenticator(WithAuthPubkeys(pubkey)).UnaryInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	publish := &grpc.UnaryServerInfo{FullMethod: pb.NostrEth_PublishEvent_FullMethodName}
+	if _, err := interceptor(context.Background(), nil, publish, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected unauthenticated, got %v", err)
+	}
+
+	header, _ := CreateAuthHeader(key, pb.NostrEth_PublishEvent_FullMethodName, http.MethodPost, nil)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", header))
+	if resp, err := interceptor(ctx, nil, publish, handler); err != nil || resp != "ok" {
+		t.Errorf("Expected the call to pass, got %v", err)
+	}
+	signing := func(ctx context.Context, req any) (any, error) { return signingAllowed(ctx), nil }
+	if resp, _ := NewAuthenticator(WithAuthPubkeys(pubkey)).UnaryInterceptor()(ctx, nil, publish, signing); resp != true {
+		t.Errorf("Expected an allowed pubkey to get server signing, got %v", resp)
+	}
+	if resp, _ := NewAuthenticator().UnaryInterceptor()(ctx, nil, publish, signing); resp != false {
+		t.Errorf("Expected server signing to require an allowlist, got %v", resp)
+	}
+
+	header, _ = CreateAuthHeader(nostr.GeneratePrivateKey(), pb.NostrEth_PublishEvent_FullMethodName, http.MethodPost, nil)
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", header))
+	if _, err := interceptor(ctx, nil, publish, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected permission denied, got %v", err)
+	}
+
+	// Other methods are not protected
+	parse := &grpc.UnaryServerInfo{FullMethod: pb.NostrEth_ParseEvent_FullMethodName}
+	if _, err := interceptor(context.Background(), nil, parse, handler); err != nil {
+		t.Errorf("Expected parse to be open, got %v", err)
+	}
+}
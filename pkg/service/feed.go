@@ -0,0 +1,93 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// feedMaxAge is how long browsers and CDNs may cache a feed, in seconds
+const feedMaxAge = "30"
+
+// addressFeed serves the latest transfers from or to an address
+func (h *HTTPHandler) addressFeed(w http.ResponseWriter, r *http.Request) {
+	h.feed(w, r, event.FeedOptions{Address: r.PathValue("address")})
+}
+
+// groupFeed serves the latest transfers of the tokens of the token gate of a group, the tokens of
+// its community
+func (h *HTTPHandler) groupFeed(w http.ResponseWriter, r *http.Request) {
+	filter := nostr.Filter{
+		Kinds: event.MappedKinds(event.KindGroupTokenGate),
+		Tags:  nostr.TagMap{"d": []string{r.PathValue("id")}},
+		Limit: 1,
+	}
+	events, err := h.store.Query(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(events) == 0 {
+		writeError(w, http.StatusNotFound, "group has no token gate")
+		return
+	}
+
+	gate, err := event.ParseTokenGateEvent(events[0])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var opts event.FeedOptions
+	for _, rule := range gate.Rules {
+		opts.Contracts = append(opts.Contracts, event.FeedContract{ChainID: rule.ChainID, Address: rule.Contract.Hex()})
+	}
+	h.feed(w, r, opts)
+}
+
+// feed writes the transfer feed selected by the options as cacheable JSON, or not modified when
+// the client has it already. Feeds are public so that any website can embed them.
+func (h *HTTPHandler) feed(w http.ResponseWriter, r *http.Request, opts event.FeedOptions) {
+	opts.Limit = event.DefaultFeedLimit
+	if r.URL.Query().Has("limit") {
+		limit, ok := limitParam(w, r)
+		if !ok {
+			return
+		}
+		opts.Limit = min(limit, DefaultHTTPLimit)
+	}
+	opts.Tokens = h.feedTokens
+
+	filter := nostr.Filter{Kinds: event.MappedKinds(event.KindTxTransfer, event.KindTxTransferLegacy, event.KindTxLog)}
+	events, err := h.store.Query(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	feed := event.BuildTransferFeed(events, opts)
+	etag := feed.ETag()
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age="+feedMaxAge)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, feed)
+}
+
+// matchesETag checks if an If-None-Match header lists an entity tag
+func matchesETag(header, etag string) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+		if value == etag || value == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestHTTPHandlerFeeds(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+	h := NewHTTPHandler(NewServer("", nil, nil), st)
+
+	if err := st.Publish(ctx, *transferEvent(t, "0x01", "0xtx1", 1700000000)); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	var feed event.Feed
+	if code := serveJSON(t, h, http.MethodGet, "/feeds/addresses/"+graphqlSender, nil, &feed); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].ID != "0x01" || feed.Items[0].Amount != "1000" {
+		t.Errorf("Expected transfer 0x01 of 1000, got %v", feed.Items)
+	}
+
+	// Clients holding the feed get not modified
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/feeds/addresses/"+graphqlSender, nil))
+	etag := recorder.Header().Get("ETag")
+	if etag != feed.ETag() || recorder.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Expected a public feed with entity tag %s, got %v", feed.ETag(), recorder.Header())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/addresses/"+graphqlSender, nil)
+	req.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Errorf("Expected status 304 without body, got %d", recorder.Code)
+	}
+
+	// Groups are fed the transfers of the tokens of their token gate
+	if code := serveJSON(t, h, http.MethodGet, "/feeds/groups/test-group", nil, nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a token gate, got %d", code)
+	}
+
+	gate, err := event.CreateTokenGateEvent("test-group", event.GateMatchAny, neth.GateRule{
+		Type:     neth.GateRuleTokenBalance,
+		ChainID:  "100",
+		Contract: common.HexToAddress("0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create token gate: %v", err)
+	}
+	gate.ID = "gate"
+	if err := st.Publish(ctx, *gate); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	feed = event.Feed{}
+	if code := serveJSON(t, h, http.MethodGet, "/feeds/groups/test-group?limit=5", nil, &feed); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(feed.Items) != 1 || feed.Items[0].ID != "0x01" {
+		t.Errorf("Expected transfer 0x01, got %v", feed.Items)
+	}
+}
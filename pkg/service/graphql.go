@@ -0,0 +1,881 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// DefaultGraphQLPageSize is the number of nodes of a connection when first is not given
+	DefaultGraphQLPageSize = 20
+
+	// MaxGraphQLPageSize is the maximum number of nodes of a connection
+	MaxGraphQLPageSize = 100
+)
+
+// GraphQLSchema is the schema of the GraphQL API, lists are connections paginated newest first
+// with the endCursor of their pageInfo
+const GraphQLSchema = `
+schema {
+	query: Query
+	subscription: Subscription
+}
+
+type Query {
+	transfers(address: String, chain: String, first: Int, after: String): TransferConnection!
+	logs(address: String, chain: String, first: Int, after: String): LogConnection!
+	userOp(hash: String!): UserOp
+	userOps(sender: String, first: Int, after: String): UserOpConnection!
+	group(id: String!): Group
+	messages(group: String!, first: Int, after: String): MessageConnection!
+}
+
+type Subscription {
+	transferAdded(address: String, chain: String): Transfer!
+	userOpUpdated(sender: String): UserOp!
+	messageAdded(group: String!): Message!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type Event {
+	id: String!
+	kind: Int!
+	pubkey: String!
+	createdAt: Int!
+	content: String!
+	tags: [[String!]!]!
+}
+
+type Transfer {
+	id: String!
+	hash: String!
+	txHash: String!
+	chain: String!
+	token: String!
+	from: String!
+	to: String!
+	amount: String!
+	status: String!
+	createdAt: Int!
+	event: Event!
+	userOp: UserOp
+}
+
+type TransferConnection {
+	nodes: [Transfer!]!
+	pageInfo: PageInfo!
+}
+
+type Log {
+	id: String!
+	hash: String!
+	txHash: String!
+	chain: String!
+	topic: String!
+	sender: String!
+	to: String!
+	value: String!
+	data: String
+	status: String!
+	createdAt: Int!
+	event: Event!
+}
+
+type LogConnection {
+	nodes: [Log!]!
+	pageInfo: PageInfo!
+}
+
+type UserOp {
+	hash: String!
+	sender: String!
+	nonce: String!
+	status: String!
+	txHash: String
+	createdAt: Int!
+	event: Event!
+	transfers: [Transfer!]!
+}
+
+type UserOpConnection {
+	nodes: [UserOp!]!
+	pageInfo: PageInfo!
+}
+
+type Member {
+	pubkey: String!
+	role: String!
+}
+
+type Group {
+	id: String!
+	name: String!
+	about: String!
+	picture: String!
+	private: Boolean!
+	closed: Boolean!
+	deleted: Boolean!
+	members: [Member!]!
+	messages(first: Int, after: String): MessageConnection!
+}
+
+type Message {
+	id: String!
+	author: String!
+	content: String!
+	createdAt: Int!
+	group: Group
+	event: Event!
+}
+
+type MessageConnection {
+	nodes: [Message!]!
+	pageInfo: PageInfo!
+}
+`
+
+// NewGraphQLSchema returns the GraphQL API over a store, see GraphQLSchema
+func NewGraphQLSchema(st *store.MemoryStore, streamBuffer int) (*graphql.Schema, error) {
+	return graphql.ParseSchema(GraphQLSchema, &graphqlResolver{store: st, buffer: streamBuffer})
+}
+
+// graphqlResolver resolves the queries and subscriptions of the GraphQL API
+type graphqlResolver struct {
+	store  *store.MemoryStore
+	buffer int // Events buffered for a subscription
+}
+
+type pageArgs struct {
+	First *int32
+	After *string
+}
+
+type addressArgs struct {
+	Address *string
+	Chain   *string
+	pageArgs
+}
+
+// query returns the events of the store of kinds of the package, newest first
+func (r *graphqlResolver) query(ctx context.Context, filter nostr.Filter, kinds ...int) ([]*nostr.Event, error) {
+	filter.Kinds = event.MappedKinds(kinds...)
+	return r.store.Query(ctx, filter)
+}
+
+// Transfers returns the latest version of the transfers, of an address and a chain when given
+func (r *graphqlResolver) Transfers(ctx context.Context, args addressArgs) (*connection[*transferResolver], error) {
+	events, err := r.query(ctx, nostr.Filter{}, event.KindTxTransfer, event.KindTxTransferLegacy)
+	if err != nil {
+		return nil, err
+	}
+
+	events = latestByD(filterEvents(events, addressMatcher(args.Address, args.Chain)))
+	return paginate(events, args.pageArgs, r.transfer)
+}
+
+// Logs returns the latest version of the tx logs, of an address and a chain when given
+func (r *graphqlResolver) Logs(ctx context.Context, args addressArgs) (*connection[*logResolver], error) {
+	events, err := r.query(ctx, nostr.Filter{}, event.KindTxLog)
+	if err != nil {
+		return nil, err
+	}
+
+	events = latestByD(filterEvents(events, addressMatcher(args.Address, args.Chain)))
+	return paginate(events, args.pageArgs, newLogResolver)
+}
+
+// UserOp returns the latest event of a user operation
+func (r *graphqlResolver) UserOp(args struct{ Hash string }) *userOpResolver {
+	evt, op, ok := r.store.Views().UserOp(args.Hash)
+	if !ok {
+		return nil
+	}
+	return &userOpResolver{root: r, evt: evt, op: op}
+}
+
+// UserOps returns the latest event of the user operations, of a sender when given
+func (r *graphqlResolver) UserOps(ctx context.Context, args struct {
+	Sender *string
+	pageArgs
+}) (*connection[*userOpResolver], error) {
+	events, err := r.query(ctx, nostr.Filter{}, event.EventUserOpKind)
+	if err != nil {
+		return nil, err
+	}
+
+	events = latestByD(filterEvents(events, senderMatcher(args.Sender)))
+	return paginate(events, args.pageArgs, r.userOp)
+}
+
+// Group returns the current state of a group
+func (r *graphqlResolver) Group(args struct{ ID string }) *groupResolver {
+	state, ok := r.store.Views().Group(args.ID)
+	if !ok {
+		return nil
+	}
+	return &groupResolver{root: r, state: state}
+}
+
+// Messages returns the messages of a group
+func (r *graphqlResolver) Messages(ctx context.Context, args struct {
+	Group string
+	pageArgs
+}) (*connection[*messageResolver], error) {
+	return r.messages(ctx, args.Group, args.pageArgs)
+}
+
+func (r *graphqlResolver) messages(ctx context.Context, group string, page pageArgs) (*connection[*messageResolver], error) {
+	events, err := r.query(ctx, nostr.Filter{Tags: nostr.TagMap{"h": []string{group}}}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(events, page, r.message)
+}
+
+// subscribe forwards the events published to the store that match to a subscription until the
+// context is done
+func subscribe[T any](ctx context.Context, r *graphqlResolver, match func(*nostr.Event) bool, resolve func(*nostr.Event) (T, error)) <-chan T {
+	sub := r.store.Subscribe(r.buffer, match)
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				node, err := resolve(evt)
+				if err != nil {
+					continue
+				}
+				select {
+				case out <- node:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// kindMatcher matches the events of a kind of the package that match
+func kindMatcher(match func(*nostr.Event) bool, kinds ...int) func(*nostr.Event) bool {
+	return func(evt *nostr.Event) bool {
+		kind := event.DefaultKind(evt.Kind)
+		for _, k := range kinds {
+			if kind == k {
+				return match(evt)
+			}
+		}
+		return false
+	}
+}
+
+// TransferAdded pushes the new transfers, of an address and a chain when given
+func (r *graphqlResolver) TransferAdded(ctx context.Context, args struct {
+	Address *string
+	Chain   *string
+}) <-chan *transferResolver {
+	match := kindMatcher(addressMatcher(args.Address, args.Chain), event.KindTxTransfer, event.KindTxTransferLegacy)
+	return subscribe(ctx, r, match, r.transfer)
+}
+
+// UserOpUpdated pushes the new user operation events, of a sender when given
+func (r *graphqlResolver) UserOpUpdated(ctx context.Context, args struct{ Sender *string }) <-chan *userOpResolver {
+	return subscribe(ctx, r, kindMatcher(senderMatcher(args.Sender), event.EventUserOpKind), r.userOp)
+}
+
+// MessageAdded pushes the new messages of a group
+func (r *graphqlResolver) MessageAdded(ctx context.Context, args struct{ Group string }) <-chan *messageResolver {
+	match := kindMatcher(func(evt *nostr.Event) bool {
+		group := evt.Tags.Find("h")
+		return group != nil && len(group) >= 2 && group[1] == args.Group
+	}, 1)
+	return subscribe(ctx, r, match, r.message)
+}
+
+// addressMatcher matches the events involving an address on a chain, either is optional
+func addressMatcher(address, chain *string) func(*nostr.Event) bool {
+	return func(evt *nostr.Event) bool {
+		if address != nil && !event.IsAddressInEvent(evt, *address) {
+			return false
+		}
+		if chain != nil {
+			layer := evt.Tags.Find("layer")
+			if layer == nil || len(layer) < 2 || layer[1] != *chain {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// senderMatcher matches the user operations of a sender, every user operation when it is nil
+func senderMatcher(sender *string) func(*nostr.Event) bool {
+	return func(evt *nostr.Event) bool {
+		if sender == nil {
+			return true
+		}
+		tag := evt.Tags.Find("p")
+		return tag != nil && len(tag) >= 2 && strings.EqualFold(tag[1], *sender)
+	}
+}
+
+// filterEvents returns the events that match
+func filterEvents(events []*nostr.Event, match func(*nostr.Event) bool) []*nostr.Event {
+	matches := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if match(evt) {
+			matches = append(matches, evt)
+		}
+	}
+	return matches
+}
+
+// latestByD keeps the newest event of every d tag of a newest first list
+func latestByD(events []*nostr.Event) []*nostr.Event {
+	seen := make(map[string]bool)
+	latest := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		d := evt.Tags.GetD()
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		latest = append(latest, evt)
+	}
+	return latest
+}
+
+// connection is a page of nodes
+type connection[T any] struct {
+	nodes []T
+	next  *event.Cursor
+}
+
+func (c *connection[T]) Nodes() []T {
+	return c.nodes
+}
+
+func (c *connection[T]) PageInfo() (*pageInfoResolver, error) {
+	if c.next == nil {
+		return &pageInfoResolver{}, nil
+	}
+
+	cursor, err := encodeCursor(c.next)
+	if err != nil {
+		return nil, err
+	}
+	return &pageInfoResolver{hasNextPage: true, endCursor: &cursor}, nil
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool  { return p.hasNextPage }
+func (p *pageInfoResolver) EndCursor() *string { return p.endCursor }
+
+// paginate returns the page of events after a cursor, resolved into nodes. Events that fail to
+// resolve, e.g. with malformed content, are left out.
+func paginate[T any](events []*nostr.Event, page pageArgs, resolve func(*nostr.Event) (T, error)) (*connection[T], error) {
+	size := DefaultGraphQLPageSize
+	if page.First != nil {
+		if *page.First <= 0 || *page.First > MaxGraphQLPageSize {
+			return nil, fmt.Errorf("first must be between 1 and %d", MaxGraphQLPageSize)
+		}
+		size = int(*page.First)
+	}
+
+	var cursor *event.Cursor
+	if page.After != nil {
+		var err error
+		if cursor, err = decodeCursor(*page.After); err != nil {
+			return nil, err
+		}
+	}
+
+	p := event.NewPaginator(events, size).Page(cursor)
+
+	c := &connection[T]{nodes: make([]T, 0, len(p.Events)), next: p.Next}
+	for _, evt := range p.Events {
+		node, err := resolve(evt)
+		if err != nil {
+			continue
+		}
+		c.nodes = append(c.nodes, node)
+	}
+	return c, nil
+}
+
+// encodeCursor returns the opaque form of a cursor
+func encodeCursor(cursor *event.Cursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor returned as the endCursor of a page
+func decodeCursor(value string) (*event.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor event.Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
+
+type eventResolver struct {
+	evt *nostr.Event
+}
+
+func (e *eventResolver) ID() string       { return e.evt.ID }
+func (e *eventResolver) Kind() int32      { return int32(e.evt.Kind) }
+func (e *eventResolver) Pubkey() string   { return e.evt.PubKey }
+func (e *eventResolver) CreatedAt() int32 { return int32(e.evt.CreatedAt) }
+func (e *eventResolver) Content() string  { return e.evt.Content }
+func (e *eventResolver) Tags() [][]string {
+	tags := make([][]string, len(e.evt.Tags))
+	for i, tag := range e.evt.Tags {
+		tags[i] = tag
+	}
+	return tags
+}
+
+type transferResolver struct {
+	root     *graphqlResolver
+	evt      *nostr.Event
+	transfer *event.TxTransferEvent
+	data     neth.LogTransferData
+}
+
+// transfer resolves a transfer event
+func (r *graphqlResolver) transfer(evt *nostr.Event) (*transferResolver, error) {
+	transfer, err := event.ParseTxTransferEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &transferResolver{root: r, evt: evt, transfer: transfer}
+	if data, err := transfer.LogData.GetTransferData(); err == nil && data != nil {
+		t.data = *data
+	}
+	return t, nil
+}
+
+func (t *transferResolver) ID() string            { return t.evt.ID }
+func (t *transferResolver) Hash() string          { return t.transfer.LogData.Hash }
+func (t *transferResolver) TxHash() string        { return t.transfer.LogData.TxHash }
+func (t *transferResolver) Chain() string         { return t.transfer.LogData.ChainID }
+func (t *transferResolver) Token() string         { return t.transfer.LogData.To }
+func (t *transferResolver) From() string          { return t.data.From }
+func (t *transferResolver) To() string            { return t.data.To }
+func (t *transferResolver) Amount() string        { return t.data.Value }
+func (t *transferResolver) Status() string        { return string(t.transfer.EventType) }
+func (t *transferResolver) CreatedAt() int32      { return int32(t.evt.CreatedAt) }
+func (t *transferResolver) Event() *eventResolver { return &eventResolver{evt: t.evt} }
+
+// UserOp returns the user operation whose transaction made the transfer
+func (t *transferResolver) UserOp(ctx context.Context) (*userOpResolver, error) {
+	events, err := t.root.query(ctx, nostr.Filter{}, event.EventUserOpKind)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, evt := range latestByD(events) {
+		op, err := t.root.userOp(evt)
+		if err == nil && op.op.TxHash != nil && strings.EqualFold(*op.op.TxHash, t.transfer.LogData.TxHash) {
+			return op, nil
+		}
+	}
+	return nil, nil
+}
+
+type logResolver struct {
+	evt *nostr.Event
+	log *event.TxLogEvent
+}
+
+// newLogResolver resolves a tx log event
+func newLogResolver(evt *nostr.Event) (*logResolver, error) {
+	log, err := event.ParseTxLogEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+	return &logResolver{evt: evt, log: log}, nil
+}
+
+func (l *logResolver) ID() string     { return l.evt.ID }
+func (l *logResolver) Hash() string   { return l.log.LogData.Hash }
+func (l *logResolver) TxHash() string { return l.log.LogData.TxHash }
+func (l *logResolver) Chain() string  { return l.log.LogData.ChainID }
+func (l *logResolver) Topic() string  { return l.log.LogData.Topic }
+func (l *logResolver) Sender() string { return l.log.LogData.Sender }
+func (l *logResolver) To() string     { return l.log.LogData.To }
+func (l *logResolver) Status() string { return string(l.log.Status) }
+
+func (l *logResolver) Value() string {
+	if l.log.LogData.Value == nil {
+		return "0"
+	}
+	return l.log.LogData.Value.String()
+}
+
+func (l *logResolver) Data() *string {
+	if l.log.LogData.Data == nil {
+		return nil
+	}
+	data := string(*l.log.LogData.Data)
+	return &data
+}
+
+func (l *logResolver) CreatedAt() int32      { return int32(l.evt.CreatedAt) }
+func (l *logResolver) Event() *eventResolver { return &eventResolver{evt: l.evt} }
+
+type userOpResolver struct {
+	root *graphqlResolver
+	evt  *nostr.Event
+	op   *event.UserOpEvent
+}
+
+// userOp resolves a user operation event
+func (r *graphqlResolver) userOp(evt *nostr.Event) (*userOpResolver, error) {
+	op, err := event.ParseUserOpEvent(evt)
+	if err != nil {
+		return nil, err
+	}
+	return &userOpResolver{root: r, evt: evt, op: op}, nil
+}
+
+func (u *userOpResolver) Hash() string          { return u.evt.Tags.GetD() }
+func (u *userOpResolver) Sender() string        { return u.op.UserOpData.Sender.Hex() }
+func (u *userOpResolver) Status() string        { return string(u.op.EventType) }
+func (u *userOpResolver) TxHash() *string       { return u.op.TxHash }
+func (u *userOpResolver) CreatedAt() int32      { return int32(u.evt.CreatedAt) }
+func (u *userOpResolver) Event() *eventResolver { return &eventResolver{evt: u.evt} }
+
+func (u *userOpResolver) Nonce() string {
+	if u.op.UserOpData.Nonce == nil {
+		return "0"
+	}
+	return u.op.UserOpData.Nonce.String()
+}
+
+// Transfers returns the transfers made by the transaction of the user operation
+func (u *userOpResolver) Transfers(ctx context.Context) ([]*transferResolver, error) {
+	if u.op.TxHash == nil {
+		return []*transferResolver{}, nil
+	}
+
+	events, err := u.root.query(ctx, nostr.Filter{Tags: nostr.TagMap{"r": []string{*u.op.TxHash}}}, event.KindTxTransfer, event.KindTxTransferLegacy)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]*transferResolver, 0, len(events))
+	for _, evt := range latestByD(events) {
+		if transfer, err := u.root.transfer(evt); err == nil {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+type groupResolver struct {
+	root  *graphqlResolver
+	state *event.GroupState
+}
+
+func (g *groupResolver) ID() string      { return g.state.GroupID }
+func (g *groupResolver) Name() string    { return g.state.Metadata.Name }
+func (g *groupResolver) About() string   { return g.state.Metadata.About }
+func (g *groupResolver) Picture() string { return g.state.Metadata.Picture }
+func (g *groupResolver) Private() bool   { return g.state.Metadata.Private }
+func (g *groupResolver) Closed() bool    { return g.state.Metadata.Closed }
+func (g *groupResolver) Deleted() bool   { return g.state.Deleted }
+
+// Members returns the members of the group by pubkey
+func (g *groupResolver) Members() []*memberResolver {
+	members := make([]*memberResolver, 0, len(g.state.Members))
+	for pubkey, role := range g.state.Members {
+		members = append(members, &memberResolver{pubkey: pubkey, role: role})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].pubkey < members[j].pubkey })
+	return members
+}
+
+// Messages returns the messages of the group
+func (g *groupResolver) Messages(ctx context.Context, args pageArgs) (*connection[*messageResolver], error) {
+	return g.root.messages(ctx, g.state.GroupID, args)
+}
+
+type memberResolver struct {
+	pubkey string
+	role   string
+}
+
+func (m *memberResolver) Pubkey() string { return m.pubkey }
+func (m *memberResolver) Role() string   { return m.role }
+
+type messageResolver struct {
+	root *graphqlResolver
+	evt  *nostr.Event
+}
+
+// message resolves a group message
+func (r *graphqlResolver) message(evt *nostr.Event) (*messageResolver, error) {
+	return &messageResolver{root: r, evt: evt}, nil
+}
+
+func (m *messageResolver) ID() string            { return m.evt.ID }
+func (m *messageResolver) Author() string        { return m.evt.PubKey }
+func (m *messageResolver) Content() string       { return m.evt.Content }
+func (m *messageResolver) CreatedAt() int32      { return int32(m.evt.CreatedAt) }
+func (m *messageResolver) Event() *eventResolver { return &eventResolver{evt: m.evt} }
+
+// Group returns the group the message was sent to
+func (m *messageResolver) Group() *groupResolver {
+	tag := m.evt.Tags.Find("h")
+	if tag == nil || len(tag) < 2 {
+		return nil
+	}
+	return m.root.Group(struct{ ID string }{ID: tag[1]})
+}
+
+// graphqlRequest is the body of a GraphQL request and the payload of a subscribe message
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphql executes a query of the GraphQL API
+func (h *HTTPHandler) graphql(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPublishSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req graphqlRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid GraphQL request: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+// graphql-transport-ws message types
+const (
+	gqlConnectionInit = "connection_init"
+	gqlConnectionAck  = "connection_ack"
+	gqlPing           = "ping"
+	gqlPong           = "pong"
+	gqlSubscribe      = "subscribe"
+	gqlNext           = "next"
+	gqlError          = "error"
+	gqlComplete       = "complete"
+)
+
+// graphql-transport-ws close codes
+const (
+	gqlInvalidMessage      websocket.StatusCode = 4400
+	gqlUnauthorized        websocket.StatusCode = 4401
+	gqlSubscriberExists    websocket.StatusCode = 4409
+	gqlTooManyInitRequests websocket.StatusCode = 4429
+)
+
+// gqlMessage is a message of the graphql-transport-ws protocol
+type gqlMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphqlSubscriptions serves subscriptions over the graphql-transport-ws protocol of the graphql-ws
+// library. Operations are identified by the client and run until they complete or the client
+// sends complete.
+func (h *HTTPHandler) graphqlSubscriptions(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols:   []string{"graphql-transport-ws"},
+		OriginPatterns: h.origins,
+	})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	var mu sync.Mutex
+	operations := make(map[string]context.CancelFunc)
+	initialized := false
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		var msg gqlMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			conn.Close(gqlInvalidMessage, "invalid message")
+			return
+		}
+
+		switch msg.Type {
+		case gqlConnectionInit:
+			if initialized {
+				conn.Close(gqlTooManyInitRequests, "too many initialisation requests")
+				return
+			}
+			initialized = true
+			if err := h.writeGraphQL(ctx, conn, gqlMessage{Type: gqlConnectionAck}); err != nil {
+				return
+			}
+
+		case gqlPing:
+			if err := h.writeGraphQL(ctx, conn, gqlMessage{Type: gqlPong}); err != nil {
+				return
+			}
+
+		case gqlPong:
+
+		case gqlSubscribe:
+			if !initialized {
+				conn.Close(gqlUnauthorized, "unauthorized")
+				return
+			}
+
+			var req graphqlRequest
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				conn.Close(gqlInvalidMessage, "invalid subscribe message")
+				return
+			}
+
+			mu.Lock()
+			if _, ok := operations[msg.ID]; ok {
+				mu.Unlock()
+				conn.Close(gqlSubscriberExists, "subscriber for "+msg.ID+" already exists")
+				return
+			}
+			opCtx, opCancel := context.WithCancel(ctx)
+			operations[msg.ID] = opCancel
+			mu.Unlock()
+
+			go func(id string) {
+				defer func() {
+					mu.Lock()
+					delete(operations, id)
+					mu.Unlock()
+					opCancel()
+				}()
+				h.runGraphQL(opCtx, conn, id, req)
+			}(msg.ID)
+
+		case gqlComplete:
+			mu.Lock()
+			if opCancel, ok := operations[msg.ID]; ok {
+				opCancel()
+			}
+			mu.Unlock()
+
+		default:
+			conn.Close(gqlInvalidMessage, "unknown message type: "+msg.Type)
+			return
+		}
+	}
+}
+
+// runGraphQL sends the results of an operation to a client, then completes it unless the client
+// did
+func (h *HTTPHandler) runGraphQL(ctx context.Context, conn *websocket.Conn, id string, req graphqlRequest) {
+	results, err := h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		payload, _ := json.Marshal([]map[string]string{{"message": err.Error()}})
+		h.writeGraphQL(ctx, conn, gqlMessage{ID: id, Type: gqlError, Payload: payload})
+		return
+	}
+
+	// The schema blocks until its results are received, they are drained once the operation stops
+	defer func() {
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				h.writeGraphQL(ctx, conn, gqlMessage{ID: id, Type: gqlComplete})
+				return
+			}
+
+			payload, err := json.Marshal(result)
+			if err != nil {
+				continue
+			}
+			if err := h.writeGraphQL(ctx, conn, gqlMessage{ID: id, Type: gqlNext, Payload: payload}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeGraphQL writes a message to a subscription client, which is closed when it is too slow
+func (h *HTTPHandler) writeGraphQL(ctx context.Context, conn *websocket.Conn, msg gqlMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.streamWriteTimeout)
+	defer cancel()
+
+	if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			conn.Close(websocket.StatusPolicyViolation, "too slow")
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const graphqlSender = "0x1111111111111111111111111111111111111111"
+
+// transferEvent returns a transfer of graphqlSender in a transaction
+func transferEvent(t *testing.T, hash, txHash string, createdAt int64) *nostr.Event {
+	t.Helper()
+
+	data := json.RawMessage(`{"from":"` + graphqlSender + `","to":"0x2222222222222222222222222222222222222222","value":"1000"}`)
+	evt, err := event.CreateTxTransferEvent(neth.Log{
+		Hash:      hash,
+		TxHash:    txHash,
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(createdAt, 0),
+		Sender:    graphqlSender,
+		To:        "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d",
+		Value:     big.NewInt(0),
+		Data:      &data,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transfer event: %v", err)
+	}
+	evt.ID = hash
+	return evt
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemoryStore()
+
+	// Transfers share timestamps to paginate across them
+	for i := 0; i < 5; i++ {
+		evt := transferEvent(t, fmt.Sprintf("0x%02d", i), fmt.Sprintf("0xtx%d", i), 1700000000+int64(i/2))
+		if err := st.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	txHash := "0xtx4"
+	userOpEvent, err := event.CreateUserOpEvent(big.NewInt(100), nil, nil, nil, &txHash, 0, neth.UserOp{
+		Sender:               common.HexToAddress(graphqlSender),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000),
+	}, event.EventTypeUserOpConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+	userOpEvent.ID = "userop"
+
+	groupID := "test-group"
+	create, _ := event.CreateGroupEvent(groupID, "Test Group", "", "", []string{"admin1"}, nil, false, false)
+	create.ID, create.PubKey, create.CreatedAt = "group", "creator", 100
+	message, _ := event.CreateMessageEvent("hello", &groupID)
+	message.ID, message.PubKey = "message", "author"
+
+	for _, evt := range []*nostr.Event{userOpEvent, create, message} {
+		if err := st.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	schema, err := NewGraphQLSchema(st, DefaultStreamBuffer)
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	exec := func(query string, variables map[string]any, out any) {
+		t.Helper()
+		resp := schema.Exec(ctx, query, "", variables)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("Failed to execute %s: %v", query, resp.Errors)
+		}
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+
+	var transfers struct {
+		Transfers struct {
+			Nodes []struct {
+				Hash   string
+				From   string
+				Amount string
+				UserOp *struct{ Status string }
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   *string
+			}
+		}
+	}
+	query := `query($after: String) {
+		transfers(address: "` + graphqlSender + `", chain: "100", first: 2, after: $after) {
+			nodes { hash from amount userOp { status } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	var hashes []string
+	variables := map[string]any{}
+	for i := 0; i < 5; i++ {
+		exec(query, variables, &transfers)
+		for _, node := range transfers.Transfers.Nodes {
+			hashes = append(hashes, node.Hash)
+		}
+		if !transfers.Transfers.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = *transfers.Transfers.PageInfo.EndCursor
+	}
+	// Transfers of the same second are ordered by ID
+	if got := strings.Join(hashes, ","); got != "0x04,0x02,0x03,0x00,0x01" {
+		t.Errorf("Expected every transfer once, newest first, got %s", got)
+	}
+
+	exec(`{ transfers(first: 1) { nodes { hash from amount userOp { status } } } }`, nil, &transfers)
+	node := transfers.Transfers.Nodes[0]
+	if node.From != graphqlSender || node.Amount != "1000" {
+		t.Errorf("Expected a transfer of 1000 from %s, got %+v", graphqlSender, node)
+	}
+	if node.UserOp == nil || node.UserOp.Status != string(event.EventTypeUserOpConfirmed) {
+		t.Errorf("Expected the confirmed user op of the transfer, got %+v", node.UserOp)
+	}
+
+	var userOps struct {
+		UserOps struct {
+			Nodes []struct {
+				Sender    string
+				Nonce     string
+				Transfers []struct{ Hash string }
+			}
+		}
+	}
+	exec(`{ userOps(sender: "`+graphqlSender+`") { nodes { sender nonce transfers { hash } } } }`, nil, &userOps)
+	if len(userOps.UserOps.Nodes) != 1 {
+		t.Fatalf("Expected 1 user op, got %d", len(userOps.UserOps.Nodes))
+	}
+	if op := userOps.UserOps.Nodes[0]; op.Nonce != "1" || len(op.Transfers) != 1 || op.Transfers[0].Hash != "0x04" {
+		t.Errorf("Expected user op 1 with transfer 0x04, got %+v", op)
+	}
+
+	var group struct {
+		Group struct {
+			Name     string
+			Members  []struct{ Pubkey, Role string }
+			Messages struct {
+				Nodes []struct {
+					Content string
+					Author  string
+					Group   struct{ ID string }
+				}
+			}
+		}
+	}
+	exec(`{ group(id: "test-group") { name members { pubkey role } messages { nodes { content author group { id } } } } }`, nil, &group)
+	if group.Group.Name != "Test Group" {
+		t.Errorf("Expected group Test Group, got %s", group.Group.Name)
+	}
+	if len(group.Group.Members) != 1 || group.Group.Members[0].Pubkey != "admin1" {
+		t.Errorf("Expected admin1 as the only member, got %v", group.Group.Members)
+	}
+	if msgs := group.Group.Messages.Nodes; len(msgs) != 1 || msgs[0].Content != "hello" || msgs[0].Group.ID != groupID {
+		t.Errorf("Expected the hello message of the group, got %+v", msgs)
+	}
+
+	for _, q := range []string{`{ transfers(first: 0) { nodes { hash } } }`, `{ transfers(after: "invalid") { nodes { hash } } }`} {
+		if resp := schema.Exec(ctx, q, "", nil); len(resp.Errors) == 0 {
+			t.Errorf("Expected %s to fail", q)
+		}
+	}
+}
+
+func TestHTTPHandlerGraphQL(t *testing.T) {
+	st := store.NewMemoryStore()
+	h := NewHTTPHandler(NewServer("", nil, nil), st)
+
+	if err := st.Publish(context.Background(), *transferEvent(t, "0x01", "0xtx1", 1700000000)); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	var resp struct {
+		Data struct {
+			Logs      struct{ Nodes []struct{ Hash string } }
+			Transfers struct{ Nodes []struct{ Hash string } }
+		}
+	}
+	body := graphqlRequest{Query: `{ transfers { nodes { hash } } logs { nodes { hash } } }`}
+	if code := serveJSON(t, h, http.MethodPost, "/graphql", body, &resp); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(resp.Data.Transfers.Nodes) != 1 || resp.Data.Transfers.Nodes[0].Hash != "0x01" {
+		t.Errorf("Expected transfer 0x01, got %v", resp.Data.Transfers.Nodes)
+	}
+	if len(resp.Data.Logs.Nodes) != 0 {
+		t.Errorf("Expected no logs, got %v", resp.Data.Logs.Nodes)
+	}
+}
+
+func TestHTTPHandlerGraphQLSubscription(t *testing.T) {
+	st := store.NewMemoryStore()
+	server := httptest.NewServer(NewHTTPHandler(NewServer("", nil, nil), st))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/graphql"
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{Subprotocols: []string{"graphql-transport-ws"}})
+	if err != nil {
+		t.Fatalf("Failed to open subscriptions: %v", err)
+	}
+	defer conn.CloseNow()
+
+	write := func(msg gqlMessage) {
+		t.Helper()
+		data, _ := json.Marshal(msg)
+		if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+	}
+	read := func() gqlMessage {
+		t.Helper()
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var msg gqlMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		return msg
+	}
+
+	write(gqlMessage{Type: gqlConnectionInit})
+	if msg := read(); msg.Type != gqlConnectionAck {
+		t.Fatalf("Expected %s, got %s", gqlConnectionAck, msg.Type)
+	}
+
+	payload, _ := json.Marshal(graphqlRequest{Query: `subscription { transferAdded(chain: "100") { hash amount } }`})
+	write(gqlMessage{ID: "1", Type: gqlSubscribe, Payload: payload})
+
+	// Wait for the subscription to the store
+	for i := 0; i < 100 && st.Subscribers() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := st.Publish(ctx, *transferEvent(t, "0x01", "0xtx1", 1700000000)); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	msg := read()
+	if msg.Type != gqlNext || msg.ID != "1" {
+		t.Fatalf("Expected next of 1, got %s of %s", msg.Type, msg.ID)
+	}
+	var result struct {
+		Data struct {
+			TransferAdded struct{ Hash, Amount string }
+		}
+	}
+	if err := json.Unmarshal(msg.Payload, &result); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if result.Data.TransferAdded.Hash != "0x01" || result.Data.TransferAdded.Amount != "1000" {
+		t.Errorf("Expected transfer 0x01 of 1000, got %+v", result.Data.TransferAdded)
+	}
+
+	write(gqlMessage{ID: "1", Type: gqlComplete})
+	for i := 0; i < 100 && st.Subscribers() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := st.Subscribers(); n != 0 {
+		t.Errorf("Expected the subscription to be closed, got %d subscribers", n)
+	}
+
+	write(gqlMessage{Type: gqlPing})
+	if msg := read(); msg.Type != gqlPong {
+		t.Errorf("Expected %s, got %s", gqlPong, msg.Type)
+	}
+}
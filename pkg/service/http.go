@@ -0,0 +1,304 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/nbd-wtf/go-nostr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxPublishSize is the maximum size of the body of a publish request
+const maxPublishSize = 1 << 20
+
+// DefaultHTTPLimit is the number of items returned by the HTTP API when no limit is given
+const DefaultHTTPLimit = 100
+
+// HTTPHandler exposes a store and the publishing of a server over a REST API, so that web
+// frontends can consume bridge data without a Nostr client:
+//
+//	GET  /addresses/{address}/transfers  latest version of the logs involving an address
+//	GET  /userops/{hash}                 latest event of a user operation
+//	GET  /groups/{id}/events             events of a group, newest first
+//	GET  /events?q=...                   events matching a filter expression, newest first
+//	POST /publish                        publish an event, see PublishRequest
+//	GET  /stream                         WebSocket of the new events, see StreamMessage
+//	GET  /stream/sse                     the same stream as server-sent events
+//	GET  /feeds/addresses/{address}      cacheable feed of the latest transfers of an address
+//	GET  /feeds/groups/{id}              cacheable feed of the latest transfers of a group's tokens
+//	POST /graphql                        GraphQL query, see GraphQLSchema
+//	GET  /graphql                        WebSocket of GraphQL subscriptions (graphql-transport-ws)
+//
+// Lists take a limit query parameter, DefaultHTTPLimit when it is missing. Publishing requires
+// NIP-98 auth when an Authenticator is given with WithAuth.
+type HTTPHandler struct {
+	server *Server
+	store  *store.MemoryStore
+	schema *graphql.Schema
+	mux    *http.ServeMux
+
+	origins            []string
+	streamBuffer       int
+	streamWriteTimeout time.Duration
+	streamKeepAlive    time.Duration
+
+	feedTokens *event.TokenRegistry
+
+	auth *Authenticator // Protects the write endpoints when set
+}
+
+// HTTPOption configures an HTTPHandler
+type HTTPOption func(*HTTPHandler)
+
+// WithStreamOrigins allows browsers on other origins to open the stream, e.g. "app.example.com"
+// or "*.example.com"
+func WithStreamOrigins(patterns ...string) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.origins = patterns
+	}
+}
+
+// WithStreamBuffer sets the number of events buffered for each stream client
+func WithStreamBuffer(size int) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.streamBuffer = size
+	}
+}
+
+// WithStreamWriteTimeout sets how long a stream client has to receive a message
+func WithStreamWriteTimeout(timeout time.Duration) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.streamWriteTimeout = timeout
+	}
+}
+
+// WithStreamKeepAlive sets how often an idle SSE stream is written to
+func WithStreamKeepAlive(interval time.Duration) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.streamKeepAlive = interval
+	}
+}
+
+// WithFeedTokens sets the registry giving the symbol and decimals of the tokens of the feeds,
+// event.DefaultTokenRegistry by default
+func WithFeedTokens(tokens *event.TokenRegistry) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.feedTokens = tokens
+	}
+}
+
+// WithAuth requires NIP-98 auth on the write endpoints
+func WithAuth(auth *Authenticator) HTTPOption {
+	return func(h *HTTPHandler) {
+		h.auth = auth
+	}
+}
+
+// PublishRequest is the body of a publish request, the event is published to the relays of the
+// server when none are given. Other relays must be allowed with Server.AllowRelays, they are
+// refused with 403 otherwise. Events whose id is not the hash of their content are refused with
+// 400. Unsigned events are only signed with the key of the server for the pubkeys allowed by
+// WithAuth, they are refused with 403 otherwise.
+type PublishRequest struct {
+	Event  nostr.Event `json:"event"`
+	Relays []string    `json:"relays,omitempty"`
+}
+
+// PublishResponse is the answer to a publish request
+type PublishResponse struct {
+	Event  nostr.Event   `json:"event"`
+	Result PublishResult `json:"result"`
+}
+
+// UserOpResponse is the latest event of a user operation with its parsed content
+type UserOpResponse struct {
+	Event  *nostr.Event       `json:"event"`
+	UserOp *event.UserOpEvent `json:"user_op"`
+}
+
+// NewHTTPHandler creates a new REST API over a store, publishing through a server
+func NewHTTPHandler(server *Server, st *store.MemoryStore, opts ...HTTPOption) *HTTPHandler {
+	h := &HTTPHandler{
+		server:             server,
+		store:              st,
+		mux:                http.NewServeMux(),
+		streamBuffer:       DefaultStreamBuffer,
+		streamWriteTimeout: DefaultStreamWriteTimeout,
+		streamKeepAlive:    DefaultStreamKeepAlive,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// The schema is a constant, it only fails to parse when resolvers do not match it
+	h.schema = graphql.MustParseSchema(GraphQLSchema, &graphqlResolver{store: st, buffer: h.streamBuffer})
+
+	h.mux.HandleFunc("GET /addresses/{address}/transfers", h.transfers)
+	h.mux.HandleFunc("GET /userops/{hash}", h.userOp)
+	h.mux.HandleFunc("GET /groups/{id}/events", h.groupEvents)
+	h.mux.HandleFunc("GET /events", h.events)
+	var publish http.Handler = http.HandlerFunc(h.publish)
+	if h.auth != nil {
+		publish = h.auth.Handler(publish)
+	}
+	h.mux.Handle("POST /publish", publish)
+	h.mux.HandleFunc("GET /stream", h.stream)
+	h.mux.HandleFunc("GET /stream/sse", h.streamSSE)
+	h.mux.HandleFunc("GET /feeds/addresses/{address}", h.addressFeed)
+	h.mux.HandleFunc("GET /feeds/groups/{id}", h.groupFeed)
+	h.mux.HandleFunc("POST /graphql", h.graphql)
+	h.mux.HandleFunc("GET /graphql", h.graphqlSubscriptions)
+
+	return h
+}
+
+// ServeHTTP routes a request to its endpoint
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *HTTPHandler) transfers(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.store.Views().LatestLogs(r.PathValue("address"), limit))
+}
+
+func (h *HTTPHandler) userOp(w http.ResponseWriter, r *http.Request) {
+	evt, userOp, ok := h.store.Views().UserOp(r.PathValue("hash"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "user operation not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, UserOpResponse{Event: evt, UserOp: userOp})
+}
+
+func (h *HTTPHandler) groupEvents(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+
+	filter := nostr.Filter{Tags: nostr.TagMap{"h": []string{r.PathValue("id")}}, Limit: limit}
+	events, err := h.store.Query(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, nonNil(events))
+}
+
+func (h *HTTPHandler) events(w http.ResponseWriter, r *http.Request) {
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+
+	q, err := store.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid query: "+err.Error())
+		return
+	}
+
+	events, err := q.Run(r.Context(), h.store, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, nonNil(events))
+}
+
+func (h *HTTPHandler) publish(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPublishSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req PublishRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid publish request: "+err.Error())
+		return
+	}
+
+	resp, err := h.server.PublishEvent(r.Context(), &pb.PublishEventRequest{Event: pb.FromEvent(&req.Event), Relays: req.Relays})
+	if err != nil {
+		writeError(w, httpStatus(err), status.Convert(err).Message())
+		return
+	}
+
+	// Events accepted by a relay are served right away, before they are ingested back
+	evt := resp.GetEvent().ToEvent()
+	result := NewPublishResult(evt.ID, resp.GetResults())
+	if len(result.Accepted()) > 0 {
+		if err := h.store.Publish(r.Context(), *evt); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, PublishResponse{Event: *evt, Result: result})
+}
+
+// limitParam returns the limit query parameter of a request, an error is written when it is
+// invalid
+func limitParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return DefaultHTTPLimit, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid limit: "+value)
+		return 0, false
+	}
+	return limit, true
+}
+
+// httpStatus returns the HTTP status of a gRPC error of the server
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// nonNil returns an empty list for nil, so that it is encoded as [] instead of null
+func nonNil(events []*nostr.Event) []*nostr.Event {
+	if events == nil {
+		return []*nostr.Event{}
+	}
+	return events
+}
+
+// writeJSON writes a value as a JSON response
+func writeJSON(w http.ResponseWriter, code int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	ctx := context.Background()
+	key := nostr.GeneratePrivateKey()
+	publisher := &recordingPublisher{}
+	st := store.NewMemoryStore()
+	server := NewServer(key, []string{"wss://relay.example.com"}, publisher)
+	h := NewHTTPHandler(server, st)
+
+	sender := "0x1111111111111111111111111111111111111111"
+	txLog, err := event.CreateTxLogEvent(neth.Log{
+		Hash:      "0xabc",
+		TxHash:    "0xdef",
+		ChainID:   "100",
+		Topic:     "0x01",
+		CreatedAt: time.Unix(1700000000, 0),
+		Sender:    sender,
+		To:        "0x2222222222222222222222222222222222222222",
+		Value:     big.NewInt(42),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+
+	chainID := big.NewInt(100)
+	userOp := neth.UserOp{
+		Sender:               common.HexToAddress(sender),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000),
+	}
+	userOpEvent, err := event.CreateUserOpEvent(chainID, nil, nil, nil, nil, 0, userOp, event.EventTypeUserOpRequested)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+
+	groupID := "test-group"
+	message, err := event.CreateMessageEvent("hello", &groupID)
+	if err != nil {
+		t.Fatalf("Failed to create message event: %v", err)
+	}
+
+	for _, evt := range []*nostr.Event{txLog, userOpEvent, message} {
+		if err := evt.Sign(key); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+		if err := st.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	var logs []neth.Log
+	if code := serveJSON(t, h, http.MethodGet, "/addresses/"+sender+"/transfers", nil, &logs); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(logs) != 1 || logs[0].Hash != "0xabc" {
+		t.Errorf("Expected log 0xabc, got %v", logs)
+	}
+
+	var op UserOpResponse
+	if code := serveJSON(t, h, http.MethodGet, "/userops/"+userOp.GetHash(chainID), nil, &op); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if op.Event.ID != userOpEvent.ID || op.UserOp.EventType != event.EventTypeUserOpRequested {
+		t.Errorf("Expected the requested user op, got %+v", op)
+	}
+	if code := serveJSON(t, h, http.MethodGet, "/userops/0x00", nil, nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+
+	var events []*nostr.Event
+	if code := serveJSON(t, h, http.MethodGet, "/groups/"+groupID+"/events", nil, &events); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(events) != 1 || events[0].ID != message.ID {
+		t.Errorf("Expected the group message, got %v", events)
+	}
+
+	if code := serveJSON(t, h, http.MethodGet, `/events?q=chain%3D100&limit=5`, nil, &events); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected 2 events on chain 100, got %d", len(events))
+	}
+	if code := serveJSON(t, h, http.MethodGet, `/events?q=chain%3D`, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+	if code := serveJSON(t, h, http.MethodGet, `/groups/x/events?limit=-1`, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+
+	// Unsigned events are not signed by the server without auth
+	note := nostr.Event{Kind: 1, Content: "hi", CreatedAt: nostr.Now(), Tags: nostr.Tags{{"h", groupID}}}
+	if code := serveJSON(t, h, http.MethodPost, "/publish", PublishRequest{Event: note}, nil); code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", code)
+	}
+
+	// Signed events are published and served
+	note.Sign(nostr.GeneratePrivateKey())
+	var published PublishResponse
+	if code := serveJSON(t, h, http.MethodPost, "/publish", PublishRequest{Event: note}, &published); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if published.Event.ID != note.ID || len(published.Result.Accepted()) != 1 {
+		t.Errorf("Expected the signed event accepted by one relay, got %+v", published)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	if _, ok := st.Snapshot().Get(published.Event.ID); !ok {
+		t.Error("Expected the published event to be stored")
+	}
+
+	tampered := published.Event
+	tampered.Content = "tampered"
+	if code := serveJSON(t, h, http.MethodPost, "/publish", PublishRequest{Event: tampered}, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+
+	// The signature does not cover the id, which must be the hash of the event
+	edited := published.Event
+	edited.ID = strings.Repeat("0", 64)
+	if code := serveJSON(t, h, http.MethodPost, "/publish", PublishRequest{Event: edited}, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an edited id, got %d", code)
+	}
+	if _, ok := st.Snapshot().Get(edited.ID); ok || len(publisher.published) != 1 {
+		t.Error("Expected an event with an edited id not to be published")
+	}
+
+	// Only the relays of the server and the allowed ones are published to
+	other := PublishRequest{Event: note, Relays: []string{"ws://127.0.0.1:6379"}}
+	if code := serveJSON(t, h, http.MethodPost, "/publish", other, nil); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for another relay, got %d", code)
+	}
+	if len(publisher.published) != 1 {
+		t.Errorf("Expected nothing to be published to another relay, got %d events", len(publisher.published))
+	}
+
+	server.AllowRelays([]string{"ws://127.0.0.1:6379/"})
+	if code := serveJSON(t, h, http.MethodPost, "/publish", other, nil); code != http.StatusOK {
+		t.Errorf("Expected status 200 for an allowed relay, got %d", code)
+	}
+	if code := serveJSON(t, h, http.MethodPost, "/publish", PublishRequest{Event: note, Relays: []string{"wss://relay.example.com/"}}, nil); code != http.StatusOK {
+		t.Errorf("Expected status 200 for a relay of the server, got %d", code)
+	}
+}
+
+// serveJSON sends a request to a handler, decodes the JSON answer into out when it is not nil and
+// returns the status
+func serveJSON(t *testing.T, h http.Handler, method, target string, body, out any) int {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(method, target, reader))
+
+	if out != nil && recorder.Code == http.StatusOK {
+		if err := json.NewDecoder(recorder.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return recorder.Code
+}
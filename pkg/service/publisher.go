@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// PoolPublisher publishes events with a go-nostr relay pool
+type PoolPublisher struct {
+	pool       *nostr.SimplePool
+	resolver   *event.RelayListResolver
+	limits     *event.RelayLimits
+	onOversize OversizeHandler
+}
+
+// OversizeHandler shrinks an event exceeding the relay limits, e.g. by compressing or offloading
+// its content, and returns the signed event to publish instead
+type OversizeHandler func(ctx context.Context, evt nostr.Event, err error) (nostr.Event, error)
+
+// PublisherOption configures a pool publisher
+type PublisherOption func(*PoolPublisher)
+
+// WithRelayListResolver copies events tagging users to the read relays of their NIP-65 relay
+// lists (the outbox model), on top of the relays the event is published to
+func WithRelayListResolver(resolver *event.RelayListResolver) PublisherOption {
+	return func(p *PoolPublisher) {
+		p.resolver = resolver
+	}
+}
+
+// WithRelayLimits checks events against the limits of the relays before publishing them, events
+// exceeding them are not sent and fail on every relay
+func WithRelayLimits(limits event.RelayLimits) PublisherOption {
+	return func(p *PoolPublisher) {
+		p.limits = &limits
+	}
+}
+
+// WithOversizeHandler sets the handler given the events exceeding the relay limits, the event it
+// returns is checked again before it is published
+func WithOversizeHandler(handler OversizeHandler) PublisherOption {
+	return func(p *PoolPublisher) {
+		p.onOversize = handler
+	}
+}
+
+// NewPoolPublisher creates a new publisher using the given relay pool
+func NewPoolPublisher(pool *nostr.SimplePool, opts ...PublisherOption) *PoolPublisher {
+	p := &PoolPublisher{pool: pool}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Publish publishes an event to the relays and waits for all of them to answer
+func (p *PoolPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	relays = p.targetRelays(ctx, relays, &evt)
+
+	evt, err := p.checkLimits(ctx, evt)
+	if err != nil {
+		results := make([]*pb.RelayResult, 0, len(relays))
+		for _, url := range relays {
+			results = append(results, &pb.RelayResult{Relay: url, Error: err.Error()})
+		}
+		return results
+	}
+
+	results := make([]*pb.RelayResult, 0, len(relays))
+	for result := range p.pool.PublishMany(ctx, relays, evt) {
+		relayResult := &pb.RelayResult{Relay: result.RelayURL, Ok: result.Error == nil}
+		if result.Error != nil {
+			relayResult.Error = result.Error.Error()
+		}
+		results = append(results, relayResult)
+	}
+	return results
+}
+
+// checkLimits returns the event to publish within the relay limits, shrunk by the oversize
+// handler when needed
+func (p *PoolPublisher) checkLimits(ctx context.Context, evt nostr.Event) (nostr.Event, error) {
+	if p.limits == nil {
+		return evt, nil
+	}
+
+	err := event.ValidateForRelay(&evt, *p.limits)
+	if err == nil || p.onOversize == nil {
+		return evt, err
+	}
+
+	shrunk, err := p.onOversize(ctx, evt, err)
+	if err != nil {
+		return evt, err
+	}
+	return shrunk, event.ValidateForRelay(&shrunk, *p.limits)
+}
+
+// targetRelays adds the read relays of the users tagged by an event to the relays
+func (p *PoolPublisher) targetRelays(ctx context.Context, relays []string, evt *nostr.Event) []string {
+	if p.resolver == nil {
+		return relays
+	}
+
+	targets := make([]string, 0, len(relays))
+	for _, url := range relays {
+		if url = nostr.NormalizeURL(url); !slices.Contains(targets, url) {
+			targets = append(targets, url)
+		}
+	}
+	for _, url := range p.resolver.OutboxRelays(ctx, evt) {
+		if !slices.Contains(targets, url) {
+			targets = append(targets, url)
+		}
+	}
+	return targets
+}
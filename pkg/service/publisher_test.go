@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// relayListQuerier answers with fixed relay lists
+type relayListQuerier []*nostr.Event
+
+func (q relayListQuerier) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var events []*nostr.Event
+	for _, evt := range q {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func TestPoolPublisherOutboxRelays(t *testing.T) {
+	pubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	relayList := &nostr.Event{PubKey: pubkey, Kind: event.KindRelayList, Tags: nostr.Tags{
+		{"r", "wss://relay.example.com"},
+		{"r", "wss://inbox.example.com", "read"},
+	}}
+
+	resolver := event.NewRelayListResolver(relayListQuerier{relayList}, 0)
+	publisher := NewPoolPublisher(nil, WithRelayListResolver(resolver))
+
+	payment := nostr.Event{Kind: event.KindTxTransfer, Tags: nostr.Tags{{"p", pubkey}}}
+	relays := publisher.targetRelays(context.Background(), []string{"wss://relay.example.com/"}, &payment)
+	if !slices.Equal(relays, []string{"wss://relay.example.com", "wss://inbox.example.com"}) {
+		t.Errorf("Expected the relay and the inbox of the payee, got %v", relays)
+	}
+
+	// Without a resolver, the relays are used as they are
+	relays = NewPoolPublisher(nil).targetRelays(context.Background(), []string{"wss://relay.example.com/"}, &payment)
+	if !slices.Equal(relays, []string{"wss://relay.example.com/"}) {
+		t.Errorf("Expected the relays unchanged, got %v", relays)
+	}
+}
+
+func TestPoolPublisherRelayLimits(t *testing.T) {
+	large := nostr.Event{Kind: 1, Content: strings.Repeat("x", 2000)}
+
+	// Without a handler the event fails on every relay without being sent
+	publisher := NewPoolPublisher(nil, WithRelayLimits(event.RelayLimits{MaxContentLength: 1000}))
+	results := publisher.Publish(context.Background(), []string{"wss://a.example.com", "wss://b.example.com"}, large)
+	if len(results) != 2 || results[0].GetOk() || !strings.Contains(results[1].GetError(), event.ErrRelayLimit.Error()) {
+		t.Errorf("Expected the event to fail on both relays, got %v", results)
+	}
+
+	// The handler can shrink the event
+	publisher = NewPoolPublisher(nil, WithRelayLimits(event.RelayLimits{MaxContentLength: 1000}),
+		WithOversizeHandler(func(ctx context.Context, evt nostr.Event, err error) (nostr.Event, error) {
+			evt.Content = evt.Content[:500]
+			return evt, nil
+		}),
+	)
+	shrunk, err := publisher.checkLimits(context.Background(), large)
+	if err != nil || len(shrunk.Content) != 500 {
+		t.Errorf("Expected the shrunk event, got %d bytes and %v", len(shrunk.Content), err)
+	}
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultRetryInterval is how long the queue waits before publishing again when no relay
+// accepted an event
+const DefaultRetryInterval = 5 * time.Second
+
+// ErrPublisherStarted is returned when Start is called on a running queue publisher
+var ErrPublisherStarted = errors.New("publisher already started")
+
+// QueuePublisher publishes signed events to relays in the background, in the order they were
+// sent. The queue is saved in the checkpoint store so that events sent before a shutdown are
+// published after the restart.
+type QueuePublisher struct {
+	mu sync.Mutex
+
+	publisher     Publisher
+	relays        []string
+	store         state.Store
+	key           string
+	retryInterval time.Duration
+	policy        PublishPolicy
+
+	queue    []*nostr.Event
+	lastID   string
+	restored bool // The saved queue is only overwritten once it was restored
+	notify   chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// QueueOption configures a queue publisher
+type QueueOption func(*QueuePublisher)
+
+// WithPublishPolicy sets the policy an event must satisfy to leave the queue, RequireAny by
+// default. Events that do not satisfy it are published again after the retry interval.
+func WithPublishPolicy(policy PublishPolicy) QueueOption {
+	return func(q *QueuePublisher) {
+		q.policy = policy
+	}
+}
+
+// NewQueuePublisher creates a new queue publisher, its progress is saved under key in the
+// store, persistence is disabled when store is nil
+func NewQueuePublisher(publisher Publisher, relays []string, store state.Store, key string, opts ...QueueOption) *QueuePublisher {
+	q := &QueuePublisher{
+		publisher:     publisher,
+		relays:        relays,
+		store:         store,
+		key:           key,
+		retryInterval: DefaultRetryInterval,
+		policy:        RequireAny(),
+		notify:        make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Send queues a signed event for publishing, it implements sink.Sink so that the queue can
+// be used as the sink of a watcher
+func (q *QueuePublisher) Send(ctx context.Context, evt *nostr.Event) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queue = append(q.queue, evt)
+	if err := q.saveCheckpoint(ctx); err != nil {
+		q.queue = q.queue[:len(q.queue)-1]
+		return err
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// LastPublished returns the ID of the last published event
+func (q *QueuePublisher) LastPublished() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.lastID
+}
+
+// Pending returns the number of events waiting to be published
+func (q *QueuePublisher) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.queue)
+}
+
+// Start restores the saved queue and publishes in the background until Stop is called or the
+// context is cancelled
+func (q *QueuePublisher) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cancel != nil {
+		return ErrPublisherStarted
+	}
+
+	if err := q.restoreCheckpoint(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+	q.done = make(chan struct{})
+
+	go q.run(ctx, q.done)
+
+	return nil
+}
+
+// Stop waits for the event being published, if any, and saves the events left in the queue.
+// The queue is saved even though the context of Start is done by then.
+func (q *QueuePublisher) Stop() error {
+	q.mu.Lock()
+	cancel, done := q.cancel, q.done
+	q.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.cancel = nil
+	q.done = nil
+
+	return q.saveCheckpoint(context.Background())
+}
+
+// run publishes the queued events one at a time
+func (q *QueuePublisher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	for {
+		q.mu.Lock()
+		var evt *nostr.Event
+		if len(q.queue) > 0 {
+			evt = q.queue[0]
+		}
+		q.mu.Unlock()
+
+		if evt == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.notify:
+				continue
+			}
+		}
+
+		if !q.publish(ctx, evt) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.retryInterval):
+				continue
+			}
+		}
+
+		q.mu.Lock()
+		q.queue = q.queue[1:]
+		q.lastID = evt.ID
+		q.saveCheckpoint(ctx)
+		q.mu.Unlock()
+	}
+}
+
+// publish publishes an event and reports whether the relays that accepted it satisfy the policy
+func (q *QueuePublisher) publish(ctx context.Context, evt *nostr.Event) bool {
+	_, err := PublishWithPolicy(ctx, q.publisher, q.relays, *evt, q.policy)
+	return err == nil
+}
+
+// saveCheckpoint saves the queue and the last published event, the caller holds the lock
+func (q *QueuePublisher) saveCheckpoint(ctx context.Context) error {
+	if q.store == nil || !q.restored {
+		return nil
+	}
+
+	data, err := json.Marshal(q.queue)
+	if err != nil {
+		return err
+	}
+
+	if err := q.store.Save(ctx, state.Checkpoint{
+		Key:       q.key,
+		EventID:   q.lastID,
+		Data:      data,
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// restoreCheckpoint restores the saved queue in front of the events sent since, the caller
+// holds the lock
+func (q *QueuePublisher) restoreCheckpoint(ctx context.Context) error {
+	if q.store == nil || q.restored {
+		return nil
+	}
+
+	checkpoint, err := q.store.Load(ctx, q.key)
+	if errors.Is(err, state.ErrNotFound) {
+		q.restored = true
+		return q.saveCheckpoint(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	var saved []*nostr.Event
+	if len(checkpoint.Data) > 0 {
+		if err := json.Unmarshal(checkpoint.Data, &saved); err != nil {
+			return fmt.Errorf("invalid checkpoint: %w", err)
+		}
+	}
+
+	// Events sent before Start are already part of the saved queue
+	known := make(map[string]bool, len(saved))
+	for _, evt := range saved {
+		known[evt.ID] = true
+	}
+	for _, evt := range q.queue {
+		if !known[evt.ID] {
+			saved = append(saved, evt)
+		}
+	}
+
+	q.queue = saved
+	q.lastID = checkpoint.EventID
+	q.restored = true
+
+	return q.saveCheckpoint(ctx)
+}
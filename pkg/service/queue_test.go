@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// switchPublisher accepts events only while its relays are up
+type switchPublisher struct {
+	mu        sync.Mutex
+	up        bool
+	published []string
+}
+
+func (p *switchPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.up {
+		return []*pb.RelayResult{{Relay: relays[0], Ok: false, Error: "connection refused"}}
+	}
+
+	p.published = append(p.published, evt.ID)
+	return []*pb.RelayResult{{Relay: relays[0], Ok: true}}
+}
+
+func TestQueuePublisherPersistsQueue(t *testing.T) {
+	store := state.NewMemoryStore()
+	relays := []string{"wss://relay.example.com"}
+	privateKey := nostr.GeneratePrivateKey()
+
+	var events []*nostr.Event
+	for i := 0; i < 3; i++ {
+		evt := &nostr.Event{Kind: 1, CreatedAt: nostr.Timestamp(1700000000 + i), Tags: nostr.Tags{}}
+		if err := evt.Sign(privateKey); err != nil {
+			t.Fatalf("Failed to sign event: %v", err)
+		}
+		events = append(events, evt)
+	}
+
+	// The relays are down, the queue is saved on shutdown
+	down := &switchPublisher{}
+	q := NewQueuePublisher(down, relays, store, "publisher")
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start publisher: %v", err)
+	}
+	for _, evt := range events[:2] {
+		if err := q.Send(context.Background(), evt); err != nil {
+			t.Fatalf("Failed to send event: %v", err)
+		}
+	}
+	if err := q.Stop(); err != nil {
+		t.Fatalf("Failed to stop publisher: %v", err)
+	}
+
+	// A new publisher sends the saved events first, then the ones sent before it started
+	up := &switchPublisher{up: true}
+	q = NewQueuePublisher(up, relays, store, "publisher")
+	if err := q.Send(context.Background(), events[2]); err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start publisher: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := q.Stop(); err != nil {
+		t.Fatalf("Failed to stop publisher: %v", err)
+	}
+
+	if len(up.published) != 3 {
+		t.Fatalf("Expected 3 published events, got %d", len(up.published))
+	}
+	for i, id := range up.published {
+		if id != events[i].ID {
+			t.Errorf("Expected event %d to be %s, got %s", i, events[i].ID, id)
+		}
+	}
+
+	checkpoint, err := store.Load(context.Background(), "publisher")
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if checkpoint.EventID != events[2].ID {
+		t.Errorf("Expected last published event %s, got %s", events[2].ID, checkpoint.EventID)
+	}
+}
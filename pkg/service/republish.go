@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultMaxStates is the number of state events a republisher remembers by default
+const DefaultMaxStates = 10000
+
+// Republisher is a Publisher remembering the latest state events it published, replaceable and
+// addressable events and events with a d tag such as tx logs, and sending them again to relays
+// that are added or reconnect, so that every relay converges on the current state
+type Republisher struct {
+	mu sync.Mutex
+
+	publisher Publisher
+	maxStates int
+
+	states map[string]*nostr.Event // Latest event by coordinate
+	relays map[string]bool         // Relays the states were sent to
+	conns  map[string]*nostr.Relay // Connections of the pool seen by Watch
+}
+
+// NewRepublisher creates a new republisher publishing with a publisher, it remembers up to
+// maxStates events and forgets the oldest first, DefaultMaxStates when maxStates is 0
+func NewRepublisher(publisher Publisher, maxStates int) *Republisher {
+	if maxStates <= 0 {
+		maxStates = DefaultMaxStates
+	}
+	return &Republisher{
+		publisher: publisher,
+		maxStates: maxStates,
+		states:    make(map[string]*nostr.Event),
+		relays:    make(map[string]bool),
+		conns:     make(map[string]*nostr.Relay),
+	}
+}
+
+// stateKey returns the coordinate of a state event, false for the other events
+func stateKey(evt *nostr.Event) (string, bool) {
+	switch {
+	case nostr.IsReplaceableKind(evt.Kind):
+		return fmt.Sprintf("%d:%s:", evt.Kind, evt.PubKey), true
+	case nostr.IsAddressableKind(evt.Kind) || evt.Tags.Find("d") != nil:
+		return fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, evt.Tags.GetD()), true
+	default:
+		return "", false
+	}
+}
+
+// Publish sends the states to the relays seen for the first time, then publishes the event and
+// remembers it when it is a state event
+func (r *Republisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	for _, url := range relays {
+		if r.addRelay(url) {
+			r.Republish(ctx, url)
+		}
+	}
+
+	r.track(&evt)
+	return r.publisher.Publish(ctx, relays, evt)
+}
+
+// addRelay records a relay and reports whether it is new
+func (r *Republisher) addRelay(url string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url = nostr.NormalizeURL(url)
+	if r.relays[url] {
+		return false
+	}
+	r.relays[url] = true
+	return true
+}
+
+// track remembers a state event unless a newer one of the same coordinate is known
+func (r *Republisher) track(evt *nostr.Event) {
+	key, ok := stateKey(evt)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, ok := r.states[key]; ok && !replaces(evt, current) {
+		return
+	}
+	stored := *evt
+	r.states[key] = &stored
+
+	for len(r.states) > r.maxStates {
+		oldest := ""
+		for key, state := range r.states {
+			if oldest == "" || state.CreatedAt < r.states[oldest].CreatedAt {
+				oldest = key
+			}
+		}
+		delete(r.states, oldest)
+	}
+}
+
+// replaces checks if an event replaces another of the same coordinate, the newest wins and ties
+// go to the lowest ID as in NIP-01
+func replaces(evt, current *nostr.Event) bool {
+	if evt.CreatedAt != current.CreatedAt {
+		return evt.CreatedAt > current.CreatedAt
+	}
+	return evt.ID < current.ID
+}
+
+// States returns the remembered state events, from the oldest
+func (r *Republisher) States() []*nostr.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]*nostr.Event, 0, len(r.states))
+	for _, state := range r.states {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].CreatedAt != states[j].CreatedAt {
+			return states[i].CreatedAt < states[j].CreatedAt
+		}
+		return states[i].ID < states[j].ID
+	})
+	return states
+}
+
+// Republish sends the remembered states to a relay, from the oldest, and returns the number it
+// accepted
+func (r *Republisher) Republish(ctx context.Context, relay string) int {
+	accepted := 0
+	for _, state := range r.States() {
+		if ctx.Err() != nil {
+			break
+		}
+		for _, result := range r.publisher.Publish(ctx, []string{relay}, *state) {
+			if result.GetOk() {
+				accepted++
+			}
+		}
+	}
+	return accepted
+}
+
+// Watch republishes the states to the relays of a pool that reconnect, checking the
+// connections at every interval until the context is done
+func (r *Republisher) Watch(ctx context.Context, pool *nostr.SimplePool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, url := range r.reconnected(pool) {
+			r.Republish(ctx, url)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconnected returns the relays of the pool with a new connection since the last check, the
+// pool replaces the connection of a relay when it reconnects
+func (r *Republisher) reconnected(pool *nostr.SimplePool) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var urls []string
+	pool.Relays.Range(func(url string, relay *nostr.Relay) bool {
+		if relay == nil || !relay.IsConnected() {
+			return true
+		}
+
+		previous, seen := r.conns[url]
+		r.conns[url] = relay
+		if seen && previous != relay {
+			urls = append(urls, url)
+		}
+		return true
+	})
+	sort.Strings(urls)
+	return urls
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// relayLogPublisher accepts every event and records the relays it was sent to
+type relayLogPublisher struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (p *relayLogPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := make([]*pb.RelayResult, 0, len(relays))
+	for _, relay := range relays {
+		p.sent = append(p.sent, relay+" "+evt.Content)
+		results = append(results, &pb.RelayResult{Relay: relay, Ok: true})
+	}
+	return results
+}
+
+func TestRepublisherNewRelays(t *testing.T) {
+	publisher := &relayLogPublisher{}
+	r := NewRepublisher(publisher, 2)
+	ctx := context.Background()
+
+	events := []nostr.Event{
+		{ID: "01", Kind: 31105, CreatedAt: 10, Content: "snapshot v2", Tags: nostr.Tags{{"d", "a"}}},
+		{ID: "02", Kind: 31105, CreatedAt: 5, Content: "snapshot v1", Tags: nostr.Tags{{"d", "a"}}},
+		{ID: "03", Kind: 1, CreatedAt: 11, Content: "note"},
+		{ID: "04", Kind: 111000, CreatedAt: 12, Content: "log", Tags: nostr.Tags{{"d", "0x01"}}},
+	}
+	for _, evt := range events {
+		r.Publish(ctx, []string{"wss://a.example.com"}, evt)
+	}
+
+	// The older snapshot and the note are not states
+	var states []string
+	for _, state := range r.States() {
+		states = append(states, state.Content)
+	}
+	if fmt.Sprint(states) != "[snapshot v2 log]" {
+		t.Errorf("Expected the latest states, got %v", states)
+	}
+
+	// A new relay gets the states before the event
+	publisher.sent = nil
+	r.Publish(ctx, []string{"wss://a.example.com", "wss://b.example.com"}, nostr.Event{ID: "05", Kind: 1, CreatedAt: 13, Content: "note 2"})
+	expected := "[wss://b.example.com snapshot v2 wss://b.example.com log wss://a.example.com note 2 wss://b.example.com note 2]"
+	if fmt.Sprint(publisher.sent) != expected {
+		t.Errorf("Expected %s, got %v", expected, publisher.sent)
+	}
+
+	// The oldest state is forgotten beyond the limit
+	r.Publish(ctx, []string{"wss://a.example.com"}, nostr.Event{ID: "06", Kind: 0, CreatedAt: 14, Content: "profile"})
+	if states := r.States(); len(states) != 2 || states[0].Content != "log" {
+		t.Errorf("Expected the two latest states, got %d", len(states))
+	}
+}
+
+func TestRepublisherReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Relays are stored in the pool without connecting them, closing real connections races
+	// with the goroutines go-nostr runs for them
+	const url = "wss://relay.example.com"
+	pool := nostr.NewSimplePool(ctx)
+	r := NewRepublisher(NewPoolPublisher(pool), 0)
+
+	connCtx, drop := context.WithCancel(ctx)
+	pool.Relays.Store(nostr.NormalizeURL(url), nostr.NewRelay(connCtx, url))
+	if urls := r.reconnected(pool); len(urls) != 0 {
+		t.Errorf("Expected no reconnection on the first check, got %v", urls)
+	}
+
+	// A dropped connection is not a reconnection
+	drop()
+	if urls := r.reconnected(pool); len(urls) != 0 {
+		t.Errorf("Expected no reconnection while disconnected, got %v", urls)
+	}
+
+	pool.Relays.Store(nostr.NormalizeURL(url), nostr.NewRelay(ctx, url))
+	if urls := r.reconnected(pool); len(urls) != 1 || urls[0] != nostr.NormalizeURL(url) {
+		t.Errorf("Expected the relay to be reconnected, got %v", urls)
+	}
+	if urls := r.reconnected(pool); len(urls) != 0 {
+		t.Errorf("Expected the reconnection to be reported once, got %v", urls)
+	}
+}
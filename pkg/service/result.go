@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ErrPublishPolicy is returned when the relays that accepted an event do not satisfy a policy
+var ErrPublishPolicy = errors.New("publish policy not satisfied")
+
+// RelayOutcome is how a relay answered the publication of an event
+type RelayOutcome string
+
+const (
+	RelayAccepted RelayOutcome = "accepted" // OK true
+	RelayRejected RelayOutcome = "rejected" // OK false, with the reason of the relay
+	RelayTimeout  RelayOutcome = "timeout"  // No OK before the deadline
+	RelayFailed   RelayOutcome = "failed"   // Not reached, e.g. the connection failed
+)
+
+// RelayPublish is the outcome of the publication of an event on a relay
+type RelayPublish struct {
+	Relay   string       `json:"relay"`
+	Outcome RelayOutcome `json:"outcome"`
+	Reason  string       `json:"reason,omitempty"`
+}
+
+// PublishResult aggregates the outcomes of the publication of an event on several relays
+type PublishResult struct {
+	EventID string         `json:"event_id"`
+	Relays  []RelayPublish `json:"relays"`
+}
+
+// NewPublishResult classifies the results returned by a Publisher
+func NewPublishResult(eventID string, results []*pb.RelayResult) PublishResult {
+	result := PublishResult{EventID: eventID, Relays: make([]RelayPublish, 0, len(results))}
+	for _, r := range results {
+		result.Relays = append(result.Relays, classifyRelayResult(r))
+	}
+	return result
+}
+
+// classifyRelayResult returns the outcome of a relay result from its error, relays rejecting an
+// event answer with a "msg: " error in go-nostr
+func classifyRelayResult(r *pb.RelayResult) RelayPublish {
+	publish := RelayPublish{Relay: r.GetRelay()}
+
+	reason := r.GetError()
+	switch {
+	case r.GetOk():
+		publish.Outcome = RelayAccepted
+	case strings.HasPrefix(reason, "msg: "):
+		publish.Outcome = RelayRejected
+		publish.Reason = strings.TrimPrefix(reason, "msg: ")
+	case strings.Contains(reason, context.DeadlineExceeded.Error()):
+		publish.Outcome = RelayTimeout
+		publish.Reason = reason
+	default:
+		publish.Outcome = RelayFailed
+		publish.Reason = reason
+	}
+	return publish
+}
+
+// RelaysWith returns the relays with an outcome
+func (r PublishResult) RelaysWith(outcome RelayOutcome) []string {
+	var relays []string
+	for _, publish := range r.Relays {
+		if publish.Outcome == outcome {
+			relays = append(relays, publish.Relay)
+		}
+	}
+	return relays
+}
+
+// Accepted returns the relays that accepted the event
+func (r PublishResult) Accepted() []string {
+	return r.RelaysWith(RelayAccepted)
+}
+
+// Check checks the result against a policy
+func (r PublishResult) Check(policy PublishPolicy) error {
+	return policy.Check(r)
+}
+
+// PublishPolicy decides if the relays that accepted an event are enough for the caller
+type PublishPolicy interface {
+	// Check returns nil when the result satisfies the policy, an error wrapping ErrPublishPolicy
+	// otherwise
+	Check(result PublishResult) error
+}
+
+type requireAll struct{}
+
+// RequireAll is satisfied when every relay accepted the event, and there was at least one
+func RequireAll() PublishPolicy {
+	return requireAll{}
+}
+
+func (requireAll) Check(result PublishResult) error {
+	if accepted := len(result.Accepted()); accepted == 0 || accepted < len(result.Relays) {
+		return fmt.Errorf("%w: %d of %d relays accepted %s", ErrPublishPolicy, accepted, len(result.Relays), result.EventID)
+	}
+	return nil
+}
+
+type quorum struct {
+	n int
+}
+
+// Quorum is satisfied when at least n relays accepted the event
+func Quorum(n int) PublishPolicy {
+	return quorum{n: n}
+}
+
+func (q quorum) Check(result PublishResult) error {
+	if accepted := len(result.Accepted()); accepted < q.n {
+		return fmt.Errorf("%w: %d relays accepted %s, %d needed", ErrPublishPolicy, accepted, result.EventID, q.n)
+	}
+	return nil
+}
+
+// RequireAny is satisfied when at least one relay accepted the event
+func RequireAny() PublishPolicy {
+	return quorum{n: 1}
+}
+
+// PublishWithPolicy publishes an event and checks the outcomes against a policy, the result is
+// returned with the error of the policy
+func PublishWithPolicy(ctx context.Context, publisher Publisher, relays []string, evt nostr.Event, policy PublishPolicy) (PublishResult, error) {
+	result := NewPublishResult(evt.ID, publisher.Publish(ctx, relays, evt))
+	return result, result.Check(policy)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// fixedPublisher answers with fixed relay results
+type fixedPublisher []*pb.RelayResult
+
+func (p fixedPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	return p
+}
+
+func TestPublishResult(t *testing.T) {
+	publisher := fixedPublisher{
+		{Relay: "wss://a.example.com", Ok: true},
+		{Relay: "wss://b.example.com", Error: "msg: blocked: kind not allowed"},
+		{Relay: "wss://c.example.com", Error: "context deadline exceeded"},
+		{Relay: "wss://d.example.com", Error: "dial tcp: connection refused"},
+		{Relay: "wss://e.example.com", Ok: true},
+	}
+
+	result, err := PublishWithPolicy(context.Background(), publisher, nil, nostr.Event{ID: "abc"}, Quorum(2))
+	if err != nil {
+		t.Fatalf("Expected the quorum of 2 to be satisfied, got %v", err)
+	}
+
+	got := fmt.Sprint(result.Accepted(), result.RelaysWith(RelayRejected), result.RelaysWith(RelayTimeout), result.RelaysWith(RelayFailed))
+	if got != "[wss://a.example.com wss://e.example.com] [wss://b.example.com] [wss://c.example.com] [wss://d.example.com]" {
+		t.Errorf("Expected the relays by outcome, got %s", got)
+	}
+	if reason := result.Relays[1].Reason; reason != "blocked: kind not allowed" {
+		t.Errorf("Expected the reason of the relay, got %q", reason)
+	}
+
+	tests := []struct {
+		policy PublishPolicy
+		ok     bool
+	}{
+		{RequireAny(), true},
+		{Quorum(3), false},
+		{RequireAll(), false},
+	}
+	for _, tt := range tests {
+		if err := result.Check(tt.policy); (err == nil) != tt.ok || (err != nil && !errors.Is(err, ErrPublishPolicy)) {
+			t.Errorf("Expected ok %v for %T, got %v", tt.ok, tt.policy, err)
+		}
+	}
+
+	if err := NewPublishResult("abc", nil).Check(RequireAll()); err == nil {
+		t.Error("Expected no relay not to satisfy RequireAll")
+	}
+}
@@ -0,0 +1,260 @@
+// Package service implements the NostrEth gRPC service of pkg/pb
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Publisher publishes signed events to relays
+type Publisher interface {
+	Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult
+}
+
+// Server implements the NostrEth gRPC service
+type Server struct {
+	pb.UnimplementedNostrEthServer
+
+	privateKey string
+	publisher  Publisher
+
+	mu      sync.RWMutex
+	relays  []string
+	allowed []string
+}
+
+// signingKey is the context key of the callers allowed to have events signed by the server
+type signingKey struct{}
+
+// AllowSigning authorizes the caller of a request to have its unsigned events signed with the key
+// of the server, e.g. once an Authenticator checked that the caller is allowlisted
+func AllowSigning(ctx context.Context) context.Context {
+	return context.WithValue(ctx, signingKey{}, true)
+}
+
+// signingAllowed checks if the caller of a request may have its events signed by the server
+func signingAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(signingKey{}).(bool)
+	return allowed
+}
+
+// NewServer creates a new server signing the unsigned events of authorized callers with the
+// private key and publishing them to the given relays, publishing is disabled when publisher is
+// nil
+func NewServer(privateKey string, relays []string, publisher Publisher) *Server {
+	return &Server{
+		privateKey: privateKey,
+		relays:     relays,
+		publisher:  publisher,
+	}
+}
+
+// Relays returns the relays events are published to when a request names none
+func (s *Server) Relays() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string(nil), s.relays...)
+}
+
+// SetRelays replaces the default relays, e.g. when the configuration is reloaded
+func (s *Server) SetRelays(relays []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.relays = append([]string(nil), relays...)
+}
+
+// AllowRelays sets the relays callers may name in publish requests besides the default relays,
+// publishing to any other relay is refused so that the server does not connect to arbitrary URLs
+func (s *Server) AllowRelays(relays []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.allowed = append([]string(nil), relays...)
+}
+
+// checkRelays checks that the relays of a publish request are default or allowed relays
+func (s *Server) checkRelays(relays []string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	known := make(map[string]bool, len(s.relays)+len(s.allowed))
+	for _, url := range append(append([]string(nil), s.relays...), s.allowed...) {
+		known[nostr.NormalizeURL(url)] = true
+	}
+
+	for _, url := range relays {
+		if !known[nostr.NormalizeURL(url)] {
+			return status.Errorf(codes.PermissionDenied, "relay %s is not allowed", url)
+		}
+	}
+	return nil
+}
+
+// CreateTxLogEvent creates an unsigned transaction log event
+func (s *Server) CreateTxLogEvent(ctx context.Context, req *pb.CreateTxLogEventRequest) (*pb.EventResponse, error) {
+	if req.GetLog() == nil {
+		return nil, status.Error(codes.InvalidArgument, "log is required")
+	}
+
+	log, err := req.GetLog().ToLog()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var opts []event.LogOption
+	if req.GetSortableAmount() {
+		opts = append(opts, event.WithSortableAmount())
+	}
+
+	evt, err := event.CreateTxLogEvent(log, opts...)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &pb.EventResponse{Event: pb.FromEvent(evt)}, nil
+}
+
+// CreateUserOpEvent creates an unsigned user operation event
+func (s *Server) CreateUserOpEvent(ctx context.Context, req *pb.CreateUserOpEventRequest) (*pb.EventResponse, error) {
+	if req.GetUserOp() == nil {
+		return nil, status.Error(codes.InvalidArgument, "user_op is required")
+	}
+
+	chainID, ok := new(big.Int).SetString(req.GetChainId(), 10)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid chain_id: %s", req.GetChainId())
+	}
+
+	userOp, err := req.GetUserOp().ToUserOp()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var paymaster, entryPoint *common.Address
+	if req.Paymaster != nil {
+		address := common.HexToAddress(req.GetPaymaster())
+		paymaster = &address
+	}
+	if req.EntryPoint != nil {
+		address := common.HexToAddress(req.GetEntryPoint())
+		entryPoint = &address
+	}
+
+	var data *json.RawMessage
+	if len(req.GetData()) > 0 {
+		raw := json.RawMessage(req.GetData())
+		data = &raw
+	}
+
+	evt, err := event.CreateUserOpEvent(chainID, paymaster, entryPoint, data, req.TxHash, int(req.GetRetryCount()), userOp, event.EventTypeUserOp(req.GetEventType()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &pb.EventResponse{Event: pb.FromEvent(evt)}, nil
+}
+
+// ParseEvent parses the content of an event according to its kind
+func (s *Server) ParseEvent(ctx context.Context, req *pb.ParseEventRequest) (*pb.ParseEventResponse, error) {
+	if req.GetEvent() == nil {
+		return nil, status.Error(codes.InvalidArgument, "event is required")
+	}
+
+	evt := req.GetEvent().ToEvent()
+	resp := &pb.ParseEventResponse{}
+
+	switch event.DefaultKind(evt.Kind) {
+	case event.KindTxLog:
+		content, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		resp.Content = &pb.ParseEventResponse_TxLog{TxLog: pb.FromTxLogEvent(content)}
+	case event.KindTxTransfer, event.KindTxTransferLegacy:
+		content, err := event.ParseTxTransferEvent(evt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		resp.Content = &pb.ParseEventResponse_TxTransfer{TxTransfer: pb.FromTxTransferEvent(content)}
+	case event.EventUserOpKind:
+		content, err := event.ParseUserOpEvent(evt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		resp.Content = &pb.ParseEventResponse_UserOp{UserOp: pb.FromUserOpEvent(content)}
+	case event.KindGroupCreate, event.KindGroupEditMetadata:
+		content, err := event.ParseGroupEvent(evt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		resp.Content = &pb.ParseEventResponse_GroupMetadata{GroupMetadata: pb.FromGroupMetadata(*content)}
+	}
+
+	if json.Valid([]byte(evt.Content)) {
+		resp.ContentJson = []byte(evt.Content)
+	}
+
+	return resp, nil
+}
+
+// PublishEvent publishes an event, signing it with the key of the server when it is unsigned.
+// Only the callers authorized with AllowSigning have their events signed, anyone else can only
+// publish signed events, so that the server cannot be used to publish as the bridge. Requests
+// may only name the default relays and the ones allowed with AllowRelays.
+func (s *Server) PublishEvent(ctx context.Context, req *pb.PublishEventRequest) (*pb.PublishEventResponse, error) {
+	if s.publisher == nil {
+		return nil, status.Error(codes.Unimplemented, "publishing is disabled")
+	}
+
+	if req.GetEvent() == nil {
+		return nil, status.Error(codes.InvalidArgument, "event is required")
+	}
+
+	evt := req.GetEvent().ToEvent()
+
+	if evt.Sig == "" {
+		if !signingAllowed(ctx) {
+			return nil, status.Error(codes.PermissionDenied, "unsigned events are only signed for authorized publishers")
+		}
+		if s.privateKey == "" {
+			return nil, status.Error(codes.FailedPrecondition, "event is unsigned and the server has no key")
+		}
+		if err := evt.Sign(s.privateKey); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	} else if !evt.CheckID() {
+		return nil, status.Error(codes.InvalidArgument, "invalid event id")
+	} else if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid event signature")
+	}
+
+	if err := s.checkRelays(req.GetRelays()); err != nil {
+		return nil, err
+	}
+
+	relays := req.GetRelays()
+	if len(relays) == 0 {
+		relays = s.Relays()
+	}
+	if len(relays) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no relays to publish to")
+	}
+
+	results := s.publisher.Publish(ctx, relays, *evt)
+
+	return &pb.PublishEventResponse{
+		Event:   pb.FromEvent(evt),
+		Results: results,
+	}, nil
+}
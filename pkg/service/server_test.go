@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/nbd-wtf/go-nostr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type recordingPublisher struct {
+	published []nostr.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	p.published = append(p.published, evt)
+
+	results := make([]*pb.RelayResult, 0, len(relays))
+	for _, relay := range relays {
+		results = append(results, &pb.RelayResult{Relay: relay, Ok: true})
+	}
+	return results
+}
+
+func TestServerCreateParsePublish(t *testing.T) {
+	publisher := &recordingPublisher{}
+	server := NewServer(nostr.GeneratePrivateKey(), []string{"wss://relay.example.com"}, publisher)
+	ctx := context.Background()
+
+	created, err := server.CreateTxLogEvent(ctx, &pb.CreateTxLogEventRequest{
+		Log: pb.FromLog(neth.Log{
+			Hash:      "0xabc",
+			TxHash:    "0xdef",
+			ChainID:   "1",
+			Topic:     "0x01",
+			CreatedAt: time.Unix(1700000000, 0),
+			Value:     big.NewInt(42),
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+
+	expected, err := event.CreateTxLogEvent(neth.Log{
+		Hash:      "0xabc",
+		TxHash:    "0xdef",
+		ChainID:   "1",
+		Topic:     "0x01",
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		Value:     big.NewInt(42),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create expected event: %v", err)
+	}
+	if !event.EqualSemantics(created.GetEvent().ToEvent(), expected) {
+		t.Errorf("Expected the gRPC event to match the Go event, got %s", created.GetEvent().GetContent())
+	}
+
+	parsed, err := server.ParseEvent(ctx, &pb.ParseEventRequest{Event: created.GetEvent()})
+	if err != nil {
+		t.Fatalf("Failed to parse event: %v", err)
+	}
+	if parsed.GetTxLog().GetLogData().GetHash() != "0xabc" {
+		t.Errorf("Expected log hash 0xabc, got %s", parsed.GetTxLog().GetLogData().GetHash())
+	}
+
+	// Unsigned events are only signed for authorized callers
+	if _, err := server.PublishEvent(ctx, &pb.PublishEventRequest{Event: created.GetEvent()}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected permission denied, got %v", err)
+	}
+	if len(publisher.published) != 0 {
+		t.Fatalf("Expected nothing to be published, got %d events", len(publisher.published))
+	}
+
+	published, err := server.PublishEvent(AllowSigning(ctx), &pb.PublishEventRequest{Event: created.GetEvent()})
+	if err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+	if published.GetEvent().GetSig() == "" || len(publisher.published) != 1 {
+		t.Errorf("Expected the event to be signed and published once")
+	}
+	if ok, _ := publisher.published[0].CheckSignature(); !ok {
+		t.Errorf("Expected a valid signature")
+	}
+}
+
+func TestServerPublishSignedEvent(t *testing.T) {
+	publisher := &recordingPublisher{}
+	server := NewServer(nostr.GeneratePrivateKey(), []string{"wss://relay.example.com"}, publisher)
+
+	evt := nostr.Event{Kind: 1, Content: "hi", CreatedAt: nostr.Now()}
+	evt.Sign(nostr.GeneratePrivateKey())
+
+	// Events signed by their author are published for anyone
+	if _, err := server.PublishEvent(context.Background(), &pb.PublishEventRequest{Event: pb.FromEvent(&evt)}); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+	if len(publisher.published) != 1 || publisher.published[0].ID != evt.ID {
+		t.Errorf("Expected the signed event to be published as is, got %v", publisher.published)
+	}
+}
+
+func TestServerCreateUserOpEventMissingFields(t *testing.T) {
+	server := NewServer(nostr.GeneratePrivateKey(), nil, nil)
+
+	_, err := server.CreateUserOpEvent(context.Background(), &pb.CreateUserOpEventRequest{
+		ChainId: "100",
+		UserOp:  &pb.UserOp{Sender: "0x0000000000000000000000000000000000000001", Nonce: "1"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected invalid argument, got %v", err)
+	}
+}
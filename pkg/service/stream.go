@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// DefaultStreamBuffer is the number of events buffered for a stream client, events are
+	// dropped while it is full
+	DefaultStreamBuffer = 256
+
+	// DefaultStreamWriteTimeout is how long a stream client has to receive a message before it
+	// is disconnected
+	DefaultStreamWriteTimeout = 10 * time.Second
+
+	// DefaultStreamKeepAlive is how often an idle SSE stream is written to, so that proxies do not
+	// close it
+	DefaultStreamKeepAlive = 15 * time.Second
+)
+
+// Stream message types
+const (
+	StreamEvent   = "event"   // A parsed event
+	StreamDropped = "dropped" // Events dropped because the client was too slow
+)
+
+// StreamMessage is a JSON message of the /stream WebSocket, typed so that browsers do not parse
+// Nostr events
+type StreamMessage struct {
+	Type      string          `json:"type"`
+	Kind      int             `json:"kind,omitempty"` // Kind of the package, before remapping
+	Name      string          `json:"name,omitempty"` // Name of the kind, e.g. "tx_log"
+	ID        string          `json:"id,omitempty"`
+	PubKey    string          `json:"pubkey,omitempty"`
+	CreatedAt nostr.Timestamp `json:"created_at,omitempty"`
+	Data      any             `json:"data,omitempty"`    // Parsed content
+	Dropped   uint64          `json:"dropped,omitempty"` // Number of dropped events
+}
+
+// NewStreamMessage returns the message of an event, with its content parsed by the parser of its
+// kind. The content of the other events is passed as is, as JSON when it is valid JSON.
+func NewStreamMessage(evt *nostr.Event) StreamMessage {
+	kind := event.DefaultKind(evt.Kind)
+	msg := StreamMessage{Type: StreamEvent, Kind: kind, ID: evt.ID, PubKey: evt.PubKey, CreatedAt: evt.CreatedAt}
+
+	if spec, ok := event.KindInfo(kind); ok {
+		msg.Name = spec.Name
+	}
+
+	if data, err := event.ParseEvent(evt); err == nil {
+		msg.Data = data
+	} else if json.Valid([]byte(evt.Content)) {
+		msg.Data = json.RawMessage(evt.Content)
+	} else {
+		msg.Data = evt.Content
+	}
+	return msg
+}
+
+// StreamFilter selects the events pushed to a stream client, empty fields select every event
+type StreamFilter struct {
+	Addresses []string     // Addresses of the p and P tags
+	Chains    []string     // Chain IDs of the layer tag
+	Kinds     []int        // Kinds of the package
+	Query     *store.Query // Filter expression
+}
+
+// ParseStreamFilter reads a filter from the parameters of a stream request, e.g.
+// /stream?address=0x...&chain=100&kind=tx_log&kind=111013&q=amount>1e18. Kinds are numbers or
+// names of the kind registry, and parameters can be repeated.
+func ParseStreamFilter(params map[string][]string) (StreamFilter, error) {
+	filter := StreamFilter{Addresses: params["address"], Chains: params["chain"]}
+
+	names := make(map[string]int)
+	for _, spec := range event.Kinds() {
+		names[spec.Name] = spec.Kind
+	}
+	for _, value := range params["kind"] {
+		if kind, ok := names[value]; ok {
+			filter.Kinds = append(filter.Kinds, kind)
+			continue
+		}
+		kind, err := strconv.Atoi(value)
+		if err != nil {
+			return StreamFilter{}, fmt.Errorf("invalid kind: %s", value)
+		}
+		filter.Kinds = append(filter.Kinds, event.DefaultKind(kind))
+	}
+
+	if q := params["q"]; len(q) > 0 && q[0] != "" {
+		query, err := store.ParseQuery(q[0])
+		if err != nil {
+			return StreamFilter{}, fmt.Errorf("invalid query: %w", err)
+		}
+		filter.Query = query
+	}
+
+	return filter, nil
+}
+
+// Match checks if an event passes the filter
+func (f StreamFilter) Match(evt *nostr.Event) bool {
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, event.DefaultKind(evt.Kind)) {
+		return false
+	}
+
+	if len(f.Chains) > 0 {
+		layer := evt.Tags.Find("layer")
+		if layer == nil || len(layer) < 2 || !containsFold(f.Chains, layer[1]) {
+			return false
+		}
+	}
+
+	if len(f.Addresses) > 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if event.IsAddressInEvent(evt, address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return f.Query == nil || f.Query.Match(evt)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// stream pushes the events published to the store to a WebSocket client as StreamMessage. Events
+// that do not fit in the buffer of the client are dropped and reported with a dropped message,
+// and clients that do not receive a message in time are disconnected.
+func (h *HTTPHandler) stream(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseStreamFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.origins})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	sub := h.store.Subscribe(h.streamBuffer, filter.Match)
+	defer sub.Close()
+
+	// Clients only listen, reading handles the control frames and notices when they leave
+	ctx := conn.CloseRead(r.Context())
+
+	h.pump(ctx, sub, nil, func(msg StreamMessage) error {
+		return h.writeStream(ctx, conn, msg)
+	})
+}
+
+// streamSSE is the stream as server-sent events, for environments that block WebSockets. Each
+// StreamMessage is an SSE event named by its type, and comments keep idle connections open
+// through proxies.
+func (h *HTTPHandler) streamSSE(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseStreamFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" && h.allowedOrigin(r, origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disables response buffering by nginx
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	sub := h.store.Subscribe(h.streamBuffer, filter.Match)
+	defer sub.Close()
+
+	write := func(data string) error {
+		// Servers that cannot time out writes still stop when the client leaves
+		_ = rc.SetWriteDeadline(time.Now().Add(h.streamWriteTimeout))
+		if _, err := io.WriteString(w, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	keepAlive := func() error {
+		return write(": keep-alive\n\n")
+	}
+
+	h.pump(r.Context(), sub, keepAlive, func(msg StreamMessage) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+
+		var b strings.Builder
+		if msg.ID != "" {
+			fmt.Fprintf(&b, "id: %s\n", msg.ID)
+		}
+		fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", msg.Type, data)
+		return write(b.String())
+	})
+}
+
+// allowedOrigin checks if a browser on another origin may read the stream, the same origin is
+// always allowed
+func (h *HTTPHandler) allowedOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, pattern := range h.origins {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(u.Host)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pump writes the events of a subscription to a stream client until it leaves or a write fails,
+// reporting the dropped events before the next one. keepAlive, when not nil, is called when no
+// event was written for a while.
+func (h *HTTPHandler) pump(ctx context.Context, sub *store.Subscription, keepAlive func() error, write func(StreamMessage) error) {
+	var tick <-chan time.Time
+	if keepAlive != nil {
+		ticker := time.NewTicker(h.streamKeepAlive)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if err := keepAlive(); err != nil {
+				return
+			}
+		case evt, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+
+			if dropped := sub.Dropped(); dropped > 0 {
+				if err := write(StreamMessage{Type: StreamDropped, Dropped: dropped}); err != nil {
+					return
+				}
+			}
+			if err := write(NewStreamMessage(evt)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeStream writes a message to a stream client, which is closed when it is too slow
+func (h *HTTPHandler) writeStream(ctx context.Context, conn *websocket.Conn, msg StreamMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.streamWriteTimeout)
+	defer cancel()
+
+	if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+		conn.Close(websocket.StatusPolicyViolation, "too slow")
+		return err
+	}
+	return nil
+}
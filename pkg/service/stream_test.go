@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseStreamFilter(t *testing.T) {
+	filter, err := ParseStreamFilter(map[string][]string{
+		"kind":  {"tx_log", "111013"},
+		"chain": {"100"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse filter: %v", err)
+	}
+	if len(filter.Kinds) != 2 || filter.Kinds[0] != event.KindTxLog || filter.Kinds[1] != event.KindTxTransfer {
+		t.Errorf("Expected the tx log and transfer kinds, got %v", filter.Kinds)
+	}
+
+	for _, params := range []map[string][]string{{"kind": {"unknown"}}, {"q": {"amount>"}}} {
+		if _, err := ParseStreamFilter(params); err == nil {
+			t.Errorf("Expected %v to be invalid", params)
+		}
+	}
+}
+
+func TestHTTPHandlerStream(t *testing.T) {
+	st := store.NewMemoryStore()
+	server := httptest.NewServer(NewHTTPHandler(NewServer("", nil, nil), st))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sender := "0x1111111111111111111111111111111111111111"
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/stream?kind=tx_log&chain=100&address=" + sender
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer conn.CloseNow()
+
+	// Wait for the subscription of the stream
+	for i := 0; i < 100 && st.Subscribers() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i, chainID := range []string{"1", "100"} {
+		evt, err := event.CreateTxLogEvent(neth.Log{
+			Hash:      "0x0" + chainID,
+			TxHash:    "0xdef",
+			ChainID:   chainID,
+			Topic:     "0x01",
+			CreatedAt: time.Unix(1700000000, 0),
+			Sender:    sender,
+			Value:     big.NewInt(42),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create tx log event: %v", err)
+		}
+		evt.ID = "0" + string(rune('0'+i))
+		if err := st.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to store event: %v", err)
+		}
+	}
+
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+
+	var msg struct {
+		StreamMessage
+		Data event.TxLogEvent `json:"data"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if msg.Type != StreamEvent || msg.Name != "tx_log" || msg.ID != "01" {
+		t.Errorf("Expected the tx log on chain 100, got %s", data)
+	}
+	if msg.Data.LogData.Hash != "0x0100" {
+		t.Errorf("Expected parsed log 0x0100, got %s", msg.Data.LogData.Hash)
+	}
+}
+
+func TestHTTPHandlerStreamSSE(t *testing.T) {
+	st := store.NewMemoryStore()
+	h := NewHTTPHandler(NewServer("", nil, nil), st, WithStreamOrigins("app.example.com"), WithStreamKeepAlive(20*time.Millisecond))
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/stream/sse?kind=tx_log&chain=100", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected content type text/event-stream, got %s", ct)
+	}
+	if origin := resp.Header.Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+		t.Errorf("Expected the origin to be allowed, got %q", origin)
+	}
+
+	for i := 0; i < 100 && st.Subscribers() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	evt, err := event.CreateTxLogEvent(neth.Log{
+		Hash:      "0x0100",
+		TxHash:    "0xdef",
+		ChainID:   "100",
+		Topic:     "0x01",
+		CreatedAt: time.Unix(1700000000, 0),
+		Value:     big.NewInt(42),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	evt.ID = "01"
+	if err := st.Publish(ctx, *evt); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+
+	// Keep-alive comments may come before the event
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(lines) > 0 && lines[0] != ": keep-alive" {
+				break
+			}
+			lines = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 3 || lines[0] != "id: 01" || lines[1] != "event: "+StreamEvent {
+		t.Fatalf("Expected the event 01, got %v", lines)
+	}
+	var msg StreamMessage
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &msg); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if msg.Name != "tx_log" || msg.ID != "01" {
+		t.Errorf("Expected the tx log 01, got %+v", msg)
+	}
+
+	// Other origins are not allowed
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/stream/sse", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	other, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	other.Body.Close()
+	if origin := other.Header.Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("Expected the origin not to be allowed, got %q", origin)
+	}
+}
+
+func TestNewStreamMessage(t *testing.T) {
+	msg := NewStreamMessage(&nostr.Event{ID: "01", Kind: 12345, Content: "hello"})
+	if msg.Data != "hello" || msg.Name != "" {
+		t.Errorf("Expected the raw content of a note, got %+v", msg)
+	}
+}
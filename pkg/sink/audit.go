@@ -0,0 +1,45 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+)
+
+// Auditor signs audit records and sends them to a sink, e.g. a publisher to relays. Records are
+// a side channel: failures are reported to onError instead of failing the audited operation.
+type Auditor struct {
+	sink       Sink
+	privateKey string
+	onError    func(error)
+}
+
+// NewAuditor creates a new auditor signing with a private key, errors are reported to onError
+// when it is not nil
+func NewAuditor(s Sink, privateKey string, onError func(error)) *Auditor {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Auditor{sink: s, privateKey: privateKey, onError: onError}
+}
+
+// Record sends an audit record, a nil auditor records nothing
+func (a *Auditor) Record(ctx context.Context, record event.AuditRecord) {
+	if a == nil {
+		return
+	}
+
+	evt, err := event.CreateAuditRecordEvent(record)
+	if err != nil {
+		a.onError(err)
+		return
+	}
+	if err := evt.Sign(a.privateKey); err != nil {
+		a.onError(fmt.Errorf("failed to sign audit record: %w", err))
+		return
+	}
+	if err := a.sink.Send(ctx, evt); err != nil {
+		a.onError(fmt.Errorf("failed to send audit record %s: %w", evt.ID, err))
+	}
+}
@@ -0,0 +1,51 @@
+package sink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+type recordingKafkaWriter struct {
+	messages []KafkaMessage
+}
+
+func (w *recordingKafkaWriter) WriteMessages(ctx context.Context, messages ...KafkaMessage) error {
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+type recordingNATSPublisher struct {
+	subjects []string
+}
+
+func (p *recordingNATSPublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	p.subjects = append(p.subjects, subject)
+	return nil
+}
+
+func TestBrokerSinksRouteByChainAndKind(t *testing.T) {
+	evt := &nostr.Event{
+		ID:      "abc",
+		Kind:    111000,
+		Tags:    nostr.Tags{{"t", "tx_log"}, {"layer", "42220"}},
+		Content: `{}`,
+	}
+
+	writer := &recordingKafkaWriter{}
+	if err := NewKafkaSink(writer, "nostr-eth", nil).Send(context.Background(), evt); err != nil {
+		t.Fatalf("Failed to send to kafka: %v", err)
+	}
+	if len(writer.messages) != 1 || string(writer.messages[0].Key) != "42220/111000" {
+		t.Errorf("Expected one message keyed 42220/111000, got %+v", writer.messages)
+	}
+
+	publisher := &recordingNATSPublisher{}
+	if err := NewNATSSink(publisher, "nostr-eth", nil).Send(context.Background(), evt); err != nil {
+		t.Fatalf("Failed to send to nats: %v", err)
+	}
+	if len(publisher.subjects) != 1 || publisher.subjects[0] != "nostr-eth.42220.111000" {
+		t.Errorf("Expected subject nostr-eth.42220.111000, got %v", publisher.subjects)
+	}
+}
@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Encoder serializes payloads for message brokers
+type Encoder interface {
+	Encode(payload Payload) ([]byte, error)
+	ContentType() string
+}
+
+// JSONEncoder encodes payloads as JSON
+type JSONEncoder struct{}
+
+// Encode encodes a payload as JSON
+func (JSONEncoder) Encode(payload Payload) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+// ContentType returns the MIME type of the encoded payloads
+func (JSONEncoder) ContentType() string {
+	return "application/json"
+}
+
+// RoutingKey returns the chain and kind of a payload as "<chain>/<kind>", the chain is
+// "unknown" for events without a layer tag
+func RoutingKey(payload Payload) string {
+	return fmt.Sprintf("%s/%d", chainOrUnknown(payload), payload.Kind)
+}
+
+// chainOrUnknown returns the chain of a payload or "unknown"
+func chainOrUnknown(payload Payload) string {
+	if payload.ChainID == "" {
+		return "unknown"
+	}
+	return payload.ChainID
+}
+
+// headers returns the message headers for a payload
+func headers(payload Payload, encoder Encoder) map[string]string {
+	return map[string]string{
+		"content-type": encoder.ContentType(),
+		"event-id":     payload.ID,
+		"kind":         strconv.Itoa(payload.Kind),
+		"chain-id":     payload.ChainID,
+	}
+}
+
+// ProtobufEncoder encodes payloads with the Payload message of pkg/pb/events.proto
+type ProtobufEncoder struct{}
+
+// Encode encodes a payload as a protobuf message
+func (ProtobufEncoder) Encode(payload Payload) ([]byte, error) {
+	msg := &pb.Payload{
+		Id:        payload.ID,
+		Kind:      int32(payload.Kind),
+		Type:      payload.Type,
+		ChainId:   payload.ChainID,
+		Pubkey:    payload.PubKey,
+		CreatedAt: timestamppb.New(payload.CreatedAt),
+		Data:      payload.Data,
+	}
+
+	if payload.Event != nil {
+		msg.Event = pb.FromEvent(payload.Event)
+	}
+
+	return proto.Marshal(msg)
+}
+
+// ContentType returns the MIME type of the encoded payloads
+func (ProtobufEncoder) ContentType() string {
+	return "application/x-protobuf"
+}
@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KafkaMessage is a message to write to a Kafka topic
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// KafkaWriter writes messages to Kafka, it is implemented by a thin adapter around the
+// client of choice (e.g. kafka-go or franz-go) so this package does not depend on one
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, messages ...KafkaMessage) error
+}
+
+// KafkaSink publishes events to a Kafka topic, keyed by chain and kind so that the events
+// of a chain and kind stay ordered within a partition
+type KafkaSink struct {
+	writer  KafkaWriter
+	topic   string
+	encoder Encoder
+}
+
+// NewKafkaSink creates a new Kafka sink, the payloads are encoded as JSON when encoder is nil
+func NewKafkaSink(writer KafkaWriter, topic string, encoder Encoder) *KafkaSink {
+	if encoder == nil {
+		encoder = JSONEncoder{}
+	}
+
+	return &KafkaSink{
+		writer:  writer,
+		topic:   topic,
+		encoder: encoder,
+	}
+}
+
+// Send publishes an event to the Kafka topic
+func (s *KafkaSink) Send(ctx context.Context, evt *nostr.Event) error {
+	payload := NewPayload(evt)
+
+	value, err := s.encoder.Encode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	message := KafkaMessage{
+		Topic:   s.topic,
+		Key:     []byte(RoutingKey(payload)),
+		Value:   value,
+		Headers: headers(payload, s.encoder),
+	}
+
+	if err := s.writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write event %s to kafka: %w", evt.ID, err)
+	}
+
+	return nil
+}
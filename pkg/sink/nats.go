@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// NATSPublisher publishes messages to NATS subjects, e.g. a thin wrapper around *nats.Conn
+type NATSPublisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+// NATSSink publishes events to NATS subjects of the form "<prefix>.<chain>.<kind>", so
+// consumers can subscribe to e.g. "nostr-eth.1.>" or "nostr-eth.*.111000"
+type NATSSink struct {
+	publisher NATSPublisher
+	prefix    string
+	encoder   Encoder
+}
+
+// NewNATSSink creates a new NATS sink, the payloads are encoded as JSON when encoder is nil
+func NewNATSSink(publisher NATSPublisher, prefix string, encoder Encoder) *NATSSink {
+	if encoder == nil {
+		encoder = JSONEncoder{}
+	}
+
+	return &NATSSink{
+		publisher: publisher,
+		prefix:    prefix,
+		encoder:   encoder,
+	}
+}
+
+// Subject returns the subject an event payload is published to
+func (s *NATSSink) Subject(payload Payload) string {
+	return fmt.Sprintf("%s.%s.%s", s.prefix, chainOrUnknown(payload), strconv.Itoa(payload.Kind))
+}
+
+// Send publishes an event to its NATS subject
+func (s *NATSSink) Send(ctx context.Context, evt *nostr.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	payload := NewPayload(evt)
+
+	data, err := s.encoder.Encode(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	if err := s.publisher.Publish(ctx, s.Subject(payload), data); err != nil {
+		return fmt.Errorf("failed to publish event %s to nats: %w", evt.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultPushTTL is how long push services keep a message for an offline device
+const DefaultPushTTL = 24 * time.Hour
+
+// PushSubscription is a device push messages are sent to. Web Push subscriptions of browsers
+// have the keys of the user agent, the messages are encrypted for them and authorized with
+// VAPID. UnifiedPush endpoints without keys receive the plain message.
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh,omitempty"`
+		Auth   string `json:"auth,omitempty"`
+	} `json:"keys"`
+}
+
+// PushMessage is the JSON message delivered to the devices, the service worker or the
+// UnifiedPush connector shows the title and body as a notification
+type PushMessage struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	EventID string `json:"event_id"`
+	Kind    int    `json:"kind"`
+	Type    string `json:"type,omitempty"`
+	ChainID string `json:"chain_id,omitempty"`
+}
+
+// PushTemplateData is what the title and body templates are executed with
+type PushTemplateData struct {
+	Payload
+	Content map[string]any    // Content of the event when it is a JSON object, e.g. .Content.log_data
+	Tags    map[string]string // First value of every tag, e.g. .Tags.layer
+}
+
+// pushTemplate renders the notification of an event type
+type pushTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// PushSink sends notifications for selected events to push services, so that mobile clients get
+// native notifications for payments or group activity. Events are selected by type, the first t
+// tag, and only the types with a template are sent.
+type PushSink struct {
+	client    *http.Client
+	vapid     *VAPIDKeys
+	ttl       time.Duration
+	templates map[string]*pushTemplate
+	formatter *event.Formatter
+
+	mu            sync.RWMutex
+	subscriptions map[string]PushSubscription // By endpoint
+}
+
+// PushOption configures a push sink
+type PushOption func(*PushSink) error
+
+// WithVAPID sets the keys Web Push messages are authorized with, they are required by the push
+// services of the browsers
+func WithVAPID(keys *VAPIDKeys) PushOption {
+	return func(s *PushSink) error {
+		s.vapid = keys
+		return nil
+	}
+}
+
+// WithPushTemplate sends the events of a type, e.g. "tx_log", with a title and body rendered
+// by text/template from PushTemplateData
+func WithPushTemplate(eventType, title, body string) PushOption {
+	return func(s *PushSink) error {
+		t := &pushTemplate{}
+
+		var err error
+		if t.title, err = template.New(eventType + " title").Option("missingkey=zero").Parse(title); err != nil {
+			return fmt.Errorf("invalid title template of %s: %w", eventType, err)
+		}
+		if t.body, err = template.New(eventType + " body").Option("missingkey=zero").Parse(body); err != nil {
+			return fmt.Errorf("invalid body template of %s: %w", eventType, err)
+		}
+
+		s.templates[eventType] = t
+		return nil
+	}
+}
+
+// WithPushFormatter also sends the events with a push body template in a formatter, titled with
+// its push title template. The templates of WithPushTemplate win over the formatter.
+func WithPushFormatter(formatter *event.Formatter) PushOption {
+	return func(s *PushSink) error {
+		s.formatter = formatter
+		return nil
+	}
+}
+
+// WithPushTTL sets how long push services keep a message for an offline device
+func WithPushTTL(ttl time.Duration) PushOption {
+	return func(s *PushSink) error {
+		s.ttl = ttl
+		return nil
+	}
+}
+
+// WithPushHTTPClient sets the HTTP client used to reach the push services
+func WithPushHTTPClient(client *http.Client) PushOption {
+	return func(s *PushSink) error {
+		s.client = client
+		return nil
+	}
+}
+
+// NewPushSink creates a new push sink, it fails when a template does not parse
+func NewPushSink(opts ...PushOption) (*PushSink, error) {
+	s := &PushSink{
+		client:        &http.Client{Timeout: 10 * time.Second},
+		ttl:           DefaultPushTTL,
+		templates:     make(map[string]*pushTemplate),
+		subscriptions: make(map[string]PushSubscription),
+	}
+
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Subscribe adds a device, replacing the subscription with the same endpoint
+func (s *PushSink) Subscribe(subscription PushSubscription) error {
+	if !strings.HasPrefix(subscription.Endpoint, "https://") && !strings.HasPrefix(subscription.Endpoint, "http://") {
+		return fmt.Errorf("invalid push endpoint: %s", subscription.Endpoint)
+	}
+	if (subscription.Keys.P256dh == "") != (subscription.Keys.Auth == "") {
+		return fmt.Errorf("push subscription needs both the p256dh and auth keys")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[subscription.Endpoint] = subscription
+	return nil
+}
+
+// Unsubscribe removes the device with an endpoint
+func (s *PushSink) Unsubscribe(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscriptions, endpoint)
+}
+
+// Subscriptions returns the subscribed devices
+func (s *PushSink) Subscriptions() []PushSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]PushSubscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions
+}
+
+// Send pushes the notification of an event to every device, events without a template are
+// skipped. Devices whose subscription expired are unsubscribed.
+func (s *PushSink) Send(ctx context.Context, evt *nostr.Event) error {
+	message, ok, err := s.Render(evt)
+	if err != nil || !ok {
+		return err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push message: %w", err)
+	}
+
+	var errs []error
+	for _, subscription := range s.Subscriptions() {
+		if err := s.push(ctx, subscription, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Render returns the push message of an event, false when its type has no template
+func (s *PushSink) Render(evt *nostr.Event) (*PushMessage, bool, error) {
+	payload := NewPayload(evt)
+
+	t, ok := s.templates[payload.Type]
+	if !ok {
+		return s.renderFormatter(evt, payload)
+	}
+
+	data := PushTemplateData{Payload: payload, Tags: make(map[string]string)}
+	json.Unmarshal([]byte(evt.Content), &data.Content)
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 {
+			if _, ok := data.Tags[tag[0]]; !ok {
+				data.Tags[tag[0]] = tag[1]
+			}
+		}
+	}
+
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, data); err != nil {
+		return nil, false, fmt.Errorf("failed to render push title: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return nil, false, fmt.Errorf("failed to render push body: %w", err)
+	}
+
+	return &PushMessage{
+		Title:   title.String(),
+		Body:    body.String(),
+		EventID: evt.ID,
+		Kind:    evt.Kind,
+		Type:    payload.Type,
+		ChainID: payload.ChainID,
+	}, true, nil
+}
+
+// renderFormatter returns the push message of an event rendered by the formatter, false when
+// the formatter has no push body template for it
+func (s *PushSink) renderFormatter(evt *nostr.Event, payload Payload) (*PushMessage, bool, error) {
+	if s.formatter == nil {
+		return nil, false, nil
+	}
+
+	body, ok, err := s.formatter.Format(event.FormatPushBody, evt)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	title, _, err := s.formatter.Format(event.FormatPushTitle, evt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &PushMessage{
+		Title:   title,
+		Body:    body,
+		EventID: evt.ID,
+		Kind:    evt.Kind,
+		Type:    payload.Type,
+		ChainID: payload.ChainID,
+	}, true, nil
+}
+
+// push delivers a message to a device
+func (s *PushSink) push(ctx context.Context, subscription PushSubscription, message []byte) error {
+	body, contentType := message, "application/json"
+	if subscription.Keys.P256dh != "" {
+		encrypted, err := encryptWebPush(message, subscription.Keys.P256dh, subscription.Keys.Auth)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt push message for %s: %w", subscription.Endpoint, err)
+		}
+		body, contentType = encrypted, "application/octet-stream"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("TTL", strconv.Itoa(int(s.ttl.Seconds())))
+	if subscription.Keys.P256dh != "" {
+		req.Header.Set("Content-Encoding", "aes128gcm")
+	}
+	if s.vapid != nil {
+		authorization, err := s.vapid.authorization(subscription.Endpoint, time.Now())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to %s: %w", subscription.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	// The device unsubscribed or the subscription expired
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		s.Unsubscribe(subscription.Endpoint)
+		return nil
+	}
+
+	return fmt.Errorf("push service %s responded with status %d", subscription.Endpoint, resp.StatusCode)
+}
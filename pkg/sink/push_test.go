@@ -0,0 +1,164 @@
+package sink
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// decryptWebPush decrypts a push message as a user agent would
+func decryptWebPush(t *testing.T, body []byte, uaPrivate *ecdh.PrivateKey, authSecret []byte) []byte {
+	salt, recordSize, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	asPublicBytes, ciphertext := body[21:21+idLen], body[21+idLen:]
+	if recordSize != webPushRecordSize {
+		t.Fatalf("Expected record size %d, got %d", webPushRecordSize, recordSize)
+	}
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	if err != nil {
+		t.Fatalf("Invalid key of the application server: %v", err)
+	}
+	cek, nonce, err := webPushKeys(uaPrivate, asPublic, asPublicBytes, uaPrivate.PublicKey().Bytes(), authSecret, salt)
+	if err != nil {
+		t.Fatalf("Failed to derive keys: %v", err)
+	}
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	record, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt push message: %v", err)
+	}
+	if record[len(record)-1] != 0x02 {
+		t.Fatalf("Expected the padding delimiter of the last record")
+	}
+	return record[:len(record)-1]
+}
+
+// verifyVAPID checks the VAPID authorization header of a request
+func verifyVAPID(t *testing.T, header string, keys *VAPIDKeys, audience string) {
+	parts := strings.Split(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if len(parts) != 2 || parts[1] != keys.PublicKey() {
+		t.Fatalf("Expected a VAPID header with the public key, got %s", header)
+	}
+
+	segments := strings.Split(parts[0], ".")
+	claims, _ := base64.RawURLEncoding.DecodeString(segments[1])
+	var decoded map[string]any
+	json.Unmarshal(claims, &decoded)
+	if decoded["aud"] != audience || decoded["sub"] != "mailto:ops@example.com" {
+		t.Errorf("Expected the audience %s, got %v", audience, decoded)
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(segments[2])
+	hash := sha256.Sum256([]byte(segments[0] + "." + segments[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(&keys.privateKey.PublicKey, hash[:], r, s) {
+		t.Error("Expected a valid VAPID signature")
+	}
+}
+
+func TestPushSink(t *testing.T) {
+	uaPrivate, _ := ecdh.P256().GenerateKey(rand.Reader)
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	generated, err := GenerateVAPIDKeys("mailto:ops@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+	keys, err := NewVAPIDKeys(generated.PrivateKey(), "mailto:ops@example.com")
+	if err != nil || keys.PublicKey() != generated.PublicKey() {
+		t.Fatalf("Expected the private key to load back, got %v", err)
+	}
+
+	var mu sync.Mutex
+	received := make(map[string][]byte)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/webpush":
+			if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") != "86400" {
+				t.Errorf("Expected an encrypted message with a TTL, got %v", r.Header)
+			}
+			verifyVAPID(t, r.Header.Get("Authorization"), keys, server.URL)
+			body = decryptWebPush(t, body, uaPrivate, authSecret)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+
+		mu.Lock()
+		received[r.URL.Path] = body
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sink, err := NewPushSink(
+		WithVAPID(keys),
+		WithPushTemplate("tx_log", "Payment on chain {{.ChainID}}", "{{.Content.event_type}} {{.Tags.amount}}"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create push sink: %v", err)
+	}
+	if _, err := NewPushSink(WithPushTemplate("tx_log", "{{.Title", "")); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+
+	webPush := PushSubscription{Endpoint: server.URL + "/webpush"}
+	webPush.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes())
+	webPush.Keys.Auth = base64.URLEncoding.EncodeToString(authSecret) // Padded, as some browsers export it
+	for _, subscription := range []PushSubscription{webPush, {Endpoint: server.URL + "/unifiedpush"}, {Endpoint: server.URL + "/gone"}} {
+		if err := sink.Subscribe(subscription); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+	}
+
+	evt := &nostr.Event{
+		ID:      "abc",
+		Kind:    111000,
+		Tags:    nostr.Tags{{"t", "tx_log"}, {"layer", "100"}, {"amount", "5"}},
+		Content: `{"event_type":"tx_log_created"}`,
+	}
+	if err := sink.Send(context.Background(), evt); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+
+	// Events without a template are not pushed
+	if err := sink.Send(context.Background(), &nostr.Event{ID: "def", Tags: nostr.Tags{{"t", "group"}}}); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+
+	for _, path := range []string{"/webpush", "/unifiedpush"} {
+		var message PushMessage
+		if err := json.Unmarshal(received[path], &message); err != nil {
+			t.Fatalf("Failed to unmarshal the message of %s: %v", path, err)
+		}
+		if message.Title != "Payment on chain 100" || message.Body != "tx_log_created 5" || message.EventID != "abc" {
+			t.Errorf("Expected the rendered message on %s, got %+v", path, message)
+		}
+	}
+
+	if len(sink.Subscriptions()) != 2 {
+		t.Errorf("Expected the gone subscription to be removed, got %d subscriptions", len(sink.Subscriptions()))
+	}
+}
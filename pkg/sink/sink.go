@@ -0,0 +1,110 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Sink delivers nostr-eth events to a system that does not speak Nostr
+type Sink interface {
+	Send(ctx context.Context, evt *nostr.Event) error
+}
+
+// Payload is the JSON representation of an event delivered by a sink
+type Payload struct {
+	ID        string          `json:"id"`
+	Kind      int             `json:"kind"`
+	Type      string          `json:"type,omitempty"`     // First t tag, e.g. "tx_log"
+	ChainID   string          `json:"chain_id,omitempty"` // Layer tag
+	PubKey    string          `json:"pubkey"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"` // The typed event struct, e.g. a TxLogEvent
+	Event     *nostr.Event    `json:"event"`
+}
+
+// NewPayload creates the payload for an event, the content is embedded as JSON when it is
+// valid JSON and as a string otherwise
+func NewPayload(evt *nostr.Event) Payload {
+	data := json.RawMessage(evt.Content)
+	if !json.Valid(data) {
+		data, _ = json.Marshal(evt.Content)
+	}
+
+	payload := Payload{
+		ID:        evt.ID,
+		Kind:      evt.Kind,
+		PubKey:    evt.PubKey,
+		CreatedAt: evt.CreatedAt.Time().UTC(),
+		Data:      data,
+		Event:     evt,
+	}
+
+	if tag := evt.Tags.GetFirst([]string{"t", ""}); tag != nil && len(*tag) >= 2 {
+		payload.Type = event.TypeTagValue((*tag)[1])
+	}
+
+	if tag := evt.Tags.GetFirst([]string{"layer", ""}); tag != nil && len(*tag) >= 2 {
+		payload.ChainID = (*tag)[1]
+	}
+
+	return payload
+}
+
+// DeadLetter is an event a sink failed to deliver
+type DeadLetter struct {
+	Event    *nostr.Event `json:"event"`
+	Target   string       `json:"target"`
+	Error    string       `json:"error"`
+	Attempts int          `json:"attempts"`
+	FailedAt time.Time    `json:"failed_at"`
+}
+
+// DeadLetterQueue stores the events a sink failed to deliver
+type DeadLetterQueue interface {
+	Push(ctx context.Context, letter DeadLetter) error
+}
+
+// MemoryDeadLetterQueue is an in-memory dead-letter queue
+type MemoryDeadLetterQueue struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+// NewMemoryDeadLetterQueue creates a new empty in-memory dead-letter queue
+func NewMemoryDeadLetterQueue() *MemoryDeadLetterQueue {
+	return &MemoryDeadLetterQueue{}
+}
+
+// Push adds a dead letter to the queue
+func (q *MemoryDeadLetterQueue) Push(ctx context.Context, letter DeadLetter) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.letters = append(q.letters, letter)
+
+	return nil
+}
+
+// Len returns the number of dead letters in the queue
+func (q *MemoryDeadLetterQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.letters)
+}
+
+// Drain removes and returns all dead letters, so they can be redelivered
+func (q *MemoryDeadLetterQueue) Drain() []DeadLetter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	letters := q.letters
+	q.letters = nil
+
+	return letters
+}
@@ -0,0 +1,187 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// HeaderSignature holds the hex encoded HMAC-SHA256 of the timestamp and body, as "sha256=<hex>"
+	HeaderSignature = "X-Nostr-Eth-Signature"
+
+	// HeaderTimestamp holds the unix time at which the request was signed
+	HeaderTimestamp = "X-Nostr-Eth-Timestamp"
+
+	// HeaderEventID holds the id of the delivered event, for idempotency
+	HeaderEventID = "X-Nostr-Eth-Event-Id"
+)
+
+// WebhookSink posts events as JSON payloads to an HTTP endpoint
+type WebhookSink struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+	deadLetter DeadLetterQueue
+}
+
+// WebhookOption configures a webhook sink
+type WebhookOption func(*WebhookSink)
+
+// WithSecret signs the payloads with an HMAC-SHA256 of the given secret
+func WithSecret(secret string) WebhookOption {
+	return func(s *WebhookSink) {
+		s.secret = []byte(secret)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to post the payloads
+func WithHTTPClient(client *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		s.client = client
+	}
+}
+
+// WithRetries sets the number of retries after a failed delivery and the initial backoff,
+// which is doubled after every attempt
+func WithRetries(maxRetries int, backoff time.Duration) WebhookOption {
+	return func(s *WebhookSink) {
+		s.maxRetries = maxRetries
+		s.backoff = backoff
+	}
+}
+
+// WithDeadLetterQueue stores the events that could not be delivered after all retries
+func WithDeadLetterQueue(queue DeadLetterQueue) WebhookOption {
+	return func(s *WebhookSink) {
+		s.deadLetter = queue
+	}
+}
+
+// NewWebhookSink creates a new webhook sink posting to the given URL
+func NewWebhookSink(url string, opts ...WebhookOption) *WebhookSink {
+	s := &WebhookSink{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		maxRetries: 3,
+		backoff:    time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Send posts an event to the webhook, retrying on network errors, 429 and 5xx responses.
+// Events that cannot be delivered are pushed to the dead-letter queue, if any.
+func (s *WebhookSink) Send(ctx context.Context, evt *nostr.Event) error {
+	body, err := json.Marshal(NewPayload(evt))
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	backoff := s.backoff
+	attempts := 0
+	for {
+		attempts++
+
+		retryable, err := s.post(ctx, evt.ID, body)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempts > s.maxRetries || ctx.Err() != nil {
+			return s.fail(ctx, evt, attempts, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return s.fail(ctx, evt, attempts, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt, reporting whether a failure may be retried
+func (s *WebhookSink) post(ctx context.Context, eventID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderEventID, eventID)
+	if len(s.secret) > 0 {
+		req.Header.Set(HeaderSignature, "sha256="+SignWebhookPayload(s.secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+	return retryable, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
+
+// fail pushes an undelivered event to the dead-letter queue
+func (s *WebhookSink) fail(ctx context.Context, evt *nostr.Event, attempts int, err error) error {
+	if s.deadLetter != nil {
+		letter := DeadLetter{
+			Event:    evt,
+			Target:   s.url,
+			Error:    err.Error(),
+			Attempts: attempts,
+			FailedAt: time.Now(),
+		}
+
+		// The dead letter is stored even if the context was cancelled
+		if dlqErr := s.deadLetter.Push(context.WithoutCancel(ctx), letter); dlqErr != nil {
+			return fmt.Errorf("failed to deliver event %s: %w (dead-letter queue: %v)", evt.ID, err, dlqErr)
+		}
+	}
+
+	return fmt.Errorf("failed to deliver event %s after %d attempts: %w", evt.ID, attempts, err)
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of a timestamp and body
+func SignWebhookPayload(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature header of a webhook request, receivers should
+// also reject timestamps that are too old to prevent replays
+func VerifyWebhookSignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	expected := "sha256=" + SignWebhookPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestWebhookSinkRetriesAndSigns(t *testing.T) {
+	secret := "s3cret"
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyWebhookSignature([]byte(secret), r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("Expected a valid signature")
+		}
+
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Failed to unmarshal payload: %v", err)
+		}
+		if payload.Type != "tx_log" || payload.ChainID != "1" {
+			t.Errorf("Expected type tx_log on chain 1, got %s on chain %s", payload.Type, payload.ChainID)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, WithSecret(secret), WithRetries(2, time.Millisecond))
+
+	evt := &nostr.Event{
+		ID:      "abc",
+		Kind:    111000,
+		Tags:    nostr.Tags{{"t", "tx_log"}, {"layer", "1"}},
+		Content: `{"event_type":"tx_log_created"}`,
+	}
+
+	if err := sink.Send(context.Background(), evt); err != nil {
+		t.Fatalf("Expected delivery to succeed, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls.Load())
+	}
+}
+
+func TestWebhookSinkDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	queue := NewMemoryDeadLetterQueue()
+	sink := NewWebhookSink(server.URL, WithRetries(3, time.Millisecond), WithDeadLetterQueue(queue))
+
+	if err := sink.Send(context.Background(), &nostr.Event{ID: "abc", Content: "hello"}); err == nil {
+		t.Fatalf("Expected delivery to fail")
+	}
+
+	letters := queue.Drain()
+	if len(letters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(letters))
+	}
+	if letters[0].Attempts != 1 {
+		t.Errorf("Expected client errors not to be retried, got %d attempts", letters[0].Attempts)
+	}
+}
@@ -0,0 +1,203 @@
+package sink
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// webPushRecordSize is the record size of the encrypted push messages, they fit in one record
+const webPushRecordSize = 4096
+
+// VAPIDKeys identify the application server to the push services of the browsers (RFC 8292)
+type VAPIDKeys struct {
+	privateKey *ecdsa.PrivateKey
+	subject    string // Contact of the operator, a mailto: or https: URL
+}
+
+// GenerateVAPIDKeys creates new VAPID keys, the public key is given to the browsers when they
+// subscribe and the private key must be kept to send them messages
+func GenerateVAPIDKeys(subject string) (*VAPIDKeys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewVAPIDKeys(base64.RawURLEncoding.EncodeToString(key.Bytes()), subject)
+}
+
+// NewVAPIDKeys loads VAPID keys from a base64url encoded private key
+func NewVAPIDKeys(privateKey, subject string) (*VAPIDKeys, error) {
+	raw, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public := key.PublicKey().Bytes()
+
+	return &VAPIDKeys{
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		subject: subject,
+	}, nil
+}
+
+// PublicKey returns the base64url encoded public key, the applicationServerKey of the browsers
+func (k *VAPIDKeys) PublicKey() string {
+	public, _ := k.privateKey.PublicKey.ECDH()
+	return base64.RawURLEncoding.EncodeToString(public.Bytes())
+}
+
+// PrivateKey returns the base64url encoded private key, to be stored and loaded with NewVAPIDKeys
+func (k *VAPIDKeys) PrivateKey() string {
+	return base64.RawURLEncoding.EncodeToString(k.privateKey.D.FillBytes(make([]byte, 32)))
+}
+
+// authorization returns the VAPID Authorization header for a push endpoint, a JWT signed with
+// ES256 for the origin of the endpoint
+func (k *VAPIDKeys) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": k.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.privateKey, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return fmt.Sprintf("vapid t=%s, k=%s", token, k.PublicKey()), nil
+}
+
+// encryptWebPush encrypts a push message for a subscription with the aes128gcm content coding
+// of RFC 8291
+func encryptWebPush(plaintext []byte, p256dh, auth string) ([]byte, error) {
+	uaPublicBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	cek, nonce, err := webPushKeys(asPrivate, uaPublic, asPublic, uaPublicBytes, authSecret, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single record, terminated by the padding delimiter of the last record
+	record := append(append([]byte(nil), plaintext...), 0x02)
+	if len(record)+gcm.Overhead() > webPushRecordSize {
+		return nil, fmt.Errorf("push message too large: %d bytes", len(plaintext))
+	}
+
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// webPushKeys derives the content encryption key and nonce of a push message from the shared
+// secret of the application server and the user agent
+func webPushKeys(private *ecdh.PrivateKey, peer *ecdh.PublicKey, asPublic, uaPublic, authSecret, salt []byte) ([]byte, []byte, error) {
+	secret, err := private.ECDH(peer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prkKey, err := hkdf.Extract(sha256.New, secret, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cek, nonce, nil
+}
+
+// decodeBase64URL decodes base64url, with or without padding, as browsers export both
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
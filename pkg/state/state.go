@@ -0,0 +1,172 @@
+// Package state persists the progress of long-running subsystems so that they resume where
+// they stopped after a restart
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when no checkpoint was saved for a key
+var ErrNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is the progress of a subsystem
+type Checkpoint struct {
+	Key       string          `json:"key"`                // Subsystem, e.g. "watcher:100"
+	Block     uint64          `json:"block,omitempty"`    // Last processed block
+	EventID   string          `json:"event_id,omitempty"` // Last published event
+	Data      json.RawMessage `json:"data,omitempty"`     // Subsystem specific state
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Store loads and saves checkpoints, implementations backed by a database or a remote service
+// stop waiting when the context is done
+type Store interface {
+	Load(ctx context.Context, key string) (*Checkpoint, error)
+	Save(ctx context.Context, checkpoint Checkpoint) error
+}
+
+// MemoryStore keeps checkpoints in memory, progress survives restarts of a subsystem but not
+// of the process
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+// Load returns the checkpoint saved for a key
+func (s *MemoryStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoint, ok := s.checkpoints[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return &checkpoint, nil
+}
+
+// Save saves a checkpoint, replacing the previous one of its key
+func (s *MemoryStore) Save(ctx context.Context, checkpoint Checkpoint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[checkpoint.Key] = checkpoint
+	return nil
+}
+
+// FileStore keeps all checkpoints in a JSON file, the file is replaced atomically on every save
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a new store backed by the file at path, the file is created on the
+// first save
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns the checkpoint saved for a key
+func (s *FileStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, ok := checkpoints[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return &checkpoint, nil
+}
+
+// Save saves a checkpoint, replacing the previous one of its key
+func (s *FileStore) Save(ctx context.Context, checkpoint Checkpoint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[checkpoint.Key] = checkpoint
+
+	b, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a truncated file behind
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file: %w", err)
+	}
+
+	return nil
+}
+
+// read reads all checkpoints, a missing file holds none
+func (s *FileStore) read() (map[string]Checkpoint, error) {
+	checkpoints := make(map[string]Checkpoint)
+
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &checkpoints); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file: %w", err)
+	}
+
+	return checkpoints, nil
+}
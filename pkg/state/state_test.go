@@ -0,0 +1,48 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+
+	store := NewFileStore(path)
+
+	if _, err := store.Load(context.Background(), "watcher:100"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	for _, checkpoint := range []Checkpoint{
+		{Key: "watcher:100", Block: 10, EventID: "a", Data: []byte(`{"next":11}`), UpdatedAt: time.Unix(1700000000, 0).UTC()},
+		{Key: "publisher", EventID: "b", UpdatedAt: time.Unix(1700000000, 0).UTC()},
+		{Key: "watcher:100", Block: 12, EventID: "c", UpdatedAt: time.Unix(1700000001, 0).UTC()},
+	} {
+		if err := store.Save(context.Background(), checkpoint); err != nil {
+			t.Fatalf("Failed to save checkpoint: %v", err)
+		}
+	}
+
+	// A new store reads the progress saved by the previous one
+	store = NewFileStore(path)
+
+	checkpoint, err := store.Load(context.Background(), "watcher:100")
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if checkpoint.Block != 12 || checkpoint.EventID != "c" || checkpoint.Data != nil {
+		t.Errorf("Expected the last saved checkpoint, got %+v", checkpoint)
+	}
+
+	checkpoint, err = store.Load(context.Background(), "publisher")
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if checkpoint.EventID != "b" {
+		t.Errorf("Expected event b, got %s", checkpoint.EventID)
+	}
+}
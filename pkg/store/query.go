@@ -0,0 +1,490 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Query is a compiled filter expression over events, e.g.
+//
+//	kind=111000 AND chain="100" AND amount>1e18
+//
+// Conditions compare a field with a value using =, !=, <, <=, > or >=, and combine with AND, OR,
+// NOT and parentheses. The fields are kind, id, pubkey (or author), created_at and content, chain
+// for the layer tag, and any other name for the values of the tags of that name; a condition on a
+// tag holds when one of its values satisfies it. Values are compared as numbers when both sides
+// are numbers, e.g. 1e18, 0.5 or 0xabc, and as strings otherwise. A kind matches both the kind of
+// the package and its remapped kind.
+type Query struct {
+	expr   string
+	root   node
+	filter nostr.Filter
+}
+
+// ParseQuery compiles a filter expression
+func ParseQuery(expr string) (*Query, error) {
+	tokens, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+
+	q := &Query{expr: expr, root: root}
+	q.filter = compileFilter(root)
+	return q, nil
+}
+
+// String returns the expression of the query
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Filter returns the store filter of the query, it selects a superset of the matching events from
+// the conditions that every match satisfies: kinds, IDs, authors, time bounds and single-letter
+// tags equal to strings or hexadecimal values
+func (q *Query) Filter() nostr.Filter {
+	filter := q.filter
+	filter.Kinds = append([]int(nil), q.filter.Kinds...)
+	filter.IDs = append([]string(nil), q.filter.IDs...)
+	filter.Authors = append([]string(nil), q.filter.Authors...)
+	if q.filter.Tags != nil {
+		filter.Tags = make(nostr.TagMap, len(q.filter.Tags))
+		for name, values := range q.filter.Tags {
+			filter.Tags[name] = append([]string(nil), values...)
+		}
+	}
+	return filter
+}
+
+// Match checks if an event matches the query
+func (q *Query) Match(evt *nostr.Event) bool {
+	return evt != nil && q.root.match(evt)
+}
+
+// Run queries a store with the filter of the query and returns the matching events, newest first.
+// A limit of 0 returns all of them.
+func (q *Query) Run(ctx context.Context, querier event.EventQuerier, limit int) ([]*nostr.Event, error) {
+	events, err := querier.Query(ctx, q.Filter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	matches := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if q.Match(evt) {
+			matches = append(matches, evt)
+		}
+	}
+
+	event.SortEventsByCreatedAt(matches, true)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// node is a node of the expression tree of a query
+type node interface {
+	match(evt *nostr.Event) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(evt *nostr.Event) bool { return n.left.match(evt) && n.right.match(evt) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(evt *nostr.Event) bool { return n.left.match(evt) || n.right.match(evt) }
+
+type notNode struct{ operand node }
+
+func (n notNode) match(evt *nostr.Event) bool { return !n.operand.match(evt) }
+
+// condition compares the values of a field with a value
+type condition struct {
+	field  string
+	op     string
+	value  string
+	number *big.Rat // Value as a number, nil when it is not one
+}
+
+func (c condition) match(evt *nostr.Event) bool {
+	if c.op == "!=" {
+		return !condition{field: c.field, op: "=", value: c.value, number: c.number}.match(evt)
+	}
+
+	for _, value := range fieldValues(evt, c.field) {
+		if c.compare(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare compares a value of a field with the value of the condition
+func (c condition) compare(value string) bool {
+	cmp := 0
+	if number, ok := parseNumber(value); ok && c.number != nil {
+		cmp = number.Cmp(c.number)
+	} else {
+		cmp = strings.Compare(value, c.value)
+	}
+
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// fieldValues returns the values of a field of an event
+func fieldValues(evt *nostr.Event, field string) []string {
+	switch field {
+	case "kind":
+		values := []string{strconv.Itoa(evt.Kind)}
+		if kind := event.DefaultKind(evt.Kind); kind != evt.Kind {
+			values = append(values, strconv.Itoa(kind))
+		}
+		return values
+	case "id":
+		return []string{evt.ID}
+	case "pubkey", "author":
+		return []string{evt.PubKey}
+	case "created_at":
+		return []string{strconv.FormatInt(int64(evt.CreatedAt), 10)}
+	case "content":
+		return []string{evt.Content}
+	case "chain":
+		field = "layer"
+	}
+
+	var values []string
+	for _, tag := range evt.Tags {
+		if len(tag) >= 2 && tag[0] == field {
+			values = append(values, tag[1])
+		}
+	}
+	return values
+}
+
+// maxExponent bounds the exponents of the numbers of a query, big numbers are expanded in full
+const maxExponent = 1000
+
+// parseNumber parses a decimal, scientific or hexadecimal number
+func parseNumber(value string) (*big.Rat, bool) {
+	if value == "" || strings.ContainsAny(value, "/") {
+		return nil, false
+	}
+	if !strings.HasPrefix(strings.ToLower(strings.TrimLeft(value, "+-")), "0x") {
+		if i := strings.IndexAny(value, "eE"); i >= 0 {
+			exponent, err := strconv.Atoi(value[i+1:])
+			if err != nil || exponent > maxExponent || exponent < -maxExponent {
+				return nil, false
+			}
+		}
+	}
+	return new(big.Rat).SetString(value)
+}
+
+// compileFilter returns the filter of the conditions that every match of a tree satisfies, the
+// conditions joined by AND from the root
+func compileFilter(root node) nostr.Filter {
+	var filter nostr.Filter
+
+	var visit func(n node)
+	visit = func(n node) {
+		switch n := n.(type) {
+		case andNode:
+			visit(n.left)
+			visit(n.right)
+		case condition:
+			addCondition(&filter, n)
+		}
+	}
+	visit(root)
+
+	return filter
+}
+
+// addCondition narrows a filter with a condition, the first condition on a field wins
+func addCondition(filter *nostr.Filter, c condition) {
+	switch {
+	case c.field == "kind" && c.op == "=" && filter.Kinds == nil:
+		kind, err := strconv.Atoi(c.value)
+		if err != nil {
+			return
+		}
+		filter.Kinds = []int{kind}
+		if mapped := event.MappedKind(kind); mapped != kind {
+			filter.Kinds = append(filter.Kinds, mapped)
+		}
+
+	case c.field == "id" && c.op == "=" && filter.IDs == nil:
+		filter.IDs = []string{c.value}
+
+	case (c.field == "pubkey" || c.field == "author") && c.op == "=" && filter.Authors == nil:
+		filter.Authors = []string{c.value}
+
+	case c.field == "created_at" && c.number != nil && c.number.IsInt() && c.number.Num().IsInt64():
+		n := c.number.Num().Int64()
+		switch {
+		case (c.op == ">=" || c.op == ">") && filter.Since == nil:
+			if c.op == ">" {
+				n++
+			}
+			since := nostr.Timestamp(n)
+			filter.Since = &since
+		case (c.op == "<=" || c.op == "<") && filter.Until == nil:
+			if c.op == "<" {
+				n--
+			}
+			until := nostr.Timestamp(n)
+			filter.Until = &until
+		}
+
+	case len(c.field) == 1 && c.op == "=" && (c.number == nil || isHex(c.value)):
+		if filter.Tags == nil {
+			filter.Tags = make(nostr.TagMap)
+		}
+		if _, ok := filter.Tags[c.field]; !ok {
+			filter.Tags[c.field] = tagValues(c.value)
+		}
+	}
+}
+
+// tagValues returns the spellings of a tag value a store must match, hexadecimal values are
+// compared as numbers so their lowercase and checksummed forms are included
+func tagValues(value string) []string {
+	values := []string{value}
+	if !isHex(value) {
+		return values
+	}
+
+	spellings := []string{strings.ToLower(value)}
+	if common.IsHexAddress(value) {
+		spellings = append(spellings, common.HexToAddress(value).Hex())
+	}
+	for _, spelling := range spellings {
+		if !slices.Contains(values, spelling) {
+			values = append(values, spelling)
+		}
+	}
+	return values
+}
+
+// isHex checks if a value is a hexadecimal number, e.g. an address or a hash
+func isHex(value string) bool {
+	return strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X")
+}
+
+// queryToken is a token of a filter expression
+type queryToken struct {
+	kind string // "word", "string", "op", "(" or ")"
+	text string
+	pos  int
+}
+
+// lexQuery splits a filter expression into tokens
+func lexQuery(expr string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, queryToken{kind: string(c), text: string(c), pos: i})
+			i++
+
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected %q at %d", op, i)
+			}
+			tokens = append(tokens, queryToken{kind: "op", text: op, pos: i})
+			i += len(op)
+
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i, err)
+			}
+			tokens = append(tokens, queryToken{kind: "string", text: value, pos: i})
+			i = end + 1
+
+		case isWordChar(rune(c)):
+			end := i
+			for end < len(expr) && isWordChar(rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: "word", text: expr[i:end], pos: i})
+			i = end
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+// isWordChar checks if a character can be part of a field name or an unquoted value
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:+", r)
+}
+
+// queryParser parses the tokens of a filter expression, AND binds tighter than OR
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// keyword checks if the next token is a keyword and consumes it
+func (p *queryParser) keyword(keyword string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "word" && strings.EqualFold(p.tokens[p.pos].text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// next consumes the next token, an error is returned at the end of the expression
+func (p *queryParser) next(expected string) (queryToken, error) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, fmt.Errorf("expected %s at the end of the query", expected)
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *queryParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (node, error) {
+	if p.keyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *queryParser) parseTerm() (node, error) {
+	token, err := p.next("a condition")
+	if err != nil {
+		return nil, err
+	}
+
+	if token.kind == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.next(`")"`)
+		if err != nil {
+			return nil, err
+		}
+		if closing.kind != ")" {
+			return nil, fmt.Errorf(`expected ")" at %d, got %q`, closing.pos, closing.text)
+		}
+		return inner, nil
+	}
+
+	if token.kind != "word" {
+		return nil, fmt.Errorf("expected a field at %d, got %q", token.pos, token.text)
+	}
+
+	op, err := p.next("an operator")
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != "op" {
+		return nil, fmt.Errorf("expected an operator at %d, got %q", op.pos, op.text)
+	}
+
+	value, err := p.next("a value")
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != "word" && value.kind != "string" {
+		return nil, fmt.Errorf("expected a value at %d, got %q", value.pos, value.text)
+	}
+
+	c := condition{field: token.text, op: op.text, value: value.text}
+	if number, ok := parseNumber(value.text); ok {
+		c.number = number
+	}
+	return c, nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestQueryMatch(t *testing.T) {
+	large, _ := event.CreateTxTransferEvent(testTransfer("0x01", alice, bob, 2000000000000000000))
+	large.ID, large.CreatedAt = "01", 100
+	small, _ := event.CreateTxTransferEvent(testTransfer("0x02", bob, carol, 5))
+	small.ID, small.CreatedAt = "02", 200
+
+	tests := []struct {
+		expr  string
+		large bool
+		small bool
+	}{
+		{`kind=` + itoa(event.KindTxTransfer) + ` AND chain="100" AND amount>1e18`, true, false},
+		{`amount<=5`, false, true},
+		{`amount >= 5 AND amount < 2e18`, false, true},
+		{`P=0X` + strings.ToUpper(alice[2:]), true, false},
+		{`P=` + alice, true, false},
+		{`P=0x1111111111111111111111111111111111111111 OR P=` + bob, true, true},
+		{`NOT (chain=100)`, false, false},
+		{`chain!=1`, true, true},
+		{`d="0x02" or created_at<150`, true, true},
+		{`created_at>100 AND (amount=5 OR amount=6)`, false, true},
+		{`t=tx_transfer AND NOT t=dust`, true, true},
+		{`missing=1`, false, false},
+		{`missing!=1`, true, true},
+	}
+
+	for _, test := range tests {
+		q, err := ParseQuery(test.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", test.expr, err)
+		}
+		if got := q.Match(large); got != test.large {
+			t.Errorf("Expected %s to match the large transfer %v, got %v", test.expr, test.large, got)
+		}
+		if got := q.Match(small); got != test.small {
+			t.Errorf("Expected %s to match the small transfer %v, got %v", test.expr, test.small, got)
+		}
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	q, err := ParseQuery(`kind=1 AND created_at>100 AND created_at<=200 AND p=0xe91d153e0b41518a2ce8dd3d7944fa863463a97d AND author=abc AND (t=a OR t=b)`)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	filter := q.Filter()
+	if len(filter.Kinds) != 1 || filter.Kinds[0] != 1 {
+		t.Errorf("Expected kinds [1], got %v", filter.Kinds)
+	}
+	if filter.Since == nil || *filter.Since != 101 || filter.Until == nil || *filter.Until != 200 {
+		t.Errorf("Expected since 101 and until 200, got %v and %v", filter.Since, filter.Until)
+	}
+	if len(filter.Authors) != 1 || filter.Authors[0] != "abc" {
+		t.Errorf("Expected authors [abc], got %v", filter.Authors)
+	}
+
+	// Hexadecimal values are queried with their lowercase and checksummed spellings
+	if values := filter.Tags["p"]; len(values) != 2 || values[1] != token {
+		t.Errorf("Expected the lowercase and checksummed spellings of %s, got %v", token, values)
+	}
+
+	// Conditions under OR do not narrow the filter
+	if _, ok := filter.Tags["t"]; ok {
+		t.Errorf("Expected no t tag filter, got %v", filter.Tags["t"])
+	}
+
+	if filter := mustParseQuery(t, `kind=1 OR kind=2`).Filter(); filter.Kinds != nil {
+		t.Errorf("Expected no kinds, got %v", filter.Kinds)
+	}
+}
+
+func TestQueryRun(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	for i, value := range []int64{10, 20, 30} {
+		evt, _ := event.CreateTxTransferEvent(testTransfer("0x0"+itoa(i), alice, bob, value))
+		evt.ID, evt.CreatedAt = "0"+itoa(i), nostr.Timestamp(100+i)
+		if err := s.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+	note := testEvent("03", 1, 200)
+	if err := s.Publish(ctx, note); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	events, err := mustParseQuery(t, `kind=`+itoa(event.KindTxTransfer)+` AND amount>=20`).Run(ctx, s, 0)
+	if err != nil {
+		t.Fatalf("Failed to run query: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "02" || events[1].ID != "01" {
+		t.Errorf("Expected events 02 and 01, got %v", events)
+	}
+
+	events, _ = mustParseQuery(t, `amount>=20 OR kind=1`).Run(ctx, s, 1)
+	if len(events) != 1 || events[0].ID != "03" {
+		t.Errorf("Expected event 03 only, got %v", events)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`kind`,
+		`kind=`,
+		`kind=1 AND`,
+		`(kind=1`,
+		`kind=1)`,
+		`kind!1`,
+		`chain="100`,
+		`=1`,
+		`kind=1 kind=2`,
+	} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Errorf("Expected %q to be invalid", expr)
+		}
+	}
+}
+
+func mustParseQuery(t *testing.T, expr string) *Query {
+	t.Helper()
+
+	q, err := ParseQuery(expr)
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", expr, err)
+	}
+	return q
+}
+
+func itoa(i int) string {
+	return strconv.Itoa(i)
+}
@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ErrNoID is returned when an event without an ID is published to a store
+var ErrNoID = errors.New("event has no ID")
+
+// MemoryStore keeps events in memory and serves many readers while events are written, e.g. the
+// subscriber of a bridge ingesting while HTTP handlers serve queries. Writers are serialized, and
+// readers query a Snapshot that no write changes. Stored events are never modified in place:
+// appends grow the event list past the snapshots and deletes replace it, so taking a snapshot
+// only copies a slice header.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	events  []*nostr.Event // In insertion order, never modified in place
+	ids     map[string]bool
+	version uint64
+
+	views *Views
+	stale bool // A delete left the views behind the events
+
+	subscriptions map[*Subscription]bool
+}
+
+// NewMemoryStore creates a new empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ids: make(map[string]bool), views: NewViews(), subscriptions: make(map[*Subscription]bool)}
+}
+
+// Snapshot is the content of a store at a version. It is immutable and can be read without
+// locking while the store is written.
+type Snapshot struct {
+	events  []*nostr.Event
+	version uint64
+}
+
+// Snapshot returns the current content of the store
+func (s *MemoryStore) Snapshot() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Snapshot{events: s.events[:len(s.events):len(s.events)], version: s.version}
+}
+
+// Publish stores a copy of an event and updates the views, events already stored are ignored.
+// Events the views cannot parse are stored without being projected.
+func (s *MemoryStore) Publish(ctx context.Context, evt nostr.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if evt.ID == "" {
+		return ErrNoID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ids[evt.ID] {
+		return nil
+	}
+
+	s.events = append(s.events, &evt)
+	s.ids[evt.ID] = true
+	s.version++
+
+	if !s.stale {
+		_ = s.views.Apply(&evt)
+	}
+
+	for sub := range s.subscriptions {
+		sub.send(evt)
+	}
+	return nil
+}
+
+// Delete removes an event from the store, snapshots taken before keep it. Deleting an unknown
+// event does nothing.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ids[id] {
+		return nil
+	}
+
+	events := make([]*nostr.Event, 0, len(s.events)-1)
+	for _, evt := range s.events {
+		if evt.ID != id {
+			events = append(events, evt)
+		}
+	}
+	s.events = events
+	delete(s.ids, id)
+	s.version++
+
+	// Views are only maintained on insert, they are rebuilt when next read
+	s.stale = true
+	return nil
+}
+
+// Query returns copies of the events of the current snapshot matching a filter, newest first
+func (s *MemoryStore) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Snapshot().Query(filter), nil
+}
+
+// Version returns the number of writes applied to the store
+func (s *MemoryStore) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version
+}
+
+// Views returns the materialized views of the stored events. They are updated by the next
+// publications until an event is deleted, then new views are built from the remaining events.
+func (s *MemoryStore) Views() *Views {
+	s.mu.RLock()
+	if !s.stale {
+		defer s.mu.RUnlock()
+		return s.views
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stale {
+		views := NewViews()
+		for _, evt := range s.events {
+			_ = views.Apply(evt)
+		}
+		s.views = views
+		s.stale = false
+	}
+	return s.views
+}
+
+// Subscription receives the events published to a store after it was created. Publishing never
+// waits for subscribers: events that do not fit in the buffer of a subscription are dropped and
+// counted.
+type Subscription struct {
+	store   *MemoryStore
+	match   func(evt *nostr.Event) bool
+	events  chan *nostr.Event
+	dropped atomic.Uint64
+}
+
+// Subscribe creates a new subscription to the events published to the store that match, every
+// event when match is nil, buffering up to buffer events. Match is called while the store is
+// written and must be fast.
+func (s *MemoryStore) Subscribe(buffer int, match func(evt *nostr.Event) bool) *Subscription {
+	sub := &Subscription{store: s, match: match, events: make(chan *nostr.Event, buffer)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscriptions[sub] = true
+	return sub
+}
+
+// Subscribers returns the number of open subscriptions
+func (s *MemoryStore) Subscribers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.subscriptions)
+}
+
+// send hands an event to the subscriber or drops it when the buffer is full, the store is locked
+func (sub *Subscription) send(evt nostr.Event) {
+	if sub.match != nil && !sub.match(&evt) {
+		return
+	}
+
+	select {
+	case sub.events <- &evt:
+	default:
+		sub.dropped.Add(1)
+	}
+}
+
+// Events returns the channel of the published events, it is closed by Close
+func (sub *Subscription) Events() <-chan *nostr.Event {
+	return sub.events
+}
+
+// Dropped returns the number of events dropped since the last call
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Swap(0)
+}
+
+// Close stops the subscription and closes its channel
+func (sub *Subscription) Close() {
+	sub.store.mu.Lock()
+	defer sub.store.mu.Unlock()
+
+	if sub.store.subscriptions[sub] {
+		delete(sub.store.subscriptions, sub)
+		close(sub.events)
+	}
+}
+
+// Version returns the version of the store the snapshot was taken at
+func (s *Snapshot) Version() uint64 {
+	return s.version
+}
+
+// Len returns the number of events of the snapshot
+func (s *Snapshot) Len() int {
+	return len(s.events)
+}
+
+// Iterate calls fn with the events of the snapshot in insertion order until it returns false.
+// It sees exactly the events stored when the snapshot was taken, whatever is written meanwhile,
+// and fn must not modify them.
+func (s *Snapshot) Iterate(fn func(evt *nostr.Event) bool) {
+	for _, evt := range s.events {
+		if !fn(evt) {
+			return
+		}
+	}
+}
+
+// Get returns a copy of an event of the snapshot by ID
+func (s *Snapshot) Get(id string) (*nostr.Event, bool) {
+	for _, evt := range s.events {
+		if evt.ID == id {
+			stored := *evt
+			return &stored, true
+		}
+	}
+	return nil, false
+}
+
+// Query returns copies of the events of the snapshot matching a filter, newest first and up to
+// the limit of the filter
+func (s *Snapshot) Query(filter nostr.Filter) []*nostr.Event {
+	var events []*nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(evt) {
+			stored := *evt
+			events = append(events, &stored)
+		}
+	}
+
+	event.SortEventsByCreatedAt(events, true)
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func testEvent(id string, kind int, createdAt nostr.Timestamp) nostr.Event {
+	return nostr.Event{ID: id, Kind: kind, CreatedAt: createdAt, Tags: nostr.Tags{}}
+}
+
+func TestMemoryStoreQuery(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	for _, evt := range []nostr.Event{
+		testEvent("01", 1, 100),
+		testEvent("02", 1, 300),
+		testEvent("03", 7, 200),
+		testEvent("01", 1, 100), // Duplicate
+	} {
+		if err := s.Publish(ctx, evt); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+	if err := s.Publish(ctx, testEvent("", 1, 100)); !errors.Is(err, ErrNoID) {
+		t.Errorf("Expected ErrNoID, got %v", err)
+	}
+
+	if version := s.Version(); version != 3 {
+		t.Errorf("Expected version 3, got %d", version)
+	}
+
+	events, err := s.Query(ctx, nostr.Filter{Kinds: []int{1}})
+	if err != nil {
+		t.Fatalf("Failed to query events: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "02" || events[1].ID != "01" {
+		t.Errorf("Expected events 02 and 01, got %v", events)
+	}
+
+	events, _ = s.Query(ctx, nostr.Filter{Limit: 1})
+	if len(events) != 1 || events[0].ID != "02" {
+		t.Errorf("Expected the newest event only, got %v", events)
+	}
+
+	// Queries return copies
+	events[0].Content = "changed"
+	if evt, _ := s.Snapshot().Get("02"); evt.Content != "" {
+		t.Error("Expected the stored event not to be changed by its copies")
+	}
+}
+
+func TestMemoryStoreSnapshotIsolation(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	for i := 0; i < 3; i++ {
+		if err := s.Publish(ctx, testEvent(fmt.Sprintf("%02d", i), 1, nostr.Timestamp(100+i))); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+
+	snapshot := s.Snapshot()
+
+	if err := s.Publish(ctx, testEvent("03", 1, 103)); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+	if err := s.Delete(ctx, "00"); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+
+	// The snapshot keeps the deleted event and does not see the new one
+	var ids []string
+	snapshot.Iterate(func(evt *nostr.Event) bool {
+		ids = append(ids, evt.ID)
+		return true
+	})
+	if fmt.Sprint(ids) != "[00 01 02]" {
+		t.Errorf("Expected events [00 01 02], got %v", ids)
+	}
+	if snapshot.Version() != 3 {
+		t.Errorf("Expected version 3, got %d", snapshot.Version())
+	}
+
+	current := s.Snapshot()
+	if current.Len() != 3 || current.Version() != 5 {
+		t.Errorf("Expected 3 events at version 5, got %d at %d", current.Len(), current.Version())
+	}
+	if _, ok := current.Get("00"); ok {
+		t.Error("Expected event 00 to have been deleted")
+	}
+
+	// Appending after a snapshot does not overwrite the events it sees
+	snapshot = s.Snapshot()
+	if err := s.Publish(ctx, testEvent("04", 1, 104)); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+	if snapshot.Len() != 3 {
+		t.Errorf("Expected 3 events, got %d", snapshot.Len())
+	}
+}
+
+func TestMemoryStoreViews(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	first, _ := event.CreateTxTransferEvent(testTransfer("0x01", alice, bob, 10))
+	first.ID, first.CreatedAt = "01", 100
+	second, _ := event.CreateTxTransferEvent(testTransfer("0x02", alice, bob, 20))
+	second.ID, second.CreatedAt = "02", 200
+
+	for _, evt := range []*nostr.Event{first, second} {
+		if err := s.Publish(ctx, *evt); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+
+	if logs := s.Views().LatestLogs(bob, 0); len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+
+	// Deleted events leave the views
+	if err := s.Delete(ctx, "02"); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	if logs := s.Views().LatestLogs(bob, 0); len(logs) != 1 || logs[0].Hash != "0x01" {
+		t.Errorf("Expected log 0x01 only, got %v", logs)
+	}
+}
+
+func TestMemoryStoreConcurrentReaders(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			_ = s.Publish(ctx, testEvent(fmt.Sprintf("%03d", i), 1, nostr.Timestamp(i)))
+			if i%10 == 0 {
+				_ = s.Delete(ctx, fmt.Sprintf("%03d", i/2))
+			}
+		}
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				snapshot := s.Snapshot()
+				count := 0
+				snapshot.Iterate(func(evt *nostr.Event) bool {
+					count++
+					return true
+				})
+				if count != snapshot.Len() {
+					t.Errorf("Expected %d events, iterated %d", snapshot.Len(), count)
+					return
+				}
+				_, _ = s.Query(ctx, nostr.Filter{Limit: 10})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMemoryStoreSubscribe(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	sub := s.Subscribe(2, func(evt *nostr.Event) bool { return evt.Kind == 1 })
+
+	for i := 0; i < 4; i++ {
+		if err := s.Publish(ctx, testEvent(fmt.Sprintf("%02d", i), 1, nostr.Timestamp(100+i))); err != nil {
+			t.Fatalf("Failed to publish event: %v", err)
+		}
+	}
+	if err := s.Publish(ctx, testEvent("04", 7, 104)); err != nil {
+		t.Fatalf("Failed to publish event: %v", err)
+	}
+
+	// Publishing does not wait for the subscriber, events past the buffer are dropped
+	if evt := <-sub.Events(); evt.ID != "00" {
+		t.Errorf("Expected event 00, got %s", evt.ID)
+	}
+	if evt := <-sub.Events(); evt.ID != "01" {
+		t.Errorf("Expected event 01, got %s", evt.ID)
+	}
+	if dropped := sub.Dropped(); dropped != 2 {
+		t.Errorf("Expected 2 dropped events, got %d", dropped)
+	}
+	if dropped := sub.Dropped(); dropped != 0 {
+		t.Errorf("Expected the dropped events to be reset, got %d", dropped)
+	}
+
+	if subscribers := s.Subscribers(); subscribers != 1 {
+		t.Errorf("Expected 1 subscriber, got %d", subscribers)
+	}
+
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.Events(); ok {
+		t.Error("Expected the channel to be closed")
+	}
+	if err := s.Publish(ctx, testEvent("05", 1, 105)); err != nil {
+		t.Fatalf("Failed to publish event after close: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package store keeps Nostr events locally, with read-optimized views so that the queries of
+// bridges and indexers do not scan every event
+package store
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// logEntry is the latest version of a transaction log in the view of an address
+type logEntry struct {
+	key       string // d tag of the tx log and transfer events of the log
+	eventID   string
+	createdAt nostr.Timestamp
+	log       neth.Log
+}
+
+// newer checks if an entry is newer than another, ties go to the lowest event ID as in NIP-01
+func (e *logEntry) newer(other *logEntry) bool {
+	if e.createdAt != other.createdAt {
+		return e.createdAt > other.createdAt
+	}
+	return e.eventID < other.eventID
+}
+
+// groupView is the moderation history and current state of a group
+type groupView struct {
+	events []*nostr.Event // Moderation events, oldest first
+	state  *event.GroupState
+}
+
+// Views are materialized projections of the events of a store, updated incrementally as events
+// are applied so that reading them does not depend on the number of events stored:
+//   - address → latest version of its transaction logs, newest first
+//   - group → current state, including its members
+//   - user op hash → latest user operation event
+type Views struct {
+	mu sync.RWMutex
+
+	logs    map[string][]*logEntry // Logs by lowercase address, newest first
+	groups  map[string]*groupView
+	userOps map[string]*nostr.Event // Latest user op event by hash
+}
+
+// NewViews creates new empty views
+func NewViews() *Views {
+	return &Views{
+		logs:    make(map[string][]*logEntry),
+		groups:  make(map[string]*groupView),
+		userOps: make(map[string]*nostr.Event),
+	}
+}
+
+// Apply updates the views with an event, events that no view projects are ignored. Events can be
+// applied in any order, the newest version of a log or user op wins.
+func (v *Views) Apply(evt *nostr.Event) error {
+	if evt == nil {
+		return nil
+	}
+
+	kind := event.DefaultKind(evt.Kind)
+	switch {
+	case kind == event.KindTxLog || event.IsTxTransferEvent(evt):
+		return v.applyLog(evt)
+	case kind >= event.KindGroupAddUser && kind <= event.KindGroupDelete:
+		return v.applyModeration(evt)
+	case kind == event.EventUserOpKind:
+		return v.applyUserOp(evt)
+	default:
+		return nil
+	}
+}
+
+// applyLog adds the log of a tx log or transfer event to the view of every address it involves
+func (v *Views) applyLog(evt *nostr.Event) error {
+	var log neth.Log
+	if event.IsTxTransferEvent(evt) {
+		transfer, err := event.ParseTxTransferEvent(evt)
+		if err != nil {
+			return err
+		}
+		log = transfer.LogData
+	} else {
+		txLog, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			return err
+		}
+		log = txLog.LogData
+	}
+
+	entry := &logEntry{key: evt.Tags.GetD(), eventID: evt.ID, createdAt: evt.CreatedAt, log: log}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 || (tag[0] != "p" && tag[0] != "P") {
+			continue
+		}
+		address := strings.ToLower(tag[1])
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+
+		v.logs[address] = insertLog(v.logs[address], entry)
+	}
+	return nil
+}
+
+// insertLog inserts an entry in a newest first list, replacing the older version of its log
+func insertLog(entries []*logEntry, entry *logEntry) []*logEntry {
+	for i, current := range entries {
+		if current.key != entry.key {
+			continue
+		}
+		if !entry.newer(current) {
+			return entries
+		}
+		entries = append(entries[:i], entries[i+1:]...)
+		break
+	}
+
+	i := sort.Search(len(entries), func(i int) bool {
+		return entry.newer(entries[i])
+	})
+	entries = append(entries, nil)
+	copy(entries[i+1:], entries[i:])
+	entries[i] = entry
+	return entries
+}
+
+// applyModeration applies a moderation event to the state of its group, the state is recomputed
+// from the history when the event is older than the last one applied
+func (v *Views) applyModeration(evt *nostr.Event) error {
+	groupID, err := event.GetGroupIDFromEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	group, ok := v.groups[groupID]
+	if !ok {
+		group = &groupView{}
+	}
+
+	stored := *evt
+	last := len(group.events) - 1
+	if group.state != nil && evt.CreatedAt >= group.events[last].CreatedAt {
+		if err := group.state.Apply(&stored); err != nil {
+			return err
+		}
+		group.events = append(group.events, &stored)
+		v.groups[groupID] = group
+		return nil
+	}
+
+	events := append(append([]*nostr.Event(nil), group.events...), &stored)
+	state, err := event.ComputeGroupState(events)
+	if err != nil {
+		return err
+	}
+	event.SortEventsByCreatedAt(events, false)
+
+	v.groups[groupID] = &groupView{events: events, state: state}
+	return nil
+}
+
+// applyUserOp keeps the latest event of a user operation
+func (v *Views) applyUserOp(evt *nostr.Event) error {
+	if _, err := event.ParseUserOpEvent(evt); err != nil {
+		return err
+	}
+
+	hash := strings.ToLower(evt.Tags.GetD())
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if current, ok := v.userOps[hash]; ok {
+		if current.CreatedAt > evt.CreatedAt || (current.CreatedAt == evt.CreatedAt && current.ID <= evt.ID) {
+			return nil
+		}
+	}
+	stored := *evt
+	v.userOps[hash] = &stored
+	return nil
+}
+
+// LatestLogs returns the latest version of the transaction logs involving an address, newest
+// first, as event.LatestLogsForAddress does over every event. A limit of 0 returns all of them.
+func (v *Views) LatestLogs(address string, limit int) []neth.Log {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	entries := v.logs[strings.ToLower(address)]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	logs := make([]neth.Log, len(entries))
+	for i, entry := range entries {
+		logs[i] = entry.log
+	}
+	return logs
+}
+
+// Group returns a copy of the current state of a group
+func (v *Views) Group(groupID string) (*event.GroupState, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	group, ok := v.groups[groupID]
+	if !ok {
+		return nil, false
+	}
+
+	state := *group.state
+	state.Members = make(map[string]string, len(group.state.Members))
+	for pubkey, role := range group.state.Members {
+		state.Members[pubkey] = role
+	}
+	state.Admins = make(map[string]bool, len(group.state.Admins))
+	for pubkey := range group.state.Admins {
+		state.Admins[pubkey] = true
+	}
+	state.Moderators = make(map[string]bool, len(group.state.Moderators))
+	for pubkey := range group.state.Moderators {
+		state.Moderators[pubkey] = true
+	}
+	state.DeletedEvents = append([]string(nil), group.state.DeletedEvents...)
+	return &state, true
+}
+
+// GroupMembers returns the members of a group by pubkey with their role
+func (v *Views) GroupMembers(groupID string) map[string]string {
+	state, ok := v.Group(groupID)
+	if !ok {
+		return map[string]string{}
+	}
+	return state.Members
+}
+
+// UserOp returns the latest event of a user operation by hash and its parsed content
+func (v *Views) UserOp(hash string) (*nostr.Event, *event.UserOpEvent, bool) {
+	v.mu.RLock()
+	evt, ok := v.userOps[strings.ToLower(hash)]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil, nil, false
+	}
+
+	stored := *evt
+	userOp, err := event.ParseUserOpEvent(&stored)
+	if err != nil {
+		return nil, nil, false
+	}
+	return &stored, userOp, true
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	token  = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+	alice  = "0x1111111111111111111111111111111111111111"
+	bob    = "0x2222222222222222222222222222222222222222"
+	carol  = "0x3333333333333333333333333333333333333333"
+	chainA = "100"
+)
+
+func testTransfer(hash, from, to string, value int64) neth.Log {
+	data := json.RawMessage(fmt.Sprintf(`{"from":%q,"to":%q,"value":"%d"}`, from, to, value))
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   chainA,
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		To:        token,
+		Value:     big.NewInt(0),
+		Data:      &data,
+	}
+}
+
+func testUserOp() neth.UserOp {
+	return neth.UserOp{
+		Sender:               common.HexToAddress(alice),
+		Nonce:                big.NewInt(1),
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		PreVerificationGas:   big.NewInt(21000),
+		MaxFeePerGas:         big.NewInt(1000000000),
+		MaxPriorityFeePerGas: big.NewInt(1000000),
+	}
+}
+
+func TestViewsLatestLogs(t *testing.T) {
+	first, _ := event.CreateTxTransferEvent(testTransfer("0x01", alice, bob, 10))
+	first.ID, first.CreatedAt = "01", 100
+	second, _ := event.CreateTxLogEvent(testTransfer("0x02", bob, carol, 20))
+	second.ID, second.CreatedAt = "02", 200
+	confirmed, err := event.UpdateTxLogStatus(second, event.TxLogStatusConfirmed)
+	if err != nil {
+		t.Fatalf("Failed to update log status: %v", err)
+	}
+	confirmed.ID, confirmed.CreatedAt = "03", 300
+
+	views := NewViews()
+
+	// Out of order on purpose, the newest version of each log wins
+	events := []*nostr.Event{confirmed, first, second}
+	for _, evt := range events {
+		if err := views.Apply(evt); err != nil {
+			t.Fatalf("Failed to apply event: %v", err)
+		}
+	}
+
+	logs := views.LatestLogs(bob, 0)
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+	if logs[0].Hash != "0x02" {
+		t.Errorf("Expected log 0x02 first, got %s", logs[0].Hash)
+	}
+	if logs[1].Hash != "0x01" {
+		t.Errorf("Expected log 0x01 second, got %s", logs[1].Hash)
+	}
+
+	// The view matches the scan of every event
+	scanned, err := event.LatestLogsForAddress(events, bob)
+	if err != nil {
+		t.Fatalf("Failed to scan logs: %v", err)
+	}
+	for i := range scanned {
+		if scanned[i].Hash != logs[i].Hash {
+			t.Errorf("Expected log %d to be %s, got %s", i, scanned[i].Hash, logs[i].Hash)
+		}
+	}
+
+	if logs := views.LatestLogs(bob, 1); len(logs) != 1 || logs[0].Hash != "0x02" {
+		t.Errorf("Expected the latest log only, got %v", logs)
+	}
+	if logs := views.LatestLogs("0x4444444444444444444444444444444444444444", 0); len(logs) != 0 {
+		t.Errorf("Expected no logs, got %d", len(logs))
+	}
+}
+
+func TestViewsGroupMembers(t *testing.T) {
+	groupID := "test-group"
+
+	create, _ := event.CreateGroupEvent(groupID, "Test Group", "", "", []string{"admin1"}, nil, false, false)
+	create.ID, create.PubKey, create.CreatedAt = "01", "creator", 100
+	addUser, _ := event.CreateAddUserEvent(groupID, "user1", "")
+	addUser.ID, addUser.PubKey, addUser.CreatedAt = "02", "admin1", 200
+	removeUser, _ := event.CreateRemoveUserEvent(groupID, "user1", "inactive")
+	removeUser.ID, removeUser.PubKey, removeUser.CreatedAt = "03", "admin1", 300
+	addOther, _ := event.CreateAddUserEvent(groupID, "user2", "")
+	addOther.ID, addOther.PubKey, addOther.CreatedAt = "04", "admin1", 400
+
+	views := NewViews()
+	for _, evt := range []*nostr.Event{create, addUser, addOther} {
+		if err := views.Apply(evt); err != nil {
+			t.Fatalf("Failed to apply event: %v", err)
+		}
+	}
+
+	members := views.GroupMembers(groupID)
+	if _, ok := members["user1"]; !ok {
+		t.Errorf("Expected user1 to be a member, got %v", members)
+	}
+
+	// A late event is folded in its place in the history
+	if err := views.Apply(removeUser); err != nil {
+		t.Fatalf("Failed to apply event: %v", err)
+	}
+
+	members = views.GroupMembers(groupID)
+	if _, ok := members["user1"]; ok {
+		t.Errorf("Expected user1 to have been removed, got %v", members)
+	}
+	if members["user2"] != "member" || members["admin1"] != "admin" {
+		t.Errorf("Expected admin1 and user2 to be members, got %v", members)
+	}
+
+	// Copies do not change the view
+	members["user3"] = "member"
+	if _, ok := views.GroupMembers(groupID)["user3"]; ok {
+		t.Error("Expected the view not to be changed by its copies")
+	}
+
+	if members := views.GroupMembers("unknown"); len(members) != 0 {
+		t.Errorf("Expected no members, got %v", members)
+	}
+}
+
+func TestViewsUserOp(t *testing.T) {
+	chainID := big.NewInt(100)
+	userOp := testUserOp()
+
+	requested, err := event.CreateUserOpEvent(chainID, nil, nil, nil, nil, 0, userOp, event.EventTypeUserOpRequested)
+	if err != nil {
+		t.Fatalf("Failed to create user op event: %v", err)
+	}
+	requested.ID, requested.CreatedAt = "01", 100
+
+	txHash := "0xdef"
+	executed, err := event.UpdateUserOpEvent(chainID, userOp, &txHash, 0, event.EventTypeUserOpExecuted, requested)
+	if err != nil {
+		t.Fatalf("Failed to update user op event: %v", err)
+	}
+	executed.ID, executed.CreatedAt = "02", 200
+
+	views := NewViews()
+	for _, evt := range []*nostr.Event{executed, requested} {
+		if err := views.Apply(evt); err != nil {
+			t.Fatalf("Failed to apply event: %v", err)
+		}
+	}
+
+	evt, state, ok := views.UserOp(userOp.GetHash(chainID))
+	if !ok {
+		t.Fatal("Expected the user op to be found")
+	}
+	if evt.ID != "02" || state.EventType != event.EventTypeUserOpExecuted {
+		t.Errorf("Expected the executed event, got %s %s", evt.ID, state.EventType)
+	}
+
+	if _, _, ok := views.UserOp("0x00"); ok {
+		t.Error("Expected an unknown user op not to be found")
+	}
+}
@@ -0,0 +1,158 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/comunifi/nostr-eth/pkg/service"
+)
+
+// maxConfigSize is the maximum size of the body of a tenant request
+const maxConfigSize = 1 << 16
+
+// Handler serves the Tenants API and the REST APIs of the tenants:
+//
+//	GET    /tenants             tenants, see Info
+//	POST   /tenants             create a tenant from a Config
+//	GET    /tenants/{id}        a tenant
+//	PUT    /tenants/{id}        replace the config of a tenant
+//	DELETE /tenants/{id}        delete a tenant and its store
+//	       /tenants/{id}/api/   the REST API of a tenant, see service.HTTPHandler
+//
+// The management endpoints require NIP-98 auth when an authenticator is given, the APIs of the
+// tenants are protected by their own publishers.
+type Handler struct {
+	registry *Registry
+	mux      *http.ServeMux
+}
+
+// NewHandler creates a new handler of the tenants of a registry, auth may be nil
+func NewHandler(registry *Registry, auth *service.Authenticator) *Handler {
+	h := &Handler{registry: registry, mux: http.NewServeMux()}
+
+	admin := func(handler http.HandlerFunc) http.Handler {
+		if auth == nil {
+			return handler
+		}
+		return auth.Handler(handler)
+	}
+
+	h.mux.Handle("GET /tenants", admin(h.list))
+	h.mux.Handle("POST /tenants", admin(h.create))
+	h.mux.Handle("GET /tenants/{id}", admin(h.get))
+	h.mux.Handle("PUT /tenants/{id}", admin(h.update))
+	h.mux.Handle("DELETE /tenants/{id}", admin(h.delete))
+	h.mux.HandleFunc("/tenants/{id}/api/", h.api)
+
+	return h
+}
+
+// ServeHTTP routes a request to its endpoint
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.registry.List())
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	config, ok := readConfig(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.registry.Create(r.Context(), config)
+	if err != nil {
+		writeError(w, errorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, t.Info())
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.registry.Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrNotFound.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t.Info())
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	config, ok := readConfig(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := h.registry.Update(r.Context(), r.PathValue("id"), config)
+	if err != nil {
+		writeError(w, errorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t.Info())
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.registry.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, errorStatus(err), err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// api forwards a request to the REST API of a tenant
+func (h *Handler) api(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	t, ok := h.registry.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrNotFound.Error())
+		return
+	}
+
+	http.StripPrefix("/tenants/"+id+"/api", t.Handler()).ServeHTTP(w, r)
+}
+
+// readConfig reads the config of a request, an error is written when it is invalid
+func readConfig(w http.ResponseWriter, r *http.Request) (Config, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxConfigSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return Config{}, false
+	}
+
+	var config Config
+	if err := json.Unmarshal(body, &config); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tenant: "+err.Error())
+		return Config{}, false
+	}
+	return config, true
+}
+
+// errorStatus returns the HTTP status of a registry error
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalid):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON writes a value as a JSON response
+func writeJSON(w http.ResponseWriter, code int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
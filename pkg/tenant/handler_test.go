@@ -0,0 +1,161 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// acceptingPublisher accepts every event on every relay
+type acceptingPublisher struct{}
+
+func (acceptingPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	results := make([]*pb.RelayResult, 0, len(relays))
+	for _, relay := range relays {
+		results = append(results, &pb.RelayResult{Relay: relay, Ok: true})
+	}
+	return results
+}
+
+func TestHandler(t *testing.T) {
+	adminKey := nostr.GeneratePrivateKey()
+	adminPubkey, _ := nostr.GetPublicKey(adminKey)
+	publisherKey := nostr.GeneratePrivateKey()
+	publisherPubkey, _ := nostr.GetPublicKey(publisherKey)
+
+	registry := NewRegistry(acceptingPublisher{})
+	if err := registry.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start registry: %v", err)
+	}
+	defer registry.Stop()
+
+	server := httptest.NewServer(NewHandler(registry, service.NewAuthenticator(service.WithAuthPubkeys(adminPubkey))))
+	defer server.Close()
+
+	do := func(key, method, path string, body any, out any) int {
+		t.Helper()
+
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, bytes.NewReader(data))
+		if key != "" {
+			header, err := service.CreateAuthHeader(key, server.URL+path, method, data)
+			if err != nil {
+				t.Fatalf("Failed to sign request: %v", err)
+			}
+			req.Header.Set("Authorization", header)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if out != nil && resp.StatusCode < 300 {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	config := testConfig("a")
+	config.Publishers = []string{publisherPubkey}
+
+	if code := do("", http.MethodPost, "/tenants", config, nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without auth, got %d", code)
+	}
+	if code := do(publisherKey, http.MethodPost, "/tenants", config, nil); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a publisher, got %d", code)
+	}
+
+	var info map[string]any
+	if code := do(adminKey, http.MethodPost, "/tenants", config, &info); code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+	if info["id"] != "a" || info["pubkey"] == "" {
+		t.Errorf("Expected tenant a with a pubkey, got %v", info)
+	}
+	if _, ok := info["private_key"]; ok {
+		t.Error("Expected the private key to be left out")
+	}
+
+	// Auth events are accepted once, so the request differs from the first one
+	config.Relays = append(config.Relays, "wss://other.example.com")
+	if code := do(adminKey, http.MethodPost, "/tenants", config, nil); code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", code)
+	}
+	if code := do(adminKey, http.MethodPost, "/tenants", Config{ID: "b"}, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+
+	var infos []Info
+	if code := do(adminKey, http.MethodGet, "/tenants", nil, &infos); code != http.StatusOK || len(infos) != 1 {
+		t.Errorf("Expected tenant a, got %d %v", code, infos)
+	}
+
+	// The API of a tenant serves its own store
+	a, _ := registry.Get("a")
+	if err := a.Store().Publish(context.Background(), nostr.Event{ID: "01", Kind: 1}); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+	var events []nostr.Event
+	if code := do("", http.MethodGet, "/tenants/a/api/events?q=kind=1", nil, &events); code != http.StatusOK || len(events) != 1 {
+		t.Errorf("Expected the event of tenant a, got %d %v", code, events)
+	}
+	if code := do("", http.MethodGet, "/tenants/b/api/events?q=kind=1", nil, nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+
+	// Publishing to a tenant is signed by its publishers, for the URL under the tenant
+	publish := service.PublishRequest{Event: nostr.Event{Kind: 1, Content: "hello"}}
+	if code := do("", http.MethodPost, "/tenants/a/api/publish", publish, nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without auth, got %d", code)
+	}
+	if code := do(adminKey, http.MethodPost, "/tenants/a/api/publish", publish, nil); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for the admin, got %d", code)
+	}
+	var published service.PublishResponse
+	if code := do(publisherKey, http.MethodPost, "/tenants/a/api/publish", publish, &published); code != http.StatusOK {
+		t.Errorf("Expected status 200 for a publisher, got %d", code)
+	}
+	if published.Event.PubKey != info["pubkey"] {
+		t.Errorf("Expected the event to be signed by the tenant %v, got %s", info["pubkey"], published.Event.PubKey)
+	}
+
+	update := Config{Relays: []string{"wss://other.example.com"}}
+	if code := do(adminKey, http.MethodPut, "/tenants/a", update, &info); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if relays := info["relays"].([]any); len(relays) != 1 || !strings.Contains(relays[0].(string), "other") {
+		t.Errorf("Expected the relays to be replaced, got %v", info["relays"])
+	}
+
+	// Without publishers, the API of the tenant publishes nothing
+	if code := do("", http.MethodPost, "/tenants/a/api/publish", publish, nil); code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for an unsigned event without publishers, got %d", code)
+	}
+	signed := nostr.Event{Kind: 1, Content: "hello", CreatedAt: nostr.Now()}
+	signed.Sign(nostr.GeneratePrivateKey())
+	if code := do("", http.MethodPost, "/tenants/a/api/publish", service.PublishRequest{Event: signed}, nil); code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 for a signed event without publishers, got %d", code)
+	}
+
+	if code := do(adminKey, http.MethodDelete, "/tenants/a", nil, nil); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if code := do(adminKey, http.MethodGet, "/tenants/a", nil, nil); code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+}
@@ -0,0 +1,484 @@
+// Package tenant lets one bridge deployment serve many communities. Each tenant has its own
+// signer, relays, watched contracts and event store, and its own REST API.
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/comunifi/nostr-eth/pkg/store"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// CheckpointKey is the key the tenants are saved under in a state store
+const CheckpointKey = "tenants"
+
+// Tenant errors
+var (
+	ErrNotFound = errors.New("tenant not found")
+	ErrExists   = errors.New("tenant already exists")
+	ErrInvalid  = errors.New("invalid tenant")
+)
+
+// Config is what a tenant is created with
+type Config struct {
+	ID         string   `json:"id"`                    // Letters, digits, hyphens and underscores
+	PrivateKey string   `json:"private_key,omitempty"` // Signs the events of the tenant
+	Relays     []string `json:"relays"`                // Relays the events are published to and read from
+	Contracts  []string `json:"contracts,omitempty"`   // Contracts watched for the tenant
+
+	// Pubkeys allowed to publish through the API of the tenant with NIP-98 auth, and to have their
+	// unsigned events signed with its key. When empty, the API of the tenant publishes nothing.
+	Publishers []string `json:"publishers,omitempty"`
+}
+
+// validate checks a config and returns the public key of its signer
+func (c Config) validate() (string, error) {
+	if c.ID == "" {
+		return "", fmt.Errorf("%w: tenant ID cannot be empty", ErrInvalid)
+	}
+	for _, char := range c.ID {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return "", fmt.Errorf("%w: tenant ID contains invalid character: %c", ErrInvalid, char)
+		}
+	}
+
+	pubkey, err := nostr.GetPublicKey(c.PrivateKey)
+	if err != nil || c.PrivateKey == "" {
+		return "", fmt.Errorf("%w: invalid private key", ErrInvalid)
+	}
+
+	if len(c.Relays) == 0 {
+		return "", fmt.Errorf("%w: at least one relay is required", ErrInvalid)
+	}
+	for _, relay := range c.Relays {
+		if !nostr.IsValidRelayURL(relay) {
+			return "", fmt.Errorf("%w: invalid relay: %s", ErrInvalid, relay)
+		}
+	}
+
+	for _, contract := range c.Contracts {
+		if !common.IsHexAddress(contract) {
+			return "", fmt.Errorf("%w: invalid contract: %s", ErrInvalid, contract)
+		}
+	}
+	return pubkey, nil
+}
+
+// Info describes a tenant without its private key
+type Info struct {
+	ID         string   `json:"id"`
+	PubKey     string   `json:"pubkey"`
+	Relays     []string `json:"relays"`
+	Contracts  []string `json:"contracts"`
+	Publishers []string `json:"publishers,omitempty"`
+}
+
+// Tenant is a community served by the bridge. Its store and watched contracts are kept when its
+// config is updated.
+type Tenant struct {
+	id            string
+	store         *store.MemoryStore
+	subscriptions *watcher.SubscriptionManager
+
+	mu      sync.RWMutex
+	config  Config
+	pubkey  string
+	server  *service.Server
+	handler *service.HTTPHandler
+	cancel  context.CancelFunc // Stops the runner of the tenant
+}
+
+// ID returns the ID of the tenant
+func (t *Tenant) ID() string {
+	return t.id
+}
+
+// Store returns the event store of the tenant
+func (t *Tenant) Store() *store.MemoryStore {
+	return t.store
+}
+
+// Subscriptions returns the contracts watched for the tenant, to be given to its watchers with
+// watcher.WithSubscriptions
+func (t *Tenant) Subscriptions() *watcher.SubscriptionManager {
+	return t.subscriptions
+}
+
+// Relays returns the relays of the tenant
+func (t *Tenant) Relays() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return append([]string(nil), t.config.Relays...)
+}
+
+// Server returns the gRPC service of the tenant, signing with its key and publishing to its
+// relays, publishing is disabled when the tenant has no publishers
+func (t *Tenant) Server() *service.Server {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.server
+}
+
+// Handler returns the REST API of the tenant
+func (t *Tenant) Handler() *service.HTTPHandler {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.handler
+}
+
+// Info describes the tenant
+func (t *Tenant) Info() Info {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return Info{
+		ID:         t.id,
+		PubKey:     t.pubkey,
+		Relays:     append([]string(nil), t.config.Relays...),
+		Contracts:  t.subscriptions.Addresses(),
+		Publishers: append([]string(nil), t.config.Publishers...),
+	}
+}
+
+// Registry holds the tenants of a deployment. Tenants are saved to a state store when one is
+// given, and a runner, e.g. ingesting the relays of a tenant into its store, and a watcher of its
+// contracts run for every tenant while the registry is started.
+type Registry struct {
+	publisher   service.Publisher
+	httpOptions []service.HTTPOption
+	runner      func(ctx context.Context, t *Tenant)
+	state       state.Store
+
+	client         watcher.ChainClient
+	chainID        string
+	watcherOptions []watcher.Option
+
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+	ctx     context.Context // Context of the runners, nil until started
+	cancel  context.CancelFunc
+}
+
+// Option configures a Registry
+type Option func(*Registry)
+
+// WithHTTPOptions sets the options of the REST APIs of the tenants
+func WithHTTPOptions(opts ...service.HTTPOption) Option {
+	return func(r *Registry) {
+		r.httpOptions = opts
+	}
+}
+
+// WithRunner runs a function for every tenant while the registry is started, its context is
+// canceled when the tenant is updated or deleted and when the registry stops
+func WithRunner(runner func(ctx context.Context, t *Tenant)) Option {
+	return func(r *Registry) {
+		r.runner = runner
+	}
+}
+
+// WithWatcher runs a watcher of the contracts of every tenant on a chain while the registry is
+// started, its events are signed with the key of the tenant and published to its relays. The
+// options must not include a checkpoint store, which would be shared by the tenants.
+func WithWatcher(client watcher.ChainClient, chainID string, opts ...watcher.Option) Option {
+	return func(r *Registry) {
+		r.client = client
+		r.chainID = chainID
+		r.watcherOptions = opts
+	}
+}
+
+// WithStateStore saves the tenants to a state store under CheckpointKey, including their
+// private keys, and restores them when the registry starts
+func WithStateStore(store state.Store) Option {
+	return func(r *Registry) {
+		r.state = store
+	}
+}
+
+// NewRegistry creates a new registry of tenants publishing through a publisher
+func NewRegistry(publisher service.Publisher, opts ...Option) *Registry {
+	r := &Registry{publisher: publisher, tenants: make(map[string]*Tenant)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start restores the saved tenants and starts the runners, until Stop is called or the context
+// is done
+func (r *Registry) Start(ctx context.Context) error {
+	if r.state != nil {
+		checkpoint, err := r.state.Load(ctx, CheckpointKey)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return err
+		}
+		if checkpoint != nil {
+			var configs []Config
+			if err := json.Unmarshal(checkpoint.Data, &configs); err != nil {
+				return fmt.Errorf("invalid saved tenants: %w", err)
+			}
+			for _, config := range configs {
+				if _, err := r.add(config); err != nil {
+					return fmt.Errorf("invalid saved tenant %s: %w", config.ID, err)
+				}
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	for _, t := range r.tenants {
+		r.run(t)
+	}
+	return nil
+}
+
+// Stop stops the runners
+func (r *Registry) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		r.ctx, r.cancel = nil, nil
+	}
+}
+
+// Create adds a tenant and saves the tenants, the tenant is removed again when they cannot be
+// saved
+func (r *Registry) Create(ctx context.Context, config Config) (*Tenant, error) {
+	t, err := r.add(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.save(ctx); err != nil {
+		r.mu.Lock()
+		if r.tenants[config.ID] == t {
+			delete(r.tenants, config.ID)
+		}
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.tenants[config.ID] == t {
+		r.run(t)
+	}
+	r.mu.Unlock()
+
+	return t, nil
+}
+
+// add adds a tenant without running it
+func (r *Registry) add(config Config) (*Tenant, error) {
+	pubkey, err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tenant{id: config.ID, store: store.NewMemoryStore(), subscriptions: watcher.NewSubscriptionManager()}
+	t.subscriptions.AddAddresses(config.Contracts...)
+	r.configure(t, config, pubkey)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[config.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrExists, config.ID)
+	}
+	r.tenants[config.ID] = t
+	return t, nil
+}
+
+// configure builds the signer and the API of a tenant from its config, the API of a tenant
+// without publishers does not publish
+func (r *Registry) configure(t *Tenant, config Config, pubkey string) {
+	var publisher service.Publisher
+	opts := append([]service.HTTPOption(nil), r.httpOptions...)
+	if len(config.Publishers) > 0 {
+		publisher = r.publisher
+		opts = append(opts, service.WithAuth(service.NewAuthenticator(service.WithAuthPubkeys(config.Publishers...))))
+	}
+
+	server := service.NewServer(config.PrivateKey, config.Relays, publisher)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.config = config
+	t.pubkey = pubkey
+	t.server = server
+	t.handler = service.NewHTTPHandler(server, t.store, opts...)
+}
+
+// run starts the runner and the watcher of a tenant if the registry is started, the registry is
+// locked
+func (r *Registry) run(t *Tenant) {
+	if (r.runner == nil && r.client == nil) || r.ctx == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+
+	t.mu.Lock()
+	t.cancel = cancel
+	privateKey := t.config.PrivateKey
+	t.mu.Unlock()
+
+	if r.runner != nil {
+		go r.runner(ctx, t)
+	}
+	// Without contracts, the filter of the watcher would match the logs of every contract
+	if r.client != nil && len(t.subscriptions.Addresses()) > 0 {
+		opts := append(append([]watcher.Option(nil), r.watcherOptions...), watcher.WithSubscriptions(t.subscriptions))
+		go watcher.New(r.client, tenantSink{publisher: r.publisher, tenant: t}, r.chainID, privateKey, opts...).Run(ctx)
+	}
+}
+
+// tenantSink publishes the events of the watcher of a tenant to its relays
+type tenantSink struct {
+	publisher service.Publisher
+	tenant    *Tenant
+}
+
+// Send publishes an event signed by the watcher, it fails when no relay accepted it
+func (s tenantSink) Send(ctx context.Context, evt *nostr.Event) error {
+	if s.publisher == nil {
+		return fmt.Errorf("tenant %s has no publisher", s.tenant.id)
+	}
+
+	results := s.publisher.Publish(ctx, s.tenant.Relays(), *evt)
+	if len(service.NewPublishResult(evt.ID, results).Accepted()) == 0 {
+		return fmt.Errorf("no relay of tenant %s accepted %s", s.tenant.id, evt.ID)
+	}
+	return nil
+}
+
+// stop stops the runner of a tenant
+func (t *Tenant) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+}
+
+// Get returns a tenant by ID
+func (r *Registry) Get(id string) (*Tenant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[id]
+	return t, ok
+}
+
+// List describes the tenants, by ID
+func (r *Registry) List() []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		infos = append(infos, t.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Update replaces the config of a tenant, keeping its store, and restarts its runner. The
+// private key is kept when the new config has none, and the watched contracts are replaced.
+func (r *Registry) Update(ctx context.Context, id string, config Config) (*Tenant, error) {
+	t, ok := r.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	config.ID = id
+	if config.PrivateKey == "" {
+		t.mu.RLock()
+		config.PrivateKey = t.config.PrivateKey
+		t.mu.RUnlock()
+	}
+
+	pubkey, err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	t.stop()
+	r.configure(t, config, pubkey)
+	t.subscriptions.RemoveAddresses(t.subscriptions.Addresses()...)
+	t.subscriptions.AddAddresses(config.Contracts...)
+
+	r.mu.Lock()
+	if r.tenants[id] == t {
+		r.run(t)
+	}
+	r.mu.Unlock()
+
+	if err := r.save(ctx); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Delete removes a tenant and its store, and saves the tenants
+func (r *Registry) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	t, ok := r.tenants[id]
+	delete(r.tenants, id)
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	t.stop()
+	return r.save(ctx)
+}
+
+// save saves the configs of the tenants to the state store, if any
+func (r *Registry) save(ctx context.Context) error {
+	if r.state == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	configs := make([]Config, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		t.mu.RLock()
+		configs = append(configs, t.config)
+		t.mu.RUnlock()
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(configs, func(i, j int) bool { return configs[i].ID < configs[j].ID })
+
+	data, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	return r.state.Save(ctx, state.Checkpoint{Key: CheckpointKey, Data: data, UpdatedAt: time.Now()})
+}
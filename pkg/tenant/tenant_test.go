@@ -0,0 +1,219 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pb"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/comunifi/nostr-eth/pkg/testutil"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func testConfig(id string) Config {
+	return Config{
+		ID:         id,
+		PrivateKey: nostr.GeneratePrivateKey(),
+		Relays:     []string{"wss://relay.example.com"},
+		Contracts:  []string{"0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"},
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	checkpoints := state.NewMemoryStore()
+
+	started := make(chan string, 10)
+	runner := func(ctx context.Context, t *Tenant) {
+		started <- t.ID()
+		<-ctx.Done()
+		started <- "stopped " + t.ID()
+	}
+
+	registry := NewRegistry(nil, WithStateStore(checkpoints), WithRunner(runner))
+	if err := registry.Start(ctx); err != nil {
+		t.Fatalf("Failed to start registry: %v", err)
+	}
+	defer registry.Stop()
+
+	a, err := registry.Create(ctx, testConfig("a"))
+	if err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+	if _, err := registry.Create(ctx, testConfig("b")); err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+	expectRuns(t, started, "a", "b")
+
+	if _, err := registry.Create(ctx, testConfig("a")); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected tenant a to exist, got %v", err)
+	}
+	for _, config := range []Config{{ID: "bad id"}, {ID: "c"}, {ID: "c", PrivateKey: nostr.GeneratePrivateKey()}} {
+		if _, err := registry.Create(ctx, config); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected %+v to be invalid, got %v", config, err)
+		}
+	}
+
+	// Stores are isolated
+	if err := a.Store().Publish(ctx, nostr.Event{ID: "01", Kind: 1}); err != nil {
+		t.Fatalf("Failed to store event: %v", err)
+	}
+	b, _ := registry.Get("b")
+	if a.Store().Snapshot().Len() != 1 || b.Store().Snapshot().Len() != 0 {
+		t.Error("Expected the event in the store of tenant a only")
+	}
+
+	infos := registry.List()
+	if len(infos) != 2 || infos[0].ID != "a" || infos[1].ID != "b" {
+		t.Fatalf("Expected tenants a and b, got %v", infos)
+	}
+	if len(infos[0].Contracts) != 1 || infos[0].PubKey == "" {
+		t.Errorf("Expected a tenant with a pubkey and a contract, got %+v", infos[0])
+	}
+
+	// Updates keep the key and the store, and restart the runner
+	update := Config{Relays: []string{"wss://other.example.com"}}
+	if _, err := registry.Update(ctx, "a", update); err != nil {
+		t.Fatalf("Failed to update tenant: %v", err)
+	}
+	expectRuns(t, started, "stopped a", "a")
+
+	info := a.Info()
+	if info.PubKey != infos[0].PubKey || len(info.Contracts) != 0 || info.Relays[0] != "wss://other.example.com" {
+		t.Errorf("Expected the relays and contracts of tenant a to be replaced, got %+v", info)
+	}
+	if a.Store().Snapshot().Len() != 1 {
+		t.Error("Expected the store of tenant a to be kept")
+	}
+
+	if err := registry.Delete(ctx, "b"); err != nil {
+		t.Fatalf("Failed to delete tenant: %v", err)
+	}
+	expectRuns(t, started, "stopped b")
+	if err := registry.Delete(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected tenant b to be deleted, got %v", err)
+	}
+
+	// Another registry restores the saved tenants
+	restored := NewRegistry(nil, WithStateStore(checkpoints))
+	if err := restored.Start(ctx); err != nil {
+		t.Fatalf("Failed to start registry: %v", err)
+	}
+	defer restored.Stop()
+
+	if infos := restored.List(); len(infos) != 1 || infos[0].PubKey != info.PubKey || infos[0].Relays[0] != "wss://other.example.com" {
+		t.Errorf("Expected tenant a to be restored, got %v", infos)
+	}
+}
+
+// expectRuns waits for the runners to report, in any order
+func expectRuns(t *testing.T, started chan string, expected ...string) {
+	t.Helper()
+
+	got := make(map[string]bool)
+	for range expected {
+		select {
+		case run := <-started:
+			got[run] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %q, got %v", expected, got)
+		}
+	}
+	for _, run := range expected {
+		if !got[run] {
+			t.Errorf("Expected %q, got %v", run, got)
+		}
+	}
+}
+
+// failingStore fails to save
+type failingStore struct {
+	state.Store
+}
+
+func (failingStore) Save(ctx context.Context, checkpoint state.Checkpoint) error {
+	return errors.New("disk full")
+}
+
+func TestRegistryCreateNotSaved(t *testing.T) {
+	ctx := context.Background()
+
+	started := make(chan string, 10)
+	runner := func(ctx context.Context, t *Tenant) {
+		started <- t.ID()
+	}
+
+	registry := NewRegistry(nil, WithStateStore(failingStore{state.NewMemoryStore()}), WithRunner(runner))
+	if err := registry.Start(ctx); err != nil {
+		t.Fatalf("Failed to start registry: %v", err)
+	}
+	defer registry.Stop()
+
+	if _, err := registry.Create(ctx, testConfig("a")); err == nil {
+		t.Fatal("Expected an error when the tenants cannot be saved")
+	}
+	if _, ok := registry.Get("a"); ok {
+		t.Error("Expected a tenant that was not saved to be removed")
+	}
+
+	select {
+	case id := <-started:
+		t.Errorf("Expected no runner for a tenant that was not saved, got %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// channelPublisher hands the published events to a channel and accepts them on every relay
+type channelPublisher chan nostr.Event
+
+func (p channelPublisher) Publish(ctx context.Context, relays []string, evt nostr.Event) []*pb.RelayResult {
+	p <- evt
+	return acceptingPublisher{}.Publish(ctx, relays, evt)
+}
+
+func TestRegistryWatcher(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chain := testutil.NewChain("100")
+	published := make(channelPublisher, 10)
+
+	registry := NewRegistry(published, WithWatcher(chain, chain.ChainID(), watcher.WithPollInterval(10*time.Millisecond)))
+	if err := registry.Start(ctx); err != nil {
+		t.Fatalf("Failed to start registry: %v", err)
+	}
+	defer registry.Stop()
+
+	config := testConfig("a")
+	a, err := registry.Create(ctx, config)
+	if err != nil {
+		t.Fatalf("Failed to create tenant: %v", err)
+	}
+
+	// Only the contracts of the tenant are watched
+	chain.Mine(chain.Transfer("0x00000000000000000000000000000000000000aa",
+		"0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222", big.NewInt(1)))
+	chain.Mine(chain.Transfer(config.Contracts[0],
+		"0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222", big.NewInt(1000)))
+
+	select {
+	case evt := <-published:
+		txLogEvent, err := event.ParseTxLogEvent(&evt)
+		if err != nil {
+			t.Fatalf("Failed to parse tx log event: %v", err)
+		}
+		if txLogEvent.LogData.To != config.Contracts[0] {
+			t.Errorf("Expected a log of %s, got %s", config.Contracts[0], txLogEvent.LogData.To)
+		}
+		if evt.PubKey != a.Info().PubKey {
+			t.Errorf("Expected the event to be signed by the tenant %s, got %s", a.Info().PubKey, evt.PubKey)
+		}
+	case <-ctx.Done():
+		t.Fatal("Expected the log of the contract of the tenant to be published")
+	}
+}
@@ -0,0 +1,167 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Chain is a simulated chain implementing watcher.ChainClient. Blocks are mined on demand and
+// reorgs replace the blocks above a height with a new fork.
+type Chain struct {
+	mu sync.Mutex
+
+	chainID string
+	blocks  []watcher.Block
+	logs    map[string][]watcher.ChainLog // By block hash
+	fork    int
+	nonce   int64
+	genesis time.Time
+}
+
+// NewChain creates a new chain with a genesis block
+func NewChain(chainID string) *Chain {
+	c := &Chain{
+		chainID: chainID,
+		logs:    make(map[string][]watcher.ChainLog),
+		genesis: time.Unix(1700000000, 0),
+	}
+	c.blocks = append(c.blocks, watcher.Block{Number: 0, Hash: c.blockHash(0)})
+	return c
+}
+
+// ChainID returns the chain ID of the chain
+func (c *Chain) ChainID() string {
+	return c.chainID
+}
+
+// blockHash derives the hash of a block from its number and fork, the caller holds the lock
+func (c *Chain) blockHash(number uint64) string {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s/%d/%d", c.chainID, c.fork, number))).Hex()
+}
+
+// Mine appends a block including the given logs, their chain ID and timestamps are set when
+// empty
+func (c *Chain) Mine(logs ...neth.Log) watcher.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	number := uint64(len(c.blocks))
+	block := watcher.Block{
+		Number:     number,
+		Hash:       c.blockHash(number),
+		ParentHash: c.blocks[number-1].Hash,
+	}
+	c.blocks = append(c.blocks, block)
+
+	for _, log := range logs {
+		if log.ChainID == "" {
+			log.ChainID = c.chainID
+		}
+		if log.CreatedAt.IsZero() {
+			log.CreatedAt = c.genesis.Add(time.Duration(number) * 12 * time.Second)
+			log.UpdatedAt = log.CreatedAt
+		}
+		c.logs[block.Hash] = append(c.logs[block.Hash], watcher.ChainLog{Log: log, BlockNumber: number, BlockHash: block.Hash})
+	}
+
+	return block
+}
+
+// Reorg drops the blocks above the given height, the blocks mined next belong to a new fork
+func (c *Chain) Reorg(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number+1 < uint64(len(c.blocks)) {
+		c.blocks = c.blocks[:number+1]
+	}
+	c.fork++
+}
+
+// Transfer returns an ERC20 Transfer log of a token, with a unique log and transaction hash
+func (c *Chain) Transfer(token, from, to string, value *big.Int) neth.Log {
+	c.mu.Lock()
+	c.nonce++
+	nonce := c.nonce
+	c.mu.Unlock()
+
+	data, _ := json.Marshal(map[string]string{
+		"from":  from,
+		"to":    to,
+		"value": value.String(),
+	})
+	raw := json.RawMessage(data)
+
+	txHash := crypto.Keccak256Hash([]byte(fmt.Sprintf("%s/tx/%d", c.chainID, nonce))).Hex()
+
+	return neth.Log{
+		Hash:    crypto.Keccak256Hash([]byte(fmt.Sprintf("%s/log/%d", c.chainID, nonce))).Hex(),
+		TxHash:  txHash,
+		ChainID: c.chainID,
+		Topic:   neth.TopicERC20Transfer,
+		Nonce:   nonce,
+		Sender:  from,
+		To:      common.HexToAddress(token).Hex(),
+		Value:   big.NewInt(0),
+		Data:    &raw,
+	}
+}
+
+// BlockNumber returns the number of the head block
+func (c *Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return uint64(len(c.blocks) - 1), nil
+}
+
+// BlockByNumber returns the canonical block at a height
+func (c *Chain) BlockByNumber(ctx context.Context, number uint64) (*watcher.Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	block := c.blocks[number]
+	return &block, nil
+}
+
+// FilterLogs returns the logs of the canonical blocks matching the filter
+func (c *Chain) FilterLogs(ctx context.Context, filter watcher.LogFilter) ([]watcher.ChainLog, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var logs []watcher.ChainLog
+	for number := filter.FromBlock; number <= filter.ToBlock && number < uint64(len(c.blocks)); number++ {
+		for _, log := range c.logs[c.blocks[number].Hash] {
+			if matchesAny(filter.Addresses, log.Log.To) && matchesAny(filter.Topics, log.Log.Topic) {
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// matchesAny reports whether a value is one of the values, an empty list matches everything
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
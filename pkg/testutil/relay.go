@@ -0,0 +1,225 @@
+// Package testutil provides an in-memory Nostr relay and a simulated chain, so that the
+// watcher to relay loop can be tested end to end without network access
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Relay is an in-memory NIP-01 relay served over a local websocket. It can be used directly
+// as the relays of the bundler or the store of the reconciler.
+type Relay struct {
+	mu sync.Mutex
+
+	server  *httptest.Server
+	events  []*nostr.Event
+	clients map[*relayClient]bool
+}
+
+// relayClient is a websocket connection and its open subscriptions
+type relayClient struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+	subs map[string]nostr.Filters
+}
+
+// NewRelay starts a new relay, it is closed when the test ends
+func NewRelay(t testing.TB) *Relay {
+	r := &Relay{clients: make(map[*relayClient]bool)}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveWebsocket))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// URL returns the websocket URL of the relay
+func (r *Relay) URL() string {
+	return "ws" + strings.TrimPrefix(r.server.URL, "http")
+}
+
+// Close stops the relay
+func (r *Relay) Close() {
+	r.server.CloseClientConnections()
+	r.server.Close()
+}
+
+// Events returns the stored events in the order they were accepted
+func (r *Relay) Events() []*nostr.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*nostr.Event(nil), r.events...)
+}
+
+// Publish verifies and stores an event and sends it to the matching subscriptions
+func (r *Relay) Publish(ctx context.Context, evt nostr.Event) error {
+	if ok, err := evt.CheckSignature(); err != nil || !ok {
+		return fmt.Errorf("invalid: bad signature for event %s", evt.ID)
+	}
+
+	r.mu.Lock()
+	stored := r.store(&evt)
+	clients := make([]*relayClient, 0, len(r.clients))
+	for client := range r.clients {
+		clients = append(clients, client)
+	}
+	r.mu.Unlock()
+
+	if !stored {
+		return nil
+	}
+
+	for _, client := range clients {
+		client.broadcast(ctx, &evt)
+	}
+
+	return nil
+}
+
+// Query returns the stored events matching a filter
+func (r *Relay) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*nostr.Event
+	for _, evt := range r.events {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+
+	return events, nil
+}
+
+// store stores an event, replaceable and addressable events replace the older version of the
+// same author and kind (and d tag), the caller holds the lock
+func (r *Relay) store(evt *nostr.Event) bool {
+	for i, existing := range r.events {
+		if existing.ID == evt.ID {
+			return false
+		}
+
+		if !replaces(evt, existing) {
+			continue
+		}
+		if existing.CreatedAt > evt.CreatedAt {
+			return false
+		}
+
+		r.events = append(r.events[:i], r.events[i+1:]...)
+		break
+	}
+
+	r.events = append(r.events, evt)
+	return true
+}
+
+// replaces reports whether evt is a newer version of the replaceable or addressable existing
+func replaces(evt, existing *nostr.Event) bool {
+	if evt.Kind != existing.Kind || evt.PubKey != existing.PubKey {
+		return false
+	}
+
+	switch {
+	case nostr.IsReplaceableKind(evt.Kind):
+		return true
+	case nostr.IsAddressableKind(evt.Kind):
+		return evt.Tags.GetD() == existing.Tags.GetD()
+	default:
+		return false
+	}
+}
+
+// serveWebsocket handles the NIP-01 messages of one connection
+func (r *Relay) serveWebsocket(w http.ResponseWriter, req *http.Request) {
+	conn, err := websocket.Accept(w, req, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	client := &relayClient{conn: conn, subs: make(map[string]nostr.Filters)}
+
+	r.mu.Lock()
+	r.clients[client] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.clients, client)
+		r.mu.Unlock()
+	}()
+
+	ctx := req.Context()
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		switch env := nostr.ParseMessage(string(data)).(type) {
+		case *nostr.EventEnvelope:
+			ok := nostr.OKEnvelope{EventID: env.Event.ID, OK: true}
+			if err := r.Publish(ctx, env.Event); err != nil {
+				ok.OK = false
+				ok.Reason = err.Error()
+			}
+			client.write(ctx, ok)
+		case *nostr.ReqEnvelope:
+			client.mu.Lock()
+			client.subs[env.SubscriptionID] = env.Filters
+			client.mu.Unlock()
+
+			for _, filter := range env.Filters {
+				events, _ := r.Query(ctx, filter)
+				for _, evt := range events {
+					client.write(ctx, nostr.EventEnvelope{SubscriptionID: &env.SubscriptionID, Event: *evt})
+				}
+			}
+			client.write(ctx, nostr.EOSEEnvelope(env.SubscriptionID))
+		case *nostr.CloseEnvelope:
+			client.mu.Lock()
+			delete(client.subs, string(*env))
+			client.mu.Unlock()
+			client.write(ctx, nostr.ClosedEnvelope{SubscriptionID: string(*env)})
+		}
+	}
+}
+
+// broadcast sends an event to the subscriptions of the client it matches
+func (c *relayClient) broadcast(ctx context.Context, evt *nostr.Event) {
+	c.mu.Lock()
+	var matching []string
+	for id, filters := range c.subs {
+		if filters.Match(evt) {
+			matching = append(matching, id)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, id := range matching {
+		c.write(ctx, nostr.EventEnvelope{SubscriptionID: &id, Event: *evt})
+	}
+}
+
+// write sends a message to the client, errors are left to the read loop to notice
+func (c *relayClient) write(ctx context.Context, env json.Marshaler) {
+	b, err := json.Marshal(env)
+	if err != nil {
+		return
+	}
+	c.conn.Write(ctx, websocket.MessageText, b)
+}
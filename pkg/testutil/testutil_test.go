@@ -0,0 +1,81 @@
+package testutil_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/pipeline"
+	"github.com/comunifi/nostr-eth/pkg/service"
+	"github.com/comunifi/nostr-eth/pkg/testutil"
+	"github.com/comunifi/nostr-eth/pkg/watcher"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestWatcherToRelayLoop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	relay := testutil.NewRelay(t)
+	chain := testutil.NewChain("100")
+	privateKey := nostr.GeneratePrivateKey()
+
+	pool := nostr.NewSimplePool(ctx)
+	publisher := service.NewQueuePublisher(service.NewPoolPublisher(pool), []string{relay.URL()}, nil, "publisher")
+	w := watcher.New(chain, publisher, chain.ChainID(), privateKey,
+		watcher.WithStartBlock(1),
+		watcher.WithConfirmations(watcher.ConfirmationPolicy{Default: 2}),
+		watcher.WithPollInterval(10*time.Millisecond),
+	)
+
+	conn, err := nostr.RelayConnect(ctx, relay.URL())
+	if err != nil {
+		t.Fatalf("Failed to connect to relay: %v", err)
+	}
+	sub, err := conn.Subscribe(ctx, nostr.Filters{{Kinds: []int{event.KindTxLog}}})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	p := pipeline.New(publisher, w)
+	if err := p.Start(ctx); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+	defer p.Stop()
+
+	transfer := chain.Transfer("0x00000000000000000000000000000000000000aa",
+		"0x1111111111111111111111111111111111111111", "0x2222222222222222222222222222222222222222", big.NewInt(1000))
+	chain.Mine(transfer)
+	chain.Mine()
+
+	var statuses []event.TxLogStatus
+	for len(statuses) < 2 {
+		select {
+		case evt := <-sub.Events:
+			txLogEvent, err := event.ParseTxLogEvent(evt)
+			if err != nil {
+				t.Fatalf("Failed to parse tx log event: %v", err)
+			}
+			if txLogEvent.LogData.Hash != transfer.Hash {
+				t.Errorf("Expected log %s, got %s", transfer.Hash, txLogEvent.LogData.Hash)
+			}
+			statuses = append(statuses, txLogEvent.Status)
+		case <-ctx.Done():
+			t.Fatalf("Expected 2 events, got %v", statuses)
+		}
+	}
+
+	if statuses[0] != event.TxLogStatusIncluded || statuses[1] != event.TxLogStatusConfirmed {
+		t.Errorf("Expected [included confirmed], got %v", statuses)
+	}
+
+	stored, err := relay.Query(ctx, nostr.Filter{Kinds: []int{event.KindTxLog}, Tags: nostr.TagMap{"d": []string{transfer.Hash}}})
+	if err != nil {
+		t.Fatalf("Failed to query relay: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Errorf("Expected 2 stored events, got %d", len(stored))
+	}
+}
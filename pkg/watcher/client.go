@@ -0,0 +1,43 @@
+// Package watcher follows a chain and turns the logs it sees into tx log events
+package watcher
+
+import (
+	"context"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+)
+
+// Block is the part of a block header the watcher needs to follow the canonical chain
+type Block struct {
+	Number     uint64
+	Hash       string
+	ParentHash string
+}
+
+// LogFilter selects the logs of a block range, empty addresses or topics match everything
+type LogFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Addresses []string
+	Topics    []string // Matched against the first topic of the logs
+}
+
+// ChainLog is a log together with the block it was included in
+type ChainLog struct {
+	Log         neth.Log `json:"log"`
+	BlockNumber uint64   `json:"block_number"`
+	BlockHash   string   `json:"block_hash"`
+}
+
+// key identifies a log in a block, the same log gets a new key when it is reorged into
+// another block
+func (l ChainLog) key() string {
+	return l.Log.Hash + "/" + l.BlockHash
+}
+
+// ChainClient reads blocks and logs from a node, implementations usually wrap a JSON-RPC client
+type ChainClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number uint64) (*Block, error)
+	FilterLogs(ctx context.Context, filter LogFilter) ([]ChainLog, error)
+}
@@ -0,0 +1,25 @@
+package watcher
+
+// ConfirmationPolicy sets how many confirmations a log needs before the watcher publishes its
+// "confirmed" update, the block including a log counts as its first confirmation. A depth of 0
+// disables confirmation tracking, only the created events are published.
+type ConfirmationPolicy struct {
+	Default  uint64
+	PerChain map[string]uint64 // Overrides by chain ID
+}
+
+// Depth returns the number of confirmations required on a chain
+func (p ConfirmationPolicy) Depth(chainID string) uint64 {
+	if depth, ok := p.PerChain[chainID]; ok {
+		return depth
+	}
+	return p.Default
+}
+
+// confirmations returns the number of confirmations of a block at the given head
+func confirmations(blockNumber, head uint64) uint64 {
+	if head < blockNumber {
+		return 0
+	}
+	return head - blockNumber + 1
+}
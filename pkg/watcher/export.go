@@ -0,0 +1,80 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// maxExportLine is the longest line of an event dump that can be imported
+const maxExportLine = 16 * 1024 * 1024
+
+// ExportEvents writes the events of a store matching a filter as NDJSON, one event per line from
+// the oldest to the newest, the dump format of strfry. It returns the number of exported events.
+func ExportEvents(ctx context.Context, store Store, w io.Writer, filter nostr.Filter) (int, error) {
+	events, err := store.Query(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query events: %w", err)
+	}
+	event.SortEventsByCreatedAt(events, false)
+
+	buf := bufio.NewWriter(w)
+	for i, evt := range events {
+		line, err := json.Marshal(evt)
+		if err != nil {
+			return i, err
+		}
+		line = append(line, '\n')
+
+		if _, err := buf.Write(line); err != nil {
+			return i, err
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// ImportEvents reads an NDJSON event dump, e.g. from ExportEvents or strfry, and publishes every
+// event to a store. Blank lines are skipped, lines that are not events with a valid ID and
+// signature fail the import. It returns the number of imported events.
+func ImportEvents(ctx context.Context, store EventStore, r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLine)
+
+	imported := 0
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var evt nostr.Event
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return imported, fmt.Errorf("line %d: invalid event: %w", line, err)
+		}
+		if !evt.CheckID() {
+			return imported, fmt.Errorf("line %d: invalid id for event %s", line, evt.ID)
+		}
+		if ok, err := evt.CheckSignature(); err != nil || !ok {
+			return imported, fmt.Errorf("line %d: invalid signature for event %s", line, evt.ID)
+		}
+
+		if err := store.Publish(ctx, evt); err != nil {
+			return imported, fmt.Errorf("line %d: failed to publish %s: %w", line, evt.ID, err)
+		}
+		imported++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
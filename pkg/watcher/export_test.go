@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestExportImportEvents(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+
+	source := &memoryEventStore{}
+	for i := range 5 {
+		evt, err := event.CreateTxLogEvent(testLog(fmt.Sprintf("0x%02x", i)))
+		if err != nil {
+			t.Fatalf("Failed to create tx log event: %v", err)
+		}
+		evt.CreatedAt = nostr.Timestamp(1700000000 - i) // Newest first in the store
+		evt.Sign(privateKey)
+		source.events = append(source.events, evt)
+	}
+	source.events = append(source.events, &nostr.Event{Kind: 1, Content: "not exported"})
+
+	var dump bytes.Buffer
+	exported, err := ExportEvents(context.Background(), source, &dump, nostr.Filter{Kinds: []int{event.KindTxLog}})
+	if err != nil {
+		t.Fatalf("Failed to export events: %v", err)
+	}
+	if exported != 5 {
+		t.Fatalf("Expected 5 exported events, got %d", exported)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(dump.String(), "\n"), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[0], source.events[4].ID) {
+		t.Fatalf("Expected 5 lines from the oldest event, got %d", len(lines))
+	}
+
+	target := &memoryEventStore{}
+	imported, err := ImportEvents(context.Background(), target, strings.NewReader(dump.String()+"\n"))
+	if err != nil {
+		t.Fatalf("Failed to import events: %v", err)
+	}
+	if imported != 5 || len(target.events) != 5 || target.events[4].ID != source.events[0].ID {
+		t.Errorf("Expected the 5 events to be restored in order, got %d", imported)
+	}
+
+	// Tampered events fail the import at their line
+	tampered := strings.Replace(dump.String(), `"kind":111000`, `"kind":1`, 1)
+	if _, err := ImportEvents(context.Background(), &memoryEventStore{}, strings.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected an error at line 1, got %v", err)
+	}
+	if _, err := ImportEvents(context.Background(), &memoryEventStore{}, strings.NewReader("{not json\n")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
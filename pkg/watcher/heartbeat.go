@@ -0,0 +1,165 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+)
+
+// DefaultHeartbeatInterval is how often heartbeats are sent
+const DefaultHeartbeatInterval = time.Minute
+
+// ErrHeartbeaterStarted is returned when a started heartbeater is started again
+var ErrHeartbeaterStarted = errors.New("heartbeater already started")
+
+// Heartbeater sends the heartbeats of a bridge instance: its uptime, the last block processed by
+// each of its watchers and the connectivity of its relays
+type Heartbeater struct {
+	mu sync.Mutex
+
+	sink        sink.Sink
+	privateKey  string
+	instance    string
+	interval    time.Duration
+	onError     func(error)
+	now         func() time.Time
+	startedAt   time.Time
+	watchers    []*Watcher
+	relayStatus func() map[string]bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHeartbeater creates a new heartbeater of an instance, sending every interval or
+// DefaultHeartbeatInterval when it is 0. Errors are reported to onError when it is not nil.
+func NewHeartbeater(s sink.Sink, privateKey, instance string, interval time.Duration, onError func(error)) *Heartbeater {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Heartbeater{
+		sink:       s,
+		privateKey: privateKey,
+		instance:   instance,
+		interval:   interval,
+		onError:    onError,
+		now:        time.Now,
+		startedAt:  time.Now(),
+	}
+}
+
+// AddWatchers reports the progress of watchers in the heartbeats
+func (h *Heartbeater) AddWatchers(watchers ...*Watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.watchers = append(h.watchers, watchers...)
+}
+
+// SetRelayStatus reports the connectivity of the relays in the heartbeats, status returns
+// whether each relay is connected
+func (h *Heartbeater) SetRelayStatus(status func() map[string]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.relayStatus = status
+}
+
+// Beat sends a heartbeat
+func (h *Heartbeater) Beat(ctx context.Context) error {
+	h.mu.Lock()
+	watchers, relayStatus := append([]*Watcher(nil), h.watchers...), h.relayStatus
+	h.mu.Unlock()
+
+	heartbeat := event.Heartbeat{
+		Instance:  h.instance,
+		StartedAt: h.startedAt,
+		Chains:    make(map[string]uint64),
+		At:        h.now(),
+	}
+	for _, w := range watchers {
+		chainID, block := w.Progress()
+		if last, ok := heartbeat.Chains[chainID]; !ok || block > last {
+			heartbeat.Chains[chainID] = block
+		}
+	}
+	if relayStatus != nil {
+		heartbeat.Relays = relayStatus()
+	}
+
+	evt, err := event.CreateHeartbeatEvent(heartbeat)
+	if err != nil {
+		return err
+	}
+	if err := evt.Sign(h.privateKey); err != nil {
+		return fmt.Errorf("failed to sign heartbeat: %w", err)
+	}
+	if err := h.sink.Send(ctx, evt); err != nil {
+		return fmt.Errorf("failed to send heartbeat %s: %w", evt.ID, err)
+	}
+	return nil
+}
+
+// Start sends a heartbeat right away, then every interval until Stop is called or the context is
+// cancelled
+func (h *Heartbeater) Start(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cancel != nil {
+		return ErrHeartbeaterStarted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	h.cancel = cancel
+	h.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		h.run(ctx)
+	}(h.done)
+
+	return nil
+}
+
+// Stop stops sending heartbeats and waits for the running one to be sent
+func (h *Heartbeater) Stop() error {
+	h.mu.Lock()
+	cancel, done := h.cancel, h.done
+	h.cancel, h.done = nil, nil
+	h.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+// run sends a heartbeat every interval until the context is done
+func (h *Heartbeater) run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.Beat(ctx); err != nil && ctx.Err() == nil {
+			h.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestHeartbeater(t *testing.T) {
+	ctx := context.Background()
+
+	chain := newFakeChain()
+	w := New(chain, &recordingSink{}, "100", nostr.GeneratePrivateKey(), WithStartBlock(1))
+	chain.mine("a")
+	chain.mine("a")
+	poll(t, w)
+
+	s := &recordingSink{}
+	h := NewHeartbeater(s, nostr.GeneratePrivateKey(), "bridge-1", time.Hour, nil)
+	h.startedAt = time.Now().Add(-time.Hour)
+	h.AddWatchers(w)
+	h.SetRelayStatus(func() map[string]bool {
+		return map[string]bool{"wss://a.example.com": true, "wss://b.example.com": false}
+	})
+
+	if err := h.Start(ctx); err != nil {
+		t.Fatalf("Failed to start heartbeater: %v", err)
+	}
+	if err := h.Start(ctx); !errors.Is(err, ErrHeartbeaterStarted) {
+		t.Errorf("Expected the heartbeater to be started, got %v", err)
+	}
+	h.Stop()
+
+	if len(s.events) != 1 {
+		t.Fatalf("Expected a heartbeat right away, got %d events", len(s.events))
+	}
+	heartbeat, err := event.ParseHeartbeatEvent(s.events[0])
+	if err != nil {
+		t.Fatalf("Failed to parse heartbeat: %v", err)
+	}
+	if heartbeat.Instance != "bridge-1" || heartbeat.Uptime < 3600 {
+		t.Errorf("Expected an hour of uptime of bridge-1, got %+v", heartbeat)
+	}
+	if heartbeat.Chains["100"] != 2 {
+		t.Errorf("Expected block 2 on chain 100, got %v", heartbeat.Chains)
+	}
+	if !heartbeat.Relays["wss://a.example.com"] || heartbeat.Relays["wss://b.example.com"] {
+		t.Errorf("Expected relay a to be connected and b not, got %v", heartbeat.Relays)
+	}
+	if !event.IsBridgeHealthy(s.events, time.Minute) {
+		t.Error("Expected the bridge to be healthy")
+	}
+}
@@ -0,0 +1,301 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultLeaderTTL is how long a leader claim is valid, claims are renewed every third of it
+const DefaultLeaderTTL = 30 * time.Second
+
+// ErrElectorStarted is returned when a started elector is started again
+var ErrElectorStarted = errors.New("elector already started")
+
+// ElectorOption is a function that configures an Elector
+type ElectorOption func(*Elector)
+
+// WithLeaderTTL sets how long the leader claims are valid
+func WithLeaderTTL(ttl time.Duration) ElectorOption {
+	return func(e *Elector) {
+		if ttl > 0 {
+			e.ttl = ttl
+		}
+	}
+}
+
+// WithLeaderChange sets a function called when the instance becomes or stops being the leader
+func WithLeaderChange(onChange func(leader bool)) ElectorOption {
+	return func(e *Elector) {
+		e.onChange = onChange
+	}
+}
+
+// Elector elects the instance publishing for a group of bridge instances watching the same
+// contracts. Instances share the key of the bridge and read each other's leader claims from the
+// relays: the leader renews its claim, followers take over once it expires.
+type Elector struct {
+	mu sync.Mutex
+
+	querier    event.EventQuerier
+	sink       sink.Sink
+	privateKey string
+	group      string
+	instance   string
+	ttl        time.Duration
+	onChange   func(leader bool)
+	onError    func(error)
+
+	leader bool
+	claim  event.LeaderClaim // Last claim sent while leading
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewElector creates a new elector of an instance in a group, reading the claims from querier
+// and sending its own to s. Errors are reported to onError when it is not nil.
+func NewElector(querier event.EventQuerier, s sink.Sink, privateKey, group, instance string, onError func(error), opts ...ElectorOption) *Elector {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	e := &Elector{
+		querier:    querier,
+		sink:       s,
+		privateKey: privateKey,
+		group:      group,
+		instance:   instance,
+		ttl:        DefaultLeaderTTL,
+		onError:    onError,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// IsLeader returns whether the instance is the leader of its group
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leader && !e.claim.Expired(time.Now())
+}
+
+// Sink returns a sink sending to s while the instance is the leader. Events sent while following
+// are dropped, so that the watchers of every instance keep up with the chains without
+// publishing the same events.
+func (e *Elector) Sink(s sink.Sink) sink.Sink {
+	return &leaderSink{elector: e, sink: s}
+}
+
+// Campaign runs a round of the election: the leader renews its claim, a follower claims the
+// group when no other instance holds an unexpired claim and leads once its claim wins
+func (e *Elector) Campaign(ctx context.Context) error {
+	leader, err := e.currentLeader(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	leading := e.leader
+	e.mu.Unlock()
+
+	if leader != nil && leader.Instance != e.instance {
+		e.setLeader(false, event.LeaderClaim{})
+		return nil
+	}
+
+	claim := event.LeaderClaim{Group: e.group, Instance: e.instance, At: time.Now()}
+	claim.ExpiresAt = claim.At.Add(e.ttl)
+	claim.Since = claim.At
+	if leading {
+		e.mu.Lock()
+		claim.Since = e.claim.Since
+		e.mu.Unlock()
+	}
+	if err := e.send(ctx, claim); err != nil {
+		return err
+	}
+
+	// Instances claiming at once only lead when their claim wins
+	if !leading {
+		e.mu.Lock()
+		e.claim = claim
+		e.mu.Unlock()
+		if leader, err = e.currentLeader(ctx); err != nil {
+			return err
+		}
+		if leader != nil && leader.Instance != e.instance {
+			e.setLeader(false, event.LeaderClaim{})
+			return nil
+		}
+	}
+	e.setLeader(true, claim)
+	return nil
+}
+
+// Resign releases the claim of the leader, so that a follower takes over at its next round
+// instead of waiting for the claim to expire
+func (e *Elector) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	leading, claim := e.leader, e.claim
+	e.mu.Unlock()
+
+	if !leading {
+		return nil
+	}
+	e.setLeader(false, event.LeaderClaim{})
+
+	claim.At = time.Now()
+	claim.ExpiresAt = claim.At
+	return e.send(ctx, claim)
+}
+
+// Start runs a round of the election right away, then every third of the TTL until Stop is
+// called or the context is cancelled
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		return ErrElectorStarted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		e.run(ctx)
+	}(e.done)
+
+	return nil
+}
+
+// Stop stops the election, waits for the running round and resigns when the instance is the
+// leader
+func (e *Elector) Stop() error {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.ttl)
+	defer cancel()
+	return e.Resign(ctx)
+}
+
+// run runs a round of the election every third of the TTL until the context is done
+func (e *Elector) run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Campaign(ctx); err != nil && ctx.Err() == nil {
+			e.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// currentLeader reads the claims of the group. The instance's own claims on the relays are
+// replaced by the one it last sent while leading, so that it does not follow a stale claim of
+// its own.
+func (e *Elector) currentLeader(ctx context.Context) (*event.LeaderClaim, error) {
+	pubkey, err := nostr.GetPublicKey(e.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive pubkey: %w", err)
+	}
+
+	events, err := e.querier.Query(ctx, nostr.Filter{
+		Kinds:   []int{event.MappedKind(event.KindLeaderClaim)},
+		Authors: []string{pubkey},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leader claims: %w", err)
+	}
+
+	own := event.LeaderClaimID(e.group, e.instance)
+	claims := make([]*nostr.Event, 0, len(events))
+	for _, evt := range events {
+		if evt.Tags.GetD() != own {
+			claims = append(claims, evt)
+		}
+	}
+
+	e.mu.Lock()
+	claim := e.claim
+	e.mu.Unlock()
+	if claim.Instance != "" {
+		evt, err := event.CreateLeaderClaimEvent(claim)
+		if err != nil {
+			return nil, err
+		}
+		evt.PubKey = pubkey
+		claims = append(claims, evt)
+	}
+
+	return event.CurrentLeader(claims, e.group), nil
+}
+
+// send signs a claim and sends it to the sink
+func (e *Elector) send(ctx context.Context, claim event.LeaderClaim) error {
+	evt, err := event.CreateLeaderClaimEvent(claim)
+	if err != nil {
+		return err
+	}
+	if err := evt.Sign(e.privateKey); err != nil {
+		return fmt.Errorf("failed to sign leader claim: %w", err)
+	}
+	if err := e.sink.Send(ctx, evt); err != nil {
+		return fmt.Errorf("failed to send leader claim %s: %w", evt.ID, err)
+	}
+	return nil
+}
+
+// setLeader records whether the instance leads and calls onChange when it changed
+func (e *Elector) setLeader(leader bool, claim event.LeaderClaim) {
+	e.mu.Lock()
+	changed := e.leader != leader
+	e.leader, e.claim = leader, claim
+	onChange := e.onChange
+	e.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(leader)
+	}
+}
+
+// leaderSink sends events to a sink while its elector leads
+type leaderSink struct {
+	elector *Elector
+	sink    sink.Sink
+}
+
+// Send sends the event when the instance is the leader and drops it otherwise
+func (s *leaderSink) Send(ctx context.Context, evt *nostr.Event) error {
+	if !s.elector.IsLeader() {
+		return nil
+	}
+	return s.sink.Send(ctx, evt)
+}
@@ -0,0 +1,133 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// claimRelay records the events sent to it and returns them to queries
+type claimRelay struct {
+	recordingSink
+}
+
+func (r *claimRelay) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*nostr.Event
+	for _, evt := range r.events {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func TestElector(t *testing.T) {
+	ctx := context.Background()
+	relay := &claimRelay{}
+	privateKey := nostr.GeneratePrivateKey()
+
+	var changes []bool
+	a := NewElector(relay, relay, privateKey, "usdc", "a", nil, WithLeaderTTL(time.Minute), WithLeaderChange(func(leader bool) {
+		changes = append(changes, leader)
+	}))
+	b := NewElector(relay, relay, privateKey, "usdc", "b", nil, WithLeaderTTL(time.Minute))
+
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if err := b.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected a to lead and b to follow, got %v and %v", a.IsLeader(), b.IsLeader())
+	}
+
+	// Only the leader publishes
+	published := &recordingSink{}
+	a.Sink(published).Send(ctx, &nostr.Event{ID: "from-a"})
+	b.Sink(published).Send(ctx, &nostr.Event{ID: "from-b"})
+	if len(published.events) != 1 || published.events[0].ID != "from-a" {
+		t.Errorf("Expected only the events of a to be published, got %d", len(published.events))
+	}
+
+	// Renewals keep the term
+	since := a.claim.Since
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if !a.IsLeader() || !a.claim.Since.Equal(since) {
+		t.Errorf("Expected a to renew its term since %v, got %v", since, a.claim.Since)
+	}
+
+	// Followers take over once the leader resigns
+	if err := a.Resign(ctx); err != nil {
+		t.Fatalf("Failed to resign: %v", err)
+	}
+	if err := b.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if a.IsLeader() || !b.IsLeader() {
+		t.Errorf("Expected b to take over, got %v and %v", a.IsLeader(), b.IsLeader())
+	}
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("Expected a to lead then follow, got %v", changes)
+	}
+
+	// Stopping resigns
+	if err := b.Start(ctx); err != nil {
+		t.Fatalf("Failed to start elector: %v", err)
+	}
+	if err := b.Start(ctx); !errors.Is(err, ErrElectorStarted) {
+		t.Errorf("Expected the elector to be started, got %v", err)
+	}
+	b.Stop()
+	if b.IsLeader() {
+		t.Error("Expected b to resign when stopped")
+	}
+	events, _ := relay.Query(ctx, nostr.Filter{})
+	if leader := event.CurrentLeader(events, "usdc"); leader != nil {
+		t.Errorf("Expected no leader, got %s", leader.Instance)
+	}
+}
+
+func TestElectorExpiredLeader(t *testing.T) {
+	ctx := context.Background()
+	relay := &claimRelay{}
+	privateKey := nostr.GeneratePrivateKey()
+
+	// A leader that stopped renewing its claim
+	now := time.Now()
+	evt, err := event.CreateLeaderClaimEvent(event.LeaderClaim{Group: "usdc", Instance: "a", Since: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Second), At: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Failed to create leader claim: %v", err)
+	}
+	evt.Sign(privateKey)
+	relay.Send(ctx, evt)
+
+	// Claims of other groups and other keys are ignored
+	other := NewElector(relay, relay, privateKey, "dai", "c", nil)
+	stranger := NewElector(relay, relay, nostr.GeneratePrivateKey(), "usdc", "0", nil)
+	for _, e := range []*Elector{other, stranger} {
+		if err := e.Campaign(ctx); err != nil {
+			t.Fatalf("Failed to campaign: %v", err)
+		}
+	}
+
+	b := NewElector(relay, relay, privateKey, "usdc", "b", nil)
+	if err := b.Campaign(ctx); err != nil {
+		t.Fatalf("Failed to campaign: %v", err)
+	}
+	if !b.IsLeader() {
+		t.Error("Expected b to take over the expired claim")
+	}
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ErrAlreadyStarted is returned when Start is called on a running watcher
+var ErrAlreadyStarted = errors.New("watcher already started")
+
+// watcherState is the part of the checkpoint specific to the watcher
+type watcherState struct {
+	Next     uint64            `json:"next"`
+	Blocks   map[uint64]string `json:"blocks,omitempty"`
+	Pending  []pendingState    `json:"pending,omitempty"`
+	Snapshot uint64            `json:"snapshot,omitempty"` // Block of the last balance snapshots
+}
+
+// pendingState is a pending log as saved in a checkpoint
+type pendingState struct {
+	Log   ChainLog     `json:"log"`
+	Event *nostr.Event `json:"event"`
+}
+
+// CheckpointKey returns the key the progress of the watcher is saved under
+func (w *Watcher) CheckpointKey() string {
+	return "watcher:" + w.chainID
+}
+
+// Start restores the saved checkpoint, if any, and polls the chain in the background until
+// Stop is called or the context is cancelled
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
+	if err := w.restoreCheckpoint(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		w.Run(ctx)
+	}(w.done)
+
+	return nil
+}
+
+// Stop stops polling, waits for the running poll to finish and saves the checkpoint. The
+// checkpoint is saved even though the context of Start is done by then.
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cancel = nil
+	w.done = nil
+
+	return w.saveCheckpoint(context.Background())
+}
+
+// saveCheckpoint saves the progress of the watcher, the caller holds the lock
+func (w *Watcher) saveCheckpoint(ctx context.Context) error {
+	if w.store == nil || !w.started {
+		return nil
+	}
+
+	ws := watcherState{Next: w.next, Blocks: w.blocks, Snapshot: w.lastSnapshot}
+	for _, p := range w.pending {
+		ws.Pending = append(ws.Pending, pendingState{Log: p.log, Event: p.evt})
+	}
+
+	data, err := json.Marshal(ws)
+	if err != nil {
+		return err
+	}
+
+	checkpoint := state.Checkpoint{
+		Key:       w.CheckpointKey(),
+		EventID:   w.lastID,
+		Data:      data,
+		UpdatedAt: time.Now(),
+	}
+	if w.next > 0 {
+		checkpoint.Block = w.next - 1
+	}
+
+	if err := w.store.Save(ctx, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// restoreCheckpoint resumes from the saved progress, the caller holds the lock
+func (w *Watcher) restoreCheckpoint(ctx context.Context) error {
+	if w.store == nil {
+		return nil
+	}
+
+	checkpoint, err := w.store.Load(ctx, w.CheckpointKey())
+	if errors.Is(err, state.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	var ws watcherState
+	if err := json.Unmarshal(checkpoint.Data, &ws); err != nil {
+		return fmt.Errorf("invalid checkpoint: %w", err)
+	}
+
+	w.started = true
+	w.next = ws.Next
+	w.lastID = checkpoint.EventID
+	w.lastSnapshot = ws.Snapshot
+
+	w.blocks = make(map[uint64]string)
+	for number, hash := range ws.Blocks {
+		w.blocks[number] = hash
+	}
+
+	w.pending = make(map[string]*pendingLog)
+	for _, p := range ws.Pending {
+		w.pending[p.Log.key()] = &pendingLog{log: p.Log, evt: p.Event}
+	}
+
+	return nil
+}
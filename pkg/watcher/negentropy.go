@@ -0,0 +1,238 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip77"
+	"github.com/nbd-wtf/go-nostr/nip77/negentropy"
+	"github.com/nbd-wtf/go-nostr/nip77/negentropy/storage/vector"
+)
+
+const (
+	// negentropyFrameSize caps the size of the negentropy messages, as go-nostr does
+	negentropyFrameSize = 1024 * 1024
+
+	// syncBatchSize is how many missing events are fetched per query
+	syncBatchSize = 50
+)
+
+// SyncDirection tells which side of a sync receives the missing events
+type SyncDirection int
+
+const (
+	SyncUp   SyncDirection = iota // Publish the events of the local store missing on the relay
+	SyncDown                      // Save the events of the relay missing in the local store
+	SyncBoth
+)
+
+// EventStore is a store events are queried from and published to, e.g. the local store of a
+// bridge or a relay
+type EventStore interface {
+	Store
+	Publish(ctx context.Context, evt nostr.Event) error
+}
+
+// NegentropyRelay is a relay supporting NIP-77 negentropy syncing. Reconcile sends a message of
+// the reconciliation, opening it with the filter on the first call, and returns the answer of the
+// relay. CloseReconcile ends the reconciliation.
+type NegentropyRelay interface {
+	EventStore
+	Reconcile(ctx context.Context, filter nostr.Filter, message string) (string, error)
+	CloseReconcile() error
+}
+
+// SyncResult lists the events exchanged by a sync
+type SyncResult struct {
+	Uploaded   []string // Published to the relay
+	Downloaded []string // Saved to the local store
+}
+
+// NegentropySync reconciles the events matching a filter between a local store and a relay with
+// NIP-77, so that only the missing events are exchanged instead of the full history, e.g. after
+// the bridge was down. Downloaded events with an invalid signature are not saved.
+func NegentropySync(ctx context.Context, local EventStore, relay NegentropyRelay, filter nostr.Filter, direction SyncDirection) (*SyncResult, error) {
+	events, err := local.Query(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the local store: %w", err)
+	}
+
+	items := vector.New()
+	for _, evt := range events {
+		items.Insert(evt.CreatedAt, evt.ID)
+	}
+	items.Seal()
+
+	neg := negentropy.New(items, negentropyFrameSize)
+
+	// The reconciliation blocks until the IDs are read
+	var haves, haveNots []string
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for id := range neg.Haves {
+			haves = append(haves, id)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for id := range neg.HaveNots {
+			haveNots = append(haveNots, id)
+		}
+	}()
+
+	// abort stops the readers of the IDs, negentropy only closes the channels once done
+	abort := func(err error) (*SyncResult, error) {
+		close(neg.Haves)
+		close(neg.HaveNots)
+		wg.Wait()
+		relay.CloseReconcile()
+		return nil, err
+	}
+
+	message := neg.Start()
+	for message != "" {
+		answer, err := relay.Reconcile(ctx, filter, message)
+		if err != nil {
+			return abort(fmt.Errorf("failed to reconcile with the relay: %w", err))
+		}
+		if message, err = neg.Reconcile(answer); err != nil {
+			return abort(fmt.Errorf("failed to reconcile: %w", err))
+		}
+	}
+	wg.Wait()
+	relay.CloseReconcile()
+
+	result := &SyncResult{}
+
+	if direction == SyncUp || direction == SyncBoth {
+		if result.Uploaded, err = copyEvents(ctx, local, relay, haves, false); err != nil {
+			return result, fmt.Errorf("failed to upload events: %w", err)
+		}
+	}
+
+	if direction == SyncDown || direction == SyncBoth {
+		if result.Downloaded, err = copyEvents(ctx, relay, local, haveNots, true); err != nil {
+			return result, fmt.Errorf("failed to download events: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// copyEvents fetches events by ID from a store and publishes them to another, by batches
+func copyEvents(ctx context.Context, source Store, target EventStore, ids []string, verify bool) ([]string, error) {
+	var copied []string
+	for start := 0; start < len(ids); start += syncBatchSize {
+		end := min(start+syncBatchSize, len(ids))
+
+		events, err := source.Query(ctx, nostr.Filter{IDs: ids[start:end]})
+		if err != nil {
+			return copied, err
+		}
+
+		for _, evt := range events {
+			if verify {
+				if ok, err := evt.CheckSignature(); err != nil || !ok || !evt.CheckID() {
+					continue
+				}
+			}
+			if err := target.Publish(ctx, *evt); err != nil {
+				return copied, fmt.Errorf("failed to publish %s: %w", evt.ID, err)
+			}
+			copied = append(copied, evt.ID)
+		}
+	}
+	return copied, nil
+}
+
+// negentropySubscription is the subscription ID of the reconciliations, one at a time per
+// connection
+const negentropySubscription = "nostr-eth-sync"
+
+// WebsocketNegentropyRelay is a NegentropyRelay over a websocket connection to a relay
+type WebsocketNegentropyRelay struct {
+	relay    *nostr.Relay
+	messages chan nostr.Envelope
+	opened   bool
+}
+
+// ConnectNegentropyRelay connects to a relay supporting NIP-77
+func ConnectNegentropyRelay(ctx context.Context, url string) (*WebsocketNegentropyRelay, error) {
+	r := &WebsocketNegentropyRelay{messages: make(chan nostr.Envelope, 1)}
+
+	relay, err := nostr.RelayConnect(ctx, url, nostr.WithCustomHandler(func(data string) {
+		envelope := nip77.ParseNegMessage(data)
+		if envelope == nil {
+			return
+		}
+		select {
+		case r.messages <- envelope:
+		default: // Unsolicited message, only answers are expected
+		}
+	}))
+	if err != nil {
+		return nil, err
+	}
+	r.relay = relay
+
+	return r, nil
+}
+
+// Query fetches the events matching a filter
+func (r *WebsocketNegentropyRelay) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	return r.relay.QuerySync(ctx, filter)
+}
+
+// Publish publishes an event and waits for the relay to accept it
+func (r *WebsocketNegentropyRelay) Publish(ctx context.Context, evt nostr.Event) error {
+	return r.relay.Publish(ctx, evt)
+}
+
+// Reconcile sends NEG-OPEN, then NEG-MSG, and waits for the NEG-MSG answer of the relay
+func (r *WebsocketNegentropyRelay) Reconcile(ctx context.Context, filter nostr.Filter, message string) (string, error) {
+	var data []byte
+	if r.opened {
+		data, _ = nip77.MessageEnvelope{SubscriptionID: negentropySubscription, Message: message}.MarshalJSON()
+	} else {
+		data, _ = nip77.OpenEnvelope{SubscriptionID: negentropySubscription, Filter: filter, Message: message}.MarshalJSON()
+		r.opened = true
+	}
+
+	if err := <-r.relay.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write to relay: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case envelope := <-r.messages:
+		switch env := envelope.(type) {
+		case *nip77.MessageEnvelope:
+			return env.Message, nil
+		case *nip77.ErrorEnvelope:
+			return "", fmt.Errorf("relay returned a %s: %s", env.Label(), env.Reason)
+		default:
+			return "", fmt.Errorf("unexpected %s received from relay", envelope.Label())
+		}
+	}
+}
+
+// CloseReconcile sends NEG-CLOSE, the next reconciliation opens a new one
+func (r *WebsocketNegentropyRelay) CloseReconcile() error {
+	if !r.opened {
+		return nil
+	}
+	r.opened = false
+
+	data, _ := nip77.CloseEnvelope{SubscriptionID: negentropySubscription}.MarshalJSON()
+	return <-r.relay.Write(data)
+}
+
+// Close closes the connection
+func (r *WebsocketNegentropyRelay) Close() error {
+	return r.relay.Close()
+}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip77/negentropy"
+	"github.com/nbd-wtf/go-nostr/nip77/negentropy/storage/vector"
+)
+
+// memoryEventStore is an event store in memory
+type memoryEventStore struct {
+	events  []*nostr.Event
+	queries int
+}
+
+func (s *memoryEventStore) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	s.queries++
+
+	var events []*nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryEventStore) Publish(ctx context.Context, evt nostr.Event) error {
+	s.events = append(s.events, &evt)
+	return nil
+}
+
+// memoryNegentropyRelay answers reconciliations as a NIP-77 relay would
+type memoryNegentropyRelay struct {
+	memoryEventStore
+	neg    *negentropy.Negentropy
+	rounds int
+}
+
+func (r *memoryNegentropyRelay) Reconcile(ctx context.Context, filter nostr.Filter, message string) (string, error) {
+	if r.neg == nil {
+		items := vector.New()
+		for _, evt := range r.events {
+			if filter.Matches(evt) {
+				items.Insert(evt.CreatedAt, evt.ID)
+			}
+		}
+		items.Seal()
+		r.neg = negentropy.New(items, negentropyFrameSize)
+	}
+
+	r.rounds++
+	return r.neg.Reconcile(message)
+}
+
+func (r *memoryNegentropyRelay) CloseReconcile() error {
+	r.neg = nil
+	return nil
+}
+
+func TestNegentropySync(t *testing.T) {
+	privateKey := nostr.GeneratePrivateKey()
+
+	events := make([]*nostr.Event, 0, 200)
+	for i := range 200 {
+		evt, err := event.CreateTxLogEvent(testLog(fmt.Sprintf("0x%02x", i)))
+		if err != nil {
+			t.Fatalf("Failed to create tx log event: %v", err)
+		}
+		evt.CreatedAt = nostr.Timestamp(1700000000 + i)
+		evt.Sign(privateKey)
+		events = append(events, evt)
+	}
+
+	// The bridge was down for the events 150 to 179, the relay lost the events 10 and 11
+	local := &memoryEventStore{}
+	relay := &memoryNegentropyRelay{}
+	for i, evt := range events {
+		if i < 150 || i >= 180 {
+			local.events = append(local.events, evt)
+		}
+		if i != 10 && i != 11 {
+			relay.events = append(relay.events, evt)
+		}
+	}
+
+	// A forged event on the relay is not saved
+	forged := *events[199]
+	forged.Content = "forged"
+	forged.CreatedAt++
+	forged.ID = forged.GetID()
+	relay.events = append(relay.events, &forged)
+
+	relay.queries = 0
+	result, err := NegentropySync(context.Background(), local, relay, nostr.Filter{Kinds: []int{event.KindTxLog}}, SyncBoth)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	if !slices.Equal(sortedIDs(result.Uploaded), sortedIDs([]string{events[10].ID, events[11].ID})) {
+		t.Errorf("Expected the events 10 and 11 to be uploaded, got %v", result.Uploaded)
+	}
+	if len(result.Downloaded) != 30 || slices.Contains(result.Downloaded, forged.ID) {
+		t.Errorf("Expected the 30 missed events to be downloaded, got %d", len(result.Downloaded))
+	}
+	if len(local.events) != 200 || len(relay.events) != 201 {
+		t.Errorf("Expected 200 local and 201 relay events, got %d and %d", len(local.events), len(relay.events))
+	}
+
+	// Only the missing events were fetched, by ID
+	if relay.queries != 1 {
+		t.Errorf("Expected 1 query to the relay, got %d", relay.queries)
+	}
+
+	// Nothing left to exchange
+	result, err = NegentropySync(context.Background(), local, relay, nostr.Filter{Kinds: []int{event.KindTxLog}}, SyncBoth)
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if len(result.Uploaded) != 0 || len(result.Downloaded) != 0 {
+		t.Errorf("Expected nothing to sync, got %d up and %d down", len(result.Uploaded), len(result.Downloaded))
+	}
+}
+
+// sortedIDs returns a sorted copy of IDs
+func sortedIDs(ids []string) []string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	return sorted
+}
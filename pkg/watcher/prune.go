@@ -0,0 +1,191 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultPruneInterval is how often a pruner applies its retention policy by default
+const DefaultPruneInterval = time.Hour
+
+// ErrPrunerStarted is returned when Start is called on a running pruner
+var ErrPrunerStarted = errors.New("pruner already started")
+
+// PrunableStore is an event store events can be deleted from, e.g. the local store of a bridge
+type PrunableStore interface {
+	EventStore
+	Delete(ctx context.Context, id string) error
+}
+
+// RetentionPolicy tells which events of a store are kept, zero values keep everything
+type RetentionPolicy struct {
+	MaxAge     time.Duration // Events created longer ago are deleted
+	MaxPerKind map[int]int   // Only the newest events of a kind are kept
+
+	// KeepLatestPerD deletes the events replaced by a newer event of the same kind, author and d
+	// tag, e.g. older tx log statuses, and keeps the latest whatever its age or kind count
+	KeepLatestPerD bool
+}
+
+// stateCoordinate returns the kind, author and d tag of an event with a d tag
+func stateCoordinate(evt *nostr.Event) (string, bool) {
+	tag := evt.Tags.Find("d")
+	if tag == nil || len(tag) < 2 {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%s:%s", evt.Kind, evt.PubKey, tag[1]), true
+}
+
+// Expired returns the IDs of the events the policy does not keep, at a time
+func (p RetentionPolicy) Expired(events []*nostr.Event, now time.Time) []string {
+	sorted := append([]*nostr.Event(nil), events...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt != sorted[j].CreatedAt {
+			return sorted[i].CreatedAt > sorted[j].CreatedAt
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	latest := make(map[string]bool) // Coordinates whose latest event was seen
+	perKind := make(map[int]int)
+	var expired []string
+	for _, evt := range sorted {
+		if p.KeepLatestPerD {
+			if coordinate, ok := stateCoordinate(evt); ok {
+				if latest[coordinate] {
+					expired = append(expired, evt.ID)
+				} else {
+					latest[coordinate] = true
+				}
+				continue
+			}
+		}
+
+		if p.MaxAge > 0 && evt.CreatedAt.Time().Before(now.Add(-p.MaxAge)) {
+			expired = append(expired, evt.ID)
+			continue
+		}
+
+		if max, ok := p.MaxPerKind[evt.Kind]; ok {
+			if perKind[evt.Kind] >= max {
+				expired = append(expired, evt.ID)
+				continue
+			}
+			perKind[evt.Kind]++
+		}
+	}
+	return expired
+}
+
+// Prune deletes the events of a store matching a filter that the policy does not keep and
+// returns their IDs
+func Prune(ctx context.Context, store PrunableStore, filter nostr.Filter, policy RetentionPolicy) ([]string, error) {
+	return pruneAt(ctx, store, filter, policy, time.Now())
+}
+
+// pruneAt prunes a store as Prune does at a time
+func pruneAt(ctx context.Context, store PrunableStore, filter nostr.Filter, policy RetentionPolicy, now time.Time) ([]string, error) {
+	events, err := store.Query(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+
+	var deleted []string
+	for _, id := range policy.Expired(events, now) {
+		if err := store.Delete(ctx, id); err != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", id, err)
+		}
+		deleted = append(deleted, id)
+	}
+	return deleted, nil
+}
+
+// Pruner prunes a store in the background so that long-running bridges do not grow it
+// unboundedly
+type Pruner struct {
+	mu sync.Mutex
+
+	store    PrunableStore
+	filter   nostr.Filter
+	policy   RetentionPolicy
+	interval time.Duration
+	onError  func(error)
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPruner creates a new pruner of the events of a store matching a filter, every interval or
+// DefaultPruneInterval when it is 0. Errors are reported to onError when it is not nil.
+func NewPruner(store PrunableStore, filter nostr.Filter, policy RetentionPolicy, interval time.Duration, onError func(error)) *Pruner {
+	if interval <= 0 {
+		interval = DefaultPruneInterval
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Pruner{store: store, filter: filter, policy: policy, interval: interval, onError: onError, now: time.Now}
+}
+
+// Start prunes the store right away, then every interval until Stop is called or the context is
+// cancelled
+func (p *Pruner) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return ErrPrunerStarted
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		p.run(ctx)
+	}(p.done)
+
+	return nil
+}
+
+// Stop stops pruning and waits for the running prune to finish
+func (p *Pruner) Stop() error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+// run prunes the store every interval until the context is done
+func (p *Pruner) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := pruneAt(ctx, p.store, p.filter, p.policy, p.now()); err != nil && ctx.Err() == nil {
+			p.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// prunableEventStore is an event store in memory events can be deleted from
+type prunableEventStore struct {
+	memoryEventStore
+}
+
+func (s *prunableEventStore) Delete(ctx context.Context, id string) error {
+	s.events = slices.DeleteFunc(s.events, func(evt *nostr.Event) bool { return evt.ID == id })
+	return nil
+}
+
+func (s *prunableEventStore) ids() []string {
+	var ids []string
+	for _, evt := range s.events {
+		ids = append(ids, evt.ID)
+	}
+	return ids
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	day := nostr.Timestamp(24 * 60 * 60)
+	at := nostr.Timestamp(now.Unix())
+
+	store := &prunableEventStore{memoryEventStore{events: []*nostr.Event{
+		{ID: "log-1-created", Kind: 111000, CreatedAt: at - 10*day, Tags: nostr.Tags{{"d", "0x01"}}},
+		{ID: "log-1-confirmed", Kind: 111000, CreatedAt: at - 9*day, Tags: nostr.Tags{{"d", "0x01"}}},
+		{ID: "note-old", Kind: 1, CreatedAt: at - 8*day},
+		{ID: "note-1", Kind: 1, CreatedAt: at - 3},
+		{ID: "note-2", Kind: 1, CreatedAt: at - 2},
+		{ID: "note-3", Kind: 1, CreatedAt: at - 1},
+	}}}
+
+	policy := RetentionPolicy{MaxAge: 7 * 24 * time.Hour, MaxPerKind: map[int]int{1: 2}, KeepLatestPerD: true}
+	expired := policy.Expired(store.events, now)
+	slices.Sort(expired)
+	if fmt.Sprint(expired) != "[log-1-created note-1 note-old]" {
+		t.Errorf("Expected the replaced, old and extra events to expire, got %v", expired)
+	}
+
+	// Without KeepLatestPerD the latest status is as old as any other event
+	if expired := (RetentionPolicy{MaxAge: 7 * 24 * time.Hour}).Expired(store.events, now); len(expired) != 3 {
+		t.Errorf("Expected 3 events older than 7 days, got %v", expired)
+	}
+
+	// The pruner deletes them in the background
+	pruner := NewPruner(store, nostr.Filter{}, policy, time.Hour, func(err error) { t.Error(err) })
+	pruner.now = func() time.Time { return now }
+	if err := pruner.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pruner: %v", err)
+	}
+	if err := pruner.Start(context.Background()); err != ErrPrunerStarted {
+		t.Errorf("Expected ErrPrunerStarted, got %v", err)
+	}
+	pruner.Stop() // Waits for the prune done when starting
+
+	if ids := store.ids(); fmt.Sprint(ids) != "[log-1-confirmed note-2 note-3]" {
+		t.Errorf("Expected the kept events, got %v", ids)
+	}
+}
@@ -0,0 +1,229 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Store answers queries for the events that were already published, e.g. a relay
+type Store interface {
+	Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error)
+}
+
+// Reconciler compares the tx log events of a store with the chain and backfills the logs that
+// were missed, e.g. while the watcher was down
+type Reconciler struct {
+	client     ChainClient
+	store      Store
+	sink       sink.Sink
+	chainID    string
+	privateKey string
+	logOptions []event.LogOption
+	auditor    *sink.Auditor
+	now        func() time.Time
+}
+
+// NewReconciler creates a new reconciler for a chain, the backfilled events and the report are
+// signed with the private key and sent to the sink
+func NewReconciler(client ChainClient, store Store, s sink.Sink, chainID, privateKey string, logOptions ...event.LogOption) *Reconciler {
+	return &Reconciler{
+		client:     client,
+		store:      store,
+		sink:       s,
+		chainID:    chainID,
+		privateKey: privateKey,
+		logOptions: logOptions,
+		now:        time.Now,
+	}
+}
+
+// SetAuditor records the backfills of the reconciler
+func (r *Reconciler) SetAuditor(auditor *sink.Auditor) {
+	r.auditor = auditor
+}
+
+// Reconcile checks the logs of every address from the last block with a published event, or
+// fromBlock when there is none, to the head. Missing logs are backfilled and a report event
+// is sent once every address was checked.
+func (r *Reconciler) Reconcile(ctx context.Context, addresses []string, fromBlock uint64) (*event.ReconciliationReport, error) {
+	head, err := r.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	report := &event.ReconciliationReport{
+		ChainID:   r.chainID,
+		FromBlock: head,
+		ToBlock:   head,
+		Addresses: make([]event.AddressReconciliation, 0, len(addresses)),
+	}
+
+	for _, address := range addresses {
+		result, err := r.reconcileAddress(ctx, address, fromBlock, head)
+		if err != nil {
+			return nil, err
+		}
+
+		start := fromBlock
+		if result.LastBlock > start {
+			start = result.LastBlock
+		}
+		if start < report.FromBlock {
+			report.FromBlock = start
+		}
+
+		report.Backfilled += len(result.Missing)
+		report.Addresses = append(report.Addresses, *result)
+	}
+
+	report.ReconciledAt = r.now()
+
+	evt, err := event.CreateReconciliationReportEvent(*report)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.send(ctx, evt); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// reconcileAddress backfills the missing logs of one address
+func (r *Reconciler) reconcileAddress(ctx context.Context, address string, fromBlock, head uint64) (*event.AddressReconciliation, error) {
+	result := &event.AddressReconciliation{Address: address}
+
+	published, err := r.store.Query(ctx, nostr.Filter{
+		Kinds: event.MappedKinds(event.KindTxLog),
+		Tags:  nostr.TagMap{"layer": []string{r.chainID}, "p": addressVariants(address)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events of %s: %w", address, err)
+	}
+
+	for _, evt := range published {
+		if number := eventBlockNumber(evt); number > result.LastBlock {
+			result.LastBlock = number
+		}
+	}
+
+	// The last block is checked again, it can hold other logs that were not published
+	start := fromBlock
+	if result.LastBlock > start {
+		start = result.LastBlock
+	}
+	if start > head {
+		return result, nil
+	}
+
+	logs, err := r.client.FilterLogs(ctx, LogFilter{FromBlock: start, ToBlock: head, Addresses: []string{address}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs of %s from block %d to %d: %w", address, start, head, err)
+	}
+	result.Checked = len(logs)
+
+	if len(logs) == 0 {
+		return result, nil
+	}
+
+	hashes := make([]string, len(logs))
+	for i, log := range logs {
+		hashes[i] = log.Log.Hash
+	}
+
+	existing, err := r.store.Query(ctx, nostr.Filter{
+		Kinds: event.MappedKinds(event.KindTxLog),
+		Tags:  nostr.TagMap{"layer": []string{r.chainID}, "d": hashes},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events of %s: %w", address, err)
+	}
+
+	found := make(map[string]bool, len(existing))
+	for _, evt := range existing {
+		if tag := evt.Tags.GetD(); tag != "" {
+			found[tag] = true
+		}
+	}
+
+	for _, log := range logs {
+		if found[log.Log.Hash] {
+			continue
+		}
+
+		if len(result.Missing) == 0 {
+			r.auditor.Record(ctx, event.AuditRecord{
+				ID:        fmt.Sprintf("backfill_started:%s:%s:%d", r.chainID, strings.ToLower(address), head),
+				Operation: event.AuditBackfillStarted,
+				Message:   fmt.Sprintf("Backfilling the missed logs of %s on chain %s from block %d to %d", address, r.chainID, start, head),
+				ChainID:   r.chainID,
+				Block:     start,
+				Details:   map[string]string{"address": address, "to_block": strconv.FormatUint(head, 10)},
+			})
+		}
+
+		opts := append([]event.LogOption{event.WithBlockNumber(log.BlockNumber)}, r.logOptions...)
+		evt, err := event.CreateTxLogEvent(log.Log, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.send(ctx, evt); err != nil {
+			return nil, err
+		}
+
+		found[log.Log.Hash] = true
+		result.Missing = append(result.Missing, log.Log.Hash)
+	}
+
+	return result, nil
+}
+
+// send signs an event and sends it to the sink
+func (r *Reconciler) send(ctx context.Context, evt *nostr.Event) error {
+	if err := evt.Sign(r.privateKey); err != nil {
+		return fmt.Errorf("failed to sign event: %w", err)
+	}
+
+	if err := r.sink.Send(ctx, evt); err != nil {
+		return fmt.Errorf("failed to send event %s: %w", evt.ID, err)
+	}
+
+	return nil
+}
+
+// eventBlockNumber returns the block number of a tx log event, 0 when it is unknown
+func eventBlockNumber(evt *nostr.Event) uint64 {
+	if tag := evt.Tags.GetFirst([]string{"block", ""}); tag != nil {
+		if number, err := strconv.ParseUint((*tag)[1], 10, 64); err == nil {
+			return number
+		}
+	}
+
+	txLogEvent, err := event.ParseTxLogEvent(evt)
+	if err != nil {
+		return 0
+	}
+	return txLogEvent.BlockNumber
+}
+
+// addressVariants returns the spellings an address can be tagged with
+func addressVariants(address string) []string {
+	variants := []string{address}
+	for _, variant := range []string{strings.ToLower(address), common.HexToAddress(address).Hex()} {
+		if !slices.Contains(variants, variant) {
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Query lets the recording sink act as the store of the reconciler
+func (s *recordingSink) Query(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var events []*nostr.Event
+	for _, evt := range s.events {
+		if filter.Matches(evt) {
+			events = append(events, evt)
+		}
+	}
+	return events, nil
+}
+
+func TestReconcilerBackfillsGaps(t *testing.T) {
+	token := "0x00000000000000000000000000000000000000aa"
+
+	tokenLog := func(hash string) (log neth.Log) {
+		log = testLog(hash)
+		log.To = token
+		return log
+	}
+
+	chain := newFakeChain()
+	chain.mine("a")
+	chain.mine("a", tokenLog("0x01"), tokenLog("0x02"))
+	chain.mine("a", tokenLog("0x03"))
+	chain.mine("a")
+
+	s := &recordingSink{}
+	privateKey := nostr.GeneratePrivateKey()
+
+	// Only the first log was published before the downtime
+	published, err := event.CreateTxLogEvent(tokenLog("0x01"), event.WithBlockNumber(2))
+	if err != nil {
+		t.Fatalf("Failed to create tx log event: %v", err)
+	}
+	s.events = append(s.events, published)
+
+	audit := &recordingSink{}
+	r := NewReconciler(chain, s, s, "100", privateKey)
+	r.SetAuditor(sink.NewAuditor(audit, privateKey, nil))
+
+	report, err := r.Reconcile(context.Background(), []string{token}, 1)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+
+	if report.FromBlock != 2 || report.ToBlock != 4 {
+		t.Errorf("Expected blocks 2 to 4, got %d to %d", report.FromBlock, report.ToBlock)
+	}
+	if report.Backfilled != 2 {
+		t.Fatalf("Expected 2 backfilled logs, got %d", report.Backfilled)
+	}
+	if got := report.Addresses[0].Missing; len(got) != 2 || got[0] != "0x02" || got[1] != "0x03" {
+		t.Errorf("Expected missing logs [0x02 0x03], got %v", got)
+	}
+
+	last := s.events[len(s.events)-1]
+	if last.Kind != event.KindReconciliationReport {
+		t.Fatalf("Expected a reconciliation report, got kind %d", last.Kind)
+	}
+	reportEvent, err := event.ParseReconciliationReportEvent(last)
+	if err != nil {
+		t.Fatalf("Failed to parse reconciliation report: %v", err)
+	}
+	if reportEvent.Report.Backfilled != 2 {
+		t.Errorf("Expected the report event to count 2 backfilled logs, got %d", reportEvent.Report.Backfilled)
+	}
+
+	// A second run finds nothing missing
+	report, err = r.Reconcile(context.Background(), []string{token}, 1)
+	if err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if report.Backfilled != 0 {
+		t.Errorf("Expected no backfilled logs, got %d", report.Backfilled)
+	}
+
+	records := event.AuditTrail(audit.events)
+	if len(records) != 1 || records[0].Operation != event.AuditBackfillStarted || records[0].Block != 2 {
+		t.Errorf("Expected one backfill from block 2 to be recorded, got %+v", records)
+	}
+}
@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Holding is a token held by an address whose balance is snapshotted
+type Holding struct {
+	Token   string
+	Address string
+}
+
+// WithBalanceSnapshots sends a balance snapshot event for every holding each time the head
+// advances by the given number of blocks, the balances are read with the token reader
+func WithBalanceSnapshots(reader neth.TokenReader, every uint64, holdings ...Holding) Option {
+	return func(w *Watcher) {
+		w.balanceReader = reader
+		w.snapshotEvery = every
+		w.holdings = holdings
+	}
+}
+
+// snapshot sends the balance snapshots of the holdings when the head is far enough from the
+// last snapshot block
+func (w *Watcher) snapshot(ctx context.Context, head uint64) error {
+	if w.balanceReader == nil || w.snapshotEvery == 0 || len(w.holdings) == 0 {
+		return nil
+	}
+	if w.lastSnapshot > 0 && head < w.lastSnapshot+w.snapshotEvery {
+		return nil
+	}
+
+	block, err := w.client.BlockByNumber(ctx, head)
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", head, err)
+	}
+
+	takenAt := time.Now()
+	for _, holding := range w.holdings {
+		balance, err := w.balanceReader.BalanceOf(ctx, common.HexToAddress(holding.Token), common.HexToAddress(holding.Address))
+		if err != nil {
+			return fmt.Errorf("failed to read the balance of %s in %s: %w", holding.Address, holding.Token, err)
+		}
+
+		evt, err := event.CreateBalanceSnapshotEvent(event.BalanceSnapshot{
+			ChainID:     w.chainID,
+			Token:       holding.Token,
+			Address:     holding.Address,
+			BlockNumber: head,
+			BlockHash:   block.Hash,
+			Balance:     balance.String(),
+			TakenAt:     takenAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := w.send(ctx, evt); err != nil {
+			return err
+		}
+	}
+
+	w.lastSnapshot = head
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// balanceReader returns a balance that the test can change
+type balanceReader struct {
+	balance int64
+}
+
+func (r *balanceReader) BalanceOf(ctx context.Context, contract, owner common.Address) (*big.Int, error) {
+	return big.NewInt(r.balance), nil
+}
+
+func (r *balanceReader) OwnerOf(ctx context.Context, contract common.Address, tokenID *big.Int) (common.Address, error) {
+	return common.Address{}, nil
+}
+
+func TestWatcherBalanceSnapshots(t *testing.T) {
+	const (
+		token   = "0xe91D153E0b41518A2Ce8Dd3D7944Fa863463a97d"
+		address = "0x1111111111111111111111111111111111111111"
+	)
+
+	chain := newFakeChain()
+	s := &recordingSink{}
+	reader := &balanceReader{balance: 100}
+	w := New(chain, s, "100", nostr.GeneratePrivateKey(),
+		WithStartBlock(1),
+		WithBalanceSnapshots(reader, 3, Holding{Token: token, Address: address}),
+	)
+
+	chain.mine("a")
+	poll(t, w)
+	if len(s.events) != 1 {
+		t.Fatalf("Expected 1 snapshot, got %d events", len(s.events))
+	}
+
+	// No snapshot before 3 more blocks
+	reader.balance = 250
+	chain.mine("a")
+	chain.mine("a")
+	poll(t, w)
+	if len(s.events) != 1 {
+		t.Fatalf("Expected 1 snapshot, got %d events", len(s.events))
+	}
+
+	chain.mine("a")
+	poll(t, w)
+	if len(s.events) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d events", len(s.events))
+	}
+
+	snapshotEvent, err := event.ParseBalanceSnapshotEvent(s.events[1])
+	if err != nil {
+		t.Fatalf("Failed to parse balance snapshot: %v", err)
+	}
+	snapshot := snapshotEvent.Snapshot
+	if snapshot.BlockNumber != 4 || snapshot.BlockHash != "0xa04" || snapshot.Balance != "250" {
+		t.Errorf("Expected 250 at block 4, got %s at block %d (%s)", snapshot.Balance, snapshot.BlockNumber, snapshot.BlockHash)
+	}
+	if ok, _ := s.events[1].CheckSignature(); !ok {
+		t.Error("Expected the snapshot to be signed")
+	}
+}
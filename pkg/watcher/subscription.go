@@ -0,0 +1,202 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// SubscriptionManager holds the contract addresses and topics a watcher filters logs on, and the
+// muted addresses whose transfers it skips. They can be changed while the watcher runs, changes
+// apply from the next block it scans.
+type SubscriptionManager struct {
+	mu sync.RWMutex
+
+	addresses map[string]bool
+	topics    map[string]bool
+	muted     map[string]bool
+}
+
+// NewSubscriptionManager creates a new subscription manager, with no addresses and topics
+// every log matches
+func NewSubscriptionManager() *SubscriptionManager {
+	return &SubscriptionManager{
+		addresses: make(map[string]bool),
+		topics:    make(map[string]bool),
+		muted:     make(map[string]bool),
+	}
+}
+
+// AddAddresses starts watching the given contract addresses
+func (m *SubscriptionManager) AddAddresses(addresses ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, address := range addresses {
+		m.addresses[strings.ToLower(address)] = true
+	}
+}
+
+// RemoveAddresses stops watching the given contract addresses
+func (m *SubscriptionManager) RemoveAddresses(addresses ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, address := range addresses {
+		delete(m.addresses, strings.ToLower(address))
+	}
+}
+
+// AddTopics starts watching the given event topics
+func (m *SubscriptionManager) AddTopics(topics ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, topic := range topics {
+		m.topics[strings.ToLower(topic)] = true
+	}
+}
+
+// RemoveTopics stops watching the given event topics
+func (m *SubscriptionManager) RemoveTopics(topics ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, topic := range topics {
+		delete(m.topics, strings.ToLower(topic))
+	}
+}
+
+// Addresses returns the watched addresses, sorted
+func (m *SubscriptionManager) Addresses() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return sortedKeys(m.addresses)
+}
+
+// Topics returns the watched topics, sorted
+func (m *SubscriptionManager) Topics() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return sortedKeys(m.topics)
+}
+
+// Filter returns the log filter of a block range for the current subscriptions
+func (m *SubscriptionManager) Filter(fromBlock, toBlock uint64) LogFilter {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return LogFilter{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Addresses: sortedKeys(m.addresses),
+		Topics:    sortedKeys(m.topics),
+	}
+}
+
+// ApplyWatchlist replaces the subscriptions with the content of a NIP-51 list. Addresses are
+// read from NIP-73 "i" tags of the chain (["i", "ethereum:<chain id>:address:<address>"]),
+// topics from ["topic", "<topic>"] tags.
+func (m *SubscriptionManager) ApplyWatchlist(chainID string, evt *nostr.Event) error {
+	addresses := make(map[string]bool)
+	topics := make(map[string]bool)
+
+	prefix := "ethereum:" + chainID + ":address:"
+
+	for _, tag := range evt.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+
+		switch tag[0] {
+		case "i":
+			if !strings.HasPrefix(tag[1], prefix) {
+				continue
+			}
+
+			address := strings.TrimPrefix(tag[1], prefix)
+			if !common.IsHexAddress(address) {
+				return fmt.Errorf("invalid watchlist address %q", address)
+			}
+			addresses[strings.ToLower(address)] = true
+		case "topic":
+			topics[strings.ToLower(tag[1])] = true
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addresses = addresses
+	m.topics = topics
+
+	return nil
+}
+
+// Set replaces the watched addresses and topics at once
+func (m *SubscriptionManager) Set(addresses, topics []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.addresses = make(map[string]bool)
+	for _, address := range addresses {
+		m.addresses[strings.ToLower(address)] = true
+	}
+	m.topics = make(map[string]bool)
+	for _, topic := range topics {
+		m.topics[strings.ToLower(topic)] = true
+	}
+}
+
+// SetMuted replaces the muted addresses
+func (m *SubscriptionManager) SetMuted(addresses ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.muted = make(map[string]bool)
+	for _, address := range addresses {
+		m.muted[strings.ToLower(address)] = true
+	}
+}
+
+// Muted returns the muted addresses, sorted
+func (m *SubscriptionManager) Muted() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return sortedKeys(m.muted)
+}
+
+// IsMuted checks if a log was sent by a muted address, or is a transfer from or to one
+func (m *SubscriptionManager) IsMuted(log neth.Log) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.muted) == 0 {
+		return false
+	}
+	if m.muted[strings.ToLower(log.Sender)] {
+		return true
+	}
+	if data, err := log.GetTransferData(); err == nil && data != nil {
+		return m.muted[strings.ToLower(data.From)] || m.muted[strings.ToLower(data.To)]
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a set, sorted
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
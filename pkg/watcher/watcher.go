@@ -0,0 +1,455 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultPollInterval is how often the chain is polled for new blocks
+const DefaultPollInterval = 5 * time.Second
+
+// Watcher polls a chain for logs and sends a created tx log event when a log is included, an
+// update once it reaches the confirmation depth of its chain and a rollback when its block is
+// orphaned before that
+type Watcher struct {
+	mu sync.Mutex
+
+	client     ChainClient
+	sink       sink.Sink
+	chainID    string
+	privateKey string
+
+	subscriptions *SubscriptionManager
+	policy        ConfirmationPolicy
+	pollInterval  time.Duration
+	logOptions    []event.LogOption
+	dust          *event.DustThresholds
+	dustAction    DustAction
+	onError       func(error)
+	store         state.Store
+	auditor       *sink.Auditor
+
+	balanceReader neth.TokenReader
+	snapshotEvery uint64
+	holdings      []Holding
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	started bool
+	next    uint64                 // Next block to scan
+	blocks  map[uint64]string      // Hashes of the scanned blocks that can still be reorged
+	pending map[string]*pendingLog // Logs waiting for confirmations, by log and block hash
+	lastID  string                 // Last sent event
+
+	lastSnapshot uint64 // Block of the last balance snapshots
+}
+
+// pendingLog is a log whose created event was sent but that is not confirmed yet
+type pendingLog struct {
+	log ChainLog
+	evt *nostr.Event
+}
+
+// Option configures a Watcher
+type Option func(*Watcher)
+
+// DustAction is what the watcher does with ERC20 transfers below the threshold of their token
+type DustAction int
+
+const (
+	DustTag  DustAction = iota // Send them with a "dust" t tag
+	DustSkip                   // Do not send them
+)
+
+// WithSubscriptions sets the subscription manager the watched addresses and topics are read
+// from, it should come before WithAddresses and WithTopics
+func WithSubscriptions(subscriptions *SubscriptionManager) Option {
+	return func(w *Watcher) {
+		w.subscriptions = subscriptions
+	}
+}
+
+// WithAddresses restricts the watched logs to the given contract addresses
+func WithAddresses(addresses ...string) Option {
+	return func(w *Watcher) {
+		w.subscriptions.AddAddresses(addresses...)
+	}
+}
+
+// WithTopics restricts the watched logs to the given event topics
+func WithTopics(topics ...string) Option {
+	return func(w *Watcher) {
+		w.subscriptions.AddTopics(topics...)
+	}
+}
+
+// WithConfirmations sets the confirmation policy
+func WithConfirmations(policy ConfirmationPolicy) Option {
+	return func(w *Watcher) {
+		w.policy = policy
+	}
+}
+
+// WithPollInterval sets how often the chain is polled
+func WithPollInterval(interval time.Duration) Option {
+	return func(w *Watcher) {
+		w.pollInterval = interval
+	}
+}
+
+// WithStartBlock sets the first block to scan, by default the watcher starts at the head
+func WithStartBlock(number uint64) Option {
+	return func(w *Watcher) {
+		w.next = number
+		w.started = true
+	}
+}
+
+// WithLogOptions sets the options the tx log events are created with
+func WithLogOptions(opts ...event.LogOption) Option {
+	return func(w *Watcher) {
+		w.logOptions = opts
+	}
+}
+
+// WithDustThresholds sets the minimum value of the watched transfers of each token, transfers
+// below it are tagged or skipped
+func WithDustThresholds(thresholds *event.DustThresholds, action DustAction) Option {
+	return func(w *Watcher) {
+		w.dust = thresholds
+		w.dustAction = action
+	}
+}
+
+// WithAuditor records the reorgs the watcher handles
+func WithAuditor(auditor *sink.Auditor) Option {
+	return func(w *Watcher) {
+		w.auditor = auditor
+	}
+}
+
+// Progress returns the chain of the watcher and the last block it processed, 0 until it scanned
+// one
+func (w *Watcher) Progress() (string, uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started || w.next == 0 {
+		return w.chainID, 0
+	}
+	return w.chainID, w.next - 1
+}
+
+// SetConfirmations replaces the confirmation policy of a running watcher, it applies from the
+// next poll
+func (w *Watcher) SetConfirmations(policy ConfirmationPolicy) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.policy = policy
+}
+
+// WithCheckpointStore persists the progress of the watcher after every poll, a restarted
+// watcher resumes from the saved checkpoint instead of the start block
+func WithCheckpointStore(store state.Store) Option {
+	return func(w *Watcher) {
+		w.store = store
+	}
+}
+
+// WithErrorHandler sets the function Run reports poll errors to, they are dropped by default
+func WithErrorHandler(handler func(error)) Option {
+	return func(w *Watcher) {
+		w.onError = handler
+	}
+}
+
+// New creates a new watcher for a chain, the events it sends are signed with the private key
+func New(client ChainClient, s sink.Sink, chainID, privateKey string, opts ...Option) *Watcher {
+	w := &Watcher{
+		client:        client,
+		sink:          s,
+		chainID:       chainID,
+		privateKey:    privateKey,
+		subscriptions: NewSubscriptionManager(),
+		pollInterval:  DefaultPollInterval,
+		onError:       func(error) {},
+		blocks:        make(map[uint64]string),
+		pending:       make(map[string]*pendingLog),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Subscriptions returns the subscription manager of the watcher, the addresses and topics it
+// holds can be changed while the watcher runs
+func (w *Watcher) Subscriptions() *SubscriptionManager {
+	return w.subscriptions
+}
+
+// Run polls the chain until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx); err != nil {
+			w.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll rolls back the logs of orphaned blocks, scans the new blocks, confirms the logs that
+// reached their depth and snapshots the balances when they are due. A failed poll can be retried,
+// events that were sent are not sent again.
+func (w *Watcher) Poll(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// The progress is saved even when the poll fails part way, it only covers sent events
+	err := w.poll(ctx)
+	if saveErr := w.saveCheckpoint(ctx); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+
+	return err
+}
+
+// poll runs one iteration of the watcher
+func (w *Watcher) poll(ctx context.Context) error {
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	if !w.started {
+		w.next = head
+		w.started = true
+	}
+
+	depth := w.policy.Depth(w.chainID)
+
+	if err := w.rollback(ctx); err != nil {
+		return err
+	}
+
+	if err := w.scan(ctx, head, depth); err != nil {
+		return err
+	}
+
+	if err := w.confirm(ctx, head, depth); err != nil {
+		return err
+	}
+
+	return w.snapshot(ctx, head)
+}
+
+// rollback finds the lowest tracked block that left the canonical chain, sends rollback events
+// for the logs at or above it and rewinds the scan to rescan the new canonical blocks
+func (w *Watcher) rollback(ctx context.Context) error {
+	heights := make(map[uint64]bool)
+	for number := range w.blocks {
+		heights[number] = true
+	}
+	for _, p := range w.pending {
+		heights[p.log.BlockNumber] = true
+	}
+
+	sorted := make([]uint64, 0, len(heights))
+	for number := range heights {
+		sorted = append(sorted, number)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	orphaned, reorged := uint64(0), false
+	for _, number := range sorted {
+		block, err := w.client.BlockByNumber(ctx, number)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", number, err)
+		}
+
+		if hash, ok := w.blocks[number]; ok && hash != block.Hash {
+			orphaned, reorged = number, true
+			break
+		}
+
+		for _, p := range w.pending {
+			if p.log.BlockNumber == number && p.log.BlockHash != block.Hash {
+				orphaned, reorged = number, true
+				break
+			}
+		}
+		if reorged {
+			break
+		}
+	}
+
+	if !reorged {
+		return nil
+	}
+
+	rolledBack := 0
+	for key, p := range w.pending {
+		if p.log.BlockNumber < orphaned {
+			continue
+		}
+
+		evt, err := event.OrphanTxLogEvent(p.evt)
+		if err != nil {
+			return err
+		}
+
+		if err := w.send(ctx, evt); err != nil {
+			return err
+		}
+
+		delete(w.pending, key)
+		rolledBack++
+	}
+
+	for number := range w.blocks {
+		if number >= orphaned {
+			delete(w.blocks, number)
+		}
+	}
+
+	w.auditor.Record(ctx, event.AuditRecord{
+		Operation: event.AuditReorgHandled,
+		Message:   fmt.Sprintf("Blocks from %d were orphaned on chain %s, %d logs were rolled back", orphaned, w.chainID, rolledBack),
+		ChainID:   w.chainID,
+		Block:     orphaned,
+		Details:   map[string]string{"rolled_back": strconv.Itoa(rolledBack)},
+	})
+
+	if orphaned < w.next {
+		w.next = orphaned
+	}
+
+	return nil
+}
+
+// scan sends created events for the logs of the blocks from the next block to the head
+func (w *Watcher) scan(ctx context.Context, head, depth uint64) error {
+	if w.next > head {
+		return nil
+	}
+
+	// Only the blocks within the confirmation depth can still orphan an unconfirmed log
+	if depth > 0 {
+		for number := w.next; number <= head; number++ {
+			if confirmations(number, head) > depth {
+				continue
+			}
+
+			block, err := w.client.BlockByNumber(ctx, number)
+			if err != nil {
+				return fmt.Errorf("failed to get block %d: %w", number, err)
+			}
+			w.blocks[number] = block.Hash
+		}
+	}
+
+	logs, err := w.client.FilterLogs(ctx, w.subscriptions.Filter(w.next, head))
+	if err != nil {
+		return fmt.Errorf("failed to filter logs from block %d to %d: %w", w.next, head, err)
+	}
+
+	for _, log := range logs {
+		key := log.key()
+		if _, ok := w.pending[key]; ok {
+			continue
+		}
+		if w.subscriptions.IsMuted(log.Log) {
+			continue
+		}
+
+		opts := append([]event.LogOption{event.WithBlockNumber(log.BlockNumber)}, w.logOptions...)
+		if w.dust != nil {
+			if w.dustAction == DustSkip && w.dust.IsDust(log.Log) {
+				continue
+			}
+			opts = append(opts, event.WithDustThresholds(w.dust))
+		}
+
+		evt, err := event.CreateTxLogEvent(log.Log, opts...)
+		if err != nil {
+			return err
+		}
+
+		if err := w.send(ctx, evt); err != nil {
+			return err
+		}
+
+		if depth > 0 {
+			w.pending[key] = &pendingLog{log: log, evt: evt}
+		}
+	}
+
+	w.next = head + 1
+
+	return nil
+}
+
+// confirm sends the update events of the logs that reached the confirmation depth
+func (w *Watcher) confirm(ctx context.Context, head, depth uint64) error {
+	for key, p := range w.pending {
+		if confirmations(p.log.BlockNumber, head) < depth {
+			continue
+		}
+
+		evt, err := event.UpdateTxLogStatus(p.evt, event.TxLogStatusConfirmed)
+		if err != nil {
+			return err
+		}
+
+		if err := w.send(ctx, evt); err != nil {
+			return err
+		}
+
+		delete(w.pending, key)
+	}
+
+	for number := range w.blocks {
+		if confirmations(number, head) > depth {
+			delete(w.blocks, number)
+		}
+	}
+
+	return nil
+}
+
+// send signs an event and sends it to the sink
+func (w *Watcher) send(ctx context.Context, evt *nostr.Event) error {
+	if err := evt.Sign(w.privateKey); err != nil {
+		return fmt.Errorf("failed to sign event: %w", err)
+	}
+
+	if err := w.sink.Send(ctx, evt); err != nil {
+		return fmt.Errorf("failed to send event %s: %w", evt.ID, err)
+	}
+	w.lastID = evt.ID
+
+	return nil
+}
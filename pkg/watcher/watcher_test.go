@@ -0,0 +1,421 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comunifi/nostr-eth/pkg/event"
+	"github.com/comunifi/nostr-eth/pkg/neth"
+	"github.com/comunifi/nostr-eth/pkg/sink"
+	"github.com/comunifi/nostr-eth/pkg/state"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// fakeChain is a chain whose blocks and logs are set by the test
+type fakeChain struct {
+	mu     sync.Mutex
+	blocks []Block
+	logs   map[string][]ChainLog // By block hash
+}
+
+func newFakeChain() *fakeChain {
+	c := &fakeChain{logs: make(map[string][]ChainLog)}
+	c.blocks = append(c.blocks, Block{Number: 0, Hash: "0x00"})
+	return c
+}
+
+// mine appends a block with the given logs to the canonical chain
+func (c *fakeChain) mine(fork string, logs ...neth.Log) Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	number := uint64(len(c.blocks))
+	block := Block{
+		Number:     number,
+		Hash:       fmt.Sprintf("0x%s%02x", fork, number),
+		ParentHash: c.blocks[number-1].Hash,
+	}
+	c.blocks = append(c.blocks, block)
+
+	for _, log := range logs {
+		c.logs[block.Hash] = append(c.logs[block.Hash], ChainLog{Log: log, BlockNumber: number, BlockHash: block.Hash})
+	}
+
+	return block
+}
+
+// reorg drops the blocks above the given number
+func (c *fakeChain) reorg(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = c.blocks[:number+1]
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *fakeChain) BlockByNumber(ctx context.Context, number uint64) (*Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	block := c.blocks[number]
+	return &block, nil
+}
+
+func (c *fakeChain) FilterLogs(ctx context.Context, filter LogFilter) ([]ChainLog, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matches := func(values []string, value string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		for _, v := range values {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var logs []ChainLog
+	for number := filter.FromBlock; number <= filter.ToBlock && number < uint64(len(c.blocks)); number++ {
+		for _, log := range c.logs[c.blocks[number].Hash] {
+			if matches(filter.Addresses, log.Log.To) && matches(filter.Topics, log.Log.Topic) {
+				logs = append(logs, log)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// recordingSink keeps the events it is sent
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*nostr.Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, evt *nostr.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, evt)
+	return nil
+}
+
+// statuses returns the event type and status of every recorded event
+func (s *recordingSink) statuses(t *testing.T) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var statuses []string
+	for _, evt := range s.events {
+		txLogEvent, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse tx log event: %v", err)
+		}
+		statuses = append(statuses, fmt.Sprintf("%s:%s", txLogEvent.EventType, txLogEvent.Status))
+	}
+	return statuses
+}
+
+func testLog(hash string) neth.Log {
+	return neth.Log{
+		Hash:      hash,
+		TxHash:    "0xabc",
+		ChainID:   "100",
+		Topic:     neth.TopicERC20Transfer,
+		CreatedAt: time.Unix(1700000000, 0),
+		Value:     big.NewInt(0),
+	}
+}
+
+func poll(t *testing.T, w *Watcher) {
+	if err := w.Poll(context.Background()); err != nil {
+		t.Fatalf("Failed to poll: %v", err)
+	}
+}
+
+func expectStatuses(t *testing.T, s *recordingSink, expected ...string) {
+	got := s.statuses(t)
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, got)
+	}
+}
+
+func TestWatcherConfirmationDepth(t *testing.T) {
+	chain := newFakeChain()
+	s := &recordingSink{}
+	w := New(chain, s, "100", nostr.GeneratePrivateKey(),
+		WithStartBlock(1),
+		WithConfirmations(ConfirmationPolicy{Default: 12, PerChain: map[string]uint64{"100": 3}}),
+	)
+
+	chain.mine("a", testLog("0x01"))
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included")
+
+	chain.mine("a")
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included")
+
+	chain.mine("a")
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included", "tx_log_updated:confirmed")
+
+	// The update references the created event
+	if tag := s.events[1].Tags.GetFirst([]string{"e"}); tag == nil || (*tag)[1] != s.events[0].ID {
+		t.Errorf("Expected the update to reference %s, got %v", s.events[0].ID, tag)
+	}
+
+	chain.mine("a")
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included", "tx_log_updated:confirmed")
+}
+
+func TestWatcherReorgRollback(t *testing.T) {
+	chain := newFakeChain()
+	s := &recordingSink{}
+	audit := &recordingSink{}
+	w := New(chain, s, "100", nostr.GeneratePrivateKey(),
+		WithStartBlock(1),
+		WithConfirmations(ConfirmationPolicy{Default: 3}),
+		WithAuditor(sink.NewAuditor(audit, nostr.GeneratePrivateKey(), nil)),
+	)
+
+	chain.mine("a")
+	chain.mine("a", testLog("0x01"))
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included")
+
+	// Block 2 is replaced by a block including another log
+	chain.reorg(1)
+	chain.mine("b", testLog("0x02"))
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included", "tx_log_orphaned:orphaned", "tx_log_created:included")
+
+	records := event.AuditTrail(audit.events)
+	if len(records) != 1 || records[0].Operation != event.AuditReorgHandled || records[0].Block != 2 || records[0].Details["rolled_back"] != "1" {
+		t.Errorf("Expected the reorg from block 2 to be recorded, got %+v", records)
+	}
+
+	chain.mine("b")
+	chain.mine("b")
+	poll(t, w)
+	expectStatuses(t, s, "tx_log_created:included", "tx_log_orphaned:orphaned", "tx_log_created:included", "tx_log_updated:confirmed")
+
+	confirmed, err := event.ParseTxLogEvent(s.events[3])
+	if err != nil {
+		t.Fatalf("Failed to parse tx log event: %v", err)
+	}
+	if confirmed.LogData.Hash != "0x02" {
+		t.Errorf("Expected log 0x02 to be confirmed, got %s", confirmed.LogData.Hash)
+	}
+}
+
+func TestWatcherSubscriptions(t *testing.T) {
+	tokenA := "0x00000000000000000000000000000000000000Aa"
+	tokenB := "0x00000000000000000000000000000000000000bB"
+
+	tokenLog := func(hash, token string) neth.Log {
+		log := testLog(hash)
+		log.To = token
+		return log
+	}
+
+	chain := newFakeChain()
+	s := &recordingSink{}
+	w := New(chain, s, "100", nostr.GeneratePrivateKey(), WithStartBlock(1), WithAddresses(tokenA))
+
+	chain.mine("a", tokenLog("0x01", tokenA), tokenLog("0x02", tokenB))
+	poll(t, w)
+
+	w.Subscriptions().AddAddresses(tokenB)
+	w.Subscriptions().RemoveAddresses(tokenA)
+
+	chain.mine("a", tokenLog("0x03", tokenA), tokenLog("0x04", tokenB))
+	poll(t, w)
+
+	var hashes []string
+	for _, evt := range s.events {
+		txLogEvent, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse tx log event: %v", err)
+		}
+		hashes = append(hashes, txLogEvent.LogData.Hash)
+	}
+	if fmt.Sprint(hashes) != "[0x01 0x04]" {
+		t.Errorf("Expected logs [0x01 0x04], got %v", hashes)
+	}
+
+	watchlist := &nostr.Event{
+		Kind: 30000,
+		Tags: nostr.Tags{
+			{"d", "tokens"},
+			{"i", "ethereum:100:address:" + tokenA},
+			{"i", "ethereum:1:address:" + tokenB},
+			{"topic", neth.TopicERC20Transfer},
+		},
+	}
+	if err := w.Subscriptions().ApplyWatchlist("100", watchlist); err != nil {
+		t.Fatalf("Failed to apply watchlist: %v", err)
+	}
+
+	if got := w.Subscriptions().Addresses(); fmt.Sprint(got) != fmt.Sprint([]string{strings.ToLower(tokenA)}) {
+		t.Errorf("Expected addresses [%s], got %v", strings.ToLower(tokenA), got)
+	}
+	if got := w.Subscriptions().Topics(); len(got) != 1 || got[0] != neth.TopicERC20Transfer {
+		t.Errorf("Expected topics [%s], got %v", neth.TopicERC20Transfer, got)
+	}
+}
+
+func TestWatcherDustThresholds(t *testing.T) {
+	transferLog := func(hash string, value int64) neth.Log {
+		log := testLog(hash)
+		data := json.RawMessage(fmt.Sprintf(`{"from":"0x1111111111111111111111111111111111111111","to":"0x2222222222222222222222222222222222222222","value":"%d"}`, value))
+		log.Data = &data
+		return log
+	}
+
+	thresholds := event.NewDustThresholds()
+	thresholds.SetDefault(big.NewInt(100))
+
+	for _, action := range []DustAction{DustTag, DustSkip} {
+		chain := newFakeChain()
+		s := &recordingSink{}
+		w := New(chain, s, "100", nostr.GeneratePrivateKey(), WithStartBlock(1), WithDustThresholds(thresholds, action))
+
+		chain.mine("a", transferLog("0x01", 5), transferLog("0x02", 500))
+		poll(t, w)
+
+		var got []string
+		for _, evt := range s.events {
+			txLogEvent, err := event.ParseTxLogEvent(evt)
+			if err != nil {
+				t.Fatalf("Failed to parse tx log event: %v", err)
+			}
+			got = append(got, fmt.Sprintf("%s:%v", txLogEvent.LogData.Hash, event.HasTypeTag(evt, "dust")))
+		}
+
+		expected := "[0x01:true 0x02:false]"
+		if action == DustSkip {
+			expected = "[0x02:false]"
+		}
+		if fmt.Sprint(got) != expected {
+			t.Errorf("Expected events %s for action %d, got %v", expected, action, got)
+		}
+	}
+}
+
+func TestWatcherMutedAddresses(t *testing.T) {
+	transferLog := func(hash, from string) neth.Log {
+		log := testLog(hash)
+		data := json.RawMessage(`{"from":"` + from + `","to":"0x2222222222222222222222222222222222222222","value":"1"}`)
+		log.Data = &data
+		return log
+	}
+	muted := "0x00000000000000000000000000000000000000Aa"
+
+	chain := newFakeChain()
+	s := &recordingSink{}
+	w := New(chain, s, "100", nostr.GeneratePrivateKey(), WithStartBlock(1))
+	w.Subscriptions().SetMuted(muted)
+
+	chain.mine("a", transferLog("0x01", strings.ToLower(muted)), transferLog("0x02", "0x1111111111111111111111111111111111111111"))
+	poll(t, w)
+
+	w.Subscriptions().SetMuted()
+
+	chain.mine("a", transferLog("0x03", muted))
+	poll(t, w)
+
+	var hashes []string
+	for _, evt := range s.events {
+		txLogEvent, err := event.ParseTxLogEvent(evt)
+		if err != nil {
+			t.Fatalf("Failed to parse tx log event: %v", err)
+		}
+		hashes = append(hashes, txLogEvent.LogData.Hash)
+	}
+	if fmt.Sprint(hashes) != "[0x02 0x03]" {
+		t.Errorf("Expected logs [0x02 0x03], got %v", hashes)
+	}
+}
+
+func TestWatcherResumesFromCheckpoint(t *testing.T) {
+	chain := newFakeChain()
+	s := &recordingSink{}
+	store := state.NewMemoryStore()
+	privateKey := nostr.GeneratePrivateKey()
+
+	opts := []Option{
+		WithStartBlock(1),
+		WithConfirmations(ConfirmationPolicy{Default: 3}),
+		WithCheckpointStore(store),
+		WithPollInterval(10 * time.Millisecond),
+	}
+
+	chain.mine("a", testLog("0x01"))
+
+	w := New(chain, s, "100", privateKey, opts...)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	waitForEvents(t, s, 1)
+	if err := w.Stop(); err != nil {
+		t.Fatalf("Failed to stop watcher: %v", err)
+	}
+
+	checkpoint, err := store.Load(context.Background(), w.CheckpointKey())
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+	if checkpoint.Block != 1 || checkpoint.EventID != s.events[0].ID {
+		t.Errorf("Expected block 1 and event %s, got %d and %s", s.events[0].ID, checkpoint.Block, checkpoint.EventID)
+	}
+
+	// The restarted watcher neither sends the log again nor forgets to confirm it
+	chain.mine("a")
+	chain.mine("a")
+
+	w = New(chain, s, "100", privateKey, opts...)
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	waitForEvents(t, s, 2)
+	if err := w.Stop(); err != nil {
+		t.Fatalf("Failed to stop watcher: %v", err)
+	}
+
+	expectStatuses(t, s, "tx_log_created:included", "tx_log_updated:confirmed")
+}
+
+// waitForEvents waits until the sink received at least n events
+func waitForEvents(t *testing.T, s *recordingSink, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		count := len(s.events)
+		s.mu.Unlock()
+
+		if count >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d events before the deadline", n)
+}